@@ -0,0 +1,155 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionTypeReady is the type of the condition which aggregates the readiness of all states
+	ConditionTypeReady = "Ready"
+	// ConditionTypeDriftDetected is the type of the condition which reports objects which drifted from their desired
+	// state during the last sync
+	ConditionTypeDriftDetected = "DriftDetected"
+	// ConditionTypeDegraded is the type of the condition which reports that the OFED driver version
+	// was rolled back after a failed upgrade
+	ConditionTypeDegraded = "Degraded"
+)
+
+// Condition reasons, derived from the State reported for a state
+const (
+	// ConditionReasonReady is used when the state is ready
+	ConditionReasonReady = "Ready"
+	// ConditionReasonNotReady is used when the state is not ready yet
+	ConditionReasonNotReady = "NotReady"
+	// ConditionReasonIgnored is used when the state is not configured in the CR and was ignored
+	ConditionReasonIgnored = "Ignored"
+	// ConditionReasonError is used when the state failed to sync
+	ConditionReasonError = "Error"
+)
+
+// Network Ready condition reasons, in addition to the reasons derived from the State
+const (
+	// ConditionReasonNetworkAttachmentDefNotFound is used when the NetworkAttachmentDefinition of a network
+	// doesn't exist
+	ConditionReasonNetworkAttachmentDefNotFound = "NetworkAttachmentDefinitionNotFound"
+	// ConditionReasonNetworkAttachmentDefInvalid is used when the NetworkAttachmentDefinition of a network
+	// has an invalid CNI config
+	ConditionReasonNetworkAttachmentDefInvalid = "InvalidNetworkAttachmentDefinition"
+	// ConditionReasonResourceNotAvailable is used when the resource of a network is not allocatable on any node
+	ConditionReasonResourceNotAvailable = "ResourceNotAvailable"
+)
+
+// Drift condition reasons
+const (
+	// ConditionReasonDriftCorrected is used when objects drifted from their desired state and were reapplied
+	ConditionReasonDriftCorrected = "DriftCorrected"
+	// ConditionReasonNoDrift is used when no object drifted from its desired state
+	ConditionReasonNoDrift = "NoDrift"
+)
+
+// Degraded condition reasons
+const (
+	// ConditionReasonOFEDDriverRolledBack is used when the OFED driver version was rolled back
+	ConditionReasonOFEDDriverRolledBack = "OFEDDriverRolledBack"
+	// ConditionReasonNotDegraded is used when the OFED driver version is deployed as set in the spec
+	ConditionReasonNotDegraded = "NotDegraded"
+)
+
+// conditionReasons maps State to condition reason
+var conditionReasons = map[State]string{
+	StateReady:    ConditionReasonReady,
+	StateNotReady: ConditionReasonNotReady,
+	StateIgnore:   ConditionReasonIgnored,
+	StateError:    ConditionReasonError,
+}
+
+// SetStateCondition adds or updates the condition of the given type according to the provided State.
+// The condition status is True only for the ready State.
+// LastTransitionTime is updated only if the condition status changes.
+func SetStateCondition(conditions *[]metav1.Condition, conditionType string, state State, message string,
+	generation int64) {
+	status := metav1.ConditionFalse
+	if state == StateReady {
+		status = metav1.ConditionTrue
+	}
+	reason, ok := conditionReasons[state]
+	if !ok {
+		reason = ConditionReasonNotReady
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
+
+// SetStateErrorCondition adds or updates the False condition of the given type for a state which failed to sync,
+// the reason is the class of the sync error, e.g. ApplyFailed, so that the failures can be told apart.
+// LastTransitionTime is updated only if the condition status changes.
+func SetStateErrorCondition(conditions *[]metav1.Condition, conditionType string, reason string, message string,
+	generation int64) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
+
+// SetDriftCondition adds or updates the DriftDetected condition according to the objects which drifted from their
+// desired state during the last sync. The condition status is True if any object drifted.
+func SetDriftCondition(conditions *[]metav1.Condition, driftedObjects []string, generation int64) {
+	cond := metav1.Condition{
+		Type:               ConditionTypeDriftDetected,
+		Status:             metav1.ConditionFalse,
+		Reason:             ConditionReasonNoDrift,
+		ObservedGeneration: generation,
+	}
+	if len(driftedObjects) > 0 {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = ConditionReasonDriftCorrected
+		cond.Message = "objects drifted from the desired state and were reapplied: " + strings.Join(driftedObjects, ", ")
+	}
+	meta.SetStatusCondition(conditions, cond)
+}
+
+// SetDegradedCondition adds or updates the Degraded condition according to the rollback of the OFED driver version.
+// The condition status is True if the version was rolled back.
+func SetDegradedCondition(conditions *[]metav1.Condition, rollback *OFEDDriverRollbackStatus, generation int64) {
+	cond := metav1.Condition{
+		Type:               ConditionTypeDegraded,
+		Status:             metav1.ConditionFalse,
+		Reason:             ConditionReasonNotDegraded,
+		ObservedGeneration: generation,
+	}
+	if rollback != nil {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = ConditionReasonOFEDDriverRolledBack
+		cond.Message = fmt.Sprintf("OFED driver version rolled back from %s to %s: %s",
+			rollback.FailedVersion, rollback.Version, rollback.Reason)
+	}
+	meta.SetStatusCondition(conditions, cond)
+}
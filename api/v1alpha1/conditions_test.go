@@ -0,0 +1,106 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Conditions tests", func() {
+	var conditions []metav1.Condition
+
+	BeforeEach(func() {
+		conditions = nil
+	})
+
+	It("should set True condition for ready state", func() {
+		SetStateCondition(&conditions, "state-OFED", StateReady, "", 2)
+		cond := meta.FindStatusCondition(conditions, "state-OFED")
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(ConditionReasonReady))
+		Expect(cond.ObservedGeneration).To(Equal(int64(2)))
+		Expect(cond.LastTransitionTime.IsZero()).To(BeFalse())
+	})
+
+	DescribeTable("should set False condition for not ready states",
+		func(state State, reason string) {
+			SetStateCondition(&conditions, "state-multus-cni", state, "msg", 1)
+			cond := meta.FindStatusCondition(conditions, "state-multus-cni")
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(reason))
+			Expect(cond.Message).To(Equal("msg"))
+		},
+		Entry("notReady", State(StateNotReady), ConditionReasonNotReady),
+		Entry("ignore", State(StateIgnore), ConditionReasonIgnored),
+		Entry("error", State(StateError), ConditionReasonError),
+	)
+
+	It("should set False condition with the error class as reason", func() {
+		SetStateCondition(&conditions, "state-OFED", StateReady, "", 1)
+		SetStateErrorCondition(&conditions, "state-OFED", "ApplyFailed", "failed to apply DaemonSet ns/ds", 2)
+		cond := meta.FindStatusCondition(conditions, "state-OFED")
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal("ApplyFailed"))
+		Expect(cond.Message).To(Equal("failed to apply DaemonSet ns/ds"))
+		Expect(cond.ObservedGeneration).To(Equal(int64(2)))
+	})
+
+	It("should update existing condition", func() {
+		SetStateCondition(&conditions, "state-OFED", StateNotReady, "", 1)
+		SetStateCondition(&conditions, ConditionTypeReady, StateNotReady, "", 1)
+		SetStateCondition(&conditions, "state-OFED", StateReady, "", 1)
+		Expect(conditions).To(HaveLen(2))
+		Expect(meta.IsStatusConditionTrue(conditions, "state-OFED")).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(conditions, ConditionTypeReady)).To(BeTrue())
+	})
+
+	It("should set drift condition", func() {
+		SetDriftCondition(&conditions, []string{"DaemonSet ns/ds"}, 1)
+		cond := meta.FindStatusCondition(conditions, ConditionTypeDriftDetected)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(ConditionReasonDriftCorrected))
+		Expect(cond.Message).To(ContainSubstring("DaemonSet ns/ds"))
+
+		SetDriftCondition(&conditions, nil, 1)
+		cond = meta.FindStatusCondition(conditions, ConditionTypeDriftDetected)
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(ConditionReasonNoDrift))
+		Expect(cond.Message).To(BeEmpty())
+	})
+	It("should set degraded condition", func() {
+		SetDegradedCondition(&conditions, &OFEDDriverRollbackStatus{
+			FailedVersion: "24.04-0.6.6.0-0", Version: "24.01-0.3.3.1-0", Reason: "upgrade failed on 2 nodes"}, 1)
+		cond := meta.FindStatusCondition(conditions, ConditionTypeDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(ConditionReasonOFEDDriverRolledBack))
+		Expect(cond.Message).To(ContainSubstring("from 24.04-0.6.6.0-0 to 24.01-0.3.3.1-0"))
+
+		SetDegradedCondition(&conditions, nil, 1)
+		cond = meta.FindStatusCondition(conditions, ConditionTypeDegraded)
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(ConditionReasonNotDegraded))
+		Expect(cond.Message).To(BeEmpty())
+	})
+})
@@ -33,6 +33,18 @@ type HostDeviceNetworkSpec struct {
 	ResourceName string `json:"resourceName,omitempty"`
 	// IPAM configuration to be used for this network
 	IPAM string `json:"ipam,omitempty"`
+	// StaticIPAM is a structured IPAM configuration allocating the addresses of the pods from static IP ranges
+	// with whereabouts, it can't be set together with ipam
+	// +optional
+	StaticIPAM *StaticIPAMSpec `json:"staticIpam,omitempty"`
+	// DNS configuration of the network
+	// +optional
+	DNS *DNSSpec `json:"dns,omitempty"`
+	// RdmaIsolation chains the RDMA CNI after the host-device CNI, which moves the RDMA device of the network
+	// interface to the network namespace of the pod. Requires the RDMA subsystem of the nodes in exclusive mode
+	// and the RDMA CNI deployed with secondaryNetwork.rdmaCni of the NicClusterPolicy.
+	// +optional
+	RdmaIsolation bool `json:"rdmaIsolation,omitempty"`
 }
 
 // HostDeviceNetworkStatus defines the observed state of HostDeviceNetwork
@@ -44,6 +56,12 @@ type HostDeviceNetworkStatus struct {
 	HostDeviceNetworkAttachmentDef string `json:"hostDeviceNetworkAttachmentDef,omitempty"`
 	// Informative string in case the observed state is error
 	Reason string `json:"reason,omitempty"`
+	// Conditions provide the Ready condition of the network, the network is ready once its
+	// NetworkAttachmentDefinition exists with a valid CNI config and its resource, if any, is available on a node
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 	// AppliedStates provide a finer view of the observed state
 	AppliedStates []AppliedState `json:"appliedStates,omitempty"`
 }
@@ -23,16 +23,48 @@ import (
 const (
 	// IPoIBNetworkCRDName is used for the CRD Kind.
 	IPoIBNetworkCRDName = "IPoIBNetwork"
+	// IPoIBBondModeActiveBackup is the only bonding mode supported by IPoIB interfaces
+	IPoIBBondModeActiveBackup = "active-backup"
 )
 
+// IPoIBBondSpec describes the bond of two IPoIB interfaces used as the parent interface of the network
+type IPoIBBondSpec struct {
+	// Name of the bond interface
+	Name string `json:"name"`
+	// Mode of the bond, IPoIB interfaces support only the active-backup mode
+	// +optional
+	// +kubebuilder:default:="active-backup"
+	// +kubebuilder:validation:Enum={"active-backup"}
+	Mode string `json:"mode,omitempty"`
+	// Links are the names of the two IPoIB interfaces enslaved to the bond
+	// +kubebuilder:validation:MinItems=2
+	// +kubebuilder:validation:MaxItems=2
+	Links []string `json:"links"`
+	// Miimon is the link monitoring interval of the bond in milliseconds
+	// +optional
+	// +kubebuilder:default:=100
+	// +kubebuilder:validation:Minimum:=0
+	Miimon int `json:"miimon,omitempty"`
+}
+
 // IPoIBNetworkSpec defines the desired state of IPoIBNetwork
 type IPoIBNetworkSpec struct {
 	// Namespace of the NetworkAttachmentDefinition custom resource
 	NetworkNamespace string `json:"networkNamespace,omitempty"`
 	// Name of the host interface to enslave. Defaults to default route interface
 	Master string `json:"master,omitempty"`
+	// Bond of IPoIB interfaces used as the host interface, master must be empty or match the name of the bond
+	// +optional
+	Bond *IPoIBBondSpec `json:"bond,omitempty"`
 	// IPAM configuration to be used for this network.
 	IPAM string `json:"ipam,omitempty"`
+	// StaticIPAM is a structured IPAM configuration allocating the addresses of the pods from static IP ranges
+	// with whereabouts, it can't be set together with ipam
+	// +optional
+	StaticIPAM *StaticIPAMSpec `json:"staticIpam,omitempty"`
+	// DNS configuration of the network
+	// +optional
+	DNS *DNSSpec `json:"dns,omitempty"`
 }
 
 // IPoIBNetworkStatus defines the observed state of IPoIBNetwork
@@ -44,6 +76,12 @@ type IPoIBNetworkStatus struct {
 	IPoIBNetworkAttachmentDef string `json:"ipoibNetworkAttachmentDef,omitempty"`
 	// Informative string in case the observed state is error
 	Reason string `json:"reason,omitempty"`
+	// Conditions provide the Ready condition of the network, the network is ready once its
+	// NetworkAttachmentDefinition exists with a valid CNI config and its resource, if any, is available on a node
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -39,6 +39,13 @@ type MacvlanNetworkSpec struct {
 	Mtu int `json:"mtu,omitempty"`
 	// IPAM configuration to be used for this network.
 	IPAM string `json:"ipam,omitempty"`
+	// StaticIPAM is a structured IPAM configuration allocating the addresses of the pods from static IP ranges
+	// with whereabouts, it can't be set together with ipam
+	// +optional
+	StaticIPAM *StaticIPAMSpec `json:"staticIpam,omitempty"`
+	// DNS configuration of the network
+	// +optional
+	DNS *DNSSpec `json:"dns,omitempty"`
 }
 
 // MacvlanNetworkStatus defines the observed state of MacvlanNetwork
@@ -50,6 +57,12 @@ type MacvlanNetworkStatus struct {
 	MacvlanNetworkAttachmentDef string `json:"macvlanNetworkAttachmentDef,omitempty"`
 	// Informative string in case the observed state is error
 	Reason string `json:"reason,omitempty"`
+	// Conditions provide the Ready condition of the network, the network is ready once its
+	// NetworkAttachmentDefinition exists with a valid CNI config and its resource, if any, is available on a node
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
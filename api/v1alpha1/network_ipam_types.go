@@ -0,0 +1,69 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// StaticIPAMSpec is the structured IPAM configuration of a network, it is rendered as the IPAM configuration
+// of the whereabouts IPAM plugin which allocates the addresses of the pods from static IP ranges
+type StaticIPAMSpec struct {
+	// Ranges of the IP addresses allocated to the pods, a pod gets an address of each range,
+	// e.g. an IPv4 and an IPv6 address
+	// +kubebuilder:validation:MinItems=1
+	Ranges []IPRangeSpec `json:"ranges"`
+	// Gateway of the pods, it must belong to the subnet of one of the ranges
+	// +optional
+	Gateway string `json:"gateway,omitempty"`
+	// Routes added to the network interface of the pods
+	// +optional
+	Routes []RouteSpec `json:"routes,omitempty"`
+}
+
+// IPRangeSpec is a range of IP addresses allocated to the pods
+type IPRangeSpec struct {
+	// Subnet of the range in CIDR notation, e.g. 192.168.2.0/24
+	Subnet string `json:"subnet"`
+	// RangeStart is the first address of the subnet allocated to the pods, defaults to the first address of the subnet
+	// +optional
+	RangeStart string `json:"rangeStart,omitempty"`
+	// RangeEnd is the last address of the subnet allocated to the pods, defaults to the last address of the subnet
+	// +optional
+	RangeEnd string `json:"rangeEnd,omitempty"`
+}
+
+// RouteSpec is a route added to the network interface of the pods
+type RouteSpec struct {
+	// Dst is the destination of the route in CIDR notation, e.g. 0.0.0.0/0 for the default route
+	Dst string `json:"dst"`
+	// GW is the next hop of the route, defaults to the gateway of the network
+	// +optional
+	GW string `json:"gw,omitempty"`
+}
+
+// DNSSpec is the DNS configuration passed to the CNI plugin of a network
+type DNSSpec struct {
+	// Nameservers are the IP addresses of the DNS servers
+	// +optional
+	Nameservers []string `json:"nameservers,omitempty"`
+	// Domain is the local domain used for short hostname lookups
+	// +optional
+	Domain string `json:"domain,omitempty"`
+	// Search domains for short hostname lookups
+	// +optional
+	Search []string `json:"search,omitempty"`
+	// Options of the resolver
+	// +optional
+	Options []string `json:"options,omitempty"`
+}
@@ -17,8 +17,13 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
@@ -48,12 +53,154 @@ type ImageSpec struct {
 	Image string `json:"image"`
 	// +kubebuilder:validation:Pattern=[a-zA-Z0-9\.\-\/]+
 	Repository string `json:"repository"`
-	// +kubebuilder:validation:Pattern=[a-zA-Z0-9\.-]+
+	// Version is the tag of the image or its digest, e.g. sha256:<hex>, to pin the image
+	// +kubebuilder:validation:Pattern=`[a-zA-Z0-9\.\-:]+`
 	Version string `json:"version"`
 	// +optional
 	// +kubebuilder:default:={}
 	ImagePullSecrets   []string               `json:"imagePullSecrets"`
 	ContainerResources []ResourceRequirements `json:"containerResources,omitempty"`
+	// List of environment variables to set in the component containers.
+	// +optional
+	Env []v1.EnvVar `json:"env,omitempty"`
+	// ImagePullPolicy of the containers of the component
+	// +kubebuilder:validation:Enum={"Always", "Never", "IfNotPresent"}
+	// +kubebuilder:default:=IfNotPresent
+	// +optional
+	ImagePullPolicy v1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// ArchImages overrides the image on the nodes of a CPU architecture, e.g. to deploy another build of the
+	// component on the ARM nodes of the cluster, the component is then restricted to the nodes of each architecture
+	// with the kubernetes.io/arch node affinity
+	// +optional
+	ArchImages *ArchImagesSpec `json:"archImages,omitempty"`
+}
+
+// ComponentDaemonSetSpec customizes the scheduling, the rollout and the security context of the DaemonSets of a
+// component
+type ComponentDaemonSetSpec struct {
+	// NodeSelector restricts the DaemonSets of the component to the nodes with matching labels
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// NodeAffinity of the DaemonSets of the component, overrides the nodeAffinity of the NicClusterPolicy when set
+	// +optional
+	NodeAffinity *v1.NodeAffinity `json:"nodeAffinity,omitempty"`
+	// Tolerations of the DaemonSets of the component, added to the tolerations of the NicClusterPolicy
+	// +optional
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// UpdateStrategy of the DaemonSets of the component, RollingUpdate by default and OnDelete for the OFED driver
+	// whose pods are then restarted by its upgrade process
+	// +optional
+	UpdateStrategy *appsv1.DaemonSetUpdateStrategy `json:"updateStrategy,omitempty"`
+	// PriorityClassName of the pods of the DaemonSets and Deployments of the component, overrides the
+	// priorityClassName of the NicClusterPolicy when set
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// SecurityContext customizes the security context of the privileged containers of the component
+	// +optional
+	SecurityContext *ContainerSecurityContextSpec `json:"securityContext,omitempty"`
+}
+
+// ContainerSecurityContextSpec customizes the security context of the containers of a component which
+// run privileged by default, so that hardened clusters can run them with tighter profiles where possible
+type ContainerSecurityContextSpec struct {
+	// Privileged runs the containers in privileged mode, disabling it requires the component to only use the
+	// devices and host paths mounted in its containers
+	// +kubebuilder:default:=true
+	// +optional
+	Privileged *bool `json:"privileged,omitempty"`
+	// SeccompProfile of the containers, it can only be set when the containers aren't privileged
+	// +optional
+	SeccompProfile *v1.SeccompProfile `json:"seccompProfile,omitempty"`
+	// SELinuxOptions of the containers, e.g. the SELinux type allowed to access the devices of the node
+	// +optional
+	SELinuxOptions *v1.SELinuxOptions `json:"seLinuxOptions,omitempty"`
+	// ReadOnlyRootFilesystem mounts the root filesystem of the containers as read-only
+	// +optional
+	ReadOnlyRootFilesystem bool `json:"readOnlyRootFilesystem,omitempty"`
+}
+
+// ArchImageSpec overrides the image of a component on the nodes of a CPU architecture,
+// the fields which are not set are taken from the image of the component
+type ArchImageSpec struct {
+	// +kubebuilder:validation:Pattern=[a-zA-Z0-9\-]+
+	// +optional
+	Image string `json:"image,omitempty"`
+	// +kubebuilder:validation:Pattern=[a-zA-Z0-9\.\-\/]+
+	// +optional
+	Repository string `json:"repository,omitempty"`
+	// Version is the tag of the image or its digest, e.g. sha256:<hex>
+	// +kubebuilder:validation:Pattern=`[a-zA-Z0-9\.\-:]+`
+	// +optional
+	Version string `json:"version,omitempty"`
+}
+
+// ArchImagesSpec holds the image overrides of a component per CPU architecture,
+// as reported by the kubernetes.io/arch node label
+type ArchImagesSpec struct {
+	// +optional
+	AMD64 *ArchImageSpec `json:"amd64,omitempty"`
+	// +optional
+	ARM64 *ArchImageSpec `json:"arm64,omitempty"`
+}
+
+// get returns the image override of the CPU architecture, nil if not set
+func (a *ArchImagesSpec) get(arch string) *ArchImageSpec {
+	if a == nil {
+		return nil
+	}
+	switch arch {
+	case "amd64":
+		return a.AMD64
+	case "arm64":
+		return a.ARM64
+	}
+	return nil
+}
+
+// Archs returns the CPU architectures which have an image override, in a stable order
+func (a *ArchImagesSpec) Archs() []string {
+	archs := []string{}
+	for _, arch := range []string{"amd64", "arm64"} {
+		if a.get(arch) != nil {
+			archs = append(archs, arch)
+		}
+	}
+	return archs
+}
+
+// ForArch returns the image of the component on the nodes of the CPU architecture,
+// the image itself if it has no override for the architecture
+func (is *ImageSpec) ForArch(arch string) *ImageSpec {
+	override := is.ArchImages.get(arch)
+	if override == nil {
+		return is
+	}
+	archImage := is.DeepCopy()
+	archImage.ArchImages = nil
+	if override.Image != "" {
+		archImage.Image = override.Image
+	}
+	if override.Repository != "" {
+		archImage.Repository = override.Repository
+	}
+	if override.Version != "" {
+		archImage.Version = override.Version
+	}
+	return archImage
+}
+
+// IsDigest returns true if the version of the image is a digest rather than a tag, tags can't contain a colon
+func (is *ImageSpec) IsDigest() bool {
+	return strings.Contains(is.Version, ":")
+}
+
+// ImagePath returns the reference of the image, repository/image@digest if the version is a digest,
+// repository/image:tag otherwise
+func (is *ImageSpec) ImagePath() string {
+	if is.IsDigest() {
+		return fmt.Sprintf("%s/%s@%s", is.Repository, is.Image, is.Version)
+	}
+	return fmt.Sprintf("%s/%s:%s", is.Repository, is.Image, is.Version)
 }
 
 // GetContainerResources is a method to easily get container resources from struct, that embed ImageSpec
@@ -74,6 +221,9 @@ type ImageSpecWithConfig struct {
 type PodProbeSpec struct {
 	InitialDelaySeconds int `json:"initialDelaySeconds"`
 	PeriodSeconds       int `json:"periodSeconds"`
+	// Number of seconds after which the probe times out, must not exceed periodSeconds
+	// +optional
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
 }
 
 // ConfigMapNameReference references a config map in a specific namespace.
@@ -82,18 +232,31 @@ type ConfigMapNameReference struct {
 	Name string `json:"name,omitempty"`
 }
 
+// ProxySpec describes the proxy used by the components which reach the internet,
+// e.g. the OFED driver container which downloads the kernel headers to compile the driver
+type ProxySpec struct {
+	// HTTPProxy is the URL of the proxy for HTTP requests
+	// +optional
+	HTTPProxy string `json:"httpProxy,omitempty"`
+	// HTTPSProxy is the URL of the proxy for HTTPS requests
+	// +optional
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+	// NoProxy is a comma-separated list of hostnames, domains and CIDRs which are reached without the proxy
+	// +optional
+	NoProxy string `json:"noProxy,omitempty"`
+}
+
 // OFEDDriverSpec describes configuration options for OFED driver
 type OFEDDriverSpec struct {
 	// Image information for ofed driver container
-	ImageSpec `json:""`
+	ImageSpec              `json:""`
+	ComponentDaemonSetSpec `json:""`
 	// Pod startup probe settings
 	StartupProbe *PodProbeSpec `json:"startupProbe,omitempty"`
 	// Pod liveness probe settings
 	LivenessProbe *PodProbeSpec `json:"livenessProbe,omitempty"`
 	// Pod readiness probe settings
 	ReadinessProbe *PodProbeSpec `json:"readinessProbe,omitempty"`
-	// List of environment variables to set in the OFED container.
-	Env []v1.EnvVar `json:"env,omitempty"`
 	// Ofed auto-upgrade settings
 	OfedUpgradePolicy *DriverUpgradePolicySpec `json:"upgradePolicy,omitempty"`
 	// Optional: Custom TLS certificates configuration for driver container
@@ -112,6 +275,12 @@ type OFEDDriverSpec struct {
 	// +optional
 	// +kubebuilder:default:=false
 	ForcePrecompiled bool `json:"forcePrecompiled,omitempty"`
+	// DisablePrecompiled specifies if MOFED precompiled images should not be used
+	// If set to true, MOFED drivers will always be compiled on Nodes, even if a precompiled image exists.
+	// DisablePrecompiled can not be set together with ForcePrecompiled.
+	// +optional
+	// +kubebuilder:default:=false
+	DisablePrecompiled bool `json:"disablePrecompiled,omitempty"`
 }
 
 // DriverUpgradePolicySpec describes policy configuration for automatic upgrades
@@ -121,18 +290,215 @@ type DriverUpgradePolicySpec struct {
 	// +optional
 	// +kubebuilder:default:=false
 	AutoUpgrade bool `json:"autoUpgrade,omitempty"`
+	// Paused freezes the upgrade, the nodes are kept in their current upgrade state until the upgrade is resumed
+	// +optional
+	// +kubebuilder:default:=false
+	Paused bool `json:"paused,omitempty"`
 	// MaxParallelUpgrades indicates how many nodes can be upgraded in parallel
 	// 0 means no limit, all nodes will be upgraded in parallel
 	// +optional
 	// +kubebuilder:default:=1
 	// +kubebuilder:validation:Minimum:=0
-	MaxParallelUpgrades int                    `json:"maxParallelUpgrades,omitempty"`
-	WaitForCompletion   *WaitForCompletionSpec `json:"waitForCompletion,omitempty"`
-	DrainSpec           *DrainSpec             `json:"drain,omitempty"`
+	MaxParallelUpgrades int `json:"maxParallelUpgrades,omitempty"`
+	// MaxUnavailable is the maximum number of nodes with the driver installed, that can be unavailable during
+	// the upgrade. Value can be an absolute number (ex: 5) or a percentage of total nodes at the start of upgrade
+	// (ex: 10%). Absolute number is calculated from percentage by rounding up.
+	// If not set, there is no limit on the number of unavailable nodes
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	MaxUnavailable    *intstr.IntOrString    `json:"maxUnavailable,omitempty"`
+	WaitForCompletion *WaitForCompletionSpec `json:"waitForCompletion,omitempty"`
+	DrainSpec         *DrainSpec             `json:"drain,omitempty"`
+	// CordonOnly restarts the driver pod on the cordoned node without removing the workloads from it,
+	// the pod deletion and the drain of the node are skipped, the drain settings are ignored
+	// +optional
+	// +kubebuilder:default:=false
+	CordonOnly bool `json:"cordonOnly,omitempty"`
 	// SafeLoad turn on safe driver loading (cordon and drain the node before loading the driver)
 	// +optional
 	// +kubebuilder:default:=false
 	SafeLoad bool `json:"safeLoad,omitempty"`
+	// GPUOperatorCoordination turns on the coordination with the driver upgrades of the NVIDIA GPU Operator,
+	// the driver upgrade of a node is postponed while the GPU driver upgrade is in progress on it
+	// and the GPU driver upgrade of a node is paused while the driver upgrade is in progress on it
+	// +optional
+	// +kubebuilder:default:=false
+	GPUOperatorCoordination bool `json:"gpuOperatorCoordination,omitempty"`
+	// PodDeletionOnDrainTimeout describes the deletion of the pods which can't be evicted from the node,
+	// e.g. because of their PodDisruptionBudgets, after the drain timeout expires
+	// +optional
+	PodDeletionOnDrainTimeout *PodDeletionOnDrainTimeoutSpec `json:"podDeletionOnDrainTimeout,omitempty"`
+	// Reboot describes the reboot of the nodes on which the driver upgrade requires a reboot
+	// +optional
+	Reboot *RebootSpec `json:"reboot,omitempty"`
+	// Hooks describes the hooks run on the node around the restart of the driver, e.g. to let the applications
+	// checkpoint their RDMA state
+	// +optional
+	Hooks *UpgradeHooksSpec `json:"hooks,omitempty"`
+	// NodeOrder describes the order in which the nodes are upgraded, the order is arbitrary if not set
+	// +optional
+	NodeOrder *NodeOrderSpec `json:"nodeOrder,omitempty"`
+	// Canary describes the canary phase of the upgrade, the canary nodes are upgraded first and the other nodes
+	// are upgraded once the driver is ready on the canary nodes for the soak period
+	// +optional
+	Canary *CanarySpec `json:"canary,omitempty"`
+	// FailureThreshold is the number of nodes on which the upgrade can fail, the nodes in upgrade-failed state
+	// or with the upgraded driver pod in CrashLoopBackOff, before the OFED driver version is automatically
+	// rolled back to the version running on the nodes which are not upgraded yet.
+	// If not set, the version is never rolled back
+	// +optional
+	// +kubebuilder:validation:Minimum:=0
+	FailureThreshold *int `json:"failureThreshold,omitempty"`
+}
+
+// CanarySpec describes the canary phase of the driver upgrade
+type CanarySpec struct {
+	// NodeSelector selects the canary nodes by their labels
+	// +kubebuilder:validation:MinProperties:=1
+	NodeSelector map[string]string `json:"nodeSelector"`
+	// SoakSeconds is the time in seconds the driver should be ready on all canary nodes
+	// before the upgrade of the other nodes starts
+	// +optional
+	// +kubebuilder:default:=600
+	// +kubebuilder:validation:Minimum:=0
+	SoakSeconds int `json:"soakSeconds,omitempty"`
+}
+
+const (
+	// NodeOrderPolicyZone upgrades the nodes zone by zone, ordered by their topology.kubernetes.io/zone label
+	NodeOrderPolicyZone = "zone"
+	// NodeOrderPolicyLabel upgrades the nodes ordered by the value of a label
+	NodeOrderPolicyLabel = "label"
+	// NodeOrderPolicyNodeList upgrades the nodes in the order of an explicit list of nodes
+	NodeOrderPolicyNodeList = "nodeList"
+	// NodeOrderPolicyEmptiestFirst upgrades the nodes running the least pods first
+	NodeOrderPolicyEmptiestFirst = "emptiestFirst"
+)
+
+// NodeOrderSpec describes the order in which the nodes are upgraded during automatic upgrade,
+// the nodes which are equal according to the policy are ordered by name
+type NodeOrderSpec struct {
+	// Policy is the policy the nodes are ordered by
+	// +kubebuilder:validation:Enum={zone,label,nodeList,emptiestFirst}
+	Policy string `json:"policy"`
+	// LabelKey is the key of the label the nodes are ordered by with label policy
+	// +optional
+	LabelKey string `json:"labelKey,omitempty"`
+	// Values is the order of the zones with zone policy or of the label values with label policy,
+	// the nodes with other values are upgraded afterwards ordered by value, the nodes without the label last
+	// +optional
+	Values []string `json:"values,omitempty"`
+	// Nodes is the ordered list of the node names with nodeList policy,
+	// the nodes which are not in the list are upgraded afterwards
+	// +optional
+	Nodes []string `json:"nodes,omitempty"`
+}
+
+// UpgradeHooksSpec describes the hooks run on the node during automatic upgrade
+type UpgradeHooksSpec struct {
+	// PreDrain is run before the node is cordoned
+	// +optional
+	PreDrain *UpgradeHookSpec `json:"preDrain,omitempty"`
+	// PostDrain is run after the driver pod is restarted, before the node is uncordoned
+	// +optional
+	PostDrain *UpgradeHookSpec `json:"postDrain,omitempty"`
+}
+
+const (
+	// UpgradeHookFailurePolicyFail moves the node to upgrade-failed state if the hook fails
+	UpgradeHookFailurePolicyFail = "Fail"
+	// UpgradeHookFailurePolicyIgnore continues the upgrade of the node if the hook fails
+	UpgradeHookFailurePolicyIgnore = "Ignore"
+)
+
+// UpgradeHookSpec describes a hook run on the node during automatic upgrade,
+// if both are set the commands of the annotated pods are executed before the Job is run
+type UpgradeHookSpec struct {
+	// Job describes the Job run on the node
+	// +optional
+	Job *UpgradeHookJobSpec `json:"job,omitempty"`
+	// ExecAnnotatedPods enables executing the command set in the nvidia.com/ofed-driver-upgrade.pre-drain-hook or
+	// nvidia.com/ofed-driver-upgrade.post-drain-hook annotation of the pods running on the node, the command is
+	// executed with "sh -c" in the container set in the nvidia.com/ofed-driver-upgrade.hook-container annotation
+	// or in the first container of the pod
+	// +optional
+	// +kubebuilder:default:=false
+	ExecAnnotatedPods bool `json:"execAnnotatedPods,omitempty"`
+	// TimeoutSeconds specifies the length of time in seconds to wait for the hook to complete before it is
+	// considered failed, zero means infinite
+	// +optional
+	// +kubebuilder:default:=600
+	// +kubebuilder:validation:Minimum:=0
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+	// FailurePolicy defines the handling of a failed hook, Fail moves the node to upgrade-failed state,
+	// Ignore continues the upgrade of the node
+	// +optional
+	// +kubebuilder:validation:Enum={Fail,Ignore}
+	// +kubebuilder:default:=Fail
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+}
+
+// UpgradeHookJobSpec describes the Job run on the node by an upgrade hook,
+// the name of the node is passed to the Job in the NODE_NAME environment variable
+type UpgradeHookJobSpec struct {
+	// Image is the image of the Job container
+	Image string `json:"image"`
+	// Command is the entrypoint of the Job container, the entrypoint of the image is used if not set
+	// +optional
+	Command []string `json:"command,omitempty"`
+	// Args are the arguments of the entrypoint of the Job container
+	// +optional
+	Args []string `json:"args,omitempty"`
+	// ServiceAccountName is the name of the ServiceAccount the Job is run with
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
+const (
+	// RebootMethodHelperPod reboots the node from a privileged helper pod
+	RebootMethodHelperPod = "helperPod"
+	// RebootMethodRebootDaemon requests the reboot of the node from a reboot daemon, e.g. kured,
+	// by creating the /var/run/reboot-required file on the node from a privileged helper pod
+	RebootMethodRebootDaemon = "rebootDaemon"
+)
+
+// RebootSpec describes the reboot of the node during automatic upgrade
+type RebootSpec struct {
+	// Enable indicates if the node should be rebooted when the driver upgrade requires a reboot,
+	// if set to false such nodes are moved to upgrade-failed state
+	// +optional
+	// +kubebuilder:default:=false
+	Enable bool `json:"enable,omitempty"`
+	// Method is the method used to reboot the node
+	// +optional
+	// +kubebuilder:validation:Enum={helperPod,rebootDaemon}
+	// +kubebuilder:default:=helperPod
+	Method string `json:"method,omitempty"`
+	// Image is the image of the privileged helper pod, it should provide the chroot command
+	// +optional
+	// +kubebuilder:default:="busybox:1.36"
+	Image string `json:"image,omitempty"`
+	// TimeoutSecond specifies the length of time in seconds to wait for the node to reboot before the node
+	// is moved to upgrade-failed state, zero means infinite
+	// +optional
+	// +kubebuilder:default:=1200
+	// +kubebuilder:validation:Minimum:=0
+	TimeoutSecond int `json:"timeoutSeconds,omitempty"`
+}
+
+// PodDeletionOnDrainTimeoutSpec describes the deletion of the pods remaining on the node after the drain timeout
+type PodDeletionOnDrainTimeoutSpec struct {
+	// Enable indicates if the pods remaining on the node after the drain timeout should be deleted,
+	// bypassing their PodDisruptionBudgets
+	// +optional
+	// +kubebuilder:default:=false
+	Enable bool `json:"enable,omitempty"`
+	// Namespaces is the list of namespaces whose pods can be deleted, all namespaces if empty
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// ExcludeNamespaces is the list of namespaces whose pods must not be deleted, it takes precedence over Namespaces
+	// +optional
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
 }
 
 // WaitForCompletionSpec describes the configuration for waiting on job completions
@@ -175,14 +541,72 @@ type DrainSpec struct {
 	// +optional
 	// +kubebuilder:default:=false
 	DeleteEmptyDir bool `json:"deleteEmptyDir,omitempty"`
+	// SkipWaitForDeleteTimeoutSeconds skips waiting for the deletion of the pods whose deletion timestamp is older
+	// than the given number of seconds, e.g. the pods of an unreachable node, zero means the deletion is waited for
+	// +optional
+	// +kubebuilder:validation:Minimum:=0
+	SkipWaitForDeleteTimeoutSeconds int `json:"skipWaitForDeleteTimeoutSeconds,omitempty"`
+	// DisableEviction deletes the pods instead of evicting them, bypassing their PodDisruptionBudgets
+	// +optional
+	// +kubebuilder:default:=false
+	DisableEviction bool `json:"disableEviction,omitempty"`
+	// NamespacePodSelectors restrict the drained pods of a namespace to the pods matching a label selector,
+	// in addition to PodSelector
+	// +optional
+	// +listType=map
+	// +listMapKey=namespace
+	NamespacePodSelectors []NamespacePodSelector `json:"namespacePodSelectors,omitempty"`
+	// RetryPolicy describes retries of a failed node drain before the node is moved to upgrade-failed state
+	// +optional
+	RetryPolicy *DrainRetryPolicySpec `json:"retryPolicy,omitempty"`
+	// NodeMaintenance indicates if the drain of the node should be delegated to the node maintenance operator,
+	// a NodeMaintenance object is created for the node and the upgrade waits for the maintenance to complete,
+	// the object is deleted when the node is uncordoned. RetryPolicy is not applicable in this mode
+	// +optional
+	// +kubebuilder:default:=false
+	NodeMaintenance bool `json:"nodeMaintenance,omitempty"`
+}
+
+// NamespacePodSelector selects the pods of a namespace by a label selector
+type NamespacePodSelector struct {
+	// Namespace of the pods
+	Namespace string `json:"namespace"`
+	// PodSelector specifies a label selector to filter the pods of the namespace
+	PodSelector string `json:"podSelector"`
+}
+
+// DrainRetryPolicySpec describes the retry policy of a failed node drain
+type DrainRetryPolicySpec struct {
+	// MaxAttempts is the maximal number of drain attempts, including the first one
+	// +optional
+	// +kubebuilder:default:=1
+	// +kubebuilder:validation:Minimum:=1
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+	// BackoffSeconds is the delay in seconds before the first retry, the delay is doubled on each next retry
+	// +optional
+	// +kubebuilder:default:=10
+	// +kubebuilder:validation:Minimum:=0
+	BackoffSeconds int `json:"backoffSeconds,omitempty"`
+	// MaxBackoffSeconds limits the delay in seconds between retries
+	// +optional
+	// +kubebuilder:default:=300
+	// +kubebuilder:validation:Minimum:=0
+	MaxBackoffSeconds int `json:"maxBackoffSeconds,omitempty"`
+	// DeadlineSeconds is the total time in seconds, starting from the first attempt,
+	// after which the drain is not retried anymore, zero means infinite
+	// +optional
+	// +kubebuilder:default:=0
+	// +kubebuilder:validation:Minimum:=0
+	DeadlineSeconds int `json:"deadlineSeconds,omitempty"`
 }
 
 // DevicePluginSpec describes configuration options for device plugin
 // 1. Image information for device plugin
 // 2. Device plugin configuration
 type DevicePluginSpec struct {
-	ImageSpecWithConfig `json:""`
-	UseCdi              bool `json:"useCdi,omitempty"`
+	ImageSpecWithConfig    `json:""`
+	ComponentDaemonSetSpec `json:""`
+	UseCdi                 bool `json:"useCdi,omitempty"`
 }
 
 // MultusSpec describes configuration options for Multus CNI
@@ -190,7 +614,14 @@ type DevicePluginSpec struct {
 //  2. Multus CNI config if config is missing or empty then multus config will be automatically generated from the CNI
 //     configuration file of the master plugin (the first file in lexicographical order in cni-conf-dir)
 type MultusSpec struct {
-	ImageSpecWithConfig `json:""`
+	ImageSpecWithConfig    `json:""`
+	ComponentDaemonSetSpec `json:""`
+}
+
+// CNIPluginSpec describes configuration options for a CNI plugin deployed by a DaemonSet
+type CNIPluginSpec struct {
+	ImageSpec              `json:""`
+	ComponentDaemonSetSpec `json:""`
 }
 
 // SecondaryNetworkSpec describes configuration options for secondary network
@@ -198,11 +629,29 @@ type SecondaryNetworkSpec struct {
 	// Image and configuration information for multus
 	Multus *MultusSpec `json:"multus,omitempty"`
 	// Image information for CNI plugins
-	CniPlugins *ImageSpec `json:"cniPlugins,omitempty"`
+	CniPlugins *CNIPluginSpec `json:"cniPlugins,omitempty"`
 	// Image information for IPoIB CNI
-	IPoIB *ImageSpec `json:"ipoib,omitempty"`
-	// Image information for IPAM plugin
-	IpamPlugin *ImageSpec `json:"ipamPlugin,omitempty"`
+	IPoIB *CNIPluginSpec `json:"ipoib,omitempty"`
+	// Image information for OVS CNI
+	OVSCni *CNIPluginSpec `json:"ovsCni,omitempty"`
+	// Image information for RDMA CNI, which moves the RDMA devices to the network namespace of the pod
+	RdmaCni *CNIPluginSpec `json:"rdmaCni,omitempty"`
+	// Image and configuration information for IPAM plugin
+	IpamPlugin *WhereaboutsSpec `json:"ipamPlugin,omitempty"`
+}
+
+// WhereaboutsSpec describes configuration options for whereabouts IPAM plugin
+// 1. Image information for whereabouts
+// 2. Configuration of the IP reconciler and node slicing
+type WhereaboutsSpec struct {
+	ImageSpec              `json:""`
+	ComponentDaemonSetSpec `json:""`
+	// Cron expression of the IP reconciler schedule, the reconciler releases IPs of deleted pods
+	// +kubebuilder:default:="30 4 * * *"
+	ReconcilerCronExpression string `json:"reconcilerCronExpression,omitempty"`
+	// Enable deployment of the node slice controller which allocates a slice of the IP range to each node,
+	// requires whereabouts v0.8.0 or newer
+	EnableNodeSlicing bool `json:"enableNodeSlicing,omitempty"`
 }
 
 // ResourceRequirements describes the compute resource requirements.
@@ -225,6 +674,10 @@ type ResourceRequirements struct {
 type IBKubernetesSpec struct {
 	// Image information for ib-kubernetes
 	ImageSpec `json:""`
+	// PriorityClassName of the pods of the Deployment of ib-kubernetes, overrides the priorityClassName of the
+	// NicClusterPolicy when set
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
 	// Interval of updates in seconds
 	// +optional
 	// +kubebuilder:default:=5
@@ -236,6 +689,92 @@ type IBKubernetesSpec struct {
 	PKeyGUIDPoolRangeEnd string `json:"pKeyGUIDPoolRangeEnd,omitempty"`
 	// Secret containing credentials to UFM service
 	UfmSecret string `json:"ufmSecret,omitempty"`
+	// UfmTLS configures the TLS connection to the UFM service, the connection uses https when set
+	// +optional
+	UfmTLS *UFMTLSSpec `json:"ufmTLS,omitempty"`
+	// GarbageCollection of the GUIDs and PKeys allocated in UFM for the deleted pods and IPoIBNetworks, the GUIDs
+	// of the deleted pods are released and removed from their PKeys
+	// +optional
+	GarbageCollection *IBKubernetesGarbageCollectionSpec `json:"garbageCollection,omitempty"`
+}
+
+// IBKubernetesGarbageCollectionSpec configures the garbage collection of the GUIDs and PKeys of ib-kubernetes
+type IBKubernetesGarbageCollectionSpec struct {
+	// IntervalSeconds between the reconciliations of the GUIDs and PKeys in UFM with the pods and networks
+	// +kubebuilder:default:=300
+	// +kubebuilder:validation:Minimum:=1
+	// +optional
+	IntervalSeconds int `json:"intervalSeconds,omitempty"`
+}
+
+// UFMTLSVerificationMode is the verification of the certificate of the UFM service
+// +kubebuilder:validation:Enum={"Verify", "SkipVerify"}
+type UFMTLSVerificationMode string
+
+const (
+	// UFMTLSVerificationModeVerify verifies the certificate of UFM with the CA bundle, or with the system
+	// CAs if no CA bundle is set
+	UFMTLSVerificationModeVerify UFMTLSVerificationMode = "Verify"
+	// UFMTLSVerificationModeSkipVerify skips the verification of the certificate of UFM
+	UFMTLSVerificationModeSkipVerify UFMTLSVerificationMode = "SkipVerify"
+)
+
+// UFMTLSSpec configures the TLS connection of ib-kubernetes to the UFM service
+type UFMTLSSpec struct {
+	// CertificateSecret is the name of the kubernetes.io/tls Secret with the client certificate and key
+	// ib-kubernetes authenticates to UFM with
+	// +optional
+	CertificateSecret string `json:"certificateSecret,omitempty"`
+	// CABundle is the name of the ConfigMap with the CA bundle in its ca.crt key which verifies the certificate of UFM
+	// +optional
+	CABundle string `json:"caBundle,omitempty"`
+	// VerificationMode of the certificate of UFM, the caBundle can't be set with SkipVerify
+	// +kubebuilder:default:=Verify
+	// +optional
+	VerificationMode UFMTLSVerificationMode `json:"verificationMode,omitempty"`
+}
+
+// IPAMPlugin is an IPAM plugin deployed by the operator
+// +kubebuilder:validation:Enum={"whereabouts", "nv-ipam"}
+type IPAMPlugin string
+
+const (
+	// IPAMPluginWhereabouts is the whereabouts IPAM plugin of secondaryNetwork.ipamPlugin
+	IPAMPluginWhereabouts IPAMPlugin = "whereabouts"
+	// IPAMPluginNVIPAM is the NVIDIA IPAM plugin of nvIpam
+	IPAMPluginNVIPAM IPAMPlugin = "nv-ipam"
+)
+
+// CNIFlavor is a Kubernetes distribution with its own locations of the CNI binaries and configurations
+// +kubebuilder:validation:Enum={"kubernetes", "openshift", "k3s", "rke2", "microk8s"}
+type CNIFlavor string
+
+const (
+	// CNIFlavorKubernetes uses the standard /opt/cni/bin and /etc/cni/net.d directories
+	CNIFlavorKubernetes CNIFlavor = "kubernetes"
+	// CNIFlavorOpenshift uses the writable /var/lib/cni/bin directory of OpenShift
+	CNIFlavorOpenshift CNIFlavor = "openshift"
+	// CNIFlavorK3s uses the directories of the CNI bundled with k3s
+	CNIFlavorK3s CNIFlavor = "k3s"
+	// CNIFlavorRKE2 uses the directories of the CNI bundled with RKE2
+	CNIFlavorRKE2 CNIFlavor = "rke2"
+	// CNIFlavorMicroK8s uses the directories of the MicroK8s snap
+	CNIFlavorMicroK8s CNIFlavor = "microk8s"
+)
+
+// CNIDirectoriesSpec describes the locations of the CNI binaries and configurations on the nodes
+type CNIDirectoriesSpec struct {
+	// Flavor presets the directories for the Kubernetes distribution of the cluster
+	// +optional
+	Flavor CNIFlavor `json:"flavor,omitempty"`
+	// BinDirectory is the directory of the CNI binaries, it overrides the directory of the flavor
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	BinDirectory string `json:"binDirectory,omitempty"`
+	// ConfDirectory is the directory of the CNI configurations, it overrides the directory of the flavor
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	ConfDirectory string `json:"confDirectory,omitempty"`
 }
 
 // NVIPAMSpec describes configuration options for nv-ipam
@@ -243,13 +782,97 @@ type IBKubernetesSpec struct {
 // 2. Configuration for nv-ipam
 type NVIPAMSpec struct {
 	// Enable deployment of the validation webhook
-	EnableWebhook bool `json:"enableWebhook,omitempty"`
-	ImageSpec     `json:""`
+	EnableWebhook          bool `json:"enableWebhook,omitempty"`
+	ImageSpec              `json:""`
+	ComponentDaemonSetSpec `json:""`
+	// IP pools created by the operator in the namespace of the operator, pools which are removed from the list
+	// are deleted
+	// +optional
+	Pools []NVIPAMPoolSpec `json:"pools,omitempty"`
+	// CIDR pools created by the operator in the namespace of the operator, pools which are removed from the list
+	// are deleted. CIDR pools are supported by nv-ipam v0.2.0 or newer
+	// +optional
+	CIDRPools []NVIPAMCIDRPoolSpec `json:"cidrPools,omitempty"`
+}
+
+// NVIPAMCIDRPoolSpec describes an nv-ipam CIDRPool managed by the operator
+type NVIPAMCIDRPoolSpec struct {
+	// Name of the CIDRPool
+	Name string `json:"name"`
+	// CIDR of the pool, each node is allocated a network of the CIDR
+	CIDR string `json:"cidr"`
+	// Prefix length of the network allocated to each node
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=128
+	PerNodeNetworkPrefix int32 `json:"perNodeNetworkPrefix"`
+	// Index of the gateway IP in the network of each node, no gateway is set if not specified
+	// +optional
+	// +kubebuilder:validation:Minimum:=0
+	GatewayIndex *int32 `json:"gatewayIndex,omitempty"`
+	// IP ranges of the CIDR which are excluded from the allocation
+	// +optional
+	Exclusions []NVIPAMExcludeRangeSpec `json:"exclusions,omitempty"`
+	// Selects the nodes the pool is allocated to, all nodes are selected if not set
+	// +optional
+	NodeSelector *v1.NodeSelector `json:"nodeSelector,omitempty"`
 }
 
+// NVIPAMExcludeRangeSpec describes a range of IPs excluded from the allocation
+type NVIPAMExcludeRangeSpec struct {
+	// First IP of the range
+	StartIP string `json:"startIP"`
+	// Last IP of the range, inclusive
+	EndIP string `json:"endIP"`
+}
+
+// NVIPAMPoolSpec describes an nv-ipam IPPool managed by the operator
+type NVIPAMPoolSpec struct {
+	// Name of the IPPool
+	Name string `json:"name"`
+	// Subnet of the pool in CIDR notation
+	Subnet string `json:"subnet"`
+	// Amount of IPs allocated to each node, must be less than the amount of available IPs in the subnet
+	// +kubebuilder:validation:Minimum:=2
+	PerNodeBlockSize int `json:"perNodeBlockSize"`
+	// Gateway of the pool, must be an address in the subnet
+	Gateway string `json:"gateway"`
+	// Selects the nodes the pool is allocated to, all nodes are selected if not set
+	// +optional
+	NodeSelector *v1.NodeSelector `json:"nodeSelector,omitempty"`
+}
+
+// NICFeatureDiscoveryLabel is an extended feature of the NICs nic-feature-discovery labels the nodes with
+// +kubebuilder:validation:Enum={"firmware-version", "link-type", "port-speed", "dpu-mode"}
+type NICFeatureDiscoveryLabel string
+
+const (
+	// NICFeatureDiscoveryLabelFirmwareVersion labels the nodes with the firmware versions of the NICs
+	NICFeatureDiscoveryLabelFirmwareVersion NICFeatureDiscoveryLabel = "firmware-version"
+	// NICFeatureDiscoveryLabelLinkType labels the nodes with the link types of the ports of the NICs,
+	// i.e. Ethernet or InfiniBand
+	NICFeatureDiscoveryLabelLinkType NICFeatureDiscoveryLabel = "link-type"
+	// NICFeatureDiscoveryLabelPortSpeed labels the nodes with the speeds of the ports of the NICs
+	NICFeatureDiscoveryLabelPortSpeed NICFeatureDiscoveryLabel = "port-speed"
+	// NICFeatureDiscoveryLabelDPUMode labels the nodes with the mode of the BlueField DPUs
+	NICFeatureDiscoveryLabelDPUMode NICFeatureDiscoveryLabel = "dpu-mode"
+)
+
 // NICFeatureDiscoverySpec describes configuration options for nic-feature-discovery
 type NICFeatureDiscoverySpec struct {
-	ImageSpec `json:""`
+	ImageSpec              `json:""`
+	ComponentDaemonSetSpec `json:""`
+	// Labels are the extended features nic-feature-discovery labels the nodes with in addition to its
+	// default labels, e.g. firmware-version to schedule workloads on the nodes with a given firmware
+	// +listType=set
+	// +optional
+	Labels []NICFeatureDiscoveryLabel `json:"labels,omitempty"`
+}
+
+// NICConfigurationDaemonSpec describes configuration options for the NIC configuration daemon
+// which applies the NicConfigurationTemplates to the NICs of the nodes
+type NICConfigurationDaemonSpec struct {
+	ImageSpec              `json:""`
+	ComponentDaemonSetSpec `json:""`
 }
 
 // DOCATelemetryServiceConfig contains configuration for the DOCATelemetryService.
@@ -260,13 +883,120 @@ type DOCATelemetryServiceConfig struct {
 	FromConfigMap string `json:"fromConfigMap"`
 }
 
+// DOCATelemetryServicePrometheusSpec configures the Prometheus exporter of the DOCATelemetryService.
+type DOCATelemetryServicePrometheusSpec struct {
+	// Port of the Prometheus endpoint on the nodes
+	// +optional
+	// +kubebuilder:default:=9189
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=65535
+	Port int `json:"port,omitempty"`
+	// IgnoreCounters is a list of counter names which are not exported to Prometheus
+	// +optional
+	IgnoreCounters []string `json:"ignoreCounters,omitempty"`
+}
+
 // DOCATelemetryServiceSpec is the configuration for DOCA Telemetry Service.
 type DOCATelemetryServiceSpec struct {
-	ImageSpec `json:""`
+	ImageSpec              `json:""`
+	ComponentDaemonSetSpec `json:""`
 	// +optional
 	// Config contains custom config for the DOCATelemetryService.
 	// If set no default config will be deployed.
 	Config *DOCATelemetryServiceConfig `json:"config"`
+	// Providers are the counter providers enabled in the default config, e.g. sysfs, ethtool.
+	// If not set, the sysfs, pod_resources, ethtool and ifconfig providers are enabled.
+	// Ignored if Config is set.
+	// +optional
+	Providers []string `json:"providers,omitempty"`
+	// Prometheus configures the Prometheus exporter in the default config.
+	// Ignored if Config is set.
+	// +optional
+	Prometheus *DOCATelemetryServicePrometheusSpec `json:"prometheus,omitempty"`
+}
+
+// MachineConfigSpec describes the OpenShift MachineConfig which loads kernel modules on boot of the nodes
+// of a MachineConfigPool. Applying the MachineConfig reboots the nodes of the pool.
+type MachineConfigSpec struct {
+	// Role of the MachineConfigPool the MachineConfig is applied to
+	// +kubebuilder:default:=worker
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	Role string `json:"role,omitempty"`
+	// KernelModules loaded on boot, e.g. ib_umad
+	// +kubebuilder:validation:MinItems=1
+	KernelModules []string `json:"kernelModules"`
+}
+
+// NodeFeatureRulesSpec configures the NodeFeatureRule of Node Feature Discovery which labels the nodes
+// with Mellanox NICs with feature.node.kubernetes.io/pci-15b3.present
+type NodeFeatureRulesSpec struct {
+	// PCIClasses of the Mellanox PCI devices which are detected, e.g. 0200 for Ethernet and 0207 for InfiniBand
+	// +kubebuilder:default:={"0200","0207"}
+	// +optional
+	PCIClasses []string `json:"pciClasses,omitempty"`
+}
+
+// NodeFeatureDiscoverySpec describes the Node Feature Discovery deployed by the operator, i.e. the nfd-master
+// Deployment and the nfd-worker DaemonSet, for the installations without the node-feature-discovery Helm chart
+type NodeFeatureDiscoverySpec struct {
+	// Image of Node Feature Discovery run by the master and the workers, its version pins the release of NFD.
+	// v0.13.0 or newer is required as the NodeFeature API is used.
+	ImageSpec              `json:""`
+	ComponentDaemonSetSpec `json:""`
+	// ExtraLabelNs are the namespaces, in addition to the NFD ones, of the labels nfd-master sets on the nodes
+	// +kubebuilder:default:={"nvidia.com"}
+	// +listType=set
+	// +optional
+	ExtraLabelNs []string `json:"extraLabelNs,omitempty"`
+	// PCIClasses of the PCI devices nfd-worker labels the nodes with, e.g. 0200 for Ethernet and 0207 for InfiniBand
+	// +kubebuilder:default:={"0200","0207","0300","0302"}
+	// +optional
+	PCIClasses []string `json:"pciClasses,omitempty"`
+}
+
+// AdditionalManifestsSpec references the raw manifests applied along with the components of the policy
+type AdditionalManifestsSpec struct {
+	// ConfigMapName is the name of the ConfigMap in the namespace of the Operator holding the manifests,
+	// each key of the ConfigMap holds YAML or JSON objects, several YAML objects are separated with ---
+	// +kubebuilder:validation:MinLength=1
+	ConfigMapName string `json:"configMapName"`
+}
+
+// RawPatchType is the type of a RawPatch
+// +kubebuilder:validation:Enum={"StrategicMerge", "JSON6902"}
+type RawPatchType string
+
+const (
+	// RawPatchTypeStrategicMerge is a strategic merge patch, for kinds not known to the operator
+	// it is applied as a JSON merge patch
+	RawPatchTypeStrategicMerge RawPatchType = "StrategicMerge"
+	// RawPatchTypeJSON6902 is a JSON patch as defined in RFC 6902
+	RawPatchTypeJSON6902 RawPatchType = "JSON6902"
+)
+
+// RawPatchTarget selects the rendered objects a RawPatch is applied to
+type RawPatchTarget struct {
+	// Kind of the objects to patch
+	Kind string `json:"kind"`
+	// Name of the object to patch, if empty objects of any name are patched
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Namespace of the object to patch, if empty objects of any namespace are patched
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// RawPatch describes a patch applied to the objects rendered by the operator before they are created or updated
+type RawPatch struct {
+	// Target selects the objects to patch
+	Target RawPatchTarget `json:"target"`
+	// Type of the patch
+	// +optional
+	// +kubebuilder:default:="StrategicMerge"
+	Type RawPatchType `json:"type,omitempty"`
+	// Patch to apply, in YAML or JSON format
+	Patch string `json:"patch"`
 }
 
 // NicClusterPolicySpec defines the desired state of NicClusterPolicy
@@ -274,16 +1004,63 @@ type NicClusterPolicySpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	NodeAffinity           *v1.NodeAffinity          `json:"nodeAffinity,omitempty"`
-	Tolerations            []v1.Toleration           `json:"tolerations,omitempty"`
-	OFEDDriver             *OFEDDriverSpec           `json:"ofedDriver,omitempty"`
-	RdmaSharedDevicePlugin *DevicePluginSpec         `json:"rdmaSharedDevicePlugin,omitempty"`
-	SriovDevicePlugin      *DevicePluginSpec         `json:"sriovDevicePlugin,omitempty"`
-	IBKubernetes           *IBKubernetesSpec         `json:"ibKubernetes,omitempty"`
-	SecondaryNetwork       *SecondaryNetworkSpec     `json:"secondaryNetwork,omitempty"`
-	NvIpam                 *NVIPAMSpec               `json:"nvIpam,omitempty"`
-	NicFeatureDiscovery    *NICFeatureDiscoverySpec  `json:"nicFeatureDiscovery,omitempty"`
-	DOCATelemetryService   *DOCATelemetryServiceSpec `json:"docaTelemetryService,omitempty"`
+	NodeAffinity           *v1.NodeAffinity            `json:"nodeAffinity,omitempty"`
+	Tolerations            []v1.Toleration             `json:"tolerations,omitempty"`
+	OFEDDriver             *OFEDDriverSpec             `json:"ofedDriver,omitempty"`
+	RdmaSharedDevicePlugin *DevicePluginSpec           `json:"rdmaSharedDevicePlugin,omitempty"`
+	SriovDevicePlugin      *DevicePluginSpec           `json:"sriovDevicePlugin,omitempty"`
+	IBKubernetes           *IBKubernetesSpec           `json:"ibKubernetes,omitempty"`
+	SecondaryNetwork       *SecondaryNetworkSpec       `json:"secondaryNetwork,omitempty"`
+	NvIpam                 *NVIPAMSpec                 `json:"nvIpam,omitempty"`
+	NicFeatureDiscovery    *NICFeatureDiscoverySpec    `json:"nicFeatureDiscovery,omitempty"`
+	DOCATelemetryService   *DOCATelemetryServiceSpec   `json:"docaTelemetryService,omitempty"`
+	NicConfigurationDaemon *NICConfigurationDaemonSpec `json:"nicConfigurationDaemon,omitempty"`
+	// RawPatches are applied in order to the objects rendered for the NicClusterPolicy before they are
+	// created or updated
+	// +optional
+	RawPatches []RawPatch `json:"rawPatches,omitempty"`
+	// NodeSelector restricts the DaemonSets of the policy to the nodes with matching labels. Several policies
+	// with non-overlapping node selectors can deploy different components to different nodes, e.g. to the
+	// InfiniBand and to the Ethernet nodes of a cluster.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// ImagePullSecrets added to the image pull secrets of every component, so that a secret of the registry
+	// doesn't have to be repeated in the image spec of each component
+	// +optional
+	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
+	// Proxy settings passed as HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables to the OFED driver
+	// and the DOCA Telemetry Service, on Openshift they take precedence over the cluster-wide Proxy
+	// +optional
+	Proxy *ProxySpec `json:"proxy,omitempty"`
+	// MachineConfig loads kernel modules on the nodes of an OpenShift cluster, ignored on other clusters.
+	// The SecurityContextConstraints of the components are deployed on OpenShift regardless.
+	// +optional
+	MachineConfig *MachineConfigSpec `json:"machineConfig,omitempty"`
+	// NodeFeatureRules deploys the NodeFeatureRule objects which detect the Mellanox NICs, instead of relying on
+	// the configuration of the NFD worker. Requires Node Feature Discovery v0.13 or newer.
+	// +optional
+	NodeFeatureRules *NodeFeatureRulesSpec `json:"nodeFeatureRules,omitempty"`
+	// NodeFeatureDiscovery deploys Node Feature Discovery with the operator, for the installations where it is not
+	// deployed by the Helm chart of the operator, e.g. with OLM. The NFD CRDs are deployed with it.
+	// +optional
+	NodeFeatureDiscovery *NodeFeatureDiscoverySpec `json:"nodeFeatureDiscovery,omitempty"`
+	// PriorityClassName of the pods of the DaemonSets and Deployments of every component, e.g. system-node-critical
+	// so that the network infrastructure pods are not evicted under node pressure. The priorityClassName of a
+	// component overrides it.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// AdditionalManifests are applied as is, with the policy as their owner, for small resources the components
+	// don't deploy, e.g. NetworkPolicies or PodMonitors. Objects removed from the manifests are deleted.
+	// +optional
+	AdditionalManifests *AdditionalManifestsSpec `json:"additionalManifests,omitempty"`
+	// DefaultIPAM is the IPAM plugin the networks use by default, it must be one of the deployed IPAM plugins
+	// and is required if both secondaryNetwork.ipamPlugin and nvIpam are deployed
+	// +optional
+	DefaultIPAM IPAMPlugin `json:"defaultIpam,omitempty"`
+	// CNIDirectories are the locations of the CNI binaries and configurations on the nodes used by the components
+	// installing a CNI plugin, by default they are detected from the cluster type
+	// +optional
+	CNIDirectories *CNIDirectoriesSpec `json:"cniDirectories,omitempty"`
 }
 
 // AppliedState defines a finer-grained view of the observed state of NicClusterPolicy
@@ -291,6 +1068,94 @@ type AppliedState struct {
 	Name string `json:"name"`
 	// +kubebuilder:validation:Enum={"ready", "notReady", "ignore", "error"}
 	State State `json:"state"`
+	// Message is the rollout progress of the DaemonSets of the state, e.g. "45/50 nodes ready"
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// NodeStatus defines the observed state of the NicClusterPolicy components on a node
+type NodeStatus struct {
+	// Name of the node
+	Name string `json:"name"`
+	// OFEDDriverLoaded is true if the OFED driver container is ready on the node
+	// +optional
+	OFEDDriverLoaded bool `json:"ofedDriverLoaded,omitempty"`
+	// OFEDDriverVersion is the version of the OFED driver container running on the node
+	// +optional
+	OFEDDriverVersion string `json:"ofedDriverVersion,omitempty"`
+	// Resources are the device plugin resources advertised in the allocatable resources of the node
+	// +optional
+	Resources []string `json:"resources,omitempty"`
+}
+
+// OFEDDriverUpgradeStatus describes the progress of the OFED driver upgrade
+type OFEDDriverUpgradeStatus struct {
+	// TotalNodes is the number of nodes with the OFED driver
+	TotalNodes int `json:"totalNodes"`
+	// UpgradedNodes is the number of nodes on which the OFED driver is up to date
+	UpgradedNodes int `json:"upgradedNodes"`
+	// PendingNodes is the number of nodes waiting for their upgrade to start
+	PendingNodes int `json:"pendingNodes"`
+	// InProgressNodes is the number of nodes on which the upgrade is in progress, including the draining nodes
+	InProgressNodes int `json:"inProgressNodes"`
+	// DrainingNodes is the number of nodes being drained
+	DrainingNodes int `json:"drainingNodes"`
+	// FailedNodes is the number of nodes on which the upgrade failed
+	FailedNodes int `json:"failedNodes"`
+	// StartTime is the time the upgrade started, it is not set if no upgrade is in progress
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// EstimatedCompletionTime is the time the upgrade of the pending and in progress nodes is expected
+	// to complete, according to the average time it took to upgrade the nodes since the start of the upgrade
+	// +optional
+	EstimatedCompletionTime *metav1.Time `json:"estimatedCompletionTime,omitempty"`
+	// Drain aggregates the drain progress reported by the nodes, it is not set if no node is drained
+	// +optional
+	Drain *OFEDDriverDrainStatus `json:"drain,omitempty"`
+}
+
+// OFEDDriverDrainStatus aggregates the drain progress the nodes report in the
+// nvidia.com/ofed-driver-upgrade.drain-progress annotation during the OFED driver upgrade
+type OFEDDriverDrainStatus struct {
+	// DrainingNodes is the number of nodes being drained
+	DrainingNodes int `json:"drainingNodes"`
+	// DrainedNodes is the number of nodes past the drain step of the upgrade which are not uncordoned yet
+	DrainedNodes int `json:"drainedNodes"`
+	// FailedNodes is the number of nodes whose drain failed
+	FailedNodes int `json:"failedNodes"`
+	// PodsRemaining is the number of pods which are yet to be evicted from the draining nodes
+	PodsRemaining int `json:"podsRemaining"`
+	// Nodes is the drain progress of the draining nodes and of the nodes whose drain failed, sorted by name
+	// +optional
+	Nodes []NodeDrainStatus `json:"nodes,omitempty"`
+}
+
+// NodeDrainStatus describes the drain progress of a node
+type NodeDrainStatus struct {
+	// Name of the node
+	Name string `json:"name"`
+	// StartTime is the time the drain of the node started
+	StartTime metav1.Time `json:"startTime"`
+	// PodsEvicted is the number of pods evicted or deleted from the node
+	PodsEvicted int `json:"podsEvicted"`
+	// PodsRemaining is the number of pods which are yet to be evicted from the node
+	PodsRemaining int `json:"podsRemaining"`
+	// Attempt is the number of the current drain attempt, starting from 1
+	Attempt int `json:"attempt"`
+	// LastError is the last error which occurred during the drain
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+}
+
+// OFEDDriverRollbackStatus describes the automatic rollback of the OFED driver version
+type OFEDDriverRollbackStatus struct {
+	// FailedVersion is the OFED driver version which failed to upgrade, the rollback applies
+	// as long as this version is set in spec.ofedDriver.version
+	FailedVersion string `json:"failedVersion"`
+	// Version is the OFED driver version deployed instead of the failed version
+	Version string `json:"version"`
+	// Reason describes why the version was rolled back
+	Reason string `json:"reason"`
 }
 
 // NicClusterPolicyStatus defines the observed state of NicClusterPolicy
@@ -305,12 +1170,35 @@ type NicClusterPolicyStatus struct {
 	Reason string `json:"reason,omitempty"`
 	// AppliedStates provide a finer view of the observed state
 	AppliedStates []AppliedState `json:"appliedStates,omitempty"`
+	// OFEDDriverVersion is the OFED driver version resolved from the version channel
+	// set in spec.ofedDriver.version, e.g. latest-24.04
+	// +optional
+	OFEDDriverVersion string `json:"ofedDriverVersion,omitempty"`
+	// OFEDDriverUpgrade reports the progress of the OFED driver upgrade, it is set when autoUpgrade is enabled
+	// +optional
+	OFEDDriverUpgrade *OFEDDriverUpgradeStatus `json:"ofedDriverUpgrade,omitempty"`
+	// OFEDDriverRollback is set when the OFED driver version was rolled back after the upgrade failed
+	// on more nodes than upgradePolicy.failureThreshold
+	// +optional
+	OFEDDriverRollback *OFEDDriverRollbackStatus `json:"ofedDriverRollback,omitempty"`
+	// Conditions provide a per-state view of the observed state, with a condition per state
+	// (type is the state name) and an aggregated Ready condition
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Nodes provide a per-node view of the OFED driver and device plugins on the nodes with NVIDIA NICs
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Nodes []NodeStatus `json:"nodes,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:object:generate=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.state`,priority=0
 // +kubebuilder:printcolumn:name="Age",type=string,JSONPath=`.metadata.creationTimestamp`,priority=0
 
@@ -0,0 +1,128 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// NicConfigurationTemplateCRDName is used for the CRD Kind.
+	NicConfigurationTemplateCRDName = "NicConfigurationTemplate"
+)
+
+const (
+	// LinkTypeEthernet configures the ports of the NIC to Ethernet
+	LinkTypeEthernet = "Ethernet"
+	// LinkTypeInfiniband configures the ports of the NIC to InfiniBand
+	LinkTypeInfiniband = "Infiniband"
+)
+
+const (
+	// NicConfigurationStatePending is set when the configuration was not applied to the node yet
+	NicConfigurationStatePending = "pending"
+	// NicConfigurationStatePendingReboot is set when the configuration was applied to the NICs of the node
+	// but some of the parameters take effect only after the reboot of the node
+	NicConfigurationStatePendingReboot = "pending-reboot"
+	// NicConfigurationStateApplied is set when the configuration is in effect on the node
+	NicConfigurationStateApplied = "applied"
+	// NicConfigurationStateError is set when the configuration could not be applied to the node
+	NicConfigurationStateError = "error"
+)
+
+// ConfigurationTemplateSpec describes the mlxconfig settings applied to the NICs
+type ConfigurationTemplateSpec struct {
+	// NumVfs is the number of SR-IOV virtual functions of each NIC, 0 disables SR-IOV
+	// +kubebuilder:validation:Minimum:=0
+	NumVfs int `json:"numVfs"`
+	// LinkType is the link type of all the ports of the NICs, the link type is not changed if not set
+	// +optional
+	// +kubebuilder:validation:Enum={"Ethernet", "Infiniband"}
+	LinkType string `json:"linkType,omitempty"`
+	// PciRelaxedOrdering forces the relaxed ordering of the PCI writes of the NICs,
+	// the setting is not changed if not set
+	// +optional
+	PciRelaxedOrdering *bool `json:"pciRelaxedOrdering,omitempty"`
+}
+
+// NicConfigurationTemplateSpec defines the desired state of NicConfigurationTemplate
+type NicConfigurationTemplateSpec struct {
+	// NodeSelector selects the nodes to configure, only the nodes with Mellanox NICs are selected
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// NicType is the PCI device ID of the NICs to configure, e.g. 101b
+	// +kubebuilder:validation:Pattern=`^[0-9a-fA-F]{4}$`
+	NicType string `json:"nicType"`
+	// Template is the configuration applied to the selected NICs
+	Template ConfigurationTemplateSpec `json:"template"`
+}
+
+// NicConfigurationNodeStatus describes the configuration state of the NICs of a node
+type NicConfigurationNodeStatus struct {
+	// Name of the node
+	Name string `json:"name"`
+	// State of the configuration of the node
+	// +kubebuilder:validation:Enum={"pending", "pending-reboot", "applied", "error"}
+	State string `json:"state"`
+	// PendingRebootParameters are the mlxconfig parameters which take effect after the reboot of the node
+	// +optional
+	PendingRebootParameters []string `json:"pendingRebootParameters,omitempty"`
+	// Informative string in case the state is error
+	Reason string `json:"reason,omitempty"`
+}
+
+// NicConfigurationTemplateStatus defines the observed state of NicConfigurationTemplate
+type NicConfigurationTemplateStatus struct {
+	// Reflects the state of the NicConfigurationTemplate
+	// +kubebuilder:validation:Enum={"notReady", "ready", "error"}
+	State State `json:"state"`
+	// Informative string in case the observed state is error
+	Reason string `json:"reason,omitempty"`
+	// Nodes reports the configuration state of the nodes selected by the template
+	// +optional
+	Nodes []NicConfigurationNodeStatus `json:"nodes,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:object:generate=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.state`,priority=0
+// +kubebuilder:printcolumn:name="Age",type=string,JSONPath=`.metadata.creationTimestamp`,priority=0
+
+// NicConfigurationTemplate is the Schema for the nicconfigurationtemplates API
+type NicConfigurationTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NicConfigurationTemplateSpec   `json:"spec,omitempty"`
+	Status NicConfigurationTemplateStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:object:generate=true
+
+// NicConfigurationTemplateList contains a list of NicConfigurationTemplate
+type NicConfigurationTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NicConfigurationTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NicConfigurationTemplate{}, &NicConfigurationTemplateList{})
+}
@@ -0,0 +1,105 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// NicFirmwarePolicyCRDName is used for the CRD Kind.
+	NicFirmwarePolicyCRDName = "NicFirmwarePolicy"
+)
+
+// NicFirmwarePolicySpec defines the desired state of NicFirmwarePolicy
+type NicFirmwarePolicySpec struct {
+	// NodeSelector selects the nodes whose NICs are managed by the policy,
+	// only the nodes with Mellanox NICs are selected
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// FirmwareVersion is the desired firmware version of the NICs, e.g. 28.39.1002
+	// +kubebuilder:validation:Pattern=`^[0-9]+\.[0-9]+\.[0-9]+$`
+	FirmwareVersion string `json:"firmwareVersion"`
+	// Image information of the firmware update container, the container runs mlxfwmanager to update
+	// the NICs of the node to the firmware version in the FW_VERSION environment variable
+	ImageSpec `json:""`
+	// MaxParallelUpgrades indicates how many nodes can be upgraded in parallel
+	// 0 means no limit, all nodes will be upgraded in parallel
+	// +optional
+	// +kubebuilder:default:=1
+	// +kubebuilder:validation:Minimum:=0
+	MaxParallelUpgrades int `json:"maxParallelUpgrades,omitempty"`
+	// DrainSpec describes the drain of the node before the firmware update, the node is always drained
+	// +optional
+	DrainSpec *DrainSpec `json:"drain,omitempty"`
+	// Reboot describes the reboot of the node which activates the new firmware
+	// +optional
+	Reboot *RebootSpec `json:"reboot,omitempty"`
+}
+
+// NicFirmwareNodeStatus describes the firmware of the NICs of a node
+type NicFirmwareNodeStatus struct {
+	// Name of the node
+	Name string `json:"name"`
+	// FirmwareVersion is the firmware version of the NICs discovered on the node
+	FirmwareVersion string `json:"firmwareVersion,omitempty"`
+	// UpgradeState is the state of the firmware upgrade of the node
+	UpgradeState string `json:"upgradeState,omitempty"`
+}
+
+// NicFirmwarePolicyStatus defines the observed state of NicFirmwarePolicy
+type NicFirmwarePolicyStatus struct {
+	// Reflects the state of the NicFirmwarePolicy
+	// +kubebuilder:validation:Enum={"notReady", "ready", "error"}
+	State State `json:"state"`
+	// Informative string in case the observed state is error
+	Reason string `json:"reason,omitempty"`
+	// Nodes reports the firmware of the nodes selected by the policy
+	// +optional
+	Nodes []NicFirmwareNodeStatus `json:"nodes,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:object:generate=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Firmware",type=string,JSONPath=`.spec.firmwareVersion`,priority=0
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.state`,priority=0
+// +kubebuilder:printcolumn:name="Age",type=string,JSONPath=`.metadata.creationTimestamp`,priority=0
+
+// NicFirmwarePolicy is the Schema for the nicfirmwarepolicies API
+type NicFirmwarePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NicFirmwarePolicySpec   `json:"spec,omitempty"`
+	Status NicFirmwarePolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:object:generate=true
+
+// NicFirmwarePolicyList contains a list of NicFirmwarePolicy
+type NicFirmwarePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NicFirmwarePolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NicFirmwarePolicy{}, &NicFirmwarePolicyList{})
+}
@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	"fmt"
+	"slices"
 
 	upgradeApi "github.com/NVIDIA/k8s-operator-libs/api/upgrade/v1alpha1"
 
@@ -35,6 +36,7 @@ func GetDriverUpgradePolicy(
 
 	driverUpgradePolicy.AutoUpgrade = ofedUpgradePolicy.AutoUpgrade
 	driverUpgradePolicy.MaxParallelUpgrades = ofedUpgradePolicy.MaxParallelUpgrades
+	driverUpgradePolicy.MaxUnavailable = ofedUpgradePolicy.MaxUnavailable
 
 	driverUpgradePolicy.PodDeletion = nil
 	driverUpgradePolicy.WaitForCompletion = getWaitForCompletionSpec(ofedUpgradePolicy.WaitForCompletion)
@@ -45,18 +47,47 @@ func GetDriverUpgradePolicy(
 		// if the operator is evicted and can't be rescheduled to any other node, e.g. in a single-node cluster.
 		// It's safe to do because the goal of the node draining during the upgrade is to
 		// evict pods that might use driver and operator doesn't use in its own pod.
-		if driverUpgradePolicy.DrainSpec.PodSelector == "" {
-			driverUpgradePolicy.DrainSpec.PodSelector = consts.OfedDriverSkipDrainLabelSelector
-		} else {
-			driverUpgradePolicy.DrainSpec.PodSelector =
-				fmt.Sprintf("%s,%s", driverUpgradePolicy.DrainSpec.PodSelector,
-					consts.OfedDriverSkipDrainLabelSelector)
-		}
+		skipOperatorDrain(driverUpgradePolicy.DrainSpec)
 	}
 
 	return &driverUpgradePolicy
 }
 
+// MergeImagePullSecrets returns the image pull secrets of the NicClusterPolicy followed by the image pull secrets
+// of the component which are not in the list yet
+func MergeImagePullSecrets(policySecrets, componentSecrets []string) []string {
+	if len(policySecrets) == 0 {
+		return componentSecrets
+	}
+	secrets := append(make([]string, 0, len(policySecrets)+len(componentSecrets)), policySecrets...)
+	for _, secret := range componentSecrets {
+		if !slices.Contains(secrets, secret) {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
+// GetFirmwareDrainSpec gets the DrainSpec for the NIC firmware upgrade, the node is always drained
+// before the firmware update
+func GetFirmwareDrainSpec(drainSpec *DrainSpec) *upgradeApi.DrainSpec {
+	spec := getDrainSpec(drainSpec)
+	if spec == nil {
+		spec = &upgradeApi.DrainSpec{DeleteEmptyDir: true}
+	}
+	spec.Enable = true
+	skipOperatorDrain(spec)
+	return spec
+}
+
+func skipOperatorDrain(drainSpec *upgradeApi.DrainSpec) {
+	if drainSpec.PodSelector == "" {
+		drainSpec.PodSelector = consts.OfedDriverSkipDrainLabelSelector
+	} else {
+		drainSpec.PodSelector = fmt.Sprintf("%s,%s", drainSpec.PodSelector, consts.OfedDriverSkipDrainLabelSelector)
+	}
+}
+
 func getWaitForCompletionSpec(
 	waitForCompletionSpec *WaitForCompletionSpec) *upgradeApi.WaitForCompletionSpec {
 	if waitForCompletionSpec == nil {
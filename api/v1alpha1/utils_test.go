@@ -18,6 +18,10 @@ package v1alpha1 //nolint:dupl
 import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/Mellanox/network-operator/pkg/consts"
 )
 
 //nolint:dupl
@@ -42,6 +46,16 @@ var _ = Describe("API utils tests", func() {
 			Expect(result.WaitForCompletion).To(BeNil())
 			Expect(result.DrainSpec).To(BeNil())
 		})
+
+		It("should retrieve MaxUnavailable", func() {
+			maxUnavailable := intstr.FromString("25%")
+			input := &DriverUpgradePolicySpec{
+				AutoUpgrade:    true,
+				MaxUnavailable: &maxUnavailable,
+			}
+			result := GetDriverUpgradePolicy(input)
+			Expect(result.MaxUnavailable).To(Equal(&maxUnavailable))
+		})
 	})
 
 	Context("getWaitForCompletionSpec tests", func() {
@@ -85,4 +99,32 @@ var _ = Describe("API utils tests", func() {
 			Expect(result.DeleteEmptyDir).To(Equal(input.DeleteEmptyDir))
 		})
 	})
+
+	Context("GetFirmwareDrainSpec tests", func() {
+		It("should always enable the drain", func() {
+			result := GetFirmwareDrainSpec(nil)
+			Expect(result.Enable).To(BeTrue())
+			Expect(result.DeleteEmptyDir).To(BeTrue())
+			Expect(result.PodSelector).To(Equal(consts.OfedDriverSkipDrainLabelSelector))
+
+			result = GetFirmwareDrainSpec(&DrainSpec{Enable: false, PodSelector: "app=myapp", TimeoutSecond: 300})
+			Expect(result.Enable).To(BeTrue())
+			Expect(result.TimeoutSecond).To(Equal(300))
+			Expect(result.PodSelector).To(Equal("app=myapp," + consts.OfedDriverSkipDrainLabelSelector))
+		})
+	})
+
+	Context("MergeImagePullSecrets tests", func() {
+		It("should return the secrets of the component when the policy has none", func() {
+			Expect(MergeImagePullSecrets(nil, []string{"component"})).To(Equal([]string{"component"}))
+			Expect(MergeImagePullSecrets(nil, nil)).To(BeNil())
+		})
+
+		It("should add the secrets of the component after the secrets of the policy", func() {
+			policySecrets := []string{"registry", "shared"}
+			result := MergeImagePullSecrets(policySecrets, []string{"shared", "component"})
+			Expect(result).To(Equal([]string{"registry", "shared", "component"}))
+			Expect(policySecrets).To(Equal([]string{"registry", "shared"}))
+		})
+	})
 })
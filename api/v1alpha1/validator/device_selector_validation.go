@@ -0,0 +1,146 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+const mellanoxVendorID = "15b3"
+
+// mellanoxDeviceIDs are the PCI device IDs of the NVIDIA (Mellanox) NICs and DPUs, including their VFs
+var mellanoxDeviceIDs = sets.New(
+	"1013", // ConnectX-4
+	"1014", // ConnectX-4 VF
+	"1015", // ConnectX-4 Lx
+	"1016", // ConnectX-4 Lx VF
+	"1017", // ConnectX-5
+	"1018", // ConnectX-5 VF
+	"1019", // ConnectX-5 Ex
+	"101a", // ConnectX-5 Ex VF
+	"101b", // ConnectX-6
+	"101c", // ConnectX-6 VF
+	"101d", // ConnectX-6 Dx
+	"101e", // ConnectX Family VF
+	"101f", // ConnectX-6 Lx
+	"1021", // ConnectX-7
+	"1023", // ConnectX-8
+	"a2d2", // BlueField
+	"a2d3", // BlueField VF
+	"a2d6", // BlueField-2
+	"a2dc", // BlueField-3
+)
+
+// deviceSelector contains the vendor and device selectors of a device plugin resource,
+// devices is used by the SR-IOV device plugin and deviceIDs by the RDMA shared device plugin
+type deviceSelector struct {
+	Vendors   []string `json:"vendors"`
+	Devices   []string `json:"devices"`
+	DeviceIDs []string `json:"deviceIDs"`
+}
+
+// deviceSelectorResource is a resource of a device plugin config with its selectors,
+// selectors is either a single selector object or a list of selector objects
+type deviceSelectorResource struct {
+	ResourceName string          `json:"resourceName"`
+	DeviceType   string          `json:"deviceType"`
+	Selectors    json.RawMessage `json:"selectors"`
+}
+
+// getDeviceSelectorWarnings warns about device plugin resources whose selectors can't match NVIDIA devices,
+// i.e. vendors which don't include 15b3 or unknown device IDs. The check is skipped if the NicClusterPolicy
+// has the consts.SkipDeviceSelectorValidationAnnotation annotation set to "true".
+func getDeviceSelectorWarnings(in *v1alpha1.NicClusterPolicy) admission.Warnings {
+	if in.Annotations[consts.SkipDeviceSelectorValidationAnnotation] == "true" {
+		return nil
+	}
+	var warnings admission.Warnings
+	if in.Spec.RdmaSharedDevicePlugin != nil && in.Spec.RdmaSharedDevicePlugin.Config != nil {
+		warnings = append(warnings, getDevicePluginSelectorWarnings(
+			"spec.rdmaSharedDevicePlugin", *in.Spec.RdmaSharedDevicePlugin.Config)...)
+	}
+	if in.Spec.SriovDevicePlugin != nil && in.Spec.SriovDevicePlugin.Config != nil {
+		warnings = append(warnings, getDevicePluginSelectorWarnings(
+			"spec.sriovNetworkDevicePlugin", *in.Spec.SriovDevicePlugin.Config)...)
+	}
+	return warnings
+}
+
+// getDevicePluginSelectorWarnings returns the warnings for the resources of a device plugin config,
+// configs which are not valid are skipped as they are rejected by the validation of the device plugin
+func getDevicePluginSelectorWarnings(path, config string) admission.Warnings {
+	var dpConfig struct {
+		ResourceList []deviceSelectorResource `json:"resourceList"`
+		ConfigList   []deviceSelectorResource `json:"configList"`
+	}
+	if err := json.Unmarshal([]byte(config), &dpConfig); err != nil {
+		return nil
+	}
+	var warnings admission.Warnings
+	for _, resource := range append(dpConfig.ResourceList, dpConfig.ConfigList...) {
+		// accelerators are not necessarily NVIDIA devices
+		if resource.DeviceType == "accelerator" || len(resource.Selectors) == 0 {
+			continue
+		}
+		var selectors []deviceSelector
+		if err := json.Unmarshal(resource.Selectors, &selectors); err != nil {
+			selector := deviceSelector{}
+			if err := json.Unmarshal(resource.Selectors, &selector); err != nil {
+				continue
+			}
+			selectors = []deviceSelector{selector}
+		}
+		for _, selector := range selectors {
+			if len(selector.Vendors) > 0 && !containsID(selector.Vendors, mellanoxVendorID) {
+				warnings = append(warnings, fmt.Sprintf(
+					"%s: selectors of resource %s don't include the NVIDIA vendor ID %s, vendors: %v",
+					path, resource.ResourceName, mellanoxVendorID, selector.Vendors))
+				continue
+			}
+			var unknown []string
+			for _, id := range append(selector.Devices, selector.DeviceIDs...) {
+				if !mellanoxDeviceIDs.Has(strings.ToLower(id)) {
+					unknown = append(unknown, id)
+				}
+			}
+			if len(unknown) > 0 {
+				warnings = append(warnings, fmt.Sprintf(
+					"%s: selectors of resource %s include device IDs which are not known NVIDIA devices: %v",
+					path, resource.ResourceName, unknown))
+			}
+		}
+	}
+	return warnings
+}
+
+// containsID returns true if ids contains id, ignoring the case
+func containsID(ids []string, id string) bool {
+	for _, i := range ids {
+		if strings.EqualFold(i, id) {
+			return true
+		}
+	}
+	return false
+}
@@ -19,14 +19,17 @@ package validator
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
-	"regexp"
+	"fmt"
 
+	"github.com/xeipuuv/gojsonschema"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -34,10 +37,15 @@ import (
 	"github.com/Mellanox/network-operator/api/v1alpha1"
 )
 
+// defaultResourcePrefix is the prefix of the device plugin resources used by HostDeviceNetwork
+const defaultResourcePrefix = "nvidia.com"
+
 // log is for logging in this package.
 var hostDeviceNetworkLog = logf.Log.WithName("hostdevicenetwork-resource")
 
-type hostDeviceNetworkValidator struct{}
+type hostDeviceNetworkValidator struct {
+	client client.Reader
+}
 
 var _ webhook.CustomValidator = &hostDeviceNetworkValidator{}
 
@@ -45,7 +53,7 @@ var _ webhook.CustomValidator = &hostDeviceNetworkValidator{}
 func SetupHostDeviceNetworkWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&v1alpha1.HostDeviceNetwork{}).
-		WithValidator(&hostDeviceNetworkValidator{}).
+		WithValidator(&hostDeviceNetworkValidator{client: mgr.GetClient()}).
 		Complete()
 }
 
@@ -53,7 +61,8 @@ func SetupHostDeviceNetworkWebhookWithManager(mgr ctrl.Manager) error {
 //+kubebuilder:webhook:path=/validate-mellanox-com-v1alpha1-hostdevicenetwork,mutating=false,failurePolicy=fail,sideEffects=None,groups=mellanox.com,resources=hostdevicenetworks,verbs=create;update,versions=v1alpha1,name=vhostdevicenetwork.kb.io,admissionReviewVersions=v1
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
-func (w *hostDeviceNetworkValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+func (w *hostDeviceNetworkValidator) ValidateCreate(
+	ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
 	if skipValidations {
 		nicClusterPolicyLog.Info("skipping CR validation")
 		return nil, nil
@@ -63,12 +72,12 @@ func (w *hostDeviceNetworkValidator) ValidateCreate(_ context.Context, obj runti
 		return nil, errors.New("failed to unmarshal HostDeviceNetwork object to validate")
 	}
 	hostDeviceNetworkLog.Info("validate create", "name", hostDeviceNetwork.Name)
-	return nil, w.validateHostDeviceNetwork(hostDeviceNetwork)
+	return w.validateHostDeviceNetwork(ctx, hostDeviceNetwork)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (w *hostDeviceNetworkValidator) ValidateUpdate(
-	_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
 	if skipValidations {
 		nicClusterPolicyLog.Info("skipping CR validation")
 		return nil, nil
@@ -80,7 +89,7 @@ func (w *hostDeviceNetworkValidator) ValidateUpdate(
 	}
 	hostDeviceNetworkLog.Info("validate update", "name", hostDeviceNetwork.Name)
 
-	return nil, w.validateHostDeviceNetwork(hostDeviceNetwork)
+	return w.validateHostDeviceNetwork(ctx, hostDeviceNetwork)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -105,25 +114,169 @@ func (w *hostDeviceNetworkValidator) ValidateDelete(
 /*
 We are validating here HostDeviceNetwork:
   - ResourceName must be valid for k8s
+  - ResourceName must be exposed by the SR-IOV or RDMA shared device plugin configured in one of the
+    NicClusterPolicies
+  - IPAM must be a valid JSON and match the IPAM schema
+  - StaticIPAM can't be set with IPAM, its ranges must be valid non-overlapping subnets and its gateway and
+    route next hops must be in the subnet of a range
+  - DNS name servers must be IP addresses and its domains valid DNS names
+  - RdmaIsolation warns if the RDMA CNI is not deployed by the NicClusterPolicy exposing ResourceName or if
+    ResourceName is exposed by its RDMA shared device plugin, whose devices can't be moved to the pod network namespace
 */
 
-func (w *hostDeviceNetworkValidator) validateHostDeviceNetwork(in *v1alpha1.HostDeviceNetwork) error {
+func (w *hostDeviceNetworkValidator) validateHostDeviceNetwork(
+	ctx context.Context, in *v1alpha1.HostDeviceNetwork) (admission.Warnings, error) {
+	var allErrs field.ErrorList
+	var warnings admission.Warnings
 	resourceName := in.Spec.ResourceName
+	resourcePath := field.NewPath("spec").Child("resourceName")
+	policies, err := w.listNicClusterPolicies(ctx)
+	if err != nil {
+		allErrs = append(allErrs, field.InternalError(resourcePath, err))
+	}
 	if !isValidHostDeviceNetworkResourceName(resourceName) {
-		var allErrs field.ErrorList
 		allErrs = append(allErrs, field.Invalid(field.NewPath("Spec"), resourceName,
 			"Invalid Resource name, it must consist of alphanumeric characters, '-', '_' or '.', "+
 				"and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', "+
 				"regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')"))
-		return apierrors.NewInvalid(
-			schema.GroupKind{Group: "mellanox.com", Kind: "HostDeviceNetwork"},
-			in.Name, allErrs)
+	} else if err == nil {
+		var errs field.ErrorList
+		warnings, errs = validateResourceExists(policies, resourceName, resourcePath)
+		allErrs = append(allErrs, errs...)
+	}
+	allErrs = append(allErrs, validateNetworkIPAM(in.Spec.IPAM, in.Spec.StaticIPAM, in.Spec.DNS, field.NewPath("spec"))...)
+	if in.Spec.RdmaIsolation {
+		warnings = append(warnings, validateRdmaIsolation(policies, resourceName)...)
+	}
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(
+		schema.GroupKind{Group: "mellanox.com", Kind: "HostDeviceNetwork"},
+		in.Name, allErrs)
+}
+
+// listNicClusterPolicies returns the NicClusterPolicies which are not being deleted, each of them may configure
+// its own device plugins
+func (w *hostDeviceNetworkValidator) listNicClusterPolicies(ctx context.Context) ([]*v1alpha1.NicClusterPolicy, error) {
+	list := &v1alpha1.NicClusterPolicyList{}
+	if err := w.client.List(ctx, list); err != nil {
+		return nil, fmt.Errorf("failed to list NicClusterPolicies: %v", err)
+	}
+	var policies []*v1alpha1.NicClusterPolicy
+	for i := range list.Items {
+		if list.Items[i].DeletionTimestamp.IsZero() {
+			policies = append(policies, &list.Items[i])
+		}
+	}
+	return policies, nil
+}
+
+// validateResourceExists checks that the resource is exposed by one of the device plugins of the NicClusterPolicies
+func validateResourceExists(policies []*v1alpha1.NicClusterPolicy, resourceName string,
+	fldPath *field.Path) (admission.Warnings, field.ErrorList) {
+	var allErrs field.ErrorList
+	if len(policies) == 0 {
+		return admission.Warnings{fmt.Sprintf("no NicClusterPolicy found, can't verify that resource %s exists",
+			resourceName)}, nil
+	}
+	resources := map[string]bool{}
+	for _, ncp := range policies {
+		addPolicyResources(ncp, resources)
+	}
+	if !resources[resourceName] {
+		allErrs = append(allErrs, field.NotFound(fldPath, resourceName))
+	}
+	return nil, allErrs
+}
+
+// validateRdmaIsolation checks that the RDMA CNI chained by the network is deployed and that the resource
+// is not shared by the NicClusterPolicies exposing the resource, the resource not being exposed by any policy
+// is already reported by validateResourceExists
+func validateRdmaIsolation(policies []*v1alpha1.NicClusterPolicy, resourceName string) admission.Warnings {
+	var warnings admission.Warnings
+	for _, ncp := range policies {
+		resources := map[string]bool{}
+		addPolicyResources(ncp, resources)
+		if !resources[resourceName] {
+			continue
+		}
+		if ncp.Spec.SecondaryNetwork == nil || ncp.Spec.SecondaryNetwork.RdmaCni == nil {
+			warnings = append(warnings, fmt.Sprintf("rdmaIsolation is set but the RDMA CNI is not deployed by "+
+				"NicClusterPolicy %s, pods of the network will fail to start", ncp.Name))
+		}
+		if ncp.Spec.RdmaSharedDevicePlugin != nil {
+			shared := map[string]bool{}
+			addDevicePluginResources(ncp.Spec.RdmaSharedDevicePlugin, shared)
+			if shared[resourceName] {
+				warnings = append(warnings, fmt.Sprintf("rdmaIsolation is set but resource %s is exposed by "+
+					"the RDMA shared device plugin of NicClusterPolicy %s, shared RDMA devices can't be isolated",
+					resourceName, ncp.Name))
+			}
+		}
+	}
+	return warnings
+}
+
+// addPolicyResources adds the names of the resources exposed by the device plugins of the NicClusterPolicy
+func addPolicyResources(ncp *v1alpha1.NicClusterPolicy, resources map[string]bool) {
+	if ncp.Spec.SriovDevicePlugin != nil {
+		addDevicePluginResources(ncp.Spec.SriovDevicePlugin, resources)
+	}
+	if ncp.Spec.RdmaSharedDevicePlugin != nil {
+		addDevicePluginResources(ncp.Spec.RdmaSharedDevicePlugin, resources)
+	}
+}
+
+// addDevicePluginResources adds the names of the device plugin resources, which use the default resource prefix
+func addDevicePluginResources(dp *v1alpha1.DevicePluginSpec, resources map[string]bool) {
+	if dp.Config == nil {
+		return
+	}
+	// SR-IOV device plugin uses resourceList, RDMA shared device plugin uses configList
+	var config struct {
+		ResourceList []devicePluginResource `json:"resourceList"`
+		ConfigList   []devicePluginResource `json:"configList"`
+	}
+	if err := json.Unmarshal([]byte(*dp.Config), &config); err != nil {
+		return
+	}
+	for _, resource := range append(config.ResourceList, config.ConfigList...) {
+		if resource.ResourcePrefix == "" || resource.ResourcePrefix == defaultResourcePrefix {
+			resources[resource.ResourceName] = true
+		}
+	}
+}
+
+type devicePluginResource struct {
+	ResourceName   string `json:"resourceName"`
+	ResourcePrefix string `json:"resourcePrefix"`
+}
+
+// validateIPAM validates that the IPAM configuration of a network is a valid JSON and matches the IPAM schema
+func validateIPAM(ipam string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if ipam == "" {
+		return allErrs
+	}
+	var ipamJSON map[string]interface{}
+	if err := json.Unmarshal([]byte(ipam), &ipamJSON); err != nil {
+		return append(allErrs, field.Invalid(fldPath, ipam, "Invalid json of IPAM configuration"))
+	}
+	ipamSchema, err := schemaValidators.GetSchema("ipam")
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath, ipam, "Invalid json schema "+err.Error()))
+	}
+	result, err := ipamSchema.Validate(gojsonschema.NewStringLoader(ipam))
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath, ipam, "Invalid json of IPAM configuration "+err.Error()))
+	}
+	for _, resultErr := range result.Errors() {
+		allErrs = append(allErrs, field.Invalid(fldPath, ipam, resultErr.String()))
 	}
-	return nil
+	return allErrs
 }
 
 func isValidHostDeviceNetworkResourceName(resourceName string) bool {
-	resourceNamePattern := `^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$`
-	resourceNameRegex := regexp.MustCompile(resourceNamePattern)
-	return resourceNameRegex.MatchString(resourceName)
+	return rdmaResourceNameRegex.MatchString(resourceName)
 }
@@ -21,8 +21,12 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/consts"
 )
 
 //nolint:dupl
@@ -35,7 +39,7 @@ var _ = Describe("Validate", func() {
 					ResourceName: "hostdev",
 				},
 			}
-			validator := hostDeviceNetworkValidator{}
+			validator := newHostDeviceNetworkValidator()
 			_, err := validator.ValidateCreate(context.TODO(), hostDeviceNetwork)
 			Expect(err).NotTo(HaveOccurred())
 		})
@@ -46,9 +50,248 @@ var _ = Describe("Validate", func() {
 					ResourceName: "hostdev!!",
 				},
 			}
-			validator := hostDeviceNetworkValidator{}
+			validator := newHostDeviceNetworkValidator()
 			_, err := validator.ValidateCreate(context.TODO(), hostDeviceNetwork)
 			Expect(err.Error()).To(ContainSubstring("Invalid Resource name"))
 		})
+		It("Valid ResourceName exposed by SR-IOV device plugin", func() {
+			hostDeviceNetwork := &v1alpha1.HostDeviceNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.HostDeviceNetworkSpec{
+					ResourceName: "hostdev",
+				},
+			}
+			validator := newHostDeviceNetworkValidator(devicePluginsNicClusterPolicy())
+			warnings, err := validator.ValidateCreate(context.TODO(), hostDeviceNetwork)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+		It("Valid ResourceName exposed by RDMA shared device plugin", func() {
+			hostDeviceNetwork := &v1alpha1.HostDeviceNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.HostDeviceNetworkSpec{
+					ResourceName: "rdma_shared_device_a",
+				},
+			}
+			validator := newHostDeviceNetworkValidator(devicePluginsNicClusterPolicy())
+			_, err := validator.ValidateCreate(context.TODO(), hostDeviceNetwork)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Warning when NicClusterPolicy doesn't exist", func() {
+			hostDeviceNetwork := &v1alpha1.HostDeviceNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.HostDeviceNetworkSpec{
+					ResourceName: "hostdev",
+				},
+			}
+			validator := newHostDeviceNetworkValidator()
+			warnings, err := validator.ValidateCreate(context.TODO(), hostDeviceNetwork)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+		})
+		It("Invalid ResourceName not exposed by device plugins", func() {
+			hostDeviceNetwork := &v1alpha1.HostDeviceNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.HostDeviceNetworkSpec{
+					ResourceName: "unknown",
+				},
+			}
+			validator := newHostDeviceNetworkValidator(devicePluginsNicClusterPolicy())
+			_, err := validator.ValidateCreate(context.TODO(), hostDeviceNetwork)
+			Expect(err.Error()).To(ContainSubstring("spec.resourceName: Not found: \"unknown\""))
+		})
+		It("Invalid ResourceName with custom resource prefix", func() {
+			hostDeviceNetwork := &v1alpha1.HostDeviceNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.HostDeviceNetworkSpec{
+					ResourceName: "custom",
+				},
+			}
+			validator := newHostDeviceNetworkValidator(devicePluginsNicClusterPolicy())
+			_, err := validator.ValidateCreate(context.TODO(), hostDeviceNetwork)
+			Expect(err.Error()).To(ContainSubstring("spec.resourceName: Not found: \"custom\""))
+		})
+		It("Valid ResourceName exposed by another NicClusterPolicy", func() {
+			hostDeviceNetwork := &v1alpha1.HostDeviceNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.HostDeviceNetworkSpec{
+					ResourceName:  "hostdev_b",
+					RdmaIsolation: true,
+				},
+			}
+			other := otherDevicePluginsNicClusterPolicy()
+			other.Spec.SecondaryNetwork = &v1alpha1.SecondaryNetworkSpec{RdmaCni: &v1alpha1.CNIPluginSpec{}}
+			validator := newHostDeviceNetworkValidator(devicePluginsNicClusterPolicy(), other)
+			warnings, err := validator.ValidateCreate(context.TODO(), hostDeviceNetwork)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+		It("Warning when RdmaIsolation is set without RDMA CNI in the NicClusterPolicy exposing the resource", func() {
+			hostDeviceNetwork := &v1alpha1.HostDeviceNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.HostDeviceNetworkSpec{
+					ResourceName:  "hostdev_b",
+					RdmaIsolation: true,
+				},
+			}
+			ncp := devicePluginsNicClusterPolicy()
+			ncp.Spec.SecondaryNetwork = &v1alpha1.SecondaryNetworkSpec{RdmaCni: &v1alpha1.CNIPluginSpec{}}
+			validator := newHostDeviceNetworkValidator(ncp, otherDevicePluginsNicClusterPolicy())
+			warnings, err := validator.ValidateCreate(context.TODO(), hostDeviceNetwork)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+			Expect(warnings[0]).To(ContainSubstring("RDMA CNI is not deployed by NicClusterPolicy policy-b"))
+		})
+		It("Invalid ResourceName exposed by a NicClusterPolicy being deleted", func() {
+			hostDeviceNetwork := &v1alpha1.HostDeviceNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.HostDeviceNetworkSpec{
+					ResourceName: "hostdev_b",
+				},
+			}
+			other := otherDevicePluginsNicClusterPolicy()
+			now := metav1.Now()
+			other.DeletionTimestamp = &now
+			other.Finalizers = []string{"test"}
+			validator := newHostDeviceNetworkValidator(devicePluginsNicClusterPolicy(), other)
+			_, err := validator.ValidateCreate(context.TODO(), hostDeviceNetwork)
+			Expect(err.Error()).To(ContainSubstring("spec.resourceName: Not found: \"hostdev_b\""))
+		})
+		It("Valid IPAM", func() {
+			hostDeviceNetwork := &v1alpha1.HostDeviceNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.HostDeviceNetworkSpec{
+					ResourceName: "hostdev",
+					IPAM:         `{"type": "whereabouts", "range": "192.168.3.225/28", "exclude": ["192.168.3.229/30"]}`,
+				},
+			}
+			validator := newHostDeviceNetworkValidator()
+			_, err := validator.ValidateCreate(context.TODO(), hostDeviceNetwork)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Invalid IPAM json", func() {
+			hostDeviceNetwork := &v1alpha1.HostDeviceNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.HostDeviceNetworkSpec{
+					ResourceName: "hostdev",
+					IPAM:         `{"type": "whereabouts",`,
+				},
+			}
+			validator := newHostDeviceNetworkValidator()
+			_, err := validator.ValidateCreate(context.TODO(), hostDeviceNetwork)
+			Expect(err.Error()).To(ContainSubstring("Invalid json of IPAM configuration"))
+		})
+		It("Invalid IPAM without type", func() {
+			hostDeviceNetwork := &v1alpha1.HostDeviceNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.HostDeviceNetworkSpec{
+					ResourceName: "hostdev",
+					IPAM:         `{"range": "192.168.3.225/28"}`,
+				},
+			}
+			validator := newHostDeviceNetworkValidator()
+			_, err := validator.ValidateCreate(context.TODO(), hostDeviceNetwork)
+			Expect(err.Error()).To(ContainSubstring("type is required"))
+		})
+		It("Invalid static IPAM gateway", func() {
+			hostDeviceNetwork := &v1alpha1.HostDeviceNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.HostDeviceNetworkSpec{
+					ResourceName: "hostdev",
+					StaticIPAM: &v1alpha1.StaticIPAMSpec{
+						Ranges:  []v1alpha1.IPRangeSpec{{Subnet: "192.168.3.0/24"}},
+						Gateway: "192.168.4.1",
+					},
+				},
+			}
+			validator := newHostDeviceNetworkValidator()
+			_, err := validator.ValidateCreate(context.TODO(), hostDeviceNetwork)
+			Expect(err.Error()).To(ContainSubstring("spec.staticIpam.gateway: Invalid value"))
+		})
+		It("Valid RdmaIsolation with RDMA CNI deployed", func() {
+			hostDeviceNetwork := &v1alpha1.HostDeviceNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.HostDeviceNetworkSpec{
+					ResourceName:  "hostdev",
+					RdmaIsolation: true,
+				},
+			}
+			ncp := devicePluginsNicClusterPolicy()
+			ncp.Spec.SecondaryNetwork = &v1alpha1.SecondaryNetworkSpec{RdmaCni: &v1alpha1.CNIPluginSpec{}}
+			validator := newHostDeviceNetworkValidator(ncp)
+			warnings, err := validator.ValidateCreate(context.TODO(), hostDeviceNetwork)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+		It("Warning when RdmaIsolation is set without RDMA CNI", func() {
+			hostDeviceNetwork := &v1alpha1.HostDeviceNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.HostDeviceNetworkSpec{
+					ResourceName:  "hostdev",
+					RdmaIsolation: true,
+				},
+			}
+			validator := newHostDeviceNetworkValidator(devicePluginsNicClusterPolicy())
+			warnings, err := validator.ValidateCreate(context.TODO(), hostDeviceNetwork)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+			Expect(warnings[0]).To(ContainSubstring("RDMA CNI is not deployed"))
+		})
+		It("Warning when RdmaIsolation is set with a shared RDMA resource", func() {
+			hostDeviceNetwork := &v1alpha1.HostDeviceNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.HostDeviceNetworkSpec{
+					ResourceName:  "rdma_shared_device_a",
+					RdmaIsolation: true,
+				},
+			}
+			ncp := devicePluginsNicClusterPolicy()
+			ncp.Spec.SecondaryNetwork = &v1alpha1.SecondaryNetworkSpec{RdmaCni: &v1alpha1.CNIPluginSpec{}}
+			validator := newHostDeviceNetworkValidator(ncp)
+			warnings, err := validator.ValidateCreate(context.TODO(), hostDeviceNetwork)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+			Expect(warnings[0]).To(ContainSubstring("shared RDMA devices can't be isolated"))
+		})
 	})
 })
+
+func newHostDeviceNetworkValidator(objs ...client.Object) hostDeviceNetworkValidator {
+	scheme := runtime.NewScheme()
+	Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+	return hostDeviceNetworkValidator{
+		client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+	}
+}
+
+func devicePluginsNicClusterPolicy() *v1alpha1.NicClusterPolicy {
+	sriovConfig := `{"resourceList": [
+		{"resourceName": "hostdev", "selectors": {"vendors": ["15b3"]}},
+		{"resourcePrefix": "example.com", "resourceName": "custom", "selectors": {"vendors": ["15b3"]}}]}`
+	rdmaConfig := `{"periodicUpdateInterval": 300, "configList": [
+		{"resourceName": "rdma_shared_device_a", "rdmaHcaMax": 63, "selectors": {"vendors": ["15b3"]}}]}`
+	return &v1alpha1.NicClusterPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: consts.NicClusterPolicyResourceName},
+		Spec: v1alpha1.NicClusterPolicySpec{
+			SriovDevicePlugin: &v1alpha1.DevicePluginSpec{
+				ImageSpecWithConfig: v1alpha1.ImageSpecWithConfig{Config: &sriovConfig},
+			},
+			RdmaSharedDevicePlugin: &v1alpha1.DevicePluginSpec{
+				ImageSpecWithConfig: v1alpha1.ImageSpecWithConfig{Config: &rdmaConfig},
+			},
+		},
+	}
+}
+
+func otherDevicePluginsNicClusterPolicy() *v1alpha1.NicClusterPolicy {
+	sriovConfig := `{"resourceList": [{"resourceName": "hostdev_b", "selectors": {"vendors": ["15b3"]}}]}`
+	return &v1alpha1.NicClusterPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy-b"},
+		Spec: v1alpha1.NicClusterPolicySpec{
+			NodeSelector: map[string]string{"pool": "b"},
+			SriovDevicePlugin: &v1alpha1.DevicePluginSpec{
+				ImageSpecWithConfig: v1alpha1.ImageSpecWithConfig{Config: &sriovConfig},
+			},
+		},
+	}
+}
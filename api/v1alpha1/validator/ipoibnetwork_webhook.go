@@ -0,0 +1,191 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+const (
+	// pKeyMembershipMask is the bit of the PKey that defines a full membership
+	pKeyMembershipMask = 0x8000
+)
+
+// log is for logging in this package.
+var ipoibNetworkLog = logf.Log.WithName("ipoibnetwork-resource")
+
+type ipoibNetworkValidator struct{}
+
+var _ webhook.CustomValidator = &ipoibNetworkValidator{}
+
+// SetupIPoIBNetworkWebhookWithManager sets up webhook for IPoIBNetwork.
+func SetupIPoIBNetworkWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.IPoIBNetwork{}).
+		WithValidator(&ipoibNetworkValidator{}).
+		Complete()
+}
+
+//nolint:lll
+//+kubebuilder:webhook:path=/validate-mellanox-com-v1alpha1-ipoibnetwork,mutating=false,failurePolicy=fail,sideEffects=None,groups=mellanox.com,resources=ipoibnetworks,verbs=create;update,versions=v1alpha1,name=vipoibnetwork.kb.io,admissionReviewVersions=v1
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (w *ipoibNetworkValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	if skipValidations {
+		ipoibNetworkLog.Info("skipping CR validation")
+		return nil, nil
+	}
+	ipoibNetwork, ok := obj.(*v1alpha1.IPoIBNetwork)
+	if !ok {
+		return nil, errors.New("failed to unmarshal IPoIBNetwork object to validate")
+	}
+	ipoibNetworkLog.Info("validate create", "name", ipoibNetwork.Name)
+	return nil, w.validateIPoIBNetwork(ipoibNetwork)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (w *ipoibNetworkValidator) ValidateUpdate(
+	_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	if skipValidations {
+		ipoibNetworkLog.Info("skipping CR validation")
+		return nil, nil
+	}
+
+	ipoibNetwork, ok := newObj.(*v1alpha1.IPoIBNetwork)
+	if !ok {
+		return nil, errors.New("failed to unmarshal IPoIBNetwork object to validate")
+	}
+	ipoibNetworkLog.Info("validate update", "name", ipoibNetwork.Name)
+
+	return nil, w.validateIPoIBNetwork(ipoibNetwork)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (w *ipoibNetworkValidator) ValidateDelete(
+	_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	if skipValidations {
+		ipoibNetworkLog.Info("skipping CR validation")
+		return nil, nil
+	}
+
+	ipoibNetwork, ok := obj.(*v1alpha1.IPoIBNetwork)
+	if !ok {
+		return nil, errors.New("failed to unmarshal IPoIBNetwork object to validate")
+	}
+
+	ipoibNetworkLog.Info("validate delete", "name", ipoibNetwork.Name)
+
+	// Validation for delete call is not required
+	return nil, nil
+}
+
+/*
+We are validating here IPoIBNetwork:
+  - Master must be a valid network interface name
+  - PKey of a child interface used as Master (e.g. ib0.8001) must be a valid 16-bit hex value
+  - Bond must enslave two distinct valid network interfaces, Master must be empty or match the name of the bond
+  - IPAM must be a valid JSON and match the IPAM schema
+  - StaticIPAM can't be set with IPAM, its ranges must be valid non-overlapping subnets and its gateway and
+    route next hops must be in the subnet of a range
+  - DNS name servers must be IP addresses and its domains valid DNS names
+*/
+
+func (w *ipoibNetworkValidator) validateIPoIBNetwork(in *v1alpha1.IPoIBNetwork) error {
+	var allErrs field.ErrorList
+	fldPath := field.NewPath("spec")
+	if in.Spec.Bond != nil {
+		allErrs = append(allErrs, validateIPoIBBond(in.Spec.Bond, in.Spec.Master, fldPath)...)
+	} else if in.Spec.Master == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("master"), "master interface must be specified"))
+	} else {
+		allErrs = append(append(allErrs,
+			validateInterfaceName(in.Spec.Master, fldPath.Child("master"))...),
+			validateIPoIBChildPKey(in.Spec.Master, fldPath.Child("master"))...)
+	}
+	allErrs = append(allErrs, validateNetworkIPAM(in.Spec.IPAM, in.Spec.StaticIPAM, in.Spec.DNS, fldPath)...)
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(
+		schema.GroupKind{Group: "mellanox.com", Kind: "IPoIBNetwork"},
+		in.Name, allErrs)
+}
+
+// validateIPoIBBond validates the bond used as the parent interface of the IPoIB network
+func validateIPoIBBond(bond *v1alpha1.IPoIBBondSpec, master string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	bondPath := fldPath.Child("bond")
+	allErrs = append(allErrs, validateInterfaceName(bond.Name, bondPath.Child("name"))...)
+	if master != "" && master != bond.Name {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("master"), master,
+			"master interface must be empty or match the name of the bond"))
+	}
+	if bond.Mode != "" && bond.Mode != v1alpha1.IPoIBBondModeActiveBackup {
+		allErrs = append(allErrs, field.NotSupported(bondPath.Child("mode"), bond.Mode,
+			[]string{v1alpha1.IPoIBBondModeActiveBackup}))
+	}
+	if len(bond.Links) != 2 {
+		return append(allErrs, field.Invalid(bondPath.Child("links"), bond.Links,
+			"bond must enslave exactly two interfaces"))
+	}
+	for i, link := range bond.Links {
+		allErrs = append(allErrs, validateInterfaceName(link, bondPath.Child("links").Index(i))...)
+		if link == bond.Name {
+			allErrs = append(allErrs, field.Invalid(bondPath.Child("links").Index(i), link,
+				"bond can not enslave itself"))
+		}
+	}
+	if bond.Links[0] == bond.Links[1] {
+		allErrs = append(allErrs, field.Duplicate(bondPath.Child("links").Index(1), bond.Links[1]))
+	}
+	return allErrs
+}
+
+// validateIPoIBChildPKey validates the PKey of the IPoIB child interface named <parent>.<pkey>
+func validateIPoIBChildPKey(master string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	idx := strings.LastIndex(master, ".")
+	if idx < 0 {
+		return allErrs
+	}
+	pKey := strings.TrimPrefix(master[idx+1:], "0x")
+	value, err := strconv.ParseUint(pKey, 16, 16)
+	if err != nil || len(pKey) > 4 {
+		return append(allErrs, field.Invalid(fldPath, master,
+			"PKey of the IPoIB child interface must be a valid 16-bit hex value, e.g. ib0.8001"))
+	}
+	if value&^pKeyMembershipMask == 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath, master,
+			fmt.Sprintf("PKey 0x%04x of the IPoIB child interface is reserved", value)))
+	}
+	return allErrs
+}
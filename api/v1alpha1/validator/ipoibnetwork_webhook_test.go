@@ -0,0 +1,188 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package validator //nolint:dupl
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+//nolint:dupl
+var _ = Describe("Validate", func() {
+	Context("IPoIBNetwork tests", func() {
+		It("Valid IPoIBNetwork", func() {
+			ipoibNetwork := &v1alpha1.IPoIBNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.IPoIBNetworkSpec{
+					Master: "ibs3f1",
+					IPAM:   `{"type": "nv-ipam", "poolName": "pool1"}`,
+				},
+			}
+			validator := ipoibNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), ipoibNetwork)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Valid IPoIBNetwork with PKey child interface", func() {
+			ipoibNetwork := &v1alpha1.IPoIBNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.IPoIBNetworkSpec{
+					Master: "ib0.8001",
+				},
+			}
+			validator := ipoibNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), ipoibNetwork)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Invalid IPoIBNetwork without Master", func() {
+			ipoibNetwork := &v1alpha1.IPoIBNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			}
+			validator := ipoibNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), ipoibNetwork)
+			Expect(err.Error()).To(ContainSubstring("spec.master: Required value"))
+		})
+		It("Invalid Master name", func() {
+			ipoibNetwork := &v1alpha1.IPoIBNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.IPoIBNetworkSpec{
+					Master: "ib 0",
+				},
+			}
+			validator := ipoibNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), ipoibNetwork)
+			Expect(err.Error()).To(ContainSubstring("must be a valid network interface name"))
+		})
+		It("Invalid PKey which is not hex", func() {
+			ipoibNetwork := &v1alpha1.IPoIBNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.IPoIBNetworkSpec{
+					Master: "ib0.80zz",
+				},
+			}
+			validator := ipoibNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), ipoibNetwork)
+			Expect(err.Error()).To(ContainSubstring("must be a valid 16-bit hex value"))
+		})
+		It("Invalid PKey longer than 16 bits", func() {
+			ipoibNetwork := &v1alpha1.IPoIBNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.IPoIBNetworkSpec{
+					Master: "ib0.18001",
+				},
+			}
+			validator := ipoibNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), ipoibNetwork)
+			Expect(err.Error()).To(ContainSubstring("must be a valid 16-bit hex value"))
+		})
+		It("Invalid reserved PKey", func() {
+			ipoibNetwork := &v1alpha1.IPoIBNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.IPoIBNetworkSpec{
+					Master: "ib0.8000",
+				},
+			}
+			validator := ipoibNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), ipoibNetwork)
+			Expect(err.Error()).To(ContainSubstring("PKey 0x8000 of the IPoIB child interface is reserved"))
+		})
+		It("Invalid IPAM", func() {
+			ipoibNetwork := &v1alpha1.IPoIBNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.IPoIBNetworkSpec{
+					Master: "ibs3f1",
+					IPAM:   `"type": "whereabouts"`,
+				},
+			}
+			validator := ipoibNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), ipoibNetwork)
+			Expect(err.Error()).To(ContainSubstring("Invalid json of IPAM configuration"))
+		})
+		It("Valid IPoIBNetwork with bond", func() {
+			ipoibNetwork := &v1alpha1.IPoIBNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.IPoIBNetworkSpec{
+					Bond: &v1alpha1.IPoIBBondSpec{Name: "bond0", Links: []string{"ib0", "ib1"}},
+				},
+			}
+			validator := ipoibNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), ipoibNetwork)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Invalid Master which does not match the bond", func() {
+			ipoibNetwork := &v1alpha1.IPoIBNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.IPoIBNetworkSpec{
+					Master: "ib0",
+					Bond:   &v1alpha1.IPoIBBondSpec{Name: "bond0", Links: []string{"ib0", "ib1"}},
+				},
+			}
+			validator := ipoibNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), ipoibNetwork)
+			Expect(err.Error()).To(ContainSubstring("master interface must be empty or match the name of the bond"))
+		})
+		It("Invalid bond mode", func() {
+			ipoibNetwork := &v1alpha1.IPoIBNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.IPoIBNetworkSpec{
+					Bond: &v1alpha1.IPoIBBondSpec{Name: "bond0", Mode: "balance-rr", Links: []string{"ib0", "ib1"}},
+				},
+			}
+			validator := ipoibNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), ipoibNetwork)
+			Expect(err.Error()).To(ContainSubstring("spec.bond.mode: Unsupported value"))
+		})
+		It("Invalid bond with a single link", func() {
+			ipoibNetwork := &v1alpha1.IPoIBNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.IPoIBNetworkSpec{
+					Bond: &v1alpha1.IPoIBBondSpec{Name: "bond0", Links: []string{"ib0"}},
+				},
+			}
+			validator := ipoibNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), ipoibNetwork)
+			Expect(err.Error()).To(ContainSubstring("bond must enslave exactly two interfaces"))
+		})
+		It("Invalid bond with duplicate links", func() {
+			ipoibNetwork := &v1alpha1.IPoIBNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.IPoIBNetworkSpec{
+					Bond: &v1alpha1.IPoIBBondSpec{Name: "bond0", Links: []string{"ib0", "ib0"}},
+				},
+			}
+			validator := ipoibNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), ipoibNetwork)
+			Expect(err.Error()).To(ContainSubstring("spec.bond.links[1]: Duplicate value"))
+		})
+		It("Invalid static IPAM together with IPAM", func() {
+			ipoibNetwork := &v1alpha1.IPoIBNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.IPoIBNetworkSpec{
+					Master:     "ib0",
+					IPAM:       `{"type": "whereabouts", "range": "192.168.5.225/28"}`,
+					StaticIPAM: &v1alpha1.StaticIPAMSpec{Ranges: []v1alpha1.IPRangeSpec{{Subnet: "192.168.5.0/24"}}},
+				},
+			}
+			validator := ipoibNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), ipoibNetwork)
+			Expect(err.Error()).To(ContainSubstring("spec.staticIpam: Forbidden"))
+		})
+	})
+})
@@ -0,0 +1,167 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+const (
+	// maxInterfaceNameLength is the maximal length of a linux network interface name (IFNAMSIZ - 1)
+	maxInterfaceNameLength = 15
+	minMacvlanMtu          = 68
+	maxMacvlanMtu          = 9978
+)
+
+var supportedMacvlanModes = []string{"bridge", "private", "vepa", "passthru"}
+
+// log is for logging in this package.
+var macvlanNetworkLog = logf.Log.WithName("macvlannetwork-resource")
+
+type macvlanNetworkValidator struct{}
+
+var _ webhook.CustomValidator = &macvlanNetworkValidator{}
+
+// SetupMacvlanNetworkWebhookWithManager sets up webhook for MacvlanNetwork.
+func SetupMacvlanNetworkWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.MacvlanNetwork{}).
+		WithValidator(&macvlanNetworkValidator{}).
+		Complete()
+}
+
+//nolint:lll
+//+kubebuilder:webhook:path=/validate-mellanox-com-v1alpha1-macvlannetwork,mutating=false,failurePolicy=fail,sideEffects=None,groups=mellanox.com,resources=macvlannetworks,verbs=create;update,versions=v1alpha1,name=vmacvlannetwork.kb.io,admissionReviewVersions=v1
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (w *macvlanNetworkValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	if skipValidations {
+		macvlanNetworkLog.Info("skipping CR validation")
+		return nil, nil
+	}
+	macvlanNetwork, ok := obj.(*v1alpha1.MacvlanNetwork)
+	if !ok {
+		return nil, errors.New("failed to unmarshal MacvlanNetwork object to validate")
+	}
+	macvlanNetworkLog.Info("validate create", "name", macvlanNetwork.Name)
+	return nil, w.validateMacvlanNetwork(macvlanNetwork)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (w *macvlanNetworkValidator) ValidateUpdate(
+	_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	if skipValidations {
+		macvlanNetworkLog.Info("skipping CR validation")
+		return nil, nil
+	}
+
+	macvlanNetwork, ok := newObj.(*v1alpha1.MacvlanNetwork)
+	if !ok {
+		return nil, errors.New("failed to unmarshal MacvlanNetwork object to validate")
+	}
+	macvlanNetworkLog.Info("validate update", "name", macvlanNetwork.Name)
+
+	return nil, w.validateMacvlanNetwork(macvlanNetwork)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (w *macvlanNetworkValidator) ValidateDelete(
+	_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	if skipValidations {
+		macvlanNetworkLog.Info("skipping CR validation")
+		return nil, nil
+	}
+
+	macvlanNetwork, ok := obj.(*v1alpha1.MacvlanNetwork)
+	if !ok {
+		return nil, errors.New("failed to unmarshal MacvlanNetwork object to validate")
+	}
+
+	macvlanNetworkLog.Info("validate delete", "name", macvlanNetwork.Name)
+
+	// Validation for delete call is not required
+	return nil, nil
+}
+
+/*
+We are validating here MacvlanNetwork:
+  - Master must be a valid network interface name
+  - Mode must be one of "bridge", "private", "vepa", "passthru"
+  - Mtu must be 0 or in the supported range
+  - IPAM must be a valid JSON and match the IPAM schema
+  - StaticIPAM can't be set with IPAM, its ranges must be valid non-overlapping subnets and its gateway and
+    route next hops must be in the subnet of a range
+  - DNS name servers must be IP addresses and its domains valid DNS names
+*/
+
+func (w *macvlanNetworkValidator) validateMacvlanNetwork(in *v1alpha1.MacvlanNetwork) error {
+	var allErrs field.ErrorList
+	fldPath := field.NewPath("spec")
+	if in.Spec.Master != "" {
+		allErrs = append(allErrs, validateInterfaceName(in.Spec.Master, fldPath.Child("master"))...)
+	}
+	if in.Spec.Mode != "" && !isSupportedValue(in.Spec.Mode, supportedMacvlanModes) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("mode"), in.Spec.Mode, supportedMacvlanModes))
+	}
+	if in.Spec.Mtu != 0 && (in.Spec.Mtu < minMacvlanMtu || in.Spec.Mtu > maxMacvlanMtu) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("mtu"), in.Spec.Mtu,
+			fmt.Sprintf("must be 0 or in the range %d-%d", minMacvlanMtu, maxMacvlanMtu)))
+	}
+	allErrs = append(allErrs, validateNetworkIPAM(in.Spec.IPAM, in.Spec.StaticIPAM, in.Spec.DNS, fldPath)...)
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(
+		schema.GroupKind{Group: "mellanox.com", Kind: "MacvlanNetwork"},
+		in.Name, allErrs)
+}
+
+// validateInterfaceName validates the name is accepted by the kernel as a network interface name
+func validateInterfaceName(name string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(name) > maxInterfaceNameLength {
+		allErrs = append(allErrs, field.TooLong(fldPath, name, maxInterfaceNameLength))
+	}
+	if name == "." || name == ".." || strings.ContainsAny(name, "/: \t\n") {
+		allErrs = append(allErrs, field.Invalid(fldPath, name,
+			"must be a valid network interface name, it can't be '.' or '..' and can't contain '/', ':' or whitespaces"))
+	}
+	return allErrs
+}
+
+func isSupportedValue(value string, supported []string) bool {
+	for _, s := range supported {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
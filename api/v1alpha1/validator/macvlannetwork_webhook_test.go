@@ -0,0 +1,199 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package validator //nolint:dupl
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+//nolint:dupl
+var _ = Describe("Validate", func() {
+	Context("MacvlanNetwork tests", func() {
+		It("Valid MacvlanNetwork", func() {
+			macvlanNetwork := &v1alpha1.MacvlanNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.MacvlanNetworkSpec{
+					Master: "ens2f0",
+					Mode:   "bridge",
+					Mtu:    1500,
+					IPAM:   `{"type": "whereabouts", "range": "192.168.2.225/28"}`,
+				},
+			}
+			validator := macvlanNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), macvlanNetwork)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Valid MacvlanNetwork with defaults", func() {
+			macvlanNetwork := &v1alpha1.MacvlanNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			}
+			validator := macvlanNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), macvlanNetwork)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Invalid Master too long", func() {
+			macvlanNetwork := &v1alpha1.MacvlanNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.MacvlanNetworkSpec{
+					Master: "enp3s0f0np0vlan100",
+				},
+			}
+			validator := macvlanNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), macvlanNetwork)
+			Expect(err.Error()).To(ContainSubstring("spec.master: Too long"))
+		})
+		It("Invalid Master with slash", func() {
+			macvlanNetwork := &v1alpha1.MacvlanNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.MacvlanNetworkSpec{
+					Master: "ens2/f0",
+				},
+			}
+			validator := macvlanNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), macvlanNetwork)
+			Expect(err.Error()).To(ContainSubstring("must be a valid network interface name"))
+		})
+		It("Invalid Mode", func() {
+			macvlanNetwork := &v1alpha1.MacvlanNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.MacvlanNetworkSpec{
+					Mode: "source",
+				},
+			}
+			validator := macvlanNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), macvlanNetwork)
+			Expect(err.Error()).To(ContainSubstring("spec.mode: Unsupported value: \"source\""))
+		})
+		It("Invalid Mtu", func() {
+			macvlanNetwork := &v1alpha1.MacvlanNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.MacvlanNetworkSpec{
+					Mtu: 65000,
+				},
+			}
+			validator := macvlanNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), macvlanNetwork)
+			Expect(err.Error()).To(ContainSubstring("must be 0 or in the range 68-9978"))
+		})
+		It("Invalid whereabouts IPAM without range", func() {
+			macvlanNetwork := &v1alpha1.MacvlanNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.MacvlanNetworkSpec{
+					IPAM: `{"type": "whereabouts", "exclude": ["192.168.2.229/30"]}`,
+				},
+			}
+			validator := macvlanNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), macvlanNetwork)
+			Expect(err.Error()).To(ContainSubstring("spec.ipam: Invalid value"))
+		})
+		It("Invalid nv-ipam IPAM without poolName", func() {
+			macvlanNetwork := &v1alpha1.MacvlanNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.MacvlanNetworkSpec{
+					IPAM: `{"type": "nv-ipam"}`,
+				},
+			}
+			validator := macvlanNetworkValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), macvlanNetwork)
+			Expect(err.Error()).To(ContainSubstring("poolName is required"))
+		})
+	})
+	Context("MacvlanNetwork static IPAM and DNS tests", func() {
+		var macvlanNetwork *v1alpha1.MacvlanNetwork
+		validator := macvlanNetworkValidator{}
+		BeforeEach(func() {
+			macvlanNetwork = &v1alpha1.MacvlanNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.MacvlanNetworkSpec{
+					StaticIPAM: &v1alpha1.StaticIPAMSpec{
+						Ranges: []v1alpha1.IPRangeSpec{
+							{Subnet: "192.168.2.0/24", RangeStart: "192.168.2.10", RangeEnd: "192.168.2.100"},
+							{Subnet: "fd00::/64"},
+						},
+						Gateway: "192.168.2.1",
+						Routes: []v1alpha1.RouteSpec{
+							{Dst: "10.0.0.0/8", GW: "192.168.2.254"},
+							{Dst: "::/0", GW: "fd00::1"},
+							{Dst: "172.16.0.0/12"},
+						},
+					},
+					DNS: &v1alpha1.DNSSpec{
+						Nameservers: []string{"192.168.2.53", "fd00::53"},
+						Domain:      "example.com",
+						Search:      []string{"svc.example.com."},
+					},
+				},
+			}
+		})
+		It("Valid static IPAM and DNS", func() {
+			_, err := validator.ValidateCreate(context.TODO(), macvlanNetwork)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Invalid static IPAM together with IPAM", func() {
+			macvlanNetwork.Spec.IPAM = `{"type": "whereabouts", "range": "192.168.2.225/28"}`
+			_, err := validator.ValidateCreate(context.TODO(), macvlanNetwork)
+			Expect(err.Error()).To(ContainSubstring("spec.staticIpam: Forbidden: can't be set together with ipam"))
+		})
+		It("Invalid subnet", func() {
+			macvlanNetwork.Spec.StaticIPAM.Ranges[0].Subnet = "192.168.2.0"
+			_, err := validator.ValidateCreate(context.TODO(), macvlanNetwork)
+			Expect(err.Error()).To(ContainSubstring("spec.staticIpam.ranges[0].subnet: Invalid value"))
+		})
+		It("Invalid overlapping subnets", func() {
+			macvlanNetwork.Spec.StaticIPAM.Ranges[1].Subnet = "192.168.0.0/16"
+			_, err := validator.ValidateCreate(context.TODO(), macvlanNetwork)
+			Expect(err.Error()).To(ContainSubstring("overlaps with the subnet 192.168.2.0/24"))
+		})
+		It("Invalid range start out of the subnet", func() {
+			macvlanNetwork.Spec.StaticIPAM.Ranges[0].RangeStart = "192.168.3.10"
+			_, err := validator.ValidateCreate(context.TODO(), macvlanNetwork)
+			Expect(err.Error()).To(ContainSubstring("spec.staticIpam.ranges[0].rangeStart: Invalid value"))
+		})
+		It("Invalid range end lower than range start", func() {
+			macvlanNetwork.Spec.StaticIPAM.Ranges[0].RangeEnd = "192.168.2.5"
+			_, err := validator.ValidateCreate(context.TODO(), macvlanNetwork)
+			Expect(err.Error()).To(ContainSubstring("must not be lower than rangeStart"))
+		})
+		It("Invalid gateway out of the subnets", func() {
+			macvlanNetwork.Spec.StaticIPAM.Gateway = "192.168.3.1"
+			_, err := validator.ValidateCreate(context.TODO(), macvlanNetwork)
+			Expect(err.Error()).To(ContainSubstring("spec.staticIpam.gateway: Invalid value"))
+		})
+		It("Invalid route next hop out of the subnets", func() {
+			macvlanNetwork.Spec.StaticIPAM.Routes[0].GW = "10.0.0.1"
+			_, err := validator.ValidateCreate(context.TODO(), macvlanNetwork)
+			Expect(err.Error()).To(ContainSubstring("spec.staticIpam.routes[0].gw: Invalid value"))
+		})
+		It("Invalid route next hop of another IP family", func() {
+			macvlanNetwork.Spec.StaticIPAM.Routes[1].GW = "192.168.2.254"
+			_, err := validator.ValidateCreate(context.TODO(), macvlanNetwork)
+			Expect(err.Error()).To(ContainSubstring("must be of the same IP family as dst"))
+		})
+		It("Invalid DNS", func() {
+			macvlanNetwork.Spec.DNS.Nameservers[0] = "dns.example.com"
+			macvlanNetwork.Spec.DNS.Search[0] = "Invalid_Domain"
+			_, err := validator.ValidateCreate(context.TODO(), macvlanNetwork)
+			Expect(err.Error()).To(ContainSubstring("spec.dns.nameservers[0]: Invalid value"))
+			Expect(err.Error()).To(ContainSubstring("spec.dns.search[0]: Invalid value"))
+		})
+	})
+})
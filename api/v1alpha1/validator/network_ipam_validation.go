@@ -0,0 +1,150 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+// validateNetworkIPAM validates the IPAM and DNS configurations of a network, ipam and staticIpam are
+// mutually exclusive
+func validateNetworkIPAM(ipam string, staticIPAM *v1alpha1.StaticIPAMSpec, dns *v1alpha1.DNSSpec,
+	fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateIPAM(ipam, fldPath.Child("ipam"))...)
+	if staticIPAM != nil {
+		if ipam != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("staticIpam"), "can't be set together with ipam"))
+		}
+		allErrs = append(allErrs, validateStaticIPAM(staticIPAM, fldPath.Child("staticIpam"))...)
+	}
+	if dns != nil {
+		allErrs = append(allErrs, validateDNS(dns, fldPath.Child("dns"))...)
+	}
+	return allErrs
+}
+
+// validateStaticIPAM checks that the ranges are non-overlapping subnets with their start and end in the subnet,
+// and that the gateway and the next hops of the routes are in the subnet of a range
+func validateStaticIPAM(ipam *v1alpha1.StaticIPAMSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(ipam.Ranges) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("ranges"), "at least one range must be specified"))
+	}
+	// parseRangeAddress returns the start or end address of a range, or nil if it is not set or invalid
+	parseRangeAddress := func(address string, subnet *net.IPNet, fldPath *field.Path) net.IP {
+		if address == "" {
+			return nil
+		}
+		ip := net.ParseIP(address)
+		if ip == nil || !subnet.Contains(ip) {
+			allErrs = append(allErrs, field.Invalid(fldPath, address, "must be a valid IP address in the subnet"))
+			return nil
+		}
+		return ip
+	}
+	var subnets []*net.IPNet
+	for i, r := range ipam.Ranges {
+		rangePath := fldPath.Child("ranges").Index(i)
+		_, subnet, err := net.ParseCIDR(r.Subnet)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(rangePath.Child("subnet"), r.Subnet, "must be a valid CIDR"))
+			continue
+		}
+		for _, other := range subnets {
+			if other.Contains(subnet.IP) || subnet.Contains(other.IP) {
+				allErrs = append(allErrs, field.Invalid(rangePath.Child("subnet"), r.Subnet,
+					fmt.Sprintf("overlaps with the subnet %s", other.String())))
+			}
+		}
+		subnets = append(subnets, subnet)
+		start := parseRangeAddress(r.RangeStart, subnet, rangePath.Child("rangeStart"))
+		end := parseRangeAddress(r.RangeEnd, subnet, rangePath.Child("rangeEnd"))
+		if start != nil && end != nil && bytes.Compare(start.To16(), end.To16()) > 0 {
+			allErrs = append(allErrs, field.Invalid(rangePath.Child("rangeEnd"), r.RangeEnd,
+				"must not be lower than rangeStart"))
+		}
+	}
+	if ipam.Gateway != "" && !inSubnets(ipam.Gateway, subnets) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("gateway"), ipam.Gateway,
+			"must be a valid IP address in the subnet of a range"))
+	}
+	for i, route := range ipam.Routes {
+		routePath := fldPath.Child("routes").Index(i)
+		_, dst, err := net.ParseCIDR(route.Dst)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(routePath.Child("dst"), route.Dst, "must be a valid CIDR"))
+			continue
+		}
+		if route.GW == "" {
+			continue
+		}
+		if !inSubnets(route.GW, subnets) {
+			allErrs = append(allErrs, field.Invalid(routePath.Child("gw"), route.GW,
+				"must be a valid IP address in the subnet of a range"))
+		} else if (net.ParseIP(route.GW).To4() == nil) != (dst.IP.To4() == nil) {
+			allErrs = append(allErrs, field.Invalid(routePath.Child("gw"), route.GW,
+				"must be of the same IP family as dst"))
+		}
+	}
+	return allErrs
+}
+
+// inSubnets returns true if address is a valid IP address in one of the subnets
+func inSubnets(address string, subnets []*net.IPNet) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, subnet := range subnets {
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateDNS checks that the name servers are IP addresses and the domains are valid DNS names
+func validateDNS(dns *v1alpha1.DNSSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, nameserver := range dns.Nameservers {
+		if net.ParseIP(nameserver) == nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("nameservers").Index(i), nameserver,
+				"must be a valid IP address"))
+		}
+	}
+	validateDomain := func(domain string, fldPath *field.Path) {
+		if errs := validation.IsDNS1123Subdomain(strings.TrimSuffix(domain, ".")); len(errs) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath, domain, strings.Join(errs, ", ")))
+		}
+	}
+	if dns.Domain != "" {
+		validateDomain(dns.Domain, fldPath.Child("domain"))
+	}
+	for i, search := range dns.Search {
+		validateDomain(search, fldPath.Child("search").Index(i))
+	}
+	return allErrs
+}
@@ -17,42 +17,84 @@ limitations under the License.
 package validator
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
-	"path/filepath"
 	"regexp"
 	"slices"
 	"strings"
+	"sync"
 
 	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/containers/image/v5/docker/reference"
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/opencontainers/go-digest"
 	"github.com/xeipuuv/gojsonschema"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/yaml"
 
 	"github.com/Mellanox/network-operator/api/v1alpha1"
-	"github.com/Mellanox/network-operator/pkg/config"
+	"github.com/Mellanox/network-operator/pkg/docadriverimages"
+	"github.com/Mellanox/network-operator/pkg/nodeinfo"
 	"github.com/Mellanox/network-operator/pkg/state"
 )
 
 const (
-	fqdnRegex              = `^[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z]{2,})+$`
-	sriovResourceNameRegex = `^([A-Za-z0-9][A-Za-z0-9_.]*)?[A-Za-z0-9]$`
-	rdmaResourceNameRegex  = `^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$`
+	fqdnPattern              = `^[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z]{2,})+$`
+	sriovResourceNamePattern = `^([A-Za-z0-9][A-Za-z0-9_.]*)?[A-Za-z0-9]$`
+	rdmaResourceNamePattern  = `^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$`
+	dtsProviderPattern       = `^[a-z0-9_-]+$`
+	dtsCounterPattern        = `^[A-Za-z0-9_.:-]+$`
+	cronFieldPattern         = `^[A-Za-z0-9*?,/-]+$`
+	pKeyGUIDPattern          = `^([0-9A-Fa-f]{2}:){7}([0-9A-Fa-f]{2})$`
+	ofedVersionPattern       = `^(\d+\.\d+-\d+(\.\d+)*(-\d+)?)$`
+
+	// default resource prefixes of the device plugins, used when resourcePrefix is not set
+	sriovNetworkDevicePluginDefaultResourcePrefix = "nvidia.com"
+	rdmaSharedDevicePluginDefaultResourcePrefix   = "rdma"
+)
+
+var (
+	// the patterns are compiled once per process, the validation helpers are called for every admission request
+	fqdnRegex              = regexp.MustCompile(fqdnPattern)
+	sriovResourceNameRegex = regexp.MustCompile(sriovResourceNamePattern)
+	rdmaResourceNameRegex  = regexp.MustCompile(rdmaResourceNamePattern)
+	dtsProviderRegex       = regexp.MustCompile(dtsProviderPattern)
+	dtsCounterRegex        = regexp.MustCompile(dtsCounterPattern)
+	cronFieldRegex         = regexp.MustCompile(cronFieldPattern)
+	pKeyGUIDRegex          = regexp.MustCompile(pKeyGUIDPattern)
+	ofedVersionRegex       = regexp.MustCompile(ofedVersionPattern)
+
+	supportedImagePullPolicies = []string{string(v1.PullAlways), string(v1.PullNever), string(v1.PullIfNotPresent)}
+	supportedUpdateStrategies  = []string{
+		string(appsv1.RollingUpdateDaemonSetStrategyType), string(appsv1.OnDeleteDaemonSetStrategyType)}
+	supportedSeccompProfileTypes = []string{string(v1.SeccompProfileTypeRuntimeDefault),
+		string(v1.SeccompProfileTypeLocalhost), string(v1.SeccompProfileTypeUnconfined)}
+
+	// OSes precompiled OFED drivers are published for, identified by the NodeLabelOSName node label
+	precompiledDriverOSes = []string{"ubuntu"}
 )
 
 // log is for logging in this package.
@@ -60,11 +102,17 @@ var nicClusterPolicyLog = logf.Log.WithName("nicclusterpolicy-resource")
 
 var schemaValidators *schemaValidator
 
-var skipValidations = false
+var schemaValidatorOnce sync.Once
 
-var envConfig = config.FromEnv().State
+// requiredSchemas are the validation schemas the webhooks validate the CRs with
+var requiredSchemas = []string{"sriov_network_device_plugin", "accelerator_selector", "net_device", "aux_net_device",
+	"rdma_shared_device_plugin", "ipam"}
 
-type nicClusterPolicyValidator struct{}
+var skipValidations = false
+
+type nicClusterPolicyValidator struct {
+	client client.Reader
+}
 
 var _ webhook.CustomValidator = &nicClusterPolicyValidator{}
 
@@ -84,13 +132,22 @@ type docaTelemetryServiceWrapper struct {
 	v1alpha1.DOCATelemetryServiceSpec
 }
 
-// SetupNicClusterPolicyWebhookWithManager sets up the webhook for NicClusterPolicy.
+type whereaboutsSpecWrapper struct {
+	v1alpha1.WhereaboutsSpec
+}
+
+type nvIpamSpecWrapper struct {
+	v1alpha1.NVIPAMSpec
+}
+
+// SetupNicClusterPolicyWebhookWithManager sets up the webhook for NicClusterPolicy, the conversion webhook
+// between its API versions is registered along as the NicClusterPolicy is the conversion hub.
 func SetupNicClusterPolicyWebhookWithManager(mgr ctrl.Manager) error {
 	nicClusterPolicyLog.Info("Nic cluster policy webhook admission controller")
 	InitSchemaValidator("./webhook-schemas")
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&v1alpha1.NicClusterPolicy{}).
-		WithValidator(&nicClusterPolicyValidator{}).
+		WithValidator(&nicClusterPolicyValidator{client: mgr.GetClient()}).
 		Complete()
 }
 
@@ -98,7 +155,8 @@ func SetupNicClusterPolicyWebhookWithManager(mgr ctrl.Manager) error {
 //+kubebuilder:webhook:path=/validate-mellanox-com-v1alpha1-nicclusterpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=mellanox.com,resources=nicclusterpolicies,verbs=create;update,versions=v1alpha1,name=vnicclusterpolicy.kb.io,admissionReviewVersions=v1
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
-func (w *nicClusterPolicyValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+func (w *nicClusterPolicyValidator) ValidateCreate(
+	ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
 	if skipValidations {
 		nicClusterPolicyLog.Info("skipping CR validation")
 		return nil, nil
@@ -109,12 +167,13 @@ func (w *nicClusterPolicyValidator) ValidateCreate(_ context.Context, obj runtim
 		return nil, errors.New("failed to unmarshal NicClusterPolicy object to validate")
 	}
 	nicClusterPolicyLog.Info("validate create", "name", nicClusterPolicy.Name)
-	return nil, w.validateNicClusterPolicy(nicClusterPolicy)
+	warnings := append(getDeviceSelectorWarnings(nicClusterPolicy), w.getNodeCapacityWarnings(ctx, nicClusterPolicy)...)
+	return warnings, w.validateNicClusterPolicy(ctx, nicClusterPolicy)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (w *nicClusterPolicyValidator) ValidateUpdate(
-	_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
 	if skipValidations {
 		nicClusterPolicyLog.Info("skipping CR validation")
 		return nil, nil
@@ -125,7 +184,8 @@ func (w *nicClusterPolicyValidator) ValidateUpdate(
 		return nil, errors.New("failed to unmarshal NicClusterPolicy object to validate")
 	}
 	nicClusterPolicyLog.Info("validate update", "name", nicClusterPolicy.Name)
-	return nil, w.validateNicClusterPolicy(nicClusterPolicy)
+	warnings := append(getDeviceSelectorWarnings(nicClusterPolicy), w.getNodeCapacityWarnings(ctx, nicClusterPolicy)...)
+	return warnings, w.validateNicClusterPolicy(ctx, nicClusterPolicy)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -147,10 +207,21 @@ func (w *nicClusterPolicyValidator) ValidateDelete(_ context.Context, in runtime
 
 /*
 We are validating here NicClusterPolicy:
- 1. IBKubernetes.pKeyGUIDPoolRangeStart and IBKubernetes.pKeyGUIDPoolRangeEnd must be valid GUID and valid range.
+ 1. IBKubernetes
+    1.1 pKeyGUIDPoolRangeStart and pKeyGUIDPoolRangeEnd must be valid GUID and valid range.
+    1.2 ufmTLS.certificateSecret and ufmTLS.caBundle are valid names, the caBundle isn't set with SkipVerify.
  2. OFEDDriver driver configuration
-    2.1 version must be a valid ofed version.
+    2.1 version must be a valid ofed version or a version channel, e.g. latest-24.04.
     2.2 safeLoad feature can be enabled only when autoUpgrade is enabled
+    2.3 forcePrecompiled and disablePrecompiled can't be enabled together
+    2.4 probes have a positive periodSeconds, a non-negative initialDelaySeconds and timeoutSeconds <= periodSeconds
+    2.5 forcePrecompiled is only enabled if the selected nodes run an OS precompiled drivers are published for
+    2.6 upgradePolicy.nodeOrder sets a valid labelKey with label policy and nodes with nodeList policy
+    2.7 upgradePolicy.canary.nodeSelector is a valid node selector
+    2.8 upgradePolicy.drain has a non-negative timeoutSeconds, valid podSelector and namespacePodSelectors
+    label selectors and deleteEmptyDir enabled only with force
+    2.9 updateStrategy is OnDelete when upgradePolicy.autoUpgrade is enabled
+    2.10 securityContext.privileged isn't disabled as the driver loads kernel modules
  3. RdmaSharedDevicePlugin.Config.
     3.1. Configuration is a valid JSON and check its schema.
     3.2. resourceName is valid for k8s.
@@ -163,8 +234,52 @@ We are validating here NicClusterPolicy:
     4.4. All selectors are strings.
  5. DocaTelemetryService.Config.
     5.1 config.FromConfigMap is valid
+ 6. Tolerations
+    6.1. key is a valid qualified name, empty key requires Exists operator.
+    6.2. operator and effect are supported, Exists operator requires empty value.
+ 7. NodeAffinity
+    7.1. node selector terms are not empty and use supported operators with valid keys and values.
+    7.2. match expressions of a term don't contradict each other (e.g. conflicting In/NotIn values).
+    7.3. the nodeSelector, nodeAffinity and tolerations of the components are valid.
+ 8. RawPatches
+    8.1. target kind is set.
+    8.2. patch is a valid YAML or JSON, a JSON6902 patch is a valid list of operations.
+ 9. ContainerResources
+    9.1. container name is a container of the state whose resources can be set.
+    9.2. resources are cpu, memory, ephemeral-storage or hugepages-<size>, quantities are not zero.
+    9.3. requests don't exceed limits, hugepages requests are equal to limits.
+    9.4. a warning is returned if the requests exceed the allocatable resources of every schedulable node selected
+    for the component.
+ 10. SecondaryNetwork.IpamPlugin
+    10.1. reconcilerCronExpression is a cron expression with five fields.
+ 11. NvIpam.Pools and NvIpam.CIDRPools
+    11.1. pool names are valid and unique.
+    11.2. subnet or cidr is a valid CIDR which doesn't overlap with the subnets of the other pools.
+    11.3. gateway is an address in the subnet, perNodeBlockSize is less than the size of the subnet.
+    11.4. perNodeNetworkPrefix is longer than the prefix of the cidr, gatewayIndex is in the network of a node.
+    11.5. exclusions are ranges of addresses in the cidr.
+ 12. RdmaSharedDevicePlugin.Config and SriovNetworkDevicePlugin.Config
+    12.1. a resourcePrefix/resourceName pair is declared only once across the configs of the device plugins.
+ 13. NodeSelector
+    13.1. keys and values are valid labels.
+    13.2. ibKubernetes, nvIpam and the IPAM plugin are only configured in the nic-cluster-policy policy.
+    13.3. if there are several policies, the node selector is set and doesn't overlap with the other policies.
+    13.4. if there are several policies, the OFED driver is configured in only one of them.
+ 14. Images
+    14.1. repository is a valid image repository.
+    14.2. version which is a digest, e.g. sha256:<hex>, is a valid digest.
+    14.3. imagePullPolicy is Always, Never or IfNotPresent.
+    14.4. updateStrategy is RollingUpdate or OnDelete, rollingUpdate is only set with RollingUpdate, its maxUnavailable
+    and maxSurge are non-negative numbers or percentages which are not both zero.
+    14.5. securityContext.seccompProfile is only set when privileged is disabled, its type is supported and
+    localhostProfile is set only with the Localhost type.
+ 15. Proxy
+    15.1. httpProxy and httpsProxy are URLs with http or https scheme and a host.
+ 16. PriorityClassName
+    16.1. priorityClassName of the policy and of the components is a valid DNS subdomain.
 */
-func (w *nicClusterPolicyValidator) validateNicClusterPolicy(in *v1alpha1.NicClusterPolicy) error {
+func (w *nicClusterPolicyValidator) validateNicClusterPolicy(
+	ctx context.Context, in *v1alpha1.NicClusterPolicy) error {
 	var allErrs field.ErrorList
 	// Validate Repository
 	allErrs = w.validateRepositories(in, allErrs)
@@ -180,9 +295,19 @@ func (w *nicClusterPolicyValidator) validateNicClusterPolicy(in *v1alpha1.NicClu
 	if ofedDriver != nil {
 		wrapper := ofedDriverSpecWrapper{OFEDDriverSpec: *in.Spec.OFEDDriver}
 		ofedDriverFieldPath := field.NewPath("spec").Child("ofedDriver")
-		allErrs = append(append(allErrs,
+		allErrs = append(append(append(allErrs,
 			wrapper.validateVersion(ofedDriverFieldPath)...),
-			wrapper.validateSafeLoad(ofedDriverFieldPath)...)
+			wrapper.validateSafeLoad(ofedDriverFieldPath)...),
+			wrapper.validatePrecompiled(ofedDriverFieldPath)...)
+		allErrs = append(append(append(allErrs,
+			wrapper.validateProbes(ofedDriverFieldPath)...),
+			w.validatePrecompiledNodes(ctx, in, ofedDriverFieldPath)...),
+			wrapper.validateNodeOrder(ofedDriverFieldPath)...)
+		allErrs = append(append(append(allErrs,
+			wrapper.validateCanary(ofedDriverFieldPath)...),
+			wrapper.validateDrain(ofedDriverFieldPath)...),
+			wrapper.validateUpdateStrategy(ofedDriverFieldPath)...)
+		allErrs = append(allErrs, wrapper.validateSecurityContext(ofedDriverFieldPath)...)
 	}
 	// Validate RdmaSharedDevicePlugin
 	rdmaSharedDevicePlugin := in.Spec.RdmaSharedDevicePlugin
@@ -198,6 +323,7 @@ func (w *nicClusterPolicyValidator) validateNicClusterPolicy(in *v1alpha1.NicClu
 		allErrs = append(allErrs, wrapper.validateSriovNetworkDevicePlugin(
 			field.NewPath("spec").Child("sriovNetworkDevicePlugin"))...)
 	}
+	allErrs = append(allErrs, validateDevicePluginResourceNames(&in.Spec)...)
 	// Validate DOCATelemetryService
 	docaTelemetryService := in.Spec.DOCATelemetryService
 	if docaTelemetryService != nil {
@@ -205,6 +331,32 @@ func (w *nicClusterPolicyValidator) validateNicClusterPolicy(in *v1alpha1.NicClu
 		allErrs = append(allErrs, dtsWrapper.validate(
 			field.NewPath("spec").Child("docaTelemetryService"))...)
 	}
+	// Validate whereabouts IPAM plugin
+	if in.Spec.SecondaryNetwork != nil && in.Spec.SecondaryNetwork.IpamPlugin != nil {
+		wrapper := whereaboutsSpecWrapper{WhereaboutsSpec: *in.Spec.SecondaryNetwork.IpamPlugin}
+		allErrs = append(allErrs, wrapper.validate(
+			field.NewPath("spec").Child("secondaryNetwork", "ipamPlugin"))...)
+	}
+	// Validate NvIpam
+	if in.Spec.NvIpam != nil {
+		wrapper := nvIpamSpecWrapper{NVIPAMSpec: *in.Spec.NvIpam}
+		allErrs = append(allErrs, wrapper.validatePools(field.NewPath("spec").Child("nvIpam"))...)
+	}
+	if in.Spec.NodeFeatureDiscovery != nil {
+		allErrs = append(allErrs, validateNodeFeatureDiscovery(in.Spec.NodeFeatureDiscovery,
+			field.NewPath("spec").Child("nodeFeatureDiscovery"))...)
+	}
+	// Validate Tolerations and NodeAffinity
+	allErrs = append(append(allErrs,
+		validateTolerations(in.Spec.Tolerations, field.NewPath("spec").Child("tolerations"))...),
+		validateNodeAffinity(in.Spec.NodeAffinity, field.NewPath("spec").Child("nodeAffinity"))...)
+	allErrs = append(allErrs, validateComponentsScheduling(&in.Spec)...)
+	allErrs = append(allErrs, validateRawPatches(in.Spec.RawPatches, field.NewPath("spec").Child("rawPatches"))...)
+	allErrs = append(allErrs, w.validatePolicyScope(ctx, in)...)
+	allErrs = append(allErrs, validateProxy(in.Spec.Proxy, field.NewPath("spec").Child("proxy"))...)
+	allErrs = append(allErrs,
+		validatePriorityClassName(in.Spec.PriorityClassName, field.NewPath("spec").Child("priorityClassName"))...)
+	allErrs = append(allErrs, validateDefaultIPAM(&in.Spec, field.NewPath("spec").Child("defaultIpam"))...)
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -213,6 +365,166 @@ func (w *nicClusterPolicyValidator) validateNicClusterPolicy(in *v1alpha1.NicClu
 		in.Name, allErrs)
 }
 
+// minNodeFeatureDiscoveryVersion is the first release of Node Feature Discovery with the NodeFeature API
+var minNodeFeatureDiscoveryVersion = semver.MustParse("v0.13.0")
+
+// validateNodeFeatureDiscovery checks that the version of Node Feature Discovery is pinned, either to a digest or
+// to a release providing the NodeFeature API, a moving tag, e.g. latest, could silently upgrade NFD
+func validateNodeFeatureDiscovery(spec *v1alpha1.NodeFeatureDiscoverySpec, fldPath *field.Path) field.ErrorList {
+	if spec.IsDigest() {
+		return nil
+	}
+	allErrs := field.ErrorList{}
+	version, err := semver.NewVersion(spec.Version)
+	switch {
+	case err != nil:
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("version"), spec.Version,
+			"the version of Node Feature Discovery must be a release, e.g. v0.13.2, or an image digest"))
+	case version.LessThan(minNodeFeatureDiscoveryVersion):
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("version"), spec.Version,
+			fmt.Sprintf("Node Feature Discovery %s or newer is required", minNodeFeatureDiscoveryVersion.Original())))
+	}
+	return allErrs
+}
+
+// validateProxy checks that the proxies are http or https URLs with a host
+func validateProxy(proxy *v1alpha1.ProxySpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if proxy == nil {
+		return allErrs
+	}
+	for _, param := range [][]string{{"httpProxy", proxy.HTTPProxy}, {"httpsProxy", proxy.HTTPSProxy}} {
+		name, proxyURL := param[0], param[1]
+		if proxyURL == "" {
+			continue
+		}
+		u, err := url.Parse(proxyURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(name), proxyURL,
+				"must be a URL with http or https scheme, e.g. http://proxy.example.com:3128"))
+		}
+	}
+	return allErrs
+}
+
+// validatePriorityClassName checks that the priority class name is a valid DNS subdomain, as the name of
+// a PriorityClass object
+func validatePriorityClassName(name string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if name == "" {
+		return allErrs
+	}
+	for _, msg := range validation.IsDNS1123Subdomain(name) {
+		allErrs = append(allErrs, field.Invalid(fldPath, name, msg))
+	}
+	return allErrs
+}
+
+// validateDefaultIPAM checks that the default IPAM plugin is deployed by the policy, and that it is set if both
+// IPAM plugins are deployed, otherwise the IPAM plugin of the networks would be ambiguous
+func validateDefaultIPAM(spec *v1alpha1.NicClusterPolicySpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	whereabouts := spec.SecondaryNetwork != nil && spec.SecondaryNetwork.IpamPlugin != nil
+	nvIpam := spec.NvIpam != nil
+	switch spec.DefaultIPAM {
+	case "":
+		if whereabouts && nvIpam {
+			allErrs = append(allErrs, field.Required(fldPath,
+				"must be set when both secondaryNetwork.ipamPlugin and nvIpam are configured"))
+		}
+	case v1alpha1.IPAMPluginWhereabouts:
+		if !whereabouts {
+			allErrs = append(allErrs, field.Invalid(fldPath, spec.DefaultIPAM,
+				"secondaryNetwork.ipamPlugin is not configured"))
+		}
+	case v1alpha1.IPAMPluginNVIPAM:
+		if !nvIpam {
+			allErrs = append(allErrs, field.Invalid(fldPath, spec.DefaultIPAM, "nvIpam is not configured"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath, spec.DefaultIPAM,
+			[]string{string(v1alpha1.IPAMPluginWhereabouts), string(v1alpha1.IPAMPluginNVIPAM)}))
+	}
+	return allErrs
+}
+
+func validateRawPatches(patches []v1alpha1.RawPatch, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, patch := range patches {
+		idxPath := fldPath.Index(i)
+		if patch.Target.Kind == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("target").Child("kind"), "kind is required"))
+		}
+		patchJSON, err := yaml.YAMLToJSON([]byte(patch.Patch))
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("patch"), patch.Patch,
+				"Invalid YAML or JSON of patch: "+err.Error()))
+			continue
+		}
+		switch patch.Type {
+		case v1alpha1.RawPatchTypeJSON6902:
+			if _, err := jsonpatch.DecodePatch(patchJSON); err != nil {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("patch"), patch.Patch,
+					"Invalid JSON6902 patch: "+err.Error()))
+			}
+		case v1alpha1.RawPatchTypeStrategicMerge, "":
+			var patchObj map[string]interface{}
+			if err := json.Unmarshal(patchJSON, &patchObj); err != nil {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("patch"), patch.Patch,
+					"Invalid strategic merge patch, must be an object"))
+			}
+		default:
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("type"), patch.Type,
+				[]string{string(v1alpha1.RawPatchTypeStrategicMerge), string(v1alpha1.RawPatchTypeJSON6902)}))
+		}
+	}
+	return allErrs
+}
+
+// devicePluginResourceConfig is a resource declared in resourceList of the SR-IOV device plugin config
+// or in configList of the RDMA shared device plugin config
+type devicePluginResourceConfig struct {
+	ResourceName   string `json:"resourceName"`
+	ResourcePrefix string `json:"resourcePrefix"`
+}
+
+// validateDevicePluginResourceNames checks that the resources of the device plugins are declared only once,
+// the kubelet behavior is undefined when several device plugins register the same resource.
+// Configs which are not valid are skipped as they are reported by the validation of each device plugin.
+func validateDevicePluginResourceNames(spec *v1alpha1.NicClusterPolicySpec) field.ErrorList {
+	var allErrs field.ErrorList
+	declared := map[string]struct{}{}
+	validate := func(dp *v1alpha1.DevicePluginSpec, defaultPrefix string, fldPath *field.Path) {
+		if dp == nil || dp.Config == nil {
+			return
+		}
+		var config struct {
+			ResourceList []devicePluginResourceConfig `json:"resourceList"`
+			ConfigList   []devicePluginResourceConfig `json:"configList"`
+		}
+		if err := json.Unmarshal([]byte(*dp.Config), &config); err != nil {
+			return
+		}
+		for _, resource := range append(config.ResourceList, config.ConfigList...) {
+			prefix := resource.ResourcePrefix
+			if prefix == "" {
+				prefix = defaultPrefix
+			}
+			name := prefix + "/" + resource.ResourceName
+			if _, exists := declared[name]; exists {
+				allErrs = append(allErrs, field.Duplicate(fldPath.Child("Config"), name))
+				continue
+			}
+			declared[name] = struct{}{}
+		}
+	}
+	validate(spec.RdmaSharedDevicePlugin, rdmaSharedDevicePluginDefaultResourcePrefix,
+		field.NewPath("spec").Child("rdmaSharedDevicePlugin"))
+	validate(spec.SriovDevicePlugin, sriovNetworkDevicePluginDefaultResourcePrefix,
+		field.NewPath("spec").Child("sriovNetworkDevicePlugin"))
+	return allErrs
+}
+
 func (dp *devicePluginSpecWrapper) validateSriovNetworkDevicePlugin(fldPath *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
 	var sriovNetworkDevicePluginConfigJSON map[string]interface{}
@@ -309,7 +621,7 @@ func validateResourceNamePrefix(resource map[string]interface{},
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("Config"), dp.Config,
 			"Invalid Resource name, it must consist of alphanumeric characters, '_' or '.', "+
 				"and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  "+
-				"or '123_abc', regex used for validation is "+sriovResourceNameRegex))
+				"or '123_abc', regex used for validation is "+sriovResourceNamePattern))
 		return false, allErrs
 	}
 	resourcePrefix, ok := resource["resourcePrefix"]
@@ -317,7 +629,7 @@ func validateResourceNamePrefix(resource map[string]interface{},
 		if !isValidFQDN(resourcePrefix.(string)) {
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("Config"), dp.Config,
 				"Invalid Resource prefix, it must be a valid FQDN"+
-					"regex used for validation is "+fqdnRegex))
+					"regex used for validation is "+fqdnPattern))
 			return false, allErrs
 		}
 	}
@@ -358,14 +670,14 @@ func (dp *devicePluginSpecWrapper) validateRdmaSharedDevicePlugin(fldPath *field
 				allErrs = append(allErrs, field.Invalid(fldPath.Child("Config"),
 					dp.Config, "Invalid Resource name, it must consist of alphanumeric characters, "+
 						"'-', '_' or '.', and must start and end with an alphanumeric character "+
-						"(e.g. 'MyName',  or 'my.name',  or '123-abc') regex used for validation is "+rdmaResourceNameRegex))
+						"(e.g. 'MyName',  or 'my.name',  or '123-abc') regex used for validation is "+rdmaResourceNamePattern))
 			}
 			resourcePrefix, ok := dpConfig["resourcePrefix"]
 			if ok {
 				if !isValidFQDN(resourcePrefix.(string)) {
 					allErrs = append(allErrs, field.Invalid(fldPath.Child("Config"), dp.Config,
 						"Invalid Resource prefix, it must be a valid FQDN "+
-							"regex used for validation is "+fqdnRegex))
+							"regex used for validation is "+fqdnPattern))
 					return allErrs
 				}
 			}
@@ -380,8 +692,23 @@ func (dp *devicePluginSpecWrapper) validateRdmaSharedDevicePlugin(fldPath *field
 
 func (dts *docaTelemetryServiceWrapper) validate(fldPath *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
+	// providers and counters are written to the default config, each in a single line
+	for i, provider := range dts.Providers {
+		if !dtsProviderRegex.MatchString(provider) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("providers").Index(i), provider,
+				"provider name must consist of lower case alphanumeric characters, '-' or '_'"))
+		}
+	}
+	if dts.Prometheus != nil {
+		for i, counter := range dts.Prometheus.IgnoreCounters {
+			if !dtsCounterRegex.MatchString(counter) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("prometheus", "ignoreCounters").Index(i), counter,
+					"counter name must consist of alphanumeric characters, '-', '_', '.' or ':'"))
+			}
+		}
+	}
 	if dts.Config == nil {
-		return nil
+		return allErrs
 	}
 	if errs := validation.IsDNS1123Subdomain(dts.Config.FromConfigMap); len(errs) > 0 {
 		allErrs = append(allErrs,
@@ -390,9 +717,123 @@ func (dts *docaTelemetryServiceWrapper) validate(fldPath *field.Path) field.Erro
 	return allErrs
 }
 
+// validate is a helper function to perform validation for WhereaboutsSpec.
+func (w *whereaboutsSpecWrapper) validate(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if w.ReconcilerCronExpression == "" {
+		return allErrs
+	}
+	fields := strings.Fields(w.ReconcilerCronExpression)
+	valid := len(fields) == 5
+	for _, cronField := range fields {
+		valid = valid && cronFieldRegex.MatchString(cronField)
+	}
+	if !valid {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("reconcilerCronExpression"),
+			w.ReconcilerCronExpression, "must be a cron expression with five fields, e.g. \"30 4 * * *\""))
+	}
+	return allErrs
+}
+
+// nvIpamPoolSubnet is the subnet of an nv-ipam pool, the subnets of the pools must not overlap
+type nvIpamPoolSubnet struct {
+	name   string
+	subnet *net.IPNet
+}
+
+// validatePools is a helper function to perform validation for the IP pools and CIDR pools of NVIPAMSpec.
+func (w *nvIpamSpecWrapper) validatePools(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	var subnets []nvIpamPoolSubnet
+	names := map[string]bool{}
+	for i, pool := range w.Pools {
+		poolPath := fldPath.Child("pools").Index(i)
+		allErrs = append(allErrs, validatePoolName(pool.Name, names, poolPath.Child("name"))...)
+		_, subnet, err := net.ParseCIDR(pool.Subnet)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(poolPath.Child("subnet"), pool.Subnet, "must be a valid CIDR"))
+			continue
+		}
+		allErrs = append(allErrs, validatePoolOverlap(subnet, subnets, poolPath.Child("subnet"))...)
+		subnets = append(subnets, nvIpamPoolSubnet{name: pool.Name, subnet: subnet})
+		gateway := net.ParseIP(pool.Gateway)
+		if gateway == nil || !subnet.Contains(gateway) {
+			allErrs = append(allErrs, field.Invalid(poolPath.Child("gateway"), pool.Gateway,
+				"must be a valid IP address in the subnet"))
+		}
+		ones, bits := subnet.Mask.Size()
+		if bits-ones < 63 && pool.PerNodeBlockSize >= 1<<(bits-ones) {
+			allErrs = append(allErrs, field.Invalid(poolPath.Child("perNodeBlockSize"), pool.PerNodeBlockSize,
+				"must be less than the amount of IPs in the subnet"))
+		}
+	}
+	names = map[string]bool{}
+	for i, pool := range w.CIDRPools {
+		poolPath := fldPath.Child("cidrPools").Index(i)
+		allErrs = append(allErrs, validatePoolName(pool.Name, names, poolPath.Child("name"))...)
+		_, cidr, err := net.ParseCIDR(pool.CIDR)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(poolPath.Child("cidr"), pool.CIDR, "must be a valid CIDR"))
+			continue
+		}
+		allErrs = append(allErrs, validatePoolOverlap(cidr, subnets, poolPath.Child("cidr"))...)
+		subnets = append(subnets, nvIpamPoolSubnet{name: pool.Name, subnet: cidr})
+		ones, bits := cidr.Mask.Size()
+		prefix := int(pool.PerNodeNetworkPrefix)
+		if prefix <= ones || prefix > bits {
+			allErrs = append(allErrs, field.Invalid(poolPath.Child("perNodeNetworkPrefix"), pool.PerNodeNetworkPrefix,
+				fmt.Sprintf("must be longer than the prefix of the CIDR and not longer than %d", bits)))
+		} else if pool.GatewayIndex != nil && bits-prefix < 31 && int(*pool.GatewayIndex) >= 1<<(bits-prefix) {
+			allErrs = append(allErrs, field.Invalid(poolPath.Child("gatewayIndex"), *pool.GatewayIndex,
+				"must be less than the amount of IPs in the network of a node"))
+		}
+		for j, exclusion := range pool.Exclusions {
+			exclusionPath := poolPath.Child("exclusions").Index(j)
+			startIP, endIP := net.ParseIP(exclusion.StartIP), net.ParseIP(exclusion.EndIP)
+			if startIP == nil || !cidr.Contains(startIP) {
+				allErrs = append(allErrs, field.Invalid(exclusionPath.Child("startIP"), exclusion.StartIP,
+					"must be a valid IP address in the CIDR"))
+			}
+			if endIP == nil || !cidr.Contains(endIP) {
+				allErrs = append(allErrs, field.Invalid(exclusionPath.Child("endIP"), exclusion.EndIP,
+					"must be a valid IP address in the CIDR"))
+			}
+			if startIP != nil && endIP != nil && bytes.Compare(startIP.To16(), endIP.To16()) > 0 {
+				allErrs = append(allErrs, field.Invalid(exclusionPath.Child("endIP"), exclusion.EndIP,
+					"must not be lower than startIP"))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validatePoolName checks that the name of an nv-ipam pool is valid and unique
+func validatePoolName(name string, names map[string]bool, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath, name, strings.Join(errs, ", ")))
+	} else if names[name] {
+		allErrs = append(allErrs, field.Duplicate(fldPath, name))
+	}
+	names[name] = true
+	return allErrs
+}
+
+// validatePoolOverlap checks that the subnet of an nv-ipam pool doesn't overlap with the subnets of the other pools
+func validatePoolOverlap(subnet *net.IPNet, subnets []nvIpamPoolSubnet, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, other := range subnets {
+		if other.subnet.Contains(subnet.IP) || subnet.Contains(other.subnet.IP) {
+			allErrs = append(allErrs, field.Invalid(fldPath, subnet.String(),
+				fmt.Sprintf("overlaps with the subnet of pool %s", other.name)))
+		}
+	}
+	return allErrs
+}
+
 // validate is a helper function to perform validation for IBKubernetesSpec.
 func (ibk *ibKubernetesSpecWrapper) validate(fldPath *field.Path) field.ErrorList {
-	var allErrs field.ErrorList
+	allErrs := ibk.validateUfmTLS(fldPath.Child("ufmTLS"))
 
 	if !isValidPKeyGUID(ibk.PKeyGUIDPoolRangeStart) || !isValidPKeyGUID(ibk.PKeyGUIDPoolRangeEnd) {
 		if !isValidPKeyGUID(ibk.PKeyGUIDPoolRangeStart) {
@@ -413,11 +854,36 @@ func (ibk *ibKubernetesSpecWrapper) validate(fldPath *field.Path) field.ErrorLis
 	return allErrs
 }
 
+// validateUfmTLS checks the names of the Secret and ConfigMap of the UFM TLS configuration, and that the CA bundle
+// is not set if the verification of the certificate of UFM is skipped
+func (ibk *ibKubernetesSpecWrapper) validateUfmTLS(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	tls := ibk.UfmTLS
+	if tls == nil {
+		return allErrs
+	}
+	if tls.CertificateSecret != "" {
+		if errs := validation.IsDNS1123Subdomain(tls.CertificateSecret); len(errs) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("certificateSecret"), tls.CertificateSecret,
+				strings.Join(errs, ", ")))
+		}
+	}
+	if tls.CABundle == "" {
+		return allErrs
+	}
+	if errs := validation.IsDNS1123Subdomain(tls.CABundle); len(errs) > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("caBundle"), tls.CABundle, strings.Join(errs, ", ")))
+	}
+	if tls.VerificationMode == v1alpha1.UFMTLSVerificationModeSkipVerify {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("caBundle"),
+			"caBundle can't be set when the verificationMode is SkipVerify"))
+	}
+	return allErrs
+}
+
 // isValidPKeyGUID checks if a given string is a valid GUID format.
 func isValidPKeyGUID(guid string) bool {
-	PKeyGUIDPattern := `^([0-9A-Fa-f]{2}:){7}([0-9A-Fa-f]{2})$`
-	PKeyGUIDRegex := regexp.MustCompile(PKeyGUIDPattern)
-	return PKeyGUIDRegex.MatchString(guid)
+	return pKeyGUIDRegex.MatchString(guid)
 }
 
 // isValidPKeyRange checks if range of startGUID and endGUID sis valid
@@ -436,9 +902,10 @@ func (ofedSpec *ofedDriverSpecWrapper) validateVersion(fldPath *field.Path) fiel
 	allErrs := field.ErrorList{}
 
 	// Perform version validation logic here
-	if !isValidOFEDVersion(ofedSpec.Version) {
+	if !isValidOFEDVersion(ofedSpec.Version) && !docadriverimages.IsVersionChannel(ofedSpec.Version) {
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("version"), ofedSpec.Version,
-			`invalid OFED version, the regex used for validation is ^(\d+\.\d+-\d+(\.\d+)*)$ `))
+			`invalid OFED version, the regex used for validation is ^(\d+\.\d+-\d+(\.\d+)*)$ `+
+				`or a version channel latest, latest-<major> or latest-<major>.<minor|xx>`))
 	}
 	return allErrs
 }
@@ -461,72 +928,316 @@ func (ofedSpec *ofedDriverSpecWrapper) validateSafeLoad(fldPath *field.Path) fie
 	return allErrs
 }
 
-func (w *nicClusterPolicyValidator) validateRepositories(
-	in *v1alpha1.NicClusterPolicy, allErrs field.ErrorList) field.ErrorList {
-	fp := field.NewPath("spec")
-	if in.Spec.OFEDDriver != nil {
-		allErrs = validateRepository(in.Spec.OFEDDriver.ImageSpec.Repository, allErrs, fp, "nicFeatureDiscovery")
+// validateSecurityContext checks that the driver container stays privileged, as it loads the kernel modules
+func (ofedSpec *ofedDriverSpecWrapper) validateSecurityContext(fldPath *field.Path) field.ErrorList {
+	securityContext := ofedSpec.SecurityContext
+	if securityContext == nil || securityContext.Privileged == nil || *securityContext.Privileged {
+		return nil
 	}
-	if in.Spec.RdmaSharedDevicePlugin != nil {
-		allErrs = validateRepository(in.Spec.RdmaSharedDevicePlugin.ImageSpec.Repository,
-			allErrs, fp, "rdmaSharedDevicePlugin")
+	return field.ErrorList{field.Forbidden(fldPath.Child("securityContext", "privileged"),
+		"the driver container must be privileged to load the kernel modules")}
+}
+
+// validateUpdateStrategy checks that the pods of the driver are restarted only by the upgrade process
+// if the automatic upgrade is enabled
+func (ofedSpec *ofedDriverSpecWrapper) validateUpdateStrategy(fldPath *field.Path) field.ErrorList {
+	upgradePolicy := ofedSpec.OfedUpgradePolicy
+	if upgradePolicy == nil || !upgradePolicy.AutoUpgrade || ofedSpec.UpdateStrategy == nil {
+		return nil
 	}
-	if in.Spec.SriovDevicePlugin != nil {
-		allErrs = validateRepository(in.Spec.SriovDevicePlugin.ImageSpec.Repository, allErrs, fp, "sriovDevicePlugin")
+	if ofedSpec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+		return nil
 	}
-	if in.Spec.IBKubernetes != nil {
-		allErrs = validateRepository(in.Spec.IBKubernetes.ImageSpec.Repository, allErrs, fp, "ibKubernetes")
+	return field.ErrorList{field.Forbidden(fldPath.Child("updateStrategy", "type"),
+		fmt.Sprintf("updateStrategy must be OnDelete when %s is true",
+			fldPath.Child("upgradePolicy", "autoUpgrade").String()))}
+}
+
+func (ofedSpec *ofedDriverSpecWrapper) validatePrecompiled(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if ofedSpec.ForcePrecompiled && ofedSpec.DisablePrecompiled {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("disablePrecompiled"),
+			fmt.Sprintf("disablePrecompiled can't be set together with %s",
+				fldPath.Child("forcePrecompiled").String())))
 	}
-	if in.Spec.NvIpam != nil {
-		allErrs = validateRepository(in.Spec.NvIpam.ImageSpec.Repository, allErrs, fp, "nvIpam")
+	return allErrs
+}
+
+// validateProbes checks that the probes of the driver container can pass, the probe fails if its timeout exceeds
+// its period and a zero period is replaced by the default of Kubernetes instead of the expected value
+func (ofedSpec *ofedDriverSpecWrapper) validateProbes(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	probes := []struct {
+		name  string
+		probe *v1alpha1.PodProbeSpec
+	}{
+		{"startupProbe", ofedSpec.StartupProbe},
+		{"livenessProbe", ofedSpec.LivenessProbe},
+		{"readinessProbe", ofedSpec.ReadinessProbe},
 	}
-	if in.Spec.NicFeatureDiscovery != nil {
-		allErrs = validateRepository(in.Spec.NicFeatureDiscovery.ImageSpec.Repository, allErrs, fp, "nicFeatureDiscovery")
+	for _, p := range probes {
+		if p.probe == nil {
+			continue
+		}
+		probePath := fldPath.Child(p.name)
+		if p.probe.InitialDelaySeconds < 0 {
+			allErrs = append(allErrs, field.Invalid(probePath.Child("initialDelaySeconds"),
+				p.probe.InitialDelaySeconds, "must be greater than or equal to 0"))
+		}
+		if p.probe.PeriodSeconds <= 0 {
+			allErrs = append(allErrs, field.Invalid(probePath.Child("periodSeconds"),
+				p.probe.PeriodSeconds, "must be greater than 0"))
+		}
+		if p.probe.TimeoutSeconds < 0 {
+			allErrs = append(allErrs, field.Invalid(probePath.Child("timeoutSeconds"),
+				p.probe.TimeoutSeconds, "must be greater than or equal to 0"))
+		}
+		if p.probe.TimeoutSeconds > 0 && p.probe.PeriodSeconds > 0 && p.probe.PeriodSeconds < p.probe.TimeoutSeconds {
+			allErrs = append(allErrs, field.Invalid(probePath.Child("periodSeconds"), p.probe.PeriodSeconds,
+				fmt.Sprintf("must be greater than or equal to %s", probePath.Child("timeoutSeconds").String())))
+		}
 	}
-	if in.Spec.DOCATelemetryService != nil {
-		allErrs = validateRepository(in.Spec.DOCATelemetryService.ImageSpec.Repository, allErrs, fp, "docaTelemetryService")
+	return allErrs
+}
+
+// validateNodeOrder checks that the node order policy has the settings it orders the nodes by
+func (ofedSpec *ofedDriverSpecWrapper) validateNodeOrder(fldPath *field.Path) field.ErrorList {
+	if ofedSpec.OfedUpgradePolicy == nil || ofedSpec.OfedUpgradePolicy.NodeOrder == nil {
+		return nil
 	}
-	if in.Spec.SecondaryNetwork != nil {
-		snfp := fp.Child("secondaryNetwork")
-		if in.Spec.SecondaryNetwork.CniPlugins != nil {
-			allErrs = validateRepository(in.Spec.SecondaryNetwork.CniPlugins.Repository, allErrs, snfp, "cniPlugins")
+	allErrs := field.ErrorList{}
+	nodeOrder := ofedSpec.OfedUpgradePolicy.NodeOrder
+	nodeOrderPath := fldPath.Child("upgradePolicy", "nodeOrder")
+	switch nodeOrder.Policy {
+	case v1alpha1.NodeOrderPolicyLabel:
+		if nodeOrder.LabelKey == "" {
+			allErrs = append(allErrs, field.Required(nodeOrderPath.Child("labelKey"),
+				fmt.Sprintf("labelKey is required with %s policy", v1alpha1.NodeOrderPolicyLabel)))
+			break
 		}
-		if in.Spec.SecondaryNetwork.IPoIB != nil {
-			allErrs = validateRepository(in.Spec.SecondaryNetwork.IPoIB.Repository, allErrs, snfp, "ipoib")
+		for _, msg := range validation.IsQualifiedName(nodeOrder.LabelKey) {
+			allErrs = append(allErrs, field.Invalid(nodeOrderPath.Child("labelKey"), nodeOrder.LabelKey, msg))
 		}
-		if in.Spec.SecondaryNetwork.Multus != nil {
-			allErrs = validateRepository(in.Spec.SecondaryNetwork.Multus.Repository, allErrs, snfp, "multus")
+	case v1alpha1.NodeOrderPolicyNodeList:
+		if len(nodeOrder.Nodes) == 0 {
+			allErrs = append(allErrs, field.Required(nodeOrderPath.Child("nodes"),
+				fmt.Sprintf("nodes are required with %s policy", v1alpha1.NodeOrderPolicyNodeList)))
 		}
-		if in.Spec.SecondaryNetwork.IpamPlugin != nil {
-			allErrs = validateRepository(in.Spec.SecondaryNetwork.IpamPlugin.Repository, allErrs, snfp, "ipamPlugin")
+	}
+	return allErrs
+}
+
+// validateCanary checks that the canary nodes are selected by a valid node selector
+func (ofedSpec *ofedDriverSpecWrapper) validateCanary(fldPath *field.Path) field.ErrorList {
+	if ofedSpec.OfedUpgradePolicy == nil || ofedSpec.OfedUpgradePolicy.Canary == nil {
+		return nil
+	}
+	nodeSelectorPath := fldPath.Child("upgradePolicy", "canary", "nodeSelector")
+	if len(ofedSpec.OfedUpgradePolicy.Canary.NodeSelector) == 0 {
+		return field.ErrorList{field.Required(nodeSelectorPath, "nodeSelector is required to select the canary nodes")}
+	}
+	return validateNodeSelectorLabels(ofedSpec.OfedUpgradePolicy.Canary.NodeSelector, nodeSelectorPath)
+}
+
+// validateDrain checks that the drain settings of the upgrade policy are valid, so that the drain
+// doesn't fail because of them during the upgrade
+func (ofedSpec *ofedDriverSpecWrapper) validateDrain(fldPath *field.Path) field.ErrorList {
+	if ofedSpec.OfedUpgradePolicy == nil || ofedSpec.OfedUpgradePolicy.DrainSpec == nil {
+		return nil
+	}
+	allErrs := field.ErrorList{}
+	drainSpec := ofedSpec.OfedUpgradePolicy.DrainSpec
+	drainPath := fldPath.Child("upgradePolicy", "drain")
+	if drainSpec.TimeoutSecond < 0 {
+		allErrs = append(allErrs, field.Invalid(drainPath.Child("timeoutSeconds"), drainSpec.TimeoutSecond,
+			"must be greater than or equal to 0"))
+	}
+	if _, err := labels.Parse(drainSpec.PodSelector); err != nil {
+		allErrs = append(allErrs, field.Invalid(drainPath.Child("podSelector"), drainSpec.PodSelector, err.Error()))
+	}
+	if drainSpec.DeleteEmptyDir && !drainSpec.Force {
+		allErrs = append(allErrs, field.Forbidden(drainPath.Child("deleteEmptyDir"),
+			"deleteEmptyDir can only be enabled together with force"))
+	}
+	selectorsPath := drainPath.Child("namespacePodSelectors")
+	for i, selector := range drainSpec.NamespacePodSelectors {
+		if _, err := labels.Parse(selector.PodSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(selectorsPath.Index(i).Child("podSelector"),
+				selector.PodSelector, err.Error()))
 		}
 	}
 	return allErrs
 }
 
-func validateRepository(repo string, allErrs field.ErrorList, fp *field.Path, child string) field.ErrorList {
-	_, err := reference.ParseNormalizedNamed(repo)
+// validatePrecompiledNodes rejects forcePrecompiled if nodes with a Mellanox NIC selected by the policy run an OS
+// precompiled drivers are not published for, the driver could never be deployed on these nodes.
+// The nodes are not checked if the validator has no client.
+func (w *nicClusterPolicyValidator) validatePrecompiledNodes(
+	ctx context.Context, in *v1alpha1.NicClusterPolicy, fldPath *field.Path) field.ErrorList {
+	if !in.Spec.OFEDDriver.ForcePrecompiled || w.client == nil {
+		return nil
+	}
+	selector := client.MatchingLabels{nodeinfo.NodeLabelMlnxNIC: "true"}
+	for key, value := range in.Spec.NodeSelector {
+		selector[key] = value
+	}
+	for key, value := range in.Spec.OFEDDriver.NodeSelector {
+		selector[key] = value
+	}
+	nodes := &v1.NodeList{}
+	if err := w.client.List(ctx, nodes, selector); err != nil {
+		return field.ErrorList{field.InternalError(fldPath.Child("forcePrecompiled"),
+			fmt.Errorf("failed to list nodes: %v", err))}
+	}
+	var unsupported []string
+	for i := range nodes.Items {
+		osName := nodes.Items[i].Labels[nodeinfo.NodeLabelOSName]
+		if osName != "" && !slices.Contains(precompiledDriverOSes, osName) && !slices.Contains(unsupported, osName) {
+			unsupported = append(unsupported, osName)
+		}
+	}
+	if len(unsupported) == 0 {
+		return nil
+	}
+	slices.Sort(unsupported)
+	return field.ErrorList{field.Forbidden(fldPath.Child("forcePrecompiled"),
+		fmt.Sprintf("precompiled drivers are only published for %s, selected nodes run %s",
+			strings.Join(precompiledDriverOSes, ", "), strings.Join(unsupported, ", ")))}
+}
+
+func (w *nicClusterPolicyValidator) validateRepositories(
+	in *v1alpha1.NicClusterPolicy, allErrs field.ErrorList) field.ErrorList {
+	for _, component := range getComponentImageSpecs(&in.Spec) {
+		allErrs = append(allErrs, validateImage(component.spec, component.path)...)
+		if component.daemonSetSpec != nil {
+			allErrs = append(allErrs, validateDaemonSetSpec(component.daemonSetSpec, component.path)...)
+		}
+	}
+	if in.Spec.IBKubernetes != nil {
+		allErrs = append(allErrs, validatePriorityClassName(in.Spec.IBKubernetes.PriorityClassName,
+			field.NewPath("spec", "ibKubernetes", "priorityClassName"))...)
+	}
+	return allErrs
+}
+
+// validateImage validates the repository of the image, the digest, if the image is pinned by digest,
+// and the image pull policy of the component
+func validateImage(spec *v1alpha1.ImageSpec, fp *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	_, err := reference.ParseNormalizedNamed(spec.Repository)
 	if err != nil {
-		allErrs = append(allErrs, field.Invalid(fp.Child(child).Child("repository"),
-			repo, "invalid container image repository format"))
+		allErrs = append(allErrs, field.Invalid(fp.Child("repository"),
+			spec.Repository, "invalid container image repository format"))
+	}
+	if spec.IsDigest() {
+		if _, err := digest.Parse(spec.Version); err != nil {
+			allErrs = append(allErrs, field.Invalid(fp.Child("version"),
+				spec.Version, fmt.Sprintf("invalid container image digest: %v", err)))
+		}
+	}
+	if spec.ImagePullPolicy != "" && !slices.Contains(supportedImagePullPolicies, string(spec.ImagePullPolicy)) {
+		allErrs = append(allErrs, field.NotSupported(fp.Child("imagePullPolicy"), spec.ImagePullPolicy,
+			supportedImagePullPolicies))
+	}
+	return allErrs
+}
+
+// validateDaemonSetSpec validates the update strategy, the priority class and the security context of a component
+// deployed by DaemonSets
+func validateDaemonSetSpec(spec *v1alpha1.ComponentDaemonSetSpec, fp *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.UpdateStrategy != nil {
+		allErrs = append(allErrs, validateUpdateStrategy(spec.UpdateStrategy, fp.Child("updateStrategy"))...)
+	}
+	allErrs = append(allErrs, validatePriorityClassName(spec.PriorityClassName, fp.Child("priorityClassName"))...)
+	if spec.SecurityContext != nil {
+		allErrs = append(allErrs, validateSecurityContext(spec.SecurityContext, fp.Child("securityContext"))...)
+	}
+	return allErrs
+}
+
+// validateSecurityContext checks that the seccomp profile is only set for containers which aren't privileged,
+// as it is not applied to privileged containers, and that the localhost profile is set only for its type
+func validateSecurityContext(spec *v1alpha1.ContainerSecurityContextSpec, fp *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	profile := spec.SeccompProfile
+	if profile == nil {
+		return allErrs
+	}
+	if spec.Privileged == nil || *spec.Privileged {
+		allErrs = append(allErrs, field.Forbidden(fp.Child("seccompProfile"),
+			"seccompProfile may only be set when privileged is disabled"))
+	}
+	if !slices.Contains(supportedSeccompProfileTypes, string(profile.Type)) {
+		allErrs = append(allErrs, field.NotSupported(fp.Child("seccompProfile", "type"), profile.Type,
+			supportedSeccompProfileTypes))
+	}
+	hasLocalhostProfile := profile.LocalhostProfile != nil && *profile.LocalhostProfile != ""
+	if profile.Type == v1.SeccompProfileTypeLocalhost && !hasLocalhostProfile {
+		allErrs = append(allErrs, field.Required(fp.Child("seccompProfile", "localhostProfile"),
+			"localhostProfile is required with Localhost type"))
+	}
+	if profile.Type != v1.SeccompProfileTypeLocalhost && profile.LocalhostProfile != nil {
+		allErrs = append(allErrs, field.Forbidden(fp.Child("seccompProfile", "localhostProfile"),
+			"localhostProfile may only be set with Localhost type"))
+	}
+	return allErrs
+}
+
+// validateUpdateStrategy validates the type of the update strategy of the DaemonSets of a component and the
+// parameters of its rolling update
+func validateUpdateStrategy(strategy *appsv1.DaemonSetUpdateStrategy, fp *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if strategy.Type != "" && !slices.Contains(supportedUpdateStrategies, string(strategy.Type)) {
+		allErrs = append(allErrs, field.NotSupported(fp.Child("type"), strategy.Type, supportedUpdateStrategies))
+	}
+	rollingUpdate := strategy.RollingUpdate
+	if rollingUpdate == nil {
+		return allErrs
+	}
+	if strategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+		return append(allErrs, field.Forbidden(fp.Child("rollingUpdate"),
+			"rollingUpdate may not be set when the type is OnDelete"))
+	}
+	// maxUnavailable defaults to 1 and maxSurge to 0 in the DaemonSet
+	maxUnavailable, errs := validateIntOrPercent(rollingUpdate.MaxUnavailable, 1,
+		fp.Child("rollingUpdate", "maxUnavailable"))
+	allErrs = append(allErrs, errs...)
+	maxSurge, errs := validateIntOrPercent(rollingUpdate.MaxSurge, 0, fp.Child("rollingUpdate", "maxSurge"))
+	allErrs = append(allErrs, errs...)
+	if len(allErrs) == 0 && maxUnavailable == 0 && maxSurge == 0 {
+		allErrs = append(allErrs, field.Invalid(fp.Child("rollingUpdate", "maxUnavailable"),
+			rollingUpdate.MaxUnavailable.String(), "may not be 0 when maxSurge is 0"))
 	}
 	return allErrs
 }
 
+// validateIntOrPercent checks that the value is a non-negative number or percentage and returns it, percentages
+// are returned as the number of percents and the default value is returned if the value is not set
+func validateIntOrPercent(value *intstr.IntOrString, defaultValue int, fp *field.Path) (int, field.ErrorList) {
+	if value == nil {
+		return defaultValue, nil
+	}
+	v, err := intstr.GetScaledValueFromIntOrPercent(value, 100, true)
+	if err != nil {
+		return 0, field.ErrorList{field.Invalid(fp, value.String(), "must be a number or a percentage, e.g. 10%")}
+	}
+	if v < 0 {
+		return 0, field.ErrorList{field.Invalid(fp, value.String(), "must be non-negative")}
+	}
+	return v, nil
+}
+
 // containerResourcesProvider is an interface for ImageSpec struct that allows to get container resources from all
 // structs, that embed ImageSpec
 type containerResourcesProvider interface {
 	GetContainerResources() []v1alpha1.ResourceRequirements
 }
 
-// newStateFunc is a common alias for all functions that create states deployed by nicClusterPolicy
-type newStateFunc func(
-	k8sAPIClient client.Client, manifestDir string) (state.State, state.ManifestRenderer, error)
-
-// stateRenderData is a helper struct that consolidates everything required to render a state
-type stateRenderData struct {
+// stateContainerResources is a helper struct that consolidates the container resources of a component with the
+// manifests directory of its state
+type stateContainerResources struct {
 	provider    containerResourcesProvider
-	newState    newStateFunc
 	manifestDir string
 }
 
@@ -534,118 +1245,81 @@ func (w *nicClusterPolicyValidator) validateContainerResources(
 	policy *v1alpha1.NicClusterPolicy, allErrs field.ErrorList) field.ErrorList {
 	fp := field.NewPath("spec")
 
-	manifestBaseDir := envConfig.ManifestBaseDir
-
-	states := map[string]stateRenderData{}
+	states := map[string]stateContainerResources{}
 
 	if policy.Spec.OFEDDriver != nil {
-		states["ofedDriver"] = stateRenderData{
-			policy.Spec.OFEDDriver, state.NewStateOFED,
-			filepath.Join(manifestBaseDir, "state-ofed-driver"),
-		}
+		states["ofedDriver"] = stateContainerResources{policy.Spec.OFEDDriver, "state-ofed-driver"}
 	}
 	if policy.Spec.RdmaSharedDevicePlugin != nil {
-		states["rdmaSharedDevicePlugin"] = stateRenderData{
-			policy.Spec.RdmaSharedDevicePlugin, state.NewStateSharedDp,
-			filepath.Join(manifestBaseDir, "state-rdma-device-plugin"),
-		}
+		states["rdmaSharedDevicePlugin"] = stateContainerResources{
+			policy.Spec.RdmaSharedDevicePlugin, "state-rdma-device-plugin"}
 	}
 	if policy.Spec.SriovDevicePlugin != nil {
-		states["sriovDevicePlugin"] = stateRenderData{
-			policy.Spec.SriovDevicePlugin, state.NewStateSriovDp,
-			filepath.Join(manifestBaseDir, "state-sriov-device-plugin"),
-		}
+		states["sriovDevicePlugin"] = stateContainerResources{policy.Spec.SriovDevicePlugin, "state-sriov-device-plugin"}
 	}
 	if policy.Spec.IBKubernetes != nil {
-		states["ibKubernetes"] = stateRenderData{
-			policy.Spec.IBKubernetes, state.NewStateIBKubernetes,
-			filepath.Join(manifestBaseDir, "state-ib-kubernetes"),
-		}
+		states["ibKubernetes"] = stateContainerResources{policy.Spec.IBKubernetes, "state-ib-kubernetes"}
 	}
 	if policy.Spec.NvIpam != nil {
-		states["nvIpam"] = stateRenderData{
-			policy.Spec.NvIpam, state.NewStateNVIPAMCNI,
-			filepath.Join(manifestBaseDir, "state-nv-ipam-cni"),
-		}
+		states["nvIpam"] = stateContainerResources{policy.Spec.NvIpam, "state-nv-ipam-cni"}
 	}
 	if policy.Spec.NicFeatureDiscovery != nil {
-		states["nicFeatureDiscovery"] = stateRenderData{
-			policy.Spec.NicFeatureDiscovery, state.NewStateNICFeatureDiscovery,
-			filepath.Join(manifestBaseDir, "state-nic-feature-discovery"),
-		}
+		states["nicFeatureDiscovery"] = stateContainerResources{
+			policy.Spec.NicFeatureDiscovery, "state-nic-feature-discovery"}
 	}
-	for stateName, renderData := range states {
-		localData := renderData
-		allErrs = validateContainerResourcesIfNotNil(&localData, policy, allErrs, fp, stateName)
+	if policy.Spec.NicConfigurationDaemon != nil {
+		states["nicConfigurationDaemon"] = stateContainerResources{
+			policy.Spec.NicConfigurationDaemon, "state-nic-configuration-daemon"}
+	}
+	if policy.Spec.NodeFeatureDiscovery != nil {
+		states["nodeFeatureDiscovery"] = stateContainerResources{
+			policy.Spec.NodeFeatureDiscovery, "state-node-feature-discovery"}
+	}
+	for stateName, resources := range states {
+		allErrs = validateResourceRequirements(resources.provider.GetContainerResources(),
+			state.SupportedContainerNames(resources.manifestDir), allErrs, fp, stateName)
 	}
 
 	if policy.Spec.SecondaryNetwork != nil {
 		snfp := fp.Child("secondaryNetwork")
 
-		states := map[string]stateRenderData{}
+		states := map[string]stateContainerResources{}
 		if policy.Spec.SecondaryNetwork.CniPlugins != nil {
-			states["cniPlugins"] = stateRenderData{
-				policy.Spec.SecondaryNetwork.CniPlugins, state.NewStateCNIPlugins,
-				filepath.Join(manifestBaseDir, "state-container-networking-plugins"),
-			}
+			states["cniPlugins"] = stateContainerResources{
+				policy.Spec.SecondaryNetwork.CniPlugins, "state-container-networking-plugins"}
 		}
 		if policy.Spec.SecondaryNetwork.IPoIB != nil {
-			states["ipoib"] = stateRenderData{
-				policy.Spec.SecondaryNetwork.IPoIB, state.NewStateIPoIBCNI,
-				filepath.Join(manifestBaseDir, "state-ipoib-cni"),
-			}
+			states["ipoib"] = stateContainerResources{policy.Spec.SecondaryNetwork.IPoIB, "state-ipoib-cni"}
+		}
+		if policy.Spec.SecondaryNetwork.OVSCni != nil {
+			states["ovsCni"] = stateContainerResources{policy.Spec.SecondaryNetwork.OVSCni, "state-ovs-cni"}
+		}
+		if policy.Spec.SecondaryNetwork.RdmaCni != nil {
+			states["rdmaCni"] = stateContainerResources{policy.Spec.SecondaryNetwork.RdmaCni, "state-rdma-cni"}
 		}
 		if policy.Spec.SecondaryNetwork.Multus != nil {
-			states["multus"] = stateRenderData{
-				policy.Spec.SecondaryNetwork.Multus, state.NewStateMultusCNI,
-				filepath.Join(manifestBaseDir, "state-multus-cni"),
-			}
+			states["multus"] = stateContainerResources{policy.Spec.SecondaryNetwork.Multus, "state-multus-cni"}
 		}
 		if policy.Spec.SecondaryNetwork.IpamPlugin != nil {
-			states["ipamPlugin"] = stateRenderData{
-				policy.Spec.SecondaryNetwork.IpamPlugin, state.NewStateWhereaboutsCNI,
-				filepath.Join(manifestBaseDir, "state-whereabouts-cni"),
-			}
+			states["ipamPlugin"] = stateContainerResources{
+				policy.Spec.SecondaryNetwork.IpamPlugin, "state-whereabouts-cni"}
 		}
-		for stateName, renderData := range states {
-			localData := renderData
-			allErrs = validateContainerResourcesIfNotNil(&localData, policy, allErrs, snfp, stateName)
+		for stateName, resources := range states {
+			allErrs = validateResourceRequirements(resources.provider.GetContainerResources(),
+				state.SupportedContainerNames(resources.manifestDir), allErrs, snfp, stateName)
 		}
 	}
 	return allErrs
 }
 
-func validateContainerResourcesIfNotNil(
-	resource *stateRenderData, policy *v1alpha1.NicClusterPolicy, allErrs field.ErrorList,
-	fp *field.Path, fieldName string) field.ErrorList {
-	if resource.provider == nil {
-		return allErrs
-	}
-
-	_, renderer, err := resource.newState(nil, resource.manifestDir)
-	if err != nil {
-		nicClusterPolicyLog.Error(err, "failed to created state renderer")
-		return allErrs
-	}
-	return validateResourceRequirements(
-		resource.provider.GetContainerResources(), policy, renderer, allErrs, fp, fieldName)
-}
-
-func validateResourceRequirements(resources []v1alpha1.ResourceRequirements,
-	policy *v1alpha1.NicClusterPolicy, manifestRenderer state.ManifestRenderer,
+// validateResourceRequirements validates the resources of the containers, supportedContainerNames are the names of
+// the containers of the state whose resources can be set
+func validateResourceRequirements(resources []v1alpha1.ResourceRequirements, supportedContainerNames []string,
 	allErrs field.ErrorList, fp *field.Path, child string) field.ErrorList {
-	if len(resources) == 0 {
-		return allErrs
-	}
-
-	supportedContainerNames, err := state.ParseContainerNames(manifestRenderer, policy, nicClusterPolicyLog)
-	if err != nil {
-		nicClusterPolicyLog.Error(err, "failed to parse container names")
-	}
-
 	for _, reqs := range resources {
 		allErrs = validateResources(reqs.Requests, allErrs, fp, child, "Requests")
 		allErrs = validateResources(reqs.Limits, allErrs, fp, child, "Limits", reqs.Requests)
+		allErrs = validateHugePages(reqs, allErrs, fp, child)
 		if !slices.Contains(supportedContainerNames, reqs.Name) {
 			allErrs = append(
 				allErrs, field.NotSupported(fp.Child(child).Child("containerResources").Child("name"),
@@ -659,7 +1333,7 @@ func validateResourceRequirements(resources []v1alpha1.ResourceRequirements,
 func validateResources(resources map[v1.ResourceName]apiresource.Quantity, allErrs field.ErrorList, fp *field.Path,
 	child, resourceType string, requests ...map[v1.ResourceName]apiresource.Quantity) field.ErrorList {
 	for resourceName, quantity := range resources {
-		if resourceName == v1.ResourceCPU || resourceName == v1.ResourceMemory {
+		if isSupportedResourceName(resourceName) {
 			if quantity.IsZero() {
 				allErrs = append(allErrs, field.Invalid(fp.Child(child).Child("containerResources").
 					Child(resourceType).Child(string(resourceName)),
@@ -668,7 +1342,8 @@ func validateResources(resources map[v1.ResourceName]apiresource.Quantity, allEr
 		} else {
 			allErrs = append(allErrs, field.NotSupported(fp.Child(child).Child("containerResources").
 				Child(resourceType).Child(string(resourceName)),
-				resourceName, []string{string(v1.ResourceCPU), string(v1.ResourceMemory)}))
+				resourceName, []string{string(v1.ResourceCPU), string(v1.ResourceMemory),
+					string(v1.ResourceEphemeralStorage), v1.ResourceHugePagesPrefix + "<size>"}))
 		}
 
 		if resourceType == "Limits" && len(requests) > 0 && requests[0] != nil {
@@ -685,26 +1360,63 @@ func validateResources(resources map[v1.ResourceName]apiresource.Quantity, allEr
 	return allErrs
 }
 
+// validateHugePages checks that hugepages requests have a limit with the same value,
+// hugepages can not be overcommitted.
+func validateHugePages(reqs v1alpha1.ResourceRequirements, allErrs field.ErrorList, fp *field.Path,
+	child string) field.ErrorList {
+	for resourceName, quantity := range reqs.Requests {
+		if !isHugePageResourceName(resourceName) {
+			continue
+		}
+		limit, hasLimit := reqs.Limits[resourceName]
+		if !hasLimit {
+			allErrs = append(allErrs, field.Required(fp.Child(child).Child("containerResources").
+				Child("Limits").Child(string(resourceName)),
+				fmt.Sprintf("resource limit for %s must be set", string(resourceName))))
+			continue
+		}
+		if quantity.Cmp(limit) != 0 {
+			allErrs = append(allErrs, field.Invalid(fp.Child(child).Child("containerResources").
+				Child("Requests").Child(string(resourceName)), quantity,
+				fmt.Sprintf("resource request for %s must be equal to the limit", string(resourceName))))
+		}
+	}
+	return allErrs
+}
+
+func isSupportedResourceName(resourceName v1.ResourceName) bool {
+	switch resourceName {
+	case v1.ResourceCPU, v1.ResourceMemory, v1.ResourceEphemeralStorage:
+		return true
+	}
+	return isHugePageResourceName(resourceName)
+}
+
+// isHugePageResourceName returns true if the resource name is hugepages-<size> with a valid page size
+func isHugePageResourceName(resourceName v1.ResourceName) bool {
+	pageSize, found := strings.CutPrefix(string(resourceName), v1.ResourceHugePagesPrefix)
+	if !found {
+		return false
+	}
+	quantity, err := apiresource.ParseQuantity(pageSize)
+	return err == nil && quantity.Sign() > 0
+}
+
 // isValidOFEDVersion is a custom function to validate OFED version
 func isValidOFEDVersion(version string) bool {
-	versionPattern := `^(\d+\.\d+-\d+(\.\d+)*(-\d+)?)$`
-	versionRegex := regexp.MustCompile(versionPattern)
-	return versionRegex.MatchString(version)
+	return ofedVersionRegex.MatchString(version)
 }
 
 func isValidSriovNetworkDevicePluginResourceName(resourceName string) bool {
-	resourceNameRegex := regexp.MustCompile(sriovResourceNameRegex)
-	return resourceNameRegex.MatchString(resourceName)
+	return sriovResourceNameRegex.MatchString(resourceName)
 }
 
 func isValidRdmaSharedDevicePluginResourceName(resourceName string) bool {
-	resourceNameRegex := regexp.MustCompile(rdmaResourceNameRegex)
-	return resourceNameRegex.MatchString(resourceName)
+	return rdmaResourceNameRegex.MatchString(resourceName)
 }
 
 func isValidFQDN(input string) bool {
-	regex := regexp.MustCompile(fqdnRegex)
-	return regex.MatchString(input)
+	return fqdnRegex.MatchString(input)
 }
 
 // +kubebuilder:object:generate=false
@@ -721,8 +1433,14 @@ func (sv *schemaValidator) GetSchema(schemaName string) (*gojsonschema.Schema, e
 	return s, nil
 }
 
-// InitSchemaValidator sets up a schemaValidator from json schema files.
+// InitSchemaValidator sets up a schemaValidator from json schema files, the schemas are loaded once per process
 func InitSchemaValidator(schemaPath string) {
+	schemaValidatorOnce.Do(func() {
+		schemaValidators = loadSchemaValidator(schemaPath)
+	})
+}
+
+func loadSchemaValidator(schemaPath string) *schemaValidator {
 	sv := &schemaValidator{
 		schemas: make(map[string]*gojsonschema.Schema),
 	}
@@ -739,7 +1457,20 @@ func InitSchemaValidator(schemaPath string) {
 		}
 		sv.schemas[strings.TrimSuffix(f.Name(), ".json")] = s
 	}
-	schemaValidators = sv
+	return sv
+}
+
+// SchemasLoadedChecker is a health check which fails unless all the validation schemas used by the webhooks are loaded
+func SchemasLoadedChecker(_ *http.Request) error {
+	if schemaValidators == nil {
+		return errors.New("validation schemas are not loaded")
+	}
+	for _, schemaName := range requiredSchemas {
+		if _, err := schemaValidators.GetSchema(schemaName); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // DisableValidations will disable all CRs admission validations
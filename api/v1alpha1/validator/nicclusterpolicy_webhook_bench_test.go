@@ -0,0 +1,198 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+const (
+	// admissionLatencyBudget is the p99 latency the validation of a large NicClusterPolicy must stay under
+	admissionLatencyBudget = 50 * time.Millisecond
+
+	benchmarkResources = 200
+	benchmarkPools     = 200
+	benchmarkNodes     = 1000
+)
+
+func BenchmarkValidateCreateLargeNicClusterPolicy(b *testing.B) {
+	InitSchemaValidator("../../../webhook-schemas")
+	validator := newBenchmarkValidator(b, benchmarkNodes)
+	policy := largeNicClusterPolicy()
+	ctx := context.Background()
+
+	durations := make([]time.Duration, 0, b.N)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := time.Now()
+		if _, err := validator.ValidateCreate(ctx, policy); err != nil {
+			b.Fatalf("unexpected validation error: %v", err)
+		}
+		durations = append(durations, time.Since(start))
+	}
+	b.StopTimer()
+
+	slices.Sort(durations)
+	p99 := durations[(len(durations)*99)/100]
+	b.ReportMetric(float64(p99.Microseconds())/1000, "p99-ms")
+	if p99 > admissionLatencyBudget {
+		b.Errorf("p99 admission latency %s exceeds the budget of %s", p99, admissionLatencyBudget)
+	}
+}
+
+func BenchmarkIsValidPKeyGUID(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		isValidPKeyGUID("02:00:00:00:00:00:00:00")
+	}
+}
+
+func BenchmarkIsValidOFEDVersion(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		isValidOFEDVersion("24.04-0.6.6.0-0")
+	}
+}
+
+func BenchmarkIsValidSriovNetworkDevicePluginResourceName(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		isValidSriovNetworkDevicePluginResourceName("hostdev_0")
+	}
+}
+
+func BenchmarkIsValidRdmaSharedDevicePluginResourceName(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		isValidRdmaSharedDevicePluginResourceName("rdma_shared_device_a")
+	}
+}
+
+func BenchmarkIsValidFQDN(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		isValidFQDN("nvidia.com")
+	}
+}
+
+// newBenchmarkValidator returns a validator whose client serves the given number of schedulable nodes
+func newBenchmarkValidator(b *testing.B, nodeCount int) *nicClusterPolicyValidator {
+	scheme := runtime.NewScheme()
+	if err := v1.AddToScheme(scheme); err != nil {
+		b.Fatal(err)
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		b.Fatal(err)
+	}
+	objs := make([]client.Object, 0, nodeCount)
+	for i := 0; i < nodeCount; i++ {
+		objs = append(objs, &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)},
+			Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("64"),
+				v1.ResourceMemory: resource.MustParse("256Gi"),
+			}},
+		})
+	}
+	return &nicClusterPolicyValidator{
+		client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+	}
+}
+
+// largeNicClusterPolicy returns a valid NicClusterPolicy with large device plugin configs and many IPAM pools
+func largeNicClusterPolicy() *v1alpha1.NicClusterPolicy {
+	sriovResources := make([]string, 0, benchmarkResources)
+	rdmaResources := make([]string, 0, benchmarkResources)
+	for i := 0; i < benchmarkResources; i++ {
+		sriovResources = append(sriovResources, fmt.Sprintf(
+			`{"resourceName": "hostdev_%d", "selectors": {"vendors": ["15b3"], "devices": ["101b"]}}`, i))
+		rdmaResources = append(rdmaResources, fmt.Sprintf(
+			`{"resourceName": "rdma_shared_device_%d", "rdmaHcaMax": 63, "selectors": {"vendors": ["15b3"]}}`, i))
+	}
+	sriovConfig := `{"resourceList": [` + strings.Join(sriovResources, ",") + `]}`
+	rdmaConfig := `{"configList": [` + strings.Join(rdmaResources, ",") + `]}`
+
+	pools := make([]v1alpha1.NVIPAMPoolSpec, 0, benchmarkPools)
+	for i := 0; i < benchmarkPools; i++ {
+		pools = append(pools, v1alpha1.NVIPAMPoolSpec{Name: fmt.Sprintf("pool%d", i),
+			Subnet: fmt.Sprintf("10.%d.0.0/24", i), PerNodeBlockSize: 16, Gateway: fmt.Sprintf("10.%d.0.1", i)})
+	}
+
+	return &v1alpha1.NicClusterPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: consts.NicClusterPolicyResourceName},
+		Spec: v1alpha1.NicClusterPolicySpec{
+			OFEDDriver: &v1alpha1.OFEDDriverSpec{
+				ImageSpec: v1alpha1.ImageSpec{
+					Image: "doca-driver", Repository: "nvcr.io/nvidia/mellanox", Version: "24.04-0.6.6.0-0",
+					ImagePullSecrets: []string{},
+				},
+			},
+			IBKubernetes: &v1alpha1.IBKubernetesSpec{
+				PKeyGUIDPoolRangeStart: "02:00:00:00:00:00:00:00",
+				PKeyGUIDPoolRangeEnd:   "02:FF:FF:FF:FF:FF:FF:FF",
+				ImageSpec: v1alpha1.ImageSpec{
+					Image: "ib-kubernetes", Repository: "ghcr.io/mellanox", Version: "v1.0.2",
+					ImagePullSecrets: []string{},
+				},
+			},
+			SriovDevicePlugin: &v1alpha1.DevicePluginSpec{
+				ImageSpecWithConfig: v1alpha1.ImageSpecWithConfig{
+					Config: &sriovConfig,
+					ImageSpec: v1alpha1.ImageSpec{
+						Image: "sriov-network-device-plugin", Repository: "ghcr.io/k8snetworkplumbingwg",
+						Version: "v3.7.0", ImagePullSecrets: []string{},
+						ContainerResources: []v1alpha1.ResourceRequirements{{
+							Name:     "kube-sriovdp",
+							Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+						}},
+					},
+				},
+			},
+			RdmaSharedDevicePlugin: &v1alpha1.DevicePluginSpec{
+				ImageSpecWithConfig: v1alpha1.ImageSpecWithConfig{
+					Config: &rdmaConfig,
+					ImageSpec: v1alpha1.ImageSpec{
+						Image: "k8s-rdma-shared-dev-plugin", Repository: "ghcr.io/mellanox", Version: "v1.5.1",
+						ImagePullSecrets: []string{},
+						ContainerResources: []v1alpha1.ResourceRequirements{{
+							Name:     "rdma-shared-dp",
+							Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("64Mi")},
+						}},
+					},
+				},
+			},
+			NvIpam: &v1alpha1.NVIPAMSpec{
+				ImageSpec: v1alpha1.ImageSpec{
+					Image: "nvidia-k8s-ipam", Repository: "ghcr.io/mellanox", Version: "v0.1.2",
+					ImagePullSecrets: []string{},
+				},
+				Pools: pools,
+			},
+		},
+	}
+}
@@ -20,26 +20,27 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/Mellanox/network-operator/api/v1alpha1"
-	env "github.com/Mellanox/network-operator/pkg/config"
+	"github.com/Mellanox/network-operator/pkg/consts"
+	"github.com/Mellanox/network-operator/pkg/nodeinfo"
 )
 
 //nolint:dupl
 var _ = Describe("Validate", func() {
 	Context("NicClusterPolicy tests", func() {
-		BeforeEach(func() {
-			envConfig = env.StateConfig{
-				ManifestBaseDir: "../../../manifests",
-			}
-		})
 		It("Valid GUID range", func() {
 			validator := nicClusterPolicyValidator{}
 			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
-				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				ObjectMeta: metav1.ObjectMeta{Name: consts.NicClusterPolicyResourceName},
 				Spec: v1alpha1.NicClusterPolicySpec{
 					IBKubernetes: &v1alpha1.IBKubernetesSpec{
 						PKeyGUIDPoolRangeStart: "00:00:00:00:00:00:00:00",
@@ -100,6 +101,59 @@ var _ = Describe("Validate", func() {
 				ContainSubstring("pKeyGUIDPoolRangeStart must be a valid GUID format"),
 				ContainSubstring("pKeyGUIDPoolRangeEnd must be a valid GUID format")))
 		})
+		It("Valid UFM TLS", func() {
+			validator := nicClusterPolicyValidator{}
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: consts.NicClusterPolicyResourceName},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					IBKubernetes: &v1alpha1.IBKubernetesSpec{
+						PKeyGUIDPoolRangeStart: "00:00:00:00:00:00:00:00",
+						PKeyGUIDPoolRangeEnd:   "00:00:00:00:00:00:00:01",
+						UfmTLS: &v1alpha1.UFMTLSSpec{
+							CertificateSecret: "ufm-client-tls",
+							CABundle:          "ufm-ca",
+							VerificationMode:  v1alpha1.UFMTLSVerificationModeVerify,
+						},
+						ImageSpec: v1alpha1.ImageSpec{
+							Image:            "ib-kubernetes",
+							Repository:       "ghcr.io/mellanox",
+							Version:          "v1.0.2",
+							ImagePullSecrets: []string{},
+						},
+					},
+				},
+			}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Invalid UFM TLS", func() {
+			validator := nicClusterPolicyValidator{}
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: consts.NicClusterPolicyResourceName},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					IBKubernetes: &v1alpha1.IBKubernetesSpec{
+						PKeyGUIDPoolRangeStart: "00:00:00:00:00:00:00:00",
+						PKeyGUIDPoolRangeEnd:   "00:00:00:00:00:00:00:01",
+						UfmTLS: &v1alpha1.UFMTLSSpec{
+							CertificateSecret: "Invalid_Secret",
+							CABundle:          "ufm-ca",
+							VerificationMode:  v1alpha1.UFMTLSVerificationModeSkipVerify,
+						},
+						ImageSpec: v1alpha1.ImageSpec{
+							Image:            "ib-kubernetes",
+							Repository:       "ghcr.io/mellanox",
+							Version:          "v1.0.2",
+							ImagePullSecrets: []string{},
+						},
+					},
+				},
+			}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(And(
+				ContainSubstring("spec.ibKubernetes.ufmTLS.certificateSecret: Invalid value"),
+				ContainSubstring("caBundle can't be set when the verificationMode is SkipVerify")))
+		})
 		It("Valid MOFED version (old scheme)", func() {
 			validator := nicClusterPolicyValidator{}
 			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
@@ -172,6 +226,42 @@ var _ = Describe("Validate", func() {
 			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
 			Expect(err.Error()).To(ContainSubstring("invalid OFED version"))
 		})
+		It("Valid MOFED version channel", func() {
+			validator := nicClusterPolicyValidator{}
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					OFEDDriver: &v1alpha1.OFEDDriverSpec{
+						ImageSpec: v1alpha1.ImageSpec{
+							Image:            "mofed",
+							Repository:       "ghcr.io/mellanox",
+							Version:          "latest-24.xx",
+							ImagePullSecrets: []string{},
+						},
+					},
+				},
+			}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("InValid MOFED version channel", func() {
+			validator := nicClusterPolicyValidator{}
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					OFEDDriver: &v1alpha1.OFEDDriverSpec{
+						ImageSpec: v1alpha1.ImageSpec{
+							Image:            "mofed",
+							Repository:       "ghcr.io/mellanox",
+							Version:          "latest-24.04.1",
+							ImagePullSecrets: []string{},
+						},
+					},
+				},
+			}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("invalid OFED version"))
+		})
 		It("MOFED SafeLoad requires AutoUpgrade to be enabled", func() {
 			validator := nicClusterPolicyValidator{}
 			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
@@ -193,6 +283,59 @@ var _ = Describe("Validate", func() {
 			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
 			Expect(err.Error()).To(ContainSubstring("autoUpgrade"))
 		})
+		It("MOFED RollingUpdate strategy with AutoUpgrade", func() {
+			validator := nicClusterPolicyValidator{}
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					OFEDDriver: &v1alpha1.OFEDDriverSpec{
+						ImageSpec: v1alpha1.ImageSpec{
+							Image:            "mofed",
+							Repository:       "ghcr.io/mellanox",
+							Version:          "23.10-0.2.2.0",
+							ImagePullSecrets: []string{},
+						},
+						ComponentDaemonSetSpec: v1alpha1.ComponentDaemonSetSpec{
+							UpdateStrategy: &appsv1.DaemonSetUpdateStrategy{
+								Type: appsv1.RollingUpdateDaemonSetStrategyType,
+							},
+						},
+						OfedUpgradePolicy: &v1alpha1.DriverUpgradePolicySpec{
+							AutoUpgrade: true,
+						},
+					},
+				},
+			}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring(
+				"spec.ofedDriver.updateStrategy.type: Forbidden: updateStrategy must be OnDelete"))
+
+			nicClusterPolicy.Spec.OFEDDriver.UpdateStrategy.Type = appsv1.OnDeleteDaemonSetStrategyType
+			_, err = validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("MOFED unprivileged security context", func() {
+			privileged := false
+			validator := nicClusterPolicyValidator{}
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					OFEDDriver: &v1alpha1.OFEDDriverSpec{
+						ImageSpec: v1alpha1.ImageSpec{
+							Image:            "mofed",
+							Repository:       "ghcr.io/mellanox",
+							Version:          "23.10-0.2.2.0",
+							ImagePullSecrets: []string{},
+						},
+						ComponentDaemonSetSpec: v1alpha1.ComponentDaemonSetSpec{
+							SecurityContext: &v1alpha1.ContainerSecurityContextSpec{Privileged: &privileged},
+						},
+					},
+				},
+			}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("spec.ofedDriver.securityContext.privileged: Forbidden"))
+		})
 		It("MOFED valid SafeLoad config", func() {
 			validator := nicClusterPolicyValidator{}
 			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
@@ -215,6 +358,190 @@ var _ = Describe("Validate", func() {
 			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
 			Expect(err).To(BeNil())
 		})
+		It("MOFED ForcePrecompiled and DisablePrecompiled can't be set together", func() {
+			validator := nicClusterPolicyValidator{}
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					OFEDDriver: &v1alpha1.OFEDDriverSpec{
+						ImageSpec: v1alpha1.ImageSpec{
+							Image:            "mofed",
+							Repository:       "ghcr.io/mellanox",
+							Version:          "23.10-0.2.2.0",
+							ImagePullSecrets: []string{},
+						},
+						ForcePrecompiled:   true,
+						DisablePrecompiled: true,
+					},
+				},
+			}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("disablePrecompiled can't be set together with"))
+		})
+		It("MOFED probes with invalid timing", func() {
+			validator := nicClusterPolicyValidator{}
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					OFEDDriver: &v1alpha1.OFEDDriverSpec{
+						ImageSpec: v1alpha1.ImageSpec{
+							Image:            "mofed",
+							Repository:       "ghcr.io/mellanox",
+							Version:          "23.10-0.2.2.0",
+							ImagePullSecrets: []string{},
+						},
+						StartupProbe:   &v1alpha1.PodProbeSpec{InitialDelaySeconds: 10, PeriodSeconds: 0},
+						LivenessProbe:  &v1alpha1.PodProbeSpec{InitialDelaySeconds: 30, PeriodSeconds: 5, TimeoutSeconds: 10},
+						ReadinessProbe: &v1alpha1.PodProbeSpec{InitialDelaySeconds: -1, PeriodSeconds: 30},
+					},
+				},
+			}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.ofedDriver.startupProbe.periodSeconds: Invalid value: 0"))
+			Expect(err.Error()).To(ContainSubstring(
+				"spec.ofedDriver.livenessProbe.periodSeconds: Invalid value: 5: must be greater than or equal to " +
+					"spec.ofedDriver.livenessProbe.timeoutSeconds"))
+			Expect(err.Error()).To(ContainSubstring("spec.ofedDriver.readinessProbe.initialDelaySeconds"))
+		})
+		It("MOFED probes with valid timing", func() {
+			validator := nicClusterPolicyValidator{}
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					OFEDDriver: &v1alpha1.OFEDDriverSpec{
+						ImageSpec: v1alpha1.ImageSpec{
+							Image:            "mofed",
+							Repository:       "ghcr.io/mellanox",
+							Version:          "23.10-0.2.2.0",
+							ImagePullSecrets: []string{},
+						},
+						StartupProbe:  &v1alpha1.PodProbeSpec{InitialDelaySeconds: 10, PeriodSeconds: 10},
+						LivenessProbe: &v1alpha1.PodProbeSpec{InitialDelaySeconds: 0, PeriodSeconds: 30, TimeoutSeconds: 30},
+					},
+				},
+			}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("MOFED upgrade node order without the settings of the policy", func() {
+			validator := nicClusterPolicyValidator{}
+			newPolicy := func(nodeOrder *v1alpha1.NodeOrderSpec) *v1alpha1.NicClusterPolicy {
+				return &v1alpha1.NicClusterPolicy{
+					ObjectMeta: metav1.ObjectMeta{Name: "test"},
+					Spec: v1alpha1.NicClusterPolicySpec{
+						OFEDDriver: &v1alpha1.OFEDDriverSpec{
+							ImageSpec: v1alpha1.ImageSpec{
+								Image:            "mofed",
+								Repository:       "ghcr.io/mellanox",
+								Version:          "23.10-0.2.2.0",
+								ImagePullSecrets: []string{},
+							},
+							OfedUpgradePolicy: &v1alpha1.DriverUpgradePolicySpec{AutoUpgrade: true, NodeOrder: nodeOrder},
+						},
+					},
+				}
+			}
+			_, err := validator.ValidateCreate(context.TODO(),
+				newPolicy(&v1alpha1.NodeOrderSpec{Policy: v1alpha1.NodeOrderPolicyLabel}))
+			Expect(err.Error()).To(ContainSubstring("spec.ofedDriver.upgradePolicy.nodeOrder.labelKey: Required value"))
+			_, err = validator.ValidateCreate(context.TODO(),
+				newPolicy(&v1alpha1.NodeOrderSpec{Policy: v1alpha1.NodeOrderPolicyLabel, LabelKey: "invalid key"}))
+			Expect(err.Error()).To(ContainSubstring("spec.ofedDriver.upgradePolicy.nodeOrder.labelKey: Invalid value"))
+			_, err = validator.ValidateCreate(context.TODO(),
+				newPolicy(&v1alpha1.NodeOrderSpec{Policy: v1alpha1.NodeOrderPolicyNodeList}))
+			Expect(err.Error()).To(ContainSubstring("spec.ofedDriver.upgradePolicy.nodeOrder.nodes: Required value"))
+			_, err = validator.ValidateCreate(context.TODO(),
+				newPolicy(&v1alpha1.NodeOrderSpec{Policy: v1alpha1.NodeOrderPolicyLabel, LabelKey: "example.com/rack"}))
+			Expect(err).NotTo(HaveOccurred())
+			_, err = validator.ValidateCreate(context.TODO(),
+				newPolicy(&v1alpha1.NodeOrderSpec{Policy: v1alpha1.NodeOrderPolicyZone}))
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("MOFED upgrade drain with invalid settings", func() {
+			validator := nicClusterPolicyValidator{}
+			newPolicy := func(drainSpec *v1alpha1.DrainSpec) *v1alpha1.NicClusterPolicy {
+				return &v1alpha1.NicClusterPolicy{
+					ObjectMeta: metav1.ObjectMeta{Name: "test"},
+					Spec: v1alpha1.NicClusterPolicySpec{
+						OFEDDriver: &v1alpha1.OFEDDriverSpec{
+							ImageSpec: v1alpha1.ImageSpec{
+								Image:            "mofed",
+								Repository:       "ghcr.io/mellanox",
+								Version:          "23.10-0.2.2.0",
+								ImagePullSecrets: []string{},
+							},
+							OfedUpgradePolicy: &v1alpha1.DriverUpgradePolicySpec{AutoUpgrade: true, DrainSpec: drainSpec},
+						},
+					},
+				}
+			}
+			_, err := validator.ValidateCreate(context.TODO(), newPolicy(&v1alpha1.DrainSpec{Enable: true, TimeoutSecond: -1}))
+			Expect(err.Error()).To(ContainSubstring(
+				"spec.ofedDriver.upgradePolicy.drain.timeoutSeconds: Invalid value: -1"))
+			_, err = validator.ValidateCreate(context.TODO(), newPolicy(&v1alpha1.DrainSpec{Enable: true,
+				PodSelector: "app in (a"}))
+			Expect(err.Error()).To(ContainSubstring("spec.ofedDriver.upgradePolicy.drain.podSelector: Invalid value"))
+			_, err = validator.ValidateCreate(context.TODO(), newPolicy(&v1alpha1.DrainSpec{Enable: true,
+				DeleteEmptyDir: true}))
+			Expect(err.Error()).To(ContainSubstring("spec.ofedDriver.upgradePolicy.drain.deleteEmptyDir: Forbidden"))
+			_, err = validator.ValidateCreate(context.TODO(), newPolicy(&v1alpha1.DrainSpec{Enable: true,
+				Force: true, DeleteEmptyDir: true, PodSelector: "app!=rdma", TimeoutSecond: 300}))
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("MOFED upgrade drain with invalid namespace pod selector", func() {
+			validator := nicClusterPolicyValidator{}
+			newPolicy := func(podSelector string) *v1alpha1.NicClusterPolicy {
+				return &v1alpha1.NicClusterPolicy{
+					ObjectMeta: metav1.ObjectMeta{Name: "test"},
+					Spec: v1alpha1.NicClusterPolicySpec{
+						OFEDDriver: &v1alpha1.OFEDDriverSpec{
+							ImageSpec: v1alpha1.ImageSpec{
+								Image:            "mofed",
+								Repository:       "ghcr.io/mellanox",
+								Version:          "23.10-0.2.2.0",
+								ImagePullSecrets: []string{},
+							},
+							OfedUpgradePolicy: &v1alpha1.DriverUpgradePolicySpec{AutoUpgrade: true,
+								DrainSpec: &v1alpha1.DrainSpec{Enable: true, NamespacePodSelectors: []v1alpha1.NamespacePodSelector{
+									{Namespace: "rdma-apps", PodSelector: podSelector}}}},
+						},
+					},
+				}
+			}
+			_, err := validator.ValidateCreate(context.TODO(), newPolicy("app in (a"))
+			Expect(err.Error()).To(ContainSubstring(
+				"spec.ofedDriver.upgradePolicy.drain.namespacePodSelectors[0].podSelector: Invalid value"))
+			_, err = validator.ValidateCreate(context.TODO(), newPolicy("app=rdma"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("MOFED upgrade canary with invalid node selector", func() {
+			validator := nicClusterPolicyValidator{}
+			newPolicy := func(canary *v1alpha1.CanarySpec) *v1alpha1.NicClusterPolicy {
+				return &v1alpha1.NicClusterPolicy{
+					ObjectMeta: metav1.ObjectMeta{Name: "test"},
+					Spec: v1alpha1.NicClusterPolicySpec{
+						OFEDDriver: &v1alpha1.OFEDDriverSpec{
+							ImageSpec: v1alpha1.ImageSpec{
+								Image:            "mofed",
+								Repository:       "ghcr.io/mellanox",
+								Version:          "23.10-0.2.2.0",
+								ImagePullSecrets: []string{},
+							},
+							OfedUpgradePolicy: &v1alpha1.DriverUpgradePolicySpec{AutoUpgrade: true, Canary: canary},
+						},
+					},
+				}
+			}
+			_, err := validator.ValidateCreate(context.TODO(), newPolicy(&v1alpha1.CanarySpec{}))
+			Expect(err.Error()).To(ContainSubstring("spec.ofedDriver.upgradePolicy.canary.nodeSelector: Required value"))
+			_, err = validator.ValidateCreate(context.TODO(),
+				newPolicy(&v1alpha1.CanarySpec{NodeSelector: map[string]string{"invalid key": "true"}}))
+			Expect(err.Error()).To(ContainSubstring("spec.ofedDriver.upgradePolicy.canary.nodeSelector: Invalid value"))
+			_, err = validator.ValidateCreate(context.TODO(),
+				newPolicy(&v1alpha1.CanarySpec{NodeSelector: map[string]string{"example.com/canary": "true"}}))
+			Expect(err).NotTo(HaveOccurred())
+		})
 		It("Valid RDMA config JSON", func() {
 			rdmaConfig := `{
 				"configList": [{
@@ -335,6 +662,44 @@ var _ = Describe("Validate", func() {
 			Expect(err.Error()).To(ContainSubstring(
 				"Invalid Resource prefix, it must be a valid FQDN"))
 		})
+		It("Invalid RDMA config JSON, unknown field in configList", func() {
+			invalidRdmaConfigJSON := `{
+				"configList": [{
+					"resourceName": "rdma_shared_device_a",
+					"rdmaHcaMax": 63,
+					"selector": {
+						"vendors": ["15b3"]}}]}`
+			nicClusterPolicy := rdmaDPNicClusterPolicy(invalidRdmaConfigJSON)
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("Additional property selector is not allowed"))
+		})
+		It("Invalid RDMA config JSON, unknown field in selectors", func() {
+			invalidRdmaConfigJSON := `{
+				"configList": [{
+					"resourceName": "rdma_shared_device_a",
+					"rdmaHcaMax": 63,
+					"selectors": {
+						"vendor": ["15b3"],
+						"deviceIDs": ["101b"]}}]}`
+			nicClusterPolicy := rdmaDPNicClusterPolicy(invalidRdmaConfigJSON)
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("Additional property vendor is not allowed"))
+		})
+		It("Valid RDMA config JSON with periodicUpdateInterval", func() {
+			rdmaConfig := `{
+				"periodicUpdateInterval": 300,
+				"configList": [{
+					"resourceName": "rdma_shared_device_a",
+					"rdmaHcaMax": 63,
+					"selectors": {
+						"vendors": ["15b3"]}}]}`
+			nicClusterPolicy := rdmaDPNicClusterPolicy(rdmaConfig)
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
 		It("Valid SriovDevicePlugin config JSON", func() {
 			sriovConfig := `{
 				"resourceList": [{
@@ -432,24 +797,139 @@ var _ = Describe("Validate", func() {
 			Expect(err.Error()).To(ContainSubstring(
 				"Invalid type. Expected: string, given: integer"))
 		})
-		It("Invalid SriovDevicePlugin resourcePrefix is not FQDN", func() {
+		It("Invalid SriovDevicePlugin config JSON, unknown field in resourceList", func() {
 			invalidSriovConfigJSON := `{
 				"resourceList": [{
 					"resourceName": "hostdev",
-					"resourcePrefix": "nvidia.com#$&&@",
+					"deviceTypes": "netDevice",
 					"selectors": {
-						"vendors": ["15b3"],
-						"devices": ["101b"]}}]}`
+						"vendors": ["15b3"]}}]}`
+			nicClusterPolicy := sriovDPNicClusterPolicy(invalidSriovConfigJSON)
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("Additional property deviceTypes is not allowed"))
+		})
+		It("Invalid SriovDevicePlugin config JSON, missing resourceName", func() {
+			invalidSriovConfigJSON := `{
+				"resourceList": [{
+					"selectors": {
+						"vendors": ["15b3"]}}]}`
 			nicClusterPolicy := sriovDPNicClusterPolicy(invalidSriovConfigJSON)
 			validator := nicClusterPolicyValidator{}
 			_, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("resourceName is required"))
+		})
+		It("Invalid device plugins config, same resource in RDMA and SriovDevicePlugin", func() {
+			nicClusterPolicy := rdmaDPNicClusterPolicy(`{
+				"configList": [{
+					"resourceName": "rdma_a",
+					"resourcePrefix": "nvidia.com",
+					"rdmaHcaMax": 63,
+					"selectors": {
+						"vendors": ["15b3"]}}]}`)
+			sriovConfig := `{
+				"resourceList": [{
+					"resourceName": "rdma_a",
+					"selectors": {
+						"vendors": ["15b3"]}}]}`
+			nicClusterPolicy.Spec.SriovDevicePlugin = sriovDPNicClusterPolicy(sriovConfig).Spec.SriovDevicePlugin
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
 			Expect(err.Error()).To(ContainSubstring(
-				"Invalid Resource prefix, it must be a valid FQDN"))
+				`spec.sriovNetworkDevicePlugin.Config: Duplicate value: "nvidia.com/rdma_a"`))
 		})
-	})
-	Context("Image repository tests", func() {
-		It("Invalid Repository IBKubernetes", func() {
-			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+		It("Valid device plugins config, same resource name with different prefixes", func() {
+			nicClusterPolicy := rdmaDPNicClusterPolicy(`{
+				"configList": [{
+					"resourceName": "rdma_a",
+					"rdmaHcaMax": 63,
+					"selectors": {
+						"vendors": ["15b3"]}}]}`)
+			sriovConfig := `{
+				"resourceList": [{
+					"resourceName": "rdma_a",
+					"selectors": {
+						"vendors": ["15b3"]}}]}`
+			nicClusterPolicy.Spec.SriovDevicePlugin = sriovDPNicClusterPolicy(sriovConfig).Spec.SriovDevicePlugin
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Invalid RDMA config JSON, duplicate resource in configList", func() {
+			invalidRdmaConfigJSON := `{
+				"configList": [{
+					"resourceName": "rdma_shared_device_a",
+					"rdmaHcaMax": 63,
+					"selectors": {
+						"vendors": ["15b3"]}}, {
+					"resourceName": "rdma_shared_device_a",
+					"rdmaHcaMax": 63,
+					"selectors": {
+						"deviceIDs": ["101b"]}}]}`
+			nicClusterPolicy := rdmaDPNicClusterPolicy(invalidRdmaConfigJSON)
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring(
+				`spec.rdmaSharedDevicePlugin.Config: Duplicate value: "rdma/rdma_shared_device_a"`))
+		})
+		It("Warn on device plugin selectors without the NVIDIA vendor", func() {
+			nicClusterPolicy := rdmaDPNicClusterPolicy(`{
+				"configList": [{
+					"resourceName": "rdma_shared_device_a",
+					"rdmaHcaMax": 63,
+					"selectors": {
+						"vendors": ["8086"]}}]}`)
+			validator := nicClusterPolicyValidator{}
+			warnings, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring(
+				"spec.rdmaSharedDevicePlugin: selectors of resource rdma_shared_device_a don't include " +
+					"the NVIDIA vendor ID 15b3")))
+		})
+		It("Warn on device plugin selectors with unknown device IDs", func() {
+			nicClusterPolicy := sriovDPNicClusterPolicy(`{
+				"resourceList": [{
+					"resourceName": "hostdev",
+					"selectors": [{
+						"vendors": ["15b3"],
+						"devices": ["101B", "1234"]}]}]}`)
+			validator := nicClusterPolicyValidator{}
+			warnings, err := validator.ValidateUpdate(context.TODO(), nil, &nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(
+				"spec.sriovNetworkDevicePlugin: selectors of resource hostdev include device IDs " +
+					"which are not known NVIDIA devices: [1234]"))
+		})
+		It("Skip device plugin selectors warnings with annotation", func() {
+			nicClusterPolicy := sriovDPNicClusterPolicy(`{
+				"resourceList": [{
+					"resourceName": "hostdev",
+					"selectors": {
+						"vendors": ["8086"]}}]}`)
+			nicClusterPolicy.Annotations = map[string]string{consts.SkipDeviceSelectorValidationAnnotation: "true"}
+			validator := nicClusterPolicyValidator{}
+			warnings, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+		It("Invalid SriovDevicePlugin resourcePrefix is not FQDN", func() {
+			invalidSriovConfigJSON := `{
+				"resourceList": [{
+					"resourceName": "hostdev",
+					"resourcePrefix": "nvidia.com#$&&@",
+					"selectors": {
+						"vendors": ["15b3"],
+						"devices": ["101b"]}}]}`
+			nicClusterPolicy := sriovDPNicClusterPolicy(invalidSriovConfigJSON)
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring(
+				"Invalid Resource prefix, it must be a valid FQDN"))
+		})
+	})
+	Context("Image repository tests", func() {
+		It("Invalid Repository IBKubernetes", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
 				ObjectMeta: metav1.ObjectMeta{Name: "test"},
 				Spec: v1alpha1.NicClusterPolicySpec{
 					IBKubernetes: &v1alpha1.IBKubernetesSpec{
@@ -564,6 +1044,408 @@ var _ = Describe("Validate", func() {
 			Expect(err.Error()).To(ContainSubstring(
 				"invalid container image repository format"))
 		})
+		It("Valid image digest", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					SecondaryNetwork: &v1alpha1.SecondaryNetworkSpec{
+						RdmaCni: &v1alpha1.CNIPluginSpec{ImageSpec: v1alpha1.ImageSpec{
+							Image:      "rdma-cni",
+							Repository: "ghcr.io/mellanox",
+							Version:    "sha256:0e5ad5ee1a1b1bd8ee2e0e1a3ba8e1cb6bb4fd89d21cb3c0c7e8db7e83a0d1d2",
+						}},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Invalid image digest", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					SecondaryNetwork: &v1alpha1.SecondaryNetworkSpec{
+						RdmaCni: &v1alpha1.CNIPluginSpec{ImageSpec: v1alpha1.ImageSpec{
+							Image:      "rdma-cni",
+							Repository: "ghcr.io/mellanox",
+							Version:    "sha256:0e5ad5ee",
+						}},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("spec.secondaryNetwork.rdmaCni.version"))
+			Expect(err.Error()).To(ContainSubstring("invalid container image digest"))
+		})
+		It("Invalid image pull policy", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					SecondaryNetwork: &v1alpha1.SecondaryNetworkSpec{
+						RdmaCni: &v1alpha1.CNIPluginSpec{ImageSpec: v1alpha1.ImageSpec{
+							Image:           "rdma-cni",
+							Repository:      "ghcr.io/mellanox",
+							Version:         "v1.2.0",
+							ImagePullPolicy: "Sometimes",
+						}},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring(
+				"spec.secondaryNetwork.rdmaCni.imagePullPolicy: Unsupported value: \"Sometimes\""))
+		})
+		It("Valid update strategy", func() {
+			maxUnavailable := intstr.FromString("10%")
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					SecondaryNetwork: &v1alpha1.SecondaryNetworkSpec{
+						RdmaCni: &v1alpha1.CNIPluginSpec{
+							ImageSpec: v1alpha1.ImageSpec{
+								Image:      "rdma-cni",
+								Repository: "ghcr.io/mellanox",
+								Version:    "v1.2.0",
+							},
+							ComponentDaemonSetSpec: v1alpha1.ComponentDaemonSetSpec{
+								UpdateStrategy: &appsv1.DaemonSetUpdateStrategy{
+									Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+									RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &maxUnavailable},
+								},
+							},
+						},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Invalid update strategy", func() {
+			maxUnavailable := intstr.FromInt32(0)
+			maxSurge := intstr.FromString("ten")
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					SecondaryNetwork: &v1alpha1.SecondaryNetworkSpec{
+						RdmaCni: &v1alpha1.CNIPluginSpec{
+							ImageSpec: v1alpha1.ImageSpec{
+								Image:      "rdma-cni",
+								Repository: "ghcr.io/mellanox",
+								Version:    "v1.2.0",
+							},
+							ComponentDaemonSetSpec: v1alpha1.ComponentDaemonSetSpec{
+								UpdateStrategy: &appsv1.DaemonSetUpdateStrategy{
+									Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+									RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxSurge: &maxSurge},
+								},
+							},
+						},
+						Multus: &v1alpha1.MultusSpec{
+							ImageSpecWithConfig: v1alpha1.ImageSpecWithConfig{
+								ImageSpec: v1alpha1.ImageSpec{
+									Image:      "multus-cni",
+									Repository: "ghcr.io/k8snetworkplumbingwg",
+									Version:    "v3.9.3",
+								},
+							},
+							ComponentDaemonSetSpec: v1alpha1.ComponentDaemonSetSpec{
+								UpdateStrategy: &appsv1.DaemonSetUpdateStrategy{
+									Type:          appsv1.OnDeleteDaemonSetStrategyType,
+									RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &maxUnavailable},
+								},
+							},
+						},
+						OVSCni: &v1alpha1.CNIPluginSpec{
+							ImageSpec: v1alpha1.ImageSpec{
+								Image:      "ovs-cni",
+								Repository: "ghcr.io/k8snetworkplumbingwg",
+								Version:    "v0.34.0",
+							},
+							ComponentDaemonSetSpec: v1alpha1.ComponentDaemonSetSpec{
+								UpdateStrategy: &appsv1.DaemonSetUpdateStrategy{
+									RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &maxUnavailable},
+								},
+							},
+						},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(And(
+				ContainSubstring("spec.secondaryNetwork.rdmaCni.updateStrategy.rollingUpdate.maxSurge: Invalid value"),
+				ContainSubstring("spec.secondaryNetwork.multus.updateStrategy.rollingUpdate: Forbidden"),
+				ContainSubstring("spec.secondaryNetwork.ovsCni.updateStrategy.rollingUpdate.maxUnavailable: "+
+					"Invalid value: \"0\": may not be 0 when maxSurge is 0")))
+		})
+		It("Valid security context", func() {
+			privileged := false
+			profile := "profiles/sriov-dp.json"
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					SecondaryNetwork: &v1alpha1.SecondaryNetworkSpec{
+						RdmaCni: &v1alpha1.CNIPluginSpec{
+							ImageSpec: v1alpha1.ImageSpec{
+								Image:      "rdma-cni",
+								Repository: "ghcr.io/mellanox",
+								Version:    "v1.2.0",
+							},
+							ComponentDaemonSetSpec: v1alpha1.ComponentDaemonSetSpec{
+								SecurityContext: &v1alpha1.ContainerSecurityContextSpec{
+									Privileged: &privileged,
+									SeccompProfile: &v1.SeccompProfile{
+										Type: v1.SeccompProfileTypeLocalhost, LocalhostProfile: &profile},
+									SELinuxOptions:         &v1.SELinuxOptions{Type: "container_device_t"},
+									ReadOnlyRootFilesystem: true,
+								},
+							},
+						},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Invalid security context", func() {
+			privileged := false
+			profile := "profiles/ovs-cni.json"
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					SecondaryNetwork: &v1alpha1.SecondaryNetworkSpec{
+						RdmaCni: &v1alpha1.CNIPluginSpec{
+							ImageSpec: v1alpha1.ImageSpec{
+								Image:      "rdma-cni",
+								Repository: "ghcr.io/mellanox",
+								Version:    "v1.2.0",
+							},
+							ComponentDaemonSetSpec: v1alpha1.ComponentDaemonSetSpec{
+								SecurityContext: &v1alpha1.ContainerSecurityContextSpec{
+									SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault},
+								},
+							},
+						},
+						OVSCni: &v1alpha1.CNIPluginSpec{
+							ImageSpec: v1alpha1.ImageSpec{
+								Image:      "ovs-cni",
+								Repository: "ghcr.io/k8snetworkplumbingwg",
+								Version:    "v0.34.0",
+							},
+							ComponentDaemonSetSpec: v1alpha1.ComponentDaemonSetSpec{
+								SecurityContext: &v1alpha1.ContainerSecurityContextSpec{
+									Privileged: &privileged,
+									SeccompProfile: &v1.SeccompProfile{
+										Type: v1.SeccompProfileTypeRuntimeDefault, LocalhostProfile: &profile},
+								},
+							},
+						},
+						IPoIB: &v1alpha1.CNIPluginSpec{
+							ImageSpec: v1alpha1.ImageSpec{
+								Image:      "ipoib-cni",
+								Repository: "ghcr.io/mellanox",
+								Version:    "v1.2.0",
+							},
+							ComponentDaemonSetSpec: v1alpha1.ComponentDaemonSetSpec{
+								SecurityContext: &v1alpha1.ContainerSecurityContextSpec{
+									Privileged:     &privileged,
+									SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeLocalhost},
+								},
+							},
+						},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(And(
+				ContainSubstring("spec.secondaryNetwork.rdmaCni.securityContext.seccompProfile: Forbidden"),
+				ContainSubstring("spec.secondaryNetwork.ovsCni.securityContext.seccompProfile.localhostProfile: "+
+					"Forbidden"),
+				ContainSubstring("spec.secondaryNetwork.ipoib.securityContext.seccompProfile.localhostProfile: "+
+					"Required value")))
+		})
+		It("Invalid priority class name", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					PriorityClassName: "Network_Critical",
+					SecondaryNetwork: &v1alpha1.SecondaryNetworkSpec{
+						RdmaCni: &v1alpha1.CNIPluginSpec{
+							ImageSpec: v1alpha1.ImageSpec{
+								Image:      "rdma-cni",
+								Repository: "ghcr.io/mellanox",
+								Version:    "v1.2.0",
+							},
+							ComponentDaemonSetSpec: v1alpha1.ComponentDaemonSetSpec{
+								PriorityClassName: "system-node-critical",
+							},
+						},
+						OVSCni: &v1alpha1.CNIPluginSpec{
+							ImageSpec: v1alpha1.ImageSpec{
+								Image:      "ovs-cni",
+								Repository: "ghcr.io/k8snetworkplumbingwg",
+								Version:    "v0.34.0",
+							},
+							ComponentDaemonSetSpec: v1alpha1.ComponentDaemonSetSpec{
+								PriorityClassName: "-ovs-cni",
+							},
+						},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(And(
+				ContainSubstring("spec.priorityClassName: Invalid value: \"Network_Critical\""),
+				ContainSubstring("spec.secondaryNetwork.ovsCni.priorityClassName: Invalid value: \"-ovs-cni\""),
+				Not(ContainSubstring("spec.secondaryNetwork.rdmaCni.priorityClassName"))))
+		})
+		It("Invalid proxy URL", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					Proxy: &v1alpha1.ProxySpec{
+						HTTPProxy:  "http://proxy.example.com:3128",
+						HTTPSProxy: "proxy.example.com:3128",
+						NoProxy:    "localhost,.cluster.local",
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("spec.proxy.httpsProxy: Invalid value"))
+			Expect(err.Error()).NotTo(ContainSubstring("spec.proxy.httpProxy"))
+		})
+		DescribeTable("Node Feature Discovery version",
+			func(version string, valid bool) {
+				nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+					ObjectMeta: metav1.ObjectMeta{Name: consts.NicClusterPolicyResourceName},
+					Spec: v1alpha1.NicClusterPolicySpec{
+						NodeFeatureDiscovery: &v1alpha1.NodeFeatureDiscoverySpec{
+							ImageSpec: v1alpha1.ImageSpec{
+								Image:      "node-feature-discovery",
+								Repository: "registry.k8s.io/nfd",
+								Version:    version,
+							},
+						},
+					},
+				}
+				validator := nicClusterPolicyValidator{}
+				_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+				if valid {
+					Expect(err).NotTo(HaveOccurred())
+				} else {
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("spec.nodeFeatureDiscovery.version: Invalid value"))
+				}
+			},
+			Entry("release", "v0.13.2", true),
+			Entry("newer release", "v0.15.0", true),
+			Entry("digest", "sha256:0e5ad5ee1a1b1bd8ee2e0e1a3ba8e1cb6bb4fd89d21cb3c0c7e8db7e83a0d1d2", true),
+			Entry("release without the NodeFeature API", "v0.12.1", false),
+			Entry("moving tag", "latest", false),
+		)
+		It("Valid NVIPAM pools", func() {
+			nicClusterPolicy := nvIpamNicClusterPolicy(
+				v1alpha1.NVIPAMPoolSpec{Name: "pool1", Subnet: "192.168.0.0/24", PerNodeBlockSize: 16,
+					Gateway: "192.168.0.1"},
+				v1alpha1.NVIPAMPoolSpec{Name: "pool2", Subnet: "192.168.1.0/24", PerNodeBlockSize: 16,
+					Gateway: "192.168.1.1"})
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Invalid NVIPAM pools with overlapping subnets", func() {
+			nicClusterPolicy := nvIpamNicClusterPolicy(
+				v1alpha1.NVIPAMPoolSpec{Name: "pool1", Subnet: "192.168.0.0/16", PerNodeBlockSize: 16,
+					Gateway: "192.168.0.1"},
+				v1alpha1.NVIPAMPoolSpec{Name: "pool2", Subnet: "192.168.1.0/24", PerNodeBlockSize: 16,
+					Gateway: "192.168.1.1"})
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("overlaps with the subnet of pool pool1"))
+		})
+		It("Invalid NVIPAM pools with duplicate names", func() {
+			nicClusterPolicy := nvIpamNicClusterPolicy(
+				v1alpha1.NVIPAMPoolSpec{Name: "pool1", Subnet: "192.168.0.0/24", PerNodeBlockSize: 16,
+					Gateway: "192.168.0.1"},
+				v1alpha1.NVIPAMPoolSpec{Name: "pool1", Subnet: "192.168.1.0/24", PerNodeBlockSize: 16,
+					Gateway: "192.168.1.1"})
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("spec.nvIpam.pools[1].name: Duplicate value"))
+		})
+		It("Invalid NVIPAM pool gateway and block size", func() {
+			nicClusterPolicy := nvIpamNicClusterPolicy(
+				v1alpha1.NVIPAMPoolSpec{Name: "pool1", Subnet: "192.168.0.0/28", PerNodeBlockSize: 16,
+					Gateway: "192.168.1.1"})
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err.Error()).To(And(
+				ContainSubstring("must be a valid IP address in the subnet"),
+				ContainSubstring("must be less than the amount of IPs in the subnet")))
+		})
+		It("Invalid NVIPAM pool subnet", func() {
+			nicClusterPolicy := nvIpamNicClusterPolicy(
+				v1alpha1.NVIPAMPoolSpec{Name: "pool1", Subnet: "192.168.0.0", PerNodeBlockSize: 16,
+					Gateway: "192.168.0.1"})
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("spec.nvIpam.pools[0].subnet: Invalid value"))
+		})
+		It("Valid NVIPAM CIDR pools", func() {
+			gatewayIndex := int32(1)
+			nicClusterPolicy := nvIpamNicClusterPolicy()
+			nicClusterPolicy.Spec.NvIpam.CIDRPools = []v1alpha1.NVIPAMCIDRPoolSpec{{
+				Name: "pool1", CIDR: "10.0.0.0/16", PerNodeNetworkPrefix: 24, GatewayIndex: &gatewayIndex,
+				Exclusions: []v1alpha1.NVIPAMExcludeRangeSpec{{StartIP: "10.0.0.10", EndIP: "10.0.0.20"}},
+			}}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Invalid NVIPAM CIDR pool overlapping with an IP pool", func() {
+			nicClusterPolicy := nvIpamNicClusterPolicy(v1alpha1.NVIPAMPoolSpec{
+				Name: "pool1", Subnet: "10.0.1.0/24", PerNodeBlockSize: 16, Gateway: "10.0.1.1"})
+			nicClusterPolicy.Spec.NvIpam.CIDRPools = []v1alpha1.NVIPAMCIDRPoolSpec{{
+				Name: "pool1", CIDR: "10.0.0.0/16", PerNodeNetworkPrefix: 24}}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring(
+				"spec.nvIpam.cidrPools[0].cidr: Invalid value: \"10.0.0.0/16\": overlaps with the subnet of pool pool1"))
+		})
+		It("Invalid NVIPAM CIDR pool per node network prefix", func() {
+			nicClusterPolicy := nvIpamNicClusterPolicy()
+			nicClusterPolicy.Spec.NvIpam.CIDRPools = []v1alpha1.NVIPAMCIDRPoolSpec{{
+				Name: "pool1", CIDR: "10.0.0.0/16", PerNodeNetworkPrefix: 8}}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("must be longer than the prefix of the CIDR and not longer than 32"))
+		})
+		It("Invalid NVIPAM CIDR pool gateway index and exclusions", func() {
+			gatewayIndex := int32(256)
+			nicClusterPolicy := nvIpamNicClusterPolicy()
+			nicClusterPolicy.Spec.NvIpam.CIDRPools = []v1alpha1.NVIPAMCIDRPoolSpec{{
+				Name: "pool1", CIDR: "10.0.0.0/16", PerNodeNetworkPrefix: 24, GatewayIndex: &gatewayIndex,
+				Exclusions: []v1alpha1.NVIPAMExcludeRangeSpec{
+					{StartIP: "10.0.0.20", EndIP: "10.0.0.10"},
+					{StartIP: "10.1.0.1", EndIP: "10.1.0.2"},
+				},
+			}}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), &nicClusterPolicy)
+			Expect(err.Error()).To(And(
+				ContainSubstring("must be less than the amount of IPs in the network of a node"),
+				ContainSubstring("spec.nvIpam.cidrPools[0].exclusions[0].endIP: Invalid value: \"10.0.0.10\": "+
+					"must not be lower than startIP"),
+				ContainSubstring("spec.nvIpam.cidrPools[0].exclusions[1].startIP: Invalid value")))
+		})
 		It("Invalid Repository NicFeatureDiscovery", func() {
 			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
 				ObjectMeta: metav1.ObjectMeta{Name: "test"},
@@ -634,12 +1516,12 @@ var _ = Describe("Validate", func() {
 				ObjectMeta: metav1.ObjectMeta{Name: "test"},
 				Spec: v1alpha1.NicClusterPolicySpec{
 					SecondaryNetwork: &v1alpha1.SecondaryNetworkSpec{
-						CniPlugins: &v1alpha1.ImageSpec{
+						CniPlugins: &v1alpha1.CNIPluginSpec{ImageSpec: v1alpha1.ImageSpec{
 							Image:            "mofed",
 							Repository:       "ghcr.io/mellanox!@!#$!",
 							Version:          "23.10-0.2.2.0",
 							ImagePullSecrets: []string{},
-						},
+						}},
 					},
 				},
 			}
@@ -653,12 +1535,12 @@ var _ = Describe("Validate", func() {
 				ObjectMeta: metav1.ObjectMeta{Name: "test"},
 				Spec: v1alpha1.NicClusterPolicySpec{
 					SecondaryNetwork: &v1alpha1.SecondaryNetworkSpec{
-						IPoIB: &v1alpha1.ImageSpec{
+						IPoIB: &v1alpha1.CNIPluginSpec{ImageSpec: v1alpha1.ImageSpec{
 							Image:            "mofed",
 							Repository:       "ghcr.io/mellanox!@!#$!",
 							Version:          "23.10-0.2.2.0",
 							ImagePullSecrets: []string{},
-						},
+						}},
 					},
 				},
 			}
@@ -667,17 +1549,17 @@ var _ = Describe("Validate", func() {
 			Expect(err.Error()).To(ContainSubstring(
 				"invalid container image repository format"))
 		})
-		It("Invalid Repository SecondaryNetwork IpamPlugin", func() {
+		It("Invalid Repository SecondaryNetwork OVSCni", func() {
 			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
 				ObjectMeta: metav1.ObjectMeta{Name: "test"},
 				Spec: v1alpha1.NicClusterPolicySpec{
 					SecondaryNetwork: &v1alpha1.SecondaryNetworkSpec{
-						IpamPlugin: &v1alpha1.ImageSpec{
-							Image:            "mofed",
+						OVSCni: &v1alpha1.CNIPluginSpec{ImageSpec: v1alpha1.ImageSpec{
+							Image:            "ovs-cni-plugin",
 							Repository:       "ghcr.io/mellanox!@!#$!",
-							Version:          "23.10-0.2.2.0",
+							Version:          "v0.34.0",
 							ImagePullSecrets: []string{},
-						},
+						}},
 					},
 				},
 			}
@@ -686,7 +1568,140 @@ var _ = Describe("Validate", func() {
 			Expect(err.Error()).To(ContainSubstring(
 				"invalid container image repository format"))
 		})
-		It("Empty ContainerResources OFEDDriver", func() {
+		It("Invalid Repository SecondaryNetwork RdmaCni", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					SecondaryNetwork: &v1alpha1.SecondaryNetworkSpec{
+						RdmaCni: &v1alpha1.CNIPluginSpec{ImageSpec: v1alpha1.ImageSpec{
+							Image:            "rdma-cni",
+							Repository:       "ghcr.io/mellanox!@!#$!",
+							Version:          "v1.2.0",
+							ImagePullSecrets: []string{},
+						}},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring(
+				"invalid container image repository format"))
+		})
+		It("Invalid Repository SecondaryNetwork IpamPlugin", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					SecondaryNetwork: &v1alpha1.SecondaryNetworkSpec{
+						IpamPlugin: &v1alpha1.WhereaboutsSpec{
+							ImageSpec: v1alpha1.ImageSpec{
+								Image:            "mofed",
+								Repository:       "ghcr.io/mellanox!@!#$!",
+								Version:          "23.10-0.2.2.0",
+								ImagePullSecrets: []string{},
+							},
+						},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring(
+				"invalid container image repository format"))
+		})
+		It("Valid IpamPlugin reconciler cron expression", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: consts.NicClusterPolicyResourceName},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					SecondaryNetwork: &v1alpha1.SecondaryNetworkSpec{
+						IpamPlugin: &v1alpha1.WhereaboutsSpec{
+							ImageSpec: v1alpha1.ImageSpec{
+								Image:      "whereabouts",
+								Repository: "ghcr.io/k8snetworkplumbingwg",
+								Version:    "v0.6.2",
+							},
+							ReconcilerCronExpression: "*/15 * * * *",
+						},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Invalid IpamPlugin reconciler cron expression", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					SecondaryNetwork: &v1alpha1.SecondaryNetworkSpec{
+						IpamPlugin: &v1alpha1.WhereaboutsSpec{
+							ImageSpec: v1alpha1.ImageSpec{
+								Image:      "whereabouts",
+								Repository: "ghcr.io/k8snetworkplumbingwg",
+								Version:    "v0.6.2",
+							},
+							ReconcilerCronExpression: "30 4 * *",
+						},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("must be a cron expression with five fields"))
+		})
+		Context("Default IPAM", func() {
+			newPolicy := func(whereabouts, nvIpam bool, defaultIPAM v1alpha1.IPAMPlugin) *v1alpha1.NicClusterPolicy {
+				policy := &v1alpha1.NicClusterPolicy{
+					ObjectMeta: metav1.ObjectMeta{Name: consts.NicClusterPolicyResourceName},
+					Spec:       v1alpha1.NicClusterPolicySpec{DefaultIPAM: defaultIPAM},
+				}
+				if whereabouts {
+					policy.Spec.SecondaryNetwork = &v1alpha1.SecondaryNetworkSpec{
+						IpamPlugin: &v1alpha1.WhereaboutsSpec{ImageSpec: v1alpha1.ImageSpec{
+							Image: "whereabouts", Repository: "ghcr.io/k8snetworkplumbingwg", Version: "v0.6.2"}},
+					}
+				}
+				if nvIpam {
+					policy.Spec.NvIpam = &v1alpha1.NVIPAMSpec{ImageSpec: v1alpha1.ImageSpec{
+						Image: "nvidia-k8s-ipam", Repository: "ghcr.io/mellanox", Version: "v0.1.2"}}
+				}
+				return policy
+			}
+			validator := nicClusterPolicyValidator{}
+
+			It("Valid without default IPAM if a single IPAM plugin is deployed", func() {
+				_, err := validator.ValidateCreate(context.TODO(), newPolicy(true, false, ""))
+				Expect(err).NotTo(HaveOccurred())
+				_, err = validator.ValidateCreate(context.TODO(), newPolicy(false, true, ""))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("Invalid without default IPAM if both IPAM plugins are deployed", func() {
+				_, err := validator.ValidateCreate(context.TODO(), newPolicy(true, true, ""))
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("spec.defaultIpam: Required value"))
+			})
+			It("Valid with default IPAM if both IPAM plugins are deployed", func() {
+				_, err := validator.ValidateCreate(context.TODO(), newPolicy(true, true, v1alpha1.IPAMPluginNVIPAM))
+				Expect(err).NotTo(HaveOccurred())
+				_, err = validator.ValidateCreate(context.TODO(),
+					newPolicy(true, true, v1alpha1.IPAMPluginWhereabouts))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("Invalid with default IPAM which is not deployed", func() {
+				_, err := validator.ValidateCreate(context.TODO(), newPolicy(true, false, v1alpha1.IPAMPluginNVIPAM))
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("nvIpam is not configured"))
+				_, err = validator.ValidateCreate(context.TODO(),
+					newPolicy(false, true, v1alpha1.IPAMPluginWhereabouts))
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("secondaryNetwork.ipamPlugin is not configured"))
+			})
+			It("Invalid with unknown default IPAM", func() {
+				_, err := validator.ValidateCreate(context.TODO(), newPolicy(true, true, "host-local"))
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Unsupported value"))
+			})
+		})
+		It("Empty ContainerResources OFEDDriver", func() {
 			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
 				ObjectMeta: metav1.ObjectMeta{Name: "test"},
 				Spec: v1alpha1.NicClusterPolicySpec{
@@ -769,7 +1784,7 @@ var _ = Describe("Validate", func() {
 							ContainerResources: []v1alpha1.ResourceRequirements{
 								{
 									Name:     "mofed-container",
-									Requests: v1.ResourceList{"ephemeral-storage": resource.MustParse("2Gi")},
+									Requests: v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")},
 								},
 							},
 						},
@@ -779,7 +1794,113 @@ var _ = Describe("Validate", func() {
 			validator := nicClusterPolicyValidator{}
 			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
 			Expect(err.Error()).To(ContainSubstring(
-				"Unsupported value: ephemeral-storage: supported values: \"cpu\", \"memory\""))
+				"Unsupported value: nvidia.com/gpu: supported values: \"cpu\", \"memory\", " +
+					"\"ephemeral-storage\", \"hugepages-<size>\""))
+		})
+		It("Valid Ephemeral Storage and Hugepages Resources OFEDDriver", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					OFEDDriver: &v1alpha1.OFEDDriverSpec{
+						ImageSpec: v1alpha1.ImageSpec{
+							Image:            "mofed",
+							Repository:       "ghcr.io/mellanox",
+							Version:          "23.10-0.2.2.0",
+							ImagePullSecrets: []string{},
+							ContainerResources: []v1alpha1.ResourceRequirements{
+								{
+									Name: "mofed-container",
+									Requests: v1.ResourceList{
+										"ephemeral-storage": resource.MustParse("2Gi"),
+										"hugepages-2Mi":     resource.MustParse("512Mi"),
+									},
+									Limits: v1.ResourceList{
+										"ephemeral-storage": resource.MustParse("10Gi"),
+										"hugepages-2Mi":     resource.MustParse("512Mi"),
+										"hugepages-1Gi":     resource.MustParse("2Gi"),
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Invalid Hugepages Size OFEDDriver", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					OFEDDriver: &v1alpha1.OFEDDriverSpec{
+						ImageSpec: v1alpha1.ImageSpec{
+							Image:            "mofed",
+							Repository:       "ghcr.io/mellanox",
+							Version:          "23.10-0.2.2.0",
+							ImagePullSecrets: []string{},
+							ContainerResources: []v1alpha1.ResourceRequirements{
+								{
+									Name:   "mofed-container",
+									Limits: v1.ResourceList{"hugepages-huge": resource.MustParse("1Gi")},
+								},
+							},
+						},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("Unsupported value: hugepages-huge"))
+		})
+		It("Hugepages Requests without Limits OFEDDriver", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					OFEDDriver: &v1alpha1.OFEDDriverSpec{
+						ImageSpec: v1alpha1.ImageSpec{
+							Image:            "mofed",
+							Repository:       "ghcr.io/mellanox",
+							Version:          "23.10-0.2.2.0",
+							ImagePullSecrets: []string{},
+							ContainerResources: []v1alpha1.ResourceRequirements{
+								{
+									Name:     "mofed-container",
+									Requests: v1.ResourceList{"hugepages-2Mi": resource.MustParse("512Mi")},
+								},
+							},
+						},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("resource limit for hugepages-2Mi must be set"))
+		})
+		It("Hugepages Requests not equal to Limits OFEDDriver", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					OFEDDriver: &v1alpha1.OFEDDriverSpec{
+						ImageSpec: v1alpha1.ImageSpec{
+							Image:            "mofed",
+							Repository:       "ghcr.io/mellanox",
+							Version:          "23.10-0.2.2.0",
+							ImagePullSecrets: []string{},
+							ContainerResources: []v1alpha1.ResourceRequirements{
+								{
+									Name:     "mofed-container",
+									Requests: v1.ResourceList{"hugepages-2Mi": resource.MustParse("256Mi")},
+									Limits:   v1.ResourceList{"hugepages-2Mi": resource.MustParse("512Mi")},
+								},
+							},
+						},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("resource request for hugepages-2Mi must be equal to the limit"))
 		})
 		It("Invalid Resource Requests Container Name OFEDDriver", func() {
 			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
@@ -804,7 +1925,8 @@ var _ = Describe("Validate", func() {
 			validator := nicClusterPolicyValidator{}
 			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
 			Expect(err.Error()).To(ContainSubstring(
-				"Unsupported value: \"invalid-container-name\": supported values: \"mofed-container\""))
+				"Unsupported value: \"invalid-container-name\": supported values: \"mofed-container\", " +
+					"\"openshift-driver-toolkit-ctr\""))
 		})
 		It("passes when DocaTelemetryService imageSpec is valid", func() {
 			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
@@ -903,6 +2025,557 @@ var _ = Describe("Validate", func() {
 			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
 			Expect(err.Error()).To(ContainSubstring("a lowercase RFC 1123 subdomain must consist of"))
 		})
+		It("succeeds with valid providers and Prometheus exporter", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					DOCATelemetryService: &v1alpha1.DOCATelemetryServiceSpec{
+						ImageSpec: v1alpha1.ImageSpec{
+							Image:      "doca-telemetry-service",
+							Repository: "ghcr.io/mellanox",
+							Version:    "1.2",
+						},
+						Providers: []string{"sysfs", "pod_resources"},
+						Prometheus: &v1alpha1.DOCATelemetryServicePrometheusSpec{
+							Port:           9189,
+							IgnoreCounters: []string{"rx_bytes", "ib:port_xmit_data"},
+						},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("fails when providers and ignored counters contain invalid characters", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					DOCATelemetryService: &v1alpha1.DOCATelemetryServiceSpec{
+						ImageSpec: v1alpha1.ImageSpec{
+							Image:      "doca-telemetry-service",
+							Repository: "ghcr.io/mellanox",
+							Version:    "1.2",
+						},
+						Providers: []string{"sysfs\nverbose=7"},
+						Prometheus: &v1alpha1.DOCATelemetryServicePrometheusSpec{
+							IgnoreCounters: []string{"rx_bytes,tx_bytes"},
+						},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("spec.docaTelemetryService.providers[0]: Invalid value"))
+			Expect(err.Error()).To(ContainSubstring(
+				"spec.docaTelemetryService.prometheus.ignoreCounters[0]: Invalid value"))
+		})
+	})
+	Context("Scheduling tests", func() {
+		It("Valid Tolerations and NodeAffinity", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					Tolerations: []v1.Toleration{
+						{Key: "nvidia.com/gpu", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+						{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "network"},
+						{Operator: v1.TolerationOpExists},
+					},
+					NodeAffinity: &v1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+							NodeSelectorTerms: []v1.NodeSelectorTerm{{
+								MatchExpressions: []v1.NodeSelectorRequirement{
+									{Key: "feature.node.kubernetes.io/pci-15b3.present",
+										Operator: v1.NodeSelectorOpIn, Values: []string{"true"}},
+									{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"a", "b"}},
+									{Key: "zone", Operator: v1.NodeSelectorOpNotIn, Values: []string{"a"}},
+								},
+							}},
+						},
+						PreferredDuringSchedulingIgnoredDuringExecution: []v1.PreferredSchedulingTerm{{
+							Weight: 10,
+							Preference: v1.NodeSelectorTerm{
+								MatchExpressions: []v1.NodeSelectorRequirement{
+									{Key: "cpus", Operator: v1.NodeSelectorOpGt, Values: []string{"8"}},
+								},
+							},
+						}},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Invalid Toleration operator", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					Tolerations: []v1.Toleration{{Key: "dedicated", Operator: "Equals", Value: "network"}},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("spec.tolerations[0].operator: Unsupported value: \"Equals\""))
+		})
+		It("Invalid Toleration with Exists operator and value", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					Tolerations: []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpExists, Value: "network"}},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("value must be empty when `operator` is 'Exists'"))
+		})
+		It("Invalid Toleration with empty key and Equal operator", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					Tolerations: []v1.Toleration{{Operator: v1.TolerationOpEqual, Value: "network"}},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("operator must be Exists when `key` is empty"))
+		})
+		It("Invalid Toleration effect", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					Tolerations: []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpExists, Effect: "NoScheduled"}},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("spec.tolerations[0].effect: Unsupported value: \"NoScheduled\""))
+		})
+		It("Invalid NodeAffinity with empty node selector term", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					NodeAffinity: &v1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+							NodeSelectorTerms: []v1.NodeSelectorTerm{{}},
+						},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("must have at least one of matchExpressions or matchFields"))
+		})
+		It("Invalid NodeAffinity operator", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					NodeAffinity: nodeAffinityWithExpressions(
+						v1.NodeSelectorRequirement{Key: "zone", Operator: "Equals", Values: []string{"a"}}),
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("Unsupported value: \"Equals\""))
+		})
+		It("Invalid NodeAffinity with In operator and no values", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					NodeAffinity: nodeAffinityWithExpressions(
+						v1.NodeSelectorRequirement{Key: "zone", Operator: v1.NodeSelectorOpIn}),
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("must be specified when `operator` is 'In' or 'NotIn'"))
+		})
+		It("Invalid NodeAffinity with Gt operator and non integer value", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					NodeAffinity: nodeAffinityWithExpressions(
+						v1.NodeSelectorRequirement{Key: "cpus", Operator: v1.NodeSelectorOpGt, Values: []string{"many"}}),
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("must be an integer when `operator` is 'Lt' or 'Gt'"))
+		})
+		It("Invalid NodeAffinity with malformed key", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					NodeAffinity: nodeAffinityWithExpressions(
+						v1.NodeSelectorRequirement{Key: "feature.node.kubernetes.io//pci", Operator: v1.NodeSelectorOpExists}),
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("matchExpressions[0].key: Invalid value"))
+		})
+		It("Invalid NodeAffinity with conflicting In and NotIn values", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					NodeAffinity: nodeAffinityWithExpressions(
+						v1.NodeSelectorRequirement{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"a"}},
+						v1.NodeSelectorRequirement{Key: "zone", Operator: v1.NodeSelectorOpNotIn, Values: []string{"a", "b"}}),
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("no value of key zone satisfies all requirements"))
+		})
+		It("Invalid NodeAffinity with Exists and DoesNotExist on the same key", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					NodeAffinity: nodeAffinityWithExpressions(
+						v1.NodeSelectorRequirement{Key: "zone", Operator: v1.NodeSelectorOpExists},
+						v1.NodeSelectorRequirement{Key: "zone", Operator: v1.NodeSelectorOpDoesNotExist}),
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("key zone is required to exist and not to exist"))
+		})
+		It("Invalid NodeAffinity preferred term weight", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					NodeAffinity: &v1.NodeAffinity{
+						PreferredDuringSchedulingIgnoredDuringExecution: []v1.PreferredSchedulingTerm{{
+							Weight: 0,
+							Preference: v1.NodeSelectorTerm{
+								MatchExpressions: []v1.NodeSelectorRequirement{
+									{Key: "zone", Operator: v1.NodeSelectorOpExists},
+								},
+							},
+						}},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("must be in the range 1-100"))
+		})
+		It("Invalid component Toleration", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					SecondaryNetwork: &v1alpha1.SecondaryNetworkSpec{
+						RdmaCni: &v1alpha1.CNIPluginSpec{
+							ImageSpec: v1alpha1.ImageSpec{
+								Image:      "rdma-cni",
+								Repository: "ghcr.io/mellanox",
+								Version:    "v1.2.0",
+							},
+							ComponentDaemonSetSpec: v1alpha1.ComponentDaemonSetSpec{
+								Tolerations: []v1.Toleration{
+									{Key: "dedicated", Operator: v1.TolerationOpExists, Value: "rdma"}},
+							},
+						},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("spec.secondaryNetwork.rdmaCni.tolerations[0].value"))
+		})
+	})
+	Context("RawPatches tests", func() {
+		It("Valid RawPatches", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					RawPatches: []v1alpha1.RawPatch{
+						{
+							Target: v1alpha1.RawPatchTarget{Kind: "DaemonSet"},
+							Type:   v1alpha1.RawPatchTypeStrategicMerge,
+							Patch:  "spec:\n  template:\n    spec:\n      nodeSelector:\n        role: network",
+						},
+						{
+							Target: v1alpha1.RawPatchTarget{Kind: "DaemonSet", Name: "mofed-ubuntu22.04-ds"},
+							Type:   v1alpha1.RawPatchTypeJSON6902,
+							Patch:  `[{"op": "add", "path": "/metadata/labels/foo", "value": "bar"}]`,
+						},
+					},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Invalid RawPatches without target kind", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					RawPatches: []v1alpha1.RawPatch{{Patch: `{"metadata": {"labels": {"foo": "bar"}}}`}},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("spec.rawPatches[0].target.kind: Required value"))
+		})
+		It("Invalid RawPatches with malformed JSON6902 patch", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					RawPatches: []v1alpha1.RawPatch{{
+						Target: v1alpha1.RawPatchTarget{Kind: "DaemonSet"},
+						Type:   v1alpha1.RawPatchTypeJSON6902,
+						Patch:  `{"op": "add", "path": "/metadata/labels/foo", "value": "bar"}`,
+					}},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("Invalid JSON6902 patch"))
+		})
+		It("Invalid RawPatches with strategic merge patch which is not an object", func() {
+			nicClusterPolicy := &v1alpha1.NicClusterPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.NicClusterPolicySpec{
+					RawPatches: []v1alpha1.RawPatch{{
+						Target: v1alpha1.RawPatchTarget{Kind: "DaemonSet"},
+						Patch:  "- foo",
+					}},
+				},
+			}
+			validator := nicClusterPolicyValidator{}
+			_, err := validator.ValidateCreate(context.TODO(), nicClusterPolicy)
+			Expect(err.Error()).To(ContainSubstring("Invalid strategic merge patch, must be an object"))
+		})
+	})
+})
+
+var _ = Describe("Validate NicClusterPolicy node selector", func() {
+	newValidator := func(objs ...client.Object) nicClusterPolicyValidator {
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		return nicClusterPolicyValidator{
+			client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+		}
+	}
+	newPolicy := func(name string, nodeSelector map[string]string) *v1alpha1.NicClusterPolicy {
+		return &v1alpha1.NicClusterPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1alpha1.NicClusterPolicySpec{NodeSelector: nodeSelector},
+		}
+	}
+	ofedDriver := &v1alpha1.OFEDDriverSpec{ImageSpec: v1alpha1.ImageSpec{
+		Image: "doca-driver", Repository: "nvcr.io/nvidia/mellanox", Version: "24.01-0.3.3.1", ImagePullSecrets: []string{}}}
+
+	It("Valid single policy without node selector", func() {
+		validator := newValidator()
+		_, err := validator.ValidateCreate(context.TODO(), newPolicy("ib", nil))
+		Expect(err).NotTo(HaveOccurred())
+	})
+	It("Valid policies with non-overlapping node selectors", func() {
+		validator := newValidator(newPolicy(consts.NicClusterPolicyResourceName,
+			map[string]string{"network.nvidia.com/type": "eth"}))
+		_, err := validator.ValidateCreate(context.TODO(), newPolicy("ib",
+			map[string]string{"network.nvidia.com/type": "ib", "kubernetes.io/os": "linux"}))
+		Expect(err).NotTo(HaveOccurred())
+	})
+	It("Invalid policy without node selector when another policy exists", func() {
+		validator := newValidator(newPolicy(consts.NicClusterPolicyResourceName,
+			map[string]string{"network.nvidia.com/type": "eth"}))
+		_, err := validator.ValidateCreate(context.TODO(), newPolicy("ib", nil))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("nodeSelector is required when several NicClusterPolicies exist"))
+	})
+	It("Invalid policies with overlapping node selectors", func() {
+		validator := newValidator(newPolicy(consts.NicClusterPolicyResourceName,
+			map[string]string{"kubernetes.io/os": "linux"}))
+		_, err := validator.ValidateCreate(context.TODO(), newPolicy("ib",
+			map[string]string{"network.nvidia.com/type": "ib"}))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(
+			"nodeSelector overlaps with the nodeSelector of NicClusterPolicy " + consts.NicClusterPolicyResourceName))
+	})
+	It("Invalid node selector label", func() {
+		validator := newValidator()
+		_, err := validator.ValidateCreate(context.TODO(), newPolicy("ib",
+			map[string]string{"network.nvidia.com/type": "ib!"}))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.nodeSelector[network.nvidia.com/type]"))
+	})
+	It("Invalid OFED driver configured in several policies", func() {
+		policy := newPolicy(consts.NicClusterPolicyResourceName, map[string]string{"network.nvidia.com/type": "eth"})
+		policy.Spec.OFEDDriver = ofedDriver
+		validator := newValidator(policy)
+		ibPolicy := newPolicy("ib", map[string]string{"network.nvidia.com/type": "ib"})
+		ibPolicy.Spec.OFEDDriver = ofedDriver
+		_, err := validator.ValidateCreate(context.TODO(), ibPolicy)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.ofedDriver: Forbidden"))
+	})
+	It("Invalid cluster-wide component in another policy", func() {
+		policy := newPolicy("ib", nil)
+		policy.Spec.IBKubernetes = &v1alpha1.IBKubernetesSpec{ImageSpec: v1alpha1.ImageSpec{
+			Image: "ib-kubernetes", Repository: "ghcr.io/mellanox", Version: "v1.0.2", ImagePullSecrets: []string{}}}
+		validator := newValidator()
+		_, err := validator.ValidateCreate(context.TODO(), policy)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.ibKubernetes: Forbidden"))
+	})
+	It("Invalid NodeFeatureRules in another policy", func() {
+		policy := newPolicy("ib", nil)
+		policy.Spec.NodeFeatureRules = &v1alpha1.NodeFeatureRulesSpec{}
+		validator := newValidator()
+		_, err := validator.ValidateCreate(context.TODO(), policy)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.nodeFeatureRules: Forbidden"))
+	})
+	It("Invalid additional manifests in another policy", func() {
+		policy := newPolicy("ib", nil)
+		policy.Spec.AdditionalManifests = &v1alpha1.AdditionalManifestsSpec{ConfigMapName: "additional-manifests"}
+		validator := newValidator()
+		_, err := validator.ValidateCreate(context.TODO(), policy)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.additionalManifests: Forbidden"))
+	})
+})
+
+var _ = Describe("Validate OFED driver forcePrecompiled nodes", func() {
+	newNode := func(name, osName string, labels map[string]string) *v1.Node {
+		node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{
+			nodeinfo.NodeLabelMlnxNIC: "true",
+			nodeinfo.NodeLabelOSName:  osName,
+		}}}
+		for key, value := range labels {
+			node.Labels[key] = value
+		}
+		return node
+	}
+	newValidator := func(objs ...client.Object) nicClusterPolicyValidator {
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(v1.AddToScheme(scheme)).To(Succeed())
+		return nicClusterPolicyValidator{
+			client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+		}
+	}
+	newPolicy := func(nodeSelector map[string]string) *v1alpha1.NicClusterPolicy {
+		return &v1alpha1.NicClusterPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: consts.NicClusterPolicyResourceName},
+			Spec: v1alpha1.NicClusterPolicySpec{
+				NodeSelector: nodeSelector,
+				OFEDDriver: &v1alpha1.OFEDDriverSpec{
+					ImageSpec: v1alpha1.ImageSpec{
+						Image: "doca-driver", Repository: "nvcr.io/nvidia/mellanox", Version: "24.01-0.3.3.1",
+						ImagePullSecrets: []string{}},
+					ForcePrecompiled: true,
+				},
+			},
+		}
+	}
+
+	It("Valid forcePrecompiled on Ubuntu nodes", func() {
+		validator := newValidator(newNode("node1", "ubuntu", nil), newNode("node2", "ubuntu", nil))
+		_, err := validator.ValidateCreate(context.TODO(), newPolicy(nil))
+		Expect(err).NotTo(HaveOccurred())
+	})
+	It("Invalid forcePrecompiled on nodes running an unsupported OS", func() {
+		validator := newValidator(newNode("node1", "ubuntu", nil), newNode("node2", "rhcos", nil),
+			newNode("node3", "rhel", nil))
+		_, err := validator.ValidateCreate(context.TODO(), newPolicy(nil))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(
+			"spec.ofedDriver.forcePrecompiled: Forbidden: precompiled drivers are only published for ubuntu, " +
+				"selected nodes run rhcos, rhel"))
+	})
+	It("Valid forcePrecompiled when nodes running an unsupported OS are not selected", func() {
+		validator := newValidator(newNode("node1", "ubuntu", map[string]string{"network.nvidia.com/type": "ib"}),
+			newNode("node2", "rhcos", nil))
+		_, err := validator.ValidateCreate(context.TODO(), newPolicy(map[string]string{"network.nvidia.com/type": "ib"}))
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
+
+var _ = Describe("Validate container resources against the node capacity", func() {
+	newNode := func(name, cpu, memory string, labels map[string]string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse(memory),
+			}},
+		}
+	}
+	newValidator := func(objs ...client.Object) nicClusterPolicyValidator {
+		scheme := runtime.NewScheme()
+		Expect(v1.AddToScheme(scheme)).To(Succeed())
+		return nicClusterPolicyValidator{
+			client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+		}
+	}
+	newPolicy := func(cpu, memory string) *v1alpha1.NicClusterPolicy {
+		return &v1alpha1.NicClusterPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: consts.NicClusterPolicyResourceName},
+			Spec: v1alpha1.NicClusterPolicySpec{
+				NicFeatureDiscovery: &v1alpha1.NICFeatureDiscoverySpec{
+					ImageSpec: v1alpha1.ImageSpec{
+						Image: "nic-feature-discovery", Repository: "ghcr.io/mellanox", Version: "v0.0.1",
+						ImagePullSecrets: []string{},
+						ContainerResources: []v1alpha1.ResourceRequirements{{
+							Name:     "nic-feature-discovery",
+							Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+							Limits:   v1.ResourceList{v1.ResourceMemory: resource.MustParse(memory)},
+						}},
+					},
+				},
+			},
+		}
+	}
+
+	It("No warning when the requests fit a selected node", func() {
+		validator := newValidator(newNode("node1", "1", "1Gi", nil), newNode("node2", "8", "16Gi", nil))
+		Expect(validator.getNodeCapacityWarnings(context.TODO(), newPolicy("2", "2Gi"))).To(BeEmpty())
+	})
+	It("Warning when the requests exceed the allocatable resources of every node", func() {
+		validator := newValidator(newNode("node1", "1", "1Gi", nil), newNode("node2", "8", "1Gi", nil))
+		Expect(validator.getNodeCapacityWarnings(context.TODO(), newPolicy("2", "2Gi"))).To(ConsistOf(
+			"spec.nicFeatureDiscovery.containerResources: requests cpu=2, memory=2Gi exceed the allocatable " +
+				"resources of every schedulable node selected for the component, its pods can't be scheduled"))
+	})
+	It("Warning when the requests only fit nodes which are not selected", func() {
+		node := newNode("node1", "8", "16Gi", map[string]string{"network.nvidia.com/type": "eth"})
+		cordonedNode := newNode("node2", "8", "16Gi", map[string]string{"network.nvidia.com/type": "ib"})
+		cordonedNode.Spec.Unschedulable = true
+		validator := newValidator(node, cordonedNode, newNode("node3", "1", "1Gi",
+			map[string]string{"network.nvidia.com/type": "ib"}))
+		policy := newPolicy("2", "2Gi")
+		policy.Spec.NicFeatureDiscovery.NodeAffinity = &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+				NodeSelectorTerms: []v1.NodeSelectorTerm{{MatchExpressions: []v1.NodeSelectorRequirement{{
+					Key: "network.nvidia.com/type", Operator: v1.NodeSelectorOpIn, Values: []string{"ib"}}}}},
+			},
+		}
+		Expect(validator.getNodeCapacityWarnings(context.TODO(), policy)).To(HaveLen(1))
+	})
+	It("No warning when no node is selected", func() {
+		validator := newValidator(newNode("node1", "1", "1Gi", nil))
+		policy := newPolicy("2", "2Gi")
+		policy.Spec.NodeSelector = map[string]string{"network.nvidia.com/type": "ib"}
+		Expect(validator.getNodeCapacityWarnings(context.TODO(), policy)).To(BeEmpty())
+	})
+	It("No warning without client", func() {
+		validator := nicClusterPolicyValidator{}
+		Expect(validator.getNodeCapacityWarnings(context.TODO(), newPolicy("2", "2Gi"))).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Validation schemas health check", func() {
+	It("Succeeds once the schemas are loaded", func() {
+		Expect(SchemasLoadedChecker(nil)).To(Succeed())
+	})
+	It("Does not reload the schemas", func() {
+		loaded := schemaValidators
+		InitSchemaValidator("../../../webhook-schemas")
+		Expect(schemaValidators).To(BeIdenticalTo(loaded))
 	})
 })
 
@@ -943,3 +2616,28 @@ func sriovDPNicClusterPolicy(config string) v1alpha1.NicClusterPolicy {
 		},
 	}
 }
+
+func nvIpamNicClusterPolicy(pools ...v1alpha1.NVIPAMPoolSpec) v1alpha1.NicClusterPolicy {
+	return v1alpha1.NicClusterPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: consts.NicClusterPolicyResourceName},
+		Spec: v1alpha1.NicClusterPolicySpec{
+			NvIpam: &v1alpha1.NVIPAMSpec{
+				ImageSpec: v1alpha1.ImageSpec{
+					Image:            "nvidia-k8s-ipam",
+					Repository:       "ghcr.io/mellanox",
+					Version:          "v0.1.2",
+					ImagePullSecrets: []string{},
+				},
+				Pools: pools,
+			},
+		},
+	}
+}
+
+func nodeAffinityWithExpressions(expressions ...v1.NodeSelectorRequirement) *v1.NodeAffinity {
+	return &v1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+			NodeSelectorTerms: []v1.NodeSelectorTerm{{MatchExpressions: expressions}},
+		},
+	}
+}
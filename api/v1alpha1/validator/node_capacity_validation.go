@@ -0,0 +1,185 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+// nodeSelectorOperators maps the operators of the node selector requirements to the label selector operators
+var nodeSelectorOperators = map[v1.NodeSelectorOperator]selection.Operator{
+	v1.NodeSelectorOpIn:           selection.In,
+	v1.NodeSelectorOpNotIn:        selection.NotIn,
+	v1.NodeSelectorOpExists:       selection.Exists,
+	v1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	v1.NodeSelectorOpGt:           selection.GreaterThan,
+	v1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+// getNodeCapacityWarnings warns about components whose containers request more resources than the allocatable
+// resources of every schedulable node selected by the node selector and the node affinity of the component,
+// the pods of its DaemonSets would never be scheduled. The nodes are not checked if the validator has no client.
+func (w *nicClusterPolicyValidator) getNodeCapacityWarnings(
+	ctx context.Context, in *v1alpha1.NicClusterPolicy) admission.Warnings {
+	if w.client == nil {
+		return nil
+	}
+	var components []componentImageSpec
+	for _, component := range getComponentImageSpecs(&in.Spec) {
+		if len(component.spec.ContainerResources) > 0 {
+			components = append(components, component)
+		}
+	}
+	if len(components) == 0 {
+		return nil
+	}
+	nodes := &v1.NodeList{}
+	if err := w.client.List(ctx, nodes); err != nil {
+		nicClusterPolicyLog.Error(err, "failed to list nodes, skipping the check of the container resources")
+		return nil
+	}
+	var warnings admission.Warnings
+	for _, component := range components {
+		requests := getPodRequests(component.spec.ContainerResources)
+		nodeAffinity := in.Spec.NodeAffinity
+		var nodeSelector map[string]string
+		if podSpec := component.daemonSetSpec; podSpec != nil {
+			if podSpec.NodeAffinity != nil {
+				nodeAffinity = podSpec.NodeAffinity
+			}
+			nodeSelector = podSpec.NodeSelector
+		}
+		selected, fits := false, false
+		for i := range nodes.Items {
+			node := &nodes.Items[i]
+			if node.Spec.Unschedulable || !nodeMatchesSelector(node, in.Spec.NodeSelector) ||
+				!nodeMatchesSelector(node, nodeSelector) || !nodeMatchesAffinity(node, nodeAffinity) {
+				continue
+			}
+			selected = true
+			if requestsFit(requests, node.Status.Allocatable) {
+				fits = true
+				break
+			}
+		}
+		if selected && !fits {
+			warnings = append(warnings, fmt.Sprintf("%s: requests %s exceed the allocatable resources of every "+
+				"schedulable node selected for the component, its pods can't be scheduled",
+				component.path.Child("containerResources"), formatResourceList(requests)))
+		}
+	}
+	return warnings
+}
+
+// getPodRequests returns the sum of the requests of the containers, the limit of a resource is used as its
+// request if the request is not set, as the API server defaults it
+func getPodRequests(resources []v1alpha1.ResourceRequirements) v1.ResourceList {
+	requests := v1.ResourceList{}
+	add := func(name v1.ResourceName, quantity apiresource.Quantity) {
+		sum := requests[name]
+		sum.Add(quantity)
+		requests[name] = sum
+	}
+	for _, container := range resources {
+		for name, quantity := range container.Requests {
+			add(name, quantity)
+		}
+		for name, quantity := range container.Limits {
+			if _, hasRequest := container.Requests[name]; !hasRequest {
+				add(name, quantity)
+			}
+		}
+	}
+	return requests
+}
+
+// requestsFit returns true if the allocatable resources of the node satisfy the requests
+func requestsFit(requests, allocatable v1.ResourceList) bool {
+	for name, quantity := range requests {
+		if capacity, ok := allocatable[name]; !ok || quantity.Cmp(capacity) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeMatchesSelector returns true if the node has all the labels of the node selector
+func nodeMatchesSelector(node *v1.Node, nodeSelector map[string]string) bool {
+	return labels.SelectorFromSet(nodeSelector).Matches(labels.Set(node.Labels))
+}
+
+// nodeMatchesAffinity returns true if the node matches one of the required node selector terms of the affinity,
+// terms which can't be evaluated don't match
+func nodeMatchesAffinity(node *v1.Node, nodeAffinity *v1.NodeAffinity) bool {
+	if nodeAffinity == nil || nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	terms := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	for i := range terms {
+		if nodeMatchesSelectorTerm(node, &terms[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeMatchesSelectorTerm returns true if the node matches all the requirements of the term
+func nodeMatchesSelectorTerm(node *v1.Node, term *v1.NodeSelectorTerm) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false
+	}
+	for _, expressions := range []struct {
+		reqs   []v1.NodeSelectorRequirement
+		values labels.Set
+	}{
+		{term.MatchExpressions, labels.Set(node.Labels)},
+		{term.MatchFields, labels.Set{"metadata.name": node.Name}},
+	} {
+		for _, req := range expressions.reqs {
+			op, ok := nodeSelectorOperators[req.Operator]
+			if !ok {
+				return false
+			}
+			requirement, err := labels.NewRequirement(req.Key, op, req.Values)
+			if err != nil || !requirement.Matches(expressions.values) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// formatResourceList formats the resources as a sorted list of name=quantity
+func formatResourceList(resources v1.ResourceList) string {
+	formatted := make([]string, 0, len(resources))
+	for name, quantity := range resources {
+		formatted = append(formatted, fmt.Sprintf("%s=%s", name, quantity.String()))
+	}
+	slices.Sort(formatted)
+	return strings.Join(formatted, ", ")
+}
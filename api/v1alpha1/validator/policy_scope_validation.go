@@ -0,0 +1,125 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validator
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+// validatePolicyScope checks that the policy can be deployed side by side with the other NicClusterPolicies:
+// the components running cluster-wide controllers are only configured in the consts.NicClusterPolicyResourceName
+// policy and if there are several policies, each of them has a node selector which doesn't overlap with the node
+// selectors of the other policies and the OFED driver, whose upgrades are managed for the whole cluster,
+// is configured in a single policy. The other policies are not checked if the validator has no client.
+func (w *nicClusterPolicyValidator) validatePolicyScope(
+	ctx context.Context, in *v1alpha1.NicClusterPolicy) field.ErrorList {
+	fldPath := field.NewPath("spec").Child("nodeSelector")
+	allErrs := validateNodeSelectorLabels(in.Spec.NodeSelector, fldPath)
+	if in.Name != consts.NicClusterPolicyResourceName {
+		allErrs = append(allErrs, validateClusterWideComponents(&in.Spec)...)
+	}
+	if w.client == nil {
+		return allErrs
+	}
+	policies := &v1alpha1.NicClusterPolicyList{}
+	if err := w.client.List(ctx, policies); err != nil {
+		return append(allErrs, field.InternalError(fldPath, fmt.Errorf("failed to list NicClusterPolicies: %v", err)))
+	}
+	for i := range policies.Items {
+		other := &policies.Items[i]
+		if other.Name == in.Name || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if in.Spec.OFEDDriver != nil && other.Spec.OFEDDriver != nil {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("ofedDriver"),
+				fmt.Sprintf("is already configured in NicClusterPolicy %s", other.Name)))
+		}
+		if len(in.Spec.NodeSelector) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath,
+				fmt.Sprintf("nodeSelector is required when several NicClusterPolicies exist, found %s", other.Name)))
+			continue
+		}
+		if nodeSelectorsOverlap(in.Spec.NodeSelector, other.Spec.NodeSelector) {
+			allErrs = append(allErrs, field.Invalid(fldPath, in.Spec.NodeSelector,
+				fmt.Sprintf("nodeSelector overlaps with the nodeSelector of NicClusterPolicy %s", other.Name)))
+		}
+	}
+	return allErrs
+}
+
+// validateNodeSelectorLabels validates the keys and values of the node selector as labels
+func validateNodeSelectorLabels(nodeSelector map[string]string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for key, value := range nodeSelector {
+		for _, msg := range validation.IsQualifiedName(key) {
+			allErrs = append(allErrs, field.Invalid(fldPath, key, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(value) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(key), value, msg))
+		}
+	}
+	return allErrs
+}
+
+// validateClusterWideComponents rejects the components running cluster-wide controllers, they render objects
+// such as CRDs and webhooks which can't be deployed once per policy. The NodeFeatureRules and the additional
+// manifests are rejected as well, their cluster-scoped objects are looked up by name and can't be renamed per policy.
+func validateClusterWideComponents(spec *v1alpha1.NicClusterPolicySpec) field.ErrorList {
+	var allErrs field.ErrorList
+	forbidden := func(fldPath *field.Path) {
+		allErrs = append(allErrs, field.Forbidden(fldPath,
+			fmt.Sprintf("can only be configured in NicClusterPolicy %s", consts.NicClusterPolicyResourceName)))
+	}
+	specPath := field.NewPath("spec")
+	if spec.IBKubernetes != nil {
+		forbidden(specPath.Child("ibKubernetes"))
+	}
+	if spec.NvIpam != nil {
+		forbidden(specPath.Child("nvIpam"))
+	}
+	if spec.SecondaryNetwork != nil && spec.SecondaryNetwork.IpamPlugin != nil {
+		forbidden(specPath.Child("secondaryNetwork", "ipamPlugin"))
+	}
+	if spec.NodeFeatureDiscovery != nil {
+		forbidden(specPath.Child("nodeFeatureDiscovery"))
+	}
+	if spec.NodeFeatureRules != nil {
+		forbidden(specPath.Child("nodeFeatureRules"))
+	}
+	if spec.AdditionalManifests != nil {
+		forbidden(specPath.Child("additionalManifests"))
+	}
+	return allErrs
+}
+
+// nodeSelectorsOverlap returns true if a node can match both node selectors,
+// i.e. unless the selectors require different values of the same label
+func nodeSelectorsOverlap(a, b map[string]string) bool {
+	for key, value := range a {
+		if other, ok := b[key]; ok && other != value {
+			return false
+		}
+	}
+	return true
+}
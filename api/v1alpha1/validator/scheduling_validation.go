@@ -0,0 +1,315 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validator
+
+import (
+	"fmt"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+var (
+	supportedTolerationOperators = []string{
+		string(v1.TolerationOpExists), string(v1.TolerationOpEqual)}
+	supportedTaintEffects = []string{
+		string(v1.TaintEffectNoSchedule), string(v1.TaintEffectPreferNoSchedule), string(v1.TaintEffectNoExecute)}
+	supportedNodeSelectorOperators = []string{
+		string(v1.NodeSelectorOpIn), string(v1.NodeSelectorOpNotIn), string(v1.NodeSelectorOpExists),
+		string(v1.NodeSelectorOpDoesNotExist), string(v1.NodeSelectorOpGt), string(v1.NodeSelectorOpLt)}
+	supportedNodeFieldSelectorOperators = []string{
+		string(v1.NodeSelectorOpIn), string(v1.NodeSelectorOpNotIn)}
+)
+
+// validateTolerations validates the tolerations the same way the API server validates pod tolerations
+func validateTolerations(tolerations []v1.Toleration, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i := range tolerations {
+		toleration := &tolerations[i]
+		idxPath := fldPath.Index(i)
+		if toleration.Key != "" {
+			for _, msg := range validation.IsQualifiedName(toleration.Key) {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("key"), toleration.Key, msg))
+			}
+		}
+		switch toleration.Operator {
+		case v1.TolerationOpEqual, "":
+			if toleration.Key == "" {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("operator"), toleration.Operator,
+					"operator must be Exists when `key` is empty, which means \"match all values and all keys\""))
+			}
+			for _, msg := range validation.IsValidLabelValue(toleration.Value) {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("value"), toleration.Value, msg))
+			}
+		case v1.TolerationOpExists:
+			if toleration.Value != "" {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("value"), toleration.Value,
+					"value must be empty when `operator` is 'Exists'"))
+			}
+		default:
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("operator"), toleration.Operator,
+				supportedTolerationOperators))
+		}
+		if toleration.Effect != "" && !sets.New(supportedTaintEffects...).Has(string(toleration.Effect)) {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("effect"), toleration.Effect,
+				supportedTaintEffects))
+		}
+		if toleration.TolerationSeconds != nil && toleration.Effect != v1.TaintEffectNoExecute {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("effect"), toleration.Effect,
+				"effect must be 'NoExecute' when `tolerationSeconds` is set"))
+		}
+	}
+	return allErrs
+}
+
+// validateNodeAffinity validates the node affinity and rejects node selector terms that can never match
+func validateNodeAffinity(nodeAffinity *v1.NodeAffinity, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if nodeAffinity == nil {
+		return allErrs
+	}
+	if required := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+		requiredPath := fldPath.Child("requiredDuringSchedulingIgnoredDuringExecution")
+		termsPath := requiredPath.Child("nodeSelectorTerms")
+		if len(required.NodeSelectorTerms) == 0 {
+			allErrs = append(allErrs, field.Required(termsPath, "must have at least one node selector term"))
+		}
+		for i := range required.NodeSelectorTerms {
+			term := &required.NodeSelectorTerms[i]
+			if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+				allErrs = append(allErrs, field.Required(termsPath.Index(i),
+					"must have at least one of matchExpressions or matchFields"))
+				continue
+			}
+			allErrs = append(allErrs, validateNodeSelectorTerm(term, termsPath.Index(i))...)
+		}
+	}
+	preferredPath := fldPath.Child("preferredDuringSchedulingIgnoredDuringExecution")
+	for i := range nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		preferred := &nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution[i]
+		if preferred.Weight < 1 || preferred.Weight > 100 {
+			allErrs = append(allErrs, field.Invalid(preferredPath.Index(i).Child("weight"), preferred.Weight,
+				"must be in the range 1-100"))
+		}
+		allErrs = append(allErrs, validateNodeSelectorTerm(&preferred.Preference,
+			preferredPath.Index(i).Child("preference"))...)
+	}
+	return allErrs
+}
+
+// validateNodeSelectorTerm validates the requirements of the term and that they don't contradict each other
+func validateNodeSelectorTerm(term *v1.NodeSelectorTerm, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i := range term.MatchExpressions {
+		allErrs = append(allErrs, validateNodeSelectorRequirement(
+			&term.MatchExpressions[i], fldPath.Child("matchExpressions").Index(i))...)
+	}
+	for i := range term.MatchFields {
+		allErrs = append(allErrs, validateNodeFieldSelectorRequirement(
+			&term.MatchFields[i], fldPath.Child("matchFields").Index(i))...)
+	}
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+	return append(allErrs, validateNodeSelectorTermSatisfiable(term.MatchExpressions,
+		fldPath.Child("matchExpressions"))...)
+}
+
+func validateNodeSelectorRequirement(req *v1.NodeSelectorRequirement, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for _, msg := range validation.IsQualifiedName(req.Key) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("key"), req.Key, msg))
+	}
+	switch req.Operator {
+	case v1.NodeSelectorOpIn, v1.NodeSelectorOpNotIn:
+		if len(req.Values) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("values"),
+				"must be specified when `operator` is 'In' or 'NotIn'"))
+		}
+	case v1.NodeSelectorOpExists, v1.NodeSelectorOpDoesNotExist:
+		if len(req.Values) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("values"),
+				"may not be specified when `operator` is 'Exists' or 'DoesNotExist'"))
+		}
+	case v1.NodeSelectorOpGt, v1.NodeSelectorOpLt:
+		if len(req.Values) != 1 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("values"),
+				"must be specified single value when `operator` is 'Lt' or 'Gt'"))
+		} else if _, err := strconv.ParseInt(req.Values[0], 10, 64); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("values").Index(0), req.Values[0],
+				"must be an integer when `operator` is 'Lt' or 'Gt'"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("operator"), req.Operator,
+			supportedNodeSelectorOperators))
+	}
+	for i, value := range req.Values {
+		for _, msg := range validation.IsValidLabelValue(value) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("values").Index(i), value, msg))
+		}
+	}
+	return allErrs
+}
+
+func validateNodeFieldSelectorRequirement(req *v1.NodeSelectorRequirement, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if req.Key != "metadata.name" {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("key"), req.Key, []string{"metadata.name"}))
+	}
+	if req.Operator != v1.NodeSelectorOpIn && req.Operator != v1.NodeSelectorOpNotIn {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("operator"), req.Operator,
+			supportedNodeFieldSelectorOperators))
+	}
+	if len(req.Values) != 1 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("values"),
+			"must have exactly one value for field selector"))
+	}
+	return allErrs
+}
+
+// validateNodeSelectorTermSatisfiable rejects match expressions with the same key which can never match together,
+// e.g. conflicting In/NotIn values or Exists and DoesNotExist
+func validateNodeSelectorTermSatisfiable(reqs []v1.NodeSelectorRequirement, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	// possible values of the key according to the In requirements, nil means any value
+	allowed := map[string]sets.Set[string]{}
+	forbidden := map[string]sets.Set[string]{}
+	mustExist := sets.New[string]()
+	mustNotExist := sets.New[string]()
+	for i := range reqs {
+		req := &reqs[i]
+		switch req.Operator {
+		case v1.NodeSelectorOpIn:
+			values := sets.New(req.Values...)
+			if prev, ok := allowed[req.Key]; ok {
+				values = prev.Intersection(values)
+			}
+			allowed[req.Key] = values
+			mustExist.Insert(req.Key)
+		case v1.NodeSelectorOpNotIn:
+			if _, ok := forbidden[req.Key]; !ok {
+				forbidden[req.Key] = sets.New[string]()
+			}
+			forbidden[req.Key].Insert(req.Values...)
+		case v1.NodeSelectorOpExists, v1.NodeSelectorOpGt, v1.NodeSelectorOpLt:
+			mustExist.Insert(req.Key)
+		case v1.NodeSelectorOpDoesNotExist:
+			mustNotExist.Insert(req.Key)
+		}
+	}
+	for _, key := range sets.List(mustExist.Intersection(mustNotExist)) {
+		allErrs = append(allErrs, field.Invalid(fldPath, key,
+			fmt.Sprintf("node selector term can never match, key %s is required to exist and not to exist", key)))
+	}
+	for _, key := range sets.List(sets.KeySet(allowed)) {
+		values := allowed[key].Difference(forbidden[key])
+		if values.Len() == 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath, key,
+				fmt.Sprintf("node selector term can never match, no value of key %s satisfies all requirements", key)))
+		}
+	}
+	return allErrs
+}
+
+// componentImageSpec is the image spec of a component with the path of the component in the NicClusterPolicy
+type componentImageSpec struct {
+	path *field.Path
+	spec *v1alpha1.ImageSpec
+	// daemonSetSpec is the pods and rollout spec of the component, nil if the component isn't deployed by DaemonSets
+	daemonSetSpec *v1alpha1.ComponentDaemonSetSpec
+}
+
+// getComponentImageSpecs returns the image specs of the components configured in the NicClusterPolicy
+func getComponentImageSpecs(spec *v1alpha1.NicClusterPolicySpec) []componentImageSpec {
+	var components []componentImageSpec
+	add := func(fldPath *field.Path, imageSpec *v1alpha1.ImageSpec, daemonSetSpec *v1alpha1.ComponentDaemonSetSpec) {
+		components = append(components, componentImageSpec{path: fldPath, spec: imageSpec, daemonSetSpec: daemonSetSpec})
+	}
+	fp := field.NewPath("spec")
+	if c := spec.OFEDDriver; c != nil {
+		add(fp.Child("ofedDriver"), &c.ImageSpec, &c.ComponentDaemonSetSpec)
+	}
+	if c := spec.RdmaSharedDevicePlugin; c != nil {
+		add(fp.Child("rdmaSharedDevicePlugin"), &c.ImageSpec, &c.ComponentDaemonSetSpec)
+	}
+	if c := spec.SriovDevicePlugin; c != nil {
+		add(fp.Child("sriovDevicePlugin"), &c.ImageSpec, &c.ComponentDaemonSetSpec)
+	}
+	if spec.IBKubernetes != nil {
+		add(fp.Child("ibKubernetes"), &spec.IBKubernetes.ImageSpec, nil)
+	}
+	if c := spec.NvIpam; c != nil {
+		add(fp.Child("nvIpam"), &c.ImageSpec, &c.ComponentDaemonSetSpec)
+	}
+	if c := spec.NicFeatureDiscovery; c != nil {
+		add(fp.Child("nicFeatureDiscovery"), &c.ImageSpec, &c.ComponentDaemonSetSpec)
+	}
+	if c := spec.DOCATelemetryService; c != nil {
+		add(fp.Child("docaTelemetryService"), &c.ImageSpec, &c.ComponentDaemonSetSpec)
+	}
+	if c := spec.NicConfigurationDaemon; c != nil {
+		add(fp.Child("nicConfigurationDaemon"), &c.ImageSpec, &c.ComponentDaemonSetSpec)
+	}
+	if c := spec.NodeFeatureDiscovery; c != nil {
+		add(fp.Child("nodeFeatureDiscovery"), &c.ImageSpec, &c.ComponentDaemonSetSpec)
+	}
+	if spec.SecondaryNetwork != nil {
+		snfp := fp.Child("secondaryNetwork")
+		if c := spec.SecondaryNetwork.Multus; c != nil {
+			add(snfp.Child("multus"), &c.ImageSpec, &c.ComponentDaemonSetSpec)
+		}
+		if c := spec.SecondaryNetwork.CniPlugins; c != nil {
+			add(snfp.Child("cniPlugins"), &c.ImageSpec, &c.ComponentDaemonSetSpec)
+		}
+		if c := spec.SecondaryNetwork.IPoIB; c != nil {
+			add(snfp.Child("ipoib"), &c.ImageSpec, &c.ComponentDaemonSetSpec)
+		}
+		if c := spec.SecondaryNetwork.OVSCni; c != nil {
+			add(snfp.Child("ovsCni"), &c.ImageSpec, &c.ComponentDaemonSetSpec)
+		}
+		if c := spec.SecondaryNetwork.RdmaCni; c != nil {
+			add(snfp.Child("rdmaCni"), &c.ImageSpec, &c.ComponentDaemonSetSpec)
+		}
+		if c := spec.SecondaryNetwork.IpamPlugin; c != nil {
+			add(snfp.Child("ipamPlugin"), &c.ImageSpec, &c.ComponentDaemonSetSpec)
+		}
+	}
+	return components
+}
+
+// validateComponentsScheduling validates the node selector, the node affinity and the tolerations of the components
+func validateComponentsScheduling(spec *v1alpha1.NicClusterPolicySpec) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for _, component := range getComponentImageSpecs(spec) {
+		podSpec := component.daemonSetSpec
+		if podSpec == nil {
+			continue
+		}
+		allErrs = append(allErrs,
+			validateNodeSelectorLabels(podSpec.NodeSelector, component.path.Child("nodeSelector"))...)
+		allErrs = append(allErrs,
+			validateNodeAffinity(podSpec.NodeAffinity, component.path.Child("nodeAffinity"))...)
+		allErrs = append(allErrs,
+			validateTolerations(podSpec.Tolerations, component.path.Child("tolerations"))...)
+	}
+	return allErrs
+}
@@ -21,10 +21,28 @@ limitations under the License.
 package v1alpha1
 
 import (
-	"k8s.io/api/core/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdditionalManifestsSpec) DeepCopyInto(out *AdditionalManifestsSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdditionalManifestsSpec.
+func (in *AdditionalManifestsSpec) DeepCopy() *AdditionalManifestsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AdditionalManifestsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AppliedState) DeepCopyInto(out *AppliedState) {
 	*out = *in
@@ -40,6 +58,144 @@ func (in *AppliedState) DeepCopy() *AppliedState {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArchImageSpec) DeepCopyInto(out *ArchImageSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArchImageSpec.
+func (in *ArchImageSpec) DeepCopy() *ArchImageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArchImageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArchImagesSpec) DeepCopyInto(out *ArchImagesSpec) {
+	*out = *in
+	if in.AMD64 != nil {
+		in, out := &in.AMD64, &out.AMD64
+		*out = new(ArchImageSpec)
+		**out = **in
+	}
+	if in.ARM64 != nil {
+		in, out := &in.ARM64, &out.ARM64
+		*out = new(ArchImageSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArchImagesSpec.
+func (in *ArchImagesSpec) DeepCopy() *ArchImagesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArchImagesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CNIDirectoriesSpec) DeepCopyInto(out *CNIDirectoriesSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNIDirectoriesSpec.
+func (in *CNIDirectoriesSpec) DeepCopy() *CNIDirectoriesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CNIDirectoriesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CNIPluginSpec) DeepCopyInto(out *CNIPluginSpec) {
+	*out = *in
+	in.ImageSpec.DeepCopyInto(&out.ImageSpec)
+	in.ComponentDaemonSetSpec.DeepCopyInto(&out.ComponentDaemonSetSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNIPluginSpec.
+func (in *CNIPluginSpec) DeepCopy() *CNIPluginSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CNIPluginSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanarySpec.
+func (in *CanarySpec) DeepCopy() *CanarySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CanarySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentDaemonSetSpec) DeepCopyInto(out *ComponentDaemonSetSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeAffinity != nil {
+		in, out := &in.NodeAffinity, &out.NodeAffinity
+		*out = new(corev1.NodeAffinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UpdateStrategy != nil {
+		in, out := &in.UpdateStrategy, &out.UpdateStrategy
+		*out = new(appsv1.DaemonSetUpdateStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(ContainerSecurityContextSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentDaemonSetSpec.
+func (in *ComponentDaemonSetSpec) DeepCopy() *ComponentDaemonSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentDaemonSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigMapNameReference) DeepCopyInto(out *ConfigMapNameReference) {
 	*out = *in
@@ -55,6 +211,86 @@ func (in *ConfigMapNameReference) DeepCopy() *ConfigMapNameReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigurationTemplateSpec) DeepCopyInto(out *ConfigurationTemplateSpec) {
+	*out = *in
+	if in.PciRelaxedOrdering != nil {
+		in, out := &in.PciRelaxedOrdering, &out.PciRelaxedOrdering
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigurationTemplateSpec.
+func (in *ConfigurationTemplateSpec) DeepCopy() *ConfigurationTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigurationTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerSecurityContextSpec) DeepCopyInto(out *ContainerSecurityContextSpec) {
+	*out = *in
+	if in.Privileged != nil {
+		in, out := &in.Privileged, &out.Privileged
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SeccompProfile != nil {
+		in, out := &in.SeccompProfile, &out.SeccompProfile
+		*out = new(corev1.SeccompProfile)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SELinuxOptions != nil {
+		in, out := &in.SELinuxOptions, &out.SELinuxOptions
+		*out = new(corev1.SELinuxOptions)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerSecurityContextSpec.
+func (in *ContainerSecurityContextSpec) DeepCopy() *ContainerSecurityContextSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerSecurityContextSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSSpec) DeepCopyInto(out *DNSSpec) {
+	*out = *in
+	if in.Nameservers != nil {
+		in, out := &in.Nameservers, &out.Nameservers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Search != nil {
+		in, out := &in.Search, &out.Search
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSSpec.
+func (in *DNSSpec) DeepCopy() *DNSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DOCATelemetryServiceConfig) DeepCopyInto(out *DOCATelemetryServiceConfig) {
 	*out = *in
@@ -70,15 +306,46 @@ func (in *DOCATelemetryServiceConfig) DeepCopy() *DOCATelemetryServiceConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DOCATelemetryServicePrometheusSpec) DeepCopyInto(out *DOCATelemetryServicePrometheusSpec) {
+	*out = *in
+	if in.IgnoreCounters != nil {
+		in, out := &in.IgnoreCounters, &out.IgnoreCounters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DOCATelemetryServicePrometheusSpec.
+func (in *DOCATelemetryServicePrometheusSpec) DeepCopy() *DOCATelemetryServicePrometheusSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DOCATelemetryServicePrometheusSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DOCATelemetryServiceSpec) DeepCopyInto(out *DOCATelemetryServiceSpec) {
 	*out = *in
 	in.ImageSpec.DeepCopyInto(&out.ImageSpec)
+	in.ComponentDaemonSetSpec.DeepCopyInto(&out.ComponentDaemonSetSpec)
 	if in.Config != nil {
 		in, out := &in.Config, &out.Config
 		*out = new(DOCATelemetryServiceConfig)
 		**out = **in
 	}
+	if in.Providers != nil {
+		in, out := &in.Providers, &out.Providers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Prometheus != nil {
+		in, out := &in.Prometheus, &out.Prometheus
+		*out = new(DOCATelemetryServicePrometheusSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DOCATelemetryServiceSpec.
@@ -95,6 +362,7 @@ func (in *DOCATelemetryServiceSpec) DeepCopy() *DOCATelemetryServiceSpec {
 func (in *DevicePluginSpec) DeepCopyInto(out *DevicePluginSpec) {
 	*out = *in
 	in.ImageSpecWithConfig.DeepCopyInto(&out.ImageSpecWithConfig)
+	in.ComponentDaemonSetSpec.DeepCopyInto(&out.ComponentDaemonSetSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevicePluginSpec.
@@ -107,9 +375,34 @@ func (in *DevicePluginSpec) DeepCopy() *DevicePluginSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainRetryPolicySpec) DeepCopyInto(out *DrainRetryPolicySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainRetryPolicySpec.
+func (in *DrainRetryPolicySpec) DeepCopy() *DrainRetryPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainRetryPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DrainSpec) DeepCopyInto(out *DrainSpec) {
 	*out = *in
+	if in.NamespacePodSelectors != nil {
+		in, out := &in.NamespacePodSelectors, &out.NamespacePodSelectors
+		*out = make([]NamespacePodSelector, len(*in))
+		copy(*out, *in)
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(DrainRetryPolicySpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainSpec.
@@ -125,6 +418,11 @@ func (in *DrainSpec) DeepCopy() *DrainSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DriverUpgradePolicySpec) DeepCopyInto(out *DriverUpgradePolicySpec) {
 	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
 	if in.WaitForCompletion != nil {
 		in, out := &in.WaitForCompletion, &out.WaitForCompletion
 		*out = new(WaitForCompletionSpec)
@@ -133,6 +431,36 @@ func (in *DriverUpgradePolicySpec) DeepCopyInto(out *DriverUpgradePolicySpec) {
 	if in.DrainSpec != nil {
 		in, out := &in.DrainSpec, &out.DrainSpec
 		*out = new(DrainSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodDeletionOnDrainTimeout != nil {
+		in, out := &in.PodDeletionOnDrainTimeout, &out.PodDeletionOnDrainTimeout
+		*out = new(PodDeletionOnDrainTimeoutSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Reboot != nil {
+		in, out := &in.Reboot, &out.Reboot
+		*out = new(RebootSpec)
+		**out = **in
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = new(UpgradeHooksSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeOrder != nil {
+		in, out := &in.NodeOrder, &out.NodeOrder
+		*out = new(NodeOrderSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanarySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int)
 		**out = **in
 	}
 }
@@ -152,7 +480,7 @@ func (in *HostDeviceNetwork) DeepCopyInto(out *HostDeviceNetwork) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -209,6 +537,16 @@ func (in *HostDeviceNetworkList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HostDeviceNetworkSpec) DeepCopyInto(out *HostDeviceNetworkSpec) {
 	*out = *in
+	if in.StaticIPAM != nil {
+		in, out := &in.StaticIPAM, &out.StaticIPAM
+		*out = new(StaticIPAMSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(DNSSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostDeviceNetworkSpec.
@@ -224,6 +562,13 @@ func (in *HostDeviceNetworkSpec) DeepCopy() *HostDeviceNetworkSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HostDeviceNetworkStatus) DeepCopyInto(out *HostDeviceNetworkStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.AppliedStates != nil {
 		in, out := &in.AppliedStates, &out.AppliedStates
 		*out = make([]AppliedState, len(*in))
@@ -241,10 +586,35 @@ func (in *HostDeviceNetworkStatus) DeepCopy() *HostDeviceNetworkStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IBKubernetesGarbageCollectionSpec) DeepCopyInto(out *IBKubernetesGarbageCollectionSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IBKubernetesGarbageCollectionSpec.
+func (in *IBKubernetesGarbageCollectionSpec) DeepCopy() *IBKubernetesGarbageCollectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IBKubernetesGarbageCollectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IBKubernetesSpec) DeepCopyInto(out *IBKubernetesSpec) {
 	*out = *in
 	in.ImageSpec.DeepCopyInto(&out.ImageSpec)
+	if in.UfmTLS != nil {
+		in, out := &in.UfmTLS, &out.UfmTLS
+		*out = new(UFMTLSSpec)
+		**out = **in
+	}
+	if in.GarbageCollection != nil {
+		in, out := &in.GarbageCollection, &out.GarbageCollection
+		*out = new(IBKubernetesGarbageCollectionSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IBKubernetesSpec.
@@ -257,13 +627,48 @@ func (in *IBKubernetesSpec) DeepCopy() *IBKubernetesSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPRangeSpec) DeepCopyInto(out *IPRangeSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPRangeSpec.
+func (in *IPRangeSpec) DeepCopy() *IPRangeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IPRangeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPoIBBondSpec) DeepCopyInto(out *IPoIBBondSpec) {
+	*out = *in
+	if in.Links != nil {
+		in, out := &in.Links, &out.Links
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPoIBBondSpec.
+func (in *IPoIBBondSpec) DeepCopy() *IPoIBBondSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IPoIBBondSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPoIBNetwork) DeepCopyInto(out *IPoIBNetwork) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPoIBNetwork.
@@ -319,11 +724,26 @@ func (in *IPoIBNetworkList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPoIBNetworkSpec) DeepCopyInto(out *IPoIBNetworkSpec) {
 	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPoIBNetworkSpec.
-func (in *IPoIBNetworkSpec) DeepCopy() *IPoIBNetworkSpec {
-	if in == nil {
+	if in.Bond != nil {
+		in, out := &in.Bond, &out.Bond
+		*out = new(IPoIBBondSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StaticIPAM != nil {
+		in, out := &in.StaticIPAM, &out.StaticIPAM
+		*out = new(StaticIPAMSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(DNSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPoIBNetworkSpec.
+func (in *IPoIBNetworkSpec) DeepCopy() *IPoIBNetworkSpec {
+	if in == nil {
 		return nil
 	}
 	out := new(IPoIBNetworkSpec)
@@ -334,6 +754,13 @@ func (in *IPoIBNetworkSpec) DeepCopy() *IPoIBNetworkSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPoIBNetworkStatus) DeepCopyInto(out *IPoIBNetworkStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPoIBNetworkStatus.
@@ -361,6 +788,18 @@ func (in *ImageSpec) DeepCopyInto(out *ImageSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ArchImages != nil {
+		in, out := &in.ArchImages, &out.ArchImages
+		*out = new(ArchImagesSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageSpec.
@@ -394,13 +833,33 @@ func (in *ImageSpecWithConfig) DeepCopy() *ImageSpecWithConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineConfigSpec) DeepCopyInto(out *MachineConfigSpec) {
+	*out = *in
+	if in.KernelModules != nil {
+		in, out := &in.KernelModules, &out.KernelModules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineConfigSpec.
+func (in *MachineConfigSpec) DeepCopy() *MachineConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MacvlanNetwork) DeepCopyInto(out *MacvlanNetwork) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MacvlanNetwork.
@@ -456,6 +915,16 @@ func (in *MacvlanNetworkList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MacvlanNetworkSpec) DeepCopyInto(out *MacvlanNetworkSpec) {
 	*out = *in
+	if in.StaticIPAM != nil {
+		in, out := &in.StaticIPAM, &out.StaticIPAM
+		*out = new(StaticIPAMSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(DNSSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MacvlanNetworkSpec.
@@ -471,6 +940,13 @@ func (in *MacvlanNetworkSpec) DeepCopy() *MacvlanNetworkSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MacvlanNetworkStatus) DeepCopyInto(out *MacvlanNetworkStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MacvlanNetworkStatus.
@@ -487,6 +963,7 @@ func (in *MacvlanNetworkStatus) DeepCopy() *MacvlanNetworkStatus {
 func (in *MultusSpec) DeepCopyInto(out *MultusSpec) {
 	*out = *in
 	in.ImageSpecWithConfig.DeepCopyInto(&out.ImageSpecWithConfig)
+	in.ComponentDaemonSetSpec.DeepCopyInto(&out.ComponentDaemonSetSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultusSpec.
@@ -499,10 +976,33 @@ func (in *MultusSpec) DeepCopy() *MultusSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NICConfigurationDaemonSpec) DeepCopyInto(out *NICConfigurationDaemonSpec) {
+	*out = *in
+	in.ImageSpec.DeepCopyInto(&out.ImageSpec)
+	in.ComponentDaemonSetSpec.DeepCopyInto(&out.ComponentDaemonSetSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NICConfigurationDaemonSpec.
+func (in *NICConfigurationDaemonSpec) DeepCopy() *NICConfigurationDaemonSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NICConfigurationDaemonSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NICFeatureDiscoverySpec) DeepCopyInto(out *NICFeatureDiscoverySpec) {
 	*out = *in
 	in.ImageSpec.DeepCopyInto(&out.ImageSpec)
+	in.ComponentDaemonSetSpec.DeepCopyInto(&out.ComponentDaemonSetSpec)
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]NICFeatureDiscoveryLabel, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NICFeatureDiscoverySpec.
@@ -515,10 +1015,90 @@ func (in *NICFeatureDiscoverySpec) DeepCopy() *NICFeatureDiscoverySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NVIPAMCIDRPoolSpec) DeepCopyInto(out *NVIPAMCIDRPoolSpec) {
+	*out = *in
+	if in.GatewayIndex != nil {
+		in, out := &in.GatewayIndex, &out.GatewayIndex
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Exclusions != nil {
+		in, out := &in.Exclusions, &out.Exclusions
+		*out = make([]NVIPAMExcludeRangeSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(corev1.NodeSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NVIPAMCIDRPoolSpec.
+func (in *NVIPAMCIDRPoolSpec) DeepCopy() *NVIPAMCIDRPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NVIPAMCIDRPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NVIPAMExcludeRangeSpec) DeepCopyInto(out *NVIPAMExcludeRangeSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NVIPAMExcludeRangeSpec.
+func (in *NVIPAMExcludeRangeSpec) DeepCopy() *NVIPAMExcludeRangeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NVIPAMExcludeRangeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NVIPAMPoolSpec) DeepCopyInto(out *NVIPAMPoolSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(corev1.NodeSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NVIPAMPoolSpec.
+func (in *NVIPAMPoolSpec) DeepCopy() *NVIPAMPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NVIPAMPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NVIPAMSpec) DeepCopyInto(out *NVIPAMSpec) {
 	*out = *in
 	in.ImageSpec.DeepCopyInto(&out.ImageSpec)
+	in.ComponentDaemonSetSpec.DeepCopyInto(&out.ComponentDaemonSetSpec)
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make([]NVIPAMPoolSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CIDRPools != nil {
+		in, out := &in.CIDRPools, &out.CIDRPools
+		*out = make([]NVIPAMCIDRPoolSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NVIPAMSpec.
@@ -531,6 +1111,21 @@ func (in *NVIPAMSpec) DeepCopy() *NVIPAMSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacePodSelector) DeepCopyInto(out *NamespacePodSelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacePodSelector.
+func (in *NamespacePodSelector) DeepCopy() *NamespacePodSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacePodSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NicClusterPolicy) DeepCopyInto(out *NicClusterPolicy) {
 	*out = *in
@@ -595,12 +1190,12 @@ func (in *NicClusterPolicySpec) DeepCopyInto(out *NicClusterPolicySpec) {
 	*out = *in
 	if in.NodeAffinity != nil {
 		in, out := &in.NodeAffinity, &out.NodeAffinity
-		*out = new(v1.NodeAffinity)
+		*out = new(corev1.NodeAffinity)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -645,6 +1240,58 @@ func (in *NicClusterPolicySpec) DeepCopyInto(out *NicClusterPolicySpec) {
 		*out = new(DOCATelemetryServiceSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NicConfigurationDaemon != nil {
+		in, out := &in.NicConfigurationDaemon, &out.NicConfigurationDaemon
+		*out = new(NICConfigurationDaemonSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RawPatches != nil {
+		in, out := &in.RawPatches, &out.RawPatches
+		*out = make([]RawPatch, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ProxySpec)
+		**out = **in
+	}
+	if in.MachineConfig != nil {
+		in, out := &in.MachineConfig, &out.MachineConfig
+		*out = new(MachineConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeFeatureRules != nil {
+		in, out := &in.NodeFeatureRules, &out.NodeFeatureRules
+		*out = new(NodeFeatureRulesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeFeatureDiscovery != nil {
+		in, out := &in.NodeFeatureDiscovery, &out.NodeFeatureDiscovery
+		*out = new(NodeFeatureDiscoverySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdditionalManifests != nil {
+		in, out := &in.AdditionalManifests, &out.AdditionalManifests
+		*out = new(AdditionalManifestsSpec)
+		**out = **in
+	}
+	if in.CNIDirectories != nil {
+		in, out := &in.CNIDirectories, &out.CNIDirectories
+		*out = new(CNIDirectoriesSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NicClusterPolicySpec.
@@ -665,6 +1312,30 @@ func (in *NicClusterPolicyStatus) DeepCopyInto(out *NicClusterPolicyStatus) {
 		*out = make([]AppliedState, len(*in))
 		copy(*out, *in)
 	}
+	if in.OFEDDriverUpgrade != nil {
+		in, out := &in.OFEDDriverUpgrade, &out.OFEDDriverUpgrade
+		*out = new(OFEDDriverUpgradeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OFEDDriverRollback != nil {
+		in, out := &in.OFEDDriverRollback, &out.OFEDDriverRollback
+		*out = new(OFEDDriverRollbackStatus)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NodeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NicClusterPolicyStatus.
@@ -678,123 +1349,652 @@ func (in *NicClusterPolicyStatus) DeepCopy() *NicClusterPolicyStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OFEDDriverSpec) DeepCopyInto(out *OFEDDriverSpec) {
+func (in *NicConfigurationNodeStatus) DeepCopyInto(out *NicConfigurationNodeStatus) {
 	*out = *in
-	in.ImageSpec.DeepCopyInto(&out.ImageSpec)
-	if in.StartupProbe != nil {
-		in, out := &in.StartupProbe, &out.StartupProbe
-		*out = new(PodProbeSpec)
-		**out = **in
-	}
-	if in.LivenessProbe != nil {
-		in, out := &in.LivenessProbe, &out.LivenessProbe
-		*out = new(PodProbeSpec)
-		**out = **in
-	}
-	if in.ReadinessProbe != nil {
-		in, out := &in.ReadinessProbe, &out.ReadinessProbe
-		*out = new(PodProbeSpec)
-		**out = **in
-	}
-	if in.Env != nil {
-		in, out := &in.Env, &out.Env
-		*out = make([]v1.EnvVar, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.OfedUpgradePolicy != nil {
-		in, out := &in.OfedUpgradePolicy, &out.OfedUpgradePolicy
-		*out = new(DriverUpgradePolicySpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.CertConfig != nil {
-		in, out := &in.CertConfig, &out.CertConfig
-		*out = new(ConfigMapNameReference)
-		**out = **in
-	}
-	if in.RepoConfig != nil {
-		in, out := &in.RepoConfig, &out.RepoConfig
-		*out = new(ConfigMapNameReference)
-		**out = **in
+	if in.PendingRebootParameters != nil {
+		in, out := &in.PendingRebootParameters, &out.PendingRebootParameters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OFEDDriverSpec.
-func (in *OFEDDriverSpec) DeepCopy() *OFEDDriverSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NicConfigurationNodeStatus.
+func (in *NicConfigurationNodeStatus) DeepCopy() *NicConfigurationNodeStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(OFEDDriverSpec)
+	out := new(NicConfigurationNodeStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PodProbeSpec) DeepCopyInto(out *PodProbeSpec) {
+func (in *NicConfigurationTemplate) DeepCopyInto(out *NicConfigurationTemplate) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodProbeSpec.
-func (in *PodProbeSpec) DeepCopy() *PodProbeSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NicConfigurationTemplate.
+func (in *NicConfigurationTemplate) DeepCopy() *NicConfigurationTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(PodProbeSpec)
+	out := new(NicConfigurationTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NicConfigurationTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceRequirements) DeepCopyInto(out *ResourceRequirements) {
+func (in *NicConfigurationTemplateList) DeepCopyInto(out *NicConfigurationTemplateList) {
 	*out = *in
-	if in.Limits != nil {
-		in, out := &in.Limits, &out.Limits
-		*out = make(v1.ResourceList, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val.DeepCopy()
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NicConfigurationTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Requests != nil {
-		in, out := &in.Requests, &out.Requests
-		*out = make(v1.ResourceList, len(*in))
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NicConfigurationTemplateList.
+func (in *NicConfigurationTemplateList) DeepCopy() *NicConfigurationTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(NicConfigurationTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NicConfigurationTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NicConfigurationTemplateSpec) DeepCopyInto(out *NicConfigurationTemplateSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val.DeepCopy()
+			(*out)[key] = val
 		}
 	}
+	in.Template.DeepCopyInto(&out.Template)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRequirements.
-func (in *ResourceRequirements) DeepCopy() *ResourceRequirements {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NicConfigurationTemplateSpec.
+func (in *NicConfigurationTemplateSpec) DeepCopy() *NicConfigurationTemplateSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceRequirements)
+	out := new(NicConfigurationTemplateSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SecondaryNetworkSpec) DeepCopyInto(out *SecondaryNetworkSpec) {
+func (in *NicConfigurationTemplateStatus) DeepCopyInto(out *NicConfigurationTemplateStatus) {
 	*out = *in
-	if in.Multus != nil {
-		in, out := &in.Multus, &out.Multus
-		*out = new(MultusSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.CniPlugins != nil {
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NicConfigurationNodeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NicConfigurationTemplateStatus.
+func (in *NicConfigurationTemplateStatus) DeepCopy() *NicConfigurationTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NicConfigurationTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NicFirmwareNodeStatus) DeepCopyInto(out *NicFirmwareNodeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NicFirmwareNodeStatus.
+func (in *NicFirmwareNodeStatus) DeepCopy() *NicFirmwareNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NicFirmwareNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NicFirmwarePolicy) DeepCopyInto(out *NicFirmwarePolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NicFirmwarePolicy.
+func (in *NicFirmwarePolicy) DeepCopy() *NicFirmwarePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NicFirmwarePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NicFirmwarePolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NicFirmwarePolicyList) DeepCopyInto(out *NicFirmwarePolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NicFirmwarePolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NicFirmwarePolicyList.
+func (in *NicFirmwarePolicyList) DeepCopy() *NicFirmwarePolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(NicFirmwarePolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NicFirmwarePolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NicFirmwarePolicySpec) DeepCopyInto(out *NicFirmwarePolicySpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.ImageSpec.DeepCopyInto(&out.ImageSpec)
+	if in.DrainSpec != nil {
+		in, out := &in.DrainSpec, &out.DrainSpec
+		*out = new(DrainSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Reboot != nil {
+		in, out := &in.Reboot, &out.Reboot
+		*out = new(RebootSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NicFirmwarePolicySpec.
+func (in *NicFirmwarePolicySpec) DeepCopy() *NicFirmwarePolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NicFirmwarePolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NicFirmwarePolicyStatus) DeepCopyInto(out *NicFirmwarePolicyStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NicFirmwareNodeStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NicFirmwarePolicyStatus.
+func (in *NicFirmwarePolicyStatus) DeepCopy() *NicFirmwarePolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NicFirmwarePolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeDrainStatus) DeepCopyInto(out *NodeDrainStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeDrainStatus.
+func (in *NodeDrainStatus) DeepCopy() *NodeDrainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeDrainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFeatureDiscoverySpec) DeepCopyInto(out *NodeFeatureDiscoverySpec) {
+	*out = *in
+	in.ImageSpec.DeepCopyInto(&out.ImageSpec)
+	in.ComponentDaemonSetSpec.DeepCopyInto(&out.ComponentDaemonSetSpec)
+	if in.ExtraLabelNs != nil {
+		in, out := &in.ExtraLabelNs, &out.ExtraLabelNs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PCIClasses != nil {
+		in, out := &in.PCIClasses, &out.PCIClasses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFeatureDiscoverySpec.
+func (in *NodeFeatureDiscoverySpec) DeepCopy() *NodeFeatureDiscoverySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFeatureDiscoverySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFeatureRulesSpec) DeepCopyInto(out *NodeFeatureRulesSpec) {
+	*out = *in
+	if in.PCIClasses != nil {
+		in, out := &in.PCIClasses, &out.PCIClasses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFeatureRulesSpec.
+func (in *NodeFeatureRulesSpec) DeepCopy() *NodeFeatureRulesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFeatureRulesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeOrderSpec) DeepCopyInto(out *NodeOrderSpec) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeOrderSpec.
+func (in *NodeOrderSpec) DeepCopy() *NodeOrderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeOrderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeStatus) DeepCopyInto(out *NodeStatus) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeStatus.
+func (in *NodeStatus) DeepCopy() *NodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OFEDDriverDrainStatus) DeepCopyInto(out *OFEDDriverDrainStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NodeDrainStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OFEDDriverDrainStatus.
+func (in *OFEDDriverDrainStatus) DeepCopy() *OFEDDriverDrainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OFEDDriverDrainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OFEDDriverRollbackStatus) DeepCopyInto(out *OFEDDriverRollbackStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OFEDDriverRollbackStatus.
+func (in *OFEDDriverRollbackStatus) DeepCopy() *OFEDDriverRollbackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OFEDDriverRollbackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OFEDDriverSpec) DeepCopyInto(out *OFEDDriverSpec) {
+	*out = *in
+	in.ImageSpec.DeepCopyInto(&out.ImageSpec)
+	in.ComponentDaemonSetSpec.DeepCopyInto(&out.ComponentDaemonSetSpec)
+	if in.StartupProbe != nil {
+		in, out := &in.StartupProbe, &out.StartupProbe
+		*out = new(PodProbeSpec)
+		**out = **in
+	}
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(PodProbeSpec)
+		**out = **in
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(PodProbeSpec)
+		**out = **in
+	}
+	if in.OfedUpgradePolicy != nil {
+		in, out := &in.OfedUpgradePolicy, &out.OfedUpgradePolicy
+		*out = new(DriverUpgradePolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertConfig != nil {
+		in, out := &in.CertConfig, &out.CertConfig
+		*out = new(ConfigMapNameReference)
+		**out = **in
+	}
+	if in.RepoConfig != nil {
+		in, out := &in.RepoConfig, &out.RepoConfig
+		*out = new(ConfigMapNameReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OFEDDriverSpec.
+func (in *OFEDDriverSpec) DeepCopy() *OFEDDriverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OFEDDriverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OFEDDriverUpgradeStatus) DeepCopyInto(out *OFEDDriverUpgradeStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.EstimatedCompletionTime != nil {
+		in, out := &in.EstimatedCompletionTime, &out.EstimatedCompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(OFEDDriverDrainStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OFEDDriverUpgradeStatus.
+func (in *OFEDDriverUpgradeStatus) DeepCopy() *OFEDDriverUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OFEDDriverUpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDeletionOnDrainTimeoutSpec) DeepCopyInto(out *PodDeletionOnDrainTimeoutSpec) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeNamespaces != nil {
+		in, out := &in.ExcludeNamespaces, &out.ExcludeNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDeletionOnDrainTimeoutSpec.
+func (in *PodDeletionOnDrainTimeoutSpec) DeepCopy() *PodDeletionOnDrainTimeoutSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDeletionOnDrainTimeoutSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodProbeSpec) DeepCopyInto(out *PodProbeSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodProbeSpec.
+func (in *PodProbeSpec) DeepCopy() *PodProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxySpec) DeepCopyInto(out *ProxySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxySpec.
+func (in *ProxySpec) DeepCopy() *ProxySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RawPatch) DeepCopyInto(out *RawPatch) {
+	*out = *in
+	out.Target = in.Target
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RawPatch.
+func (in *RawPatch) DeepCopy() *RawPatch {
+	if in == nil {
+		return nil
+	}
+	out := new(RawPatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RawPatchTarget) DeepCopyInto(out *RawPatchTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RawPatchTarget.
+func (in *RawPatchTarget) DeepCopy() *RawPatchTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(RawPatchTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RebootSpec) DeepCopyInto(out *RebootSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebootSpec.
+func (in *RebootSpec) DeepCopy() *RebootSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RebootSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceRequirements) DeepCopyInto(out *ResourceRequirements) {
+	*out = *in
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRequirements.
+func (in *ResourceRequirements) DeepCopy() *ResourceRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteSpec) DeepCopyInto(out *RouteSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteSpec.
+func (in *RouteSpec) DeepCopy() *RouteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecondaryNetworkSpec) DeepCopyInto(out *SecondaryNetworkSpec) {
+	*out = *in
+	if in.Multus != nil {
+		in, out := &in.Multus, &out.Multus
+		*out = new(MultusSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CniPlugins != nil {
 		in, out := &in.CniPlugins, &out.CniPlugins
-		*out = new(ImageSpec)
+		*out = new(CNIPluginSpec)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.IPoIB != nil {
 		in, out := &in.IPoIB, &out.IPoIB
-		*out = new(ImageSpec)
+		*out = new(CNIPluginSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OVSCni != nil {
+		in, out := &in.OVSCni, &out.OVSCni
+		*out = new(CNIPluginSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RdmaCni != nil {
+		in, out := &in.RdmaCni, &out.RdmaCni
+		*out = new(CNIPluginSpec)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.IpamPlugin != nil {
 		in, out := &in.IpamPlugin, &out.IpamPlugin
-		*out = new(ImageSpec)
+		*out = new(WhereaboutsSpec)
 		(*in).DeepCopyInto(*out)
 	}
 }
@@ -809,6 +2009,116 @@ func (in *SecondaryNetworkSpec) DeepCopy() *SecondaryNetworkSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticIPAMSpec) DeepCopyInto(out *StaticIPAMSpec) {
+	*out = *in
+	if in.Ranges != nil {
+		in, out := &in.Ranges, &out.Ranges
+		*out = make([]IPRangeSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.Routes != nil {
+		in, out := &in.Routes, &out.Routes
+		*out = make([]RouteSpec, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticIPAMSpec.
+func (in *StaticIPAMSpec) DeepCopy() *StaticIPAMSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticIPAMSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UFMTLSSpec) DeepCopyInto(out *UFMTLSSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UFMTLSSpec.
+func (in *UFMTLSSpec) DeepCopy() *UFMTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UFMTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeHookJobSpec) DeepCopyInto(out *UpgradeHookJobSpec) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeHookJobSpec.
+func (in *UpgradeHookJobSpec) DeepCopy() *UpgradeHookJobSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeHookJobSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeHookSpec) DeepCopyInto(out *UpgradeHookSpec) {
+	*out = *in
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(UpgradeHookJobSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeHookSpec.
+func (in *UpgradeHookSpec) DeepCopy() *UpgradeHookSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeHookSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeHooksSpec) DeepCopyInto(out *UpgradeHooksSpec) {
+	*out = *in
+	if in.PreDrain != nil {
+		in, out := &in.PreDrain, &out.PreDrain
+		*out = new(UpgradeHookSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PostDrain != nil {
+		in, out := &in.PostDrain, &out.PostDrain
+		*out = new(UpgradeHookSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeHooksSpec.
+func (in *UpgradeHooksSpec) DeepCopy() *UpgradeHooksSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeHooksSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WaitForCompletionSpec) DeepCopyInto(out *WaitForCompletionSpec) {
 	*out = *in
@@ -823,3 +2133,20 @@ func (in *WaitForCompletionSpec) DeepCopy() *WaitForCompletionSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WhereaboutsSpec) DeepCopyInto(out *WhereaboutsSpec) {
+	*out = *in
+	in.ImageSpec.DeepCopyInto(&out.ImageSpec)
+	in.ComponentDaemonSetSpec.DeepCopyInto(&out.ComponentDaemonSetSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WhereaboutsSpec.
+func (in *WhereaboutsSpec) DeepCopy() *WhereaboutsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WhereaboutsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
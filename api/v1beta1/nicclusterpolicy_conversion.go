@@ -0,0 +1,111 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+var _ conversion.Convertible = &NicClusterPolicy{}
+
+// ConvertTo converts the NicClusterPolicy to the v1alpha1 NicClusterPolicy, the components of the secondary network
+// are grouped under secondaryNetwork
+func (r *NicClusterPolicy) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.NicClusterPolicy)
+	dst.ObjectMeta = r.ObjectMeta
+	dst.Status = r.Status
+
+	dst.Spec = v1alpha1.NicClusterPolicySpec{
+		NodeAffinity:           r.Spec.NodeAffinity,
+		Tolerations:            r.Spec.Tolerations,
+		OFEDDriver:             r.Spec.OFEDDriver,
+		RdmaSharedDevicePlugin: r.Spec.RdmaSharedDevicePlugin,
+		SriovDevicePlugin:      r.Spec.SriovDevicePlugin,
+		IBKubernetes:           r.Spec.IBKubernetes,
+		NvIpam:                 r.Spec.NvIpam,
+		NicFeatureDiscovery:    r.Spec.NicFeatureDiscovery,
+		DOCATelemetryService:   r.Spec.DOCATelemetryService,
+		NicConfigurationDaemon: r.Spec.NicConfigurationDaemon,
+		RawPatches:             r.Spec.RawPatches,
+		NodeSelector:           r.Spec.NodeSelector,
+		ImagePullSecrets:       r.Spec.ImagePullSecrets,
+		Proxy:                  r.Spec.Proxy,
+		MachineConfig:          r.Spec.MachineConfig,
+		NodeFeatureRules:       r.Spec.NodeFeatureRules,
+		NodeFeatureDiscovery:   r.Spec.NodeFeatureDiscovery,
+		PriorityClassName:      r.Spec.PriorityClassName,
+		AdditionalManifests:    r.Spec.AdditionalManifests,
+		DefaultIPAM:            r.Spec.DefaultIPAM,
+		CNIDirectories:         r.Spec.CNIDirectories,
+	}
+	secondaryNetwork := v1alpha1.SecondaryNetworkSpec{
+		Multus:     r.Spec.Multus,
+		CniPlugins: r.Spec.CniPlugins,
+		IPoIB:      r.Spec.IPoIB,
+		OVSCni:     r.Spec.OVSCni,
+		RdmaCni:    r.Spec.RdmaCni,
+		IpamPlugin: r.Spec.IpamPlugin,
+	}
+	// secondaryNetwork is only set if one of its components is set, an empty secondaryNetwork deploys nothing
+	if secondaryNetwork != (v1alpha1.SecondaryNetworkSpec{}) {
+		dst.Spec.SecondaryNetwork = &secondaryNetwork
+	}
+	return nil
+}
+
+// ConvertFrom converts the v1alpha1 NicClusterPolicy to this version, the components of the secondary network
+// are moved to the top level of the spec
+func (r *NicClusterPolicy) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.NicClusterPolicy)
+	r.ObjectMeta = src.ObjectMeta
+	r.Status = src.Status
+
+	r.Spec = NicClusterPolicySpec{
+		NodeAffinity:           src.Spec.NodeAffinity,
+		Tolerations:            src.Spec.Tolerations,
+		OFEDDriver:             src.Spec.OFEDDriver,
+		RdmaSharedDevicePlugin: src.Spec.RdmaSharedDevicePlugin,
+		SriovDevicePlugin:      src.Spec.SriovDevicePlugin,
+		IBKubernetes:           src.Spec.IBKubernetes,
+		NvIpam:                 src.Spec.NvIpam,
+		NicFeatureDiscovery:    src.Spec.NicFeatureDiscovery,
+		DOCATelemetryService:   src.Spec.DOCATelemetryService,
+		NicConfigurationDaemon: src.Spec.NicConfigurationDaemon,
+		RawPatches:             src.Spec.RawPatches,
+		NodeSelector:           src.Spec.NodeSelector,
+		ImagePullSecrets:       src.Spec.ImagePullSecrets,
+		Proxy:                  src.Spec.Proxy,
+		MachineConfig:          src.Spec.MachineConfig,
+		NodeFeatureRules:       src.Spec.NodeFeatureRules,
+		NodeFeatureDiscovery:   src.Spec.NodeFeatureDiscovery,
+		PriorityClassName:      src.Spec.PriorityClassName,
+		AdditionalManifests:    src.Spec.AdditionalManifests,
+		DefaultIPAM:            src.Spec.DefaultIPAM,
+		CNIDirectories:         src.Spec.CNIDirectories,
+	}
+	if sn := src.Spec.SecondaryNetwork; sn != nil {
+		r.Spec.Multus = sn.Multus
+		r.Spec.CniPlugins = sn.CniPlugins
+		r.Spec.IPoIB = sn.IPoIB
+		r.Spec.OVSCni = sn.OVSCni
+		r.Spec.RdmaCni = sn.RdmaCni
+		r.Spec.IpamPlugin = sn.IpamPlugin
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	fuzz "github.com/google/gofuzz"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+const fuzzIterations = 1000
+
+var _ = Describe("NicClusterPolicy conversion", func() {
+	var fuzzer *fuzz.Fuzzer
+
+	BeforeEach(func() {
+		fuzzer = fuzz.New().NilChance(0.3).NumElements(0, 3)
+	})
+
+	It("should group the secondary network components in the hub", func() {
+		src := &NicClusterPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "nic-cluster-policy"},
+			Spec: NicClusterPolicySpec{
+				Multus:     &v1alpha1.MultusSpec{},
+				CniPlugins: &v1alpha1.CNIPluginSpec{ImageSpec: v1alpha1.ImageSpec{Image: "plugins"}},
+				NvIpam:     &v1alpha1.NVIPAMSpec{EnableWebhook: true},
+			},
+		}
+		hub := &v1alpha1.NicClusterPolicy{}
+		Expect(src.ConvertTo(hub)).To(Succeed())
+		Expect(hub.Name).To(Equal("nic-cluster-policy"))
+		Expect(hub.Spec.NvIpam).To(Equal(src.Spec.NvIpam))
+		Expect(hub.Spec.SecondaryNetwork).To(Equal(&v1alpha1.SecondaryNetworkSpec{
+			Multus: src.Spec.Multus, CniPlugins: src.Spec.CniPlugins}))
+	})
+	It("should not set the secondary network in the hub without its components", func() {
+		hub := &v1alpha1.NicClusterPolicy{}
+		Expect((&NicClusterPolicy{}).ConvertTo(hub)).To(Succeed())
+		Expect(hub.Spec.SecondaryNetwork).To(BeNil())
+	})
+	It("should move the secondary network components to the top level of the spec", func() {
+		hub := &v1alpha1.NicClusterPolicy{Spec: v1alpha1.NicClusterPolicySpec{
+			SecondaryNetwork: &v1alpha1.SecondaryNetworkSpec{
+				IPoIB:   &v1alpha1.CNIPluginSpec{ImageSpec: v1alpha1.ImageSpec{Image: "ipoib-cni"}},
+				RdmaCni: &v1alpha1.CNIPluginSpec{ImageSpec: v1alpha1.ImageSpec{Image: "rdma-cni"}}},
+		}}
+		dst := &NicClusterPolicy{}
+		Expect(dst.ConvertFrom(hub)).To(Succeed())
+		Expect(dst.Spec.IPoIB).To(Equal(hub.Spec.SecondaryNetwork.IPoIB))
+		Expect(dst.Spec.RdmaCni).To(Equal(hub.Spec.SecondaryNetwork.RdmaCni))
+		Expect(dst.Spec.Multus).To(BeNil())
+	})
+	It("should round trip v1beta1 to the hub and back", func() {
+		for i := 0; i < fuzzIterations; i++ {
+			src := &NicClusterPolicy{}
+			fuzzer.Fuzz(src)
+			src.TypeMeta = metav1.TypeMeta{}
+
+			hub := &v1alpha1.NicClusterPolicy{}
+			Expect(src.ConvertTo(hub)).To(Succeed())
+			dst := &NicClusterPolicy{}
+			Expect(dst.ConvertFrom(hub)).To(Succeed())
+			Expect(dst).To(Equal(src))
+		}
+	})
+	It("should round trip the hub to v1beta1 and back", func() {
+		for i := 0; i < fuzzIterations; i++ {
+			src := &v1alpha1.NicClusterPolicy{}
+			fuzzer.Fuzz(src)
+			src.TypeMeta = metav1.TypeMeta{}
+			// an empty secondaryNetwork deploys nothing, it is not kept by the conversion
+			if src.Spec.SecondaryNetwork != nil && *src.Spec.SecondaryNetwork == (v1alpha1.SecondaryNetworkSpec{}) {
+				src.Spec.SecondaryNetwork = nil
+			}
+
+			spoke := &NicClusterPolicy{}
+			Expect(spoke.ConvertFrom(src)).To(Succeed())
+			dst := &v1alpha1.NicClusterPolicy{}
+			Expect(spoke.ConvertTo(dst)).To(Succeed())
+			Expect(dst).To(Equal(src))
+		}
+	})
+})
@@ -0,0 +1,129 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+// NicClusterPolicySpec defines the desired state of NicClusterPolicy
+type NicClusterPolicySpec struct {
+	NodeAffinity           *v1.NodeAffinity                     `json:"nodeAffinity,omitempty"`
+	Tolerations            []v1.Toleration                      `json:"tolerations,omitempty"`
+	OFEDDriver             *v1alpha1.OFEDDriverSpec             `json:"ofedDriver,omitempty"`
+	RdmaSharedDevicePlugin *v1alpha1.DevicePluginSpec           `json:"rdmaSharedDevicePlugin,omitempty"`
+	SriovDevicePlugin      *v1alpha1.DevicePluginSpec           `json:"sriovDevicePlugin,omitempty"`
+	IBKubernetes           *v1alpha1.IBKubernetesSpec           `json:"ibKubernetes,omitempty"`
+	NvIpam                 *v1alpha1.NVIPAMSpec                 `json:"nvIpam,omitempty"`
+	NicFeatureDiscovery    *v1alpha1.NICFeatureDiscoverySpec    `json:"nicFeatureDiscovery,omitempty"`
+	DOCATelemetryService   *v1alpha1.DOCATelemetryServiceSpec   `json:"docaTelemetryService,omitempty"`
+	NicConfigurationDaemon *v1alpha1.NICConfigurationDaemonSpec `json:"nicConfigurationDaemon,omitempty"`
+	// The components of the secondary network are set at the top level of the spec,
+	// they are grouped under secondaryNetwork in v1alpha1
+
+	// Image and configuration information for multus
+	Multus *v1alpha1.MultusSpec `json:"multus,omitempty"`
+	// Image information for CNI plugins
+	CniPlugins *v1alpha1.CNIPluginSpec `json:"cniPlugins,omitempty"`
+	// Image information for IPoIB CNI
+	IPoIB *v1alpha1.CNIPluginSpec `json:"ipoib,omitempty"`
+	// Image information for OVS CNI
+	OVSCni *v1alpha1.CNIPluginSpec `json:"ovsCni,omitempty"`
+	// Image information for RDMA CNI, which moves the RDMA devices to the network namespace of the pod
+	RdmaCni *v1alpha1.CNIPluginSpec `json:"rdmaCni,omitempty"`
+	// Image and configuration information for IPAM plugin
+	IpamPlugin *v1alpha1.WhereaboutsSpec `json:"ipamPlugin,omitempty"`
+	// DefaultIPAM is the IPAM plugin the networks use by default, it must be one of the deployed IPAM plugins
+	// and is required if both ipamPlugin and nvIpam are deployed
+	// +optional
+	DefaultIPAM v1alpha1.IPAMPlugin `json:"defaultIpam,omitempty"`
+	// RawPatches are applied in order to the objects rendered for the NicClusterPolicy before they are
+	// created or updated
+	// +optional
+	RawPatches []v1alpha1.RawPatch `json:"rawPatches,omitempty"`
+	// NodeSelector restricts the DaemonSets of the policy to the nodes with matching labels. Several policies
+	// with non-overlapping node selectors can deploy different components to different nodes, e.g. to the
+	// InfiniBand and to the Ethernet nodes of a cluster.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// ImagePullSecrets added to the image pull secrets of every component, so that a secret of the registry
+	// doesn't have to be repeated in the image spec of each component
+	// +optional
+	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
+	// Proxy settings passed as HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables to the OFED driver
+	// and the DOCA Telemetry Service, on Openshift they take precedence over the cluster-wide Proxy
+	// +optional
+	Proxy *v1alpha1.ProxySpec `json:"proxy,omitempty"`
+	// MachineConfig loads kernel modules on the nodes of an OpenShift cluster, ignored on other clusters.
+	// The SecurityContextConstraints of the components are deployed on OpenShift regardless.
+	// +optional
+	MachineConfig *v1alpha1.MachineConfigSpec `json:"machineConfig,omitempty"`
+	// NodeFeatureRules deploys the NodeFeatureRule objects which detect the Mellanox NICs, instead of relying on
+	// the configuration of the NFD worker. Requires Node Feature Discovery v0.13 or newer.
+	// +optional
+	NodeFeatureRules *v1alpha1.NodeFeatureRulesSpec `json:"nodeFeatureRules,omitempty"`
+	// NodeFeatureDiscovery deploys Node Feature Discovery with the operator, for the installations where it is not
+	// deployed by the Helm chart of the operator, e.g. with OLM. The NFD CRDs are deployed with it.
+	// +optional
+	NodeFeatureDiscovery *v1alpha1.NodeFeatureDiscoverySpec `json:"nodeFeatureDiscovery,omitempty"`
+	// PriorityClassName of the pods of the DaemonSets and Deployments of every component, e.g. system-node-critical
+	// so that the network infrastructure pods are not evicted under node pressure. The priorityClassName of a
+	// component overrides it.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// AdditionalManifests are applied as is, with the policy as their owner, for small resources the components
+	// don't deploy, e.g. NetworkPolicies or PodMonitors. Objects removed from the manifests are deleted.
+	// +optional
+	AdditionalManifests *v1alpha1.AdditionalManifestsSpec `json:"additionalManifests,omitempty"`
+	// CNIDirectories are the locations of the CNI binaries and configurations on the nodes used by the components
+	// installing a CNI plugin, by default they are detected from the cluster type
+	// +optional
+	CNIDirectories *v1alpha1.CNIDirectoriesSpec `json:"cniDirectories,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:object:generate=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.state`,priority=0
+// +kubebuilder:printcolumn:name="Age",type=string,JSONPath=`.metadata.creationTimestamp`,priority=0
+
+// NicClusterPolicy is the Schema for the nicclusterpolicies API
+type NicClusterPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NicClusterPolicySpec            `json:"spec,omitempty"`
+	Status v1alpha1.NicClusterPolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:object:generate=true
+
+// NicClusterPolicyList contains a list of NicClusterPolicy
+type NicClusterPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NicClusterPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NicClusterPolicy{}, &NicClusterPolicyList{})
+}
@@ -0,0 +1,40 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	"github.com/Mellanox/network-operator/pkg/config"
+)
+
+// getStateControllerOptions returns the options of the controllers which reconcile their custom resources
+// with a state manager, i.e. the NicClusterPolicy and the network CRs controllers.
+// The concurrency and the per-item retry delays are configured with the operator ControllerConfig.
+func getStateControllerOptions() controller.Options {
+	cfg := config.FromEnv().Controller
+	return controller.Options{
+		MaxConcurrentReconciles: cfg.MaxConcurrentReconciles,
+		RateLimiter: workqueue.NewMaxOfRateLimiter(
+			workqueue.NewItemExponentialFailureRateLimiter(cfg.RateLimiterBaseDelay, cfg.RateLimiterMaxDelay),
+			// overall rate limit of workqueue.DefaultControllerRateLimiter
+			&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+		),
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -96,7 +97,8 @@ func (r *HostDeviceNetworkReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		}, nil
 	}
 
-	return ctrl.Result{}, nil
+	// periodic resync detects drift of objects even if no watch event was received
+	return ctrl.Result{RequeueAfter: config.FromEnv().Controller.ResyncPeriod}, nil
 }
 
 //nolint:dupl
@@ -120,6 +122,7 @@ NextResult:
 	// Update global State
 	cr.Status.State = mellanoxcomv1alpha1.State(status.Status)
 
+	var readyNetAttachDef *netattdefv1.NetworkAttachmentDefinition
 	if cr.Status.State == state.SyncStateReady {
 		netAttachDef := &netattdefv1.NetworkAttachmentDefinition{}
 		err := r.Get(ctx,
@@ -132,8 +135,13 @@ NextResult:
 			reqLogger.V(consts.LogLevelError).Error(err, "Can not retrieve NetworkAttachmentDefinition object")
 		} else {
 			cr.Status.HostDeviceNetworkAttachmentDef = utils.GetNetworkAttachmentDefLink(netAttachDef)
+			readyNetAttachDef = netAttachDef
 		}
 	}
+	if condErr := setNetworkReadyCondition(ctx, r.Client, &cr.Status.Conditions, cr.Status.State, cr.Status.Reason,
+		readyNetAttachDef, cr.Generation); condErr != nil {
+		reqLogger.V(consts.LogLevelError).Error(condErr, "Failed to set Ready condition")
+	}
 
 	// send status update request to k8s API
 	reqLogger.V(consts.LogLevelInfo).Info(
@@ -150,7 +158,7 @@ NextResult:
 func (r *HostDeviceNetworkReconciler) SetupWithManager(mgr ctrl.Manager, setupLog logr.Logger) error {
 	// Create state manager
 	stateManager, err := state.NewManager(mellanoxcomv1alpha1.HostDeviceNetworkCRDName, mgr.GetClient(),
-		setupLog.WithName("StateManager"))
+		mgr.GetEventRecorderFor("hostdevicenetwork-controller"), setupLog.WithName("StateManager"))
 	if err != nil {
 		// Error creating stateManager
 		setupLog.V(consts.LogLevelError).Error(err, "Error creating state manager.")
@@ -158,18 +166,19 @@ func (r *HostDeviceNetworkReconciler) SetupWithManager(mgr ctrl.Manager, setupLo
 	}
 	r.stateManager = stateManager
 
-	builder := ctrl.NewControllerManagedBy(mgr).
+	ctl := ctrl.NewControllerManagedBy(mgr).
 		For(&mellanoxcomv1alpha1.HostDeviceNetwork{}).
 		// Watch for changes to primary resource HostDeviceNetwork
 		Watches(&mellanoxcomv1alpha1.HostDeviceNetwork{}, &handler.EnqueueRequestForObject{})
 
 	// Watch for changes to secondary resource DaemonSet and requeue the owner HostDeviceNetwork
 	ws := stateManager.GetWatchSources()
-	for kindName := range ws {
+	for kindName, source := range ws {
 		setupLog.V(consts.LogLevelInfo).Info("Watching", "Kind", kindName)
-		builder = builder.Watches(ws[kindName], handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(),
-			&mellanoxcomv1alpha1.HostDeviceNetwork{}, handler.OnlyControllerOwner()))
+		ctl = ctl.Watches(source.Object, handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(),
+			&mellanoxcomv1alpha1.HostDeviceNetwork{}, handler.OnlyControllerOwner()),
+			builder.WithPredicates(source.Predicates...))
 	}
 
-	return builder.Complete(r)
+	return ctl.WithOptions(getStateControllerOptions()).Complete(r)
 }
@@ -27,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -94,7 +95,8 @@ func (r *IPoIBNetworkReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}, nil
 	}
 
-	return ctrl.Result{}, nil
+	// periodic resync detects drift of objects even if no watch event was received
+	return ctrl.Result{RequeueAfter: config.FromEnv().Controller.ResyncPeriod}, nil
 }
 
 func (r *IPoIBNetworkReconciler) updateCrStatus(
@@ -107,6 +109,7 @@ func (r *IPoIBNetworkReconciler) updateCrStatus(
 
 	var err error
 
+	var readyNetAttachDef *netattdefv1.NetworkAttachmentDefinition
 	if cr.Status.State == state.SyncStateReady {
 		netAttachDef := &netattdefv1.NetworkAttachmentDefinition{}
 		getErr := r.Get(ctx,
@@ -120,13 +123,18 @@ func (r *IPoIBNetworkReconciler) updateCrStatus(
 			err = getErr
 		} else {
 			cr.Status.IPoIBNetworkAttachmentDef = utils.GetNetworkAttachmentDefLink(netAttachDef)
+			readyNetAttachDef = netAttachDef
 		}
 	}
+	if condErr := setNetworkReadyCondition(ctx, r.Client, &cr.Status.Conditions, cr.Status.State, cr.Status.Reason,
+		readyNetAttachDef, cr.Generation); condErr != nil {
+		reqLogger.V(consts.LogLevelError).Error(condErr, "Failed to set Ready condition")
+	}
 
 	// send status update request to k8s API
 	reqLogger.V(consts.LogLevelInfo).Info(
 		"Updating status", "Custom resource name", cr.Name, "namespace", cr.Namespace, "Result:", cr.Status)
-	updateErr := r.Status().Update(context.TODO(), cr)
+	updateErr := r.Status().Update(ctx, cr)
 	if updateErr != nil {
 		reqLogger.V(consts.LogLevelError).Error(updateErr, "Failed to update CR status")
 		err = updateErr
@@ -141,7 +149,7 @@ func (r *IPoIBNetworkReconciler) updateCrStatus(
 func (r *IPoIBNetworkReconciler) SetupWithManager(mgr ctrl.Manager, setupLog logr.Logger) error {
 	// Create state manager
 	stateManager, err := state.NewManager(mellanoxcomv1alpha1.IPoIBNetworkCRDName, mgr.GetClient(),
-		setupLog.WithName("StateManager"))
+		mgr.GetEventRecorderFor("ipoibnetwork-controller"), setupLog.WithName("StateManager"))
 	if err != nil {
 		// Error creating stateManager
 		setupLog.V(consts.LogLevelError).Error(err, "Error creating state manager.")
@@ -149,18 +157,19 @@ func (r *IPoIBNetworkReconciler) SetupWithManager(mgr ctrl.Manager, setupLog log
 	}
 	r.stateManager = stateManager
 
-	builder := ctrl.NewControllerManagedBy(mgr).
+	ctl := ctrl.NewControllerManagedBy(mgr).
 		For(&mellanoxcomv1alpha1.IPoIBNetwork{}).
 		// Watch for changes to primary resource IPoIBNetwork
 		Watches(&mellanoxcomv1alpha1.IPoIBNetwork{}, &handler.EnqueueRequestForObject{})
 
 	// Watch for changes to secondary resource DaemonSet and requeue the owner IPoIBNetwork
 	ws := stateManager.GetWatchSources()
-	for kindName := range ws {
+	for kindName, source := range ws {
 		setupLog.V(consts.LogLevelInfo).Info("Watching", "Kind", kindName)
-		builder = builder.Watches(ws[kindName], handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(),
-			&mellanoxcomv1alpha1.IPoIBNetwork{}, handler.OnlyControllerOwner()))
+		ctl = ctl.Watches(source.Object, handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(),
+			&mellanoxcomv1alpha1.IPoIBNetwork{}, handler.OnlyControllerOwner()),
+			builder.WithPredicates(source.Predicates...))
 	}
 
-	return builder.Complete(r)
+	return ctl.WithOptions(getStateControllerOptions()).Complete(r)
 }
@@ -27,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -95,7 +96,8 @@ func (r *MacvlanNetworkReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		}, nil
 	}
 
-	return ctrl.Result{}, nil
+	// periodic resync detects drift of objects even if no watch event was received
+	return ctrl.Result{RequeueAfter: config.FromEnv().Controller.ResyncPeriod}, nil
 }
 
 func (r *MacvlanNetworkReconciler) updateCrStatus(
@@ -106,6 +108,7 @@ func (r *MacvlanNetworkReconciler) updateCrStatus(
 		cr.Status.Reason = status.StatesStatus[0].ErrInfo.Error()
 	}
 
+	var readyNetAttachDef *netattdefv1.NetworkAttachmentDefinition
 	if cr.Status.State == state.SyncStateReady {
 		netAttachDef := &netattdefv1.NetworkAttachmentDefinition{}
 		err := r.Get(ctx,
@@ -118,8 +121,13 @@ func (r *MacvlanNetworkReconciler) updateCrStatus(
 			reqLogger.V(consts.LogLevelError).Error(err, "Can not retrieve NetworkAttachmentDefinition object")
 		} else {
 			cr.Status.MacvlanNetworkAttachmentDef = utils.GetNetworkAttachmentDefLink(netAttachDef)
+			readyNetAttachDef = netAttachDef
 		}
 	}
+	if condErr := setNetworkReadyCondition(ctx, r.Client, &cr.Status.Conditions, cr.Status.State, cr.Status.Reason,
+		readyNetAttachDef, cr.Generation); condErr != nil {
+		reqLogger.V(consts.LogLevelError).Error(condErr, "Failed to set Ready condition")
+	}
 
 	// send status update request to k8s API
 	reqLogger.V(consts.LogLevelInfo).Info(
@@ -136,7 +144,7 @@ func (r *MacvlanNetworkReconciler) updateCrStatus(
 func (r *MacvlanNetworkReconciler) SetupWithManager(mgr ctrl.Manager, setupLog logr.Logger) error {
 	// Create state manager
 	stateManager, err := state.NewManager(mellanoxcomv1alpha1.MacvlanNetworkCRDName, mgr.GetClient(),
-		setupLog.WithName("StateManager"))
+		mgr.GetEventRecorderFor("macvlannetwork-controller"), setupLog.WithName("StateManager"))
 	if err != nil {
 		// Error creating stateManager
 		setupLog.V(consts.LogLevelError).Error(err, "Error creating state manager.")
@@ -144,19 +152,19 @@ func (r *MacvlanNetworkReconciler) SetupWithManager(mgr ctrl.Manager, setupLog l
 	}
 	r.stateManager = stateManager
 
-	builder := ctrl.NewControllerManagedBy(mgr).
+	ctl := ctrl.NewControllerManagedBy(mgr).
 		For(&mellanoxcomv1alpha1.MacvlanNetwork{}).
 		// Watch for changes to primary resource MacvlanNetwork
 		Watches(&mellanoxcomv1alpha1.MacvlanNetwork{}, &handler.EnqueueRequestForObject{})
 
 	// Watch for changes to secondary resource DaemonSet and requeue the owner MacvlanNetwork
 	ws := stateManager.GetWatchSources()
-	for kindName := range ws {
+	for kindName, source := range ws {
 		setupLog.V(consts.LogLevelInfo).Info("Watching", "Kind", kindName)
-		builder = builder.Watches(ws[kindName],
-			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(),
-				&mellanoxcomv1alpha1.MacvlanNetwork{}, handler.OnlyControllerOwner()))
+		ctl = ctl.Watches(source.Object, handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(),
+			&mellanoxcomv1alpha1.MacvlanNetwork{}, handler.OnlyControllerOwner()),
+			builder.WithPredicates(source.Predicates...))
 	}
 
-	return builder.Complete(r)
+	return ctl.WithOptions(getStateControllerOptions()).Complete(r)
 }
@@ -0,0 +1,109 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+// networkResourceNameAnnotation is the annotation of a NetworkAttachmentDefinition with the resource of the network
+const networkResourceNameAnnotation = "k8s.v1.cni.cncf.io/resourceName"
+
+// setNetworkReadyCondition sets the Ready condition of a network CR. The network is ready once its state is ready
+// and its NetworkAttachmentDefinition exists with a valid CNI config, nil if it doesn't exist. If the
+// NetworkAttachmentDefinition has a resource, the resource must also be allocatable on at least one node.
+func setNetworkReadyCondition(ctx context.Context, c client.Reader, conditions *[]metav1.Condition,
+	syncState mellanoxv1alpha1.State, message string, netAttachDef *netattdefv1.NetworkAttachmentDefinition,
+	generation int64) error {
+	if syncState != mellanoxv1alpha1.StateReady {
+		mellanoxv1alpha1.SetStateCondition(conditions, mellanoxv1alpha1.ConditionTypeReady, syncState, message,
+			generation)
+		return nil
+	}
+	cond := metav1.Condition{
+		Type:               mellanoxv1alpha1.ConditionTypeReady,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+	}
+	if netAttachDef == nil {
+		cond.Reason = mellanoxv1alpha1.ConditionReasonNetworkAttachmentDefNotFound
+		cond.Message = "NetworkAttachmentDefinition of the network doesn't exist"
+	} else if err := validateCNIConfig(netAttachDef.Spec.Config); err != nil {
+		cond.Reason = mellanoxv1alpha1.ConditionReasonNetworkAttachmentDefInvalid
+		cond.Message = fmt.Sprintf("NetworkAttachmentDefinition %s/%s has an invalid CNI config: %v",
+			netAttachDef.Namespace, netAttachDef.Name, err)
+	} else if resourceName := netAttachDef.Annotations[networkResourceNameAnnotation]; resourceName != "" {
+		available, err := isResourceAvailable(ctx, c, resourceName)
+		if err != nil {
+			return err
+		}
+		if !available {
+			cond.Reason = mellanoxv1alpha1.ConditionReasonResourceNotAvailable
+			cond.Message = fmt.Sprintf("resource %s is not available on any node", resourceName)
+		}
+	}
+	if cond.Reason == "" {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = mellanoxv1alpha1.ConditionReasonReady
+	}
+	meta.SetStatusCondition(conditions, cond)
+	return nil
+}
+
+// validateCNIConfig returns an error if the config is not a CNI config or a CNI config list
+func validateCNIConfig(config string) error {
+	cniConfig := struct {
+		CNIVersion string            `json:"cniVersion"`
+		Type       string            `json:"type"`
+		Plugins    []json.RawMessage `json:"plugins"`
+	}{}
+	if err := json.Unmarshal([]byte(config), &cniConfig); err != nil {
+		return err
+	}
+	if cniConfig.CNIVersion == "" {
+		return fmt.Errorf("cniVersion is not set")
+	}
+	if cniConfig.Type == "" && len(cniConfig.Plugins) == 0 {
+		return fmt.Errorf("neither type nor plugins are set")
+	}
+	return nil
+}
+
+// isResourceAvailable returns true if the resource is allocatable on at least one node
+func isResourceAvailable(ctx context.Context, c client.Reader, resourceName string) (bool, error) {
+	nodes := &corev1.NodeList{}
+	if err := c.List(ctx, nodes); err != nil {
+		return false, err
+	}
+	for i := range nodes.Items {
+		if quantity, ok := nodes.Items[i].Status.Allocatable[corev1.ResourceName(resourceName)]; ok &&
+			!quantity.IsZero() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,103 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+var _ = Describe("Network Ready condition", func() {
+	const validConfig = `{"cniVersion": "0.3.1", "name": "net", "type": "host-device"}`
+	var conditions []metav1.Condition
+
+	newNetAttachDef := func(config, resourceName string) *netattdefv1.NetworkAttachmentDefinition {
+		nad := &netattdefv1.NetworkAttachmentDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "net", Namespace: "default"},
+			Spec:       netattdefv1.NetworkAttachmentDefinitionSpec{Config: config},
+		}
+		if resourceName != "" {
+			nad.Annotations = map[string]string{networkResourceNameAnnotation: resourceName}
+		}
+		return nad
+	}
+	readyCondition := func(c client.Reader, syncState mellanoxv1alpha1.State,
+		nad *netattdefv1.NetworkAttachmentDefinition) *metav1.Condition {
+		Expect(setNetworkReadyCondition(context.Background(), c, &conditions, syncState, "", nad, 1)).To(Succeed())
+		return meta.FindStatusCondition(conditions, mellanoxv1alpha1.ConditionTypeReady)
+	}
+
+	BeforeEach(func() {
+		conditions = nil
+	})
+
+	It("Should not be ready if the state is not ready", func() {
+		cond := readyCondition(fake.NewClientBuilder().Build(), mellanoxv1alpha1.StateNotReady, nil)
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(mellanoxv1alpha1.ConditionReasonNotReady))
+	})
+
+	It("Should not be ready without NetworkAttachmentDefinition", func() {
+		cond := readyCondition(fake.NewClientBuilder().Build(), mellanoxv1alpha1.StateReady, nil)
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(mellanoxv1alpha1.ConditionReasonNetworkAttachmentDefNotFound))
+	})
+
+	It("Should not be ready with an invalid CNI config", func() {
+		for _, config := range []string{"", `{"type": "host-device"}`, `{"cniVersion": "0.3.1"}`} {
+			cond := readyCondition(fake.NewClientBuilder().Build(), mellanoxv1alpha1.StateReady,
+				newNetAttachDef(config, ""))
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(mellanoxv1alpha1.ConditionReasonNetworkAttachmentDefInvalid))
+		}
+	})
+
+	It("Should be ready with a valid CNI config", func() {
+		cond := readyCondition(fake.NewClientBuilder().Build(), mellanoxv1alpha1.StateReady,
+			newNetAttachDef(validConfig, ""))
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.ObservedGeneration).To(Equal(int64(1)))
+		cond = readyCondition(fake.NewClientBuilder().Build(), mellanoxv1alpha1.StateReady,
+			newNetAttachDef(`{"cniVersion": "0.3.1", "name": "net", "plugins": [{"type": "macvlan"}]}`, ""))
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("Should be ready once the resource is available on a node", func() {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+		c := fake.NewClientBuilder().WithObjects(node).Build()
+		nad := newNetAttachDef(validConfig, "nvidia.com/hostdev")
+		cond := readyCondition(c, mellanoxv1alpha1.StateReady, nad)
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(mellanoxv1alpha1.ConditionReasonResourceNotAvailable))
+
+		node.Status.Allocatable = corev1.ResourceList{"nvidia.com/hostdev": resource.MustParse("2")}
+		Expect(c.Status().Update(context.Background(), node)).To(Succeed())
+		cond = readyCondition(c, mellanoxv1alpha1.StateReady, nad)
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+	})
+})
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -26,12 +27,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -65,24 +68,30 @@ type NicClusterPolicyReconciler struct {
 // +kubebuilder:rbac:groups=mellanox.com,resources=nicclusterpolicies;nicclusterpolicies/status,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=mellanox.com,resources=nicclusterpolicies/finalizers,verbs=update
 // +kubebuilder:rbac:groups=security.openshift.io,resourceNames=privileged,resources=securitycontextconstraints,verbs=use
+// +kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=machineconfiguration.openshift.io,resources=machineconfigs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nfd.k8s-sigs.io,resources=nodefeatures;nodefeaturerules,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings;roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch;update
 // +kubebuilder:rbac:groups="",resources=namespaces;serviceaccounts;pods;pods/status;services;services/finalizers;endpoints,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=persistentvolumeclaims;events;configmaps;secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups="",resources=nodes/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=pods,verbs=list
 // +kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create;delete;get;list;patch;update;watch
 // +kubebuilder:rbac:groups=apps,resources=deployments;daemonsets;replicasets;statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments/finalizers,verbs=update
 // +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=podmonitors,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=whereabouts.cni.cncf.io,resources=ippools,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=whereabouts.cni.cncf.io,resources=overlappingrangeipreservations,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=config.openshift.io,resources=proxies;clusterversions,verbs=get;list;watch
-// +kubebuilder:rbac:groups=nv-ipam.nvidia.com,resources=ippools,verbs=get;list;watch;create;
-// +kubebuilder:rbac:groups=nv-ipam.nvidia.com,resources=ippools/status,verbs=get;update;patch;
+// +kubebuilder:rbac:groups=nv-ipam.nvidia.com,resources=ippools;cidrpools,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nv-ipam.nvidia.com,resources=ippools/status;cidrpools/status,verbs=get;update;patch;
 // +kubebuilder:rbac:groups=cert-manager.io,resources=issuers;certificates,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=image.openshift.io,resources=imagestreams,verbs=get;list;watch
@@ -107,7 +116,7 @@ func (r *NicClusterPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Req
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
-			shouldRequeue, err := r.handleMOFEDWaitLabelsNoConfig(ctx)
+			shouldRequeue, err := r.handleMOFEDWaitLabelsNoConfig(ctx, nil)
 			if err != nil {
 				reqLogger.V(consts.LogLevelError).Error(err, "Fail to clear Mofed label on CR deletion.")
 				return reconcile.Result{}, err
@@ -122,9 +131,19 @@ func (r *NicClusterPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return reconcile.Result{}, err
 	}
 
-	if req.Name != consts.NicClusterPolicyResourceName {
-		err := r.handleUnsupportedInstance(ctx, instance)
-		return reconcile.Result{}, err
+	// the objects of the states are scoped to the policy, several policies with different node selectors
+	// can deploy the same states
+	ctx = state.WithPolicyScope(ctx, state.PolicyScope{Name: instance.Name, NodeSelector: instance.Spec.NodeSelector})
+
+	if !instance.DeletionTimestamp.IsZero() {
+		return r.handleDeletion(ctx, instance)
+	}
+	if !controllerutil.ContainsFinalizer(instance, consts.NicClusterPolicyFinalizer) {
+		controllerutil.AddFinalizer(instance, consts.NicClusterPolicyFinalizer)
+		if err := r.Update(ctx, instance); err != nil {
+			reqLogger.V(consts.LogLevelError).Error(err, "Failed to add finalizer to NicClusterPolicy")
+			return reconcile.Result{}, err
+		}
 	}
 
 	// Create a new State service catalog
@@ -136,7 +155,7 @@ func (r *NicClusterPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		// Create node infoProvider and add to the service catalog
 		reqLogger.V(consts.LogLevelInfo).Info("Creating Node info provider")
 		nodeList := &corev1.NodeList{}
-		err = r.List(ctx, nodeList, nodeinfo.MellanoxNICListOptions...)
+		err = r.List(ctx, nodeList, getPolicyNodesListOptions(instance)...)
 		if err != nil {
 			// Failed to get node list
 			reqLogger.V(consts.LogLevelError).Error(err, "Error occurred on LIST nodes request from API server.")
@@ -151,13 +170,31 @@ func (r *NicClusterPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		reqLogger.V(consts.LogLevelDebug).Info("Node info provider with", "Nodes:", nodeNames)
 		infoProvider := nodeinfo.NewProvider(nodePtrList)
 		sc.Add(state.InfoTypeNodeInfo, infoProvider)
-		r.DocaDriverImagesProvider.SetImageSpec(&instance.Spec.OFEDDriver.ImageSpec)
+		docaImageSpec := instance.Spec.OFEDDriver.ImageSpec.DeepCopy()
+		docaImageSpec.ImagePullSecrets = mellanoxv1alpha1.MergeImagePullSecrets(
+			instance.Spec.ImagePullSecrets, docaImageSpec.ImagePullSecrets)
+		r.DocaDriverImagesProvider.SetImageSpec(docaImageSpec)
 		sc.Add(state.InfoTypeDocaDriverImage, r.DocaDriverImagesProvider)
 	} else {
-		r.DocaDriverImagesProvider.SetImageSpec(nil)
+		// the OFED driver may be configured in another policy
+		policies := &mellanoxv1alpha1.NicClusterPolicyList{}
+		if err := r.List(ctx, policies); err != nil {
+			reqLogger.V(consts.LogLevelError).Error(err, "Error occurred on LIST NicClusterPolicies request from API server.")
+			return reconcile.Result{}, err
+		}
+		if getOFEDDriverSpec(policies.Items) == nil {
+			r.DocaDriverImagesProvider.SetImageSpec(nil)
+		}
+		instance.Status.OFEDDriverVersion = ""
+		instance.Status.OFEDDriverRollback = nil
+		instance.Status.OFEDDriverUpgrade = nil
 	}
 	// Sync state and update status
 	managerStatus := r.stateManager.SyncState(ctx, instance, sc)
+	if err := r.updateNodesStatus(ctx, instance); err != nil {
+		reqLogger.V(consts.LogLevelError).Error(err, "Failed to get nodes status")
+		return reconcile.Result{}, err
+	}
 	r.updateCrStatus(ctx, instance, managerStatus)
 
 	shouldRequeue, err := r.handleMOFEDWaitLabels(ctx, instance)
@@ -169,7 +206,8 @@ func (r *NicClusterPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return r.requeue()
 	}
 
-	return ctrl.Result{}, nil
+	// periodic resync detects drift of objects even if no watch event was received
+	return ctrl.Result{RequeueAfter: config.FromEnv().Controller.ResyncPeriod}, nil
 }
 
 // triggers resync with configured requeue delay
@@ -179,6 +217,31 @@ func (r *NicClusterPolicyReconciler) requeue() (reconcile.Result, error) {
 	}, nil
 }
 
+// handleDeletion tears down the states of the NicClusterPolicy in reverse dependency order
+// and removes the finalizer once all the objects of the states are deleted
+func (r *NicClusterPolicyReconciler) handleDeletion(
+	ctx context.Context, instance *mellanoxv1alpha1.NicClusterPolicy) (reconcile.Result, error) {
+	reqLogger := log.FromContext(ctx)
+	if !controllerutil.ContainsFinalizer(instance, consts.NicClusterPolicyFinalizer) {
+		return reconcile.Result{}, nil
+	}
+	reqLogger.V(consts.LogLevelInfo).Info("Tearing down NicClusterPolicy states")
+	done, err := r.stateManager.Teardown(ctx)
+	if err != nil {
+		reqLogger.V(consts.LogLevelError).Error(err, "Failed to teardown NicClusterPolicy states")
+		return reconcile.Result{}, err
+	}
+	if !done {
+		return r.requeue()
+	}
+	controllerutil.RemoveFinalizer(instance, consts.NicClusterPolicyFinalizer)
+	if err := r.Update(ctx, instance); err != nil {
+		reqLogger.V(consts.LogLevelError).Error(err, "Failed to remove finalizer from NicClusterPolicy")
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
 // handleMOFEDWaitLabels updates nodes labels to mark device plugins should wait for OFED pod
 // Set nvidia.com/ofed.wait=false if OFED is not deployed.
 // returns true if requeue (resync) is required
@@ -187,7 +250,7 @@ func (r *NicClusterPolicyReconciler) handleMOFEDWaitLabels(
 	reqLogger := log.FromContext(ctx)
 	if cr.Spec.OFEDDriver == nil {
 		reqLogger.V(consts.LogLevelDebug).Info("no OFED config in the policy, check OFED wait label on nodes")
-		return r.handleMOFEDWaitLabelsNoConfig(ctx)
+		return r.handleMOFEDWaitLabelsNoConfig(ctx, cr.Spec.NodeSelector)
 	}
 	pods := &corev1.PodList{}
 	_ = r.Client.List(ctx, pods, client.MatchingLabels{"nvidia.com/ofed-driver": ""})
@@ -218,8 +281,10 @@ func (r *NicClusterPolicyReconciler) handleMOFEDWaitLabels(
 // - remove "network.nvidia.com/operator.mofed.wait" which have no NVIDIA NICs anymore
 // - set "network.nvidia.com/operator.mofed.wait" to true if detects OFED Pod
 // on the node (probably in the terminating state).
+// Only the nodes matching the node selector are handled, the other nodes may be handled by another policy.
 // returns true if requeue (resync) is required
-func (r *NicClusterPolicyReconciler) handleMOFEDWaitLabelsNoConfig(ctx context.Context) (bool, error) {
+func (r *NicClusterPolicyReconciler) handleMOFEDWaitLabelsNoConfig(
+	ctx context.Context, nodeSelector map[string]string) (bool, error) {
 	reqLogger := log.FromContext(ctx)
 	nodesWithOFEDContainer := map[string]struct{}{}
 	pods := &corev1.PodList{}
@@ -233,7 +298,7 @@ func (r *NicClusterPolicyReconciler) handleMOFEDWaitLabelsNoConfig(ctx context.C
 		}
 	}
 	nodes := &corev1.NodeList{}
-	if err := r.Client.List(ctx, nodes); err != nil {
+	if err := r.Client.List(ctx, nodes, client.MatchingLabels(nodeSelector)); err != nil {
 		return false, errors.Wrap(err, "failed to list nodes")
 	}
 	for i := range nodes.Items {
@@ -298,16 +363,19 @@ NextResult:
 		for i := range cr.Status.AppliedStates {
 			if cr.Status.AppliedStates[i].Name == stateStatus.StateName {
 				cr.Status.AppliedStates[i].State = mellanoxv1alpha1.State(stateStatus.Status)
+				cr.Status.AppliedStates[i].Message = stateStatus.ReadinessSummary()
 				continue NextResult
 			}
 		}
 		cr.Status.AppliedStates = append(cr.Status.AppliedStates, mellanoxv1alpha1.AppliedState{
-			Name:  stateStatus.StateName,
-			State: mellanoxv1alpha1.State(stateStatus.Status),
+			Name:    stateStatus.StateName,
+			State:   mellanoxv1alpha1.State(stateStatus.Status),
+			Message: stateStatus.ReadinessSummary(),
 		})
 	}
 	// Update global State
 	cr.Status.State = mellanoxv1alpha1.State(status.Status)
+	updateStateConditions(cr, status)
 
 	// send status update request to k8s API
 	reqLogger.V(consts.LogLevelInfo).Info(
@@ -318,23 +386,48 @@ NextResult:
 	}
 }
 
-func (r *NicClusterPolicyReconciler) handleUnsupportedInstance(
-	ctx context.Context, instance *mellanoxv1alpha1.NicClusterPolicy) error {
-	reqLogger := log.FromContext(ctx)
-	reqLogger.V(consts.LogLevelWarning).Info("unsupported NicClusterPolicy instance name")
-	reqLogger.V(consts.LogLevelWarning).Info("NicClusterPolicy supports instance with predefined name",
-		"supported instance name:", consts.NicClusterPolicyResourceName)
-
-	instance.Status.State = mellanoxv1alpha1.StateIgnore
-	instance.Status.Reason = fmt.Sprintf("Unsupported NicClusterPolicy instance %s. Only instance with name %s is"+
-		" supported", instance.Name, consts.NicClusterPolicyResourceName)
-
-	err := r.Status().Update(ctx, instance)
-	if err != nil {
-		reqLogger.V(consts.LogLevelError).Error(err, "Failed to update CR status")
+// updateStateConditions sets a condition per state, the aggregated Ready condition and the Degraded condition
+// in the CR status
+func updateStateConditions(cr *mellanoxv1alpha1.NicClusterPolicy, status state.Results) {
+	notReadyStates := make([]string, 0)
+	driftedObjects := make([]string, 0)
+	for _, stateStatus := range status.StatesStatus {
+		driftedObjects = append(driftedObjects, stateStatus.DriftedObjects...)
+		message := stateStatus.ReadinessSummary()
+		if stateStatus.ErrInfo != nil {
+			message = stateStatus.ErrInfo.Error()
+		}
+		if stateStatus.Status != state.SyncStateReady && stateStatus.Status != state.SyncStateIgnore {
+			if summary := stateStatus.ReadinessSummary(); summary != "" {
+				notReadyStates = append(notReadyStates, fmt.Sprintf("%s (%s)", stateStatus.StateName, summary))
+			} else {
+				notReadyStates = append(notReadyStates, stateStatus.StateName)
+			}
+		}
+		if stateStatus.ErrClass != "" {
+			mellanoxv1alpha1.SetStateErrorCondition(&cr.Status.Conditions, stateStatus.StateName,
+				string(stateStatus.ErrClass), message, cr.Generation)
+		} else {
+			mellanoxv1alpha1.SetStateCondition(&cr.Status.Conditions, stateStatus.StateName,
+				mellanoxv1alpha1.State(stateStatus.Status), message, cr.Generation)
+		}
 	}
-
-	return err
+	message := ""
+	if len(notReadyStates) > 0 {
+		message = fmt.Sprintf("states not ready: %s", strings.Join(notReadyStates, ", "))
+	}
+	// the Ready condition reports the error class as reason if all the failed states failed with the same class
+	if errs := status.ErrorsByClass(); len(errs) == 1 {
+		for class := range errs {
+			mellanoxv1alpha1.SetStateErrorCondition(&cr.Status.Conditions, mellanoxv1alpha1.ConditionTypeReady,
+				string(class), message, cr.Generation)
+		}
+	} else {
+		mellanoxv1alpha1.SetStateCondition(&cr.Status.Conditions, mellanoxv1alpha1.ConditionTypeReady,
+			mellanoxv1alpha1.State(status.Status), message, cr.Generation)
+	}
+	mellanoxv1alpha1.SetDriftCondition(&cr.Status.Conditions, driftedObjects, cr.Generation)
+	mellanoxv1alpha1.SetDegradedCondition(&cr.Status.Conditions, cr.Status.OFEDDriverRollback, cr.Generation)
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -343,7 +436,7 @@ func (r *NicClusterPolicyReconciler) handleUnsupportedInstance(
 func (r *NicClusterPolicyReconciler) SetupWithManager(mgr ctrl.Manager, setupLog logr.Logger) error {
 	// Create state manager
 	stateManager, err := state.NewManager(mellanoxv1alpha1.NicClusterPolicyCRDName, mgr.GetClient(),
-		setupLog.WithName("StateManager"))
+		mgr.GetEventRecorderFor("nicclusterpolicy-controller"), setupLog.WithName("StateManager"))
 	if err != nil {
 		setupLog.V(consts.LogLevelError).Error(err, "Error creating state manager.")
 		return err
@@ -355,13 +448,18 @@ func (r *NicClusterPolicyReconciler) SetupWithManager(mgr ctrl.Manager, setupLog
 		// Watch for changes to primary resource NicClusterPolicy
 		Watches(&mellanoxv1alpha1.NicClusterPolicy{}, &handler.EnqueueRequestForObject{})
 
-	// we always add object with a same(static) key to the queue to reduce
-	// reconciliation count
+	// the policies are added with static keys to the queue to reduce reconciliation count,
+	// all the policies are reconciled as the node may start or stop matching their node selectors
 	updateEnqueue := handler.Funcs{
-		UpdateFunc: func(_ context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
-			q.Add(reconcile.Request{NamespacedName: types.NamespacedName{
-				Name: consts.NicClusterPolicyResourceName,
-			}})
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			policies := &mellanoxv1alpha1.NicClusterPolicyList{}
+			if err := mgr.GetClient().List(ctx, policies); err != nil {
+				setupLog.V(consts.LogLevelError).Error(err, "Failed to list NicClusterPolicies")
+				return
+			}
+			for i := range policies.Items {
+				q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: policies.Items[i].Name}})
+			}
 		},
 	}
 
@@ -371,12 +469,30 @@ func (r *NicClusterPolicyReconciler) SetupWithManager(mgr ctrl.Manager, setupLog
 
 	ws := stateManager.GetWatchSources()
 
-	for kindName := range ws {
+	for kindName, source := range ws {
 		setupLog.V(consts.LogLevelInfo).Info("Watching", "Kind", kindName)
-		ctl = ctl.Watches(ws[kindName], handler.EnqueueRequestForOwner(
+		ctl = ctl.Watches(source.Object, handler.EnqueueRequestForOwner(
 			mgr.GetScheme(), mgr.GetRESTMapper(), &mellanoxv1alpha1.NicClusterPolicy{}, handler.OnlyControllerOwner()),
-			builder.WithPredicates(IgnoreSameContentPredicate{}))
+			builder.WithPredicates(append(source.Predicates, IgnoreSameContentPredicate{})...))
 	}
 
-	return ctl.Complete(r)
+	return ctl.WithOptions(getStateControllerOptions()).Complete(r)
+}
+
+// getPolicyNodesListOptions returns the options to list the nodes with NVIDIA NICs matching the node selector
+// of the policy
+func getPolicyNodesListOptions(cr *mellanoxv1alpha1.NicClusterPolicy) []client.ListOption {
+	return []client.ListOption{client.MatchingLabels(labels.Merge(cr.Spec.NodeSelector,
+		labels.Set{nodeinfo.NodeLabelMlnxNIC: "true"}))}
+}
+
+// getOFEDDriverSpec returns the OFED driver spec of the policies, the validation webhook ensures that
+// the OFED driver is configured in a single policy. Returns nil if no policy configures the OFED driver.
+func getOFEDDriverSpec(policies []mellanoxv1alpha1.NicClusterPolicy) *mellanoxv1alpha1.OFEDDriverSpec {
+	for i := range policies {
+		if policies[i].Spec.OFEDDriver != nil {
+			return policies[i].Spec.OFEDDriver
+		}
+	}
+	return nil
 }
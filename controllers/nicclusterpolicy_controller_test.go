@@ -46,11 +46,13 @@ var _ = Describe("NicClusterPolicyReconciler Controller", func() {
 				},
 				Spec: mellanoxv1alpha1.NicClusterPolicySpec{
 					SecondaryNetwork: &mellanoxv1alpha1.SecondaryNetworkSpec{
-						IpamPlugin: &mellanoxv1alpha1.ImageSpec{
-							Image:            "whereabouts",
-							Repository:       "ghcr.io/k8snetworkplumbingwg",
-							Version:          "v0.5.4-amd64",
-							ImagePullSecrets: []string{},
+						IpamPlugin: &mellanoxv1alpha1.WhereaboutsSpec{
+							ImageSpec: mellanoxv1alpha1.ImageSpec{
+								Image:            "whereabouts",
+								Repository:       "ghcr.io/k8snetworkplumbingwg",
+								Version:          "v0.5.4-amd64",
+								ImagePullSecrets: []string{},
+							},
 						},
 					},
 				},
@@ -114,28 +116,51 @@ var _ = Describe("NicClusterPolicyReconciler Controller", func() {
 				return apierrors.IsNotFound(err)
 			}, timeout*3, interval).Should(BeTrue())
 
+			By("Check NicClusterPolicy has the teardown finalizer")
+			Eventually(func() []string {
+				found := &mellanoxv1alpha1.NicClusterPolicy{}
+				err = k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()}, found)
+				Expect(err).NotTo(HaveOccurred())
+				return found.Finalizers
+			}, timeout*3, interval).Should(ContainElement(consts.NicClusterPolicyFinalizer))
+
 			By("Delete NicClusterPolicy")
 			err = k8sClient.Delete(context.TODO(), &cr)
 			Expect(err).NotTo(HaveOccurred())
+
+			By("Check NicClusterPolicy is removed after teardown")
+			Eventually(func() bool {
+				found := &mellanoxv1alpha1.NicClusterPolicy{}
+				err = k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()}, found)
+				return apierrors.IsNotFound(err)
+			}, timeout*3, interval).Should(BeTrue())
 		})
-		It("Unsupported name", func() {
+		It("Additional policy with node selector", func() {
 			cr := mellanoxv1alpha1.NicClusterPolicy{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "test",
 					Namespace: "",
 				},
+				Spec: mellanoxv1alpha1.NicClusterPolicySpec{
+					NodeSelector: map[string]string{"network.nvidia.com/type": "ib"},
+				},
 			}
 			err := k8sClient.Create(context.TODO(), &cr)
 			Expect(err).NotTo(HaveOccurred())
-			Eventually(func() string {
+			Eventually(func() []string {
 				found := &mellanoxv1alpha1.NicClusterPolicy{}
 				err = k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()}, found)
 				Expect(err).NotTo(HaveOccurred())
-				return string(found.Status.State)
-			}, timeout*3, interval).Should(BeEquivalentTo(mellanoxv1alpha1.StateIgnore))
+				return found.Finalizers
+			}, timeout*3, interval).Should(ContainElement(consts.NicClusterPolicyFinalizer))
 
 			err = k8sClient.Delete(context.TODO(), &cr)
 			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() bool {
+				found := &mellanoxv1alpha1.NicClusterPolicy{}
+				err = k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()}, found)
+				return apierrors.IsNotFound(err)
+			}, timeout*3, interval).Should(BeTrue())
 		})
 	})
 	Context("When NicClusterPolicy CR is deleted", func() {
@@ -0,0 +1,145 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/consts"
+	"github.com/Mellanox/network-operator/pkg/nodeinfo"
+)
+
+const (
+	// default resource prefixes of the device plugins
+	sriovDpDefaultResourcePrefix = "nvidia.com"
+	rdmaDpDefaultResourcePrefix  = "rdma"
+)
+
+// devicePluginResource is a resource in the config of the SR-IOV or RDMA shared device plugin
+type devicePluginResource struct {
+	ResourceName   string `json:"resourceName"`
+	ResourcePrefix string `json:"resourcePrefix,omitempty"`
+}
+
+// updateNodesStatus sets the per-node status of the NicClusterPolicy for the nodes with NVIDIA NICs
+// matching the node selector of the policy
+func (r *NicClusterPolicyReconciler) updateNodesStatus(
+	ctx context.Context, cr *mellanoxv1alpha1.NicClusterPolicy) error {
+	nodes := &corev1.NodeList{}
+	if err := r.List(ctx, nodes, getPolicyNodesListOptions(cr)...); err != nil {
+		return errors.Wrap(err, "failed to list nodes")
+	}
+	pods := &corev1.PodList{}
+	if cr.Spec.OFEDDriver != nil {
+		if err := r.List(ctx, pods, client.MatchingLabels{consts.OfedDriverLabel: ""}); err != nil {
+			return errors.Wrap(err, "failed to list pods")
+		}
+	}
+	cr.Status.Nodes = getNodesStatus(nodes.Items, pods.Items, getDevicePluginResourceNames(&cr.Spec))
+	return nil
+}
+
+// getNodesStatus returns the status of the given nodes sorted by name
+func getNodesStatus(nodes []corev1.Node, ofedPods []corev1.Pod, resourceNames []string) []mellanoxv1alpha1.NodeStatus {
+	nodesStatus := make([]mellanoxv1alpha1.NodeStatus, 0, len(nodes))
+	for i := range nodes {
+		node := &nodes[i]
+		nodeStatus := mellanoxv1alpha1.NodeStatus{Name: node.Name}
+		// a terminating OFED pod can co-exist with the new one during an upgrade, the new one is reported
+		for j := range ofedPods {
+			pod := &ofedPods[j]
+			if pod.Spec.NodeName != node.Name || pod.DeletionTimestamp != nil || len(pod.Spec.Containers) == 0 {
+				continue
+			}
+			nodeStatus.OFEDDriverVersion = getOFEDDriverVersion(pod.Spec.Containers[0].Image, node)
+			// OFED pod contains only one container, see handleMOFEDWaitLabels
+			nodeStatus.OFEDDriverLoaded = len(pod.Status.ContainerStatuses) != 0 && pod.Status.ContainerStatuses[0].Ready
+		}
+		for _, resourceName := range resourceNames {
+			if quantity, ok := node.Status.Allocatable[corev1.ResourceName(resourceName)]; ok && !quantity.IsZero() {
+				nodeStatus.Resources = append(nodeStatus.Resources, resourceName)
+			}
+		}
+		nodesStatus = append(nodesStatus, nodeStatus)
+	}
+	sort.Slice(nodesStatus, func(i, j int) bool {
+		return nodesStatus[i].Name < nodesStatus[j].Name
+	})
+	return nodesStatus
+}
+
+// getOFEDDriverVersion returns the driver version from the tag of the OFED driver image running on the node.
+// The tag of the image is <version>-<os><os version>-<arch> or <version>-<kernel>-<os><os version>-<arch>
+// for precompiled images, the tag is returned as is if it doesn't match the node.
+func getOFEDDriverVersion(image string, node *corev1.Node) string {
+	image, _, _ = strings.Cut(image, "@")
+	tag := image[strings.LastIndex(image, "/")+1:]
+	_, tag, found := strings.Cut(tag, ":")
+	if !found {
+		return ""
+	}
+	end := len(tag)
+	for _, suffix := range []string{
+		"-" + node.Labels[nodeinfo.NodeLabelKernelVerFull] + "-",
+		"-" + node.Labels[nodeinfo.NodeLabelOSName] + node.Labels[nodeinfo.NodeLabelOSVer] + "-",
+	} {
+		if idx := strings.Index(tag, suffix); idx > 0 && idx < end && strings.Trim(suffix, "-") != "" {
+			end = idx
+		}
+	}
+	return tag[:end]
+}
+
+// getDevicePluginResourceNames returns the names of the resources configured for the device plugins,
+// invalid configs are ignored as they are rejected by the validation webhook
+func getDevicePluginResourceNames(spec *mellanoxv1alpha1.NicClusterPolicySpec) []string {
+	resourceNames := make([]string, 0)
+	addResources := func(resources []devicePluginResource, defaultPrefix string) {
+		for _, resource := range resources {
+			prefix := resource.ResourcePrefix
+			if prefix == "" {
+				prefix = defaultPrefix
+			}
+			resourceNames = append(resourceNames, prefix+"/"+resource.ResourceName)
+		}
+	}
+	if spec.SriovDevicePlugin != nil && spec.SriovDevicePlugin.Config != nil {
+		config := struct {
+			ResourceList []devicePluginResource `json:"resourceList"`
+		}{}
+		if err := json.Unmarshal([]byte(*spec.SriovDevicePlugin.Config), &config); err == nil {
+			addResources(config.ResourceList, sriovDpDefaultResourcePrefix)
+		}
+	}
+	if spec.RdmaSharedDevicePlugin != nil && spec.RdmaSharedDevicePlugin.Config != nil {
+		config := struct {
+			ConfigList []devicePluginResource `json:"configList"`
+		}{}
+		if err := json.Unmarshal([]byte(*spec.RdmaSharedDevicePlugin.Config), &config); err == nil {
+			addResources(config.ConfigList, rdmaDpDefaultResourcePrefix)
+		}
+	}
+	return resourceNames
+}
@@ -0,0 +1,92 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/nodeinfo"
+)
+
+var _ = Describe("NicClusterPolicy nodes status", func() {
+	newNode := func(name string) corev1.Node {
+		return corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{
+			nodeinfo.NodeLabelOSName:        "rhel",
+			nodeinfo.NodeLabelOSVer:         "9.2",
+			nodeinfo.NodeLabelKernelVerFull: "5.14.0-284.32.1.el9_2.x86_64",
+		}}}
+	}
+	newOFEDPod := func(node, image string, ready bool) corev1.Pod {
+		return corev1.Pod{
+			Spec:   corev1.PodSpec{NodeName: node, Containers: []corev1.Container{{Image: image}}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Ready: ready}}},
+		}
+	}
+
+	It("should get the OFED driver version from the image tag", func() {
+		node := newNode("node1")
+		Expect(getOFEDDriverVersion("nvcr.io/nvidia/mellanox/doca-driver:24.01-0.3.3.1-0-rhel9.2-amd64", &node)).
+			To(Equal("24.01-0.3.3.1-0"))
+		Expect(getOFEDDriverVersion(
+			"registry:5000/doca-driver:24.01-0.3.3.1-0-5.14.0-284.32.1.el9_2.x86_64-rhel9.2-amd64", &node)).
+			To(Equal("24.01-0.3.3.1-0"))
+		Expect(getOFEDDriverVersion("registry:5000/doca-driver:custom", &node)).To(Equal("custom"))
+		Expect(getOFEDDriverVersion("registry:5000/doca-driver", &node)).To(BeEmpty())
+	})
+
+	It("should get the resource names of the device plugins", func() {
+		sriovConfig := `{"resourceList": [{"resourceName": "hostdev"}, {"resourcePrefix": "example.com",
+			"resourceName": "sriov"}]}`
+		rdmaConfig := `{"configList": [{"resourceName": "rdma_shared_device_a"}]}`
+		spec := &mellanoxv1alpha1.NicClusterPolicySpec{
+			SriovDevicePlugin: &mellanoxv1alpha1.DevicePluginSpec{
+				ImageSpecWithConfig: mellanoxv1alpha1.ImageSpecWithConfig{Config: &sriovConfig}},
+			RdmaSharedDevicePlugin: &mellanoxv1alpha1.DevicePluginSpec{
+				ImageSpecWithConfig: mellanoxv1alpha1.ImageSpecWithConfig{Config: &rdmaConfig}},
+		}
+		Expect(getDevicePluginResourceNames(spec)).To(Equal([]string{
+			"nvidia.com/hostdev", "example.com/sriov", "rdma/rdma_shared_device_a"}))
+	})
+
+	It("should report the OFED driver and the advertised resources per node", func() {
+		node1 := newNode("node1")
+		node1.Status.Allocatable = corev1.ResourceList{
+			"rdma/rdma_shared_device_a": resource.MustParse("63"),
+			"nvidia.com/hostdev":        resource.MustParse("0"),
+		}
+		node2 := newNode("node2")
+		oldPod := newOFEDPod("node2", "repository/doca-driver:23.10-0.5.5.0-0-rhel9.2-amd64", true)
+		oldPod.DeletionTimestamp = &metav1.Time{}
+		pods := []corev1.Pod{
+			newOFEDPod("node1", "repository/doca-driver:24.01-0.3.3.1-0-rhel9.2-amd64", true),
+			oldPod,
+			newOFEDPod("node2", "repository/doca-driver:24.01-0.3.3.1-0-rhel9.2-amd64", false),
+		}
+		status := getNodesStatus([]corev1.Node{node2, node1}, pods,
+			[]string{"rdma/rdma_shared_device_a", "nvidia.com/hostdev"})
+		Expect(status).To(Equal([]mellanoxv1alpha1.NodeStatus{
+			{Name: "node1", OFEDDriverLoaded: true, OFEDDriverVersion: "24.01-0.3.3.1-0",
+				Resources: []string{"rdma/rdma_shared_device_a"}},
+			{Name: "node2", OFEDDriverVersion: "24.01-0.3.3.1-0"},
+		}))
+	})
+})
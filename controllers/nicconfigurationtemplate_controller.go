@@ -0,0 +1,207 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/config"
+	"github.com/Mellanox/network-operator/pkg/consts"
+	"github.com/Mellanox/network-operator/pkg/nicconfig"
+	"github.com/Mellanox/network-operator/pkg/nodeinfo"
+)
+
+// NicConfigurationTemplateReconciler reconciles a NicConfigurationTemplate object
+type NicConfigurationTemplateReconciler struct {
+	client.Client
+	Scheme      *runtime.Scheme
+	MigrationCh chan struct{}
+}
+
+//nolint:lll
+// +kubebuilder:rbac:groups=mellanox.com,resources=nicconfigurationtemplates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=mellanox.com,resources=nicconfigurationtemplates/finalizers,verbs=update
+// +kubebuilder:rbac:groups=mellanox.com,resources=nicconfigurationtemplates/status,verbs=get;update;patch
+
+// Reconcile writes the configuration of the NicConfigurationTemplate to the nodes it selects and reports
+// the configuration state of the nodes reported by the NIC configuration daemon
+func (r *NicConfigurationTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// Wait for migration flow to finish
+	select {
+	case <-r.MigrationCh:
+	case <-ctx.Done():
+		return ctrl.Result{}, fmt.Errorf("canceled")
+	}
+	reqLogger := log.FromContext(ctx)
+	reqLogger.Info("Reconciling NicConfigurationTemplate")
+
+	instance := &mellanoxv1alpha1.NicConfigurationTemplate{}
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// the template was deleted, release the nodes it configured
+			return reconcile.Result{}, r.releaseNodes(ctx, req.Name, nil)
+		}
+		return reconcile.Result{}, err
+	}
+
+	instance.Status.Nodes, err = r.configureNodes(ctx, instance)
+	instance.Status.State = mellanoxv1alpha1.StateReady
+	instance.Status.Reason = ""
+	if err != nil {
+		reqLogger.V(consts.LogLevelError).Error(err, "Failed to configure nodes")
+		instance.Status.State = mellanoxv1alpha1.StateError
+		instance.Status.Reason = err.Error()
+	}
+	for _, nodeStatus := range instance.Status.Nodes {
+		if nodeStatus.State == mellanoxv1alpha1.NicConfigurationStateError {
+			instance.Status.State = mellanoxv1alpha1.StateError
+			break
+		}
+		if nodeStatus.State != mellanoxv1alpha1.NicConfigurationStateApplied {
+			instance.Status.State = mellanoxv1alpha1.StateNotReady
+		}
+	}
+
+	reqLogger.V(consts.LogLevelInfo).Info("Updating status", "Custom resource name", instance.Name,
+		"Result:", instance.Status.State)
+	if updateErr := r.Status().Update(ctx, instance); updateErr != nil {
+		reqLogger.V(consts.LogLevelError).Error(updateErr, "Failed to update CR status")
+		return reconcile.Result{}, updateErr
+	}
+
+	if instance.Status.State != mellanoxv1alpha1.StateReady {
+		return reconcile.Result{
+			RequeueAfter: time.Duration(config.FromEnv().Controller.RequeueTimeSeconds) * time.Second,
+		}, nil
+	}
+	return ctrl.Result{RequeueAfter: config.FromEnv().Controller.ResyncPeriod}, nil
+}
+
+// configureNodes writes the desired configuration to the nodes selected by the template
+// and returns their configuration state, the nodes which are no longer selected are released
+func (r *NicConfigurationTemplateReconciler) configureNodes(ctx context.Context,
+	template *mellanoxv1alpha1.NicConfigurationTemplate) ([]mellanoxv1alpha1.NicConfigurationNodeStatus, error) {
+	selector := client.MatchingLabels{nodeinfo.NodeLabelMlnxNIC: "true"}
+	for key, value := range template.Spec.NodeSelector {
+		selector[key] = value
+	}
+	nodeList := &corev1.NodeList{}
+	if err := r.List(ctx, nodeList, selector); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %v", err)
+	}
+
+	desired := nicconfig.GetNodeConfiguration(template)
+	desiredValue, err := json.Marshal(desired)
+	if err != nil {
+		return nil, err
+	}
+	selected := map[string]bool{}
+	statuses := make([]mellanoxv1alpha1.NicConfigurationNodeStatus, 0, len(nodeList.Items))
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		selected[node.Name] = true
+		owner := node.Labels[nicconfig.TemplateLabelKey]
+		if owner != "" && owner != template.Name {
+			statuses = append(statuses, mellanoxv1alpha1.NicConfigurationNodeStatus{
+				Name:   node.Name,
+				State:  mellanoxv1alpha1.NicConfigurationStateError,
+				Reason: fmt.Sprintf("node is configured by NicConfigurationTemplate %s", owner),
+			})
+			continue
+		}
+		if owner != template.Name || node.Annotations[nicconfig.DesiredConfigurationAnnotationKey] != string(desiredValue) {
+			patch := client.MergeFrom(node.DeepCopy())
+			if node.Annotations == nil {
+				node.Annotations = map[string]string{}
+			}
+			node.Labels[nicconfig.TemplateLabelKey] = template.Name
+			node.Annotations[nicconfig.DesiredConfigurationAnnotationKey] = string(desiredValue)
+			if err := r.Patch(ctx, node, patch); err != nil {
+				return nil, fmt.Errorf("failed to configure node %s: %v", node.Name, err)
+			}
+		}
+		statuses = append(statuses, nicconfig.GetNodeStatus(node, desired))
+	}
+	return statuses, r.releaseNodes(ctx, template.Name, selected)
+}
+
+// releaseNodes removes the configuration of the template from the nodes which are not selected by it anymore
+func (r *NicConfigurationTemplateReconciler) releaseNodes(
+	ctx context.Context, templateName string, selected map[string]bool) error {
+	nodeList := &corev1.NodeList{}
+	if err := r.List(ctx, nodeList, client.MatchingLabels{nicconfig.TemplateLabelKey: templateName}); err != nil {
+		return fmt.Errorf("failed to list nodes: %v", err)
+	}
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		if selected[node.Name] {
+			continue
+		}
+		log.FromContext(ctx).V(consts.LogLevelInfo).Info("Releasing node", "node", node.Name)
+		patch := client.MergeFrom(node.DeepCopy())
+		delete(node.Labels, nicconfig.TemplateLabelKey)
+		delete(node.Annotations, nicconfig.DesiredConfigurationAnnotationKey)
+		if err := r.Patch(ctx, node, patch); err != nil {
+			return fmt.Errorf("failed to release node %s: %v", node.Name, err)
+		}
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NicConfigurationTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// requeue all templates on node changes, the nodes selected by a template are resolved during the reconcile
+	enqueueTemplates := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, _ client.Object) []reconcile.Request {
+		templates := &mellanoxv1alpha1.NicConfigurationTemplateList{}
+		if err := r.List(ctx, templates); err != nil {
+			log.FromContext(ctx).V(consts.LogLevelError).Error(err, "Failed to list NicConfigurationTemplates")
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(templates.Items))
+		for _, template := range templates.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: template.Name}})
+		}
+		return requests
+	})
+
+	// react only on label and annotation changes
+	nodePredicates := builder.WithPredicates(
+		predicate.Or(predicate.AnnotationChangedPredicate{},
+			predicate.LabelChangedPredicate{}))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&mellanoxv1alpha1.NicConfigurationTemplate{}).
+		Watches(&corev1.Node{}, enqueueTemplates, nodePredicates).
+		Complete(r)
+}
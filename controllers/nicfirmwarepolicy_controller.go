@@ -0,0 +1,156 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/config"
+	"github.com/Mellanox/network-operator/pkg/consts"
+	"github.com/Mellanox/network-operator/pkg/firmware"
+	"github.com/Mellanox/network-operator/pkg/nodeinfo"
+)
+
+// NicFirmwarePolicyReconciler reconciles a NicFirmwarePolicy object
+type NicFirmwarePolicyReconciler struct {
+	client.Client
+	Scheme          *runtime.Scheme
+	MigrationCh     chan struct{}
+	FirmwareManager *firmware.Manager
+}
+
+//nolint:lll
+// +kubebuilder:rbac:groups=mellanox.com,resources=nicfirmwarepolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=mellanox.com,resources=nicfirmwarepolicies/finalizers,verbs=update
+// +kubebuilder:rbac:groups=mellanox.com,resources=nicfirmwarepolicies/status,verbs=get;update;patch
+
+// Reconcile upgrades the firmware of the NICs of the nodes selected by the NicFirmwarePolicy
+func (r *NicFirmwarePolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// Wait for migration flow to finish
+	select {
+	case <-r.MigrationCh:
+	case <-ctx.Done():
+		return ctrl.Result{}, fmt.Errorf("canceled")
+	}
+	reqLogger := log.FromContext(ctx)
+	reqLogger.Info("Reconciling NicFirmwarePolicy")
+
+	instance := &mellanoxv1alpha1.NicFirmwarePolicy{}
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	nodes, err := r.getPolicyNodes(ctx, instance)
+	if err == nil {
+		instance.Status.Nodes, err = r.FirmwareManager.ApplyState(ctx, instance, nodes)
+	}
+	instance.Status.State = mellanoxv1alpha1.StateReady
+	instance.Status.Reason = ""
+	if err != nil {
+		reqLogger.V(consts.LogLevelError).Error(err, "Failed to apply firmware upgrade state")
+		instance.Status.State = mellanoxv1alpha1.StateError
+		instance.Status.Reason = err.Error()
+	} else {
+		for _, nodeStatus := range instance.Status.Nodes {
+			if nodeStatus.FirmwareVersion != instance.Spec.FirmwareVersion {
+				instance.Status.State = mellanoxv1alpha1.StateNotReady
+				break
+			}
+		}
+	}
+
+	reqLogger.V(consts.LogLevelInfo).Info("Updating status", "Custom resource name", instance.Name,
+		"Result:", instance.Status.State)
+	if updateErr := r.Status().Update(ctx, instance); updateErr != nil {
+		reqLogger.V(consts.LogLevelError).Error(updateErr, "Failed to update CR status")
+		return reconcile.Result{}, updateErr
+	}
+
+	if instance.Status.State != mellanoxv1alpha1.StateReady {
+		return reconcile.Result{
+			RequeueAfter: time.Duration(config.FromEnv().Controller.RequeueTimeSeconds) * time.Second,
+		}, nil
+	}
+	return ctrl.Result{RequeueAfter: config.FromEnv().Controller.ResyncPeriod}, nil
+}
+
+// getPolicyNodes returns the nodes with Mellanox NICs selected by the policy
+func (r *NicFirmwarePolicyReconciler) getPolicyNodes(
+	ctx context.Context, policy *mellanoxv1alpha1.NicFirmwarePolicy) ([]*corev1.Node, error) {
+	selector := client.MatchingLabels{nodeinfo.NodeLabelMlnxNIC: "true"}
+	for key, value := range policy.Spec.NodeSelector {
+		selector[key] = value
+	}
+	nodeList := &corev1.NodeList{}
+	if err := r.List(ctx, nodeList, selector); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %v", err)
+	}
+	nodes := make([]*corev1.Node, 0, len(nodeList.Items))
+	for i := range nodeList.Items {
+		nodes = append(nodes, &nodeList.Items[i])
+	}
+	return nodes, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NicFirmwarePolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// requeue all policies on node changes, the nodes selected by a policy are resolved during the reconcile
+	enqueuePolicies := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, _ client.Object) []reconcile.Request {
+		policies := &mellanoxv1alpha1.NicFirmwarePolicyList{}
+		if err := r.List(ctx, policies); err != nil {
+			log.FromContext(ctx).V(consts.LogLevelError).Error(err, "Failed to list NicFirmwarePolicies")
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(policies.Items))
+		for _, policy := range policies.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: policy.Name}})
+		}
+		return requests
+	})
+
+	// react only on label and annotation changes
+	nodePredicates := builder.WithPredicates(
+		predicate.Or(predicate.AnnotationChangedPredicate{},
+			predicate.LabelChangedPredicate{}))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&mellanoxv1alpha1.NicFirmwarePolicy{}).
+		// the firmware manager is not concurrent friendly
+		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
+		Watches(&corev1.Node{}, enqueuePolicies, nodePredicates).
+		Complete(r)
+}
@@ -23,8 +23,8 @@ import (
 
 	"github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
@@ -41,17 +41,20 @@ import (
 	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
 	"github.com/Mellanox/network-operator/pkg/config"
 	"github.com/Mellanox/network-operator/pkg/consts"
+	nodeupgrade "github.com/Mellanox/network-operator/pkg/upgrade"
 )
 
 // UpgradeReconciler reconciles OFED Daemon Sets for upgrade
 type UpgradeReconciler struct {
 	client.Client
 	Scheme       *runtime.Scheme
-	StateManager upgrade.ClusterUpgradeStateManager
+	StateManager nodeupgrade.ClusterUpgradeStateManager
 	MigrationCh  chan struct{}
 }
 
-const plannedRequeueInterval = time.Minute * 2
+// plannedRequeueInterval is the interval of the reconciliation which recovers from missed events,
+// the upgrade progresses on the events of the nodes and the OFED driver Pods and DaemonSets
+const plannedRequeueInterval = time.Minute * 10
 
 // UpgradeStateAnnotation is kept for backwards cleanup TODO: drop in 2 releases
 const UpgradeStateAnnotation = "nvidia.com/ofed-upgrade-state"
@@ -60,6 +63,9 @@ const UpgradeStateAnnotation = "nvidia.com/ofed-upgrade-state"
 // +kubebuilder:rbac:groups=mellanox.com,resources=nicclusterpolicies;nicclusterpolicies/status,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups="",resources=pods,verbs=list
+// +kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=nodemaintenance.medik8s.io,resources=nodemaintenances,verbs=get;create;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments;daemonsets;replicasets;statefulsets;controllerrevisions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments/finalizers,verbs=update
 
@@ -75,15 +81,16 @@ func (r *UpgradeReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl
 	reqLogger := log.FromContext(ctx)
 	reqLogger.V(consts.LogLevelInfo).Info("Reconciling Upgrade")
 
-	nicClusterPolicy := &mellanoxv1alpha1.NicClusterPolicy{}
-	err := r.Get(ctx, types.NamespacedName{Name: consts.NicClusterPolicyResourceName}, nicClusterPolicy)
-
+	nicClusterPolicies := &mellanoxv1alpha1.NicClusterPolicyList{}
+	err := r.List(ctx, nicClusterPolicies)
 	if err != nil {
-		if errors.IsNotFound(err) {
-			return ctrl.Result{}, nil
-		}
 		return ctrl.Result{}, err
 	}
+	if len(nicClusterPolicies.Items) == 0 {
+		return ctrl.Result{}, nil
+	}
+	ofedDriver := getOFEDDriverSpec(nicClusterPolicies.Items)
+	policy := getOFEDDriverPolicy(nicClusterPolicies.Items)
 
 	// Cleanup old annotations, leftover from the old versions of network-operator
 	// TODO drop in 2 releases
@@ -92,18 +99,21 @@ func (r *UpgradeReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl
 		return ctrl.Result{}, err
 	}
 
-	if nicClusterPolicy.Spec.OFEDDriver == nil ||
-		nicClusterPolicy.Spec.OFEDDriver.OfedUpgradePolicy == nil ||
-		!nicClusterPolicy.Spec.OFEDDriver.OfedUpgradePolicy.AutoUpgrade {
+	if ofedDriver == nil ||
+		ofedDriver.OfedUpgradePolicy == nil ||
+		!ofedDriver.OfedUpgradePolicy.AutoUpgrade {
 		reqLogger.V(consts.LogLevelInfo).Info("OFED Upgrade Policy is disabled, skipping driver upgrade")
 		err = r.removeNodeUpgradeStateLabels(ctx)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
+		if err := r.updateUpgradeStatus(ctx, policy, nil); err != nil {
+			return ctrl.Result{}, err
+		}
 		return ctrl.Result{}, nil
 	}
 
-	upgradePolicy := nicClusterPolicy.Spec.OFEDDriver.OfedUpgradePolicy
+	upgradePolicy := ofedDriver.OfedUpgradePolicy
 
 	state, err := r.StateManager.BuildState(ctx,
 		config.FromEnv().State.NetworkOperatorResourceNamespace,
@@ -113,9 +123,18 @@ func (r *UpgradeReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl
 		return ctrl.Result{}, err
 	}
 
+	err = r.handleUpgradeFailureThreshold(ctx, policy, state)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.updateUpgradeStatus(ctx, policy, state); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	reqLogger.V(consts.LogLevelInfo).Info("Propagate state to state manager")
 	reqLogger.V(consts.LogLevelDebug).Info("Current cluster upgrade state", "state", state)
 	driverUpgradePolicy := mellanoxv1alpha1.GetDriverUpgradePolicy(upgradePolicy)
+	r.StateManager.SetUpgradePolicy(upgradePolicy)
 	err = r.StateManager.ApplyState(ctx, state, driverUpgradePolicy)
 	if err != nil {
 		reqLogger.V(consts.LogLevelError).Error(err, "Failed to apply cluster upgrade state")
@@ -124,8 +143,8 @@ func (r *UpgradeReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl
 
 	// In some cases if node state changes fail to apply, upgrade process
 	// might become stuck until the new reconcile loop is scheduled.
-	// Since node/ds/nicclusterpolicy updates from outside of the upgrade flow
-	// are not guaranteed, for safety reconcile loop should be requeued every few minutes.
+	// The upgrade reacts on the events of the nodes, the OFED driver Pods and DaemonSets,
+	// for safety reconcile loop is still requeued in case an event was missed.
 	return ctrl.Result{Requeue: true, RequeueAfter: plannedRequeueInterval}, nil
 }
 
@@ -149,6 +168,17 @@ func (r *UpgradeReconciler) removeNodeUpgradeStateLabels(ctx context.Context) er
 		_, present := node.Labels[upgradeStateLabel]
 		if present {
 			delete(node.Labels, upgradeStateLabel)
+			delete(node.Annotations, nodeupgrade.GetDrainProgressAnnotationKey())
+			delete(node.Annotations, nodeupgrade.GetRebootBootIDAnnotationKey())
+			delete(node.Annotations, nodeupgrade.GetSafeLoadStageAnnotationKey())
+			delete(node.Annotations, nodeupgrade.GetSafeLoadCancelAnnotationKey())
+			for _, key := range nodeupgrade.GetHookDoneAnnotationKeys() {
+				delete(node.Annotations, key)
+			}
+			if _, paused := node.Annotations[nodeupgrade.GetGPUUpgradePausedAnnotationKey()]; paused {
+				delete(node.Labels, nodeupgrade.GetGPUUpgradeSkipLabelKey())
+				delete(node.Annotations, nodeupgrade.GetGPUUpgradePausedAnnotationKey())
+			}
 			err = r.Update(ctx, node)
 			if err != nil {
 				reqLogger.V(consts.LogLevelError).Error(
@@ -230,10 +260,15 @@ func (r *UpgradeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return ok
 	}))
 
-	// react only on label and annotation changes
+	// react only on label and annotation changes and on nodes being cordoned or uncordoned
 	nodePredicates := builder.WithPredicates(
 		predicate.Or(predicate.AnnotationChangedPredicate{},
-			predicate.LabelChangedPredicate{}))
+			predicate.LabelChangedPredicate{},
+			nodeUnschedulableChangedPredicate()))
+
+	// react on the OFED driver pods becoming ready or being restarted, so that the node upgrade proceeds
+	// without waiting for the planned requeue
+	podPredicates := builder.WithPredicates(ofedDriverPodPredicate())
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&mellanoxv1alpha1.NicClusterPolicy{}).
@@ -244,5 +279,66 @@ func (r *UpgradeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(&mellanoxv1alpha1.NicClusterPolicy{}, createUpdateDeleteEnqueue).
 		Watches(&corev1.Node{}, createUpdateEnqueue, nodePredicates).
 		Watches(&appsv1.DaemonSet{}, createUpdateDeleteEnqueue, daemonSetPredicates).
+		Watches(&corev1.Pod{}, createUpdateDeleteEnqueue, podPredicates).
+		// only the upgrade hook Jobs are cached, the hooks proceed once their Jobs complete
+		Watches(&batchv1.Job{}, createUpdateDeleteEnqueue).
 		Complete(r)
 }
+
+// nodeUnschedulableChangedPredicate passes the node updates which change whether the node is schedulable
+func nodeUnschedulableChangedPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, ok := e.ObjectOld.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			newNode, ok := e.ObjectNew.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			return oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable
+		},
+	}
+}
+
+// ofedDriverPodPredicate passes the events of the OFED driver pods, updates are passed only if the phase or
+// the readiness of the pod changed
+func ofedDriverPodPredicate() predicate.Funcs {
+	isOFEDDriverPod := func(object client.Object) bool {
+		_, ok := object.GetLabels()[consts.OfedDriverLabel]
+		return ok
+	}
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return isOFEDDriverPod(e.Object) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return isOFEDDriverPod(e.Object) },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if !isOFEDDriverPod(e.ObjectNew) {
+				return false
+			}
+			oldPod, ok := e.ObjectOld.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			newPod, ok := e.ObjectNew.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			return oldPod.Status.Phase != newPod.Status.Phase || isPodReady(oldPod) != isPodReady(newPod)
+		},
+	}
+}
+
+// isPodReady returns true if the Ready condition of the pod is true
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -27,6 +27,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 
 	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
 	"github.com/Mellanox/network-operator/pkg/consts"
@@ -117,3 +118,45 @@ func createTestNodes(count int) []*corev1.Node {
 	}
 	return nodes
 }
+
+var _ = Describe("Upgrade Controller predicates", func() {
+	newPod := func(labels map[string]string, phase corev1.PodPhase, ready corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "mofed-ds-abcde", Labels: labels},
+			Status: corev1.PodStatus{
+				Phase:      phase,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		}
+	}
+	ofedLabels := map[string]string{consts.OfedDriverLabel: ""}
+
+	It("should pass the readiness changes of the OFED driver pods", func() {
+		p := ofedDriverPodPredicate()
+		notReady := newPod(ofedLabels, corev1.PodRunning, corev1.ConditionFalse)
+		ready := newPod(ofedLabels, corev1.PodRunning, corev1.ConditionTrue)
+		Expect(p.Update(event.UpdateEvent{ObjectOld: notReady, ObjectNew: ready})).To(BeTrue())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: ready.DeepCopy()})).To(BeFalse())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: newPod(ofedLabels, corev1.PodPending, corev1.ConditionFalse),
+			ObjectNew: notReady})).To(BeTrue())
+		Expect(p.Create(event.CreateEvent{Object: notReady})).To(BeTrue())
+		Expect(p.Delete(event.DeleteEvent{Object: ready})).To(BeTrue())
+	})
+	It("should ignore the pods other than the OFED driver pods", func() {
+		p := ofedDriverPodPredicate()
+		notReady := newPod(nil, corev1.PodRunning, corev1.ConditionFalse)
+		ready := newPod(nil, corev1.PodRunning, corev1.ConditionTrue)
+		Expect(p.Update(event.UpdateEvent{ObjectOld: notReady, ObjectNew: ready})).To(BeFalse())
+		Expect(p.Create(event.CreateEvent{Object: ready})).To(BeFalse())
+		Expect(p.Delete(event.DeleteEvent{Object: ready})).To(BeFalse())
+	})
+	It("should pass the nodes being cordoned or uncordoned", func() {
+		p := nodeUnschedulableChangedPredicate()
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+		cordoned := node.DeepCopy()
+		cordoned.Spec.Unschedulable = true
+		Expect(p.Update(event.UpdateEvent{ObjectOld: node, ObjectNew: cordoned})).To(BeTrue())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: cordoned, ObjectNew: node})).To(BeTrue())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: node, ObjectNew: node.DeepCopy()})).To(BeFalse())
+	})
+})
@@ -0,0 +1,168 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/consts"
+	nodeupgrade "github.com/Mellanox/network-operator/pkg/upgrade"
+)
+
+// updateUpgradeStatus reports the progress of the upgrade in the status of the policy, the status is removed
+// if the upgrade state is nil. The status is only updated if the progress changed
+func (r *UpgradeReconciler) updateUpgradeStatus(ctx context.Context,
+	policy *mellanoxv1alpha1.NicClusterPolicy, state *upgrade.ClusterUpgradeState) error {
+	reqLogger := log.FromContext(ctx)
+	if policy == nil {
+		return nil
+	}
+	var upgradeStatus *mellanoxv1alpha1.OFEDDriverUpgradeStatus
+	if state != nil {
+		upgradeStatus = getUpgradeStatus(state, policy.Status.OFEDDriverUpgrade, time.Now())
+	}
+	if equality.Semantic.DeepEqual(policy.Status.OFEDDriverUpgrade, upgradeStatus) {
+		return nil
+	}
+	policy.Status.OFEDDriverUpgrade = upgradeStatus
+	if err := r.Status().Update(ctx, policy); err != nil {
+		reqLogger.V(consts.LogLevelError).Error(err, "Failed to update upgrade progress in NicClusterPolicy status")
+		return err
+	}
+	return nil
+}
+
+// getUpgradeStatus returns the progress of the upgrade in the cluster upgrade state, the start time is kept
+// from the previous status while the upgrade is in progress
+func getUpgradeStatus(state *upgrade.ClusterUpgradeState, previous *mellanoxv1alpha1.OFEDDriverUpgradeStatus,
+	now time.Time) *mellanoxv1alpha1.OFEDDriverUpgradeStatus {
+	status := &mellanoxv1alpha1.OFEDDriverUpgradeStatus{Drain: getDrainStatus(state)}
+	started := false
+	for upgradeState, nodeStates := range state.NodeStates {
+		status.TotalNodes += len(nodeStates)
+		switch upgradeState {
+		case upgrade.UpgradeStateDone, nodeupgrade.UpgradeStateCanarySoakRequired:
+			status.UpgradedNodes += len(nodeStates)
+		case upgrade.UpgradeStateUnknown:
+			// the nodes are not processed yet, their driver may be up to date
+			status.PendingNodes += len(nodeStates)
+		case upgrade.UpgradeStateUpgradeRequired, nodeupgrade.UpgradeStateCanaryWaitRequired:
+			status.PendingNodes += len(nodeStates)
+			started = started || len(nodeStates) > 0
+		case upgrade.UpgradeStateFailed:
+			status.FailedNodes += len(nodeStates)
+		case upgrade.UpgradeStateWaitForJobsRequired, upgrade.UpgradeStatePodDeletionRequired,
+			upgrade.UpgradeStateDrainRequired:
+			status.DrainingNodes += len(nodeStates)
+			status.InProgressNodes += len(nodeStates)
+			started = started || len(nodeStates) > 0
+		default:
+			status.InProgressNodes += len(nodeStates)
+			started = started || len(nodeStates) > 0
+		}
+	}
+	if !started {
+		return status
+	}
+	if previous != nil && previous.StartTime != nil {
+		status.StartTime = previous.StartTime.DeepCopy()
+	} else {
+		startTime := metav1.NewTime(now).Rfc3339Copy()
+		status.StartTime = &startTime
+	}
+	status.EstimatedCompletionTime = estimateUpgradeCompletion(state, status)
+	return status
+}
+
+// estimateUpgradeCompletion returns the time the upgrade of the pending and in progress nodes is expected
+// to complete, nil if no node was upgraded since the start of the upgrade. The nodes upgraded since the start
+// of the upgrade are the nodes whose driver pod was created after it
+func estimateUpgradeCompletion(state *upgrade.ClusterUpgradeState,
+	status *mellanoxv1alpha1.OFEDDriverUpgradeStatus) *metav1.Time {
+	upgraded := 0
+	lastUpgrade := status.StartTime.Time
+	for _, upgradeState := range []string{upgrade.UpgradeStateDone, nodeupgrade.UpgradeStateCanarySoakRequired} {
+		for _, nodeState := range state.NodeStates[upgradeState] {
+			if nodeState.DriverPod == nil || nodeState.DriverPod.CreationTimestamp.Before(status.StartTime) {
+				continue
+			}
+			upgraded++
+			if created := nodeState.DriverPod.CreationTimestamp.Time; created.After(lastUpgrade) {
+				lastUpgrade = created
+			}
+		}
+	}
+	if upgraded == 0 {
+		return nil
+	}
+	perNode := lastUpgrade.Sub(status.StartTime.Time) / time.Duration(upgraded)
+	remaining := time.Duration(status.PendingNodes + status.InProgressNodes)
+	completion := metav1.NewTime(lastUpgrade.Add(perNode * remaining)).Rfc3339Copy()
+	return &completion
+}
+
+// getDrainStatus aggregates the drain progress reported in the annotations of the nodes being drained and of the
+// nodes whose upgrade failed, the annotation is removed once the drain completes. Nil is returned if no node is
+// drained, invalid annotations are ignored
+func getDrainStatus(state *upgrade.ClusterUpgradeState) *mellanoxv1alpha1.OFEDDriverDrainStatus {
+	status := &mellanoxv1alpha1.OFEDDriverDrainStatus{}
+	for upgradeState, nodeStates := range state.NodeStates {
+		switch upgradeState {
+		case upgrade.UpgradeStateDrainRequired, upgrade.UpgradeStateFailed:
+		case nodeupgrade.UpgradeStatePostDrainHookRequired, nodeupgrade.UpgradeStateRebootRequired,
+			upgrade.UpgradeStatePodRestartRequired, upgrade.UpgradeStateValidationRequired,
+			upgrade.UpgradeStateUncordonRequired:
+			status.DrainedNodes += len(nodeStates)
+			continue
+		default:
+			continue
+		}
+		for _, nodeState := range nodeStates {
+			progress, err := nodeupgrade.GetDrainProgress(nodeState.Node)
+			if err != nil || progress == nil {
+				continue
+			}
+			if upgradeState == upgrade.UpgradeStateFailed {
+				status.FailedNodes++
+			} else {
+				status.DrainingNodes++
+				status.PodsRemaining += progress.PodsRemaining
+			}
+			status.Nodes = append(status.Nodes, mellanoxv1alpha1.NodeDrainStatus{
+				Name:          nodeState.Node.Name,
+				StartTime:     metav1.NewTime(progress.StartTime),
+				PodsEvicted:   progress.PodsEvicted,
+				PodsRemaining: progress.PodsRemaining,
+				Attempt:       progress.Attempt,
+				LastError:     progress.LastError,
+			})
+		}
+	}
+	if status.DrainingNodes == 0 && status.DrainedNodes == 0 && status.FailedNodes == 0 {
+		return nil
+	}
+	sort.Slice(status.Nodes, func(i, j int) bool { return status.Nodes[i].Name < status.Nodes[j].Name })
+	return status
+}
@@ -0,0 +1,173 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	nodeupgrade "github.com/Mellanox/network-operator/pkg/upgrade"
+)
+
+var _ = Describe("Upgrade progress", func() {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	newNodeStates := func(podCreated time.Time, names ...string) []*upgrade.NodeUpgradeState {
+		nodeStates := make([]*upgrade.NodeUpgradeState, 0, len(names))
+		for _, name := range names {
+			nodeStates = append(nodeStates, &upgrade.NodeUpgradeState{
+				Node: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}},
+				DriverPod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+					Name: "driver-" + name, CreationTimestamp: metav1.NewTime(podCreated)}},
+			})
+		}
+		return nodeStates
+	}
+
+	It("should count the nodes by upgrade phase", func() {
+		clusterState := upgrade.NewClusterUpgradeState()
+		clusterState.NodeStates[upgrade.UpgradeStateDone] = newNodeStates(start.Add(-time.Hour), "node1", "node2")
+		clusterState.NodeStates[nodeupgrade.UpgradeStateCanarySoakRequired] = newNodeStates(start, "node3")
+		clusterState.NodeStates[upgrade.UpgradeStateUpgradeRequired] = newNodeStates(start, "node4")
+		clusterState.NodeStates[nodeupgrade.UpgradeStateCanaryWaitRequired] = newNodeStates(start, "node5")
+		clusterState.NodeStates[upgrade.UpgradeStateDrainRequired] = newNodeStates(start, "node6")
+		clusterState.NodeStates[upgrade.UpgradeStateCordonRequired] = newNodeStates(start, "node7")
+		clusterState.NodeStates[upgrade.UpgradeStateFailed] = newNodeStates(start, "node8")
+
+		status := getUpgradeStatus(&clusterState, nil, start)
+		Expect(status.TotalNodes).To(Equal(8))
+		Expect(status.UpgradedNodes).To(Equal(3))
+		Expect(status.PendingNodes).To(Equal(2))
+		Expect(status.InProgressNodes).To(Equal(2))
+		Expect(status.DrainingNodes).To(Equal(1))
+		Expect(status.FailedNodes).To(Equal(1))
+		Expect(status.StartTime.Time).To(BeTemporally("==", start))
+	})
+
+	It("should not report a start time if no upgrade is in progress", func() {
+		clusterState := upgrade.NewClusterUpgradeState()
+		clusterState.NodeStates[upgrade.UpgradeStateDone] = newNodeStates(start, "node1", "node2")
+
+		status := getUpgradeStatus(&clusterState, &mellanoxv1alpha1.OFEDDriverUpgradeStatus{
+			StartTime: &metav1.Time{Time: start}}, start.Add(time.Hour))
+		Expect(status).To(Equal(&mellanoxv1alpha1.OFEDDriverUpgradeStatus{TotalNodes: 2, UpgradedNodes: 2}))
+	})
+
+	It("should estimate the completion from the nodes upgraded since the start", func() {
+		clusterState := upgrade.NewClusterUpgradeState()
+		clusterState.NodeStates[upgrade.UpgradeStateDone] = append(append(
+			newNodeStates(start.Add(-time.Hour), "node1"),
+			newNodeStates(start.Add(10*time.Minute), "node2")...),
+			newNodeStates(start.Add(20*time.Minute), "node3")...)
+		clusterState.NodeStates[upgrade.UpgradeStateUpgradeRequired] = newNodeStates(start, "node4", "node5")
+		clusterState.NodeStates[upgrade.UpgradeStateDrainRequired] = newNodeStates(start, "node6")
+
+		status := getUpgradeStatus(&clusterState, &mellanoxv1alpha1.OFEDDriverUpgradeStatus{
+			StartTime: &metav1.Time{Time: start}}, start.Add(25*time.Minute))
+		Expect(status.StartTime.Time).To(BeTemporally("==", start))
+		Expect(status.EstimatedCompletionTime).NotTo(BeNil())
+		Expect(status.EstimatedCompletionTime.Time).To(BeTemporally("==", start.Add(50*time.Minute)))
+	})
+
+	setDrainProgress := func(nodeState *upgrade.NodeUpgradeState, progress string) {
+		nodeState.Node.Annotations = map[string]string{nodeupgrade.GetDrainProgressAnnotationKey(): progress}
+	}
+
+	It("should aggregate the drain progress of the nodes", func() {
+		clusterState := upgrade.NewClusterUpgradeState()
+		clusterState.NodeStates[upgrade.UpgradeStateDrainRequired] = newNodeStates(start, "node2", "node1", "node3")
+		setDrainProgress(clusterState.NodeStates[upgrade.UpgradeStateDrainRequired][0],
+			`{"startTime":"2024-05-01T10:00:00Z","elapsedSeconds":60,"podsEvicted":1,"podsRemaining":2,"attempt":1}`)
+		setDrainProgress(clusterState.NodeStates[upgrade.UpgradeStateDrainRequired][1],
+			`{"startTime":"2024-05-01T09:40:00Z","elapsedSeconds":1200,"podsEvicted":3,"podsRemaining":1,`+
+				`"attempt":2,"lastError":"cannot evict pod"}`)
+		clusterState.NodeStates[upgrade.UpgradeStatePodRestartRequired] = newNodeStates(start, "node4")
+		clusterState.NodeStates[upgrade.UpgradeStateFailed] = newNodeStates(start, "node5")
+		setDrainProgress(clusterState.NodeStates[upgrade.UpgradeStateFailed][0],
+			`{"startTime":"2024-05-01T09:00:00Z","elapsedSeconds":600,"podsEvicted":0,"podsRemaining":1,`+
+				`"attempt":3,"lastError":"timed out"}`)
+		// the annotation of a node which is not drained is ignored
+		clusterState.NodeStates[upgrade.UpgradeStateDone] = newNodeStates(start, "node6")
+		setDrainProgress(clusterState.NodeStates[upgrade.UpgradeStateDone][0],
+			`{"startTime":"2024-05-01T09:00:00Z","podsRemaining":5,"attempt":1}`)
+
+		status := getUpgradeStatus(&clusterState, nil, start)
+		Expect(status.Drain).To(Equal(&mellanoxv1alpha1.OFEDDriverDrainStatus{
+			DrainingNodes: 2,
+			DrainedNodes:  1,
+			FailedNodes:   1,
+			PodsRemaining: 3,
+			Nodes: []mellanoxv1alpha1.NodeDrainStatus{
+				{Name: "node1", StartTime: metav1.NewTime(start.Add(-20 * time.Minute)), PodsEvicted: 3,
+					PodsRemaining: 1, Attempt: 2, LastError: "cannot evict pod"},
+				{Name: "node2", StartTime: metav1.NewTime(start), PodsEvicted: 1, PodsRemaining: 2, Attempt: 1},
+				{Name: "node5", StartTime: metav1.NewTime(start.Add(-time.Hour)), PodsRemaining: 1, Attempt: 3,
+					LastError: "timed out"},
+			},
+		}))
+	})
+
+	It("should not report the drain progress if no node is drained", func() {
+		clusterState := upgrade.NewClusterUpgradeState()
+		clusterState.NodeStates[upgrade.UpgradeStateUpgradeRequired] = newNodeStates(start, "node1")
+
+		status := getUpgradeStatus(&clusterState, nil, start)
+		Expect(status.Drain).To(BeNil())
+	})
+
+	It("should update the drain progress in the status of the policy", func() {
+		scheme := runtime.NewScheme()
+		Expect(mellanoxv1alpha1.AddToScheme(scheme)).To(Succeed())
+		policy := &mellanoxv1alpha1.NicClusterPolicy{ObjectMeta: metav1.ObjectMeta{Name: "nic-cluster-policy"}}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(policy).
+			WithStatusSubresource(policy).Build()
+		r := &UpgradeReconciler{Client: c, Scheme: scheme}
+
+		clusterState := upgrade.NewClusterUpgradeState()
+		clusterState.NodeStates[upgrade.UpgradeStateDrainRequired] = newNodeStates(start, "node1")
+		setDrainProgress(clusterState.NodeStates[upgrade.UpgradeStateDrainRequired][0],
+			`{"startTime":"2024-05-01T10:00:00Z","elapsedSeconds":60,"podsEvicted":1,"podsRemaining":2,"attempt":1}`)
+		Expect(r.updateUpgradeStatus(context.TODO(), policy, &clusterState)).To(Succeed())
+
+		updated := &mellanoxv1alpha1.NicClusterPolicy{}
+		Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(policy), updated)).To(Succeed())
+		Expect(updated.Status.OFEDDriverUpgrade).NotTo(BeNil())
+		Expect(updated.Status.OFEDDriverUpgrade.Drain).NotTo(BeNil())
+		Expect(updated.Status.OFEDDriverUpgrade.Drain.DrainingNodes).To(Equal(1))
+		Expect(updated.Status.OFEDDriverUpgrade.Drain.PodsRemaining).To(Equal(2))
+		Expect(updated.Status.OFEDDriverUpgrade.Drain.Nodes).To(HaveLen(1))
+		Expect(updated.Status.OFEDDriverUpgrade.Drain.Nodes[0].Attempt).To(Equal(1))
+	})
+
+	It("should not estimate the completion before a node is upgraded", func() {
+		clusterState := upgrade.NewClusterUpgradeState()
+		clusterState.NodeStates[upgrade.UpgradeStateDone] = newNodeStates(start.Add(-time.Hour), "node1")
+		clusterState.NodeStates[upgrade.UpgradeStateUpgradeRequired] = newNodeStates(start, "node2")
+
+		status := getUpgradeStatus(&clusterState, nil, start)
+		Expect(status.EstimatedCompletionTime).To(BeNil())
+	})
+})
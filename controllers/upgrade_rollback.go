@@ -0,0 +1,145 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+// crashLoopBackOffReason is the reason of the waiting state of a container which is restarted with a back-off
+const crashLoopBackOffReason = "CrashLoopBackOff"
+
+// getOFEDDriverPolicy returns the NicClusterPolicy which configures the OFED driver, nil if there is none
+func getOFEDDriverPolicy(policies []mellanoxv1alpha1.NicClusterPolicy) *mellanoxv1alpha1.NicClusterPolicy {
+	for i := range policies {
+		if policies[i].Spec.OFEDDriver != nil {
+			return &policies[i]
+		}
+	}
+	return nil
+}
+
+// handleUpgradeFailureThreshold rolls back the OFED driver version if the upgrade failed on more nodes
+// than the failure threshold of the upgrade policy, the rollback is recorded in the status of the policy
+// and applied by the OFED state when the DaemonSets are rendered
+func (r *UpgradeReconciler) handleUpgradeFailureThreshold(ctx context.Context,
+	policy *mellanoxv1alpha1.NicClusterPolicy, state *upgrade.ClusterUpgradeState) error {
+	reqLogger := log.FromContext(ctx)
+	threshold := policy.Spec.OFEDDriver.OfedUpgradePolicy.FailureThreshold
+	if threshold == nil {
+		return nil
+	}
+	version := policy.Status.OFEDDriverVersion
+	if version == "" {
+		version = policy.Spec.OFEDDriver.Version
+	}
+	if rollback := policy.Status.OFEDDriverRollback; rollback != nil && rollback.FailedVersion == version {
+		return nil
+	}
+	failedNodes := getFailedUpgradeNodes(state, version)
+	if len(failedNodes) <= *threshold {
+		return nil
+	}
+	previousVersion := getPreviousOFEDDriverVersion(state, version)
+	if previousVersion == "" {
+		reqLogger.V(consts.LogLevelWarning).Info(
+			"Upgrade failure threshold exceeded, but no previous OFED version to roll back to",
+			"version", version, "failedNodes", failedNodes)
+		return nil
+	}
+	policy.Status.OFEDDriverRollback = &mellanoxv1alpha1.OFEDDriverRollbackStatus{
+		FailedVersion: version,
+		Version:       previousVersion,
+		Reason: fmt.Sprintf("upgrade failed on %d nodes, exceeding the failure threshold of %d: %s",
+			len(failedNodes), *threshold, strings.Join(failedNodes, ", ")),
+	}
+	mellanoxv1alpha1.SetDegradedCondition(&policy.Status.Conditions, policy.Status.OFEDDriverRollback,
+		policy.Generation)
+	reqLogger.V(consts.LogLevelInfo).Info("Upgrade failure threshold exceeded, rolling back OFED version",
+		"failedVersion", version, "version", previousVersion, "failedNodes", failedNodes)
+	if err := r.Status().Update(ctx, policy); err != nil {
+		reqLogger.V(consts.LogLevelError).Error(err, "Failed to record OFED version rollback in NicClusterPolicy status")
+		return err
+	}
+	return nil
+}
+
+// getFailedUpgradeNodes returns the sorted names of the nodes in upgrade-failed state and of the nodes
+// on which the driver pod of the given version is in CrashLoopBackOff
+func getFailedUpgradeNodes(state *upgrade.ClusterUpgradeState, version string) []string {
+	failedNodes := make([]string, 0)
+	for upgradeState, nodeStates := range state.NodeStates {
+		for _, nodeState := range nodeStates {
+			if upgradeState == upgrade.UpgradeStateFailed ||
+				(getDriverPodVersion(nodeState) == version && isPodCrashLooping(nodeState.DriverPod)) {
+				failedNodes = append(failedNodes, nodeState.Node.Name)
+			}
+		}
+	}
+	sort.Strings(failedNodes)
+	return failedNodes
+}
+
+// getPreviousOFEDDriverVersion returns the OFED driver version running on most of the nodes
+// which are not upgraded to the given version, an empty string if all nodes are upgraded
+func getPreviousOFEDDriverVersion(state *upgrade.ClusterUpgradeState, version string) string {
+	nodesPerVersion := make(map[string]int)
+	for _, nodeStates := range state.NodeStates {
+		for _, nodeState := range nodeStates {
+			if podVersion := getDriverPodVersion(nodeState); podVersion != "" && podVersion != version {
+				nodesPerVersion[podVersion]++
+			}
+		}
+	}
+	previousVersion := ""
+	for podVersion, count := range nodesPerVersion {
+		if count > nodesPerVersion[previousVersion] ||
+			(count == nodesPerVersion[previousVersion] && podVersion < previousVersion) {
+			previousVersion = podVersion
+		}
+	}
+	return previousVersion
+}
+
+// getDriverPodVersion returns the version of the OFED driver pod on the node, an empty string if there is no pod
+func getDriverPodVersion(nodeState *upgrade.NodeUpgradeState) string {
+	if nodeState.DriverPod == nil || len(nodeState.DriverPod.Spec.Containers) == 0 {
+		return ""
+	}
+	return getOFEDDriverVersion(nodeState.DriverPod.Spec.Containers[0].Image, nodeState.Node)
+}
+
+// isPodCrashLooping returns true if a container of the pod is in CrashLoopBackOff
+func isPodCrashLooping(pod *corev1.Pod) bool {
+	for i := range pod.Status.ContainerStatuses {
+		waiting := pod.Status.ContainerStatuses[i].State.Waiting
+		if waiting != nil && waiting.Reason == crashLoopBackOffReason {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Mellanox/network-operator/pkg/nodeinfo"
+)
+
+var _ = Describe("Upgrade failure threshold", func() {
+	const (
+		oldVersion = "24.01-0.3.3.1-0"
+		newVersion = "24.04-0.6.6.0-0"
+	)
+	newNodeState := func(name, version string, crashLooping bool) *upgrade.NodeUpgradeState {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{
+			nodeinfo.NodeLabelOSName: "ubuntu",
+			nodeinfo.NodeLabelOSVer:  "22.04",
+		}}}
+		pod := &corev1.Pod{Spec: corev1.PodSpec{NodeName: name, Containers: []corev1.Container{
+			{Image: "nvcr.io/nvidia/mellanox/doca-driver:" + version + "-ubuntu22.04-amd64"}}}}
+		if crashLooping {
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: crashLoopBackOffReason}}}}
+		}
+		return &upgrade.NodeUpgradeState{Node: node, DriverPod: pod}
+	}
+	var state *upgrade.ClusterUpgradeState
+
+	BeforeEach(func() {
+		clusterState := upgrade.NewClusterUpgradeState()
+		state = &clusterState
+		state.NodeStates[upgrade.UpgradeStateFailed] = []*upgrade.NodeUpgradeState{
+			newNodeState("node3", newVersion, true)}
+		state.NodeStates[upgrade.UpgradeStateDone] = []*upgrade.NodeUpgradeState{
+			newNodeState("node2", newVersion, true), newNodeState("node1", newVersion, false)}
+		state.NodeStates[upgrade.UpgradeStateUpgradeRequired] = []*upgrade.NodeUpgradeState{
+			newNodeState("node4", oldVersion, true), newNodeState("node5", oldVersion, false),
+			newNodeState("node6", "23.10-0.5.5.0-0", false)}
+	})
+
+	It("should get the nodes on which the upgrade failed", func() {
+		Expect(getFailedUpgradeNodes(state, newVersion)).To(Equal([]string{"node2", "node3"}))
+	})
+
+	It("should get the version running on most of the nodes which are not upgraded", func() {
+		Expect(getPreviousOFEDDriverVersion(state, newVersion)).To(Equal(oldVersion))
+	})
+
+	It("should not get a previous version if all nodes are upgraded", func() {
+		delete(state.NodeStates, upgrade.UpgradeStateUpgradeRequired)
+		Expect(getPreviousOFEDDriverVersion(state, newVersion)).To(BeEmpty())
+	})
+})
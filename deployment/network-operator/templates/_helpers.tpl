@@ -181,6 +181,38 @@ imagePullSecrets helpers
 {{- $imagePullSecrets | toJson }}
 {{- end }}
 
+{{- define "network-operator.secondaryNetwork.ovsCni.imagePullSecrets" }}
+{{- $imagePullSecrets := list }}
+{{- if .Values.secondaryNetwork.ovsCni.imagePullSecrets }}
+{{- range .Values.secondaryNetwork.ovsCni.imagePullSecrets }}
+{{- $imagePullSecrets  = append $imagePullSecrets  . }}
+{{- end }}
+{{- else }}
+{{- if .Values.imagePullSecrets }}
+{{- range .Values.imagePullSecrets }}
+{{- $imagePullSecrets  = append $imagePullSecrets  . }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- $imagePullSecrets | toJson }}
+{{- end }}
+
+{{- define "network-operator.secondaryNetwork.rdmaCni.imagePullSecrets" }}
+{{- $imagePullSecrets := list }}
+{{- if .Values.secondaryNetwork.rdmaCni.imagePullSecrets }}
+{{- range .Values.secondaryNetwork.rdmaCni.imagePullSecrets }}
+{{- $imagePullSecrets  = append $imagePullSecrets  . }}
+{{- end }}
+{{- else }}
+{{- if .Values.imagePullSecrets }}
+{{- range .Values.imagePullSecrets }}
+{{- $imagePullSecrets  = append $imagePullSecrets  . }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- $imagePullSecrets | toJson }}
+{{- end }}
+
 {{- define "network-operator.secondaryNetwork.ipamPlugin.imagePullSecrets" }}
 {{- $imagePullSecrets := list }}
 {{- if .Values.secondaryNetwork.ipamPlugin.imagePullSecrets }}
@@ -229,6 +261,22 @@ imagePullSecrets helpers
 {{- $imagePullSecrets | toJson }}
 {{- end }}
 
+{{- define "network-operator.nicConfigurationDaemon.imagePullSecrets" }}
+{{- $imagePullSecrets := list }}
+{{- if .Values.nicConfigurationDaemon.imagePullSecrets }}
+{{- range .Values.nicConfigurationDaemon.imagePullSecrets }}
+{{- $imagePullSecrets  = append $imagePullSecrets  . }}
+{{- end }}
+{{- else }}
+{{- if .Values.imagePullSecrets }}
+{{- range .Values.imagePullSecrets }}
+{{- $imagePullSecrets  = append $imagePullSecrets  . }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- $imagePullSecrets | toJson }}
+{{- end }}
+
 {{- define "network-operator.docaTelemetryService.imagePullSecrets" }}
 {{- $imagePullSecrets := list }}
 {{- if .Values.docaTelemetryService.imagePullSecrets }}
@@ -245,3 +293,12 @@ imagePullSecrets helpers
 {{- $imagePullSecrets | toJson }}
 {{- end }}
 
+
+{{/*
+The webhook certificate is generated and rotated by the operator when neither cert-manager nor a custom certificate is used
+*/}}
+{{- define "network-operator.admissionController.manageCertificate" -}}
+{{- with .Values.operator.admissionController }}
+{{- if and .enabled (not .useCertManager) .manageCertificate }}true{{ end }}
+{{- end }}
+{{- end }}
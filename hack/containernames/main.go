@@ -0,0 +1,96 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package main generates the names of the containers whose resources can be set for each state.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/Mellanox/network-operator/pkg/state"
+)
+
+const header = `/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by hack/containernames. DO NOT EDIT.
+`
+
+var containerNamesTemplate = template.Must(template.New("containerNames").Parse(header + `
+package state
+
+// containerNames are the names of the containers whose resources can be set, keyed by the manifests directory
+// of the state
+var containerNames = map[string][]string{
+{{- range $dir, $names := . }}
+	{{ printf "%q" $dir }}: { {{- range $i, $name := $names }}{{ if $i }}, {{ end }}{{ printf "%q" $name }}{{ end -}} },
+{{- end }}
+}
+`))
+
+func main() {
+	manifestsDir := flag.String("manifestsDir", "manifests", "directory of the manifests of the states")
+	output := flag.String("output", "zz_generated.container_names.go", "generated file")
+	flag.Parse()
+
+	dirs, err := filepath.Glob(filepath.Join(*manifestsDir, "state-*"))
+	if err != nil {
+		log.Fatalf("failed to list the manifests directories: %v", err)
+	}
+	containerNames := map[string][]string{}
+	for _, dir := range dirs {
+		names, err := state.ParseTemplateContainerNames(dir)
+		if err != nil {
+			log.Fatalf("failed to parse the container names of %s: %v", dir, err)
+		}
+		if len(names) > 0 {
+			containerNames[filepath.Base(dir)] = names
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := containerNamesTemplate.Execute(&buf, containerNames); err != nil {
+		log.Fatalf("failed to render the container names: %v", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("failed to format the container names: %v", err)
+	}
+	if err := os.WriteFile(*output, src, 0o600); err != nil {
+		log.Fatalf("failed to write %s: %v", *output, err)
+	}
+	fmt.Printf("generated %s\n", *output)
+}
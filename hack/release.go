@@ -51,7 +51,9 @@ type Release struct {
 	NvIPAM                       *mellanoxv1alpha1.ImageSpec
 	NicFeatureDiscovery          *mellanoxv1alpha1.ImageSpec
 	DOCATelemetryService         *mellanoxv1alpha1.ImageSpec
+	NicConfigurationDaemon       *mellanoxv1alpha1.ImageSpec
 	OVSCni                       *mellanoxv1alpha1.ImageSpec
+	RdmaCni                      *mellanoxv1alpha1.ImageSpec
 }
 
 func readDefaults(releaseDefaults string) Release {
@@ -98,7 +100,9 @@ func readEnvironmentVariables(release *Release) {
 	initWithEnvVariale("NV_IPAM", release.NvIPAM)
 	initWithEnvVariale("NIC_FEATURE_DISCOVERY", release.NicFeatureDiscovery)
 	initWithEnvVariale("DOCA_TELEMETRY_SERVICE", release.DOCATelemetryService)
+	initWithEnvVariale("NIC_CONFIGURATION_DAEMON", release.NicConfigurationDaemon)
 	initWithEnvVariale("OVS_CNI", release.OVSCni)
+	initWithEnvVariale("RDMA_CNI", release.RdmaCni)
 }
 
 func main() {
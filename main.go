@@ -20,21 +20,34 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
 	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	osconfigv1 "github.com/openshift/api/config/v1"
 	imagev1 "github.com/openshift/api/image/v1"
+	uberzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/yaml"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -42,11 +55,18 @@ import (
 
 	mellanoxcomv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
 	"github.com/Mellanox/network-operator/api/v1alpha1/validator"
+	mellanoxcomv1beta1 "github.com/Mellanox/network-operator/api/v1beta1"
 	"github.com/Mellanox/network-operator/controllers"
 	"github.com/Mellanox/network-operator/pkg/clustertype"
+	operatorconfig "github.com/Mellanox/network-operator/pkg/config"
+	"github.com/Mellanox/network-operator/pkg/consts"
 	"github.com/Mellanox/network-operator/pkg/docadriverimages"
+	"github.com/Mellanox/network-operator/pkg/firmware"
 	"github.com/Mellanox/network-operator/pkg/migrate"
+	"github.com/Mellanox/network-operator/pkg/state"
 	"github.com/Mellanox/network-operator/pkg/staticconfig"
+	nodeupgrade "github.com/Mellanox/network-operator/pkg/upgrade"
+	"github.com/Mellanox/network-operator/pkg/webhookcert"
 	"github.com/Mellanox/network-operator/version"
 	// +kubebuilder:scaffold:imports
 )
@@ -60,6 +80,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(mellanoxcomv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(mellanoxcomv1beta1.AddToScheme(scheme))
 	utilruntime.Must(netattdefv1.AddToScheme(scheme))
 	utilruntime.Must(osconfigv1.AddToScheme(scheme))
 	utilruntime.Must(imagev1.AddToScheme(scheme))
@@ -75,6 +96,14 @@ func setupWebhookControllers(mgr ctrl.Manager) error {
 		setupLog.Error(err, "unable to create webhook", "webhook", "HostDeviceNetwork")
 		return err
 	}
+	if err := validator.SetupMacvlanNetworkWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "MacvlanNetwork")
+		return err
+	}
+	if err := validator.SetupIPoIBNetworkWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "IPoIBNetwork")
+		return err
+	}
 	if err := validator.SetupNicClusterPolicyWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "NicClusterPolicy")
 
@@ -91,8 +120,11 @@ func setupCRDControllers(ctx context.Context, c client.Client, mgr ctrl.Manager,
 		return err
 	}
 
-	cniBinDir := os.Getenv("CNI_BIN_DIR")
-	staticInfoProvider := staticconfig.NewProvider(staticconfig.StaticConfig{CniBinDirectory: cniBinDir})
+	staticInfoProvider := staticconfig.NewProvider(staticconfig.StaticConfig{
+		CniBinDirectory:  os.Getenv("CNI_BIN_DIR"),
+		CniConfDirectory: os.Getenv("CNI_CONF_DIR"),
+		ClusterType:      clusterTypeProvider.GetClusterType(),
+	})
 
 	docaImagesProvider := docadriverimages.NewProvider(ctx, c)
 
@@ -131,26 +163,120 @@ func setupCRDControllers(ctx context.Context, c client.Client, mgr ctrl.Manager,
 		setupLog.Error(err, "unable to create controller", "controller", "IPoIBNetwork")
 		return err
 	}
+	if err := (&controllers.NicConfigurationTemplateReconciler{
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		MigrationCh: migrationChan,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NicConfigurationTemplate")
+		return err
+	}
+	return nil
+}
+
+// setupConfigWatcher loads the configuration of the Operator from the ConfigMap, if set, before the controllers
+// are set up and reloads it when the ConfigMap changes
+func setupConfigWatcher(ctx context.Context, c client.Client, mgr ctrl.Manager,
+	logLevels *operatorconfig.LogLevels) error {
+	cfg := operatorconfig.FromEnv()
+	if cfg.ConfigMapName == "" {
+		return nil
+	}
+	w := operatorconfig.NewWatcher(c, cfg.State.NetworkOperatorResourceNamespace, cfg.ConfigMapName, logLevels,
+		ctrl.Log.WithName("ConfigWatcher"))
+	if err := w.Load(ctx); err != nil {
+		setupLog.Error(err, "failed to load Operator configuration")
+		return err
+	}
+	if err := mgr.Add(w); err != nil {
+		setupLog.Error(err, "failed to add ConfigWatcher to the Manager")
+		return err
+	}
+	return nil
+}
+
+// setupWebhookCertManager sets up the management of the webhook server certificate if enabled,
+// the certificate is ensured before the manager starts since the webhook server requires it to start.
+// The certificate is not managed if the Operator is installed by OLM, which generates it and injects its CA
+// into the webhooks of the ClusterServiceVersion.
+func setupWebhookCertManager(ctx context.Context, c client.Client, mgr ctrl.Manager) error {
+	webhookConfig := operatorconfig.FromEnv().Webhook
+	if !webhookConfig.ManageCertificate {
+		return nil
+	}
+	if operatorconfig.FromEnv().OLM.Installed() {
+		setupLog.Info("webhook certificate is managed by OLM, ignoring WEBHOOK_MANAGE_CERTIFICATE")
+		return nil
+	}
+	m := &webhookcert.Manager{
+		K8sClient: c,
+		Namespace: operatorconfig.FromEnv().State.NetworkOperatorResourceNamespace,
+		CertDir:   filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs"),
+		Config:    webhookConfig,
+		Logger:    ctrl.Log.WithName("WebhookCertManager"),
+	}
+	if err := m.Ensure(ctx); err != nil {
+		setupLog.Error(err, "failed to ensure webhook certificate")
+		return err
+	}
+	if err := mgr.Add(m); err != nil {
+		setupLog.Error(err, "failed to add WebhookCertManager to the Manager")
+		return err
+	}
 	return nil
 }
 
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
+	var leaseDuration, renewDeadline, retryPeriod time.Duration
 	var probeAddr string
+	var renderOnly string
+	var renderNodes string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&leaseDuration, "leader-elect-lease-duration", 15*time.Second,
+		"The duration non-leader replicas wait before trying to acquire the leadership of a leader which "+
+			"stopped renewing it.")
+	flag.DurationVar(&renewDeadline, "leader-elect-renew-deadline", 10*time.Second,
+		"The duration the leader retries to renew its leadership before giving it up.")
+	flag.DurationVar(&retryPeriod, "leader-elect-retry-period", 2*time.Second,
+		"The duration replicas wait between attempts to acquire or renew the leadership.")
+	flag.StringVar(&renderOnly, "render-only", "",
+		"Path to a NicClusterPolicy YAML file. If set, the manifests of the NicClusterPolicy are rendered "+
+			"to stdout without accessing the cluster and the operator exits.")
+	flag.StringVar(&renderNodes, "render-nodes", "",
+		"Path to a YAML file with a NodeList used to render the OFED driver in render-only mode.")
 	opts := zap.Options{
 		Development: true,
 	}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
+	// the log levels can be changed at runtime by the configuration ConfigMap
+	logLevel, ok := opts.Level.(uberzap.AtomicLevel)
+	if !ok {
+		logLevel = uberzap.NewAtomicLevelAt(zapcore.InfoLevel)
+		if opts.Development {
+			logLevel.SetLevel(zapcore.DebugLevel)
+		}
+		opts.Level = logLevel
+	}
+	logLevels := operatorconfig.NewLogLevels(logLevel)
+	opts.ZapOpts = append(opts.ZapOpts, uberzap.WrapCore(logLevels.WrapCore))
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if renderOnly != "" {
+		if err := renderNicClusterPolicy(renderOnly, renderNodes, os.Stdout); err != nil {
+			setupLog.Error(err, "failed to render NicClusterPolicy")
+			os.Exit(1)
+		}
+		return
+	}
+
 	stopCtx := ctrl.SetupSignalHandler()
 
 	clientConf := ctrl.GetConfigOrDie()
@@ -161,6 +287,14 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "12620820.mellanox.com",
+		Cache:                  getCacheOptions(),
+		Client:                 getClientOptions(),
+		// the process exits once the manager is stopped, releasing the leadership lets another replica take over
+		// without waiting for the lease to expire
+		LeaderElectionReleaseOnCancel: true,
+		LeaseDuration:                 &leaseDuration,
+		RenewDeadline:                 &renewDeadline,
+		RetryPeriod:                   &retryPeriod,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -174,6 +308,10 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := setupConfigWatcher(stopCtx, directClient, mgr, logLevels); err != nil {
+		os.Exit(1)
+	}
+
 	migrationCompletionChan := make(chan struct{})
 	m := migrate.Migrator{
 		K8sClient:      directClient,
@@ -197,10 +335,18 @@ func main() {
 		os.Exit(1)
 	}
 
+	err = setupFirmwareController(mgr, migrationCompletionChan)
+	if err != nil {
+		os.Exit(1)
+	}
+
 	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
 		if err := setupWebhookControllers(mgr); err != nil {
 			os.Exit(1)
 		}
+		if err := setupWebhookCertManager(stopCtx, directClient, mgr); err != nil {
+			os.Exit(1)
+		}
 	}
 
 	// +kubebuilder:scaffold:builder
@@ -213,6 +359,17 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	// webhooks are served by all the replicas, replicas are ready only once their webhook server is started
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
+			setupLog.Error(err, "unable to set up webhook ready check")
+			os.Exit(1)
+		}
+		if err := mgr.AddHealthzCheck("webhook-schemas", validator.SchemasLoadedChecker); err != nil {
+			setupLog.Error(err, "unable to set up webhook schemas health check")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager", "version", version.Version, "commit", version.Commit, "buildDate", version.Date)
 	if err := mgr.Start(stopCtx); err != nil {
@@ -221,12 +378,41 @@ func main() {
 	}
 }
 
+// getCacheOptions returns the options of the manager cache, the DaemonSets, ConfigMaps and ServiceAccounts
+// are watched only if they were created by the operator, i.e. labeled with consts.StateLabel,
+// and the Jobs only if they run an upgrade hook,
+// so that the memory used by the cache doesn't scale with the objects of these kinds in the cluster.
+func getCacheOptions() cache.Options {
+	// a label key is parsed as a selector requiring the label to exist
+	stateLabelSelector, err := labels.Parse(consts.StateLabel)
+	utilruntime.Must(err)
+	stateSelector := cache.ByObject{Label: stateLabelSelector}
+	hookJobLabelSelector, err := labels.Parse(nodeupgrade.HookJobLabel)
+	utilruntime.Must(err)
+	return cache.Options{
+		ByObject: map[client.Object]cache.ByObject{
+			&appsv1.DaemonSet{}:      stateSelector,
+			&corev1.ConfigMap{}:      stateSelector,
+			&corev1.ServiceAccount{}: stateSelector,
+			&batchv1.Job{}:           {Label: hookJobLabelSelector},
+		},
+	}
+}
+
+// getClientOptions returns the options of the manager client, ConfigMaps are read from the API server since
+// the operator reads ConfigMaps it didn't create, e.g. referenced by the NicClusterPolicy, which are not cached
+func getClientOptions() client.Options {
+	return client.Options{
+		Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.ConfigMap{}}},
+	}
+}
+
 func setupUpgradeController(mgr ctrl.Manager, migrationChan chan struct{}) error {
 	upgrade.SetDriverName("ofed")
 
 	upgradeLogger := ctrl.Log.WithName("controllers").WithName("Upgrade")
 
-	clusterUpdateStateManager, err := upgrade.NewClusterUpgradeStateManager(
+	clusterUpdateStateManager, err := nodeupgrade.NewClusterUpgradeStateManager(
 		upgradeLogger.WithName("clusterUpgradeManager"), config.GetConfigOrDie(), nil)
 
 	if err != nil {
@@ -245,3 +431,71 @@ func setupUpgradeController(mgr ctrl.Manager, migrationChan chan struct{}) error
 	}
 	return nil
 }
+
+func setupFirmwareController(mgr ctrl.Manager, migrationChan chan struct{}) error {
+	k8sInterface, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create kubernetes client", "controller", "NicFirmwarePolicy")
+		return err
+	}
+
+	firmwareLogger := ctrl.Log.WithName("controllers").WithName("NicFirmwarePolicy")
+	if err = (&controllers.NicFirmwarePolicyReconciler{
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		MigrationCh: migrationChan,
+		FirmwareManager: firmware.NewManager(k8sInterface, firmwareLogger.WithName("firmwareManager"),
+			mgr.GetEventRecorderFor("nicfirmwarepolicy-controller")),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NicFirmwarePolicy")
+		return err
+	}
+	return nil
+}
+
+// renderNicClusterPolicy writes the manifests of the NicClusterPolicy read from policyFile to w
+// as a multi-document YAML, the nodes used to render the OFED driver are read from nodesFile if set
+func renderNicClusterPolicy(policyFile, nodesFile string, w io.Writer) error {
+	data, err := os.ReadFile(filepath.Clean(policyFile))
+	if err != nil {
+		return err
+	}
+	cr := &mellanoxcomv1alpha1.NicClusterPolicy{}
+	if err := yaml.UnmarshalStrict(data, cr); err != nil {
+		return fmt.Errorf("failed to parse NicClusterPolicy: %v", err)
+	}
+
+	var nodes []*corev1.Node
+	if nodesFile != "" {
+		data, err := os.ReadFile(filepath.Clean(nodesFile))
+		if err != nil {
+			return err
+		}
+		nodeList := &corev1.NodeList{}
+		if err := yaml.Unmarshal(data, nodeList); err != nil {
+			return fmt.Errorf("failed to parse NodeList: %v", err)
+		}
+		for i := range nodeList.Items {
+			nodes = append(nodes, &nodeList.Items[i])
+		}
+	}
+
+	staticConfig := staticconfig.StaticConfig{
+		CniBinDirectory:  os.Getenv("CNI_BIN_DIR"),
+		CniConfDirectory: os.Getenv("CNI_CONF_DIR"),
+	}
+	objs, err := state.RenderNicClusterPolicy(context.Background(), cr, nodes, staticConfig, ctrl.Log.WithName("render"))
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		out, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "---\n%s", out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
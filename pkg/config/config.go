@@ -18,20 +18,39 @@ limitations under the License.
 package config
 
 import (
+	"fmt"
+	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/caarlos0/env/v6"
 )
 
 var once sync.Once
-var operatorConfig *OperatorConfig
+var operatorConfig atomic.Pointer[OperatorConfig]
 
 // OperatorConfig holds configuration for the Operator.
 type OperatorConfig struct {
 	State      StateConfig
 	Controller ControllerConfig
+	Webhook    WebhookConfig
+	OLM        OLMConfig
 	// disable migration logic in the operator.
 	DisableMigration bool `env:"DISABLE_MIGRATION" envDefault:"false"`
+	// AdoptExistingResources enables adopting NetworkAttachmentDefinitions of the network CRs which already exist,
+	// e.g. created manually or by an older release, by setting the CR as their controller during migration
+	AdoptExistingResources bool `env:"ADOPT_EXISTING_RESOURCES" envDefault:"false"`
+	// ConfigMapName is the name of the ConfigMap in the namespace of the Operator which overrides the configuration
+	// from the environment, its keys are the names of the environment variables. Disabled if empty.
+	ConfigMapName string `env:"OPERATOR_CONFIG_MAP_NAME" envDefault:""`
+	// LogLevel is the log level of the Operator, debug, info, error or a verbosity level, e.g. 2,
+	// the level set with the --zap-log-level flag is used if empty
+	LogLevel string `env:"LOG_LEVEL" envDefault:""`
+	// NamedLogLevels are comma separated log levels of named loggers overriding LogLevel,
+	// e.g. controllers.Upgrade=debug,state=2, see LogLevels.SetNamed
+	NamedLogLevels string `env:"LOG_LEVELS" envDefault:""`
 }
 
 // StateConfig holds configuration for Operator State.
@@ -40,6 +59,62 @@ type StateConfig struct {
 	ManifestBaseDir                  string `env:"STATE_MANIFEST_BASE_DIR" envDefault:"./manifests"`
 	OFEDState                        OFEDStateConfig
 	DocaDriverImagePollTimeMinutes   uint `env:"DOCA_DRIVER_IMAGE_POLL_TIME_MINUTES" envDefault:"30"`
+	// SyncWorkers is the maximal number of independent states synced in parallel
+	SyncWorkers int `env:"STATE_SYNC_WORKERS" envDefault:"1"`
+	// NotReadyEventThreshold is the time a state can stay not ready before an event is emitted on the custom resource
+	NotReadyEventThreshold time.Duration `env:"STATE_NOT_READY_EVENT_THRESHOLD" envDefault:"5m"`
+	// ManifestOverlay enables overlaying the manifests of the states with manifests from ConfigMaps
+	ManifestOverlay bool `env:"STATE_MANIFEST_OVERLAY" envDefault:"false"`
+	// ServerSideApply enables reconciling the objects of the states with Server-Side Apply instead of create/update
+	ServerSideApply bool `env:"STATE_SERVER_SIDE_APPLY" envDefault:"false"`
+	// DryRun enables validating the objects of a state with a server-side dry-run before they are created or updated
+	DryRun bool `env:"STATE_DRY_RUN" envDefault:"true"`
+	// DiffEvents enables emitting an event on the custom resource with the changed fields of each updated object
+	DiffEvents bool `env:"STATE_DIFF_EVENTS" envDefault:"false"`
+	// SyncTimeout is the maximal duration of the sync of a state, the pending API calls of a state which exceeds it
+	// are cancelled and the state fails to sync. Disabled if 0.
+	SyncTimeout time.Duration `env:"STATE_SYNC_TIMEOUT" envDefault:"2m"`
+	// SyncTimeouts are comma separated sync timeouts of states overriding SyncTimeout,
+	// e.g. state-OFED=5m,state-multus-cni=30s, see ParseStateSyncTimeouts
+	SyncTimeouts string `env:"STATE_SYNC_TIMEOUTS" envDefault:""`
+}
+
+// SyncTimeoutOf returns the sync timeout of the state, SyncTimeout is returned if SyncTimeouts is invalid
+func (c *StateConfig) SyncTimeoutOf(stateName string) time.Duration {
+	timeouts, err := ParseStateSyncTimeouts(c.SyncTimeouts)
+	if err != nil {
+		return c.SyncTimeout
+	}
+	if timeout, ok := timeouts[stateName]; ok {
+		return timeout
+	}
+	return c.SyncTimeout
+}
+
+// ParseStateSyncTimeouts parses comma separated sync timeouts of states, each entry is <state name>=<duration>,
+// e.g. state-OFED=5m. A timeout of 0 disables the timeout of the state.
+func ParseStateSyncTimeouts(timeouts string) (map[string]time.Duration, error) {
+	parsed := map[string]time.Duration{}
+	for _, entry := range strings.Split(timeouts, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid state sync timeout %q, must be <state name>=<duration>", entry)
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid sync timeout of state %s: %v", name, err)
+		}
+		if timeout < 0 {
+			return nil, fmt.Errorf("invalid sync timeout of state %s: must not be negative", name)
+		}
+		parsed[name] = timeout
+	}
+	return parsed, nil
 }
 
 // ControllerConfig holds configuration for Operator controllers.
@@ -47,6 +122,73 @@ type ControllerConfig struct {
 	//nolint:stylecheck
 	// Request requeue time(seconds) in case the system still needs to be reconciled
 	RequeueTimeSeconds uint `env:"CONTROLLER_REQUEST_REQUEUE_SECONDS" envDefault:"5"`
+	// ResyncPeriod is the period in which custom resources are reconciled even if no watch event was received,
+	// periodic resync is disabled if zero
+	ResyncPeriod time.Duration `env:"CONTROLLER_RESYNC_PERIOD" envDefault:"0"`
+	// MaxConcurrentReconciles is the maximal number of custom resources reconciled in parallel by the
+	// NicClusterPolicy and the network CRs controllers
+	MaxConcurrentReconciles int `env:"CONTROLLER_MAX_CONCURRENT_RECONCILES" envDefault:"1"`
+	// RateLimiterBaseDelay and RateLimiterMaxDelay bound the exponential delay before a custom resource
+	// which failed to reconcile is retried by the NicClusterPolicy and the network CRs controllers
+	RateLimiterBaseDelay time.Duration `env:"CONTROLLER_RATE_LIMITER_BASE_DELAY" envDefault:"5ms"`
+	RateLimiterMaxDelay  time.Duration `env:"CONTROLLER_RATE_LIMITER_MAX_DELAY" envDefault:"1000s"`
+}
+
+// WebhookConfig holds configuration for the certificate of the webhook server managed by the Operator.
+type WebhookConfig struct {
+	// ManageCertificate enables generating and rotating the serving certificate of the webhook server and
+	// injecting its CA into the ValidatingWebhookConfiguration, as an alternative to cert-manager
+	ManageCertificate bool `env:"WEBHOOK_MANAGE_CERTIFICATE" envDefault:"false"`
+	// CertSecretName is the name of the Secret the certificate is stored in, in the namespace of the Operator
+	CertSecretName string `env:"WEBHOOK_CERT_SECRET_NAME" envDefault:"webhook-server-cert"`
+	// ServiceName is the name of the Service of the webhook server, the certificate is issued for its DNS names
+	ServiceName string `env:"WEBHOOK_SERVICE_NAME" envDefault:"network-operator-webhook-service"`
+	// ConfigurationName is the name of the ValidatingWebhookConfiguration the CA is injected into
+	ConfigurationName string `env:"WEBHOOK_CONFIGURATION_NAME" envDefault:"network-operator-validating-webhook"`
+	// ConversionCRDName is the name of the CRD whose conversion is set to the conversion webhook of the webhook
+	// server, with the CA bundle. Disabled if empty.
+	ConversionCRDName string `env:"WEBHOOK_CONVERSION_CRD_NAME" envDefault:"nicclusterpolicies.mellanox.com"`
+	// CertValidity is the validity of the serving certificate, the CA is valid ten times longer
+	CertValidity time.Duration `env:"WEBHOOK_CERT_VALIDITY" envDefault:"8760h"`
+	// CertRotationThreshold is the remaining validity of a certificate below which it is rotated
+	CertRotationThreshold time.Duration `env:"WEBHOOK_CERT_ROTATION_THRESHOLD" envDefault:"720h"`
+	// CertCheckInterval is the interval in which the certificate is checked for rotation
+	CertCheckInterval time.Duration `env:"WEBHOOK_CERT_CHECK_INTERVAL" envDefault:"1h"`
+}
+
+// OLMConfig holds configuration for the Operator installed by the Operator Lifecycle Manager.
+type OLMConfig struct {
+	// OperatorConditionName is the name of the OperatorCondition of the Operator, set by OLM in the containers of
+	// the deployments of the ClusterServiceVersion. Empty if the Operator is not installed by OLM.
+	OperatorConditionName string `env:"OPERATOR_CONDITION_NAME" envDefault:""`
+	// TargetNamespaces are the comma separated namespaces of the OperatorGroup of the Operator, set from the
+	// olm.targetNamespaces annotation of the Operator pod, empty with the AllNamespaces install mode
+	TargetNamespaces string `env:"OLM_TARGET_NAMESPACES" envDefault:""`
+}
+
+// Installed returns true if the Operator is installed by OLM, the webhook certificates are then managed by OLM
+func (c *OLMConfig) Installed() bool {
+	return c.OperatorConditionName != ""
+}
+
+// TargetNamespace returns the target namespace of the OwnNamespace and SingleNamespace install modes,
+// false is returned with the AllNamespaces and MultiNamespace install modes
+func (c *OLMConfig) TargetNamespace() (string, bool) {
+	namespaces := strings.Split(c.TargetNamespaces, ",")
+	namespace := strings.TrimSpace(namespaces[0])
+	if len(namespaces) != 1 || namespace == "" {
+		return "", false
+	}
+	return namespace, true
+}
+
+// resolveNamespace sets the namespace of the Operator resources to the target namespace of the Operator if it is
+// installed by OLM in the SingleNamespace install mode, since the namespaced permissions of the Operator are only
+// granted in the target namespace. The namespace of the Operator pod is kept with the other install modes.
+func (c *OperatorConfig) resolveNamespace() {
+	if namespace, ok := c.OLM.TargetNamespace(); ok {
+		c.State.NetworkOperatorResourceNamespace = namespace
+	}
 }
 
 // OFEDStateConfig contains extra configuration options for the OFED state which
@@ -60,10 +202,61 @@ type OFEDStateConfig struct {
 }
 
 // FromEnv pulls the operator configuration from the environment.
+// Once Reload was called, the configuration from the environment overridden by the ConfigMap is returned.
+// The returned configuration must not be modified, callers which support changing the configuration at runtime
+// call FromEnv each time they use it instead of keeping the returned value.
 func FromEnv() *OperatorConfig {
 	once.Do(func() {
-		operatorConfig = &OperatorConfig{}
-		_ = env.Parse(operatorConfig)
+		cfg := &OperatorConfig{}
+		_ = env.Parse(cfg)
+		cfg.resolveNamespace()
+		operatorConfig.CompareAndSwap(nil, cfg)
 	})
-	return operatorConfig
+	return operatorConfig.Load()
+}
+
+// Reload parses the configuration from the environment overridden by the data of the ConfigMap and makes it
+// the configuration returned by FromEnv. The current configuration is kept if the data can't be parsed.
+// The configuration used only when the Operator starts, e.g. the namespace or the concurrency of the controllers,
+// requires a restart of the Operator to take effect.
+func Reload(data map[string]string) (*OperatorConfig, error) {
+	return reload(data, nil)
+}
+
+// reload parses and stores the configuration like Reload, the namespace and the manifests directory are taken from
+// the startup configuration, if set, since changing them at runtime would leave the objects already deployed behind
+func reload(data map[string]string, startup *OperatorConfig) (*OperatorConfig, error) {
+	environment := map[string]string{}
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			environment[key] = value
+		}
+	}
+	for key, value := range data {
+		environment[key] = value
+	}
+	cfg := &OperatorConfig{}
+	if err := env.Parse(cfg, env.Options{Environment: environment}); err != nil {
+		return nil, err
+	}
+	cfg.resolveNamespace()
+	if cfg.LogLevel != "" {
+		if _, err := ParseLogLevel(cfg.LogLevel); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := ParseNamedLogLevels(cfg.NamedLogLevels); err != nil {
+		return nil, err
+	}
+	if _, err := ParseStateSyncTimeouts(cfg.State.SyncTimeouts); err != nil {
+		return nil, err
+	}
+	// the name of the ConfigMap can't be changed by the ConfigMap itself
+	cfg.ConfigMapName = FromEnv().ConfigMapName
+	if startup != nil {
+		cfg.State.NetworkOperatorResourceNamespace = startup.State.NetworkOperatorResourceNamespace
+		cfg.State.ManifestBaseDir = startup.State.ManifestBaseDir
+	}
+	operatorConfig.Store(cfg)
+	return cfg, nil
 }
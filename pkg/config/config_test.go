@@ -0,0 +1,81 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseStateSyncTimeouts", func() {
+	It("should parse the state sync timeouts", func() {
+		Expect(ParseStateSyncTimeouts(" state-OFED=5m,state-multus-cni = 0,")).To(Equal(map[string]time.Duration{
+			"state-OFED":       5 * time.Minute,
+			"state-multus-cni": 0,
+		}))
+		Expect(ParseStateSyncTimeouts("")).To(BeEmpty())
+	})
+	It("should reject invalid state sync timeouts", func() {
+		for _, timeouts := range []string{"state-OFED", "=5m", "state-OFED=5", "state-OFED=-1m"} {
+			_, err := ParseStateSyncTimeouts(timeouts)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+})
+
+var _ = Describe("StateConfig", func() {
+	It("should return the sync timeout of a state", func() {
+		c := StateConfig{SyncTimeout: 2 * time.Minute, SyncTimeouts: "state-OFED=10m,state-multus-cni=0"}
+		Expect(c.SyncTimeoutOf("state-OFED")).To(Equal(10 * time.Minute))
+		Expect(c.SyncTimeoutOf("state-multus-cni")).To(BeZero())
+		Expect(c.SyncTimeoutOf("state-rdma-cni")).To(Equal(2 * time.Minute))
+	})
+	It("should return the default sync timeout if the state sync timeouts are invalid", func() {
+		c := StateConfig{SyncTimeout: time.Minute, SyncTimeouts: "state-OFED"}
+		Expect(c.SyncTimeoutOf("state-OFED")).To(Equal(time.Minute))
+	})
+})
+
+var _ = Describe("OLMConfig", func() {
+	DescribeTable("should return the target namespace of the install mode",
+		func(targetNamespaces, namespace string, ok bool) {
+			c := OLMConfig{TargetNamespaces: targetNamespaces}
+			ns, found := c.TargetNamespace()
+			Expect(found).To(Equal(ok))
+			Expect(ns).To(Equal(namespace))
+		},
+		Entry("OwnNamespace or SingleNamespace", "network-operator", "network-operator", true),
+		Entry("AllNamespaces", "", "", false),
+		Entry("MultiNamespace", "ns1,ns2", "", false),
+	)
+	It("should deploy the operands in the target namespace of the SingleNamespace install mode", func() {
+		c := OperatorConfig{
+			State: StateConfig{NetworkOperatorResourceNamespace: "nvidia-network-operator"},
+			OLM:   OLMConfig{TargetNamespaces: "network-operator"},
+		}
+		c.resolveNamespace()
+		Expect(c.State.NetworkOperatorResourceNamespace).To(Equal("network-operator"))
+	})
+	It("should keep the namespace of the Operator with the AllNamespaces install mode", func() {
+		c := OperatorConfig{State: StateConfig{NetworkOperatorResourceNamespace: "nvidia-network-operator"}}
+		c.resolveNamespace()
+		Expect(c.State.NetworkOperatorResourceNamespace).To(Equal("nvidia-network-operator"))
+		Expect(c.OLM.Installed()).To(BeFalse())
+	})
+})
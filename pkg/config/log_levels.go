@@ -0,0 +1,151 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LogLevels holds the log level of the Operator and the log levels of named loggers which override it,
+// both can be changed at runtime
+type LogLevels struct {
+	// Level is the log level of the Operator
+	Level zap.AtomicLevel
+	named atomic.Pointer[map[string]zapcore.Level]
+}
+
+// NewLogLevels creates LogLevels with the given log level of the Operator and no named log levels
+func NewLogLevels(level zap.AtomicLevel) *LogLevels {
+	return &LogLevels{Level: level}
+}
+
+// SetNamed replaces the log levels of named loggers. The level of a name applies to the loggers whose name contains
+// it as a whole segment, e.g. the level of Upgrade applies to controllers.Upgrade.clusterUpgradeManager,
+// the level of the longest matching name is used.
+func (l *LogLevels) SetNamed(levels map[string]zapcore.Level) {
+	l.named.Store(&levels)
+}
+
+// levelFor returns the log level of the named logger, false if no named level matches the logger
+func (l *LogLevels) levelFor(loggerName string) (zapcore.Level, bool) {
+	named := l.named.Load()
+	if named == nil || len(*named) == 0 || loggerName == "" {
+		return 0, false
+	}
+	var level zapcore.Level
+	matched := ""
+	segments := "." + loggerName + "."
+	for name, lvl := range *named {
+		if len(name) > len(matched) && strings.Contains(segments, "."+name+".") {
+			matched, level = name, lvl
+		}
+	}
+	return level, matched != ""
+}
+
+// WrapCore wraps the core to apply the log levels of named loggers, used with zap.WrapCore
+func (l *LogLevels) WrapCore(core zapcore.Core) zapcore.Core {
+	return &namedLevelCore{Core: core, levels: l}
+}
+
+// namedLevelCore is a zapcore.Core which enables the entries of a named logger according to its log level,
+// ignoring the level of the wrapped core
+type namedLevelCore struct {
+	zapcore.Core
+	levels *LogLevels
+}
+
+// Enabled implements zapcore.Core, the level is enabled if enabled for the Operator or for a named logger
+func (c *namedLevelCore) Enabled(level zapcore.Level) bool {
+	if c.Core.Enabled(level) {
+		return true
+	}
+	named := c.levels.named.Load()
+	if named == nil {
+		return false
+	}
+	for _, lvl := range *named {
+		if level >= lvl {
+			return true
+		}
+	}
+	return false
+}
+
+// With implements zapcore.Core
+func (c *namedLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &namedLevelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+// Check implements zapcore.Core
+func (c *namedLevelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	level, ok := c.levels.levelFor(ent.LoggerName)
+	if !ok {
+		return c.Core.Check(ent, ce)
+	}
+	if ent.Level < level {
+		return ce
+	}
+	return ce.AddCore(ent, c.Core)
+}
+
+// ParseLogLevel parses a log level which is debug, info, error or a positive verbosity level,
+// the verbosity level n enables the logs of logger.V(n)
+func ParseLogLevel(level string) (zapcore.Level, error) {
+	switch level {
+	case "debug":
+		return zapcore.DebugLevel, nil
+	case "info":
+		return zapcore.InfoLevel, nil
+	case "error":
+		return zapcore.ErrorLevel, nil
+	}
+	verbosity, err := strconv.Atoi(level)
+	if err != nil || verbosity <= 0 {
+		return zapcore.InfoLevel, fmt.Errorf("invalid log level %q, must be debug, info, error or a positive integer",
+			level)
+	}
+	return zapcore.Level(-verbosity), nil
+}
+
+// ParseNamedLogLevels parses comma separated log levels of named loggers, e.g. Upgrade=debug,state=2
+func ParseNamedLogLevels(levels string) (map[string]zapcore.Level, error) {
+	named := map[string]zapcore.Level{}
+	for _, entry := range strings.Split(levels, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid named log level %q, must be <logger name>=<log level>", entry)
+		}
+		level, err := ParseLogLevel(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level of logger %s: %v", name, err)
+		}
+		named[name] = level
+	}
+	return named, nil
+}
@@ -0,0 +1,112 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+
+	"github.com/go-logr/zapr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var _ = Describe("LogLevels", func() {
+	var (
+		logLevels *LogLevels
+		logs      *observer.ObservedLogs
+	)
+
+	BeforeEach(func() {
+		logLevel := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+		logLevels = NewLogLevels(logLevel)
+		var core zapcore.Core
+		core, logs = observer.New(logLevel)
+		zapLogger := zap.New(core, zap.WrapCore(logLevels.WrapCore))
+		log := zapr.NewLogger(zapLogger)
+
+		logLevels.SetNamed(map[string]zapcore.Level{
+			"Upgrade":                       zapcore.DebugLevel,
+			"Upgrade.clusterUpgradeManager": zapcore.Level(-2),
+			"state":                         zapcore.ErrorLevel,
+		})
+		for _, name := range []string{"controllers", "controllers.Upgrade", "controllers.Upgrade.clusterUpgradeManager",
+			"setup.StateManager.state"} {
+			named := log
+			for _, segment := range strings.Split(name, ".") {
+				named = named.WithName(segment)
+			}
+			named.Info("info")
+			named.V(1).Info("debug")
+			named.V(2).Info("verbose")
+		}
+	})
+
+	It("should apply the level of the longest matching name", func() {
+		messages := map[string][]string{}
+		for _, entry := range logs.All() {
+			messages[entry.LoggerName] = append(messages[entry.LoggerName], entry.Message)
+		}
+		Expect(messages).To(Equal(map[string][]string{
+			"controllers":         {"info"},
+			"controllers.Upgrade": {"info", "debug"},
+			"controllers.Upgrade.clusterUpgradeManager": {"info", "debug", "verbose"},
+		}))
+	})
+	It("should apply the level of the Operator once the named levels are removed", func() {
+		logLevels.SetNamed(nil)
+		_, ok := logLevels.levelFor("controllers.Upgrade")
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("ParseLogLevel", func() {
+	It("should parse the log levels", func() {
+		for level, expected := range map[string]zapcore.Level{
+			"debug": zapcore.DebugLevel,
+			"info":  zapcore.InfoLevel,
+			"error": zapcore.ErrorLevel,
+			"3":     zapcore.Level(-3),
+		} {
+			Expect(ParseLogLevel(level)).To(Equal(expected))
+		}
+	})
+	It("should reject invalid log levels", func() {
+		for _, level := range []string{"", "warning", "0", "-1"} {
+			_, err := ParseLogLevel(level)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+})
+
+var _ = Describe("ParseNamedLogLevels", func() {
+	It("should parse the named log levels", func() {
+		Expect(ParseNamedLogLevels(" controllers.Upgrade=debug,state = 2,")).To(Equal(map[string]zapcore.Level{
+			"controllers.Upgrade": zapcore.DebugLevel,
+			"state":               zapcore.Level(-2),
+		}))
+		Expect(ParseNamedLogLevels("")).To(BeEmpty())
+	})
+	It("should reject invalid named log levels", func() {
+		for _, levels := range []string{"Upgrade", "=debug", "Upgrade=verbose"} {
+			_, err := ParseNamedLogLevels(levels)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+})
@@ -0,0 +1,117 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ConfigMapPollInterval is the interval in which the Watcher checks the ConfigMap for changes
+const ConfigMapPollInterval = 30 * time.Second
+
+// Watcher reloads the configuration of the Operator when the ConfigMap overriding it changes and applies
+// the log levels. The ConfigMap is read with a client which doesn't use the cache of the manager,
+// since the cache only holds the ConfigMaps created by the Operator.
+type Watcher struct {
+	k8sClient       client.Client
+	key             types.NamespacedName
+	logLevels       *LogLevels
+	defaultLogLevel zapcore.Level
+	log             logr.Logger
+	resourceVersion string
+	// startup is the configuration loaded when the Operator started
+	startup *OperatorConfig
+}
+
+// NewWatcher creates a Watcher of the ConfigMap which sets the log levels,
+// the log level of the Operator when the Watcher is created is used if the ConfigMap doesn't set it
+func NewWatcher(c client.Client, namespace, name string, logLevels *LogLevels, log logr.Logger) *Watcher {
+	return &Watcher{
+		k8sClient:       c,
+		key:             types.NamespacedName{Namespace: namespace, Name: name},
+		logLevels:       logLevels,
+		defaultLogLevel: logLevels.Level.Level(),
+		log:             log,
+	}
+}
+
+// NeedLeaderElection implements manager.NeedLeaderElection,
+// the configuration is reloaded by all the replicas
+func (w *Watcher) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable, reloads the configuration on change until the context is cancelled
+func (w *Watcher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(ConfigMapPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := w.Load(ctx); err != nil {
+				// the previous configuration is kept until the ConfigMap is fixed
+				w.log.Error(err, "failed to reload the Operator configuration", "configMap", w.key)
+			}
+		}
+	}
+}
+
+// Load reloads the configuration from the ConfigMap if it changed since the last load,
+// the configuration from the environment is used if the ConfigMap doesn't exist
+func (w *Watcher) Load(ctx context.Context) error {
+	cm := &corev1.ConfigMap{}
+	err := w.k8sClient.Get(ctx, w.key, cm)
+	if err != nil && !apiErrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to get Operator configuration ConfigMap")
+	}
+	if cm.ResourceVersion == w.resourceVersion && w.resourceVersion != "" {
+		return nil
+	}
+	cfg, err := reload(cm.Data, w.startup)
+	if err != nil {
+		return errors.Wrap(err, "invalid Operator configuration")
+	}
+	if w.startup == nil {
+		w.startup = cfg
+	}
+	level := w.defaultLogLevel
+	if cfg.LogLevel != "" {
+		// validated by Reload
+		level, _ = ParseLogLevel(cfg.LogLevel)
+	}
+	// validated by Reload
+	named, _ := ParseNamedLogLevels(cfg.NamedLogLevels)
+	w.logLevels.Level.SetLevel(level)
+	w.logLevels.SetNamed(named)
+	if cm.ResourceVersion != w.resourceVersion {
+		w.log.Info("Operator configuration loaded", "configMap", w.key, "resourceVersion", cm.ResourceVersion,
+			"logLevel", level.String(), "namedLogLevels", cfg.NamedLogLevels)
+	}
+	w.resourceVersion = cm.ResourceVersion
+	return nil
+}
@@ -0,0 +1,145 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Operator configuration Watcher", func() {
+	var (
+		ctx       context.Context
+		k8sClient client.Client
+		logLevel  zap.AtomicLevel
+		logLevels *LogLevels
+		w         *Watcher
+		cm        *corev1.ConfigMap
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		k8sClient = fake.NewClientBuilder().Build()
+		logLevel = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+		logLevels = NewLogLevels(logLevel)
+		w = NewWatcher(k8sClient, "nvidia-network-operator", "network-operator-config", logLevels, logr.Discard())
+		cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "nvidia-network-operator", Name: "network-operator-config"}}
+	})
+
+	AfterEach(func() {
+		_, err := Reload(nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should use the configuration from the environment if the ConfigMap doesn't exist", func() {
+		Expect(w.Load(ctx)).To(Succeed())
+		Expect(FromEnv().State.ManifestBaseDir).To(Equal("./manifests"))
+		Expect(FromEnv().State.DryRun).To(BeTrue())
+		Expect(logLevel.Level()).To(Equal(zapcore.InfoLevel))
+	})
+	It("should override the configuration from the environment", func() {
+		cm.Data = map[string]string{
+			"STATE_MANIFEST_BASE_DIR":  "/manifests",
+			"STATE_DRY_RUN":            "false",
+			"CONTROLLER_RESYNC_PERIOD": "10m",
+			"LOG_LEVEL":                "debug",
+		}
+		Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+		Expect(w.Load(ctx)).To(Succeed())
+		Expect(FromEnv().State.ManifestBaseDir).To(Equal("/manifests"))
+		Expect(FromEnv().State.DryRun).To(BeFalse())
+		Expect(FromEnv().Controller.ResyncPeriod).To(Equal(10 * time.Minute))
+		Expect(logLevel.Level()).To(Equal(zapcore.DebugLevel))
+	})
+	It("should reload the configuration when the ConfigMap changes", func() {
+		cm.Data = map[string]string{"LOG_LEVEL": "2", "STATE_DIFF_EVENTS": "true"}
+		Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+		Expect(w.Load(ctx)).To(Succeed())
+		Expect(logLevel.Level()).To(Equal(zapcore.Level(-2)))
+		Expect(FromEnv().State.DiffEvents).To(BeTrue())
+
+		cm.Data = map[string]string{"STATE_DIFF_EVENTS": "false"}
+		Expect(k8sClient.Update(ctx, cm)).To(Succeed())
+		Expect(w.Load(ctx)).To(Succeed())
+		Expect(logLevel.Level()).To(Equal(zapcore.InfoLevel))
+		Expect(FromEnv().State.DiffEvents).To(BeFalse())
+	})
+	It("should set the log levels of named loggers", func() {
+		cm.Data = map[string]string{"LOG_LEVELS": "controllers.Upgrade=debug, state=2"}
+		Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+		Expect(w.Load(ctx)).To(Succeed())
+		level, ok := logLevels.levelFor("controllers.Upgrade.clusterUpgradeManager")
+		Expect(ok).To(BeTrue())
+		Expect(level).To(Equal(zapcore.DebugLevel))
+		level, ok = logLevels.levelFor("setup.StateManager.state.state-OFED")
+		Expect(ok).To(BeTrue())
+		Expect(level).To(Equal(zapcore.Level(-2)))
+
+		cm.Data = nil
+		Expect(k8sClient.Update(ctx, cm)).To(Succeed())
+		Expect(w.Load(ctx)).To(Succeed())
+		_, ok = logLevels.levelFor("controllers.Upgrade")
+		Expect(ok).To(BeFalse())
+	})
+	It("should keep the previous configuration if the ConfigMap is invalid", func() {
+		cm.Data = map[string]string{"STATE_SYNC_WORKERS": "4"}
+		Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+		Expect(w.Load(ctx)).To(Succeed())
+
+		for _, data := range []map[string]string{
+			{"STATE_SYNC_WORKERS": "four"},
+			{"LOG_LEVEL": "verbose"},
+			{"LOG_LEVELS": "controllers.Upgrade"},
+			{"STATE_SYNC_TIMEOUTS": "state-OFED"},
+		} {
+			cm.Data = data
+			Expect(k8sClient.Update(ctx, cm)).To(Succeed())
+			Expect(w.Load(ctx)).NotTo(Succeed())
+			Expect(FromEnv().State.SyncWorkers).To(Equal(4))
+		}
+	})
+	It("should keep the namespace and the manifests directory of the startup configuration", func() {
+		cm.Data = map[string]string{"POD_NAMESPACE": "network-operator", "STATE_MANIFEST_BASE_DIR": "/manifests"}
+		Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+		Expect(w.Load(ctx)).To(Succeed())
+		Expect(FromEnv().State.NetworkOperatorResourceNamespace).To(Equal("network-operator"))
+
+		cm.Data = map[string]string{"POD_NAMESPACE": "other", "STATE_MANIFEST_BASE_DIR": "/other", "LOG_LEVEL": "error"}
+		Expect(k8sClient.Update(ctx, cm)).To(Succeed())
+		Expect(w.Load(ctx)).To(Succeed())
+		Expect(logLevel.Level()).To(Equal(zapcore.ErrorLevel))
+		Expect(FromEnv().State.NetworkOperatorResourceNamespace).To(Equal("network-operator"))
+		Expect(FromEnv().State.ManifestBaseDir).To(Equal("/manifests"))
+	})
+	It("should not override the name of the ConfigMap", func() {
+		cm.Data = map[string]string{"OPERATOR_CONFIG_MAP_NAME": "other"}
+		Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+		Expect(w.Load(ctx)).To(Succeed())
+		Expect(FromEnv().ConfigMapName).To(BeEmpty())
+	})
+})
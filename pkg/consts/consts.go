@@ -36,14 +36,33 @@ const (
 	OfedDriverLabel = "nvidia.com/ofed-driver"
 	// StateLabel is the label key describing which state the operator created a Kubernetes object from.
 	StateLabel = "nvidia.network-operator.state"
+	// StateChecksumLabel is the label key for the checksum of the set of objects rendered by a state,
+	// objects of the state with a different checksum are no longer rendered and are garbage collected.
+	StateChecksumLabel = "nvidia.network-operator.state-checksum"
+	// PolicyLabel is the label key for the name of the NicClusterPolicy an object was created for,
+	// it is set only on the objects of the policies other than NicClusterPolicyResourceName.
+	PolicyLabel = "nvidia.network-operator.policy"
 	// DefaultCniBinDirectory is the default location of the CNI binaries on a host.
 	DefaultCniBinDirectory = "/opt/cni/bin"
 	// OcpCniBinDirectory is the location of the CNI binaries on an OpenShift host.
 	OcpCniBinDirectory = "/var/lib/cni/bin"
+	// DefaultCniConfDirectory is the default location of the CNI configurations on a host.
+	DefaultCniConfDirectory = "/etc/cni/net.d"
 	// OfedDriverSkipDrainLabelSelector contains labelselector which is used to indicate
 	// that network-operator pod should be skipped during the drain operation which
 	// is executed by the upgrade controller.
 	OfedDriverSkipDrainLabelSelector = "nvidia.com/ofed-driver-upgrade-drain.skip!=true"
 	// ControllerRevisionAnnotation is the key for annotations used to store revision information on Kubernetes objects.
 	ControllerRevisionAnnotation = "nvidia.network-operator.revision"
+	// ManifestOverlayLabel is the label key for ConfigMaps overlaying the manifests of a state,
+	// its value is the name of the manifests directory of the state.
+	ManifestOverlayLabel = "nvidia.network-operator.manifest-overlay"
+	// SkipDeviceSelectorValidationAnnotation is the annotation which disables the warnings about device plugin
+	// selectors which don't match NVIDIA devices when set to "true" on the NicClusterPolicy.
+	SkipDeviceSelectorValidationAnnotation = "nvidia.network-operator.skip-device-selector-validation"
+	// NicClusterPolicyFinalizer is the finalizer which holds the deletion of the NicClusterPolicy
+	// until the objects of its states are removed in order.
+	NicClusterPolicyFinalizer = "mellanox.com/nic-cluster-policy-teardown"
+	// FieldManager is the field manager used by the operator to server-side apply Kubernetes objects.
+	FieldManager = "network-operator"
 )
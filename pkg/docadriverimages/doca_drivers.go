@@ -21,6 +21,9 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -39,10 +42,34 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const (
+	// versionChannelLatest is the prefix of version channels which are resolved to the newest driver version
+	versionChannelLatest = "latest"
+	// versionChannelWildcard is the suffix of a version channel matching any minor version
+	versionChannelWildcard = ".xx"
+)
+
+var (
+	// versionChannelRegex matches version channels, e.g. latest, latest-24, latest-24.xx or latest-24.04
+	versionChannelRegex = regexp.MustCompile(`^latest(-\d+(\.(\d+|xx))?)?$`)
+	// tagVersionRegex matches the driver version of the tag of an image compiling the driver on the node,
+	// e.g. 24.04-0.6.6.0-0 in 24.04-0.6.6.0-0-ubuntu22.04-amd64
+	tagVersionRegex = regexp.MustCompile(`^(\d+\.\d+-\d+(?:\.\d+)*(?:-\d+)?)-[a-z]`)
+)
+
+// IsVersionChannel returns true if the provided driver version is a version channel
+// which is resolved to the newest driver version available in the container registry
+func IsVersionChannel(version string) bool {
+	return versionChannelRegex.MatchString(version)
+}
+
 // Provider provides interface to check the DOCA driver images
 type Provider interface {
 	// TagExists returns true if DOCA driver image with provided tag exists
 	TagExists(tag string) bool
+	// ResolveVersion returns the newest driver version of the DOCA driver images matching the version channel,
+	// returns false if no such version exists
+	ResolveVersion(channel string) (string, bool)
 	// SetImageSpec sets the Container registry details
 	SetImageSpec(*mellanoxv1alpha1.ImageSpec)
 }
@@ -83,6 +110,14 @@ func (p *provider) TagExists(tag string) bool {
 	return false
 }
 
+// ResolveVersion returns the newest driver version of the DOCA driver images matching the version channel,
+// returns false if no such version exists
+func (p *provider) ResolveVersion(channel string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return resolveVersion(channel, p.tags)
+}
+
 // SetImageSpec sets the Container registry details
 func (p *provider) SetImageSpec(spec *mellanoxv1alpha1.ImageSpec) {
 	p.mu.Lock()
@@ -98,7 +133,8 @@ func (p *provider) SetImageSpec(spec *mellanoxv1alpha1.ImageSpec) {
 		p.mu.Unlock()
 		return
 	}
-	p.docaImageSpec = spec
+	// keep a copy, the spec may be modified by the caller after it was set
+	p.docaImageSpec = spec.DeepCopy()
 	p.mu.Unlock()
 	p.retrieveTags()
 }
@@ -144,3 +180,43 @@ func (p *provider) retrieveTags() {
 	}
 	p.tags = tags
 }
+
+// resolveVersion returns the newest driver version of the tags matching the version channel
+func resolveVersion(channel string, tags []string) (string, bool) {
+	if !IsVersionChannel(channel) {
+		return "", false
+	}
+	prefix := strings.TrimPrefix(strings.TrimPrefix(channel, versionChannelLatest), "-")
+	prefix = strings.TrimSuffix(prefix, versionChannelWildcard)
+	latest := ""
+	for _, tag := range tags {
+		match := tagVersionRegex.FindStringSubmatch(tag)
+		if match == nil {
+			continue
+		}
+		version := match[1]
+		if prefix != "" && version != prefix &&
+			!strings.HasPrefix(version, prefix+".") && !strings.HasPrefix(version, prefix+"-") {
+			continue
+		}
+		if latest == "" || compareVersions(version, latest) > 0 {
+			latest = version
+		}
+	}
+	return latest, latest != ""
+}
+
+// compareVersions compares the numeric components of two driver versions,
+// returns a negative number if a < b, zero if a == b and a positive number if a > b
+func compareVersions(a, b string) int {
+	split := func(r rune) bool { return r == '.' || r == '-' }
+	aParts, bParts := strings.FieldsFunc(a, split), strings.FieldsFunc(b, split)
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, _ := strconv.Atoi(aParts[i])
+		bNum, _ := strconv.Atoi(bParts[i])
+		if aNum != bNum {
+			return aNum - bNum
+		}
+	}
+	return len(aParts) - len(bParts)
+}
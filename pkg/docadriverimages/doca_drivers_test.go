@@ -0,0 +1,74 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docadriverimages
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DOCA driver images", func() {
+	DescribeTable("IsVersionChannel",
+		func(version string, expected bool) {
+			Expect(IsVersionChannel(version)).To(Equal(expected))
+		},
+		Entry("latest", "latest", true),
+		Entry("major", "latest-24", true),
+		Entry("major and minor", "latest-24.04", true),
+		Entry("major and any minor", "latest-24.xx", true),
+		Entry("version", "24.04-0.6.6.0-0", false),
+		Entry("invalid channel", "latest-foo", false),
+		Entry("invalid prefix", "newest-24.04", false),
+	)
+
+	tags := []string{
+		"23.10-0.5.5.0-ubuntu22.04-amd64",
+		"24.01-0.3.3.1-10-ubuntu22.04-amd64",
+		"24.04-0.6.6.0-0-ubuntu22.04-amd64",
+		"24.04-0.6.6.0-0-rhcos4.15-amd64",
+		"24.04-0.7.0.0-2-ubuntu22.04-amd64",
+		"24.04-0.7.0.0-2-ubuntu22.04-arm64",
+		"24.10-0.10.1.0-0-5.15.0-78-generic-ubuntu22.04-amd64",
+		"latest",
+		"sha256-0123456789abcdef.sig",
+	}
+
+	DescribeTable("resolveVersion",
+		func(channel string, expectedVersion string, expectedFound bool) {
+			version, found := resolveVersion(channel, tags)
+			Expect(found).To(Equal(expectedFound))
+			Expect(version).To(Equal(expectedVersion))
+		},
+		Entry("latest", "latest", "24.04-0.7.0.0-2", true),
+		Entry("major", "latest-24", "24.04-0.7.0.0-2", true),
+		Entry("any minor", "latest-23.xx", "23.10-0.5.5.0", true),
+		Entry("major and minor", "latest-24.01", "24.01-0.3.3.1-10", true),
+		Entry("precompiled images are ignored", "latest-24.10", "", false),
+		Entry("no matching version", "latest-25", "", false),
+		Entry("not a channel", "24.04-0.6.6.0-0", "", false),
+	)
+
+	DescribeTable("compareVersions",
+		func(a, b string, comparator string) {
+			Expect(compareVersions(a, b)).To(BeNumerically(comparator, 0))
+		},
+		Entry("equal", "24.04-0.6.6.0-0", "24.04-0.6.6.0-0", "=="),
+		Entry("greater minor", "24.10-0.5.5.0", "24.04-0.6.6.0", ">"),
+		Entry("lower build", "24.04-0.6.6.0", "24.04-0.6.10.0", "<"),
+		Entry("greater suffix", "24.04-0.6.6.0-1", "24.04-0.6.6.0", ">"),
+	)
+})
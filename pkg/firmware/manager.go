@@ -0,0 +1,314 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package firmware manages the firmware of the Mellanox NICs of the nodes.
+
+The firmware of the nodes selected by a NicFirmwarePolicy is upgraded node by node, the progress of each node
+is reported in the UpgradeStateLabelKey label:
+
+	"" or upgrade-done -> upgrade-required -> drain-required -> firmware-update-required ->
+		reboot-required -> validation-required -> upgrade-done
+
+Any failure moves the node to the upgrade-failed state, the upgrade of the node is retried
+once the label is removed by the admin.
+The current firmware version of the node is discovered by nic-feature-discovery.
+*/
+package firmware
+
+import (
+	"context"
+	"fmt"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubectl/pkg/drain"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/config"
+	"github.com/Mellanox/network-operator/pkg/consts"
+	"github.com/Mellanox/network-operator/pkg/nodeinfo"
+	"github.com/Mellanox/network-operator/pkg/upgrade"
+)
+
+const (
+	// UpgradeStateLabelKey is the key of the node label which reports the firmware upgrade state of the node
+	UpgradeStateLabelKey = "network.nvidia.com/nic-fw-upgrade-state"
+	// RebootBootIDAnnotationKey is the key of the node annotation which holds the boot ID of the node
+	// at the time its reboot was requested by the firmware upgrade
+	RebootBootIDAnnotationKey = "network.nvidia.com/nic-fw-upgrade.reboot-boot-id"
+
+	// UpgradeStateUpgradeRequired is set when the firmware of the node should be upgraded
+	UpgradeStateUpgradeRequired = "upgrade-required"
+	// UpgradeStateDrainRequired is set when the node is being cordoned and drained
+	UpgradeStateDrainRequired = "drain-required"
+	// UpgradeStateFirmwareUpdateRequired is set when the firmware update pod is running on the node
+	UpgradeStateFirmwareUpdateRequired = "firmware-update-required"
+	// UpgradeStateRebootRequired is set when the node is rebooted to activate the new firmware
+	UpgradeStateRebootRequired = "reboot-required"
+	// UpgradeStateValidationRequired is set when the node waits for the new firmware version to be discovered
+	UpgradeStateValidationRequired = "validation-required"
+	// UpgradeStateDone is set when the firmware of the node is up to date
+	UpgradeStateDone = "upgrade-done"
+	// UpgradeStateFailed is set when the firmware upgrade of the node failed
+	UpgradeStateFailed = "upgrade-failed"
+
+	// firmwareVersionEnv is the environment variable of the update container with the desired firmware version
+	firmwareVersionEnv  = "FW_VERSION"
+	updatePodNamePrefix = "nic-fw-update"
+	rebootPodNamePrefix = "nic-fw-reboot"
+)
+
+// inProgressStates are the states of the nodes which take an upgrade slot
+var inProgressStates = []string{
+	UpgradeStateDrainRequired, UpgradeStateFirmwareUpdateRequired,
+	UpgradeStateRebootRequired, UpgradeStateValidationRequired,
+}
+
+// defaultRebootSpec is used when the reboot is not configured in the NicFirmwarePolicy
+var defaultRebootSpec = &mellanoxv1alpha1.RebootSpec{
+	Enable:        true,
+	Method:        mellanoxv1alpha1.RebootMethodHelperPod,
+	Image:         "busybox:1.36",
+	TimeoutSecond: 1200,
+}
+
+// Manager upgrades the firmware of the nodes according to the NicFirmwarePolicy,
+// the nodes are drained and rebooted by the managers of the driver upgrade
+type Manager struct {
+	k8sInterface  kubernetes.Interface
+	log           logr.Logger
+	eventRecorder record.EventRecorder
+	stateProvider *nodeStateProvider
+	drainManager  *upgrade.DrainManager
+	rebootManager *upgrade.RebootManager
+}
+
+// NewManager creates a firmware upgrade Manager
+func NewManager(k8sInterface kubernetes.Interface, log logr.Logger, eventRecorder record.EventRecorder) *Manager {
+	stateProvider := &nodeStateProvider{k8sInterface: k8sInterface, log: log}
+	return &Manager{
+		k8sInterface:  k8sInterface,
+		log:           log,
+		eventRecorder: eventRecorder,
+		stateProvider: stateProvider,
+		drainManager:  upgrade.NewDrainManager(k8sInterface, nil, stateProvider, log, eventRecorder),
+		rebootManager: upgrade.NewRebootManager(k8sInterface, stateProvider, log, eventRecorder,
+			RebootBootIDAnnotationKey, rebootPodNamePrefix),
+	}
+}
+
+// GetUpdatePodName returns the name of the firmware update pod of the node
+func GetUpdatePodName(nodeName string) string {
+	return fmt.Sprintf("%s-%s", updatePodNamePrefix, nodeName)
+}
+
+// ApplyState moves the firmware upgrade of each node one step forward and returns the firmware status of the nodes.
+// The nodes are expected to be selected by the policy.
+func (m *Manager) ApplyState(ctx context.Context, policy *mellanoxv1alpha1.NicFirmwarePolicy,
+	nodes []*corev1.Node) ([]mellanoxv1alpha1.NicFirmwareNodeStatus, error) {
+	nodeStates := map[string][]*corev1.Node{}
+	for _, node := range nodes {
+		state := node.Labels[UpgradeStateLabelKey]
+		nodeStates[state] = append(nodeStates[state], node)
+	}
+
+	for _, state := range []string{"", UpgradeStateDone} {
+		for _, node := range nodeStates[state] {
+			if err := m.processIdleNode(ctx, node, policy.Spec.FirmwareVersion); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := m.processUpgradeRequiredNodes(ctx, nodeStates, policy.Spec.MaxParallelUpgrades); err != nil {
+		return nil, err
+	}
+	m.drainManager.SetDrainOptions(policy.Spec.DrainSpec)
+	if err := m.drainManager.ScheduleNodesDrain(ctx, &upgradeLib.DrainConfiguration{
+		Spec:  mellanoxv1alpha1.GetFirmwareDrainSpec(policy.Spec.DrainSpec),
+		Nodes: nodeStates[UpgradeStateDrainRequired],
+	}); err != nil {
+		return nil, err
+	}
+	for _, node := range nodeStates[UpgradeStateFirmwareUpdateRequired] {
+		if err := m.processFirmwareUpdateRequiredNode(ctx, node, policy); err != nil {
+			return nil, err
+		}
+	}
+	rebootSpec := policy.Spec.Reboot
+	if rebootSpec == nil {
+		rebootSpec = defaultRebootSpec
+	}
+	for _, node := range nodeStates[UpgradeStateRebootRequired] {
+		if err := m.rebootManager.ProcessRebootRequiredNode(ctx, node, rebootSpec); err != nil {
+			return nil, err
+		}
+	}
+	for _, node := range nodeStates[UpgradeStateValidationRequired] {
+		if err := m.processValidationRequiredNode(ctx, node, policy.Spec.FirmwareVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]mellanoxv1alpha1.NicFirmwareNodeStatus, 0, len(nodes))
+	for _, node := range nodes {
+		statuses = append(statuses, mellanoxv1alpha1.NicFirmwareNodeStatus{
+			Name:            node.Name,
+			FirmwareVersion: node.Labels[nodeinfo.NodeLabelNicFirmwareVer],
+			UpgradeState:    node.Labels[UpgradeStateLabelKey],
+		})
+	}
+	return statuses, nil
+}
+
+// processIdleNode moves the node whose firmware is not in the desired version to the upgrade-required state
+func (m *Manager) processIdleNode(ctx context.Context, node *corev1.Node, firmwareVersion string) error {
+	currentVersion, discovered := node.Labels[nodeinfo.NodeLabelNicFirmwareVer]
+	if !discovered {
+		m.log.V(consts.LogLevelDebug).Info("Firmware version of the node is not discovered yet", "node", node.Name)
+		return nil
+	}
+	newState := UpgradeStateDone
+	if currentVersion != firmwareVersion {
+		newState = UpgradeStateUpgradeRequired
+	}
+	if node.Labels[UpgradeStateLabelKey] == newState {
+		return nil
+	}
+	return m.stateProvider.ChangeNodeUpgradeState(ctx, node, newState)
+}
+
+// processUpgradeRequiredNodes starts the upgrade of the nodes in the upgrade-required state
+// as long as upgrade slots are available, the nodes with a driver upgrade in progress are skipped
+func (m *Manager) processUpgradeRequiredNodes(
+	ctx context.Context, nodeStates map[string][]*corev1.Node, maxParallelUpgrades int) error {
+	inProgress := 0
+	for _, state := range inProgressStates {
+		inProgress += len(nodeStates[state])
+	}
+	for _, node := range nodeStates[UpgradeStateUpgradeRequired] {
+		if maxParallelUpgrades > 0 && inProgress >= maxParallelUpgrades {
+			m.log.V(consts.LogLevelInfo).Info("No upgrade slots available", "inProgress", inProgress)
+			return nil
+		}
+		if upgrade.IsUpgradeInProgress(node, upgradeLib.GetUpgradeStateLabelKey()) {
+			m.log.V(consts.LogLevelInfo).Info("Driver upgrade of the node is in progress, postponing firmware upgrade",
+				"node", node.Name)
+			continue
+		}
+		if err := m.stateProvider.ChangeNodeUpgradeState(ctx, node, UpgradeStateDrainRequired); err != nil {
+			return err
+		}
+		nodeStates[UpgradeStateDrainRequired] = append(nodeStates[UpgradeStateDrainRequired], node)
+		inProgress++
+	}
+	return nil
+}
+
+// processFirmwareUpdateRequiredNode runs the firmware update pod on the node and moves the node
+// to the reboot-required state once the pod succeeds, or to the upgrade-failed state if it fails
+func (m *Manager) processFirmwareUpdateRequiredNode(ctx context.Context, node *corev1.Node,
+	policy *mellanoxv1alpha1.NicFirmwarePolicy) error {
+	pods := m.k8sInterface.CoreV1().Pods(config.FromEnv().State.NetworkOperatorResourceNamespace)
+	pod, err := pods.Get(ctx, GetUpdatePodName(node.Name), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		m.log.V(consts.LogLevelInfo).Info("Starting firmware update of the node", "node", node.Name,
+			"version", policy.Spec.FirmwareVersion)
+		m.logEvent(node, corev1.EventTypeNormal,
+			fmt.Sprintf("Updating the NIC firmware to version %s", policy.Spec.FirmwareVersion))
+		_, err = pods.Create(ctx, newUpdatePod(node, policy), metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	var newState string
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		newState = UpgradeStateRebootRequired
+		m.logEvent(node, corev1.EventTypeNormal, "Successfully updated the NIC firmware")
+	case corev1.PodFailed:
+		newState = UpgradeStateFailed
+		m.logEvent(node, corev1.EventTypeWarning, "Failed to update the NIC firmware")
+	default:
+		return nil
+	}
+	err = pods.Delete(ctx, pod.Name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return m.stateProvider.ChangeNodeUpgradeState(ctx, node, newState)
+}
+
+// processValidationRequiredNode uncordons the node and moves it to the upgrade-done state
+// once nic-feature-discovery reports the desired firmware version
+func (m *Manager) processValidationRequiredNode(ctx context.Context, node *corev1.Node, firmwareVersion string) error {
+	if node.Labels[nodeinfo.NodeLabelNicFirmwareVer] != firmwareVersion {
+		m.log.V(consts.LogLevelDebug).Info("Waiting for the new firmware version to be discovered", "node", node.Name)
+		return nil
+	}
+	helper := &drain.Helper{Ctx: ctx, Client: m.k8sInterface}
+	if err := drain.RunCordonOrUncordon(helper, node, false); err != nil {
+		return fmt.Errorf("failed to uncordon node %s: %v", node.Name, err)
+	}
+	m.logEvent(node, corev1.EventTypeNormal, fmt.Sprintf("Successfully upgraded the NIC firmware to %s", firmwareVersion))
+	return m.stateProvider.ChangeNodeUpgradeState(ctx, node, UpgradeStateDone)
+}
+
+func (m *Manager) logEvent(node *corev1.Node, eventType, message string) {
+	if m.eventRecorder != nil {
+		m.eventRecorder.Event(node, eventType, "NicFirmwareUpgrade", message)
+	}
+}
+
+// newUpdatePod creates the privileged pod which updates the firmware of the NICs of the node,
+// with the image of the CPU architecture of the node
+func newUpdatePod(node *corev1.Node, policy *mellanoxv1alpha1.NicFirmwarePolicy) *corev1.Pod {
+	spec := policy.Spec.ImageSpec.ForArch(node.Labels[nodeinfo.NodeLabelCPUArch])
+	env := append([]corev1.EnvVar{{Name: firmwareVersionEnv, Value: policy.Spec.FirmwareVersion}}, spec.Env...)
+	pullSecrets := make([]corev1.LocalObjectReference, 0, len(spec.ImagePullSecrets))
+	for _, secret := range spec.ImagePullSecrets {
+		pullSecrets = append(pullSecrets, corev1.LocalObjectReference{Name: secret})
+	}
+	privileged := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetUpdatePodName(node.Name),
+			Namespace: config.FromEnv().State.NetworkOperatorResourceNamespace,
+			Labels:    map[string]string{"app": updatePodNamePrefix},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:         node.Name,
+			HostPID:          true,
+			RestartPolicy:    corev1.RestartPolicyNever,
+			Tolerations:      []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			ImagePullSecrets: pullSecrets,
+			Containers: []corev1.Container{{
+				Name:            "firmware-update",
+				Image:           spec.ImagePath(),
+				ImagePullPolicy: spec.ImagePullPolicy,
+				Env:             env,
+				SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+			}},
+		},
+	}
+}
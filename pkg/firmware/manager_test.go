@@ -0,0 +1,210 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package firmware
+
+import (
+	"context"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/config"
+	"github.com/Mellanox/network-operator/pkg/nodeinfo"
+)
+
+const testFirmwareVersion = "28.39.1002"
+
+func newTestNode(name, firmwareVersion, state string) *corev1.Node {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}}}
+	if firmwareVersion != "" {
+		node.Labels[nodeinfo.NodeLabelNicFirmwareVer] = firmwareVersion
+	}
+	if state != "" {
+		node.Labels[UpgradeStateLabelKey] = state
+	}
+	return node
+}
+
+func getNode(k8sInterface *fake.Clientset, name string) *corev1.Node {
+	node, err := k8sInterface.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
+	Expect(err).NotTo(HaveOccurred())
+	return node
+}
+
+func getState(k8sInterface *fake.Clientset, name string) string {
+	return getNode(k8sInterface, name).Labels[UpgradeStateLabelKey]
+}
+
+var _ = Describe("Firmware manager tests", func() {
+	var (
+		k8sInterface *fake.Clientset
+		manager      *Manager
+		policy       *mellanoxv1alpha1.NicFirmwarePolicy
+		nodes        []*corev1.Node
+	)
+
+	BeforeEach(func() {
+		policy = &mellanoxv1alpha1.NicFirmwarePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "fw"},
+			Spec: mellanoxv1alpha1.NicFirmwarePolicySpec{
+				FirmwareVersion:     testFirmwareVersion,
+				ImageSpec:           mellanoxv1alpha1.ImageSpec{Repository: "repo", Image: "fw-update", Version: "v1"},
+				MaxParallelUpgrades: 1,
+			},
+		}
+	})
+
+	applyState := func() []mellanoxv1alpha1.NicFirmwareNodeStatus {
+		objects := make([]runtime.Object, 0, len(nodes))
+		for _, node := range nodes {
+			objects = append(objects, node.DeepCopy())
+		}
+		k8sInterface = fake.NewSimpleClientset(objects...)
+		manager = NewManager(k8sInterface, log.Log, nil)
+		statuses, err := manager.ApplyState(context.TODO(), policy, nodes)
+		Expect(err).NotTo(HaveOccurred())
+		return statuses
+	}
+
+	It("should detect the nodes which require firmware upgrade", func() {
+		nodes = []*corev1.Node{
+			newTestNode("node1", "", ""),
+			newTestNode("node2", testFirmwareVersion, ""),
+			newTestNode("node3", "28.38.1002", ""),
+			newTestNode("node4", "28.38.1002", UpgradeStateDone),
+		}
+		statuses := applyState()
+		Expect(getState(k8sInterface, "node1")).To(BeEmpty())
+		Expect(getState(k8sInterface, "node2")).To(Equal(UpgradeStateDone))
+		Expect(getState(k8sInterface, "node3")).To(Equal(UpgradeStateUpgradeRequired))
+		Expect(getState(k8sInterface, "node4")).To(Equal(UpgradeStateUpgradeRequired))
+		Expect(statuses).To(ContainElement(mellanoxv1alpha1.NicFirmwareNodeStatus{
+			Name: "node3", FirmwareVersion: "28.38.1002", UpgradeState: UpgradeStateUpgradeRequired}))
+	})
+
+	It("should not upgrade more nodes than allowed in parallel", func() {
+		nodes = []*corev1.Node{
+			newTestNode("node1", "28.38.1002", UpgradeStateValidationRequired),
+			newTestNode("node2", "28.38.1002", UpgradeStateUpgradeRequired),
+		}
+		applyState()
+		Expect(getState(k8sInterface, "node2")).To(Equal(UpgradeStateUpgradeRequired))
+	})
+
+	It("should postpone the upgrade of the nodes with driver upgrade in progress", func() {
+		nodes = []*corev1.Node{newTestNode("node1", "28.38.1002", UpgradeStateUpgradeRequired)}
+		nodes[0].Labels[upgradeLib.GetUpgradeStateLabelKey()] = upgradeLib.UpgradeStateDrainRequired
+		applyState()
+		Expect(getState(k8sInterface, "node1")).To(Equal(UpgradeStateUpgradeRequired))
+	})
+
+	It("should drain the node and start the firmware update", func() {
+		nodes = []*corev1.Node{newTestNode("node1", "28.38.1002", UpgradeStateUpgradeRequired)}
+		applyState()
+		Eventually(func() string { return getState(k8sInterface, "node1") }).
+			Should(Equal(UpgradeStateFirmwareUpdateRequired))
+		Expect(getNode(k8sInterface, "node1").Spec.Unschedulable).To(BeTrue())
+	})
+
+	Context("firmware update", func() {
+		var namespace string
+
+		BeforeEach(func() {
+			namespace = config.FromEnv().State.NetworkOperatorResourceNamespace
+			nodes = []*corev1.Node{newTestNode("node1", "28.38.1002", UpgradeStateFirmwareUpdateRequired)}
+		})
+
+		It("should create the firmware update pod", func() {
+			applyState()
+			pod, err := k8sInterface.CoreV1().Pods(namespace).Get(
+				context.TODO(), GetUpdatePodName("node1"), metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pod.Spec.NodeName).To(Equal("node1"))
+			Expect(pod.Spec.Containers[0].Image).To(Equal("repo/fw-update:v1"))
+			Expect(pod.Spec.Containers[0].Env).To(ContainElement(
+				corev1.EnvVar{Name: firmwareVersionEnv, Value: testFirmwareVersion}))
+			Expect(getState(k8sInterface, "node1")).To(Equal(UpgradeStateFirmwareUpdateRequired))
+		})
+
+		DescribeTable("should move the node to the next state once the update pod completes",
+			func(phase corev1.PodPhase, expectedState string) {
+				k8sInterface = fake.NewSimpleClientset(nodes[0].DeepCopy())
+				manager = NewManager(k8sInterface, log.Log, nil)
+				pod := newUpdatePod(nodes[0], policy)
+				pod.Status.Phase = phase
+				_, err := k8sInterface.CoreV1().Pods(namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = manager.ApplyState(context.TODO(), policy, nodes)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getState(k8sInterface, "node1")).To(Equal(expectedState))
+				_, err = k8sInterface.CoreV1().Pods(namespace).Get(
+					context.TODO(), GetUpdatePodName("node1"), metav1.GetOptions{})
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			},
+			Entry("succeeded", corev1.PodSucceeded, UpgradeStateRebootRequired),
+			Entry("failed", corev1.PodFailed, UpgradeStateFailed),
+		)
+	})
+
+	Context("validation", func() {
+		It("should wait for the new firmware version to be discovered", func() {
+			nodes = []*corev1.Node{newTestNode("node1", "28.38.1002", UpgradeStateValidationRequired)}
+			applyState()
+			Expect(getState(k8sInterface, "node1")).To(Equal(UpgradeStateValidationRequired))
+		})
+
+		It("should uncordon the node once the new firmware version is discovered", func() {
+			nodes = []*corev1.Node{newTestNode("node1", testFirmwareVersion, UpgradeStateValidationRequired)}
+			nodes[0].Spec.Unschedulable = true
+			applyState()
+			node := getNode(k8sInterface, "node1")
+			Expect(node.Labels[UpgradeStateLabelKey]).To(Equal(UpgradeStateDone))
+			Expect(node.Spec.Unschedulable).To(BeFalse())
+		})
+	})
+
+	Context("state provider", func() {
+		DescribeTable("should translate the completion of drain and reboot to the next firmware upgrade state",
+			func(state, expectedState string) {
+				node := newTestNode("node1", "28.38.1002", state)
+				k8sInterface = fake.NewSimpleClientset(node.DeepCopy())
+				provider := &nodeStateProvider{k8sInterface: k8sInterface, log: log.Log}
+				Expect(provider.ChangeNodeUpgradeState(
+					context.TODO(), node, upgradeLib.UpgradeStatePodRestartRequired)).To(Succeed())
+				Expect(node.Labels[UpgradeStateLabelKey]).To(Equal(expectedState))
+			},
+			Entry("drain", UpgradeStateDrainRequired, UpgradeStateFirmwareUpdateRequired),
+			Entry("reboot", UpgradeStateRebootRequired, UpgradeStateValidationRequired),
+		)
+
+		It("should reject unexpected transitions", func() {
+			node := newTestNode("node1", "28.38.1002", UpgradeStateUpgradeRequired)
+			provider := &nodeStateProvider{k8sInterface: fake.NewSimpleClientset(node.DeepCopy()), log: log.Log}
+			Expect(provider.ChangeNodeUpgradeState(
+				context.TODO(), node, upgradeLib.UpgradeStatePodRestartRequired)).NotTo(Succeed())
+		})
+	})
+})
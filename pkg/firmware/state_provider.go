@@ -0,0 +1,83 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package firmware
+
+import (
+	"context"
+	"fmt"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+// nodeStateProvider implements upgradeLib.NodeUpgradeStateProvider for the firmware upgrade,
+// it allows to reuse the drain and reboot managers of the driver upgrade.
+// The managers move the node to the pod-restart-required state once they are done,
+// this state is translated to the firmware upgrade state which follows the current one
+type nodeStateProvider struct {
+	k8sInterface kubernetes.Interface
+	log          logr.Logger
+}
+
+// GetNode returns the node with the given name
+func (p *nodeStateProvider) GetNode(ctx context.Context, nodeName string) (*corev1.Node, error) {
+	return p.k8sInterface.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+}
+
+// ChangeNodeUpgradeState sets the firmware upgrade state label of the node and updates the node object
+func (p *nodeStateProvider) ChangeNodeUpgradeState(ctx context.Context, node *corev1.Node, newNodeState string) error {
+	if newNodeState == upgradeLib.UpgradeStatePodRestartRequired {
+		switch node.Labels[UpgradeStateLabelKey] {
+		case UpgradeStateDrainRequired:
+			newNodeState = UpgradeStateFirmwareUpdateRequired
+		case UpgradeStateRebootRequired:
+			newNodeState = UpgradeStateValidationRequired
+		default:
+			return fmt.Errorf("unexpected transition of node %s from firmware upgrade state %q",
+				node.Name, node.Labels[UpgradeStateLabelKey])
+		}
+	}
+	p.log.V(consts.LogLevelInfo).Info("Changing firmware upgrade state of the node",
+		"node", node.Name, "state", newNodeState)
+	return p.patchNode(ctx, node, fmt.Sprintf(`{"metadata":{"labels":{%q: %q}}}`, UpgradeStateLabelKey, newNodeState))
+}
+
+// ChangeNodeUpgradeAnnotation sets the annotation of the node and updates the node object,
+// the "null" value deletes the annotation
+func (p *nodeStateProvider) ChangeNodeUpgradeAnnotation(
+	ctx context.Context, node *corev1.Node, key string, value string) error {
+	if value == "null" {
+		return p.patchNode(ctx, node, fmt.Sprintf(`{"metadata":{"annotations":{%q: null}}}`, key))
+	}
+	return p.patchNode(ctx, node, fmt.Sprintf(`{"metadata":{"annotations":{%q: %q}}}`, key, value))
+}
+
+func (p *nodeStateProvider) patchNode(ctx context.Context, node *corev1.Node, patch string) error {
+	updated, err := p.k8sInterface.CoreV1().Nodes().Patch(
+		ctx, node.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to patch node %s: %v", node.Name, err)
+	}
+	updated.DeepCopyInto(node)
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 
 	"github.com/go-logr/logr"
+	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -29,6 +30,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/Mellanox/network-operator/pkg/config"
 	"github.com/Mellanox/network-operator/pkg/consts"
@@ -89,6 +91,13 @@ func migrate(ctx context.Context, log logr.Logger, c client.Client) error {
 		log.V(consts.LogLevelError).Error(err, "error trying to handle single MOFED DS")
 		return err
 	}
+	if config.FromEnv().AdoptExistingResources {
+		if err := adoptNetworkAttachmentDefinitions(ctx, log, c); err != nil {
+			// critical for the operator operation, the network CRs would fail to take over their NADs
+			log.V(consts.LogLevelError).Error(err, "error trying to adopt existing NetworkAttachmentDefinitions")
+			return err
+		}
+	}
 	return nil
 }
 
@@ -201,6 +210,104 @@ func handleSingleMofedDS(ctx context.Context, log logr.Logger, c client.Client)
 	return nil
 }
 
+// networkCR is a network custom resource which renders a NetworkAttachmentDefinition with its name
+type networkCR struct {
+	obj              client.Object
+	networkNamespace string
+	// stateName is the name of the state which syncs the custom resource
+	stateName string
+}
+
+func listNetworkCRs(ctx context.Context, c client.Client) ([]networkCR, error) {
+	crs := []networkCR{}
+	macvlanList := &mellanoxv1alpha1.MacvlanNetworkList{}
+	if err := c.List(ctx, macvlanList); err != nil {
+		return nil, err
+	}
+	for i := range macvlanList.Items {
+		cr := &macvlanList.Items[i]
+		crs = append(crs, networkCR{obj: cr, networkNamespace: cr.Spec.NetworkNamespace,
+			stateName: "state-Macvlan-Network"})
+	}
+	hostDeviceList := &mellanoxv1alpha1.HostDeviceNetworkList{}
+	if err := c.List(ctx, hostDeviceList); err != nil {
+		return nil, err
+	}
+	for i := range hostDeviceList.Items {
+		cr := &hostDeviceList.Items[i]
+		crs = append(crs, networkCR{obj: cr, networkNamespace: cr.Spec.NetworkNamespace,
+			stateName: "state-host-device-network"})
+	}
+	ipoibList := &mellanoxv1alpha1.IPoIBNetworkList{}
+	if err := c.List(ctx, ipoibList); err != nil {
+		return nil, err
+	}
+	for i := range ipoibList.Items {
+		cr := &ipoibList.Items[i]
+		crs = append(crs, networkCR{obj: cr, networkNamespace: cr.Spec.NetworkNamespace,
+			stateName: "state-IPoIB-Network"})
+	}
+	return crs, nil
+}
+
+// reason: NetworkAttachmentDefinitions created manually or by an older release for a network CR are not owned by it,
+// they would not be removed with the CR and are not recognized as objects of its state.
+// Set the network CR as the controller of its NetworkAttachmentDefinition and add the state label,
+// NADs controlled by another object are left untouched.
+func adoptNetworkAttachmentDefinitions(ctx context.Context, log logr.Logger, c client.Client) error {
+	crs, err := listNetworkCRs(ctx, c)
+	if err != nil {
+		log.V(consts.LogLevelError).Error(err, "fail to list network CRs")
+		return err
+	}
+	for _, cr := range crs {
+		if err := adoptNetworkAttachmentDefinition(ctx, log, c, cr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func adoptNetworkAttachmentDefinition(ctx context.Context, log logr.Logger, c client.Client, cr networkCR) error {
+	namespace := cr.networkNamespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	nad := &netattdefv1.NetworkAttachmentDefinition{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: cr.obj.GetName()}, nad)
+	if apiErrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		log.V(consts.LogLevelError).Error(err, "fail to get NetworkAttachmentDefinition",
+			"namespace", namespace, "name", cr.obj.GetName())
+		return err
+	}
+	if owner := metav1.GetControllerOf(nad); owner != nil {
+		if owner.UID != cr.obj.GetUID() {
+			log.V(consts.LogLevelWarning).Info(
+				"NetworkAttachmentDefinition is controlled by another object, skip adoption",
+				"namespace", namespace, "name", nad.Name, "owner", owner.Kind+"/"+owner.Name)
+		}
+		return nil
+	}
+	patchBase := client.MergeFrom(nad.DeepCopy())
+	if err := controllerutil.SetControllerReference(cr.obj, nad, c.Scheme()); err != nil {
+		return err
+	}
+	if nad.Labels == nil {
+		nad.Labels = map[string]string{}
+	}
+	nad.Labels[consts.StateLabel] = cr.stateName
+	if err := c.Patch(ctx, nad, patchBase); err != nil {
+		log.V(consts.LogLevelError).Error(err, "fail to adopt NetworkAttachmentDefinition",
+			"namespace", namespace, "name", nad.Name)
+		return err
+	}
+	log.V(consts.LogLevelDebug).Info("NetworkAttachmentDefinition adopted",
+		"namespace", namespace, "name", nad.Name, "state", cr.stateName)
+	return nil
+}
+
 func markNodesAsUpgradeRequested(ctx context.Context, log logr.Logger, c client.Client, ds *appsv1.DaemonSet) error {
 	nodes, err := getDaemonSetNodes(ctx, c, ds)
 	if err != nil {
@@ -211,7 +318,7 @@ func markNodesAsUpgradeRequested(ctx context.Context, log logr.Logger, c client.
 		nodeKey := client.ObjectKey{
 			Name: nodeName,
 		}
-		if err := c.Get(context.Background(), nodeKey, node); err != nil {
+		if err := c.Get(ctx, nodeKey, node); err != nil {
 			return err
 		}
 		patchString := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q: "true"}}}`,
@@ -19,11 +19,13 @@ package migrate
 import (
 	goctx "context"
 
+	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -89,8 +91,67 @@ var _ = Describe("Migrate", func() {
 				node2.Annotations[upgrade.GetUpgradeRequestedAnnotationKey()] == "true"
 		})
 	})
+	Context("Adopt NetworkAttachmentDefinitions", func() {
+		AfterEach(func() {
+			_ = k8sClient.DeleteAllOf(goctx.Background(), &mellanoxv1alpha1.MacvlanNetwork{})
+			_ = k8sClient.DeleteAllOf(goctx.Background(), &netattdefv1.NetworkAttachmentDefinition{},
+				client.InNamespace(namespaceName))
+		})
+		It("should set the network CR as controller of its NAD", func() {
+			cr := createMacvlanNetwork("adopt-net")
+			createNetAttDef("adopt-net", nil)
+			err := adoptNetworkAttachmentDefinitions(goctx.Background(), testLog, k8sClient)
+			Expect(err).NotTo(HaveOccurred())
+			nad := &netattdefv1.NetworkAttachmentDefinition{}
+			err = k8sClient.Get(goctx.Background(), types.NamespacedName{Namespace: namespaceName, Name: "adopt-net"}, nad)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nad.Labels).To(HaveKeyWithValue(consts.StateLabel, "state-Macvlan-Network"))
+			owner := metav1.GetControllerOf(nad)
+			Expect(owner).NotTo(BeNil())
+			Expect(owner.UID).To(Equal(cr.UID))
+		})
+		It("should not adopt a NAD controlled by another object", func() {
+			createMacvlanNetwork("owned-net")
+			other := createMacvlanNetwork("other-net")
+			isController := true
+			createNetAttDef("owned-net", []metav1.OwnerReference{{
+				APIVersion: mellanoxv1alpha1.GroupVersion.String(), Kind: "MacvlanNetwork",
+				Name: other.Name, UID: other.UID, Controller: &isController,
+			}})
+			err := adoptNetworkAttachmentDefinitions(goctx.Background(), testLog, k8sClient)
+			Expect(err).NotTo(HaveOccurred())
+			nad := &netattdefv1.NetworkAttachmentDefinition{}
+			err = k8sClient.Get(goctx.Background(), types.NamespacedName{Namespace: namespaceName, Name: "owned-net"}, nad)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nad.Labels).NotTo(HaveKey(consts.StateLabel))
+			Expect(metav1.GetControllerOf(nad).UID).To(Equal(other.UID))
+		})
+		It("should not fail if the NAD does not exist", func() {
+			createMacvlanNetwork("missing-net")
+			err := adoptNetworkAttachmentDefinitions(goctx.Background(), testLog, k8sClient)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
 })
 
+func createMacvlanNetwork(name string) *mellanoxv1alpha1.MacvlanNetwork {
+	cr := &mellanoxv1alpha1.MacvlanNetwork{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	cr.Spec.NetworkNamespace = namespaceName
+	cr.Spec.Master = "ens1f0"
+	cr.Spec.Mode = "bridge"
+	err := k8sClient.Create(goctx.Background(), cr)
+	Expect(err).NotTo(HaveOccurred())
+	return cr
+}
+
+func createNetAttDef(name string, owners []metav1.OwnerReference) {
+	nad := &netattdefv1.NetworkAttachmentDefinition{ObjectMeta: metav1.ObjectMeta{
+		Namespace: namespaceName, Name: name, OwnerReferences: owners,
+	}}
+	err := k8sClient.Create(goctx.Background(), nad)
+	Expect(err).NotTo(HaveOccurred())
+}
+
 func createConfigMap(addLabel bool) {
 	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespaceName, Name: nvIPAMcmName}}
 	if addLabel {
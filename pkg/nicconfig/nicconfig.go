@@ -0,0 +1,131 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package nicconfig translates the NicConfigurationTemplates to the mlxconfig parameters applied by the
+NIC configuration daemon.
+
+The operator writes the desired configuration of the NICs of a node to the DesiredConfigurationAnnotationKey
+node annotation, the daemon running on the node applies it with mlxconfig and reports the result
+in the ConfigurationStatusAnnotationKey node annotation.
+*/
+package nicconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+const (
+	// TemplateLabelKey is the key of the node label with the name of the NicConfigurationTemplate
+	// which configures the node
+	TemplateLabelKey = "network.nvidia.com/nic-configuration-template"
+	// DesiredConfigurationAnnotationKey is the key of the node annotation with the desired NodeConfiguration
+	DesiredConfigurationAnnotationKey = "network.nvidia.com/nic-configuration"
+	// ConfigurationStatusAnnotationKey is the key of the node annotation with the NodeConfigurationStatus
+	// reported by the NIC configuration daemon
+	ConfigurationStatusAnnotationKey = "network.nvidia.com/nic-configuration-status"
+)
+
+// mlxconfig parameters and values
+const (
+	paramSriovEnabled      = "SRIOV_EN"
+	paramNumVfs            = "NUM_OF_VFS"
+	paramLinkTypeP1        = "LINK_TYPE_P1"
+	paramLinkTypeP2        = "LINK_TYPE_P2"
+	paramPciWriteOrdering  = "PCI_WR_ORDERING"
+	linkTypeInfiniband     = "IB"
+	linkTypeEthernet       = "ETH"
+	pciWriteOrderingRelax  = "force_relax"
+	pciWriteOrderingPerKey = "per_mkey"
+)
+
+// NodeConfiguration is the desired configuration of the NICs of a node
+type NodeConfiguration struct {
+	// NicType is the PCI device ID of the NICs to configure
+	NicType string `json:"nicType"`
+	// Parameters are the mlxconfig parameters to apply to the NICs
+	Parameters map[string]string `json:"parameters"`
+}
+
+// NodeConfigurationStatus is the configuration state of the NICs of a node reported by the NIC configuration daemon
+type NodeConfigurationStatus struct {
+	// Parameters are the mlxconfig parameters which were applied to the NICs
+	Parameters map[string]string `json:"parameters,omitempty"`
+	// PendingRebootParameters are the applied parameters which take effect after the reboot of the node
+	PendingRebootParameters []string `json:"pendingRebootParameters,omitempty"`
+	// Error is set if the parameters could not be applied
+	Error string `json:"error,omitempty"`
+}
+
+// GetNodeConfiguration returns the desired configuration of the nodes selected by the template
+func GetNodeConfiguration(template *mellanoxv1alpha1.NicConfigurationTemplate) *NodeConfiguration {
+	spec := template.Spec.Template
+	params := map[string]string{
+		paramSriovEnabled: strconv.FormatBool(spec.NumVfs > 0),
+		paramNumVfs:       strconv.Itoa(spec.NumVfs),
+	}
+	switch spec.LinkType {
+	case mellanoxv1alpha1.LinkTypeEthernet:
+		params[paramLinkTypeP1] = linkTypeEthernet
+		params[paramLinkTypeP2] = linkTypeEthernet
+	case mellanoxv1alpha1.LinkTypeInfiniband:
+		params[paramLinkTypeP1] = linkTypeInfiniband
+		params[paramLinkTypeP2] = linkTypeInfiniband
+	}
+	if spec.PciRelaxedOrdering != nil {
+		params[paramPciWriteOrdering] = pciWriteOrderingPerKey
+		if *spec.PciRelaxedOrdering {
+			params[paramPciWriteOrdering] = pciWriteOrderingRelax
+		}
+	}
+	return &NodeConfiguration{NicType: template.Spec.NicType, Parameters: params}
+}
+
+// GetNodeStatus returns the configuration state of the node according to the status reported by the daemon
+func GetNodeStatus(node *corev1.Node, desired *NodeConfiguration) mellanoxv1alpha1.NicConfigurationNodeStatus {
+	nodeStatus := mellanoxv1alpha1.NicConfigurationNodeStatus{
+		Name: node.Name, State: mellanoxv1alpha1.NicConfigurationStatePending}
+	value, ok := node.Annotations[ConfigurationStatusAnnotationKey]
+	if !ok {
+		return nodeStatus
+	}
+	status := &NodeConfigurationStatus{}
+	if err := json.Unmarshal([]byte(value), status); err != nil {
+		nodeStatus.State = mellanoxv1alpha1.NicConfigurationStateError
+		nodeStatus.Reason = fmt.Sprintf("failed to parse configuration status: %v", err)
+		return nodeStatus
+	}
+	switch {
+	case status.Error != "":
+		nodeStatus.State = mellanoxv1alpha1.NicConfigurationStateError
+		nodeStatus.Reason = status.Error
+	case !reflect.DeepEqual(status.Parameters, desired.Parameters):
+		// the daemon did not apply the current configuration yet
+	case len(status.PendingRebootParameters) > 0:
+		nodeStatus.State = mellanoxv1alpha1.NicConfigurationStatePendingReboot
+		nodeStatus.PendingRebootParameters = status.PendingRebootParameters
+	default:
+		nodeStatus.State = mellanoxv1alpha1.NicConfigurationStateApplied
+	}
+	return nodeStatus
+}
@@ -0,0 +1,86 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nicconfig
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+func newTestNode(status string) *corev1.Node {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Annotations: map[string]string{}}}
+	if status != "" {
+		node.Annotations[ConfigurationStatusAnnotationKey] = status
+	}
+	return node
+}
+
+var _ = Describe("NIC configuration tests", func() {
+	Context("GetNodeConfiguration", func() {
+		It("should translate the template to mlxconfig parameters", func() {
+			relaxed := true
+			template := &mellanoxv1alpha1.NicConfigurationTemplate{
+				Spec: mellanoxv1alpha1.NicConfigurationTemplateSpec{
+					NicType: "101b",
+					Template: mellanoxv1alpha1.ConfigurationTemplateSpec{
+						NumVfs: 8, LinkType: mellanoxv1alpha1.LinkTypeInfiniband, PciRelaxedOrdering: &relaxed},
+				},
+			}
+			Expect(GetNodeConfiguration(template)).To(Equal(&NodeConfiguration{
+				NicType: "101b",
+				Parameters: map[string]string{
+					"SRIOV_EN": "true", "NUM_OF_VFS": "8", "LINK_TYPE_P1": "IB", "LINK_TYPE_P2": "IB",
+					"PCI_WR_ORDERING": "force_relax",
+				},
+			}))
+		})
+
+		It("should not change the settings which are not set", func() {
+			template := &mellanoxv1alpha1.NicConfigurationTemplate{
+				Spec: mellanoxv1alpha1.NicConfigurationTemplateSpec{NicType: "101b"},
+			}
+			Expect(GetNodeConfiguration(template).Parameters).To(Equal(
+				map[string]string{"SRIOV_EN": "false", "NUM_OF_VFS": "0"}))
+		})
+	})
+
+	Context("GetNodeStatus", func() {
+		desired := &NodeConfiguration{NicType: "101b", Parameters: map[string]string{"NUM_OF_VFS": "8"}}
+
+		DescribeTable("should report the configuration state of the node",
+			func(status, expectedState string) {
+				Expect(GetNodeStatus(newTestNode(status), desired).State).To(Equal(expectedState))
+			},
+			Entry("not reported", "", mellanoxv1alpha1.NicConfigurationStatePending),
+			Entry("outdated", `{"parameters":{"NUM_OF_VFS":"4"}}`, mellanoxv1alpha1.NicConfigurationStatePending),
+			Entry("applied", `{"parameters":{"NUM_OF_VFS":"8"}}`, mellanoxv1alpha1.NicConfigurationStateApplied),
+			Entry("error", `{"error":"mlxconfig failed"}`, mellanoxv1alpha1.NicConfigurationStateError),
+			Entry("invalid", `{`, mellanoxv1alpha1.NicConfigurationStateError),
+		)
+
+		It("should report the parameters pending reboot", func() {
+			status := GetNodeStatus(newTestNode(
+				`{"parameters":{"NUM_OF_VFS":"8"},"pendingRebootParameters":["NUM_OF_VFS"]}`), desired)
+			Expect(status.State).To(Equal(mellanoxv1alpha1.NicConfigurationStatePendingReboot))
+			Expect(status.PendingRebootParameters).To(Equal([]string{"NUM_OF_VFS"}))
+		})
+	})
+})
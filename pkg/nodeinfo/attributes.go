@@ -38,6 +38,7 @@ const (
 	NodeLabelWaitOFED         = "network.nvidia.com/operator.mofed.wait"
 	NodeLabelCudaVersionMajor = "nvidia.com/cuda.driver.major"
 	NodeLabelOSTreeVersion    = "feature.node.kubernetes.io/system-os_release.OSTREE_VERSION"
+	NodeLabelNicFirmwareVer   = "network.nvidia.com/nic-firmware.version"
 )
 
 // AttributeType categorizes Attributes of the host.
@@ -0,0 +1,89 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// maxRenderCacheEntries bounds the number of renderings cached by a renderer, a renderer can be used with
+// different data in the same reconcile, e.g. for the node pools of the OFED driver
+const maxRenderCacheEntries = 64
+
+// renderCache caches the objects rendered from the manifests and the data with the same hash
+type renderCache struct {
+	mu      sync.Mutex
+	entries map[string][]*unstructured.Unstructured
+}
+
+func newRenderCache() *renderCache {
+	return &renderCache{entries: make(map[string][]*unstructured.Unstructured)}
+}
+
+// cacheKey returns the hash of the manifests and the templating data, ok is false if the rendering can't be cached,
+// i.e. when additional functions are used, as their behavior can't be hashed, or the data can't be serialized
+func cacheKey(manifests []Manifest, data *TemplatingData) (key string, ok bool) {
+	if data.Funcs != nil {
+		return "", false
+	}
+	dataJSON, err := json.Marshal(data.Data)
+	if err != nil {
+		return "", false
+	}
+	h := sha256.New()
+	for _, manifest := range manifests {
+		// NUL characters separate the fields as they can't be part of the manifests
+		_, _ = h.Write([]byte(manifest.Name + "\x00" + manifest.Content + "\x00"))
+	}
+	_, _ = h.Write(dataJSON)
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// get returns a copy of the objects cached for the key
+func (c *renderCache) get(key string) ([]*unstructured.Unstructured, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	objs, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	return deepCopyObjects(objs), true
+}
+
+// set caches a copy of the objects for the key, the cache is reset when it is full
+func (c *renderCache) set(key string, objs []*unstructured.Unstructured) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= maxRenderCacheEntries {
+		c.entries = make(map[string][]*unstructured.Unstructured)
+	}
+	c.entries[key] = deepCopyObjects(objs)
+}
+
+// deepCopyObjects copies the objects, callers modify the rendered objects, e.g. to set their owner
+func deepCopyObjects(objs []*unstructured.Unstructured) []*unstructured.Unstructured {
+	out := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		out = append(out, obj.DeepCopy())
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"context"
+	"fmt"
+	"text/template"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// staticSource is a ManifestSource which returns the given manifests
+type staticSource struct {
+	manifests []Manifest
+}
+
+func (s *staticSource) Manifests(_ context.Context) ([]Manifest, error) {
+	return s.manifests, nil
+}
+
+var _ = Describe("Render cache", func() {
+	var source *staticSource
+	var r *textTemplateRenderer
+
+	BeforeEach(func() {
+		source = &staticSource{manifests: []Manifest{{
+			Name:    "0001_obj.yaml",
+			Content: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Name }}\n",
+		}}}
+		r = NewSourceRenderer(source).(*textTemplateRenderer)
+	})
+
+	It("Should return copies of the cached objects", func() {
+		data := &TemplatingData{Data: map[string]string{"Name": "foo"}}
+		objs, err := r.RenderObjects(context.Background(), data)
+		Expect(err).NotTo(HaveOccurred())
+		objs[0].SetName("modified")
+		Expect(r.cache.entries).To(HaveLen(1))
+
+		objs, err = r.RenderObjects(context.Background(), data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs[0].GetName()).To(Equal("foo"))
+		Expect(r.cache.entries).To(HaveLen(1))
+	})
+
+	It("Should render again if the data or the manifests change", func() {
+		objs, err := r.RenderObjects(context.Background(), &TemplatingData{Data: map[string]string{"Name": "foo"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs[0].GetName()).To(Equal("foo"))
+
+		objs, err = r.RenderObjects(context.Background(), &TemplatingData{Data: map[string]string{"Name": "bar"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs[0].GetName()).To(Equal("bar"))
+		Expect(r.cache.entries).To(HaveLen(2))
+
+		source.manifests[0].Content += "  labels:\n    app: bar\n"
+		objs, err = r.RenderObjects(context.Background(), &TemplatingData{Data: map[string]string{"Name": "bar"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs[0].GetLabels()).To(HaveKeyWithValue("app", "bar"))
+		Expect(r.cache.entries).To(HaveLen(3))
+	})
+
+	It("Should not cache renderings with additional functions", func() {
+		data := &TemplatingData{
+			Funcs: template.FuncMap{"name": func() string { return "foo" }},
+			Data:  map[string]string{"Name": "foo"},
+		}
+		_, err := r.RenderObjects(context.Background(), data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.cache.entries).To(BeEmpty())
+	})
+
+	It("Should bound the number of cached renderings", func() {
+		for i := 0; i <= maxRenderCacheEntries; i++ {
+			_, err := r.RenderObjects(context.Background(),
+				&TemplatingData{Data: map[string]string{"Name": fmt.Sprint("foo", i)}})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(r.cache.entries).To(HaveLen(1))
+	})
+})
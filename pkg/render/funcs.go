@@ -0,0 +1,147 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/semver/v3"
+	yamlConverter "sigs.k8s.io/yaml"
+)
+
+// builtinFuncs returns the functions available to all the manifest templates, functions with the same name
+// in TemplatingData.Funcs take precedence. Functions follow the semantics of their sprig counterparts.
+func builtinFuncs() template.FuncMap {
+	return template.FuncMap{
+		"yaml":          yaml,
+		"toYaml":        toYaml,
+		"quote":         quote,
+		"indent":        indent,
+		"nindent":       nindent,
+		"nindentPrefix": nindentPrefix,
+		"default":       defaultValue,
+		"b64enc":        b64enc,
+		"semverCompare": semverCompare,
+		"required":      required,
+		"fail":          fail,
+	}
+}
+
+// FailError is the error of a template which failed on purpose with the required or fail functions,
+// its message is meant for the user and is reported without the location in the template
+type FailError struct {
+	Message string
+}
+
+func (e *FailError) Error() string {
+	return e.Message
+}
+
+// required returns the given value, or fails the rendering with the message if the value is nil or an empty string,
+// e.g. {{ required "spec.ofedDriver.repository is required" .Repository }}
+func required(msg string, v interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	switch {
+	case !rv.IsValid(),
+		rv.Kind() == reflect.String && rv.Len() == 0,
+		(rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) && rv.IsNil():
+		return nil, &FailError{Message: msg}
+	}
+	return v, nil
+}
+
+// fail fails the rendering with the message, e.g. {{ if .Invalid }}{{ fail "spec.foo is invalid" }}{{ end }}
+func fail(msg string) (string, error) {
+	return "", &FailError{Message: msg}
+}
+
+// yaml marshals the object to yaml
+func yaml(obj interface{}) (string, error) {
+	yamlBytes, err := yamlConverter.Marshal(obj)
+	return string(yamlBytes), err
+}
+
+// toYaml marshals the object to yaml without the trailing newline
+func toYaml(obj interface{}) (string, error) {
+	out, err := yaml(obj)
+	return strings.TrimSuffix(out, "\n"), err
+}
+
+func quote(obj interface{}) string {
+	return fmt.Sprintf("%q", obj)
+}
+
+func indent(spaces int, v string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.Replace(v, "\n", "\n"+pad, -1)
+}
+
+func nindent(spaces int, v string) string {
+	return "\n" + indent(spaces, v)
+}
+
+// nindentPrefix adds a prefix in front of the indented string, left from the initial indentation
+func nindentPrefix(spaces int, prefix, v string) string {
+	// Remove len(prefix) spaces from the beginning of the indented string
+	return strings.Replace(nindent(spaces, prefix+v), " ", "", len(prefix))
+}
+
+// defaultValue returns the given value, or def if the value is not given or is empty,
+// e.g. {{ .Version | default "latest" }}
+func defaultValue(def interface{}, given ...interface{}) interface{} {
+	if len(given) == 0 || isEmpty(given[0]) {
+		return def
+	}
+	return given[0]
+}
+
+// isEmpty returns true if the value is nil or the zero value of its type, and for empty collections
+func isEmpty(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return true
+	}
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return rv.IsZero()
+	}
+}
+
+func b64enc(v string) string {
+	return base64.StdEncoding.EncodeToString([]byte(v))
+}
+
+// semverCompare returns true if the version satisfies the constraint, e.g. {{ if semverCompare ">=1.28" .Version }}
+func semverCompare(constraint, version string) (bool, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false, err
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false, err
+	}
+	return c.Check(v), nil
+}
@@ -0,0 +1,125 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// IsHelmChart returns true if the directory contains a Helm chart
+func IsHelmChart(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, chartutil.ChartfileName))
+	return err == nil
+}
+
+// NewHelmChartRenderer creates a Renderer which renders the templates of the Helm chart in chartDir,
+// the chart is rendered as a release with the given name in the given namespace.
+// The TemplatingData.Data are the values of the chart, they are merged with the default values of the chart.
+// Additional functions are not supported, CRDs of the chart are not rendered.
+func NewHelmChartRenderer(chartDir, releaseName, namespace string) Renderer {
+	return &helmChartRenderer{
+		chartDir:    chartDir,
+		releaseName: releaseName,
+		namespace:   namespace,
+	}
+}
+
+// helmChartRenderer is an implementation of the Renderer interface using the Helm template engine
+type helmChartRenderer struct {
+	chartDir    string
+	releaseName string
+	namespace   string
+}
+
+// RenderObjects renders the templates of the chart with the values provided in TemplatingData.Data,
+// objects are returned ordered by the name of the template they are rendered from.
+func (r *helmChartRenderer) RenderObjects(
+	_ context.Context, data *TemplatingData) ([]*unstructured.Unstructured, error) {
+	if data.Funcs != nil {
+		return nil, errors.New("additional template functions are not supported by Helm charts")
+	}
+	chrt, err := loader.Load(r.chartDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load Helm chart %s", r.chartDir)
+	}
+	values, err := toChartValues(data.Data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to convert the values of Helm chart %s", r.chartDir)
+	}
+	renderValues, err := chartutil.ToRenderValues(chrt, values, chartutil.ReleaseOptions{
+		Name:      r.releaseName,
+		Namespace: r.namespace,
+		IsInstall: true,
+	}, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compute the values of Helm chart %s", r.chartDir)
+	}
+	rendered, err := engine.Render(chrt, renderValues)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to render Helm chart %s", r.chartDir)
+	}
+
+	names := make([]string, 0, len(rendered))
+	for name := range rendered {
+		base := path.Base(name)
+		// partials and notes don't contain objects
+		if strings.HasPrefix(base, "_") || strings.EqualFold(base, "NOTES.txt") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	objs := []*unstructured.Unstructured{}
+	for _, name := range names {
+		out, err := decodeManifest(name, bytes.NewBufferString(rendered[name]))
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, out...)
+	}
+	return objs, nil
+}
+
+// toChartValues converts the data to the values of a chart, the data is converted through its JSON representation
+func toChartValues(data interface{}) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if data == nil {
+		return values, nil
+	}
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(dataJSON, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -0,0 +1,87 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render_test
+
+import (
+	"context"
+	"path/filepath"
+	"text/template"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/Mellanox/network-operator/pkg/render"
+)
+
+var _ = Describe("Helm chart renderer", func() {
+	chartDir := filepath.Join("testdata", "helmChart")
+
+	containerImage := func(obj *unstructured.Unstructured) string {
+		containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(containers).To(HaveLen(1))
+		return containers[0].(map[string]interface{})["image"].(string)
+	}
+
+	It("Should detect Helm charts", func() {
+		Expect(render.IsHelmChart(chartDir)).To(BeTrue())
+		Expect(render.IsHelmChart(filepath.Join("testdata", "manifests"))).To(BeFalse())
+	})
+
+	It("Should render the chart with its default values", func() {
+		r := render.NewHelmChartRenderer(chartDir, "release", "nvidia-network-operator")
+		objs, err := r.RenderObjects(context.Background(), &render.TemplatingData{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(1))
+		Expect(objs[0].GetKind()).To(Equal("DaemonSet"))
+		Expect(objs[0].GetName()).To(Equal("release-test-chart"))
+		Expect(objs[0].GetNamespace()).To(Equal("nvidia-network-operator"))
+		Expect(containerImage(objs[0])).To(Equal("nvcr.io/nvidia/mellanox/test:v1.0.0"))
+	})
+
+	It("Should render the chart with the given values ordered by template name", func() {
+		type imageValues struct {
+			Tag string `json:"tag"`
+		}
+		values := struct {
+			Image  imageValues       `json:"image"`
+			Config map[string]string `json:"config"`
+		}{Image: imageValues{Tag: "v2.0.0"}, Config: map[string]string{"key": "value"}}
+		r := render.NewHelmChartRenderer(chartDir, "release", "nvidia-network-operator")
+		objs, err := r.RenderObjects(context.Background(), &render.TemplatingData{Data: values})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(2))
+		Expect(objs[0].GetKind()).To(Equal("ConfigMap"))
+		Expect(objs[0].Object["data"]).To(Equal(map[string]interface{}{"key": "value"}))
+		Expect(objs[1].GetKind()).To(Equal("DaemonSet"))
+		// values not given keep their default
+		Expect(containerImage(objs[1])).To(Equal("nvcr.io/nvidia/mellanox/test:v2.0.0"))
+	})
+
+	It("Should fail with additional functions", func() {
+		r := render.NewHelmChartRenderer(chartDir, "release", "nvidia-network-operator")
+		_, err := r.RenderObjects(context.Background(), &render.TemplatingData{Funcs: template.FuncMap{}})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should fail if the chart doesn't exist", func() {
+		r := render.NewHelmChartRenderer(filepath.Join("testdata", "doesNotExist"), "release", "ns")
+		_, err := r.RenderObjects(context.Background(), &render.TemplatingData{})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -25,18 +25,14 @@ package render
 
 import (
 	"bytes"
-	"fmt"
+	"context"
 	"io"
-	"os"
-	"path"
-	"path/filepath"
 	"strings"
 	"text/template"
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	yamlDecoder "k8s.io/apimachinery/pkg/util/yaml"
-	yamlConverter "sigs.k8s.io/yaml"
 )
 
 const (
@@ -48,13 +44,15 @@ var ManifestFileSuffix = []string{"yaml", "yml", "json"}
 
 // Renderer renders k8s objects from a manifest source dir and TemplatingData used by the templating engine
 type Renderer interface {
-	// RenderObjects renders kubernetes objects using provided TemplatingData
-	RenderObjects(data *TemplatingData) ([]*unstructured.Unstructured, error)
+	// RenderObjects renders kubernetes objects using provided TemplatingData, the context is passed to the
+	// manifest source which may read the manifests from the API server
+	RenderObjects(ctx context.Context, data *TemplatingData) ([]*unstructured.Unstructured, error)
 }
 
 // TemplatingData is used by the templating engine to render templates
 type TemplatingData struct {
-	// Funcs are additional Functions used during the templating process
+	// Funcs are additional Functions used during the templating process, in addition to the built-in functions:
+	// yaml, toYaml, quote, indent, nindent, nindentPrefix, default, b64enc, semverCompare, required and fail
 	Funcs template.FuncMap
 	// Data used for the rendering process
 	Data interface{}
@@ -63,23 +61,38 @@ type TemplatingData struct {
 // NewRenderer creates a Renderer object, that will render all template files provided.
 // file format needs to be either json or yaml.
 func NewRenderer(files []string) Renderer {
+	return NewSourceRenderer(NewFilesSource(files))
+}
+
+// NewSourceRenderer creates a Renderer object, that will render all manifest templates provided by the source.
+// manifest format needs to be either json or yaml.
+func NewSourceRenderer(source ManifestSource) Renderer {
 	return &textTemplateRenderer{
-		files: files,
+		source: source,
+		cache:  newRenderCache(),
 	}
 }
 
-// textTemplateRenderer is an implementation of the Renderer interface using golang builtin text/template package
-// as its templating engine
-type textTemplateRenderer struct {
-	files []string
+// NewRawRenderer creates a Renderer object, that will decode the manifests provided by the source as is,
+// the manifests are not templates and the TemplatingData is ignored. manifest format needs to be either json or yaml.
+func NewRawRenderer(source ManifestSource) Renderer {
+	return &rawRenderer{source: source}
 }
 
-// RenderObjects renders kubernetes objects utilizing the provided TemplatingData.
-func (r *textTemplateRenderer) RenderObjects(data *TemplatingData) ([]*unstructured.Unstructured, error) {
-	objs := []*unstructured.Unstructured{}
+// rawRenderer is an implementation of the Renderer interface which decodes the manifests without templating
+type rawRenderer struct {
+	source ManifestSource
+}
 
-	for _, file := range r.files {
-		out, err := r.renderFile(file, data)
+// RenderObjects decodes the kubernetes objects of the manifests
+func (r *rawRenderer) RenderObjects(ctx context.Context, _ *TemplatingData) ([]*unstructured.Unstructured, error) {
+	manifests, err := r.source.Manifests(ctx)
+	if err != nil {
+		return nil, err
+	}
+	objs := []*unstructured.Unstructured{}
+	for _, manifest := range manifests {
+		out, err := decodeManifest(manifest.Name, bytes.NewBufferString(manifest.Content))
 		if err != nil {
 			return nil, err
 		}
@@ -88,57 +101,73 @@ func (r *textTemplateRenderer) RenderObjects(data *TemplatingData) ([]*unstructu
 	return objs, nil
 }
 
-func indent(spaces int, v string) string {
-	pad := strings.Repeat(" ", spaces)
-	return pad + strings.Replace(v, "\n", "\n"+pad, -1)
-}
-
-func nindent(spaces int, v string) string {
-	return "\n" + indent(spaces, v)
+// textTemplateRenderer is an implementation of the Renderer interface using golang builtin text/template package
+// as its templating engine
+type textTemplateRenderer struct {
+	source ManifestSource
+	// cache holds the objects rendered previously, manifests are re-rendered only if they or the data change
+	cache *renderCache
 }
 
-// nindentPrefix adds a prefix in front of the indented string, left from the initial indentation
-func nindentPrefix(spaces int, prefix, v string) string {
-	// Remove len(prefix) spaces from the beginning of the indented string
-	return strings.Replace(nindent(spaces, prefix+v), " ", "", len(prefix))
-}
+// RenderObjects renders kubernetes objects utilizing the provided TemplatingData.
+// Objects rendered from the same manifests and data are returned from a cache.
+func (r *textTemplateRenderer) RenderObjects(
+	ctx context.Context, data *TemplatingData) ([]*unstructured.Unstructured, error) {
+	objs := []*unstructured.Unstructured{}
 
-// renderFile renders a single file to a list of k8s unstructured objects
-func (r *textTemplateRenderer) renderFile(filePath string, data *TemplatingData) ([]*unstructured.Unstructured, error) {
-	// Read file
-	txt, err := os.ReadFile(filepath.Clean(filePath))
+	manifests, err := r.source.Manifests(ctx)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to read manifest file %s", filePath)
+		return nil, err
+	}
+	key, cacheable := cacheKey(manifests, data)
+	if cacheable {
+		if cached, ok := r.cache.get(key); ok {
+			return cached, nil
+		}
+	}
+	for _, manifest := range manifests {
+		out, err := r.renderManifest(manifest, data)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, out...)
 	}
+	if cacheable {
+		r.cache.set(key, objs)
+	}
+	return objs, nil
+}
 
+// renderManifest renders a single manifest to a list of k8s unstructured objects
+func (r *textTemplateRenderer) renderManifest(
+	manifest Manifest, data *TemplatingData) ([]*unstructured.Unstructured, error) {
 	// Create a new template
-	tmpl := template.New(path.Base(filePath)).Option("missingkey=error")
-	tmpl.Funcs(template.FuncMap{
-		"yaml": func(obj interface{}) (string, error) {
-			yamlBytes, err := yamlConverter.Marshal(obj)
-			return string(yamlBytes), err
-		},
-		"quote": func(obj interface{}) string {
-			return fmt.Sprintf("%q", obj)
-		},
-		"indent":        indent,
-		"nindent":       nindent,
-		"nindentPrefix": nindentPrefix,
-	})
+	tmpl := template.New(manifest.Name).Option("missingkey=error")
+	tmpl.Funcs(builtinFuncs())
 
 	if data.Funcs != nil {
 		tmpl.Funcs(data.Funcs)
 	}
 
-	if _, err := tmpl.Parse(string(txt)); err != nil {
-		return nil, errors.Wrapf(err, "failed to parse manifest file %s", filePath)
+	if _, err := tmpl.Parse(manifest.Content); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse manifest file %s", manifest.Name)
 	}
 	rendered := bytes.Buffer{}
 
 	if err := tmpl.Execute(&rendered, data.Data); err != nil {
-		return nil, errors.Wrapf(err, "failed to render manifest %s", filePath)
+		// the message of a template failing on purpose is reported as is
+		var failErr *FailError
+		if errors.As(err, &failErr) {
+			return nil, errors.Wrapf(failErr, "failed to render manifest %s", manifest.Name)
+		}
+		return nil, errors.Wrapf(err, "failed to render manifest %s", manifest.Name)
 	}
 
+	return decodeManifest(manifest.Name, &rendered)
+}
+
+// decodeManifest decodes the objects of a rendered manifest, empty objects are skipped
+func decodeManifest(name string, rendered *bytes.Buffer) ([]*unstructured.Unstructured, error) {
 	out := []*unstructured.Unstructured{}
 
 	// special case - if the entire file is whitespace, skip
@@ -146,14 +175,14 @@ func (r *textTemplateRenderer) renderFile(filePath string, data *TemplatingData)
 		return out, nil
 	}
 
-	decoder := yamlDecoder.NewYAMLOrJSONDecoder(&rendered, maxBufSizeForYamlDecode)
+	decoder := yamlDecoder.NewYAMLOrJSONDecoder(rendered, maxBufSizeForYamlDecode)
 	for {
 		u := unstructured.Unstructured{}
 		if err := decoder.Decode(&u); err != nil {
 			if err == io.EOF {
 				break
 			}
-			return nil, errors.Wrapf(err, "failed to unmarshal manifest %s", filePath)
+			return nil, errors.Wrapf(err, "failed to unmarshal manifest %s", name)
 		}
 		// Ensure object is not empty by checking the object kind
 		if u.GetKind() == "" {
@@ -17,9 +17,12 @@ limitations under the License.
 package render_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"text/template"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
@@ -68,7 +71,7 @@ var _ = Describe("Test Renderer via API", func() {
 	Context("Render objects without files", func() {
 		It("Should return no objects", func() {
 			r := render.NewRenderer([]string{})
-			objs, err := r.RenderObjects(t)
+			objs, err := r.RenderObjects(context.Background(), t)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(objs).To(BeEmpty())
 		})
@@ -77,7 +80,7 @@ var _ = Describe("Test Renderer via API", func() {
 	Context("Render objects from non-existent files", func() {
 		It("Should fail", func() {
 			r := render.NewRenderer([]string{filepath.Join(manifestsTestDir, "doesNotExist.yaml")})
-			objs, err := r.RenderObjects(t)
+			objs, err := r.RenderObjects(context.Background(), t)
 			Expect(err).To(HaveOccurred())
 			Expect(objs).To(BeNil())
 		})
@@ -88,7 +91,7 @@ var _ = Describe("Test Renderer via API", func() {
 			files := getFilesFromDir(filepath.Join(manifestsTestDir, "badManifests"))
 			for _, file := range files {
 				r := render.NewRenderer([]string{file})
-				objs, err := r.RenderObjects(t)
+				objs, err := r.RenderObjects(context.Background(), t)
 				Expect(err).To(HaveOccurred())
 				Expect(objs).To(BeNil())
 			}
@@ -98,7 +101,7 @@ var _ = Describe("Test Renderer via API", func() {
 	Context("Render objects from template with invalid template data", func() {
 		It("Should fail", func() {
 			r := render.NewRenderer(getFilesFromDir(filepath.Join(manifestsTestDir, "invalidManifests")))
-			objs, err := r.RenderObjects(t)
+			objs, err := r.RenderObjects(context.Background(), t)
 			Expect(err).To(HaveOccurred())
 			Expect(objs).To(BeNil())
 		})
@@ -107,7 +110,7 @@ var _ = Describe("Test Renderer via API", func() {
 	Context("Render objects from valid manifests dir", func() {
 		It("Should return objects in order as appear in the directory lexicographically", func() {
 			r := render.NewRenderer(getFilesFromDir(filepath.Join(manifestsTestDir, "manifests")))
-			objs, err := r.RenderObjects(t)
+			objs, err := r.RenderObjects(context.Background(), t)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(objs)).To(Equal(3))
 			checkRenderedUnstructured(objs, t.Data.(*templateData))
@@ -117,10 +120,119 @@ var _ = Describe("Test Renderer via API", func() {
 	Context("Render objects from valid manifests dir with mixed file suffixes", func() {
 		It("Should return objects in order as appear in the directory lexicographically", func() {
 			r := render.NewRenderer(getFilesFromDir(filepath.Join(manifestsTestDir, "mixedManifests")))
-			objs, err := r.RenderObjects(t)
+			objs, err := r.RenderObjects(context.Background(), t)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(objs)).To(Equal(3))
 			checkRenderedUnstructured(objs, t.Data.(*templateData))
 		})
 	})
+
+	Context("Render objects using the built-in template functions", func() {
+		type funcsData struct {
+			Name              string
+			Version           string
+			Labels            map[string]string
+			KubernetesVersion string
+		}
+		renderObject := func(data *funcsData) *unstructured.Unstructured {
+			r := render.NewRenderer(getFilesFromDir(filepath.Join(manifestsTestDir, "funcsManifests")))
+			objs, err := r.RenderObjects(context.Background(), &render.TemplatingData{Data: data})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(objs).To(HaveLen(1))
+			return objs[0]
+		}
+
+		It("Should render the template functions", func() {
+			obj := renderObject(&funcsData{Name: "foo", Version: "v1", Labels: map[string]string{"app": "foo"},
+				KubernetesVersion: "v1.29.3"})
+			Expect(obj.GetName()).To(Equal("foo"))
+			Expect(obj.GetLabels()).To(Equal(map[string]string{"app": "foo"}))
+			Expect(obj.Object["spec"]).To(Equal(map[string]interface{}{
+				"version": "v1", "encoded": "Zm9v", "sidecar": "native"}))
+		})
+
+		It("Should use the default values for empty values", func() {
+			obj := renderObject(&funcsData{Labels: map[string]string{"app": "foo"}, KubernetesVersion: "1.27.1"})
+			Expect(obj.GetName()).To(Equal("default-name"))
+			Expect(obj.Object["spec"]).To(HaveKeyWithValue("version", "latest"))
+			Expect(obj.Object["spec"]).To(HaveKeyWithValue("sidecar", "legacy"))
+		})
+
+		It("Should fail on invalid versions", func() {
+			r := render.NewRenderer(getFilesFromDir(filepath.Join(manifestsTestDir, "funcsManifests")))
+			_, err := r.RenderObjects(context.Background(),
+				&render.TemplatingData{Data: &funcsData{KubernetesVersion: "invalid"}})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should let the additional functions override the built-in functions", func() {
+			r := render.NewRenderer(getFilesFromDir(filepath.Join(manifestsTestDir, "funcsManifests")))
+			objs, err := r.RenderObjects(context.Background(), &render.TemplatingData{
+				Funcs: template.FuncMap{"b64enc": func(s string) string { return "custom" }},
+				Data:  &funcsData{Name: "foo", KubernetesVersion: "1.29.0"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(objs[0].Object["spec"]).To(HaveKeyWithValue("encoded", "custom"))
+		})
+	})
+
+	Context("Render objects with the required and fail functions", func() {
+		type requiredData struct {
+			Repository string
+			Spec       *struct{ Image string }
+			Invalid    bool
+		}
+		r := render.NewSourceRenderer(staticSource{{Name: "a.yaml", Content: "kind: TestObj\nmetadata:\n" +
+			"  name: {{ required \"spec.ofedDriver.repository is required\" .Repository }}\n" +
+			"{{- if .Invalid }}{{ fail \"spec.ofedDriver.version is invalid\" }}{{ end }}\n"}})
+
+		It("Should render the required values", func() {
+			objs, err := r.RenderObjects(context.Background(), &render.TemplatingData{Data: &requiredData{Repository: "repo"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(objs).To(HaveLen(1))
+			Expect(objs[0].GetName()).To(Equal("repo"))
+		})
+
+		It("Should report the message of a missing required value", func() {
+			_, err := r.RenderObjects(context.Background(), &render.TemplatingData{Data: &requiredData{}})
+			Expect(err).To(MatchError("failed to render manifest a.yaml: spec.ofedDriver.repository is required"))
+			var failErr *render.FailError
+			Expect(errors.As(err, &failErr)).To(BeTrue())
+		})
+
+		It("Should report the message of a failed template", func() {
+			_, err := r.RenderObjects(context.Background(),
+				&render.TemplatingData{Data: &requiredData{Repository: "repo", Invalid: true}})
+			Expect(err).To(MatchError("failed to render manifest a.yaml: spec.ofedDriver.version is invalid"))
+		})
+
+		It("Should fail on nil pointers", func() {
+			r := render.NewSourceRenderer(staticSource{{Name: "b.yaml",
+				Content: "kind: TestObj\n{{ required \"spec.ofedDriver is required\" .Spec }}\n"}})
+			_, err := r.RenderObjects(context.Background(), &render.TemplatingData{Data: &requiredData{}})
+			Expect(err).To(MatchError("failed to render manifest b.yaml: spec.ofedDriver is required"))
+		})
+	})
+
+	Context("Render objects with the raw renderer", func() {
+		It("Should decode the manifests without templating", func() {
+			r := render.NewRawRenderer(staticSource{
+				{Name: "a.yaml", Content: "kind: TestObj\nmetadata:\n  name: \"{{.Foo}}\"\n---\n---\n" +
+					"kind: TestObj\nmetadata:\n  name: bar\n"},
+				{Name: "b.json", Content: `{"kind": "TestObj", "metadata": {"name": "baz"}}`},
+			})
+			objs, err := r.RenderObjects(context.Background(), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(objs).To(HaveLen(3))
+			Expect(objs[0].GetName()).To(Equal("{{.Foo}}"))
+			Expect(objs[1].GetName()).To(Equal("bar"))
+			Expect(objs[2].GetName()).To(Equal("baz"))
+		})
+
+		It("Should fail on malformed manifests", func() {
+			r := render.NewRawRenderer(staticSource{{Name: "a.yaml", Content: "kind: [TestObj"}})
+			_, err := r.RenderObjects(context.Background(), nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
@@ -0,0 +1,147 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package rendertest compares the objects rendered from manifests with golden files, so that the changes of
+the rendered objects caused by a change of the manifests are reviewed with the change itself.
+
+The golden files hold the rendered objects as a multi-document YAML, in the format of the render command of
+the operator. A test renders the objects for a given custom resource spec or templating data and asserts them
+with AssertGolden, e.g. in a table of cases each compared with its own golden file:
+
+	rendertest.AssertGolden(GinkgoT(), filepath.Join("testdata", "golden", "rdma-cni.yaml"), objs)
+
+The golden files are regenerated from the rendered objects by running the tests with the -update flag:
+
+	go test ./pkg/state/... -args -update
+*/
+package rendertest
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/Mellanox/network-operator/pkg/render"
+)
+
+var update = flag.Bool("update", false, "regenerate the golden files from the rendered objects")
+
+// TestingT is the subset of testing.T used to report the mismatches with the golden files,
+// it is implemented by *testing.T and by GinkgoT()
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// Update returns true if the tests were run with the -update flag, in which case the golden files are
+// overwritten with the rendered objects instead of being compared with them
+func Update() bool {
+	return *update
+}
+
+// Marshal returns the objects as a multi-document YAML, each object is preceded by a document separator and
+// its fields are sorted so that the output doesn't depend on the order of the fields in the manifests
+func Marshal(objs []*unstructured.Unstructured) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	for _, obj := range objs {
+		out, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s %s: %v", obj.GetKind(), obj.GetName(), err)
+		}
+		buf.WriteString("---\n")
+		buf.Write(out)
+	}
+	return buf.Bytes(), nil
+}
+
+// AssertGolden compares the objects with the content of the golden file and reports the first differing line,
+// the golden file and its directory are created if the tests were run with the -update flag
+func AssertGolden(t TestingT, goldenFile string, objs []*unstructured.Unstructured) {
+	t.Helper()
+	actual, err := Marshal(objs)
+	if err != nil {
+		t.Fatalf("%v", err)
+		return
+	}
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(goldenFile), 0o755); err != nil {
+			t.Fatalf("failed to create the directory of golden file %s: %v", goldenFile, err)
+			return
+		}
+		if err := os.WriteFile(goldenFile, actual, 0o600); err != nil {
+			t.Fatalf("failed to update golden file %s: %v", goldenFile, err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(filepath.Clean(goldenFile))
+	if err != nil {
+		t.Fatalf("failed to read golden file %s, run the tests with -update to create it: %v", goldenFile, err)
+		return
+	}
+	if line, ok := firstDiff(expected, actual); !ok {
+		t.Errorf("rendered objects differ from golden file %s at line %d:\n- %s\n+ %s\n"+
+			"run the tests with -update to regenerate the golden file if the change is expected",
+			goldenFile, line.number, line.expected, line.actual)
+	}
+}
+
+// AssertRenderedGolden renders the objects of the manifests with the templating data and compares them with
+// the content of the golden file, see AssertGolden
+func AssertRenderedGolden(t TestingT, renderer render.Renderer, data *render.TemplatingData, goldenFile string) {
+	t.Helper()
+	objs, err := renderer.RenderObjects(context.Background(), data)
+	if err != nil {
+		t.Fatalf("failed to render objects: %v", err)
+		return
+	}
+	AssertGolden(t, goldenFile, objs)
+}
+
+// diffLine is the first line which differs between the golden file and the rendered objects
+type diffLine struct {
+	number           int
+	expected, actual string
+}
+
+// firstDiff returns the first differing line of expected and actual, a missing line is reported as "<EOF>".
+// Returns true if they are equal.
+func firstDiff(expected, actual []byte) (diffLine, bool) {
+	if bytes.Equal(expected, actual) {
+		return diffLine{}, true
+	}
+	expectedLines := bytes.Split(expected, []byte("\n"))
+	actualLines := bytes.Split(actual, []byte("\n"))
+	lineAt := func(lines [][]byte, i int) string {
+		if i < len(lines) {
+			return string(lines[i])
+		}
+		return "<EOF>"
+	}
+	for i := 0; ; i++ {
+		e, a := lineAt(expectedLines, i), lineAt(actualLines, i)
+		if e != a || (i >= len(expectedLines)) != (i >= len(actualLines)) {
+			return diffLine{number: i + 1, expected: e, actual: a}, false
+		}
+	}
+}
@@ -0,0 +1,131 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rendertest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/Mellanox/network-operator/pkg/render"
+)
+
+// recordingT records the failures reported by the assertions
+type recordingT struct {
+	errors []string
+	fatal  bool
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Fatalf(format string, args ...interface{}) {
+	t.Errorf(format, args...)
+	t.fatal = true
+}
+
+type templateData struct {
+	Foo string
+	Bar string
+	Baz string
+}
+
+var _ = Describe("Golden files", func() {
+	var goldenFile string
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":       "ConfigMap",
+		"apiVersion": "v1",
+		"metadata":   map[string]interface{}{"name": "test"},
+	}}
+
+	BeforeEach(func() {
+		goldenFile = filepath.Join(GinkgoT().TempDir(), "golden", "test.yaml")
+	})
+
+	AfterEach(func() {
+		*update = false
+	})
+
+	It("Should marshal the objects as a multi-document YAML with sorted fields", func() {
+		out, err := Marshal([]*unstructured.Unstructured{obj, obj})
+		Expect(err).NotTo(HaveOccurred())
+		doc := "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n"
+		Expect(string(out)).To(Equal(doc + doc))
+	})
+
+	It("Should create the golden file on update", func() {
+		t := &recordingT{}
+		*update = true
+		AssertGolden(t, goldenFile, []*unstructured.Unstructured{obj})
+		Expect(t.errors).To(BeEmpty())
+		Expect(os.ReadFile(goldenFile)).To(BeEquivalentTo("---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n"))
+
+		*update = false
+		AssertGolden(t, goldenFile, []*unstructured.Unstructured{obj})
+		Expect(t.errors).To(BeEmpty())
+	})
+
+	It("Should report the first line differing from the golden file", func() {
+		Expect(os.MkdirAll(filepath.Dir(goldenFile), 0o755)).To(Succeed())
+		Expect(os.WriteFile(goldenFile, []byte("---\napiVersion: v1\nkind: Secret\n"), 0o600)).To(Succeed())
+		t := &recordingT{}
+		AssertGolden(t, goldenFile, []*unstructured.Unstructured{obj})
+		Expect(t.fatal).To(BeFalse())
+		Expect(t.errors).To(ConsistOf(And(ContainSubstring("at line 3"),
+			ContainSubstring("- kind: Secret"), ContainSubstring("+ kind: ConfigMap"))))
+	})
+
+	It("Should report a missing golden file", func() {
+		t := &recordingT{}
+		AssertGolden(t, goldenFile, []*unstructured.Unstructured{obj})
+		Expect(t.fatal).To(BeTrue())
+		Expect(t.errors).To(ConsistOf(ContainSubstring("run the tests with -update to create it")))
+	})
+
+	It("Should compare the objects rendered from the manifests with the golden file", func() {
+		renderer := render.NewRenderer([]string{filepath.Join("..", "testdata", "manifests", "0001_oneObj.yaml")})
+		data := &render.TemplatingData{Data: &templateData{"foo", "bar", "baz"}}
+		t := &recordingT{}
+		*update = true
+		AssertRenderedGolden(t, renderer, data, goldenFile)
+		*update = false
+		AssertRenderedGolden(t, renderer, data, goldenFile)
+		Expect(t.errors).To(BeEmpty())
+
+		AssertRenderedGolden(t, renderer, &render.TemplatingData{Data: &templateData{"foo", "bar", "other"}}, goldenFile)
+		Expect(t.errors).To(ConsistOf(ContainSubstring("+   anotherAttribute: other")))
+	})
+
+	DescribeTable("firstDiff",
+		func(expected, actual string, line int, equal bool) {
+			diff, ok := firstDiff([]byte(expected), []byte(actual))
+			Expect(ok).To(Equal(equal))
+			Expect(diff.number).To(Equal(line))
+		},
+		Entry("equal", "a\nb\n", "a\nb\n", 0, true),
+		Entry("changed line", "a\nb\n", "a\nc\n", 2, false),
+		Entry("missing line", "a\nb\nc", "a\nb", 3, false),
+		Entry("added line", "a\nb", "a\nb\nc", 3, false),
+	)
+})
@@ -0,0 +1,94 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Manifest is a manifest template
+type Manifest struct {
+	// Name of the manifest, manifests of a layered source are rendered ordered by name
+	Name string
+	// Content is the template of the manifest
+	Content string
+}
+
+// ManifestSource provides the manifest templates to render
+type ManifestSource interface {
+	// Manifests returns the manifest templates, the context cancels reading them
+	Manifests(ctx context.Context) ([]Manifest, error)
+}
+
+// NewFilesSource creates a ManifestSource which reads the manifest templates from the given files,
+// the manifests are named after the base name of the files
+func NewFilesSource(files []string) ManifestSource {
+	return &filesSource{files: files}
+}
+
+type filesSource struct {
+	files []string
+}
+
+// Manifests returns the manifest templates read from the files
+func (s *filesSource) Manifests(_ context.Context) ([]Manifest, error) {
+	manifests := make([]Manifest, 0, len(s.files))
+	for _, file := range s.files {
+		txt, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read manifest file %s", file)
+		}
+		manifests = append(manifests, Manifest{Name: filepath.Base(file), Content: string(txt)})
+	}
+	return manifests, nil
+}
+
+// NewLayeredSource creates a ManifestSource which overlays the manifests of the given layers,
+// a manifest overrides the manifest with the same name from the preceding layers.
+// A manifest can be disabled by overriding it with an empty manifest.
+func NewLayeredSource(layers ...ManifestSource) ManifestSource {
+	return &layeredSource{layers: layers}
+}
+
+type layeredSource struct {
+	layers []ManifestSource
+}
+
+// Manifests returns the overlaid manifest templates ordered by name
+func (s *layeredSource) Manifests(ctx context.Context) ([]Manifest, error) {
+	byName := make(map[string]Manifest)
+	for _, layer := range s.layers {
+		manifests, err := layer.Manifests(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, manifest := range manifests {
+			byName[manifest.Name] = manifest
+		}
+	}
+	manifests := make([]Manifest, 0, len(byName))
+	for _, manifest := range byName {
+		manifests = append(manifests, manifest)
+	}
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].Name < manifests[j].Name })
+	return manifests, nil
+}
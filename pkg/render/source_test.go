@@ -0,0 +1,91 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/Mellanox/network-operator/pkg/render"
+)
+
+type staticSource []render.Manifest
+
+func (s staticSource) Manifests(_ context.Context) ([]render.Manifest, error) {
+	return s, nil
+}
+
+const overlayManifest = `
+apiVersion: v1
+kind: TestObj1
+metadata:
+  name: {{.Foo}}-overlay
+spec:
+  attribute: {{.Bar}}
+  anotherAttribute: {{.Baz}}
+`
+
+var _ = Describe("Test Layered Manifest Source", func() {
+	t := &render.TemplatingData{
+		Data: &templateData{"foo", "bar", "baz"},
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		panic("Failed to get CWD")
+	}
+	files := getFilesFromDir(filepath.Join(cwd, "testdata", "manifests"))
+
+	It("Should return the manifests of the files named after the file base name", func() {
+		manifests, err := render.NewFilesSource(files).Manifests(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifests).To(HaveLen(2))
+		Expect(manifests[0].Name).To(Equal("0001_oneObj.yaml"))
+		Expect(manifests[1].Name).To(Equal("0002_twoObj.yaml"))
+	})
+
+	It("Should override a manifest with the same name", func() {
+		source := render.NewLayeredSource(render.NewFilesSource(files),
+			staticSource{{Name: "0001_oneObj.yaml", Content: overlayManifest}})
+		objs, err := render.NewSourceRenderer(source).RenderObjects(context.Background(), t)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(objs).To(HaveLen(3))
+		Expect(objs[0].GetName()).To(Equal("foo-overlay"))
+		Expect(objs[1].GetName()).To(Equal("foo"))
+	})
+
+	It("Should add a manifest ordered by name", func() {
+		source := render.NewLayeredSource(render.NewFilesSource(files),
+			staticSource{{Name: "0000_extra.yaml", Content: overlayManifest}})
+		objs, err := render.NewSourceRenderer(source).RenderObjects(context.Background(), t)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(objs).To(HaveLen(4))
+		Expect(objs[0].GetName()).To(Equal("foo-overlay"))
+	})
+
+	It("Should disable a manifest overridden with an empty manifest", func() {
+		source := render.NewLayeredSource(render.NewFilesSource(files),
+			staticSource{{Name: "0002_twoObj.yaml", Content: ""}})
+		objs, err := render.NewSourceRenderer(source).RenderObjects(context.Background(), t)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(objs).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,3 @@
+{{- define "test-chart.fullname" -}}
+{{ .Release.Name }}-{{ .Chart.Name }}
+{{- end }}
@@ -0,0 +1,123 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/nodeinfo"
+)
+
+// archLabel labels the Pods of the DaemonSets rendered for a CPU architecture
+const archLabel = "nvidia.network-operator.arch"
+
+// splitDaemonSetsByArch renders a copy of each DaemonSet of the component for every CPU architecture with an image
+// override, suffixed with the architecture and restricted to its nodes, the image of the component is replaced
+// with the image of the architecture in its containers. The original DaemonSets are excluded from these nodes.
+func splitDaemonSetsByArch(objs []*unstructured.Unstructured,
+	imageSpec *mellanoxv1alpha1.ImageSpec) ([]*unstructured.Unstructured, error) {
+	archs := imageSpec.ArchImages.Archs()
+	if len(archs) == 0 {
+		return objs, nil
+	}
+	result := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		result = append(result, obj)
+		if obj.GetKind() != "DaemonSet" {
+			continue
+		}
+		for _, arch := range archs {
+			archObj := obj.DeepCopy()
+			archObj.SetName(obj.GetName() + "-" + arch)
+			if err := restrictToArchs(archObj, v1.NodeSelectorOpIn, []string{arch}); err != nil {
+				return nil, err
+			}
+			archSelector := map[string]string{archLabel: arch}
+			if err := mergeStringMap(archObj, archSelector, "spec", "selector", "matchLabels"); err != nil {
+				return nil, err
+			}
+			if err := mergeStringMap(archObj, archSelector, "spec", "template", "metadata", "labels"); err != nil {
+				return nil, err
+			}
+			if err := replaceImage(archObj, imageSpec.ImagePath(), imageSpec.ForArch(arch).ImagePath()); err != nil {
+				return nil, err
+			}
+			result = append(result, archObj)
+		}
+		if err := restrictToArchs(obj, v1.NodeSelectorOpNotIn, archs); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// restrictToArchs adds a kubernetes.io/arch requirement to each required node selector term of the workload
+func restrictToArchs(obj *unstructured.Unstructured, op v1.NodeSelectorOperator, archs []string) error {
+	fields := []string{"spec", "template", "spec", "affinity", "nodeAffinity",
+		"requiredDuringSchedulingIgnoredDuringExecution", "nodeSelectorTerms"}
+	terms, _, err := unstructured.NestedSlice(obj.Object, fields...)
+	if err != nil {
+		return err
+	}
+	if len(terms) == 0 {
+		terms = []interface{}{map[string]interface{}{}}
+	}
+	values := make([]interface{}, 0, len(archs))
+	for _, arch := range archs {
+		values = append(values, arch)
+	}
+	requirement := map[string]interface{}{
+		"key": nodeinfo.NodeLabelCPUArch, "operator": string(op), "values": values}
+	for _, t := range terms {
+		term, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		expressions, _, err := unstructured.NestedSlice(term, "matchExpressions")
+		if err != nil {
+			return err
+		}
+		term["matchExpressions"] = append(expressions, runtime.DeepCopyJSONValue(requirement))
+	}
+	return unstructured.SetNestedSlice(obj.Object, terms, fields...)
+}
+
+// replaceImage replaces the image of the containers and init containers of the workload which use the given image
+func replaceImage(obj *unstructured.Unstructured, image, newImage string) error {
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, found, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", field)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if ok && container["image"] == image {
+				container["image"] = newImage
+			}
+		}
+		if err := unstructured.SetNestedSlice(obj.Object, containers, "spec", "template", "spec", field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/nodeinfo"
+)
+
+var _ = Describe("splitDaemonSetsByArch", func() {
+	var (
+		objs      []*unstructured.Unstructured
+		imageSpec *mellanoxv1alpha1.ImageSpec
+	)
+
+	toDaemonSet := func(obj *unstructured.Unstructured) *appsv1.DaemonSet {
+		ds := &appsv1.DaemonSet{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, ds)).To(Succeed())
+		return ds
+	}
+
+	BeforeEach(func() {
+		ds := &appsv1.DaemonSet{
+			TypeMeta:   metav1.TypeMeta{Kind: "DaemonSet", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "dp", Namespace: "test"},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "dp"}},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "dp"}},
+					Spec: corev1.PodSpec{
+						InitContainers: []corev1.Container{{Name: "wait", Image: "busybox"}},
+						Containers:     []corev1.Container{{Name: "dp", Image: "nvcr.io/nvidia/dp:v1"}},
+					},
+				},
+			},
+		}
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ds)
+		Expect(err).NotTo(HaveOccurred())
+		cm := &unstructured.Unstructured{}
+		cm.SetAPIVersion("v1")
+		cm.SetKind("ConfigMap")
+		cm.SetName("dp-config")
+		objs = []*unstructured.Unstructured{{Object: u}, cm}
+		imageSpec = &mellanoxv1alpha1.ImageSpec{Repository: "nvcr.io/nvidia", Image: "dp", Version: "v1"}
+	})
+
+	It("should not change the objects without image overrides", func() {
+		result, err := splitDaemonSetsByArch(objs, imageSpec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(HaveLen(2))
+		Expect(toDaemonSet(result[0]).Spec.Template.Spec.Affinity).To(BeNil())
+	})
+
+	It("should render a DaemonSet with the image of each overridden architecture", func() {
+		imageSpec.ArchImages = &mellanoxv1alpha1.ArchImagesSpec{
+			ARM64: &mellanoxv1alpha1.ArchImageSpec{Version: "v1-arm64"}}
+		result, err := splitDaemonSetsByArch(objs, imageSpec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(HaveLen(3))
+
+		ds := toDaemonSet(result[0])
+		Expect(ds.Name).To(Equal("dp"))
+		Expect(ds.Spec.Template.Spec.Containers[0].Image).To(Equal("nvcr.io/nvidia/dp:v1"))
+		Expect(ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.
+			NodeSelectorTerms[0].MatchExpressions).To(ConsistOf(corev1.NodeSelectorRequirement{
+			Key: nodeinfo.NodeLabelCPUArch, Operator: corev1.NodeSelectorOpNotIn, Values: []string{"arm64"}}))
+
+		armDs := toDaemonSet(result[1])
+		Expect(armDs.Name).To(Equal("dp-arm64"))
+		Expect(armDs.Spec.Template.Spec.Containers[0].Image).To(Equal("nvcr.io/nvidia/dp:v1-arm64"))
+		Expect(armDs.Spec.Template.Spec.InitContainers[0].Image).To(Equal("busybox"))
+		Expect(armDs.Spec.Selector.MatchLabels).To(HaveKeyWithValue(archLabel, "arm64"))
+		Expect(armDs.Spec.Template.Labels).To(HaveKeyWithValue(archLabel, "arm64"))
+		Expect(armDs.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.
+			NodeSelectorTerms[0].MatchExpressions).To(ConsistOf(corev1.NodeSelectorRequirement{
+			Key: nodeinfo.NodeLabelCPUArch, Operator: corev1.NodeSelectorOpIn, Values: []string{"arm64"}}))
+
+		Expect(result[2].GetKind()).To(Equal("ConfigMap"))
+	})
+
+	It("should add the architecture requirement to each node selector term", func() {
+		imageSpec.ArchImages = &mellanoxv1alpha1.ArchImagesSpec{
+			AMD64: &mellanoxv1alpha1.ArchImageSpec{Repository: "example.com/x86"}}
+		ds := toDaemonSet(objs[0])
+		ds.Spec.Template.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{
+					{Key: "a", Operator: corev1.NodeSelectorOpExists}}},
+				{MatchExpressions: []corev1.NodeSelectorRequirement{
+					{Key: "b", Operator: corev1.NodeSelectorOpExists}}},
+			}}}}
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ds)
+		Expect(err).NotTo(HaveOccurred())
+		objs[0] = &unstructured.Unstructured{Object: u}
+
+		result, err := splitDaemonSetsByArch(objs, imageSpec)
+		Expect(err).NotTo(HaveOccurred())
+		amdDs := toDaemonSet(result[1])
+		Expect(amdDs.Spec.Template.Spec.Containers[0].Image).To(Equal("example.com/x86/dp:v1"))
+		terms := amdDs.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.
+			NodeSelectorTerms
+		Expect(terms).To(HaveLen(2))
+		for _, term := range terms {
+			Expect(term.MatchExpressions).To(HaveLen(2))
+			Expect(term.MatchExpressions[1]).To(Equal(corev1.NodeSelectorRequirement{
+				Key: nodeinfo.NodeLabelCPUArch, Operator: corev1.NodeSelectorOpIn, Values: []string{"amd64"}}))
+		}
+	})
+})
@@ -85,6 +85,9 @@ func assertCommonPodTemplateFields(template *corev1.PodTemplateSpec, image *mell
 		corev1.LocalObjectReference{Name: "secret-two"},
 	))
 
+	// Env
+	Expect(template.Spec.Containers[0].Env).To(ContainElements(image.Env))
+
 	// Container Resources
 	Expect(template.Spec.Containers[0].Resources.Limits).To(Equal(image.ContainerResources[0].Limits))
 	Expect(template.Spec.Containers[0].Resources.Requests).To(Equal(image.ContainerResources[0].Requests))
@@ -125,6 +128,11 @@ func getTestImageSpec() *mellanoxv1alpha1.ImageSpec {
 		Repository:       "repository",
 		Version:          "five",
 		ImagePullSecrets: []string{"secret-one", "secret-two"},
+		Env: []corev1.EnvVar{
+			{Name: "ENV_ONE", Value: "value-one"},
+			{Name: "ENV_TWO", ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"}}},
+		},
 	}
 }
 
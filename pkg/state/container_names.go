@@ -0,0 +1,75 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+//go:generate go run ../../hack/containernames --manifestsDir ../../manifests --output zz_generated.container_names.go
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+// containerResourcesRegex matches the lookups of the resources of a container in the ContainerResources of the
+// render data of a state, either directly or within a `with .RuntimeSpec.ContainerResources` block
+var containerResourcesRegex = regexp.MustCompile(
+	`index \.RuntimeSpec\.ContainerResources "([^"]+)"|` +
+		`\{\{-?\s*with \.RuntimeSpec\.ContainerResources\s*-?\}\}\s*\{\{-?\s*with index \. "([^"]+)"`)
+
+// SupportedContainerNames returns the names of the containers of a state whose resources can be set with
+// containerResources, manifestDir is the name of the manifests directory of the state, e.g. state-ofed-driver.
+// The names are generated from the manifests at build time, see ParseTemplateContainerNames.
+func SupportedContainerNames(manifestDir string) []string {
+	return slices.Clone(containerNames[manifestDir])
+}
+
+// ContainerNames returns the supported container names of all the states keyed by their manifests directory
+func ContainerNames() map[string][]string {
+	names := make(map[string][]string, len(containerNames))
+	for manifestDir := range containerNames {
+		names[manifestDir] = SupportedContainerNames(manifestDir)
+	}
+	return names
+}
+
+// ParseTemplateContainerNames returns the sorted names of the containers whose resources are looked up in the
+// ContainerResources by the templates of the manifests directory, without rendering the manifests
+func ParseTemplateContainerNames(manifestDir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(manifestDir, "*"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list manifests")
+	}
+	var names []string
+	for _, file := range files {
+		//nolint:gosec
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read manifest %s", file)
+		}
+		for _, match := range containerResourcesRegex.FindAllStringSubmatch(string(data), -1) {
+			name := match[1] + match[2]
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
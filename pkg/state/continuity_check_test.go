@@ -19,6 +19,7 @@ package state
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"os"
 	"path/filepath"
 	"sort"
@@ -27,6 +28,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"gopkg.in/yaml.v3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
 	"github.com/Mellanox/network-operator/pkg/config"
@@ -124,6 +126,7 @@ var _ = Describe("Continuity check", func() {
 			cr.Name = "nic-cluster-policy"
 			imageSpec := mellanoxv1alpha1.ImageSpec{Image: "image", Repository: "", Version: "version"}
 			imageSpecWithConfig := mellanoxv1alpha1.ImageSpecWithConfig{ImageSpec: imageSpec}
+			cniPluginSpec := mellanoxv1alpha1.CNIPluginSpec{ImageSpec: imageSpec}
 			cr.Spec.IBKubernetes = &mellanoxv1alpha1.IBKubernetesSpec{ImageSpec: imageSpec}
 			cr.Spec.OFEDDriver = &mellanoxv1alpha1.OFEDDriverSpec{ImageSpec: imageSpec}
 			cr.Spec.RdmaSharedDevicePlugin = &mellanoxv1alpha1.DevicePluginSpec{ImageSpecWithConfig: imageSpecWithConfig}
@@ -131,19 +134,26 @@ var _ = Describe("Continuity check", func() {
 			cr.Spec.NvIpam = &mellanoxv1alpha1.NVIPAMSpec{ImageSpec: imageSpec}
 			cr.Spec.NicFeatureDiscovery = &mellanoxv1alpha1.NICFeatureDiscoverySpec{ImageSpec: imageSpec}
 			cr.Spec.SecondaryNetwork = &mellanoxv1alpha1.SecondaryNetworkSpec{}
-			cr.Spec.SecondaryNetwork.CniPlugins = &imageSpec
-			cr.Spec.SecondaryNetwork.IpamPlugin = &imageSpec
-			cr.Spec.SecondaryNetwork.IPoIB = &imageSpec
+			cr.Spec.SecondaryNetwork.CniPlugins = &cniPluginSpec
+			cr.Spec.SecondaryNetwork.IpamPlugin = &mellanoxv1alpha1.WhereaboutsSpec{ImageSpec: imageSpec}
+			cr.Spec.SecondaryNetwork.IPoIB = &cniPluginSpec
+			cr.Spec.SecondaryNetwork.OVSCni = &cniPluginSpec
+			cr.Spec.SecondaryNetwork.RdmaCni = &cniPluginSpec
 			cr.Spec.SecondaryNetwork.Multus = &mellanoxv1alpha1.MultusSpec{ImageSpecWithConfig: imageSpecWithConfig}
 			cr.Spec.DOCATelemetryService = &mellanoxv1alpha1.DOCATelemetryServiceSpec{ImageSpec: imageSpec}
+			cr.Spec.NicConfigurationDaemon = &mellanoxv1alpha1.NICConfigurationDaemonSpec{ImageSpec: imageSpec}
 
 			manifestsBaseDir := filepath.Join("..", "..", "manifests")
-			envConfig = &config.OperatorConfig{State: config.StateConfig{ManifestBaseDir: manifestsBaseDir}}
+			origConfig := envConfig
+			defer func() { envConfig = origConfig }()
+			envConfig = func() *config.OperatorConfig {
+				return &config.OperatorConfig{State: config.StateConfig{ManifestBaseDir: manifestsBaseDir}}
+			}
 			states, err := newNicClusterPolicyStates(nil)
 			Expect(err).NotTo(HaveOccurred())
 
 			for _, state := range states {
-				names, err := ParseContainerNames(state.(ManifestRenderer), cr, testLogger)
+				names, err := ParseContainerNames(context.Background(), state.(ManifestRenderer), cr, testLogger)
 				Expect(err).NotTo(HaveOccurred())
 				namesFromManifests = append(namesFromManifests, names...)
 			}
@@ -153,5 +163,41 @@ var _ = Describe("Continuity check", func() {
 			Expect(namesFromChart).To(Equal(namesFromManifests))
 
 		})
+		It("Generated container names should match the templates of the manifests", func() {
+			manifestsDirs, err := filepath.Glob(filepath.Join("..", "..", "manifests", "state-*"))
+			Expect(err).NotTo(HaveOccurred())
+			namesFromTemplates := map[string][]string{}
+			for _, manifestsDir := range manifestsDirs {
+				names, err := ParseTemplateContainerNames(manifestsDir)
+				Expect(err).NotTo(HaveOccurred())
+				if len(names) > 0 {
+					namesFromTemplates[filepath.Base(manifestsDir)] = names
+				}
+			}
+			Expect(ContainerNames()).To(Equal(namesFromTemplates),
+				"container names are out of date, run 'go generate ./pkg/state'")
+		})
+		It("Generated container names should include the rendered containers", func() {
+			cr := &mellanoxv1alpha1.NicClusterPolicy{}
+			cr.Name = "nic-cluster-policy"
+			imageSpec := mellanoxv1alpha1.ImageSpec{Image: "image", Repository: "", Version: "version"}
+			cr.Spec.OFEDDriver = &mellanoxv1alpha1.OFEDDriverSpec{ImageSpec: imageSpec}
+			cr.Spec.NvIpam = &mellanoxv1alpha1.NVIPAMSpec{ImageSpec: imageSpec}
+			cr.Spec.SecondaryNetwork = &mellanoxv1alpha1.SecondaryNetworkSpec{
+				IpamPlugin: &mellanoxv1alpha1.WhereaboutsSpec{ImageSpec: imageSpec, EnableNodeSlicing: true}}
+
+			manifestsBaseDir := filepath.Join("..", "..", "manifests")
+			for manifestsDir, newState := range map[string]func(client.Client, string) (State, ManifestRenderer, error){
+				"state-ofed-driver":     NewStateOFED,
+				"state-nv-ipam-cni":     NewStateNVIPAMCNI,
+				"state-whereabouts-cni": NewStateWhereaboutsCNI,
+			} {
+				_, renderer, err := newState(nil, filepath.Join(manifestsBaseDir, manifestsDir))
+				Expect(err).NotTo(HaveOccurred())
+				names, err := ParseContainerNames(context.Background(), renderer, cr, testLogger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(SupportedContainerNames(manifestsDir)).To(ContainElements(names))
+			}
+		})
 	})
 })
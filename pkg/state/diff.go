@@ -0,0 +1,104 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// getObjectDiff returns the fields set in the desired object which differ from the live object,
+// formatted as "<path>: <live value> -> <desired value>" and ordered by path.
+// Fields are compared with the semantics of isDrifted, e.g. fields which are only set in the live object are ignored.
+func getObjectDiff(desired, live *unstructured.Unstructured) []string {
+	diff := make([]string, 0)
+	diffFields("", desired.Object, live.Object, &diff)
+	return diff
+}
+
+func diffFields(path string, desired, live interface{}, diff *[]string) {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			if !isZero(desired) {
+				*diff = append(*diff, formatFieldDiff(path, live, desired))
+			}
+			return
+		}
+		keys := make([]string, 0, len(d))
+		for key := range d {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			lVal, ok := l[key]
+			if !ok {
+				if !isZero(d[key]) {
+					*diff = append(*diff, formatFieldDiff(fieldPath, nil, d[key]))
+				}
+				continue
+			}
+			diffFields(fieldPath, d[key], lVal, diff)
+		}
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			if !isZero(desired) {
+				*diff = append(*diff, formatFieldDiff(path, live, desired))
+			}
+			return
+		}
+		if len(d) != len(l) {
+			*diff = append(*diff, fmt.Sprintf("%s: %d items -> %d items", path, len(l), len(d)))
+			return
+		}
+		for i := range d {
+			diffFields(fmt.Sprintf("%s[%d]", path, i), d[i], l[i], diff)
+		}
+	default:
+		if isDrifted(desired, live) {
+			*diff = append(*diff, formatFieldDiff(path, live, desired))
+		}
+	}
+}
+
+// formatFieldDiff formats the change of a field, maps and lists are summarized
+func formatFieldDiff(path string, live, desired interface{}) string {
+	return fmt.Sprintf("%s: %s -> %s", path, formatFieldValue(live), formatFieldValue(desired))
+}
+
+func formatFieldValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "<unset>"
+	case map[string]interface{}:
+		return fmt.Sprintf("<map with %d keys>", len(val))
+	case []interface{}:
+		return fmt.Sprintf("<list with %d items>", len(val))
+	case string:
+		return fmt.Sprintf("%q", val)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
@@ -0,0 +1,90 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/Mellanox/network-operator/pkg/config"
+)
+
+var _ = Describe("Object diff", func() {
+	var (
+		desired *unstructured.Unstructured
+		live    *unstructured.Unstructured
+	)
+
+	BeforeEach(func() {
+		desired = testDaemonSet()
+		live = desired.DeepCopy()
+		live.SetResourceVersion("10")
+		Expect(unstructured.SetNestedField(live.Object, int64(10), "spec", "revisionHistoryLimit")).To(Succeed())
+	})
+
+	It("Should not report fields which are only set in the live object", func() {
+		Expect(getObjectDiff(desired, live)).To(BeEmpty())
+	})
+
+	It("Should report the changed fields ordered by path", func() {
+		desired.SetLabels(map[string]string{"app": "test"})
+		containers, _, _ := unstructured.NestedSlice(desired.Object, "spec", "template", "spec", "containers")
+		containers[1].(map[string]interface{})["image"] = "sidecar:v2"
+		Expect(unstructured.SetNestedSlice(desired.Object, containers,
+			"spec", "template", "spec", "containers")).To(Succeed())
+		Expect(getObjectDiff(desired, live)).To(Equal([]string{
+			`metadata.labels: <unset> -> <map with 1 keys>`,
+			`spec.template.spec.containers[1].image: "sidecar:v1" -> "sidecar:v2"`,
+		}))
+	})
+
+	It("Should summarize lists with a different number of items", func() {
+		containers, _, _ := unstructured.NestedSlice(desired.Object, "spec", "template", "spec", "containers")
+		Expect(unstructured.SetNestedSlice(desired.Object, containers[:1],
+			"spec", "template", "spec", "containers")).To(Succeed())
+		Expect(getObjectDiff(desired, live)).To(Equal([]string{
+			"spec.template.spec.containers: 2 items -> 1 items",
+		}))
+	})
+
+	It("Should record the changes of updated objects if enabled", func() {
+		origConfig := envConfig
+		defer func() { envConfig = origConfig }()
+		envConfig = func() *config.OperatorConfig {
+			return &config.OperatorConfig{State: config.StateConfig{DiffEvents: true}}
+		}
+		s := stateSkel{name: testState, client: fake.NewClientBuilder().Build()}
+		setControllerReference := func(obj *unstructured.Unstructured) error { return nil }
+		ctx, recorder := withDriftRecorder(context.Background())
+		Expect(s.createOrUpdateObjs(ctx, setControllerReference,
+			[]*unstructured.Unstructured{testDaemonSet()}, nil)).To(Succeed())
+		Expect(recorder.updated()).To(BeEmpty())
+
+		updated := testDaemonSet()
+		Expect(unstructured.SetNestedField(updated.Object, true, "spec", "template", "spec", "hostNetwork")).
+			To(Succeed())
+		Expect(s.createOrUpdateObjs(ctx, setControllerReference,
+			[]*unstructured.Unstructured{updated}, nil)).To(Succeed())
+		Expect(recorder.updated()).To(ConsistOf(And(
+			HavePrefix("DaemonSet test/test-ds: "),
+			ContainSubstring("spec.template.spec.hostNetwork: <unset> -> true"))))
+	})
+})
@@ -0,0 +1,205 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type driftRecorderKey struct{}
+
+// driftRecorder collects the objects of a state which drifted from their desired state during a sync,
+// and the changes of the updated objects
+type driftRecorder struct {
+	mu      sync.Mutex
+	objects []string
+	updates []string
+}
+
+// withDriftRecorder returns a context which records the drifted objects in the returned driftRecorder
+func withDriftRecorder(ctx context.Context) (context.Context, *driftRecorder) {
+	recorder := &driftRecorder{}
+	return context.WithValue(ctx, driftRecorderKey{}, recorder), recorder
+}
+
+// recordDrift records the object as drifted in the driftRecorder of the context, if any
+func recordDrift(ctx context.Context, obj *unstructured.Unstructured) {
+	recorder, ok := ctx.Value(driftRecorderKey{}).(*driftRecorder)
+	if !ok {
+		return
+	}
+	name := obj.GetName()
+	if obj.GetNamespace() != "" {
+		name = obj.GetNamespace() + "/" + name
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.objects = append(recorder.objects, obj.GetKind()+" "+name)
+}
+
+// recordUpdate records the changed fields of the updated object in the driftRecorder of the context, if any
+func recordUpdate(ctx context.Context, obj *unstructured.Unstructured, diff []string) {
+	recorder, ok := ctx.Value(driftRecorderKey{}).(*driftRecorder)
+	if !ok {
+		return
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.updates = append(recorder.updates,
+		fmt.Sprintf("%s %s: %s", obj.GetKind(), getObjectName(obj), strings.Join(diff, ", ")))
+}
+
+// updated returns the recorded changes of the updated objects
+func (r *driftRecorder) updated() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.updates
+}
+
+// drifted returns the recorded drifted objects
+func (r *driftRecorder) drifted() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.objects
+}
+
+// driftedMetadataFields are the metadata fields of an object which are checked for drift
+var driftedMetadataFields = []string{"labels", "annotations", "ownerReferences"}
+
+// isObjectDrifted returns true if the live object drifted from the desired object.
+// Only the labels, annotations and owner references of the object metadata are checked.
+func isObjectDrifted(desired, live *unstructured.Unstructured) bool {
+	desiredObj := make(map[string]interface{}, len(desired.Object))
+	for key, val := range desired.Object {
+		desiredObj[key] = val
+	}
+	desiredMeta := make(map[string]interface{})
+	if meta, ok := desired.Object["metadata"].(map[string]interface{}); ok {
+		for _, field := range driftedMetadataFields {
+			if val, ok := meta[field]; ok {
+				desiredMeta[field] = val
+			}
+		}
+	}
+	desiredObj["metadata"] = desiredMeta
+	return isDrifted(desiredObj, live.Object)
+}
+
+// isDrifted returns true if a field set in the desired object differs from the live object.
+// Fields which are only set in the live object, e.g. defaulted by the API server, are ignored,
+// as well as zero values in the desired object which are missing in the live object.
+func isDrifted(desired, live interface{}) bool {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return !isZero(desired)
+		}
+		for key, dVal := range d {
+			lVal, ok := l[key]
+			if !ok {
+				if !isZero(dVal) {
+					return true
+				}
+				continue
+			}
+			if isDrifted(dVal, lVal) {
+				return true
+			}
+		}
+		return false
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			return !isZero(desired)
+		}
+		if len(d) != len(l) {
+			return true
+		}
+		for i := range d {
+			if isDrifted(d[i], l[i]) {
+				return true
+			}
+		}
+		return false
+	case string:
+		l, ok := live.(string)
+		if !ok {
+			return true
+		}
+		return d != l && !isEqualQuantity(d, l)
+	case int64, float64:
+		return !isEqualNumber(d, live)
+	case nil:
+		return false
+	default:
+		return !reflect.DeepEqual(desired, live)
+	}
+}
+
+func isZero(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return len(val) == 0
+	case []interface{}:
+		return len(val) == 0
+	default:
+		return reflect.ValueOf(v).IsZero()
+	}
+}
+
+func isEqualNumber(a, b interface{}) bool {
+	toFloat := func(v interface{}) (float64, bool) {
+		switch n := v.(type) {
+		case int64:
+			return float64(n), true
+		case float64:
+			return n, true
+		}
+		return 0, false
+	}
+	af, ok := toFloat(a)
+	if !ok {
+		return false
+	}
+	bf, ok := toFloat(b)
+	return ok && af == bf
+}
+
+// isEqualQuantity returns true if both values are equal quantities, the API server normalizes quantities
+// e.g. 1000m to 1
+func isEqualQuantity(a, b string) bool {
+	aq, err := resource.ParseQuantity(a)
+	if err != nil {
+		return false
+	}
+	bq, err := resource.ParseQuantity(b)
+	if err != nil {
+		return false
+	}
+	return aq.Cmp(bq) == 0
+}
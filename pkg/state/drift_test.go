@@ -0,0 +1,112 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Drift detection", func() {
+	var (
+		desired *unstructured.Unstructured
+		live    *unstructured.Unstructured
+	)
+
+	BeforeEach(func() {
+		desired = testDaemonSet()
+		live = desired.DeepCopy()
+		// fields set by the API server
+		live.SetResourceVersion("10")
+		live.SetUID("uid")
+		Expect(unstructured.SetNestedField(live.Object, int64(10), "spec", "revisionHistoryLimit")).To(Succeed())
+	})
+
+	It("Should not detect drift for fields which are only set in the live object", func() {
+		live.SetAnnotations(map[string]string{"deprecated.daemonset.template.generation": "1"})
+		Expect(isObjectDrifted(desired, live)).To(BeFalse())
+	})
+
+	It("Should not detect drift for metadata fields set in the desired object", func() {
+		desired.SetResourceVersion("1")
+		Expect(isObjectDrifted(desired, live)).To(BeFalse())
+	})
+
+	It("Should detect drift of a changed field", func() {
+		containers, _, _ := unstructured.NestedSlice(live.Object, "spec", "template", "spec", "containers")
+		containers[0].(map[string]interface{})["image"] = "main:v2"
+		Expect(unstructured.SetNestedSlice(live.Object, containers,
+			"spec", "template", "spec", "containers")).To(Succeed())
+		Expect(isObjectDrifted(desired, live)).To(BeTrue())
+	})
+
+	It("Should detect drift of an added list item", func() {
+		containers, _, _ := unstructured.NestedSlice(live.Object, "spec", "template", "spec", "containers")
+		containers = append(containers, map[string]interface{}{"name": "debug", "image": "debug:v1"})
+		Expect(unstructured.SetNestedSlice(live.Object, containers,
+			"spec", "template", "spec", "containers")).To(Succeed())
+		Expect(isObjectDrifted(desired, live)).To(BeTrue())
+	})
+
+	It("Should detect drift of a removed label", func() {
+		desired.SetLabels(map[string]string{"app": "test"})
+		Expect(isObjectDrifted(desired, live)).To(BeTrue())
+	})
+
+	It("Should compare numbers and quantities by value", func() {
+		Expect(isDrifted(map[string]interface{}{"cpu": "1000m", "replicas": int64(1)},
+			map[string]interface{}{"cpu": "1", "replicas": float64(1)})).To(BeFalse())
+		Expect(isDrifted(map[string]interface{}{"cpu": "500m"}, map[string]interface{}{"cpu": "1"})).To(BeTrue())
+	})
+
+	It("Should ignore zero values missing in the live object", func() {
+		Expect(isDrifted(map[string]interface{}{"resources": map[string]interface{}{}, "hostNetwork": false},
+			map[string]interface{}{})).To(BeFalse())
+	})
+
+	It("Should reapply drifted object and record it", func() {
+		s := stateSkel{name: testState, client: fake.NewClientBuilder().Build()}
+		setControllerReference := func(obj *unstructured.Unstructured) error { return nil }
+		ctx := context.Background()
+		Expect(s.createOrUpdateObjs(ctx, setControllerReference,
+			[]*unstructured.Unstructured{testDaemonSet()}, nil)).To(Succeed())
+
+		key := types.NamespacedName{Name: "test-ds", Namespace: "test"}
+		ds := &appsv1.DaemonSet{}
+		Expect(s.client.Get(ctx, key, ds)).To(Succeed())
+		ds.Spec.Template.Spec.Containers[0].Image = "main:edited"
+		Expect(s.client.Update(ctx, ds)).To(Succeed())
+
+		driftCtx, recorder := withDriftRecorder(ctx)
+		Expect(s.createOrUpdateObjs(driftCtx, setControllerReference,
+			[]*unstructured.Unstructured{testDaemonSet()}, nil)).To(Succeed())
+		Expect(recorder.drifted()).To(Equal([]string{"DaemonSet test/test-ds"}))
+		Expect(s.client.Get(ctx, key, ds)).To(Succeed())
+		Expect(ds.Spec.Template.Spec.Containers[0].Image).To(Equal("main:v1"))
+
+		driftCtx, recorder = withDriftRecorder(ctx)
+		Expect(s.createOrUpdateObjs(driftCtx, setControllerReference,
+			[]*unstructured.Unstructured{testDaemonSet()}, nil)).To(Succeed())
+		Expect(recorder.drifted()).To(BeEmpty())
+	})
+})
@@ -39,7 +39,7 @@ func (d *dummyProvider) IsOpenshift() bool {
 }
 
 func (d *dummyProvider) GetStaticConfig() staticconfig.StaticConfig {
-	return staticconfig.StaticConfig{CniBinDirectory: ""}
+	return staticconfig.StaticConfig{CniBinDirectory: "", ClusterType: clustertype.Kubernetes}
 }
 
 func (d *dummyProvider) GetNodesAttributes(...nodeinfo.Filter) []nodeinfo.NodeAttributes {
@@ -65,6 +65,10 @@ func (d *dummyProvider) TagExists(_ string) bool {
 	return false
 }
 
+func (d *dummyProvider) ResolveVersion(_ string) (string, bool) {
+	return "", false
+}
+
 func (d *dummyProvider) SetImageSpec(_ *v1alpha1.ImageSpec) {}
 
 func getDummyCatalog() InfoCatalog {
@@ -0,0 +1,156 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ErrorClass is the class of the error of a failed state sync, it is used as the reason of the status conditions
+// and as a metrics label
+type ErrorClass string
+
+// Error classes, the class of an error is the class of the first typed error found in its chain
+const (
+	// ErrorClassRender is the class of RenderError
+	ErrorClassRender ErrorClass = "RenderFailed"
+	// ErrorClassDryRun is the class of DryRunError
+	ErrorClassDryRun ErrorClass = "DryRunFailed"
+	// ErrorClassApply is the class of ApplyError
+	ErrorClassApply ErrorClass = "ApplyFailed"
+	// ErrorClassStaleObject is the class of StaleObjectError
+	ErrorClassStaleObject ErrorClass = "StaleObjectRemovalFailed"
+	// ErrorClassTimeout is the class of TimeoutError
+	ErrorClassTimeout ErrorClass = "SyncTimedOut"
+	// ErrorClassUnknown is the class of the errors which are not typed
+	ErrorClassUnknown ErrorClass = "SyncFailed"
+)
+
+// RenderError is returned by a state when its objects could not be rendered from its manifests,
+// e.g. because of a missing information source or an invalid template
+type RenderError struct {
+	Err error
+}
+
+func (e *RenderError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RenderError) Unwrap() error {
+	return e.Err
+}
+
+// DryRunError is returned by a state when the server-side dry-run of one of its objects failed,
+// in which case none of the objects of the state were created or updated.
+type DryRunError struct {
+	// Object is the kind and name of the rejected object
+	Object string
+	Err    error
+}
+
+func (e *DryRunError) Error() string {
+	return fmt.Sprintf("dry-run of %s failed: %v", e.Object, e.Err)
+}
+
+func (e *DryRunError) Unwrap() error {
+	return e.Err
+}
+
+// ApplyError is returned by a state when one of its objects could not be created or updated
+type ApplyError struct {
+	GVK schema.GroupVersionKind
+	// Name of the object, prefixed with its namespace for namespaced objects
+	Name string
+	Err  error
+}
+
+func (e *ApplyError) Error() string {
+	return fmt.Sprintf("failed to apply %s %s: %v", e.GVK.Kind, e.Name, e.Err)
+}
+
+func (e *ApplyError) Unwrap() error {
+	return e.Err
+}
+
+// StaleObjectError is returned by a state when one of its objects which is no longer rendered could not be deleted
+type StaleObjectError struct {
+	GVK schema.GroupVersionKind
+	// Name of the object, prefixed with its namespace for namespaced objects
+	Name string
+	Err  error
+}
+
+func (e *StaleObjectError) Error() string {
+	return fmt.Sprintf("failed to delete %s %s: %v", e.GVK.Kind, e.Name, e.Err)
+}
+
+func (e *StaleObjectError) Unwrap() error {
+	return e.Err
+}
+
+// TimeoutError is returned when the sync of a state didn't complete within its sync timeout,
+// it wraps the error returned by the state once its pending API calls were cancelled
+type TimeoutError struct {
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("sync timed out after %s: %v", e.Timeout, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// ClassifyError returns the class of the error, or an empty class if err is nil
+func ClassifyError(err error) ErrorClass {
+	var (
+		timeoutErr *TimeoutError
+		renderErr  *RenderError
+		dryRunErr  *DryRunError
+		applyErr   *ApplyError
+		staleErr   *StaleObjectError
+	)
+	switch {
+	case err == nil:
+		return ""
+	// the failure which caused a timeout is a consequence of the timeout
+	case errors.As(err, &timeoutErr):
+		return ErrorClassTimeout
+	case errors.As(err, &renderErr):
+		return ErrorClassRender
+	case errors.As(err, &dryRunErr):
+		return ErrorClassDryRun
+	case errors.As(err, &applyErr):
+		return ErrorClassApply
+	case errors.As(err, &staleErr):
+		return ErrorClassStaleObject
+	default:
+		return ErrorClassUnknown
+	}
+}
+
+// joinErrors aggregates the errors in a single error, each of them can be matched with errors.As.
+// Returns nil if there are no errors.
+func joinErrors(errs []error) error {
+	return errors.Join(errs...)
+}
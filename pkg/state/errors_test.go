@@ -0,0 +1,65 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("Errors tests", func() {
+	gvk := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "DaemonSet"}
+
+	DescribeTable("ClassifyError",
+		func(err error, class ErrorClass) {
+			Expect(ClassifyError(err)).To(Equal(class))
+		},
+		Entry("no error", nil, ErrorClass("")),
+		Entry("untyped error", errors.New("error"), ErrorClassUnknown),
+		Entry("wrapped render error", errors.Wrap(&RenderError{Err: errors.New("error")}, "failed"),
+			ErrorClassRender),
+		Entry("dry-run error", &DryRunError{Object: "DaemonSet test/ds", Err: errors.New("error")},
+			ErrorClassDryRun),
+		Entry("wrapped apply error", errors.Wrap(&ApplyError{GVK: gvk, Name: "test/ds", Err: errors.New("error")},
+			"failed"), ErrorClassApply),
+		Entry("aggregated stale object errors", joinErrors([]error{
+			&StaleObjectError{GVK: gvk, Name: "test/ds", Err: errors.New("error")},
+			&StaleObjectError{GVK: gvk, Name: "test/other", Err: errors.New("error")}}), ErrorClassStaleObject),
+	)
+
+	It("Should keep the message of the wrapped errors", func() {
+		Expect((&RenderError{Err: errors.New("invalid template")}).Error()).To(Equal("invalid template"))
+		Expect((&ApplyError{GVK: gvk, Name: "test/ds", Err: errors.New("invalid")}).Error()).
+			To(Equal("failed to apply DaemonSet test/ds: invalid"))
+		Expect(joinErrors(nil)).To(BeNil())
+	})
+
+	It("Should group the failed states by error class", func() {
+		results := Results{StatesStatus: []Result{
+			{StateName: "a", Status: SyncStateError, ErrClass: ErrorClassApply},
+			{StateName: "b", Status: SyncStateReady},
+			{StateName: "c", Status: SyncStateNotReady, ErrClass: ErrorClassRender},
+			{StateName: "d", Status: SyncStateError, ErrClass: ErrorClassApply},
+		}}
+		Expect(results.ErrorsByClass()).To(Equal(map[ErrorClass][]string{
+			ErrorClassApply:  {"a", "d"},
+			ErrorClassRender: {"c"},
+		}))
+	})
+})
@@ -22,6 +22,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
@@ -29,11 +30,13 @@ import (
 	"github.com/Mellanox/network-operator/pkg/consts"
 )
 
-var envConfig = config.FromEnv()
+// envConfig returns the current configuration of the Operator, the configuration can change at runtime
+var envConfig = config.FromEnv
 
-// NewManager creates a state.Manager for the given CRD Kind
-func NewManager(
-	crdKind string, k8sAPIClient client.Client, setupLog logr.Logger) (Manager, error) {
+// NewManager creates a state.Manager for the given CRD Kind.
+// Events about states which fail to sync are emitted on the custom resource using the eventRecorder, if not nil.
+func NewManager(crdKind string, k8sAPIClient client.Client, eventRecorder record.EventRecorder,
+	setupLog logr.Logger) (Manager, error) {
 	states, err := newStates(crdKind, k8sAPIClient)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create state manager")
@@ -47,9 +50,16 @@ func NewManager(
 		setupLog.V(consts.LogLevelDebug).Info("Creating a new State manager with", "states:", stateNames)
 	}
 
+	if _, err := orderStates(states); err != nil {
+		return nil, errors.Wrapf(err, "failed to create state manager")
+	}
+
 	return &stateManager{
-		states: states,
-		client: k8sAPIClient,
+		crdKind:     crdKind,
+		states:      states,
+		client:      k8sAPIClient,
+		syncWorkers: envConfig().State.SyncWorkers,
+		events:      newSyncEventEmitter(eventRecorder, envConfig().State.NotReadyEventThreshold),
 	}, nil
 }
 
@@ -70,9 +80,10 @@ func newStates(crdKind string, k8sAPIClient client.Client) ([]State, error) {
 	return nil, fmt.Errorf("unsupported CRD for states factory: %s", crdKind)
 }
 
-// newNicClusterPolicyStates creates states that reconcile NicClusterPolicy CRD
+// newNicClusterPolicyStates creates states that reconcile NicClusterPolicy CRD,
+// states added here should be added to offlineStates as well
 func newNicClusterPolicyStates(k8sAPIClient client.Client) ([]State, error) {
-	manifestBaseDir := envConfig.State.ManifestBaseDir
+	manifestBaseDir := envConfig().State.ManifestBaseDir
 	ofedState, _, err := NewStateOFED(
 		k8sAPIClient, filepath.Join(manifestBaseDir, "state-ofed-driver"))
 	if err != nil {
@@ -104,6 +115,16 @@ func newNicClusterPolicyStates(k8sAPIClient client.Client) ([]State, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create Container Networking CNI Plugins State")
 	}
+	ovsCniState, _, err := NewStateOVSCNI(
+		k8sAPIClient, filepath.Join(manifestBaseDir, "state-ovs-cni"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create OVS CNI State")
+	}
+	rdmaCniState, _, err := NewStateRDMACNI(
+		k8sAPIClient, filepath.Join(manifestBaseDir, "state-rdma-cni"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create RDMA CNI State")
+	}
 	whereaboutState, _, err := NewStateWhereaboutsCNI(
 		k8sAPIClient, filepath.Join(manifestBaseDir, "state-whereabouts-cni"))
 	if err != nil {
@@ -129,10 +150,36 @@ func newNicClusterPolicyStates(k8sAPIClient client.Client) ([]State, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create doca-telemetry-service State")
 	}
+	nicConfigurationDaemonState, _, err := NewStateNICConfigurationDaemon(
+		k8sAPIClient, filepath.Join(manifestBaseDir, "state-nic-configuration-daemon"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create nic-configuration-daemon State")
+	}
+	openshiftState, _, err := NewStateOpenshift(
+		k8sAPIClient, filepath.Join(manifestBaseDir, "state-openshift"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create OpenShift State")
+	}
+	nodeFeatureDiscoveryState, _, err := NewStateNodeFeatureDiscovery(
+		k8sAPIClient, filepath.Join(manifestBaseDir, stateNodeFeatureDiscoveryName))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create node-feature-discovery State")
+	}
+	nodeFeatureRulesState, _, err := NewStateNodeFeatureRules(
+		k8sAPIClient, filepath.Join(manifestBaseDir, "state-node-feature-rules"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create node-feature-rules State")
+	}
+	additionalManifestsState, _, err := NewStateAdditionalManifests(
+		k8sAPIClient, filepath.Join(manifestBaseDir, "state-additional-manifests"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create additional-manifests State")
+	}
 	return []State{
-		multusState, cniPluginsState, ipoibState, whereaboutState,
+		openshiftState, multusState, cniPluginsState, ipoibState, ovsCniState, rdmaCniState, whereaboutState,
 		ofedState, sriovDpState, sharedDpState, ibKubernetesState, nvIpamCniState,
-		nicFeatureDiscoveryState, docaTelemetryServiceState}, nil
+		nicFeatureDiscoveryState, docaTelemetryServiceState, nicConfigurationDaemonState, nodeFeatureDiscoveryState,
+		nodeFeatureRulesState, additionalManifestsState}, nil
 }
 
 // newMacvlanNetworkStates creates states that reconcile MacvlanNetwork CRD
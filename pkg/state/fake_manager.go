@@ -18,19 +18,17 @@ package state
 
 import (
 	"context"
-
-	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 //nolint:unused
 type fakeMananger struct {
-	watchResources map[string]client.Object
+	watchResources map[string]WatchSource
 }
 
 // GetWatchSources gets Resources that should be watched by a Controller for this state manager
 //
 //nolint:unused
-func (m *fakeMananger) GetWatchSources() map[string]client.Object {
+func (m *fakeMananger) GetWatchSources() map[string]WatchSource {
 	return m.watchResources
 }
 
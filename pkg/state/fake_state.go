@@ -18,14 +18,21 @@ package state
 
 import (
 	"context"
-
-	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type fakeState struct {
 	name, description string
-	watchResources    map[string]client.Object
+	watchResources    map[string]WatchSource
 	syncState         SyncState
+	syncErr           error
+	dependencies      []string
+	// syncFunc is called on Sync if set
+	syncFunc func()
+	// blockSync makes Sync block until its context is done, e.g. like a hung API call
+	blockSync bool
+	// teardownDone is returned by Teardown, teardownFunc is called on Teardown if set
+	teardownDone bool
+	teardownFunc func()
 }
 
 // Name provides the State name
@@ -40,11 +47,31 @@ func (s *fakeState) Description() string {
 
 // Sync attempt to get the system to match the desired state which State represent.
 // a sync operation must be relatively short and must not block the execution thread.
-func (s *fakeState) Sync(_ context.Context, _ interface{}, _ InfoCatalog) (SyncState, error) {
-	return s.syncState, nil
+func (s *fakeState) Sync(ctx context.Context, _ interface{}, _ InfoCatalog) (SyncState, error) {
+	if s.syncFunc != nil {
+		s.syncFunc()
+	}
+	if s.blockSync {
+		<-ctx.Done()
+		return SyncStateNotReady, ctx.Err()
+	}
+	return s.syncState, s.syncErr
+}
+
+// Teardown removes the objects of the State
+func (s *fakeState) Teardown(_ context.Context) (bool, error) {
+	if s.teardownFunc != nil {
+		s.teardownFunc()
+	}
+	return s.teardownDone, nil
+}
+
+// Dependencies provides the names of the States which should be synced before this State
+func (s *fakeState) Dependencies() []string {
+	return s.dependencies
 }
 
 // Get a map of source kinds that should be watched for the state keyed by the source kind name
-func (s *fakeState) GetWatchSources() map[string]client.Object {
+func (s *fakeState) GetWatchSources() map[string]WatchSource {
 	return s.watchResources
 }
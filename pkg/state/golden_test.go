@@ -0,0 +1,80 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/config"
+	"github.com/Mellanox/network-operator/pkg/render/rendertest"
+	"github.com/Mellanox/network-operator/pkg/staticconfig"
+)
+
+// The objects rendered for the NicClusterPolicy specs are compared with the golden files in testdata/golden,
+// run `go test ./pkg/state -args -update` to regenerate them after a change of the manifests.
+var _ = Describe("Golden files of the rendered NicClusterPolicy states", func() {
+	var savedEnvConfig func() *config.OperatorConfig
+
+	BeforeEach(func() {
+		savedEnvConfig = envConfig
+		envConfig = func() *config.OperatorConfig {
+			return &config.OperatorConfig{
+				State: config.StateConfig{ManifestBaseDir: filepath.Join("..", "..", "manifests")}}
+		}
+	})
+
+	AfterEach(func() {
+		envConfig = savedEnvConfig
+	})
+
+	imageSpec := func(image string) *mellanoxv1alpha1.ImageSpec {
+		return &mellanoxv1alpha1.ImageSpec{Image: image, Repository: "nvcr.io/nvidia/mellanox", Version: "v1.0.0"}
+	}
+	cniPluginSpec := func(image string) *mellanoxv1alpha1.CNIPluginSpec {
+		return &mellanoxv1alpha1.CNIPluginSpec{ImageSpec: *imageSpec(image)}
+	}
+
+	DescribeTable("Should render the objects of the golden file",
+		func(golden string, name string, spec mellanoxv1alpha1.NicClusterPolicySpec) {
+			cr := &mellanoxv1alpha1.NicClusterPolicy{Spec: spec}
+			cr.Name = name
+			objs, err := RenderNicClusterPolicy(context.TODO(), cr, nil, staticconfig.StaticConfig{}, testLogger)
+			Expect(err).NotTo(HaveOccurred())
+			rendertest.AssertGolden(GinkgoT(), filepath.Join("testdata", "golden", golden), objs)
+		},
+		Entry("RDMA CNI", "rdma-cni.yaml", "nic-cluster-policy", mellanoxv1alpha1.NicClusterPolicySpec{
+			SecondaryNetwork: &mellanoxv1alpha1.SecondaryNetworkSpec{RdmaCni: cniPluginSpec("rdma-cni")},
+		}),
+		Entry("RDMA CNI scoped to a policy", "rdma-cni-scoped.yaml", "ib", mellanoxv1alpha1.NicClusterPolicySpec{
+			NodeSelector:     map[string]string{"network.nvidia.com/type": "ib"},
+			SecondaryNetwork: &mellanoxv1alpha1.SecondaryNetworkSpec{RdmaCni: cniPluginSpec("rdma-cni")},
+		}),
+		Entry("Multus and CNI plugins", "multus-cni-plugins.yaml", "nic-cluster-policy",
+			mellanoxv1alpha1.NicClusterPolicySpec{
+				SecondaryNetwork: &mellanoxv1alpha1.SecondaryNetworkSpec{
+					Multus: &mellanoxv1alpha1.MultusSpec{ImageSpecWithConfig: mellanoxv1alpha1.ImageSpecWithConfig{
+						ImageSpec: *imageSpec("multus-cni")}},
+					CniPlugins: cniPluginSpec("plugins"),
+				},
+			}),
+	)
+})
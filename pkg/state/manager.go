@@ -18,9 +18,14 @@ package state
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"time"
 
+	"github.com/pkg/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	"github.com/Mellanox/network-operator/pkg/consts"
 )
@@ -28,12 +33,18 @@ import (
 // Manager manages a collection of states and handles transitions from State to State.
 // A state manager invokes states in order to get the system to its desired state
 type Manager interface {
-	// GetWatchSources gets Resources that should be watched by a Controller for this state manager
-	GetWatchSources() map[string]client.Object
+	// GetWatchSources gets Resources that should be watched by a Controller for this state manager, an event passes
+	// the predicates of a source if the object is controlled by the custom resource and is relevant to one of the states
+	GetWatchSources() map[string]WatchSource
 	// SyncState reconciles the state of the system and returns a list of status of the applied states
 	// InfoCatalog is provided to optionally provide a State additional information sources required for it to perform
 	// the Sync operation.
+	// Each state is synced with a context bounded by its sync timeout, states are not synced once ctx is done.
 	SyncState(ctx context.Context, customResource interface{}, infoCatalog InfoCatalog) Results
+	// Teardown removes the objects of the states in the reverse order of their dependencies, i.e. a state is removed
+	// only once the states which depend on it are removed. Returns true once the objects of all the states are removed.
+	// Each state is removed with a context bounded by its sync timeout.
+	Teardown(ctx context.Context) (bool, error)
 }
 
 // Result is the result of a single State.Sync() invocation
@@ -42,6 +53,16 @@ type Result struct {
 	Status    SyncState
 	// if SyncStateError then ErrInfo will contain additional error information
 	ErrInfo error
+	// ErrClass is the class of ErrInfo, empty if the sync didn't fail
+	ErrClass ErrorClass
+	// DriftedObjects are the objects which drifted from their desired state and were reapplied during the sync
+	DriftedObjects []string
+	// DryRunFailedObject is the object rejected by the server-side dry-run of the sync, if any
+	DryRunFailedObject string
+	// UpdatedObjects are the changed fields of the objects updated during the sync, recorded if enabled
+	UpdatedObjects []string
+	// Readiness is the rollout progress of the workloads of the state checked during the sync
+	Readiness []ObjectReadiness
 }
 
 // Results is the result of a collection of State.Sync() invocations, Status reflects the global status of all states.
@@ -51,49 +72,75 @@ type Results struct {
 	StatesStatus []Result
 }
 
+// ErrorsByClass returns the names of the states which failed to sync grouped by the class of their error
+func (r *Results) ErrorsByClass() map[ErrorClass][]string {
+	errs := make(map[ErrorClass][]string)
+	for _, result := range r.StatesStatus {
+		if result.ErrClass != "" {
+			errs[result.ErrClass] = append(errs[result.ErrClass], result.StateName)
+		}
+	}
+	return errs
+}
+
 type stateManager struct {
-	states []State
-	client client.Client
+	// crdKind is the kind of the custom resource reconciled by the states, used as a metrics label and to filter
+	// the events of the watched objects by their controller
+	crdKind string
+	states  []State
+	client  client.Client
+	// syncWorkers is the maximal number of states synced in parallel, states are synced sequentially if <= 1
+	syncWorkers int
+	events      *syncEventEmitter
 }
 
-func (smgr *stateManager) GetWatchSources() map[string]client.Object {
-	kindMap := make(map[string]client.Object)
+func (smgr *stateManager) GetWatchSources() map[string]WatchSource {
+	stateSources := make([]map[string]WatchSource, 0, len(smgr.states))
 	for _, state := range smgr.states {
-		wr := state.GetWatchSources()
-		// append to kindMap
-		for name, kind := range wr {
-			if _, ok := kindMap[name]; !ok {
-				kindMap[name] = kind
-			}
-		}
+		stateSources = append(stateSources, state.GetWatchSources())
+	}
+	kindMap := mergeWatchSources(stateSources)
+	for name, source := range kindMap {
+		source.Predicates = append([]predicate.Predicate{controlledByPredicate(smgr.crdKind)}, source.Predicates...)
+		kindMap[name] = source
 	}
 	return kindMap
 }
 
-// SyncState attempts to reconcile the system by invoking Sync on each of the states
+// SyncState attempts to reconcile the system by invoking Sync on each of the states.
+// States are synced in groups ordered according to their dependencies, states in the same group
+// are synced in parallel by up to syncWorkers workers.
 func (smgr *stateManager) SyncState(ctx context.Context, customResource interface{}, infoCatalog InfoCatalog) Results {
 	reqLogger := log.FromContext(ctx)
 	reqLogger.V(consts.LogLevelInfo).Info("Syncing system state")
 
 	managerResult := Results{
-		Status: SyncStateNotReady,
+		Status:       SyncStateNotReady,
+		StatesStatus: make([]Result, len(smgr.states)),
 	}
-	statesReady := true
 
-	for _, state := range smgr.states {
-		reqLogger.V(consts.LogLevelInfo).Info("Sync State", "Name", state.Name(), "Description", state.Description())
-		stateCtx := log.IntoContext(ctx, reqLogger.WithName("state").WithName(state.Name()))
-		ss, err := state.Sync(stateCtx, customResource, infoCatalog)
-		result := Result{StateName: state.Name(), Status: ss, ErrInfo: err}
-		managerResult.StatesStatus = append(managerResult.StatesStatus, result)
+	groups, err := orderStates(smgr.states)
+	if err != nil {
+		reqLogger.V(consts.LogLevelError).Error(err, "Failed to order states")
+		for i, state := range smgr.states {
+			managerResult.StatesStatus[i] = Result{StateName: state.Name(), Status: SyncStateError, ErrInfo: err,
+				ErrClass: ClassifyError(err)}
+		}
+		smgr.reportResults(customResource, managerResult.StatesStatus)
+		return managerResult
+	}
+
+	for _, group := range groups {
+		smgr.syncGroup(ctx, group, customResource, infoCatalog, managerResult.StatesStatus)
+	}
+
+	smgr.reportResults(customResource, managerResult.StatesStatus)
 
+	statesReady := true
+	for _, result := range managerResult.StatesStatus {
 		if result.Status == SyncStateNotReady || result.Status == SyncStateError {
 			statesReady = false
 		}
-
-		if result.ErrInfo != nil {
-			reqLogger.V(consts.LogLevelWarning).Error(result.ErrInfo, "Error while syncing state")
-		}
 	}
 
 	if statesReady {
@@ -106,3 +153,207 @@ func (smgr *stateManager) SyncState(ctx context.Context, customResource interfac
 
 	return managerResult
 }
+
+// Teardown removes the objects of the states group by group, starting with the last group synced by SyncState.
+// The states of a group are removed only once all the states of the following groups are removed.
+func (smgr *stateManager) Teardown(ctx context.Context) (bool, error) {
+	reqLogger := log.FromContext(ctx)
+	groups, err := orderStates(smgr.states)
+	if err != nil {
+		return false, err
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		groupDone := true
+		for _, idx := range groups[i] {
+			ts, ok := smgr.states[idx].(TeardownState)
+			if !ok {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return false, err
+			}
+			reqLogger.V(consts.LogLevelInfo).Info("Teardown State", "Name", ts.Name())
+			done, err := teardownSingleState(ctx, ts)
+			if err != nil {
+				return false, fmt.Errorf("failed to teardown state %s: %v", ts.Name(), err)
+			}
+			groupDone = groupDone && done
+		}
+		if !groupDone {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// reportResults emits events and records metrics according to the results of the states sync
+func (smgr *stateManager) reportResults(customResource interface{}, results []Result) {
+	smgr.events.emit(customResource, results)
+	for _, result := range results {
+		recordSyncResult(smgr.crdKind, customResource, result)
+	}
+}
+
+// syncGroup syncs a group of independent states and stores the results in the results slice,
+// indexed by the position of the state in the states managed by the stateManager
+func (smgr *stateManager) syncGroup(ctx context.Context, group []int, customResource interface{},
+	infoCatalog InfoCatalog, results []Result) {
+	workers := smgr.syncWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	wg := sync.WaitGroup{}
+	for _, idx := range group {
+		if ctx.Err() != nil {
+			log.FromContext(ctx).V(consts.LogLevelInfo).Info("Skip State sync, the sync was cancelled",
+				"Name", smgr.states[idx].Name())
+			results[idx] = Result{StateName: smgr.states[idx].Name(), Status: SyncStateNotReady}
+			continue
+		}
+		if notReady := smgr.notReadyDependencies(idx, results); len(notReady) > 0 {
+			log.FromContext(ctx).V(consts.LogLevelInfo).Info("Skip State sync, dependencies are not ready",
+				"Name", smgr.states[idx].Name(), "Dependencies", notReady)
+			results[idx] = Result{StateName: smgr.states[idx].Name(), Status: SyncStateNotReady}
+			continue
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(idx int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			start := time.Now()
+			results[idx] = syncSingleState(ctx, smgr.states[idx], customResource, infoCatalog)
+			observeSyncDuration(smgr.crdKind, customResource, smgr.states[idx].Name(), time.Since(start))
+		}(idx)
+	}
+	wg.Wait()
+}
+
+// notReadyDependencies returns the names of the dependencies of the state with the given index which are
+// not ready, i.e. in SyncStateNotReady or SyncStateError. Dependencies are expected to be already synced.
+func (smgr *stateManager) notReadyDependencies(idx int, results []Result) []string {
+	ds, ok := smgr.states[idx].(DependentState)
+	if !ok {
+		return nil
+	}
+	notReady := make([]string, 0)
+	for _, dep := range ds.Dependencies() {
+		for _, result := range results {
+			if result.StateName == dep && (result.Status == SyncStateNotReady || result.Status == SyncStateError) {
+				notReady = append(notReady, dep)
+			}
+		}
+	}
+	return notReady
+}
+
+// syncSingleState invokes Sync of the given state and returns its Result.
+// The state fails to sync with a TimeoutError if it doesn't complete within its sync timeout.
+func syncSingleState(ctx context.Context, state State, customResource interface{}, infoCatalog InfoCatalog) Result {
+	reqLogger := log.FromContext(ctx)
+	reqLogger.V(consts.LogLevelInfo).Info("Sync State", "Name", state.Name(), "Description", state.Description())
+	stateCtx := log.IntoContext(ctx, reqLogger.WithName("state").WithName(state.Name()))
+	stateCtx, drift := withDriftRecorder(stateCtx)
+	stateCtx, readiness := withReadinessRecorder(stateCtx)
+	stateCtx, cancel, timeout := withSyncTimeout(stateCtx, state.Name())
+	defer cancel()
+	ss, err := state.Sync(stateCtx, customResource, infoCatalog)
+	err = checkSyncTimeout(ctx, stateCtx, timeout, err)
+	if _, timedOut := err.(*TimeoutError); timedOut {
+		ss = SyncStateError
+	}
+	if err != nil {
+		reqLogger.V(consts.LogLevelWarning).Error(err, "Error while syncing state", "Name", state.Name())
+	}
+	result := Result{StateName: state.Name(), Status: ss, ErrInfo: err, ErrClass: ClassifyError(err),
+		DriftedObjects: drift.drifted(), UpdatedObjects: drift.updated(), Readiness: readiness.recorded()}
+	var dryRunErr *DryRunError
+	if errors.As(err, &dryRunErr) {
+		result.DryRunFailedObject = dryRunErr.Object
+	}
+	return result
+}
+
+// orderStates partitions the states into groups which should be synced one after the other.
+// Each group contains indexes of states whose dependencies are all part of previous groups,
+// states keep their relative order within a group.
+// Returns an error if a dependency is unknown or in case of a dependency cycle.
+func orderStates(states []State) ([][]int, error) {
+	stateIdx := make(map[string]int, len(states))
+	for i, state := range states {
+		stateIdx[state.Name()] = i
+	}
+	// number of unsatisfied dependencies per state
+	pending := make([]int, len(states))
+	dependents := make([][]int, len(states))
+	for i, state := range states {
+		ds, ok := state.(DependentState)
+		if !ok {
+			continue
+		}
+		for _, dep := range ds.Dependencies() {
+			depIdx, ok := stateIdx[dep]
+			if !ok {
+				return nil, fmt.Errorf("state %s depends on unknown state %s", state.Name(), dep)
+			}
+			pending[i]++
+			dependents[depIdx] = append(dependents[depIdx], i)
+		}
+	}
+
+	groups := make([][]int, 0)
+	ordered := 0
+	done := make([]bool, len(states))
+	for ordered < len(states) {
+		group := make([]int, 0)
+		for i := range states {
+			if !done[i] && pending[i] == 0 {
+				group = append(group, i)
+			}
+		}
+		if len(group) == 0 {
+			return nil, fmt.Errorf("dependency cycle detected between states")
+		}
+		for _, i := range group {
+			done[i] = true
+			for _, dependent := range dependents[i] {
+				pending[dependent]--
+			}
+		}
+		ordered += len(group)
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// teardownSingleState invokes Teardown of the given state, the teardown fails with a TimeoutError if it doesn't
+// complete within the sync timeout of the state
+func teardownSingleState(ctx context.Context, state TeardownState) (bool, error) {
+	stateCtx, cancel, timeout := withSyncTimeout(ctx, state.Name())
+	defer cancel()
+	done, err := state.Teardown(stateCtx)
+	return done, checkSyncTimeout(ctx, stateCtx, timeout, err)
+}
+
+// withSyncTimeout returns a context which is cancelled once the sync timeout of the state expires, and the timeout.
+// The returned context is ctx if the state has no timeout.
+func withSyncTimeout(ctx context.Context, stateName string) (context.Context, context.CancelFunc, time.Duration) {
+	timeout := envConfig().State.SyncTimeoutOf(stateName)
+	if timeout <= 0 {
+		return ctx, func() {}, 0
+	}
+	stateCtx, cancel := context.WithTimeout(ctx, timeout)
+	return stateCtx, cancel, timeout
+}
+
+// checkSyncTimeout wraps the error returned by a state in a TimeoutError if the state failed because its sync
+// timeout expired, the error is returned as is if ctx, the context of the whole sync, is done
+func checkSyncTimeout(ctx, stateCtx context.Context, timeout time.Duration, err error) error {
+	if err == nil || ctx.Err() != nil || !errors.Is(stateCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return &TimeoutError{Timeout: timeout, Err: err}
+}
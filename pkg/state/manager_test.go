@@ -18,10 +18,14 @@ package state
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 
+	"github.com/Mellanox/network-operator/pkg/config"
 	"github.com/Mellanox/network-operator/pkg/testing/mocks"
 )
 
@@ -67,5 +71,223 @@ var _ = Describe("Manager tests", func() {
 			Expect(results.StatesStatus[1].StateName).To(Equal("test ready"))
 			Expect(results.StatesStatus[1].Status).To(Equal(SyncState(SyncStateReady)))
 		})
+		It("Should report the object which failed the dry-run", func() {
+			testState := &fakeState{
+				name:        "test",
+				description: "test description",
+				syncState:   SyncStateError,
+				syncErr: errors.Wrap(&DryRunError{Object: "DaemonSet test/ds", Err: errors.New("invalid")},
+					"failed to create k8s objects"),
+			}
+			client := mocks.ControllerRuntimeClient{}
+			manager := &stateManager{
+				states: []State{testState},
+				client: &client,
+			}
+			results := manager.SyncState(context.TODO(), nil, nil)
+			Expect(results.StatesStatus[0].Status).To(Equal(SyncState(SyncStateError)))
+			Expect(results.StatesStatus[0].DryRunFailedObject).To(Equal("DaemonSet test/ds"))
+		})
+	})
+
+	Context("Sync states with timeouts", func() {
+		var origConfig func() *config.OperatorConfig
+
+		BeforeEach(func() {
+			origConfig = envConfig
+			envConfig = func() *config.OperatorConfig {
+				return &config.OperatorConfig{State: config.StateConfig{
+					SyncTimeout: 50 * time.Millisecond, SyncTimeouts: "unbounded=0"}}
+			}
+		})
+		AfterEach(func() {
+			envConfig = origConfig
+		})
+
+		It("Should fail a state which exceeds its sync timeout", func() {
+			manager := &stateManager{
+				states: []State{
+					&fakeState{name: "hung", blockSync: true},
+					&fakeState{name: "ready", syncState: SyncStateReady}},
+				client: &mocks.ControllerRuntimeClient{},
+			}
+			results := manager.SyncState(context.TODO(), nil, nil)
+			Expect(results.Status).To(Equal(SyncState(SyncStateNotReady)))
+			Expect(results.StatesStatus[0].Status).To(Equal(SyncState(SyncStateError)))
+			Expect(results.StatesStatus[0].ErrClass).To(Equal(ErrorClassTimeout))
+			var timeoutErr *TimeoutError
+			Expect(errors.As(results.StatesStatus[0].ErrInfo, &timeoutErr)).To(BeTrue())
+			Expect(timeoutErr.Timeout).To(Equal(50 * time.Millisecond))
+			Expect(results.StatesStatus[1].Status).To(Equal(SyncState(SyncStateReady)))
+		})
+
+		It("Should not bound the sync of a state whose timeout is disabled", func() {
+			ctx, cancel := context.WithTimeout(context.TODO(), 200*time.Millisecond)
+			defer cancel()
+			manager := &stateManager{
+				states: []State{&fakeState{name: "unbounded", blockSync: true}},
+				client: &mocks.ControllerRuntimeClient{},
+			}
+			results := manager.SyncState(ctx, nil, nil)
+			// the state returns once the context of the whole sync is done, which is not a timeout of the state
+			Expect(results.StatesStatus[0].Status).To(Equal(SyncState(SyncStateNotReady)))
+			Expect(results.StatesStatus[0].ErrClass).To(Equal(ErrorClassUnknown))
+		})
+
+		It("Should not sync the states once the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.TODO())
+			cancel()
+			synced := false
+			manager := &stateManager{
+				states: []State{&fakeState{name: "test", syncState: SyncStateReady,
+					syncFunc: func() { synced = true }}},
+				client: &mocks.ControllerRuntimeClient{},
+			}
+			results := manager.SyncState(ctx, nil, nil)
+			Expect(synced).To(BeFalse())
+			Expect(results.Status).To(Equal(SyncState(SyncStateNotReady)))
+			Expect(results.StatesStatus[0].Status).To(Equal(SyncState(SyncStateNotReady)))
+		})
+	})
+
+	Context("Sync states with dependencies", func() {
+		var (
+			mu    sync.Mutex
+			order []string
+		)
+		record := func(name string) func() {
+			return func() {
+				time.Sleep(10 * time.Millisecond)
+				mu.Lock()
+				defer mu.Unlock()
+				order = append(order, name)
+			}
+		}
+		BeforeEach(func() {
+			order = nil
+		})
+
+		It("Should sync dependencies first and keep results order", func() {
+			first := &fakeState{name: "first", syncState: SyncStateReady, dependencies: []string{"second"},
+				syncFunc: record("first")}
+			second := &fakeState{name: "second", syncState: SyncStateReady, syncFunc: record("second")}
+			third := &fakeState{name: "third", syncState: SyncStateReady, dependencies: []string{"first"},
+				syncFunc: record("third")}
+			manager := &stateManager{
+				states:      []State{first, second, third},
+				client:      &mocks.ControllerRuntimeClient{},
+				syncWorkers: 3,
+			}
+			results := manager.SyncState(context.TODO(), nil, nil)
+			Expect(results.Status).To(Equal(SyncState(SyncStateReady)))
+			Expect(order).To(Equal([]string{"second", "first", "third"}))
+			Expect(results.StatesStatus[0].StateName).To(Equal("first"))
+			Expect(results.StatesStatus[1].StateName).To(Equal("second"))
+			Expect(results.StatesStatus[2].StateName).To(Equal("third"))
+		})
+
+		It("Should sync independent states in parallel", func() {
+			wg := sync.WaitGroup{}
+			wg.Add(2)
+			// each state waits for the other one to start syncing, would block if synced sequentially
+			waitForOther := func() {
+				wg.Done()
+				wg.Wait()
+			}
+			manager := &stateManager{
+				states: []State{
+					&fakeState{name: "a", syncState: SyncStateReady, syncFunc: waitForOther},
+					&fakeState{name: "b", syncState: SyncStateNotReady, syncFunc: waitForOther}},
+				client:      &mocks.ControllerRuntimeClient{},
+				syncWorkers: 2,
+			}
+			results := manager.SyncState(context.TODO(), nil, nil)
+			Expect(results.Status).To(Equal(SyncState(SyncStateNotReady)))
+			Expect(results.StatesStatus).To(HaveLen(2))
+		})
+
+		It("Should not sync state with not ready dependencies", func() {
+			synced := false
+			manager := &stateManager{
+				states: []State{
+					&fakeState{name: "driver", syncState: SyncStateNotReady},
+					&fakeState{name: "plugin", syncState: SyncStateReady, dependencies: []string{"driver"},
+						syncFunc: func() { synced = true }}},
+				client: &mocks.ControllerRuntimeClient{},
+			}
+			results := manager.SyncState(context.TODO(), nil, nil)
+			Expect(synced).To(BeFalse())
+			Expect(results.Status).To(Equal(SyncState(SyncStateNotReady)))
+			Expect(results.StatesStatus[1].StateName).To(Equal("plugin"))
+			Expect(results.StatesStatus[1].Status).To(Equal(SyncState(SyncStateNotReady)))
+		})
+
+		It("Should sync state with ignored dependencies", func() {
+			manager := &stateManager{
+				states: []State{
+					&fakeState{name: "driver", syncState: SyncStateIgnore},
+					&fakeState{name: "plugin", syncState: SyncStateReady, dependencies: []string{"driver"}}},
+				client: &mocks.ControllerRuntimeClient{},
+			}
+			results := manager.SyncState(context.TODO(), nil, nil)
+			Expect(results.Status).To(Equal(SyncState(SyncStateReady)))
+			Expect(results.StatesStatus[1].Status).To(Equal(SyncState(SyncStateReady)))
+		})
+
+		It("Should fail on dependency cycle", func() {
+			manager := &stateManager{
+				states: []State{
+					&fakeState{name: "a", syncState: SyncStateReady, dependencies: []string{"b"}},
+					&fakeState{name: "b", syncState: SyncStateReady, dependencies: []string{"a"}}},
+				client: &mocks.ControllerRuntimeClient{},
+			}
+			results := manager.SyncState(context.TODO(), nil, nil)
+			Expect(results.Status).To(Equal(SyncState(SyncStateNotReady)))
+			Expect(results.StatesStatus[0].Status).To(Equal(SyncState(SyncStateError)))
+			Expect(results.StatesStatus[1].Status).To(Equal(SyncState(SyncStateError)))
+		})
+
+		It("Should fail on unknown dependency", func() {
+			_, err := orderStates([]State{
+				&fakeState{name: "a", syncState: SyncStateReady, dependencies: []string{"unknown"}}})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Teardown", func() {
+		It("Should teardown states in reverse order of their dependencies", func() {
+			var order []string
+			newState := func(name string, done bool, dependencies ...string) *fakeState {
+				return &fakeState{name: name, dependencies: dependencies, teardownDone: done,
+					teardownFunc: func() { order = append(order, name) }}
+			}
+			manager := &stateManager{
+				states: []State{
+					newState("plugin", true, "driver"),
+					newState("driver", true),
+					newState("network", true, "plugin")},
+				client: &mocks.ControllerRuntimeClient{},
+			}
+			done, err := manager.Teardown(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(done).To(BeTrue())
+			Expect(order).To(Equal([]string{"network", "plugin", "driver"}))
+		})
+
+		It("Should not teardown states until their dependents are removed", func() {
+			var order []string
+			manager := &stateManager{
+				states: []State{
+					&fakeState{name: "driver", teardownDone: true,
+						teardownFunc: func() { order = append(order, "driver") }},
+					&fakeState{name: "plugin", dependencies: []string{"driver"},
+						teardownFunc: func() { order = append(order, "plugin") }}},
+				client: &mocks.ControllerRuntimeClient{},
+			}
+			done, err := manager.Teardown(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(done).To(BeFalse())
+			Expect(order).To(Equal([]string{"plugin"}))
+		})
 	})
 })
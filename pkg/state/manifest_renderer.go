@@ -35,10 +35,9 @@ type ManifestRenderer interface {
 }
 
 // ParseContainerNames renders the manifests using ManifestRenderer and extracts container names out of them
-func ParseContainerNames(
+func ParseContainerNames(ctx context.Context,
 	renderer ManifestRenderer, cr *mellanoxv1alpha1.NicClusterPolicy, reqLogger logr.Logger) ([]string, error) {
 	catalog := getDummyCatalog()
-	ctx := context.TODO()
 
 	manifests, err := renderer.GetManifestObjects(ctx, cr, catalog, reqLogger)
 	if err != nil {
@@ -0,0 +1,87 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/Mellanox/network-operator/pkg/consts"
+	"github.com/Mellanox/network-operator/pkg/render"
+	"github.com/Mellanox/network-operator/pkg/utils"
+)
+
+// newManifestRenderer creates a renderer for the manifests in manifestDir.
+// If manifest overlay is enabled, the manifests are overlaid by the manifests of the ConfigMaps
+// in the operator namespace labeled with consts.ManifestOverlayLabel=<manifestDir base name>.
+// If manifestDir contains a Helm chart, the chart is rendered as a release named after the manifestDir base name
+// in the operator namespace, the render data of the state are then the values of the chart.
+func newManifestRenderer(k8sAPIClient client.Client, manifestDir string) (render.Renderer, error) {
+	if render.IsHelmChart(manifestDir) {
+		return render.NewHelmChartRenderer(manifestDir, filepath.Base(manifestDir),
+			envConfig().State.NetworkOperatorResourceNamespace), nil
+	}
+	files, err := utils.GetFilesWithSuffix(manifestDir, render.ManifestFileSuffix...)
+	if err != nil {
+		return nil, err
+	}
+	source := render.NewFilesSource(files)
+	if envConfig().State.ManifestOverlay {
+		source = render.NewLayeredSource(source, &configMapManifestSource{
+			client:    k8sAPIClient,
+			namespace: envConfig().State.NetworkOperatorResourceNamespace,
+			name:      filepath.Base(manifestDir),
+		})
+	}
+	return render.NewSourceRenderer(source), nil
+}
+
+// configMapManifestSource provides the manifests of the ConfigMaps labeled with consts.ManifestOverlayLabel=name,
+// each key of a ConfigMap is a manifest. ConfigMaps are layered ordered by name.
+type configMapManifestSource struct {
+	client    client.Reader
+	namespace string
+	name      string
+}
+
+// Manifests returns the manifests of the overlay ConfigMaps
+func (s *configMapManifestSource) Manifests(ctx context.Context) ([]render.Manifest, error) {
+	cmList := &v1.ConfigMapList{}
+	if err := s.client.List(ctx, cmList, client.InNamespace(s.namespace),
+		client.MatchingLabels{consts.ManifestOverlayLabel: s.name}); err != nil {
+		return nil, errors.Wrapf(err, "failed to list manifest overlay ConfigMaps for %s", s.name)
+	}
+	sort.Slice(cmList.Items, func(i, j int) bool { return cmList.Items[i].Name < cmList.Items[j].Name })
+	manifests := make([]render.Manifest, 0)
+	for i := range cmList.Items {
+		cm := &cmList.Items[i]
+		keys := make([]string, 0, len(cm.Data))
+		for key := range cm.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			manifests = append(manifests, render.Manifest{Name: key, Content: cm.Data[key]})
+		}
+	}
+	return manifests, nil
+}
@@ -0,0 +1,148 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/Mellanox/network-operator/pkg/config"
+	"github.com/Mellanox/network-operator/pkg/consts"
+	"github.com/Mellanox/network-operator/pkg/render"
+)
+
+const overlayConfigMap = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: overlay-config
+  namespace: {{ .RuntimeSpec.Namespace }}
+`
+
+var _ = Describe("Manifest overlay", func() {
+	var origConfig func() *config.OperatorConfig
+	var cfg *config.OperatorConfig
+
+	BeforeEach(func() {
+		origConfig = envConfig
+		cfg = &config.OperatorConfig{State: config.StateConfig{
+			ManifestOverlay:                  true,
+			NetworkOperatorResourceNamespace: "nvidia-network-operator",
+		}}
+		envConfig = func() *config.OperatorConfig { return cfg }
+	})
+
+	AfterEach(func() {
+		envConfig = origConfig
+	})
+
+	overlayCM := func(name, namespace, dir string, data map[string]string) *v1.ConfigMap {
+		return &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{consts.ManifestOverlayLabel: dir},
+			},
+			Data: data,
+		}
+	}
+
+	getManifests := func(objs ...*v1.ConfigMap) map[string]string {
+		builder := fake.NewClientBuilder()
+		for _, obj := range objs {
+			builder = builder.WithObjects(obj)
+		}
+		source := &configMapManifestSource{
+			client:    builder.Build(),
+			namespace: cfg.State.NetworkOperatorResourceNamespace,
+			name:      "state-nv-ipam-cni",
+		}
+		manifests, err := render.NewLayeredSource(render.NewFilesSource(nil), source).Manifests(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		byName := map[string]string{}
+		for _, m := range manifests {
+			byName[m.Name] = m.Content
+		}
+		return byName
+	}
+
+	It("Should return manifests of the labeled ConfigMaps in the operator namespace", func() {
+		manifests := getManifests(
+			overlayCM("a", "nvidia-network-operator", "state-nv-ipam-cni",
+				map[string]string{"0100_overlay.yaml": overlayConfigMap}),
+			overlayCM("b", "other", "state-nv-ipam-cni", map[string]string{"0200_other.yaml": "other"}),
+			overlayCM("c", "nvidia-network-operator", "state-ofed", map[string]string{"0300_ofed.yaml": "ofed"}))
+		Expect(manifests).To(Equal(map[string]string{"0100_overlay.yaml": overlayConfigMap}))
+	})
+
+	It("Should layer ConfigMaps ordered by name", func() {
+		manifests := getManifests(
+			overlayCM("b", "nvidia-network-operator", "state-nv-ipam-cni", map[string]string{"0100_overlay.yaml": "b"}),
+			overlayCM("a", "nvidia-network-operator", "state-nv-ipam-cni", map[string]string{"0100_overlay.yaml": "a"}))
+		Expect(manifests).To(Equal(map[string]string{"0100_overlay.yaml": "b"}))
+	})
+
+	renderManifestDir := func() []string {
+		manifestDir := filepath.Join(GinkgoT().TempDir(), "state-test")
+		Expect(os.Mkdir(manifestDir, 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(manifestDir, "0010_config.yaml"),
+			[]byte(strings.Replace(overlayConfigMap, "overlay-config", "config", 1)), 0o600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(manifestDir, "0020_disabled.yaml"),
+			[]byte(strings.Replace(overlayConfigMap, "overlay-config", "disabled", 1)), 0o600)).To(Succeed())
+		c := fake.NewClientBuilder().WithObjects(overlayCM("a", "nvidia-network-operator", "state-test",
+			map[string]string{"0020_disabled.yaml": "", "0030_overlay.yaml": overlayConfigMap})).Build()
+
+		renderer, err := newManifestRenderer(c, manifestDir)
+		Expect(err).NotTo(HaveOccurred())
+		objs, err := renderer.RenderObjects(context.Background(), &render.TemplatingData{Data: map[string]interface{}{
+			"RuntimeSpec": map[string]interface{}{"Namespace": "nvidia-network-operator"}}})
+		Expect(err).NotTo(HaveOccurred())
+		names := make([]string, 0, len(objs))
+		for _, obj := range objs {
+			names = append(names, obj.GetName())
+		}
+		return names
+	}
+
+	It("Should overlay the manifests of the manifest dir", func() {
+		Expect(renderManifestDir()).To(Equal([]string{"config", "overlay-config"}))
+	})
+
+	It("Should not overlay the manifests if disabled", func() {
+		cfg.State.ManifestOverlay = false
+		Expect(renderManifestDir()).To(Equal([]string{"config", "disabled"}))
+	})
+
+	It("Should render the Helm chart of the manifest dir", func() {
+		renderer, err := newManifestRenderer(fake.NewClientBuilder().Build(),
+			filepath.Join("..", "render", "testdata", "helmChart"))
+		Expect(err).NotTo(HaveOccurred())
+		objs, err := renderer.RenderObjects(context.Background(), &render.TemplatingData{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(1))
+		Expect(objs[0].GetName()).To(Equal("helmChart-test-chart"))
+		Expect(objs[0].GetNamespace()).To(Equal("nvidia-network-operator"))
+	})
+})
@@ -0,0 +1,104 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "network_operator"
+
+var (
+	stateSyncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "state_sync_duration_seconds",
+		Help:      "Duration of the sync of a state in seconds",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{"kind", "name", "state"})
+	stateSyncStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "state_sync_status",
+		Help:      "Result of the last sync of a state, 1 for the current status of the state and 0 otherwise",
+	}, []string{"kind", "name", "state", "status"})
+	stateSyncConsecutiveFailures = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "state_sync_consecutive_failures",
+		Help:      "Number of consecutive syncs of a state which ended with an error",
+	}, []string{"kind", "name", "state"})
+	stateLastReadyTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "state_last_ready_timestamp_seconds",
+		Help:      "Unix timestamp of the last sync in which a state was ready or ignored",
+	}, []string{"kind", "name", "state"})
+	stateSyncErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "state_sync_errors_total",
+		Help:      "Number of syncs of a state which failed, by class of the error",
+	}, []string{"kind", "name", "state", "class"})
+	stateObjectsApplied = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "state_objects_applied_total",
+		Help:      "Number of objects created or updated by a state",
+	}, []string{"state"})
+)
+
+var syncStatuses = []SyncState{SyncStateReady, SyncStateNotReady, SyncStateIgnore, SyncStateReset, SyncStateError}
+
+func init() {
+	metrics.Registry.MustRegister(stateSyncDuration, stateSyncStatus, stateSyncConsecutiveFailures,
+		stateLastReadyTimestamp, stateSyncErrors, stateObjectsApplied)
+}
+
+// observeSyncDuration records the duration of the state sync of the custom resource
+func observeSyncDuration(kind string, customResource interface{}, stateName string, duration time.Duration) {
+	stateSyncDuration.WithLabelValues(kind, customResourceName(customResource), stateName).Observe(duration.Seconds())
+}
+
+// recordSyncResult records the metrics of the result of the state sync of the custom resource
+func recordSyncResult(kind string, customResource interface{}, result Result) {
+	name := customResourceName(customResource)
+	for _, status := range syncStatuses {
+		value := 0.0
+		if status == result.Status {
+			value = 1
+		}
+		stateSyncStatus.WithLabelValues(kind, name, result.StateName, string(status)).Set(value)
+	}
+	failures := stateSyncConsecutiveFailures.WithLabelValues(kind, name, result.StateName)
+	if result.Status == SyncStateError {
+		failures.Inc()
+	} else {
+		failures.Set(0)
+	}
+	if result.ErrClass != "" {
+		stateSyncErrors.WithLabelValues(kind, name, result.StateName, string(result.ErrClass)).Inc()
+	}
+	if result.Status == SyncStateReady || result.Status == SyncStateIgnore {
+		stateLastReadyTimestamp.WithLabelValues(kind, name, result.StateName).SetToCurrentTime()
+	}
+}
+
+func customResourceName(customResource interface{}) string {
+	if obj, ok := customResource.(client.Object); ok {
+		return obj.GetName()
+	}
+	return ""
+}
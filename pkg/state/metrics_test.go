@@ -0,0 +1,115 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/testing/mocks"
+)
+
+var _ = Describe("Metrics tests", func() {
+	const kind = "MetricsTest"
+
+	BeforeEach(func() {
+		stateSyncDuration.Reset()
+		stateSyncStatus.Reset()
+		stateSyncConsecutiveFailures.Reset()
+		stateLastReadyTimestamp.Reset()
+		stateSyncErrors.Reset()
+	})
+
+	It("Should record state sync metrics", func() {
+		readyState := &fakeState{name: "ready", syncState: SyncStateReady}
+		errorState := &fakeState{name: "error", syncState: SyncStateError, syncErr: errors.New("error")}
+		client := mocks.ControllerRuntimeClient{}
+		manager := &stateManager{
+			crdKind: kind,
+			states:  []State{readyState, errorState},
+			client:  &client,
+		}
+		cr := &mellanoxv1alpha1.NicClusterPolicy{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+
+		manager.SyncState(context.TODO(), cr, nil)
+		manager.SyncState(context.TODO(), cr, nil)
+
+		Expect(testutil.ToFloat64(stateSyncStatus.WithLabelValues(kind, "test", "ready", SyncStateReady))).
+			To(Equal(1.0))
+		Expect(testutil.ToFloat64(stateSyncStatus.WithLabelValues(kind, "test", "ready", SyncStateError))).
+			To(Equal(0.0))
+		Expect(testutil.ToFloat64(stateSyncStatus.WithLabelValues(kind, "test", "error", SyncStateError))).
+			To(Equal(1.0))
+		Expect(testutil.ToFloat64(stateSyncConsecutiveFailures.WithLabelValues(kind, "test", "ready"))).
+			To(Equal(0.0))
+		Expect(testutil.ToFloat64(stateSyncConsecutiveFailures.WithLabelValues(kind, "test", "error"))).
+			To(Equal(2.0))
+		Expect(testutil.ToFloat64(stateLastReadyTimestamp.WithLabelValues(kind, "test", "ready"))).
+			To(BeNumerically(">", 0))
+		Expect(testutil.CollectAndCount(stateLastReadyTimestamp)).To(Equal(1))
+		Expect(testutil.CollectAndCount(stateSyncDuration)).To(Equal(2))
+	})
+
+	It("Should reset consecutive failures once the state recovers", func() {
+		testState := &fakeState{name: "test", syncState: SyncStateError, syncErr: errors.New("error")}
+		client := mocks.ControllerRuntimeClient{}
+		manager := &stateManager{
+			crdKind: kind,
+			states:  []State{testState},
+			client:  &client,
+		}
+		cr := &mellanoxv1alpha1.NicClusterPolicy{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+
+		manager.SyncState(context.TODO(), cr, nil)
+		Expect(testutil.ToFloat64(stateSyncConsecutiveFailures.WithLabelValues(kind, "test", "test"))).
+			To(Equal(1.0))
+		testState.syncState = SyncStateNotReady
+		testState.syncErr = nil
+		manager.SyncState(context.TODO(), cr, nil)
+		Expect(testutil.ToFloat64(stateSyncConsecutiveFailures.WithLabelValues(kind, "test", "test"))).
+			To(Equal(0.0))
+		Expect(testutil.ToFloat64(stateSyncStatus.WithLabelValues(kind, "test", "test", SyncStateNotReady))).
+			To(Equal(1.0))
+	})
+	It("Should count state sync errors by class", func() {
+		renderState := &fakeState{name: "render", syncState: SyncStateNotReady,
+			syncErr: &RenderError{Err: errors.New("error")}}
+		errorState := &fakeState{name: "error", syncState: SyncStateError, syncErr: errors.New("error")}
+		client := mocks.ControllerRuntimeClient{}
+		manager := &stateManager{
+			crdKind: kind,
+			states:  []State{renderState, errorState},
+			client:  &client,
+		}
+		cr := &mellanoxv1alpha1.NicClusterPolicy{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+
+		manager.SyncState(context.TODO(), cr, nil)
+		manager.SyncState(context.TODO(), cr, nil)
+
+		Expect(testutil.ToFloat64(stateSyncErrors.WithLabelValues(kind, "test", "render",
+			string(ErrorClassRender)))).To(Equal(2.0))
+		Expect(testutil.ToFloat64(stateSyncErrors.WithLabelValues(kind, "test", "error",
+			string(ErrorClassUnknown)))).To(Equal(2.0))
+		Expect(testutil.CollectAndCount(stateSyncErrors)).To(Equal(2))
+	})
+})
@@ -0,0 +1,78 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+type whereaboutsIPRange struct {
+	Range      string `json:"range"`
+	RangeStart string `json:"range_start,omitempty"`
+	RangeEnd   string `json:"range_end,omitempty"`
+}
+
+type whereaboutsIPAMConfig struct {
+	Type     string                       `json:"type"`
+	IPRanges []whereaboutsIPRange         `json:"ipRanges"`
+	Gateway  string                       `json:"gateway,omitempty"`
+	Routes   []mellanoxv1alpha1.RouteSpec `json:"routes,omitempty"`
+}
+
+// getNetworkIPAMConfig returns the "ipam" entry of the CNI config of a network, rendered from the static IPAM
+// if set, otherwise from the IPAM config with its whitespaces removed
+func getNetworkIPAMConfig(ipam string, staticIPAM *mellanoxv1alpha1.StaticIPAMSpec) (string, error) {
+	if staticIPAM == nil {
+		if ipam == "" {
+			return "\"ipam\":{}", nil
+		}
+		return "\"ipam\":" + strings.Join(strings.Fields(ipam), ""), nil
+	}
+	config := whereaboutsIPAMConfig{
+		Type:     "whereabouts",
+		IPRanges: make([]whereaboutsIPRange, 0, len(staticIPAM.Ranges)),
+		Gateway:  staticIPAM.Gateway,
+		Routes:   staticIPAM.Routes,
+	}
+	for _, r := range staticIPAM.Ranges {
+		config.IPRanges = append(config.IPRanges,
+			whereaboutsIPRange{Range: r.Subnet, RangeStart: r.RangeStart, RangeEnd: r.RangeEnd})
+	}
+	rendered, err := json.Marshal(config)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to render static IPAM config")
+	}
+	return "\"ipam\":" + string(rendered), nil
+}
+
+// getNetworkDNSConfig returns the "dns" entry of the CNI config of a network followed by a comma,
+// or an empty string if the network has no DNS config
+func getNetworkDNSConfig(dns *mellanoxv1alpha1.DNSSpec) (string, error) {
+	if dns == nil {
+		return "", nil
+	}
+	rendered, err := json.Marshal(dns)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to render DNS config")
+	}
+	return "\"dns\":" + string(rendered) + ",", nil
+}
@@ -0,0 +1,38 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// getNodeAffinity returns the node affinity of the component if set, otherwise the node affinity of the policy
+func getNodeAffinity(policyAffinity, componentAffinity *v1.NodeAffinity) *v1.NodeAffinity {
+	if componentAffinity != nil {
+		return componentAffinity
+	}
+	return policyAffinity
+}
+
+// getTolerations returns the tolerations of the policy followed by the tolerations of the component
+func getTolerations(policyTolerations, componentTolerations []v1.Toleration) []v1.Toleration {
+	if len(componentTolerations) == 0 {
+		return policyTolerations
+	}
+	tolerations := make([]v1.Toleration, 0, len(policyTolerations)+len(componentTolerations))
+	return append(append(tolerations, policyTolerations...), componentTolerations...)
+}
@@ -0,0 +1,156 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/nodeinfo"
+	"github.com/Mellanox/network-operator/pkg/staticconfig"
+)
+
+// offlineState describes a NicClusterPolicy state rendered by RenderNicClusterPolicy
+type offlineState struct {
+	manifestDir string
+	newState    func(k8sAPIClient client.Client, manifestDir string) (State, ManifestRenderer, error)
+	enabled     func(spec *mellanoxv1alpha1.NicClusterPolicySpec) bool
+}
+
+// offlineStates lists the NicClusterPolicy states in the order they are created by newNicClusterPolicyStates
+var offlineStates = []offlineState{
+	{"state-openshift", NewStateOpenshift, func(_ *mellanoxv1alpha1.NicClusterPolicySpec) bool {
+		return true
+	}},
+	{"state-multus-cni", NewStateMultusCNI, func(spec *mellanoxv1alpha1.NicClusterPolicySpec) bool {
+		return spec.SecondaryNetwork != nil && spec.SecondaryNetwork.Multus != nil
+	}},
+	{"state-container-networking-plugins", NewStateCNIPlugins, func(spec *mellanoxv1alpha1.NicClusterPolicySpec) bool {
+		return spec.SecondaryNetwork != nil && spec.SecondaryNetwork.CniPlugins != nil
+	}},
+	{"state-ipoib-cni", NewStateIPoIBCNI, func(spec *mellanoxv1alpha1.NicClusterPolicySpec) bool {
+		return spec.SecondaryNetwork != nil && spec.SecondaryNetwork.IPoIB != nil
+	}},
+	{"state-ovs-cni", NewStateOVSCNI, func(spec *mellanoxv1alpha1.NicClusterPolicySpec) bool {
+		return spec.SecondaryNetwork != nil && spec.SecondaryNetwork.OVSCni != nil
+	}},
+	{"state-rdma-cni", NewStateRDMACNI, func(spec *mellanoxv1alpha1.NicClusterPolicySpec) bool {
+		return spec.SecondaryNetwork != nil && spec.SecondaryNetwork.RdmaCni != nil
+	}},
+	{"state-whereabouts-cni", NewStateWhereaboutsCNI, func(spec *mellanoxv1alpha1.NicClusterPolicySpec) bool {
+		return spec.SecondaryNetwork != nil && spec.SecondaryNetwork.IpamPlugin != nil
+	}},
+	{"state-ofed-driver", NewStateOFED, func(spec *mellanoxv1alpha1.NicClusterPolicySpec) bool {
+		return spec.OFEDDriver != nil
+	}},
+	{"state-sriov-device-plugin", NewStateSriovDp, func(spec *mellanoxv1alpha1.NicClusterPolicySpec) bool {
+		return spec.SriovDevicePlugin != nil
+	}},
+	{"state-rdma-device-plugin", NewStateSharedDp, func(spec *mellanoxv1alpha1.NicClusterPolicySpec) bool {
+		return spec.RdmaSharedDevicePlugin != nil
+	}},
+	{"state-ib-kubernetes", NewStateIBKubernetes, func(spec *mellanoxv1alpha1.NicClusterPolicySpec) bool {
+		return spec.IBKubernetes != nil
+	}},
+	{"state-nv-ipam-cni", NewStateNVIPAMCNI, func(spec *mellanoxv1alpha1.NicClusterPolicySpec) bool {
+		return spec.NvIpam != nil
+	}},
+	{"state-nic-feature-discovery", NewStateNICFeatureDiscovery, func(spec *mellanoxv1alpha1.NicClusterPolicySpec) bool {
+		return spec.NicFeatureDiscovery != nil
+	}},
+	{"state-doca-telemetry-service", NewStateDOCATelemetryService,
+		func(spec *mellanoxv1alpha1.NicClusterPolicySpec) bool {
+			return spec.DOCATelemetryService != nil
+		}},
+	{"state-nic-configuration-daemon", NewStateNICConfigurationDaemon,
+		func(spec *mellanoxv1alpha1.NicClusterPolicySpec) bool {
+			return spec.NicConfigurationDaemon != nil
+		}},
+	{"state-node-feature-discovery", NewStateNodeFeatureDiscovery,
+		func(spec *mellanoxv1alpha1.NicClusterPolicySpec) bool {
+			return spec.NodeFeatureDiscovery != nil
+		}},
+	{"state-node-feature-rules", NewStateNodeFeatureRules, func(spec *mellanoxv1alpha1.NicClusterPolicySpec) bool {
+		return spec.NodeFeatureRules != nil
+	}},
+	{"state-additional-manifests", NewStateAdditionalManifests,
+		func(spec *mellanoxv1alpha1.NicClusterPolicySpec) bool {
+			return spec.AdditionalManifests != nil
+		}},
+}
+
+// RenderNicClusterPolicy renders the objects of all the states enabled in the NicClusterPolicy without
+// accessing the cluster, the raw patches of the policy are applied to the rendered objects which are then scoped
+// to the policy, see PolicyScope. The static config provides the CNI directories set for the operator.
+// The node pools of the OFED driver are computed from the given nodes, a sample node pool is used if no nodes
+// are given. Objects referenced by the policy, e.g. the ConfigMaps of the OFED driver, are not available
+// offline and fail the rendering.
+func RenderNicClusterPolicy(ctx context.Context, cr *mellanoxv1alpha1.NicClusterPolicy, nodes []*corev1.Node,
+	staticConfig staticconfig.StaticConfig, reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := mellanoxv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	// the client holds no objects, lookups of referenced objects fail instead of reaching a cluster
+	offlineClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	catalog := getDummyCatalog()
+	catalog.Add(InfoTypeStaticConfig, staticconfig.NewProvider(staticConfig))
+	if len(nodes) > 0 {
+		catalog.Add(InfoTypeNodeInfo, nodeinfo.NewProvider(nodes))
+	}
+
+	scope := PolicyScope{Name: cr.Name, NodeSelector: cr.Spec.NodeSelector}
+	manifestBaseDir := envConfig().State.ManifestBaseDir
+	objs := make([]*unstructured.Unstructured, 0)
+	for _, s := range offlineStates {
+		if !s.enabled(&cr.Spec) {
+			continue
+		}
+		_, renderer, err := s.newState(offlineClient, filepath.Join(manifestBaseDir, s.manifestDir))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create %s state", s.manifestDir)
+		}
+		stateObjs, err := renderer.GetManifestObjects(ctx, cr, catalog, reqLogger)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to render %s state", s.manifestDir)
+		}
+		for _, obj := range stateObjs {
+			if err := applyRawPatches(scheme, obj, cr.Spec.RawPatches); err != nil {
+				return nil, errors.Wrapf(err, "failed to patch %s %s", obj.GetKind(), obj.GetName())
+			}
+		}
+		if err := scope.apply(stateObjs); err != nil {
+			return nil, errors.Wrapf(err, "failed to scope %s state to policy %s", s.manifestDir, cr.Name)
+		}
+		objs = append(objs, stateObjs...)
+	}
+	return objs, nil
+}
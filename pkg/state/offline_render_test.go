@@ -0,0 +1,119 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/config"
+	"github.com/Mellanox/network-operator/pkg/nodeinfo"
+	"github.com/Mellanox/network-operator/pkg/staticconfig"
+)
+
+var _ = Describe("Offline rendering", func() {
+	var savedEnvConfig func() *config.OperatorConfig
+	var cr *mellanoxv1alpha1.NicClusterPolicy
+
+	BeforeEach(func() {
+		savedEnvConfig = envConfig
+		envConfig = func() *config.OperatorConfig {
+			return &config.OperatorConfig{
+				State: config.StateConfig{ManifestBaseDir: filepath.Join("..", "..", "manifests")}}
+		}
+		imageSpec := mellanoxv1alpha1.CNIPluginSpec{
+			ImageSpec: mellanoxv1alpha1.ImageSpec{Image: "image", Repository: "repository", Version: "version"}}
+		cr = &mellanoxv1alpha1.NicClusterPolicy{}
+		cr.Name = "nic-cluster-policy"
+		cr.Spec.SecondaryNetwork = &mellanoxv1alpha1.SecondaryNetworkSpec{RdmaCni: &imageSpec}
+	})
+
+	AfterEach(func() {
+		envConfig = savedEnvConfig
+	})
+
+	It("should list the NicClusterPolicy states in order", func() {
+		states, err := newNicClusterPolicyStates(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offlineStates).To(HaveLen(len(states)))
+		for i, s := range offlineStates {
+			offline, _, err := s.newState(nil, filepath.Join(envConfig().State.ManifestBaseDir, s.manifestDir))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offline.Name()).To(Equal(states[i].Name()))
+		}
+	})
+
+	It("should render only the enabled states", func() {
+		objs, err := RenderNicClusterPolicy(context.TODO(), cr, nil, staticconfig.StaticConfig{}, testLogger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(1))
+		Expect(objs[0].GetKind()).To(Equal("DaemonSet"))
+		Expect(objs[0].GetName()).To(Equal("kube-rdma-cni-ds"))
+	})
+
+	It("should apply the raw patches and the CNI bin directory", func() {
+		cr.Spec.RawPatches = []mellanoxv1alpha1.RawPatch{{
+			Target: mellanoxv1alpha1.RawPatchTarget{Kind: "DaemonSet"},
+			Patch:  `{"metadata": {"labels": {"patched": "true"}}}`,
+		}}
+		objs, err := RenderNicClusterPolicy(context.TODO(), cr, nil,
+			staticconfig.StaticConfig{CniBinDirectory: "/custom/cni/bin"}, testLogger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(1))
+		Expect(objs[0].GetLabels()).To(HaveKeyWithValue("patched", "true"))
+		Expect(objs[0].Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("template",
+			HaveKeyWithValue("spec", HaveKeyWithValue("volumes", ContainElement(
+				HaveKeyWithValue("hostPath", HaveKeyWithValue("path", "/custom/cni/bin"))))))))
+	})
+
+	It("should scope the objects to the policy", func() {
+		cr.Name = "ib"
+		cr.Spec.NodeSelector = map[string]string{"network.nvidia.com/type": "ib"}
+		objs, err := RenderNicClusterPolicy(context.TODO(), cr, nil, staticconfig.StaticConfig{}, testLogger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(1))
+		Expect(objs[0].GetName()).To(Equal("kube-rdma-cni-ds-ib"))
+		Expect(objs[0].Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("template",
+			HaveKeyWithValue("spec", HaveKeyWithValue("nodeSelector",
+				HaveKeyWithValue("network.nvidia.com/type", "ib"))))))
+	})
+
+	It("should render the OFED driver for the node pools of the given nodes", func() {
+		cr.Spec.OFEDDriver = &mellanoxv1alpha1.OFEDDriverSpec{ImageSpec: mellanoxv1alpha1.ImageSpec{
+			Image: "doca-driver", Repository: "repository", Version: "24.01-0.3.3.1"}}
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{
+			nodeinfo.NodeLabelMlnxNIC:       "true",
+			nodeinfo.NodeLabelOSName:        "rhel",
+			nodeinfo.NodeLabelOSVer:         "9.2",
+			nodeinfo.NodeLabelKernelVerFull: "5.14.0-284.32.1.el9_2.x86_64",
+			nodeinfo.NodeLabelCPUArch:       "amd64",
+		}}}
+		objs, err := RenderNicClusterPolicy(context.TODO(), cr, []*corev1.Node{node}, staticconfig.StaticConfig{}, testLogger)
+		Expect(err).NotTo(HaveOccurred())
+		names := make([]string, 0, len(objs))
+		for _, obj := range objs {
+			names = append(names, obj.GetName())
+		}
+		Expect(names).To(ContainElement(HavePrefix("mofed-rhel9.2-")))
+	})
+})
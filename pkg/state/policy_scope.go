@@ -0,0 +1,213 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+// PolicyScope describes the NicClusterPolicy the states are synced for. The objects of the policies other than
+// consts.NicClusterPolicyResourceName are suffixed with the name of the policy and labeled with it, so that several
+// policies can deploy the same states side by side. The DaemonSets are restricted to the nodes selected by the policy.
+type PolicyScope struct {
+	// Name of the NicClusterPolicy
+	Name string
+	// NodeSelector of the NicClusterPolicy, merged into the node selector of the DaemonSets
+	NodeSelector map[string]string
+}
+
+type policyScopeKey struct{}
+
+// WithPolicyScope returns a context in which the objects of the states are scoped to the given policy
+func WithPolicyScope(ctx context.Context, scope PolicyScope) context.Context {
+	return context.WithValue(ctx, policyScopeKey{}, scope)
+}
+
+// getPolicyScope returns the policy scope of the context, if any
+func getPolicyScope(ctx context.Context) (PolicyScope, bool) {
+	scope, ok := ctx.Value(policyScopeKey{}).(PolicyScope)
+	return scope, ok
+}
+
+// isDefault returns true for the consts.NicClusterPolicyResourceName policy, its objects keep the rendered names
+// and are not labeled with the policy name, as they were before several policies were supported
+func (p PolicyScope) isDefault() bool {
+	return p.Name == consts.NicClusterPolicyResourceName
+}
+
+// addPolicyRequirement adds the requirement which selects only the objects of the policy to the selector
+func (p PolicyScope) addPolicyRequirement(selector labels.Selector) (labels.Selector, error) {
+	op, values := selection.Equals, []string{p.Name}
+	if p.isDefault() {
+		op, values = selection.DoesNotExist, nil
+	}
+	req, err := labels.NewRequirement(consts.PolicyLabel, op, values)
+	if err != nil {
+		return nil, err
+	}
+	return selector.Add(*req), nil
+}
+
+// addScopeRequirement adds the policy requirement of the scope of the context to the selector, if any
+func addScopeRequirement(ctx context.Context, selector labels.Selector) (labels.Selector, error) {
+	scope, ok := getPolicyScope(ctx)
+	if !ok {
+		return selector, nil
+	}
+	return scope.addPolicyRequirement(selector)
+}
+
+// apply scopes the objects rendered by a state to the policy. The namespaced objects and the cluster roles and
+// cluster role bindings of a non-default policy are renamed and the references between them are updated, i.e. the
+// service account and the volumes of the workloads and the role and subjects of the role bindings. The Pods of their
+// workloads are labeled with the policy name. The other cluster-scoped objects, e.g. SecurityContextConstraints,
+// keep their names as other components look them up by name and are shared by the policies.
+func (p PolicyScope) apply(objs []*unstructured.Unstructured) error {
+	renamed := map[string]string{}
+	if !p.isDefault() {
+		for _, obj := range objs {
+			if !isPolicyScopedObject(obj) {
+				continue
+			}
+			name := obj.GetName() + "-" + p.Name
+			renamed[obj.GetKind()+"/"+obj.GetName()] = name
+			obj.SetName(name)
+			obj.SetLabels(labels.Merge(obj.GetLabels(), labels.Set{consts.PolicyLabel: p.Name}))
+		}
+	}
+	for _, obj := range objs {
+		var err error
+		switch obj.GetKind() {
+		case "DaemonSet":
+			if err = p.scopeWorkload(obj, renamed); err == nil && len(p.NodeSelector) > 0 {
+				err = mergeStringMap(obj, p.NodeSelector, "spec", "template", "spec", "nodeSelector")
+			}
+		case "Deployment":
+			err = p.scopeWorkload(obj, renamed)
+		case "RoleBinding", "ClusterRoleBinding":
+			err = scopeBinding(obj, renamed)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isPolicyScopedObject returns true if the object is deployed once per policy, i.e. it is namespaced
+// or it is a cluster role or cluster role binding granting permissions to the service accounts of the policy
+func isPolicyScopedObject(obj *unstructured.Unstructured) bool {
+	switch obj.GetKind() {
+	case "ClusterRole", "ClusterRoleBinding":
+		return true
+	}
+	return obj.GetNamespace() != ""
+}
+
+// scopeWorkload labels the Pods of the workload with the policy name and updates the references of its Pods
+// to the renamed objects
+func (p PolicyScope) scopeWorkload(obj *unstructured.Unstructured, renamed map[string]string) error {
+	if p.isDefault() {
+		return nil
+	}
+	policyLabel := map[string]string{consts.PolicyLabel: p.Name}
+	if err := mergeStringMap(obj, policyLabel, "spec", "selector", "matchLabels"); err != nil {
+		return err
+	}
+	if err := mergeStringMap(obj, policyLabel, "spec", "template", "metadata", "labels"); err != nil {
+		return err
+	}
+	for _, field := range []string{"serviceAccountName", "serviceAccount"} {
+		if err := renameReference(obj.Object, renamed, "ServiceAccount",
+			"spec", "template", "spec", field); err != nil {
+			return err
+		}
+	}
+	volumes, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "volumes")
+	if err != nil {
+		return err
+	}
+	for _, v := range volumes {
+		volume, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if err := renameReference(volume, renamed, "ConfigMap", "configMap", "name"); err != nil {
+			return err
+		}
+		if err := renameReference(volume, renamed, "Secret", "secret", "secretName"); err != nil {
+			return err
+		}
+	}
+	if volumes == nil {
+		return nil
+	}
+	return unstructured.SetNestedSlice(obj.Object, volumes, "spec", "template", "spec", "volumes")
+}
+
+// scopeBinding updates the references of the role binding to the renamed role and service accounts
+func scopeBinding(obj *unstructured.Unstructured, renamed map[string]string) error {
+	roleKind, _, err := unstructured.NestedString(obj.Object, "roleRef", "kind")
+	if err != nil {
+		return err
+	}
+	if err := renameReference(obj.Object, renamed, roleKind, "roleRef", "name"); err != nil {
+		return err
+	}
+	subjects, found, err := unstructured.NestedSlice(obj.Object, "subjects")
+	if err != nil || !found {
+		return err
+	}
+	for _, s := range subjects {
+		subject, ok := s.(map[string]interface{})
+		if !ok || subject["kind"] != "ServiceAccount" {
+			continue
+		}
+		if err := renameReference(subject, renamed, "ServiceAccount", "name"); err != nil {
+			return err
+		}
+	}
+	return unstructured.SetNestedSlice(obj.Object, subjects, "subjects")
+}
+
+// renameReference replaces the name at the given path if it references a renamed object of the given kind
+func renameReference(obj map[string]interface{}, renamed map[string]string, kind string, fields ...string) error {
+	name, found, err := unstructured.NestedString(obj, fields...)
+	if err != nil || !found {
+		return err
+	}
+	newName, ok := renamed[kind+"/"+name]
+	if !ok {
+		return nil
+	}
+	return unstructured.SetNestedField(obj, newName, fields...)
+}
+
+// mergeStringMap merges the values into the string map at the given path of the object
+func mergeStringMap(obj *unstructured.Unstructured, values map[string]string, fields ...string) error {
+	current, _, err := unstructured.NestedStringMap(obj.Object, fields...)
+	if err != nil {
+		return err
+	}
+	return unstructured.SetNestedStringMap(obj.Object, labels.Merge(current, values), fields...)
+}
@@ -0,0 +1,139 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+var _ = Describe("PolicyScope", func() {
+	var objs []*unstructured.Unstructured
+
+	toUnstructured := func(obj runtime.Object) *unstructured.Unstructured {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		Expect(err).NotTo(HaveOccurred())
+		return &unstructured.Unstructured{Object: u}
+	}
+
+	BeforeEach(func() {
+		ds := &appsv1.DaemonSet{
+			TypeMeta:   metav1.TypeMeta{Kind: "DaemonSet", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "dp", Namespace: "test"},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "dp"}},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "dp"}},
+					Spec: corev1.PodSpec{
+						ServiceAccountName: "dp",
+						NodeSelector:       map[string]string{"kubernetes.io/os": "linux"},
+						Volumes: []corev1.Volume{
+							{Name: "config", VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "dp-config"}}}},
+							{Name: "external", VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "external"}}}},
+						},
+					},
+				},
+			},
+		}
+		binding := &rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{Kind: "ClusterRoleBinding", APIVersion: "rbac.authorization.k8s.io/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "dp"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "dp"},
+			Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "dp", Namespace: "test"}},
+		}
+		objs = []*unstructured.Unstructured{
+			toUnstructured(ds),
+			toUnstructured(binding),
+			toUnstructured(&corev1.ServiceAccount{
+				TypeMeta:   metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "dp", Namespace: "test"}}),
+			toUnstructured(&corev1.ConfigMap{
+				TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "dp-config", Namespace: "test"}}),
+			toUnstructured(&rbacv1.ClusterRole{
+				TypeMeta:   metav1.TypeMeta{Kind: "ClusterRole", APIVersion: "rbac.authorization.k8s.io/v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "dp"}}),
+		}
+	})
+
+	getDaemonSet := func() *appsv1.DaemonSet {
+		ds := &appsv1.DaemonSet{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(objs[0].Object, ds)).To(Succeed())
+		return ds
+	}
+
+	It("Should keep the names of the objects of the default policy", func() {
+		scope := PolicyScope{Name: consts.NicClusterPolicyResourceName,
+			NodeSelector: map[string]string{"network.nvidia.com/type": "ib"}}
+		Expect(scope.apply(objs)).To(Succeed())
+		for _, obj := range objs {
+			Expect(obj.GetName()).To(Or(Equal("dp"), Equal("dp-config")))
+			Expect(obj.GetLabels()).NotTo(HaveKey(consts.PolicyLabel))
+		}
+		ds := getDaemonSet()
+		Expect(ds.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app": "dp"}))
+		Expect(ds.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{
+			"kubernetes.io/os": "linux", "network.nvidia.com/type": "ib"}))
+	})
+
+	It("Should rename the objects of another policy and their references", func() {
+		scope := PolicyScope{Name: "ib", NodeSelector: map[string]string{"network.nvidia.com/type": "ib"}}
+		Expect(scope.apply(objs)).To(Succeed())
+		for _, obj := range objs {
+			Expect(obj.GetName()).To(HaveSuffix("-ib"))
+			Expect(obj.GetLabels()).To(HaveKeyWithValue(consts.PolicyLabel, "ib"))
+		}
+		ds := getDaemonSet()
+		Expect(ds.Spec.Selector.MatchLabels).To(HaveKeyWithValue(consts.PolicyLabel, "ib"))
+		Expect(ds.Spec.Template.Labels).To(HaveKeyWithValue(consts.PolicyLabel, "ib"))
+		Expect(ds.Spec.Template.Spec.ServiceAccountName).To(Equal("dp-ib"))
+		Expect(ds.Spec.Template.Spec.NodeSelector).To(HaveKeyWithValue("network.nvidia.com/type", "ib"))
+		Expect(ds.Spec.Template.Spec.Volumes[0].ConfigMap.Name).To(Equal("dp-config-ib"))
+		// objects which are not rendered by the state keep their name
+		Expect(ds.Spec.Template.Spec.Volumes[1].ConfigMap.Name).To(Equal("external"))
+
+		binding := &rbacv1.ClusterRoleBinding{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(objs[1].Object, binding)).To(Succeed())
+		Expect(binding.RoleRef.Name).To(Equal("dp-ib"))
+		Expect(binding.Subjects[0].Name).To(Equal("dp-ib"))
+	})
+
+	It("Should keep the names of the shared cluster-scoped objects of another policy", func() {
+		scc := &unstructured.Unstructured{}
+		scc.SetAPIVersion("security.openshift.io/v1")
+		scc.SetKind("SecurityContextConstraints")
+		scc.SetName("dp")
+		objs = append(objs, scc)
+		scope := PolicyScope{Name: "ib", NodeSelector: map[string]string{"network.nvidia.com/type": "ib"}}
+		Expect(scope.apply(objs)).To(Succeed())
+		Expect(scc.GetName()).To(Equal("dp"))
+		Expect(scc.GetLabels()).NotTo(HaveKey(consts.PolicyLabel))
+		Expect(objs[4].GetName()).To(Equal("dp-ib"))
+	})
+})
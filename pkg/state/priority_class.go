@@ -0,0 +1,47 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+// setPriorityClassName sets the priority class of the pods of the DaemonSets and Deployments of a component,
+// the priority class of the component overrides the one of the policy. The priority class of the manifests
+// is kept if neither is set.
+func setPriorityClassName(objs []*unstructured.Unstructured,
+	spec *mellanoxv1alpha1.NicClusterPolicySpec, className string) error {
+	if className == "" {
+		className = spec.PriorityClassName
+	}
+	if className == "" {
+		return nil
+	}
+	for _, obj := range objs {
+		if obj.GetKind() != "DaemonSet" && obj.GetKind() != "Deployment" {
+			continue
+		}
+		err := unstructured.SetNestedField(obj.Object, className, "spec", "template", "spec", "priorityClassName")
+		if err != nil {
+			return errors.Wrapf(err, "failed to set priority class of %s %s", obj.GetKind(), obj.GetName())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+var _ = Describe("setPriorityClassName", func() {
+	var objs []*unstructured.Unstructured
+
+	getPriorityClassName := func(obj *unstructured.Unstructured) string {
+		name, _, err := unstructured.NestedString(obj.Object, "spec", "template", "spec", "priorityClassName")
+		Expect(err).NotTo(HaveOccurred())
+		return name
+	}
+
+	BeforeEach(func() {
+		objs = []*unstructured.Unstructured{
+			{Object: map[string]interface{}{
+				"kind": "DaemonSet",
+				"spec": map[string]interface{}{"template": map[string]interface{}{
+					"spec": map[string]interface{}{"priorityClassName": "system-node-critical"}}},
+			}},
+			{Object: map[string]interface{}{"kind": "Deployment"}},
+			{Object: map[string]interface{}{"kind": "ConfigMap"}},
+		}
+	})
+
+	It("should keep the priority class of the manifests if none is set", func() {
+		Expect(setPriorityClassName(objs, &mellanoxv1alpha1.NicClusterPolicySpec{}, "")).To(Succeed())
+		Expect(getPriorityClassName(objs[0])).To(Equal("system-node-critical"))
+		Expect(getPriorityClassName(objs[1])).To(BeEmpty())
+	})
+
+	It("should set the priority class of the policy", func() {
+		Expect(setPriorityClassName(objs, &mellanoxv1alpha1.NicClusterPolicySpec{PriorityClassName: "network"}, "")).To(Succeed())
+		Expect(getPriorityClassName(objs[0])).To(Equal("network"))
+		Expect(getPriorityClassName(objs[1])).To(Equal("network"))
+		Expect(objs[2].Object).NotTo(HaveKey("spec"))
+	})
+
+	It("should override the priority class of the policy with the one of the component", func() {
+		Expect(setPriorityClassName(objs, &mellanoxv1alpha1.NicClusterPolicySpec{PriorityClassName: "network"},
+			"network-critical")).To(Succeed())
+		Expect(getPriorityClassName(objs[0])).To(Equal("network-critical"))
+		Expect(getPriorityClassName(objs[1])).To(Equal("network-critical"))
+	})
+})
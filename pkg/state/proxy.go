@@ -0,0 +1,60 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+// addProxyEnvs returns the env variables with the proxy settings added, in both upper and lower case
+// for compatibility. Proxy settings which are already set in the env variables, in any case, are kept.
+func addProxyEnvs(envs []v1.EnvVar, proxy *mellanoxv1alpha1.ProxySpec) []v1.EnvVar {
+	if proxy == nil {
+		return envs
+	}
+	// use [][]string to preserve order of env variables
+	proxiesParams := [][]string{
+		{envVarNameHTTPSProxy, proxy.HTTPSProxy},
+		{envVarNameHTTPProxy, proxy.HTTPProxy},
+		{envVarNameNoProxy, proxy.NoProxy},
+	}
+	envsFromStaticCfg := map[string]v1.EnvVar{}
+	for _, e := range envs {
+		envsFromStaticCfg[e.Name] = e
+	}
+	for _, param := range proxiesParams {
+		envKey, envValue := param[0], param[1]
+		if envValue == "" {
+			continue
+		}
+		_, upperCaseExist := envsFromStaticCfg[strings.ToUpper(envKey)]
+		_, lowerCaseExist := envsFromStaticCfg[strings.ToLower(envKey)]
+		if upperCaseExist || lowerCaseExist {
+			// environment variable statically configured in NicClusterPolicy
+			continue
+		}
+		envs = append(envs,
+			v1.EnvVar{Name: strings.ToUpper(envKey), Value: envValue},
+			v1.EnvVar{Name: strings.ToLower(envKey), Value: envValue},
+		)
+	}
+	return envs
+}
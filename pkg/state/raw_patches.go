@@ -0,0 +1,92 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/yaml"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+// applyRawPatches applies the patches matching the object in order, the object is patched in place.
+// Strategic merge patches use the type registered in the scheme for the object kind,
+// for kinds which are not registered a JSON merge patch is applied.
+func applyRawPatches(scheme *runtime.Scheme, obj *unstructured.Unstructured,
+	patches []mellanoxv1alpha1.RawPatch) error {
+	for i := range patches {
+		patch := &patches[i]
+		if !rawPatchMatches(&patch.Target, obj) {
+			continue
+		}
+		original, err := json.Marshal(obj.Object)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal object")
+		}
+		patched, err := applyRawPatch(scheme, obj, original, patch)
+		if err != nil {
+			return errors.Wrapf(err, "failed to apply raw patch %d", i)
+		}
+		patchedObj := map[string]interface{}{}
+		if err := json.Unmarshal(patched, &patchedObj); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal object patched by raw patch %d", i)
+		}
+		obj.Object = patchedObj
+	}
+	return nil
+}
+
+func applyRawPatch(scheme *runtime.Scheme, obj *unstructured.Unstructured, original []byte,
+	patch *mellanoxv1alpha1.RawPatch) ([]byte, error) {
+	patchJSON, err := yaml.YAMLToJSON([]byte(patch.Patch))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse patch")
+	}
+	switch patch.Type {
+	case mellanoxv1alpha1.RawPatchTypeJSON6902:
+		jsonPatch, err := jsonpatch.DecodePatch(patchJSON)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode JSON6902 patch")
+		}
+		return jsonPatch.Apply(original)
+	case mellanoxv1alpha1.RawPatchTypeStrategicMerge, "":
+		dataStruct, err := scheme.New(obj.GroupVersionKind())
+		if err != nil {
+			if runtime.IsNotRegisteredError(err) {
+				return jsonpatch.MergePatch(original, patchJSON)
+			}
+			return nil, err
+		}
+		return strategicpatch.StrategicMergePatch(original, patchJSON, dataStruct)
+	default:
+		return nil, fmt.Errorf("unsupported patch type %s", patch.Type)
+	}
+}
+
+// rawPatchMatches returns true if the object is selected by the patch target
+func rawPatchMatches(target *mellanoxv1alpha1.RawPatchTarget, obj *unstructured.Unstructured) bool {
+	return target.Kind == obj.GetKind() &&
+		(target.Name == "" || target.Name == obj.GetName()) &&
+		(target.Namespace == "" || target.Namespace == obj.GetNamespace())
+}
@@ -0,0 +1,137 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+func testDaemonSet() *unstructured.Unstructured {
+	ds := &appsv1.DaemonSet{
+		TypeMeta:   metav1.TypeMeta{Kind: "DaemonSet", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ds", Namespace: "test"},
+		Spec: appsv1.DaemonSetSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "main", Image: "main:v1"},
+						{Name: "sidecar", Image: "sidecar:v1"},
+					},
+				},
+			},
+		},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ds)
+	Expect(err).NotTo(HaveOccurred())
+	return &unstructured.Unstructured{Object: obj}
+}
+
+var _ = Describe("Raw patches", func() {
+	It("Should apply a strategic merge patch by merge key", func() {
+		obj := testDaemonSet()
+		err := applyRawPatches(scheme.Scheme, obj, []mellanoxv1alpha1.RawPatch{{
+			Target: mellanoxv1alpha1.RawPatchTarget{Kind: "DaemonSet"},
+			Type:   mellanoxv1alpha1.RawPatchTypeStrategicMerge,
+			Patch: `
+spec:
+  template:
+    spec:
+      nodeSelector:
+        role: network
+      containers:
+      - name: sidecar
+        image: sidecar:v2`,
+		}})
+		Expect(err).NotTo(HaveOccurred())
+		ds := &appsv1.DaemonSet{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, ds)).To(Succeed())
+		Expect(ds.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"role": "network"}))
+		Expect(ds.Spec.Template.Spec.Containers).To(HaveLen(2))
+		Expect(ds.Spec.Template.Spec.Containers[0].Image).To(Equal("main:v1"))
+		Expect(ds.Spec.Template.Spec.Containers[1].Image).To(Equal("sidecar:v2"))
+	})
+	It("Should apply a JSON6902 patch", func() {
+		obj := testDaemonSet()
+		err := applyRawPatches(scheme.Scheme, obj, []mellanoxv1alpha1.RawPatch{{
+			Target: mellanoxv1alpha1.RawPatchTarget{Kind: "DaemonSet", Name: "test-ds", Namespace: "test"},
+			Type:   mellanoxv1alpha1.RawPatchTypeJSON6902,
+			Patch:  `[{"op": "add", "path": "/metadata/annotations", "value": {"foo": "bar"}}]`,
+		}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj.GetAnnotations()).To(Equal(map[string]string{"foo": "bar"}))
+	})
+	It("Should apply a JSON merge patch to kinds not registered in the scheme", func() {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("example.com/v1")
+		obj.SetKind("Custom")
+		obj.SetName("test")
+		err := applyRawPatches(scheme.Scheme, obj, []mellanoxv1alpha1.RawPatch{{
+			Target: mellanoxv1alpha1.RawPatchTarget{Kind: "Custom"},
+			Patch:  `{"metadata": {"labels": {"foo": "bar"}}}`,
+		}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj.GetLabels()).To(Equal(map[string]string{"foo": "bar"}))
+	})
+	It("Should skip objects not matching the target", func() {
+		obj := testDaemonSet()
+		expected := obj.DeepCopy()
+		err := applyRawPatches(scheme.Scheme, obj, []mellanoxv1alpha1.RawPatch{
+			{Target: mellanoxv1alpha1.RawPatchTarget{Kind: "ConfigMap"}, Patch: `{"data": {"foo": "bar"}}`},
+			{Target: mellanoxv1alpha1.RawPatchTarget{Kind: "DaemonSet", Name: "other"}, Patch: `{"spec": null}`},
+			{Target: mellanoxv1alpha1.RawPatchTarget{Kind: "DaemonSet", Namespace: "other"}, Patch: `{"spec": null}`},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj).To(Equal(expected))
+	})
+	It("Should fail on invalid patch", func() {
+		err := applyRawPatches(scheme.Scheme, testDaemonSet(), []mellanoxv1alpha1.RawPatch{{
+			Target: mellanoxv1alpha1.RawPatchTarget{Kind: "DaemonSet"},
+			Type:   mellanoxv1alpha1.RawPatchTypeJSON6902,
+			Patch:  `[{"op": "remove", "path": "/metadata/doesNotExist"}]`,
+		}})
+		Expect(err).To(HaveOccurred())
+	})
+	It("Should create patched objects", func() {
+		s := stateSkel{name: testState, client: fake.NewClientBuilder().Build()}
+		obj := testDaemonSet()
+		err := s.createOrUpdateObjs(context.Background(), func(obj *unstructured.Unstructured) error { return nil },
+			[]*unstructured.Unstructured{obj}, []mellanoxv1alpha1.RawPatch{{
+				Target: mellanoxv1alpha1.RawPatchTarget{Kind: "DaemonSet"},
+				Patch:  `{"metadata": {"labels": {"foo": "bar"}}}`,
+			}})
+		Expect(err).NotTo(HaveOccurred())
+		ds := &appsv1.DaemonSet{}
+		Expect(s.client.Get(context.Background(), types.NamespacedName{Name: "test-ds", Namespace: "test"}, ds)).
+			To(Succeed())
+		Expect(ds.Labels).To(HaveKeyWithValue("foo", "bar"))
+		Expect(ds.Labels).To(HaveKeyWithValue(consts.StateLabel, testState))
+	})
+})
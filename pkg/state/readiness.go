@@ -0,0 +1,119 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// ObjectReadiness is the rollout progress of a workload of a state, e.g. of a DaemonSet
+type ObjectReadiness struct {
+	// Kind and Name of the workload, the name is prefixed by the namespace
+	Kind string
+	Name string
+	// Desired is the number of nodes which should run a pod of the workload
+	Desired int32
+	// Ready is the number of nodes which run an available pod of the current revision of the workload
+	Ready int32
+	// Generation is the generation of the spec of the workload, the status is not up to date with the spec
+	// until ObservedGeneration reaches it
+	Generation         int64
+	ObservedGeneration int64
+}
+
+// String returns the rollout progress of the workload, e.g. "DaemonSet ns/name: 45/50 nodes ready"
+func (r ObjectReadiness) String() string {
+	return fmt.Sprintf("%s %s: %d/%d nodes ready", r.Kind, r.Name, r.Ready, r.Desired)
+}
+
+// daemonSetReadiness returns the rollout progress of the DaemonSet
+func daemonSetReadiness(ds *appsv1.DaemonSet) ObjectReadiness {
+	// pods of a previous revision are available while the DaemonSet is rolled out, they are not counted
+	ready := ds.Status.NumberAvailable
+	if ds.Status.UpdatedNumberScheduled < ready {
+		ready = ds.Status.UpdatedNumberScheduled
+	}
+	name := ds.Name
+	if ds.Namespace != "" {
+		name = ds.Namespace + "/" + name
+	}
+	return ObjectReadiness{
+		Kind:               "DaemonSet",
+		Name:               name,
+		Desired:            ds.Status.DesiredNumberScheduled,
+		Ready:              ready,
+		Generation:         ds.Generation,
+		ObservedGeneration: ds.Status.ObservedGeneration,
+	}
+}
+
+// ReadinessSummary returns the rollout progress of all the workloads of the state, e.g. "45/50 nodes ready",
+// or an empty string if the state has no workloads. The nodes of each workload are counted.
+func (r *Result) ReadinessSummary() string {
+	if len(r.Readiness) == 0 {
+		return ""
+	}
+	var desired, ready int32
+	pending := false
+	for _, readiness := range r.Readiness {
+		desired += readiness.Desired
+		ready += readiness.Ready
+		pending = pending || readiness.ObservedGeneration < readiness.Generation
+	}
+	summary := fmt.Sprintf("%d/%d nodes ready", ready, desired)
+	if pending {
+		summary += ", rollout pending"
+	}
+	return summary
+}
+
+type readinessRecorderKey struct{}
+
+// readinessRecorder collects the rollout progress of the workloads of a state checked during a sync
+type readinessRecorder struct {
+	mu        sync.Mutex
+	readiness []ObjectReadiness
+}
+
+// withReadinessRecorder returns a context which records the readiness of the workloads in the returned
+// readinessRecorder
+func withReadinessRecorder(ctx context.Context) (context.Context, *readinessRecorder) {
+	recorder := &readinessRecorder{}
+	return context.WithValue(ctx, readinessRecorderKey{}, recorder), recorder
+}
+
+// recordReadiness records the readiness of a workload in the readinessRecorder of the context, if any
+func recordReadiness(ctx context.Context, readiness ObjectReadiness) {
+	recorder, ok := ctx.Value(readinessRecorderKey{}).(*readinessRecorder)
+	if !ok {
+		return
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.readiness = append(recorder.readiness, readiness)
+}
+
+// recorded returns the recorded readiness of the workloads
+func (r *readinessRecorder) recorded() []ObjectReadiness {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.readiness
+}
@@ -0,0 +1,86 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+// setSecurityContext customizes the security context of the privileged containers of the DaemonSets and
+// Deployments of a component, the containers which already run without privileges are kept as is.
+func setSecurityContext(objs []*unstructured.Unstructured,
+	spec *mellanoxv1alpha1.ContainerSecurityContextSpec) error {
+	if spec == nil {
+		return nil
+	}
+	for _, obj := range objs {
+		if obj.GetKind() != "DaemonSet" && obj.GetKind() != "Deployment" {
+			continue
+		}
+		for _, field := range []string{"initContainers", "containers"} {
+			containers, found, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", field)
+			if err != nil || !found {
+				continue
+			}
+			for _, c := range containers {
+				if err := setContainerSecurityContext(c.(map[string]interface{}), spec); err != nil {
+					return errors.Wrapf(err, "failed to set security context of %s %s", obj.GetKind(), obj.GetName())
+				}
+			}
+			if err := unstructured.SetNestedSlice(obj.Object, containers, "spec", "template", "spec", field); err != nil {
+				return errors.Wrapf(err, "failed to set security context of %s %s", obj.GetKind(), obj.GetName())
+			}
+		}
+	}
+	return nil
+}
+
+// setContainerSecurityContext updates the security context of the container if it is privileged
+func setContainerSecurityContext(container map[string]interface{},
+	spec *mellanoxv1alpha1.ContainerSecurityContextSpec) error {
+	privileged, _, _ := unstructured.NestedBool(container, "securityContext", "privileged")
+	if !privileged {
+		return nil
+	}
+	securityContext, _, _ := unstructured.NestedMap(container, "securityContext")
+	if spec.Privileged != nil && !*spec.Privileged {
+		securityContext["privileged"] = false
+	}
+	if spec.SeccompProfile != nil {
+		profile, err := runtime.DefaultUnstructuredConverter.ToUnstructured(spec.SeccompProfile)
+		if err != nil {
+			return err
+		}
+		securityContext["seccompProfile"] = profile
+	}
+	if spec.SELinuxOptions != nil {
+		options, err := runtime.DefaultUnstructuredConverter.ToUnstructured(spec.SELinuxOptions)
+		if err != nil {
+			return err
+		}
+		securityContext["seLinuxOptions"] = options
+	}
+	if spec.ReadOnlyRootFilesystem {
+		securityContext["readOnlyRootFilesystem"] = true
+	}
+	container["securityContext"] = securityContext
+	return nil
+}
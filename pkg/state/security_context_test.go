@@ -0,0 +1,93 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+var _ = Describe("setSecurityContext", func() {
+	var objs []*unstructured.Unstructured
+
+	getSecurityContext := func(field string, index int) map[string]interface{} {
+		containers, _, err := unstructured.NestedSlice(objs[0].Object, "spec", "template", "spec", field)
+		Expect(err).NotTo(HaveOccurred())
+		securityContext, _, err := unstructured.NestedMap(containers[index].(map[string]interface{}),
+			"securityContext")
+		Expect(err).NotTo(HaveOccurred())
+		return securityContext
+	}
+
+	BeforeEach(func() {
+		objs = []*unstructured.Unstructured{
+			{Object: map[string]interface{}{
+				"kind": "DaemonSet",
+				"spec": map[string]interface{}{"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"initContainers": []interface{}{
+							map[string]interface{}{"name": "init"},
+						},
+						"containers": []interface{}{
+							map[string]interface{}{"name": "plugin",
+								"securityContext": map[string]interface{}{"privileged": true}},
+							map[string]interface{}{"name": "sidecar",
+								"securityContext": map[string]interface{}{"allowPrivilegeEscalation": false}},
+						},
+					}}},
+			}},
+		}
+	})
+
+	It("should keep the security context of the manifests if not set", func() {
+		Expect(setSecurityContext(objs, nil)).To(Succeed())
+		Expect(getSecurityContext("containers", 0)).To(Equal(map[string]interface{}{"privileged": true}))
+	})
+
+	It("should customize the security context of the privileged containers only", func() {
+		privileged := false
+		Expect(setSecurityContext(objs, &mellanoxv1alpha1.ContainerSecurityContextSpec{
+			Privileged:             &privileged,
+			SeccompProfile:         &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+			SELinuxOptions:         &corev1.SELinuxOptions{Type: "container_device_t"},
+			ReadOnlyRootFilesystem: true,
+		})).To(Succeed())
+		Expect(getSecurityContext("containers", 0)).To(Equal(map[string]interface{}{
+			"privileged":             false,
+			"seccompProfile":         map[string]interface{}{"type": "RuntimeDefault"},
+			"seLinuxOptions":         map[string]interface{}{"type": "container_device_t"},
+			"readOnlyRootFilesystem": true,
+		}))
+		Expect(getSecurityContext("containers", 1)).To(Equal(
+			map[string]interface{}{"allowPrivilegeEscalation": false}))
+		Expect(getSecurityContext("initContainers", 0)).To(BeNil())
+	})
+
+	It("should keep the containers privileged by default", func() {
+		Expect(setSecurityContext(objs, &mellanoxv1alpha1.ContainerSecurityContextSpec{
+			SELinuxOptions: &corev1.SELinuxOptions{Level: "s0"},
+		})).To(Succeed())
+		Expect(getSecurityContext("containers", 0)).To(Equal(map[string]interface{}{
+			"privileged":     true,
+			"seLinuxOptions": map[string]interface{}{"level": "s0"},
+		}))
+	})
+})
@@ -19,8 +19,6 @@ package state
 
 import (
 	"context"
-
-	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // SyncState represents the Sync state of a specific State or a collection of States
@@ -54,6 +52,25 @@ type State interface {
 	// InfoCatalog is provided to optionally provide a State additional infoSources required for it to perform
 	// the Sync operation.
 	Sync(ctx context.Context, customResource interface{}, infoCatalog InfoCatalog) (SyncState, error)
-	// GetWatchSources provides a map of source kinds that should be watched for the state keyed by the source kind name
-	GetWatchSources() map[string]client.Object
+	// GetWatchSources provides a map of source kinds that should be watched for the state keyed by the source kind name,
+	// the predicates of a source filter out the events of the objects which are not relevant to the state
+	GetWatchSources() map[string]WatchSource
+}
+
+// TeardownState is a State which can remove its objects before the custom resource is deleted.
+type TeardownState interface {
+	State
+	// Teardown removes the objects of the State, the workloads are removed first and the other objects,
+	// e.g. the RBAC and ConfigMaps used by the workloads, only once the Pods of the workloads are gone.
+	// Returns true once all the objects are removed.
+	Teardown(ctx context.Context) (bool, error)
+}
+
+// DependentState is a State which declares the States it depends on.
+// A DependentState is synced by the Manager only after all of its dependencies were synced
+// and only if none of them is in SyncStateNotReady or SyncStateError, otherwise it is reported as SyncStateNotReady.
+type DependentState interface {
+	State
+	// Dependencies provides the names of the States which should be synced before this State
+	Dependencies() []string
 }
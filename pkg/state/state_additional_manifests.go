@@ -0,0 +1,163 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/consts"
+	"github.com/Mellanox/network-operator/pkg/render"
+)
+
+// NewStateAdditionalManifests creates a new state for the additional manifests of the NicClusterPolicy,
+// the manifests are read from the ConfigMap referenced by the policy, manifestDir is not used
+func NewStateAdditionalManifests(
+	k8sAPIClient client.Client, _ string) (State, ManifestRenderer, error) {
+	state := &stateAdditionalManifests{
+		stateSkel: stateSkel{
+			name:        "state-additional-manifests",
+			description: "Additional manifests of the NicClusterPolicy deployed in the cluster",
+			client:      k8sAPIClient,
+		}}
+	return state, state, nil
+}
+
+type stateAdditionalManifests struct {
+	stateSkel
+}
+
+// Sync attempt to get the system to match the desired state which State represent.
+// a sync operation must be relatively short and must not block the execution thread.
+func (s *stateAdditionalManifests) Sync(
+	ctx context.Context, customResource interface{}, infoCatalog InfoCatalog) (SyncState, error) {
+	reqLogger := log.FromContext(ctx)
+	cr := customResource.(*mellanoxv1alpha1.NicClusterPolicy)
+	reqLogger.V(consts.LogLevelInfo).Info(
+		"Sync Custom resource", "State:", s.name, "Name:", cr.Name, "Namespace:", cr.Namespace)
+
+	if cr.Spec.AdditionalManifests == nil {
+		// Either this state was not required to run or an update occurred and we need to remove
+		// the resources that where created.
+		return s.handleStateObjectsDeletion(ctx)
+	}
+
+	objs, err := s.GetManifestObjects(ctx, cr, infoCatalog, reqLogger)
+	if err != nil {
+		return SyncStateNotReady, &RenderError{Err: errors.Wrap(err, "failed to create k8s objects from manifest")}
+	}
+
+	// Create objects if they dont exist, Update objects if they do exist
+	err = s.createOrUpdateObjs(ctx, func(obj *unstructured.Unstructured) error {
+		if err := controllerutil.SetControllerReference(cr, obj, s.client.Scheme()); err != nil {
+			return errors.Wrap(err, "failed to set controller reference for object")
+		}
+		return nil
+	}, objs, cr.Spec.RawPatches)
+	if err != nil {
+		return SyncStateNotReady, errors.Wrap(err, "failed to create/update objects")
+	}
+	// the objects removed from the ConfigMap are removed as stale objects
+	waitForStaleObjectsRemoval, err := s.handleStaleStateObjects(ctx, objs)
+	if err != nil {
+		return SyncStateNotReady, errors.Wrap(err, "failed to handle state stale objects")
+	}
+	if waitForStaleObjectsRemoval {
+		return SyncStateNotReady, nil
+	}
+	return SyncStateReady, nil
+}
+
+// GetWatchSources returns a map of source kinds that should be watched for the state keyed by the source kind name,
+// the kinds of the additional manifests are arbitrary and are not watched
+func (s *stateAdditionalManifests) GetWatchSources() map[string]WatchSource {
+	return map[string]WatchSource{}
+}
+
+// GetManifestObjects decodes the objects of the additional manifests ConfigMap, namespaced objects without
+// a namespace are placed in the namespace of the operator. No objects are rendered without additional manifests.
+func (s *stateAdditionalManifests) GetManifestObjects(
+	ctx context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
+	_ InfoCatalog, reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
+	if cr == nil {
+		return nil, errors.New("failed to render objects: state spec is nil")
+	}
+	if cr.Spec.AdditionalManifests == nil {
+		return []*unstructured.Unstructured{}, nil
+	}
+
+	source := &configMapDataSource{
+		client: s.client,
+		name: types.NamespacedName{
+			Namespace: envConfig().State.NetworkOperatorResourceNamespace,
+			Name:      cr.Spec.AdditionalManifests.ConfigMapName,
+		},
+	}
+	objs, err := render.NewRawRenderer(source).RenderObjects(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render objects")
+	}
+	for _, obj := range objs {
+		if obj.GetNamespace() != "" {
+			continue
+		}
+		namespaced, err := s.client.IsObjectNamespaced(obj)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the scope of %s %s", obj.GetKind(), obj.GetName())
+		}
+		if namespaced {
+			obj.SetNamespace(source.name.Namespace)
+		}
+	}
+	reqLogger.V(consts.LogLevelDebug).Info("Rendered", "objects:", objs)
+	return objs, nil
+}
+
+// configMapDataSource provides the manifests of a single ConfigMap, each key of the ConfigMap is a manifest.
+// Manifests are ordered by key.
+type configMapDataSource struct {
+	client client.Reader
+	name   types.NamespacedName
+}
+
+// Manifests returns the manifests of the ConfigMap
+func (s *configMapDataSource) Manifests(ctx context.Context) ([]render.Manifest, error) {
+	cm := &v1.ConfigMap{}
+	if err := s.client.Get(ctx, s.name, cm); err != nil {
+		return nil, errors.Wrapf(err, "failed to get manifests ConfigMap %s", s.name)
+	}
+	keys := make([]string, 0, len(cm.Data))
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	manifests := make([]render.Manifest, 0, len(keys))
+	for _, key := range keys {
+		manifests = append(manifests, render.Manifest{Name: key, Content: cm.Data[key]})
+	}
+	return manifests, nil
+}
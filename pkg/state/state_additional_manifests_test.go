@@ -0,0 +1,145 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/config"
+	"github.com/Mellanox/network-operator/pkg/state"
+)
+
+const (
+	additionalNetworkPolicy = `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: allow-metrics
+spec:
+  podSelector: {}
+`
+	additionalClusterRole = `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: metrics-reader
+`
+)
+
+var _ = Describe("Additional manifests state", func() {
+	var (
+		cr        *mellanoxv1alpha1.NicClusterPolicy
+		cm        *corev1.ConfigMap
+		k8sClient client.Client
+		s         state.State
+		renderer  state.ManifestRenderer
+		namespace string
+	)
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		namespace = config.FromEnv().State.NetworkOperatorResourceNamespace
+		scheme := runtime.NewScheme()
+		Expect(mellanoxv1alpha1.AddToScheme(scheme)).NotTo(HaveOccurred())
+		Expect(corev1.AddToScheme(scheme)).NotTo(HaveOccurred())
+		Expect(networkingv1.AddToScheme(scheme)).NotTo(HaveOccurred())
+		Expect(rbacv1.AddToScheme(scheme)).NotTo(HaveOccurred())
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{
+			networkingv1.SchemeGroupVersion, rbacv1.SchemeGroupVersion})
+		mapper.Add(networkingv1.SchemeGroupVersion.WithKind("NetworkPolicy"), meta.RESTScopeNamespace)
+		mapper.Add(rbacv1.SchemeGroupVersion.WithKind("ClusterRole"), meta.RESTScopeRoot)
+
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "additional-manifests", Namespace: namespace},
+			Data:       map[string]string{"b.yaml": additionalClusterRole, "a.yaml": additionalNetworkPolicy},
+		}
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(cm).Build()
+		var err error
+		s, renderer, err = state.NewStateAdditionalManifests(k8sClient, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		cr = getTestClusterPolicyWithBaseFields()
+		cr.Name = "nic-cluster-policy"
+		cr.UID = "policy-uid"
+		cr.Spec.AdditionalManifests = &mellanoxv1alpha1.AdditionalManifestsSpec{ConfigMapName: cm.Name}
+	})
+
+	It("should not render objects without AdditionalManifests spec", func() {
+		cr.Spec.AdditionalManifests = nil
+		objs, err := renderer.GetManifestObjects(ctx, cr, state.NewInfoCatalog(), log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(BeEmpty())
+	})
+
+	It("should fail to render if the ConfigMap does not exist", func() {
+		cr.Spec.AdditionalManifests.ConfigMapName = "missing"
+		_, err := renderer.GetManifestObjects(ctx, cr, state.NewInfoCatalog(), log.Log)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should render the objects ordered by key and default the namespace of namespaced objects", func() {
+		objs, err := renderer.GetManifestObjects(ctx, cr, state.NewInfoCatalog(), log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(2))
+		Expect(objs[0].GetKind()).To(Equal("NetworkPolicy"))
+		Expect(objs[0].GetNamespace()).To(Equal(namespace))
+		Expect(objs[1].GetKind()).To(Equal("ClusterRole"))
+		Expect(objs[1].GetNamespace()).To(BeEmpty())
+	})
+
+	It("should apply the objects owned by the policy and delete the objects removed from the ConfigMap", func() {
+		status, err := s.Sync(ctx, cr, state.NewInfoCatalog())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(BeEquivalentTo(state.SyncStateReady))
+		np := &networkingv1.NetworkPolicy{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "allow-metrics"}, np)).To(Succeed())
+		Expect(np.OwnerReferences).To(HaveLen(1))
+		Expect(np.OwnerReferences[0].UID).To(Equal(cr.UID))
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "metrics-reader"}, &rbacv1.ClusterRole{})).To(Succeed())
+
+		By("Removing the NetworkPolicy from the ConfigMap")
+		delete(cm.Data, "a.yaml")
+		Expect(k8sClient.Update(ctx, cm)).To(Succeed())
+		status, err = s.Sync(ctx, cr, state.NewInfoCatalog())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(BeEquivalentTo(state.SyncStateNotReady))
+		err = k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "allow-metrics"}, np)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "metrics-reader"}, &rbacv1.ClusterRole{})).To(Succeed())
+
+		status, err = s.Sync(ctx, cr, state.NewInfoCatalog())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(BeEquivalentTo(state.SyncStateReady))
+	})
+})
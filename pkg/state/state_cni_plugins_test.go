@@ -214,6 +214,24 @@ var _ = Describe("CNI plugins state", func() {
 			Expect(ds.Spec).To(BeEquivalentTo(expectedDs.Spec))
 
 		})
+
+		It("should render Daemonset with UpdateStrategy when specified in CR", func() {
+			By("Sync")
+			cr := getMinimalNicClusterPolicyWithCNIPlugins()
+			cr.Spec.SecondaryNetwork.CniPlugins.UpdateStrategy = &appsv1.DaemonSetUpdateStrategy{
+				Type: appsv1.OnDeleteDaemonSetStrategyType,
+			}
+			_, err := cniPluginsState.Sync(context.Background(), cr, catalog)
+			Expect(err).NotTo(HaveOccurred())
+			By("Verify DaemonSet")
+			ds := &appsv1.DaemonSet{}
+			err = client.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: "cni-plugins-ds"}, ds)
+			Expect(err).NotTo(HaveOccurred())
+			expectedDs := getExpectedMinimalCniPluginDS()
+			expectedDs.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}
+			By("Verify UpdateStrategy")
+			Expect(ds.Spec).To(BeEquivalentTo(expectedDs.Spec))
+		})
 	})
 	Context("Verify Sync flows", func() {
 		It("should create Daemonset, update state to Ready", func() {
@@ -293,7 +311,7 @@ func getMinimalNicClusterPolicyWithCNIPlugins() *mellanoxv1alpha1.NicClusterPoli
 	cr.Name = "nic-cluster-policy"
 
 	secondaryNetworkSpec := &mellanoxv1alpha1.SecondaryNetworkSpec{}
-	secondaryNetworkSpec.CniPlugins = &mellanoxv1alpha1.ImageSpec{}
+	secondaryNetworkSpec.CniPlugins = &mellanoxv1alpha1.CNIPluginSpec{}
 	secondaryNetworkSpec.CniPlugins.Image = "myimage"
 	secondaryNetworkSpec.CniPlugins.Repository = "myrepo"
 	secondaryNetworkSpec.CniPlugins.Version = "myversion"
@@ -321,6 +339,9 @@ func getExpectedMinimalCniPluginDS() *appsv1.DaemonSet {
 					"name": "cni-plugins",
 				},
 			},
+			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+				Type: appsv1.RollingUpdateDaemonSetStrategyType,
+			},
 			Template: v1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: map[string]string{
@@ -18,10 +18,10 @@ package state
 
 import (
 	"context"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
-	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -32,7 +32,6 @@ import (
 	"github.com/Mellanox/network-operator/pkg/config"
 	"github.com/Mellanox/network-operator/pkg/consts"
 	"github.com/Mellanox/network-operator/pkg/render"
-	"github.com/Mellanox/network-operator/pkg/utils"
 )
 
 type docaTelemetryServiceState struct {
@@ -45,19 +44,31 @@ type dtsRuntimeSpec struct {
 }
 
 const (
-	docaTelemetryServiceName                 = "state-doca-telemetry-service"
-	docaTelemetryServiceDefaultConfigMapName = "doca-telemetry-service"
-	docaTelemetryServiceDescription          = "DOCA Telemetry Service deployed in the cluster"
+	docaTelemetryServiceName                  = "state-doca-telemetry-service"
+	docaTelemetryServiceDefaultConfigMapName  = "doca-telemetry-service"
+	docaTelemetryServiceDescription           = "DOCA Telemetry Service deployed in the cluster"
+	docaTelemetryServiceDefaultPrometheusPort = 9189
 )
 
+// docaTelemetryServiceDefaultProviders are the counter providers enabled in the default config
+var docaTelemetryServiceDefaultProviders = []string{"sysfs", "pod_resources", "ethtool", "ifconfig"}
+
 // DOCATelemetryServiceManifestRenderData is used to render Kubernetes objects related to DOCA Telemetry Service.
 type DOCATelemetryServiceManifestRenderData struct {
 	CrSpec          *mellanoxv1alpha1.DOCATelemetryServiceSpec
 	ConfigMapName   string
 	DeployConfigMap bool
-	RuntimeSpec     *dtsRuntimeSpec
-	Tolerations     []v1.Toleration
-	NodeAffinity    *v1.NodeAffinity
+	// Providers are the counter providers enabled in the default config
+	Providers []string
+	// PrometheusPort is the port of the Prometheus endpoint in the default config
+	PrometheusPort int
+	// PrometheusIgnoreCounters is a comma-separated list of counters not exported to Prometheus in the default config
+	PrometheusIgnoreCounters string
+	RuntimeSpec              *dtsRuntimeSpec
+	Tolerations              []v1.Toleration
+	ImagePullSecrets         []string
+	NodeAffinity             *v1.NodeAffinity
+	NodeSelector             map[string]string
 }
 
 // Sync attempt to get the system to match the desired state which State represents.
@@ -79,7 +90,7 @@ func (d docaTelemetryServiceState) Sync(
 
 	objs, err := d.GetManifestObjects(ctx, cr, infoCatalog, reqLogger)
 	if err != nil {
-		return SyncStateNotReady, errors.Wrap(err, "failed to create k8s objects from manifest")
+		return SyncStateNotReady, &RenderError{Err: errors.Wrap(err, "failed to create k8s objects from manifest")}
 	}
 	if len(objs) == 0 {
 		return SyncStateNotReady, nil
@@ -91,7 +102,7 @@ func (d docaTelemetryServiceState) Sync(
 			return errors.Wrap(err, "failed to set controller reference for object")
 		}
 		return nil
-	}, objs)
+	}, objs, cr.Spec.RawPatches)
 	if err != nil {
 		return SyncStateNotReady, errors.Wrap(err, "failed to create/update objects")
 	}
@@ -112,35 +123,63 @@ func (d docaTelemetryServiceState) Sync(
 
 // GetManifestObjects returns the Unstructured objects to deploy for DOCA Telemetry Service.
 func (d docaTelemetryServiceState) GetManifestObjects(
-	_ context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
+	ctx context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
 	_ InfoCatalog, reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
 	if cr == nil || cr.Spec.DOCATelemetryService == nil {
 		return nil, errors.New("failed to render objects: state spec is nil")
 	}
 	dts := cr.Spec.DOCATelemetryService
+	if cr.Spec.Proxy != nil {
+		dts = dts.DeepCopy()
+		dts.Env = addProxyEnvs(dts.Env, cr.Spec.Proxy)
+	}
 
 	configMapName := docaTelemetryServiceDefaultConfigMapName
 	if dts.Config != nil {
 		configMapName = dts.Config.FromConfigMap
 	}
+	providers := docaTelemetryServiceDefaultProviders
+	if len(dts.Providers) > 0 {
+		providers = dts.Providers
+	}
+	prometheusPort := docaTelemetryServiceDefaultPrometheusPort
+	prometheusIgnoreCounters := ""
+	if dts.Prometheus != nil {
+		if dts.Prometheus.Port != 0 {
+			prometheusPort = dts.Prometheus.Port
+		}
+		prometheusIgnoreCounters = strings.Join(dts.Prometheus.IgnoreCounters, ",")
+	}
 	renderData := &DOCATelemetryServiceManifestRenderData{
-		CrSpec:          dts,
-		ConfigMapName:   configMapName,
-		DeployConfigMap: shouldDeployConfigMap(cr.Spec.DOCATelemetryService),
-		Tolerations:     cr.Spec.Tolerations,
-		NodeAffinity:    cr.Spec.NodeAffinity,
+		CrSpec:                   dts,
+		ConfigMapName:            configMapName,
+		DeployConfigMap:          shouldDeployConfigMap(cr.Spec.DOCATelemetryService),
+		Providers:                providers,
+		PrometheusPort:           prometheusPort,
+		PrometheusIgnoreCounters: prometheusIgnoreCounters,
+		Tolerations:              getTolerations(cr.Spec.Tolerations, cr.Spec.DOCATelemetryService.Tolerations),
+		NodeAffinity:             getNodeAffinity(cr.Spec.NodeAffinity, cr.Spec.DOCATelemetryService.NodeAffinity),
+		NodeSelector:             cr.Spec.DOCATelemetryService.NodeSelector,
 		RuntimeSpec: &dtsRuntimeSpec{
 			runtimeSpec:        runtimeSpec{config.FromEnv().State.NetworkOperatorResourceNamespace},
 			ContainerResources: createContainerResourcesMap(cr.Spec.DOCATelemetryService.ContainerResources),
 		},
+		ImagePullSecrets: mellanoxv1alpha1.MergeImagePullSecrets(
+			cr.Spec.ImagePullSecrets, cr.Spec.DOCATelemetryService.ImagePullSecrets),
 	}
 
 	// Render objects related to the DOCATelemetryService
 	reqLogger.V(consts.LogLevelDebug).Info("Rendering objects", "data:", renderData)
-	renderedObjects, err := d.renderer.RenderObjects(&render.TemplatingData{Data: renderData})
+	renderedObjects, err := d.renderer.RenderObjects(ctx, &render.TemplatingData{Data: renderData})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to render objects")
 	}
+	if err := setPriorityClassName(renderedObjects, &cr.Spec, cr.Spec.DOCATelemetryService.PriorityClassName); err != nil {
+		return nil, err
+	}
+	if err := setSecurityContext(renderedObjects, cr.Spec.DOCATelemetryService.SecurityContext); err != nil {
+		return nil, err
+	}
 
 	reqLogger.V(consts.LogLevelDebug).Info("Rendered", "objects:", renderedObjects)
 	return renderedObjects, nil
@@ -157,21 +196,20 @@ func (d docaTelemetryServiceState) Description() string {
 }
 
 // GetWatchSources returns the objects that should be watched to trigger events for the DOCA Telemetry Service state.
-func (docaTelemetryServiceState) GetWatchSources() map[string]client.Object {
-	wr := make(map[string]client.Object)
-	wr["DaemonSet"] = &appsv1.DaemonSet{}
+func (d docaTelemetryServiceState) GetWatchSources() map[string]WatchSource {
+	wr := make(map[string]WatchSource)
+	wr["DaemonSet"] = daemonSetsWatchSource(d.Name())
 	return wr
 }
 
 // NewStateDOCATelemetryService creates a new state for DOCA Telemetry Service.
 func NewStateDOCATelemetryService(
 	c client.Client, manifestDir string) (State, ManifestRenderer, error) {
-	files, err := utils.GetFilesWithSuffix(manifestDir, render.ManifestFileSuffix...)
+	renderer, err := newManifestRenderer(c, manifestDir)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to get files from manifest dir")
 	}
 
-	renderer := render.NewRenderer(files)
 	state := &docaTelemetryServiceState{
 		stateSkel: stateSkel{
 			name:        docaTelemetryServiceName,
@@ -18,10 +18,12 @@ package state_test
 
 import (
 	"context"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -68,6 +70,71 @@ var _ = Describe("DOCATelemetryService Controller", func() {
 
 		}
 	})
+	renderDTS := func(cr *mellanoxv1alpha1.NicClusterPolicy) (*appsv1.DaemonSet, *corev1.ConfigMap) {
+		got, err := s.GetManifestObjects(ctx, cr, getTestCatalog(), log.FromContext(ctx))
+		Expect(err).ToNot(HaveOccurred())
+		ds := &appsv1.DaemonSet{}
+		var cm *corev1.ConfigMap
+		for _, obj := range got {
+			switch obj.GetKind() {
+			case "DaemonSet":
+				Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), ds)).To(Succeed())
+			case "ConfigMap":
+				cm = &corev1.ConfigMap{}
+				Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), cm)).To(Succeed())
+			}
+		}
+		return ds, cm
+	}
+	enabledProviders := func(cm *corev1.ConfigMap) []string {
+		providers := []string{}
+		for _, line := range strings.Split(cm.Data["dts_config_map.ini"], "\n") {
+			if provider, ok := strings.CutPrefix(line, "enable-provider="); ok {
+				providers = append(providers, provider)
+			}
+		}
+		return providers
+	}
+	It("should test the default providers and Prometheus exporter are rendered", func() {
+		ds, cm := renderDTS(cr)
+		Expect(cm).NotTo(BeNil())
+		Expect(enabledProviders(cm)).To(Equal([]string{"sysfs", "pod_resources", "ethtool", "ifconfig"}))
+		Expect(cm.Data["dts_config_map.ini"]).To(ContainSubstring("\nprometheus=http://0.0.0.0:9189\n"))
+		Expect(cm.Data["dts_config_map.ini"]).NotTo(ContainSubstring("\nprometheus-ignore-names="))
+		Expect(ds.Spec.Template.Annotations).To(HaveKeyWithValue("prometheus.io/port", "9189"))
+		Expect(ds.Spec.Template.Spec.Containers[0].Ports).To(ConsistOf(
+			corev1.ContainerPort{Name: "metrics", ContainerPort: 9189}))
+	})
+	It("should test custom providers and Prometheus exporter are rendered", func() {
+		withExporter := cr.DeepCopy()
+		withExporter.Spec.DOCATelemetryService.Providers = []string{"sysfs", "hcaperf"}
+		withExporter.Spec.DOCATelemetryService.Prometheus = &mellanoxv1alpha1.DOCATelemetryServicePrometheusSpec{
+			Port:           9500,
+			IgnoreCounters: []string{"rx_bytes", "tx_bytes"},
+		}
+		ds, cm := renderDTS(withExporter)
+		Expect(cm).NotTo(BeNil())
+		Expect(enabledProviders(cm)).To(Equal([]string{"sysfs", "hcaperf"}))
+		Expect(cm.Data["dts_config_map.ini"]).To(ContainSubstring("\nprometheus=http://0.0.0.0:9500\n"))
+		Expect(cm.Data["dts_config_map.ini"]).To(ContainSubstring("\nprometheus-ignore-names=rx_bytes,tx_bytes\n"))
+		Expect(ds.Spec.Template.Annotations).To(HaveKeyWithValue("prometheus.io/port", "9500"))
+		Expect(ds.Spec.Template.Spec.Containers[0].Ports).To(ConsistOf(
+			corev1.ContainerPort{Name: "metrics", ContainerPort: 9500}))
+	})
+	It("should test the proxy settings are added to the container env", func() {
+		withProxy := cr.DeepCopy()
+		withProxy.Spec.DOCATelemetryService.Env = []corev1.EnvVar{{Name: "no_proxy", Value: "localhost"}}
+		withProxy.Spec.Proxy = &mellanoxv1alpha1.ProxySpec{
+			HTTPSProxy: "http://proxy.example.com:3128", NoProxy: ".cluster.local"}
+		ds, _ := renderDTS(withProxy)
+		Expect(ds.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+			corev1.EnvVar{Name: "no_proxy", Value: "localhost"},
+			corev1.EnvVar{Name: "HTTPS_PROXY", Value: "http://proxy.example.com:3128"},
+			corev1.EnvVar{Name: "https_proxy", Value: "http://proxy.example.com:3128"}))
+		Expect(ds.Spec.Template.Spec.Containers[0].Env).NotTo(ContainElement(
+			corev1.EnvVar{Name: "NO_PROXY", Value: ".cluster.local"}))
+		Expect(withProxy.Spec.DOCATelemetryService.Env).To(HaveLen(1))
+	})
 	It("should test configmap not rendered if nicClusterPolicy `config.fromConfigMap` is set", func() {
 		customConfigMapName := "custom-cm-name"
 		withConfig := cr.DeepCopy()
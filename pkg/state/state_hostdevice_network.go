@@ -32,7 +32,6 @@ import (
 	"github.com/Mellanox/network-operator/pkg/config"
 	"github.com/Mellanox/network-operator/pkg/consts"
 	"github.com/Mellanox/network-operator/pkg/render"
-	"github.com/Mellanox/network-operator/pkg/utils"
 )
 
 const (
@@ -43,12 +42,11 @@ const (
 
 // NewStateHostDeviceNetwork creates a new state for HostDeviceNetwork CR
 func NewStateHostDeviceNetwork(k8sAPIClient client.Client, manifestDir string) (State, error) {
-	files, err := utils.GetFilesWithSuffix(manifestDir, render.ManifestFileSuffix...)
+	renderer, err := newManifestRenderer(k8sAPIClient, manifestDir)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get files from manifest dir")
 	}
 
-	renderer := render.NewRenderer(files)
 	return &stateHostDeviceNetwork{
 		stateSkel: stateSkel{
 			name:        stateHostDeviceNetworkName,
@@ -68,6 +66,10 @@ type HostDeviceManifestRenderData struct {
 	CrSpec                mellanoxv1alpha1.HostDeviceNetworkSpec
 	RuntimeSpec           *runtimeSpec
 	ResourceName          string
+	// Ipam is the "ipam" entry of the CNI config
+	Ipam string
+	// DNS is the "dns" entry of the CNI config followed by a comma, empty if the network has no DNS config
+	DNS string
 }
 
 // Sync attempt to get the system to match the desired state which State represent.
@@ -79,9 +81,9 @@ func (s *stateHostDeviceNetwork) Sync(
 	reqLogger.V(consts.LogLevelInfo).Info(
 		"Sync Custom resource", "State:", s.name, "Name:", cr.Name, "Namespace:", cr.Namespace)
 
-	objs, err := s.getManifestObjects(cr, reqLogger)
+	objs, err := s.getManifestObjects(ctx, cr, reqLogger)
 	if err != nil {
-		return SyncStateError, errors.Wrap(err, "failed to render HostDeviceNetwork")
+		return SyncStateError, &RenderError{Err: errors.Wrap(err, "failed to render HostDeviceNetwork")}
 	}
 
 	if len(objs) == 0 {
@@ -98,7 +100,7 @@ func (s *stateHostDeviceNetwork) Sync(
 			return errors.Wrap(err, "failed to set controller reference for object")
 		}
 		return nil
-	}, objs)
+	}, objs, nil)
 
 	if err != nil {
 		return SyncStateNotReady, errors.Wrap(err, "failed to create/update objects")
@@ -118,20 +120,29 @@ func (s *stateHostDeviceNetwork) Sync(
 }
 
 // Get a map of source kinds that should be watched for the state keyed by the source kind name
-func (s *stateHostDeviceNetwork) GetWatchSources() map[string]client.Object {
-	wr := make(map[string]client.Object)
-	wr["HostDeviceNetwork"] = &mellanoxv1alpha1.HostDeviceNetwork{}
-	wr["NetworkAttachmentDefinition"] = &netattdefv1.NetworkAttachmentDefinition{}
+func (s *stateHostDeviceNetwork) GetWatchSources() map[string]WatchSource {
+	wr := make(map[string]WatchSource)
+	wr["HostDeviceNetwork"] = specChangesWatchSource(&mellanoxv1alpha1.HostDeviceNetwork{})
+	wr["NetworkAttachmentDefinition"] = stateObjectsWatchSource(&netattdefv1.NetworkAttachmentDefinition{}, s.name)
 	return wr
 }
 
 func (s *stateHostDeviceNetwork) getManifestObjects(
-	cr *mellanoxv1alpha1.HostDeviceNetwork, reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
+	ctx context.Context, cr *mellanoxv1alpha1.HostDeviceNetwork,
+	reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
 	resourceName := cr.Spec.ResourceName
 	if !strings.HasPrefix(resourceName, resourceNamePrefix) {
 		resourceName = resourceNamePrefix + resourceName
 	}
 
+	ipam, err := getNetworkIPAMConfig(cr.Spec.IPAM, cr.Spec.StaticIPAM)
+	if err != nil {
+		return nil, err
+	}
+	dns, err := getNetworkDNSConfig(cr.Spec.DNS)
+	if err != nil {
+		return nil, err
+	}
 	renderData := &HostDeviceManifestRenderData{
 		HostDeviceNetworkName: cr.Name,
 		CrSpec:                cr.Spec,
@@ -139,11 +150,13 @@ func (s *stateHostDeviceNetwork) getManifestObjects(
 			Namespace: config.FromEnv().State.NetworkOperatorResourceNamespace,
 		},
 		ResourceName: resourceName,
+		Ipam:         ipam,
+		DNS:          dns,
 	}
 
 	// render objects
 	reqLogger.V(consts.LogLevelDebug).Info("Rendering objects", "data:", renderData)
-	objs, err := s.renderer.RenderObjects(&render.TemplatingData{Data: renderData})
+	objs, err := s.renderer.RenderObjects(ctx, &render.TemplatingData{Data: renderData})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to render objects")
 	}
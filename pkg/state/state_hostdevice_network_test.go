@@ -134,6 +134,25 @@ var _ = Describe("HostDevice Network State rendering tests", func() {
 			expectedNad := getExpectedHostDeviceNetNAD(testName, ipam)
 			Expect(nad.Spec).To(BeEquivalentTo(expectedNad.Spec))
 		})
+		It("Should Render NetworkAttachmentDefinition with RDMA isolation", func() {
+			testName := "host-device"
+			testResourceName := "test"
+			cr := getHostDeviceNetwork(testName, testResourceName)
+			cr.Spec.RdmaIsolation = true
+			err := client.Create(context.Background(), cr)
+			Expect(err).NotTo(HaveOccurred())
+			status, err := hostDeviceNetState.Sync(context.Background(), cr, catalog)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(BeEquivalentTo(state.SyncStateReady))
+
+			By("Verify NetworkAttachmentDefinition")
+			nad := &netattdefv1.NetworkAttachmentDefinition{}
+			err = client.Get(context.Background(), types.NamespacedName{Namespace: testNamespace, Name: testName}, nad)
+			Expect(err).NotTo(HaveOccurred())
+			expectedConfig := fmt.Sprintf("{ \"cniVersion\":\"0.3.1\", \"name\":%q, \"plugins\":[{ "+
+				"\"type\":\"host-device\", \"ipam\":{} },{ \"type\":\"rdma\" }] }", testName)
+			Expect(nad.Spec.Config).To(Equal(expectedConfig))
+		})
 	})
 })
 
@@ -19,7 +19,6 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
-	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -30,18 +29,16 @@ import (
 	"github.com/Mellanox/network-operator/pkg/config"
 	"github.com/Mellanox/network-operator/pkg/consts"
 	"github.com/Mellanox/network-operator/pkg/render"
-	"github.com/Mellanox/network-operator/pkg/utils"
 )
 
 // NewStateIBKubernetes creates a new ib-kubernetes state
 func NewStateIBKubernetes(
 	k8sAPIClient client.Client, manifestDir string) (State, ManifestRenderer, error) {
-	files, err := utils.GetFilesWithSuffix(manifestDir, render.ManifestFileSuffix...)
+	renderer, err := newManifestRenderer(k8sAPIClient, manifestDir)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to get files from manifest dir")
 	}
 
-	renderer := render.NewRenderer(files)
 	state := &stateIBKubernetes{
 		stateSkel: stateSkel{
 			name:        "state-ib-kubernetes",
@@ -56,6 +53,9 @@ type stateIBKubernetes struct {
 	stateSkel
 }
 
+// defaultIBKubernetesGCIntervalSeconds is the interval of the garbage collection of ib-kubernetes if not set
+const defaultIBKubernetesGCIntervalSeconds = 300
+
 // IBKubernetesSpec holds additional information for rendering Kubernetes objects related to Infiniband.
 type IBKubernetesSpec struct {
 	runtimeSpec
@@ -68,10 +68,13 @@ type IBKubernetesSpec struct {
 type IBKubernetesManifestRenderData struct {
 	CrSpec                      *mellanoxv1alpha1.IBKubernetesSpec
 	PeriodicUpdateSecondsString string
-	Tolerations                 []v1.Toleration
-	NodeAffinity                *v1.NodeAffinity
-	DeployInitContainer         bool
-	RuntimeSpec                 *IBKubernetesSpec
+	// GarbageCollectionIntervalString is the interval of the garbage collection of GUIDs and PKeys if enabled
+	GarbageCollectionIntervalString string
+	Tolerations                     []v1.Toleration
+	ImagePullSecrets                []string
+	NodeAffinity                    *v1.NodeAffinity
+	DeployInitContainer             bool
+	RuntimeSpec                     *IBKubernetesSpec
 }
 
 // Sync attempt to get the system to match the desired state which State represent.
@@ -98,7 +101,7 @@ func (s *stateIBKubernetes) Sync(
 
 	objs, err := s.GetManifestObjects(ctx, cr, infoCatalog, reqLogger)
 	if err != nil {
-		return SyncStateNotReady, errors.Wrap(err, "failed to create k8s objects from manifest")
+		return SyncStateNotReady, &RenderError{Err: errors.Wrap(err, "failed to create k8s objects from manifest")}
 	}
 	if len(objs) == 0 {
 		return SyncStateNotReady, nil
@@ -110,7 +113,7 @@ func (s *stateIBKubernetes) Sync(
 			return errors.Wrap(err, "failed to set controller reference for object")
 		}
 		return nil
-	}, objs)
+	}, objs, cr.Spec.RawPatches)
 	if err != nil {
 		return SyncStateNotReady, errors.Wrap(err, "failed to create/update objects")
 	}
@@ -130,14 +133,14 @@ func (s *stateIBKubernetes) Sync(
 }
 
 // GetWatchSources returns a map of source kinds that should be watched for the state keyed by the source kind name.
-func (s *stateIBKubernetes) GetWatchSources() map[string]client.Object {
-	wr := make(map[string]client.Object)
-	wr["Deployment"] = &appsv1.Deployment{}
+func (s *stateIBKubernetes) GetWatchSources() map[string]WatchSource {
+	wr := make(map[string]WatchSource)
+	wr["Deployment"] = deploymentsWatchSource(s.name)
 	return wr
 }
 
 func (s *stateIBKubernetes) GetManifestObjects(
-	_ context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
+	ctx context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
 	catalog InfoCatalog, reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
 	if cr == nil || cr.Spec.IBKubernetes == nil {
 		return nil, errors.New("failed to render objects: state spec is nil")
@@ -158,13 +161,25 @@ func (s *stateIBKubernetes) GetManifestObjects(
 			IsOpenshift:        clusterInfo.IsOpenshift(),
 			ContainerResources: createContainerResourcesMap(cr.Spec.IBKubernetes.ContainerResources),
 		},
+		ImagePullSecrets: mellanoxv1alpha1.MergeImagePullSecrets(
+			cr.Spec.ImagePullSecrets, cr.Spec.IBKubernetes.ImagePullSecrets),
+	}
+	if gc := cr.Spec.IBKubernetes.GarbageCollection; gc != nil {
+		interval := gc.IntervalSeconds
+		if interval == 0 {
+			interval = defaultIBKubernetesGCIntervalSeconds
+		}
+		renderData.GarbageCollectionIntervalString = strconv.Itoa(interval)
 	}
 	// render objects
 	reqLogger.V(consts.LogLevelDebug).Info("Rendering objects", "data:", renderData)
-	objs, err := s.renderer.RenderObjects(&render.TemplatingData{Data: renderData})
+	objs, err := s.renderer.RenderObjects(ctx, &render.TemplatingData{Data: renderData})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to render objects")
 	}
+	if err := setPriorityClassName(objs, &cr.Spec, cr.Spec.IBKubernetes.PriorityClassName); err != nil {
+		return nil, err
+	}
 	reqLogger.V(consts.LogLevelDebug).Info("Rendered", "objects:", objs)
 	return objs, nil
 }
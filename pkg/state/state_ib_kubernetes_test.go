@@ -20,8 +20,11 @@ import (
 	"context"
 	"strconv"
 
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -241,5 +244,129 @@ var _ = Describe("IB Kubernetes state rendering tests", func() {
 			limits := resources["limits"]
 			Expect(limits).To(BeNil())
 		})
+		It("Should Render UFM TLS configuration", func() {
+			manifestBaseDir := "../../manifests/state-ib-kubernetes"
+
+			files, err := utils.GetFilesWithSuffix(manifestBaseDir, render.ManifestFileSuffix...)
+			Expect(err).NotTo(HaveOccurred())
+			ibKubernetesState := stateIBKubernetes{
+				stateSkel: stateSkel{
+					renderer: render.NewRenderer(files),
+				},
+			}
+
+			ibKubernetesSpec := &mellanoxv1alpha1.IBKubernetesSpec{}
+			ibKubernetesSpec.Image = "image"
+			ibKubernetesSpec.ImagePullSecrets = []string{}
+			ibKubernetesSpec.Version = "version"
+			ibKubernetesSpec.UfmSecret = "ufm-secret"
+			ibKubernetesSpec.UfmTLS = &mellanoxv1alpha1.UFMTLSSpec{
+				CertificateSecret: "ufm-client-tls",
+				CABundle:          "ufm-ca",
+				VerificationMode:  mellanoxv1alpha1.UFMTLSVerificationModeVerify,
+			}
+			cr := &mellanoxv1alpha1.NicClusterPolicy{}
+			cr.Spec.IBKubernetes = ibKubernetesSpec
+
+			catalog := NewInfoCatalog()
+			catalog.Add(InfoTypeClusterType, &dummyProvider{})
+
+			objs, err := ibKubernetesState.GetManifestObjects(context.TODO(), cr, catalog, testLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(objs)).To(Equal(4))
+			deployment := &appsv1.Deployment{}
+			Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(objs[3].Object, deployment)).To(Succeed())
+			podSpec := deployment.Spec.Template.Spec
+			env := podSpec.Containers[0].Env
+			Expect(env).To(ContainElements(
+				v1.EnvVar{Name: "UFM_HTTP_SCHEMA", Value: "https"},
+				v1.EnvVar{Name: "UFM_CERTIFICATE", ValueFrom: &v1.EnvVarSource{
+					ConfigMapKeyRef: &v1.ConfigMapKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{Name: "ufm-ca"}, Key: "ca.crt"}}},
+				v1.EnvVar{Name: "UFM_CLIENT_CERTIFICATE", Value: "/etc/ib-kubernetes/ufm-tls/tls.crt"},
+				v1.EnvVar{Name: "UFM_CLIENT_KEY", Value: "/etc/ib-kubernetes/ufm-tls/tls.key"}))
+			Expect(env).NotTo(ContainElement(HaveField("Name", "UFM_INSECURE_SKIP_VERIFY")))
+			Expect(podSpec.Volumes).To(HaveLen(1))
+			Expect(podSpec.Volumes[0].Secret.SecretName).To(Equal("ufm-client-tls"))
+			Expect(podSpec.Containers[0].VolumeMounts).To(HaveLen(1))
+		})
+		It("Should Render UFM TLS without verification", func() {
+			manifestBaseDir := "../../manifests/state-ib-kubernetes"
+
+			files, err := utils.GetFilesWithSuffix(manifestBaseDir, render.ManifestFileSuffix...)
+			Expect(err).NotTo(HaveOccurred())
+			ibKubernetesState := stateIBKubernetes{
+				stateSkel: stateSkel{
+					renderer: render.NewRenderer(files),
+				},
+			}
+
+			ibKubernetesSpec := &mellanoxv1alpha1.IBKubernetesSpec{}
+			ibKubernetesSpec.Image = "image"
+			ibKubernetesSpec.ImagePullSecrets = []string{}
+			ibKubernetesSpec.Version = "version"
+			ibKubernetesSpec.UfmSecret = "ufm-secret"
+			ibKubernetesSpec.UfmTLS = &mellanoxv1alpha1.UFMTLSSpec{
+				VerificationMode: mellanoxv1alpha1.UFMTLSVerificationModeSkipVerify,
+			}
+			cr := &mellanoxv1alpha1.NicClusterPolicy{}
+			cr.Spec.IBKubernetes = ibKubernetesSpec
+
+			catalog := NewInfoCatalog()
+			catalog.Add(InfoTypeClusterType, &dummyProvider{})
+
+			objs, err := ibKubernetesState.GetManifestObjects(context.TODO(), cr, catalog, testLogger)
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &appsv1.Deployment{}
+			Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(objs[3].Object, deployment)).To(Succeed())
+			podSpec := deployment.Spec.Template.Spec
+			Expect(podSpec.Containers[0].Env).To(ContainElements(
+				v1.EnvVar{Name: "UFM_HTTP_SCHEMA", Value: "https"},
+				v1.EnvVar{Name: "UFM_INSECURE_SKIP_VERIFY", Value: "true"}))
+			Expect(podSpec.Volumes).To(BeEmpty())
+			Expect(podSpec.Containers[0].VolumeMounts).To(BeEmpty())
+		})
+		It("Should Render garbage collection", func() {
+			manifestBaseDir := "../../manifests/state-ib-kubernetes"
+
+			files, err := utils.GetFilesWithSuffix(manifestBaseDir, render.ManifestFileSuffix...)
+			Expect(err).NotTo(HaveOccurred())
+			ibKubernetesState := stateIBKubernetes{
+				stateSkel: stateSkel{
+					renderer: render.NewRenderer(files),
+				},
+			}
+
+			ibKubernetesSpec := &mellanoxv1alpha1.IBKubernetesSpec{}
+			ibKubernetesSpec.Image = "image"
+			ibKubernetesSpec.ImagePullSecrets = []string{}
+			ibKubernetesSpec.Version = "version"
+			ibKubernetesSpec.GarbageCollection = &mellanoxv1alpha1.IBKubernetesGarbageCollectionSpec{}
+			cr := &mellanoxv1alpha1.NicClusterPolicy{}
+			cr.Spec.IBKubernetes = ibKubernetesSpec
+
+			catalog := NewInfoCatalog()
+			catalog.Add(InfoTypeClusterType, &dummyProvider{})
+
+			objs, err := ibKubernetesState.GetManifestObjects(context.TODO(), cr, catalog, testLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(objs)).To(Equal(4))
+			Expect(objs[1].GetKind()).To(Equal("ClusterRole"))
+			rules, _, err := unstructured.NestedSlice(objs[1].Object, "rules")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rules).To(HaveLen(4))
+			deployment := &appsv1.Deployment{}
+			Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(objs[3].Object, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+				v1.EnvVar{Name: "DAEMON_GC_ENABLED", Value: "true"},
+				v1.EnvVar{Name: "DAEMON_GC_INTERVAL", Value: "300"}))
+
+			ibKubernetesSpec.GarbageCollection.IntervalSeconds = 60
+			objs, err = ibKubernetesState.GetManifestObjects(context.TODO(), cr, catalog, testLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(objs[3].Object, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(ContainElement(
+				v1.EnvVar{Name: "DAEMON_GC_INTERVAL", Value: "60"}))
+		})
 	})
 })
@@ -57,11 +57,11 @@ var _ = Describe("IPoIB CNI State tests", func() {
 
 			cr := &mellanoxv1alpha1.NicClusterPolicy{}
 
-			imageSpec := &mellanoxv1alpha1.ImageSpec{
+			imageSpec := &mellanoxv1alpha1.CNIPluginSpec{ImageSpec: mellanoxv1alpha1.ImageSpec{
 				Image:      "image",
 				Repository: "Repository",
 				Version:    "v0.0",
-			}
+			}}
 			netSpec := &mellanoxv1alpha1.SecondaryNetworkSpec{}
 			netSpec.IPoIB = imageSpec
 			cr.Spec.SecondaryNetwork = netSpec
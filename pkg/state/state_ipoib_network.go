@@ -18,7 +18,7 @@ package state //nolint:dupl
 
 import (
 	"context"
-	"strings"
+	"encoding/json"
 
 	"github.com/go-logr/logr"
 	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
@@ -33,7 +33,6 @@ import (
 	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
 	"github.com/Mellanox/network-operator/pkg/consts"
 	"github.com/Mellanox/network-operator/pkg/render"
-	"github.com/Mellanox/network-operator/pkg/utils"
 )
 
 const (
@@ -44,12 +43,11 @@ const (
 
 // NewStateIPoIBNetwork creates a new state for IPoIBNetwork CR
 func NewStateIPoIBNetwork(k8sAPIClient client.Client, manifestDir string) (State, error) {
-	files, err := utils.GetFilesWithSuffix(manifestDir, render.ManifestFileSuffix...)
+	renderer, err := newManifestRenderer(k8sAPIClient, manifestDir)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get files from manifest dir")
 	}
 
-	renderer := render.NewRenderer(files)
 	return &stateIPoIBNetwork{
 		stateSkel: stateSkel{
 			name:        stateIPoIBNetworkName,
@@ -73,9 +71,9 @@ func (s *stateIPoIBNetwork) Sync(ctx context.Context, customResource interface{}
 	reqLogger.V(consts.LogLevelInfo).Info(
 		"Sync Custom resource", "State:", s.name, "Name:", cr.Name, "Namespace:", cr.Namespace)
 
-	objs, err := s.getManifestObjects(cr, reqLogger)
+	objs, err := s.getManifestObjects(ctx, cr, reqLogger)
 	if err != nil {
-		return SyncStateError, errors.Wrap(err, "failed to render IPoIBNetwork")
+		return SyncStateError, &RenderError{Err: errors.Wrap(err, "failed to render IPoIBNetwork")}
 	}
 
 	if len(objs) == 0 {
@@ -97,7 +95,7 @@ func (s *stateIPoIBNetwork) Sync(ctx context.Context, customResource interface{}
 			return errors.Wrap(err, "failed to set controller reference for object")
 		}
 		return nil
-	}, objs)
+	}, objs, nil)
 
 	if err != nil {
 		return SyncStateNotReady, errors.Wrap(err, "failed to create/update objects")
@@ -120,15 +118,16 @@ func (s *stateIPoIBNetwork) Sync(ctx context.Context, customResource interface{}
 }
 
 // GetWatchSources returns a map of source kinds that should be watched for the state keyed by the source kind name
-func (s *stateIPoIBNetwork) GetWatchSources() map[string]client.Object {
-	wr := make(map[string]client.Object)
-	wr["IPoIBNetwork"] = &mellanoxv1alpha1.IPoIBNetwork{}
-	wr["NetworkAttachmentDefinition"] = &netattdefv1.NetworkAttachmentDefinition{}
+func (s *stateIPoIBNetwork) GetWatchSources() map[string]WatchSource {
+	wr := make(map[string]WatchSource)
+	wr["IPoIBNetwork"] = specChangesWatchSource(&mellanoxv1alpha1.IPoIBNetwork{})
+	wr["NetworkAttachmentDefinition"] = stateObjectsWatchSource(&netattdefv1.NetworkAttachmentDefinition{}, s.name)
 	return wr
 }
 
 func (s *stateIPoIBNetwork) getManifestObjects(
-	cr *mellanoxv1alpha1.IPoIBNetwork, reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
+	ctx context.Context, cr *mellanoxv1alpha1.IPoIBNetwork,
+	reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
 	data := map[string]interface{}{}
 	data["NetworkName"] = cr.Name
 	if cr.Spec.NetworkNamespace == "" {
@@ -138,16 +137,30 @@ func (s *stateIPoIBNetwork) getManifestObjects(
 	}
 
 	data["Master"] = cr.Spec.Master
+	data["Bond"] = ""
+	if cr.Spec.Bond != nil {
+		bond, err := getIPoIBBondConfig(cr.Spec.Bond)
+		if err != nil {
+			return nil, err
+		}
+		data["Master"] = cr.Spec.Bond.Name
+		data["Bond"] = bond
+	}
 
-	if cr.Spec.IPAM != "" {
-		data["Ipam"] = "\"ipam\":" + strings.Join(strings.Fields(cr.Spec.IPAM), "")
-	} else {
-		data["Ipam"] = "\"ipam\":{}"
+	ipam, err := getNetworkIPAMConfig(cr.Spec.IPAM, cr.Spec.StaticIPAM)
+	if err != nil {
+		return nil, err
+	}
+	data["Ipam"] = ipam
+	dns, err := getNetworkDNSConfig(cr.Spec.DNS)
+	if err != nil {
+		return nil, err
 	}
+	data["DNS"] = dns
 
 	// render objects
 	reqLogger.V(consts.LogLevelDebug).Info("Rendering objects", "data:", data)
-	objs, err := s.renderer.RenderObjects(&render.TemplatingData{Data: data})
+	objs, err := s.renderer.RenderObjects(ctx, &render.TemplatingData{Data: data})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to render objects")
 	}
@@ -188,3 +201,21 @@ func (s *stateIPoIBNetwork) updateNetAttDefNamespace(ctx context.Context, cr *me
 	}
 	return nil
 }
+
+// getIPoIBBondConfig returns the bond section of the ipoib CNI config
+func getIPoIBBondConfig(bond *mellanoxv1alpha1.IPoIBBondSpec) (string, error) {
+	mode := bond.Mode
+	if mode == "" {
+		mode = mellanoxv1alpha1.IPoIBBondModeActiveBackup
+	}
+	config, err := json.Marshal(map[string]interface{}{
+		"name":   bond.Name,
+		"mode":   mode,
+		"miimon": bond.Miimon,
+		"links":  bond.Links,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to render IPoIB bond config")
+	}
+	return string(config), nil
+}
@@ -99,6 +99,28 @@ var _ = Describe("IPoIBNetwork Network state rendering tests", func() {
 			expectedNad := getExpectedIPoIBNAD(name, ipam)
 			Expect(nad.Spec).To(BeEquivalentTo(expectedNad.Spec))
 		})
+		It("Should Render NetworkAttachmentDefinition with bond", func() {
+			name := "ipoib"
+			cr := getIPoIBNetwork()
+			cr.Spec.Master = ""
+			cr.Spec.Bond = &mellanoxv1alpha1.IPoIBBondSpec{Name: "bond0", Links: []string{"ib0", "ib1"}, Miimon: 100}
+			err := client.Create(context.Background(), cr)
+			Expect(err).NotTo(HaveOccurred())
+			status, err := ipoibState.Sync(context.Background(), cr, catalog)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(BeEquivalentTo(state.SyncStateReady))
+
+			By("Verify NetworkAttachmentDefinition")
+			nad := &netattdefv1.NetworkAttachmentDefinition{}
+			err = client.Get(context.Background(), types.NamespacedName{Namespace: testNamespace, Name: name}, nad)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = getNADConfig(nad.Spec.Config)
+			Expect(err).NotTo(HaveOccurred())
+			cfg := fmt.Sprintf("{ \"cniVersion\":\"0.3.1\", \"name\":%q, \"type\":\"ipoib\", "+
+				"\"master\": \"bond0\", \"bond\": {\"links\":[\"ib0\",\"ib1\"],\"miimon\":100,"+
+				"\"mode\":\"active-backup\",\"name\":\"bond0\"}, \"ipam\":{} }", name)
+			Expect(nad.Spec.Config).To(Equal(cfg))
+		})
 		It("Should Render NetworkAttachmentDefinition with default namespace", func() {
 			name := "ipoib"
 			cr := getIPoIBNetwork()
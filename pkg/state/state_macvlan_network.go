@@ -18,7 +18,6 @@ package state //nolint:dupl
 
 import (
 	"context"
-	"strings"
 
 	"github.com/go-logr/logr"
 	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
@@ -33,7 +32,6 @@ import (
 	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
 	"github.com/Mellanox/network-operator/pkg/consts"
 	"github.com/Mellanox/network-operator/pkg/render"
-	"github.com/Mellanox/network-operator/pkg/utils"
 )
 
 const (
@@ -44,12 +42,11 @@ const (
 
 // NewStateMacvlanNetwork creates a new state for MacvlanNetwork CR
 func NewStateMacvlanNetwork(k8sAPIClient client.Client, manifestDir string) (State, error) {
-	files, err := utils.GetFilesWithSuffix(manifestDir, render.ManifestFileSuffix...)
+	renderer, err := newManifestRenderer(k8sAPIClient, manifestDir)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get files from manifest dir")
 	}
 
-	renderer := render.NewRenderer(files)
 	return &stateMacvlanNetwork{
 		stateSkel: stateSkel{
 			name:        stateMacvlanNetworkName,
@@ -73,9 +70,9 @@ func (s *stateMacvlanNetwork) Sync(ctx context.Context, customResource interface
 	reqLogger.V(consts.LogLevelInfo).Info(
 		"Sync Custom resource", "State:", s.name, "Name:", cr.Name, "Namespace:", cr.Namespace)
 
-	objs, err := s.getManifestObjects(cr, reqLogger)
+	objs, err := s.getManifestObjects(ctx, cr, reqLogger)
 	if err != nil {
-		return SyncStateError, errors.Wrap(err, "failed to render MacvlanNetwork")
+		return SyncStateError, &RenderError{Err: errors.Wrap(err, "failed to render MacvlanNetwork")}
 	}
 
 	if len(objs) == 0 {
@@ -97,7 +94,7 @@ func (s *stateMacvlanNetwork) Sync(ctx context.Context, customResource interface
 			return errors.Wrap(err, "failed to set controller reference for object")
 		}
 		return nil
-	}, objs)
+	}, objs, nil)
 
 	if err != nil {
 		return SyncStateNotReady, errors.Wrap(err, "failed to create/update objects")
@@ -120,15 +117,16 @@ func (s *stateMacvlanNetwork) Sync(ctx context.Context, customResource interface
 }
 
 // Get a map of source kinds that should be watched for the state keyed by the source kind name
-func (s *stateMacvlanNetwork) GetWatchSources() map[string]client.Object {
-	wr := make(map[string]client.Object)
-	wr["MacvlanNetwork"] = &mellanoxv1alpha1.MacvlanNetwork{}
-	wr["NetworkAttachmentDefinition"] = &netattdefv1.NetworkAttachmentDefinition{}
+func (s *stateMacvlanNetwork) GetWatchSources() map[string]WatchSource {
+	wr := make(map[string]WatchSource)
+	wr["MacvlanNetwork"] = specChangesWatchSource(&mellanoxv1alpha1.MacvlanNetwork{})
+	wr["NetworkAttachmentDefinition"] = stateObjectsWatchSource(&netattdefv1.NetworkAttachmentDefinition{}, s.name)
 	return wr
 }
 
 func (s *stateMacvlanNetwork) getManifestObjects(
-	cr *mellanoxv1alpha1.MacvlanNetwork, reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
+	ctx context.Context, cr *mellanoxv1alpha1.MacvlanNetwork,
+	reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
 	data := map[string]interface{}{}
 	data["NetworkName"] = cr.Name
 	if cr.Spec.NetworkNamespace == "" {
@@ -141,15 +139,20 @@ func (s *stateMacvlanNetwork) getManifestObjects(
 	data["Mode"] = cr.Spec.Mode
 	data["Mtu"] = cr.Spec.Mtu
 
-	if cr.Spec.IPAM != "" {
-		data["Ipam"] = "\"ipam\":" + strings.Join(strings.Fields(cr.Spec.IPAM), "")
-	} else {
-		data["Ipam"] = "\"ipam\":{}"
+	ipam, err := getNetworkIPAMConfig(cr.Spec.IPAM, cr.Spec.StaticIPAM)
+	if err != nil {
+		return nil, err
+	}
+	data["Ipam"] = ipam
+	dns, err := getNetworkDNSConfig(cr.Spec.DNS)
+	if err != nil {
+		return nil, err
 	}
+	data["DNS"] = dns
 
 	// render objects
 	reqLogger.V(consts.LogLevelDebug).Info("Rendering objects", "data:", data)
-	objs, err := s.renderer.RenderObjects(&render.TemplatingData{Data: data})
+	objs, err := s.renderer.RenderObjects(ctx, &render.TemplatingData{Data: data})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to render objects")
 	}
@@ -19,6 +19,7 @@ package state_test
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -101,6 +102,36 @@ var _ = Describe("MacVlan Network State rendering tests", func() {
 			expectedNad := getExpectedNAD(ipam)
 			Expect(nad.Spec).To(BeEquivalentTo(expectedNad.Spec))
 		})
+		It("Should Render NetworkAttachmentDefinition with static IPAM and DNS", func() {
+			cr := getMacvlanNetwork()
+			cr.Spec.StaticIPAM = &mellanoxv1alpha1.StaticIPAMSpec{
+				Ranges: []mellanoxv1alpha1.IPRangeSpec{
+					{Subnet: "192.168.2.0/24", RangeStart: "192.168.2.10", RangeEnd: "192.168.2.100"},
+					{Subnet: "fd00::/64"},
+				},
+				Gateway: "192.168.2.1",
+				Routes:  []mellanoxv1alpha1.RouteSpec{{Dst: "10.0.0.0/8", GW: "192.168.2.254"}},
+			}
+			cr.Spec.DNS = &mellanoxv1alpha1.DNSSpec{Nameservers: []string{"192.168.2.53"}, Search: []string{"example.com"}}
+			err := client.Create(context.Background(), cr)
+			Expect(err).NotTo(HaveOccurred())
+			status, err := macvlanState.Sync(context.Background(), cr, catalog)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(BeEquivalentTo(state.SyncStateReady))
+
+			By("Verify NetworkAttachmentDefinition")
+			nad := &netattdefv1.NetworkAttachmentDefinition{}
+			err = client.Get(context.Background(), types.NamespacedName{Namespace: testNamespace, Name: testName}, nad)
+			Expect(err).NotTo(HaveOccurred())
+			ipam := "{\"type\":\"whereabouts\",\"ipRanges\":[" +
+				"{\"range\":\"192.168.2.0/24\",\"range_start\":\"192.168.2.10\",\"range_end\":\"192.168.2.100\"}," +
+				"{\"range\":\"fd00::/64\"}],\"gateway\":\"192.168.2.1\"," +
+				"\"routes\":[{\"dst\":\"10.0.0.0/8\",\"gw\":\"192.168.2.254\"}]}"
+			expectedNad := getExpectedNAD(ipam)
+			expectedNad.Spec.Config = strings.Replace(expectedNad.Spec.Config, "\"ipam\"",
+				"\"dns\":{\"nameservers\":[\"192.168.2.53\"],\"search\":[\"example.com\"]},\"ipam\"", 1)
+			Expect(nad.Spec).To(BeEquivalentTo(expectedNad.Spec))
+		})
 		It("Should Render NetworkAttachmentDefinition with default namespace", func() {
 			cr := getMacvlanNetwork()
 			cr.Spec.NetworkNamespace = ""
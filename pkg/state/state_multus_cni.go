@@ -21,7 +21,6 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
-	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -38,12 +37,11 @@ import (
 // NewStateMultusCNI creates a new state for Multus
 func NewStateMultusCNI(
 	k8sAPIClient client.Client, manifestDir string) (State, ManifestRenderer, error) {
-	files, err := utils.GetFilesWithSuffix(manifestDir, render.ManifestFileSuffix...)
+	renderer, err := newManifestRenderer(k8sAPIClient, manifestDir)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to get files from manifest dir")
 	}
 
-	renderer := render.NewRenderer(files)
 	state := &stateMultusCNI{
 		stateSkel: stateSkel{
 			name:        "state-multus-cni",
@@ -60,10 +58,12 @@ type stateMultusCNI struct {
 
 // MultusManifestRenderData contains information used to render Kubernetes objects related to Multus.
 type MultusManifestRenderData struct {
-	CrSpec       *mellanoxv1alpha1.MultusSpec
-	Tolerations  []v1.Toleration
-	NodeAffinity *v1.NodeAffinity
-	RuntimeSpec  *cniRuntimeSpec
+	CrSpec           *mellanoxv1alpha1.MultusSpec
+	Tolerations      []v1.Toleration
+	ImagePullSecrets []string
+	NodeAffinity     *v1.NodeAffinity
+	NodeSelector     map[string]string
+	RuntimeSpec      *cniRuntimeSpec
 }
 
 // Sync attempt to get the system to match the desired state which State represent.
@@ -90,7 +90,7 @@ func (s *stateMultusCNI) Sync(
 
 	objs, err := s.GetManifestObjects(ctx, cr, infoCatalog, reqLogger)
 	if err != nil {
-		return SyncStateNotReady, errors.Wrap(err, "failed to create k8s objects from manifest")
+		return SyncStateNotReady, &RenderError{Err: errors.Wrap(err, "failed to create k8s objects from manifest")}
 	}
 	if len(objs) == 0 {
 		return SyncStateNotReady, nil
@@ -102,7 +102,7 @@ func (s *stateMultusCNI) Sync(
 			return errors.Wrap(err, "failed to set controller reference for object")
 		}
 		return nil
-	}, objs)
+	}, objs, cr.Spec.RawPatches)
 	if err != nil {
 		return SyncStateNotReady, errors.Wrap(err, "failed to create/update objects")
 	}
@@ -122,16 +122,16 @@ func (s *stateMultusCNI) Sync(
 }
 
 // Get a map of source kinds that should be watched for the state keyed by the source kind name
-func (s *stateMultusCNI) GetWatchSources() map[string]client.Object {
-	wr := make(map[string]client.Object)
-	wr["DaemonSet"] = &appsv1.DaemonSet{}
-	wr["ConfigMap"] = &v1.ConfigMap{}
+func (s *stateMultusCNI) GetWatchSources() map[string]WatchSource {
+	wr := make(map[string]WatchSource)
+	wr["DaemonSet"] = daemonSetsWatchSource(s.name)
+	wr["ConfigMap"] = stateObjectsWatchSource(&v1.ConfigMap{}, s.name)
 	return wr
 }
 
 //nolint:dupl
 func (s *stateMultusCNI) GetManifestObjects(
-	_ context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
+	ctx context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
 	catalog InfoCatalog, reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
 	if cr == nil || cr.Spec.SecondaryNetwork == nil || cr.Spec.SecondaryNetwork.Multus == nil {
 		return nil, errors.New("failed to render objects: state spec is nil")
@@ -147,22 +147,32 @@ func (s *stateMultusCNI) GetManifestObjects(
 	}
 	renderData := &MultusManifestRenderData{
 		CrSpec:       cr.Spec.SecondaryNetwork.Multus,
-		Tolerations:  cr.Spec.Tolerations,
-		NodeAffinity: cr.Spec.NodeAffinity,
+		Tolerations:  getTolerations(cr.Spec.Tolerations, cr.Spec.SecondaryNetwork.Multus.Tolerations),
+		NodeAffinity: getNodeAffinity(cr.Spec.NodeAffinity, cr.Spec.SecondaryNetwork.Multus.NodeAffinity),
+		NodeSelector: cr.Spec.SecondaryNetwork.Multus.NodeSelector,
 		RuntimeSpec: &cniRuntimeSpec{
 			runtimeSpec:        runtimeSpec{config.FromEnv().State.NetworkOperatorResourceNamespace},
-			CniBinDirectory:    utils.GetCniBinDirectory(staticConfig, clusterInfo),
+			CniBinDirectory:    utils.GetCniBinDirectory(staticConfig, clusterInfo, cr.Spec.CNIDirectories),
+			CniConfDirectory:   utils.GetCniConfDirectory(staticConfig, cr.Spec.CNIDirectories),
 			ContainerResources: createContainerResourcesMap(cr.Spec.SecondaryNetwork.Multus.ContainerResources),
 		},
+		ImagePullSecrets: mellanoxv1alpha1.MergeImagePullSecrets(
+			cr.Spec.ImagePullSecrets, cr.Spec.SecondaryNetwork.Multus.ImagePullSecrets),
 	}
 
 	// render objects
 	reqLogger.V(consts.LogLevelDebug).Info("Rendering objects", "data:", renderData)
-	objs, err := s.renderer.RenderObjects(&render.TemplatingData{Data: renderData})
+	objs, err := s.renderer.RenderObjects(ctx, &render.TemplatingData{Data: renderData})
 
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to render objects")
 	}
+	if err := setPriorityClassName(objs, &cr.Spec, cr.Spec.SecondaryNetwork.Multus.PriorityClassName); err != nil {
+		return nil, err
+	}
+	if err := setSecurityContext(objs, cr.Spec.SecondaryNetwork.Multus.SecurityContext); err != nil {
+		return nil, err
+	}
 
 	reqLogger.V(consts.LogLevelDebug).Info("Rendered", "objects:", objs)
 	return objs, nil
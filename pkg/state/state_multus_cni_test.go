@@ -126,6 +126,61 @@ var _ = Describe("Multus CNI state", func() {
 				},
 			))
 			Expect(daemonSet.Spec.Template.Spec.Containers[0].Image).To(Equal("myrepo/myimage:myversion"))
+			Expect(daemonSet.Spec.Template.Spec.Containers[0].ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+		})).To(BeTrue())
+	})
+
+	It("should render Daemonset with the image pull policy of the component", func() {
+		cr := getMinimalNicClusterPolicyWithMultus()
+		cr.Spec.SecondaryNetwork.Multus.ImagePullPolicy = corev1.PullNever
+
+		objs, err := state.GetManifestObjects(context.TODO(), cr, catalog, testLogger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(runFuncForObjectInSlice(objs, "DaemonSet", func(obj *unstructured.Unstructured) {
+			var daemonSet appsv1.DaemonSet
+			err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &daemonSet)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(daemonSet.Spec.Template.Spec.Containers[0].ImagePullPolicy).To(Equal(corev1.PullNever))
+		})).To(BeTrue())
+	})
+
+	It("should render Daemonset with the image pinned by digest", func() {
+		cr := getMinimalNicClusterPolicyWithMultus()
+		digest := "sha256:0e5ad5ee1a1b1bd8ee2e0e1a3ba8e1cb6bb4fd89d21cb3c0c7e8db7e83a0d1d2"
+		cr.Spec.SecondaryNetwork.Multus.Version = digest
+
+		objs, err := state.GetManifestObjects(context.TODO(), cr, catalog, testLogger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(runFuncForObjectInSlice(objs, "DaemonSet", func(obj *unstructured.Unstructured) {
+			var daemonSet appsv1.DaemonSet
+			err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &daemonSet)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(daemonSet.Spec.Template.Spec.Containers[0].Image).To(Equal("myrepo/myimage@" + digest))
+		})).To(BeTrue())
+	})
+
+	It("should render Daemonset with the CNI directories of the flavor specified in CR", func() {
+		cr := getMinimalNicClusterPolicyWithMultus()
+		cr.Spec.CNIDirectories = &mellanoxv1alpha1.CNIDirectoriesSpec{Flavor: mellanoxv1alpha1.CNIFlavorK3s}
+
+		objs, err := state.GetManifestObjects(context.TODO(), cr, catalog, testLogger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(runFuncForObjectInSlice(objs, "DaemonSet", func(obj *unstructured.Unstructured) {
+			var daemonSet appsv1.DaemonSet
+			err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &daemonSet)
+			Expect(err).NotTo(HaveOccurred())
+
+			confDir := "/var/lib/rancher/k3s/agent/etc/cni/net.d"
+			Expect(daemonSet.Spec.Template.Spec.Volumes[0].HostPath.Path).To(Equal(confDir))
+			Expect(daemonSet.Spec.Template.Spec.Volumes[1].HostPath.Path).To(
+				Equal("/var/lib/rancher/k3s/data/current/bin"))
+			Expect(daemonSet.Spec.Template.Spec.Containers[0].Args).To(
+				ContainElement("--multus-kubeconfig-file-host=" + confDir + "/multus.d/multus.kubeconfig"))
 		})).To(BeTrue())
 	})
 
@@ -161,6 +216,59 @@ var _ = Describe("Multus CNI state", func() {
 		})).To(BeTrue())
 	})
 
+	It("should render Daemonset with the NodeAffinity and NodeSelector of the component", func() {
+		cr := getMinimalNicClusterPolicyWithMultus()
+
+		cr.Spec.NodeAffinity = &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+					{Key: "policykey", Operator: corev1.NodeSelectorOpExists}}}},
+			},
+		}
+		nodeAffinity := corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+					{Key: "componentkey", Operator: corev1.NodeSelectorOpExists}}}},
+			},
+		}
+		cr.Spec.SecondaryNetwork.Multus.NodeAffinity = nodeAffinity.DeepCopy()
+		cr.Spec.SecondaryNetwork.Multus.NodeSelector = map[string]string{"network.nvidia.com/rdma": "true"}
+
+		objs, err := state.GetManifestObjects(context.TODO(), cr, catalog, testLogger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(runFuncForObjectInSlice(objs, "DaemonSet", func(obj *unstructured.Unstructured) {
+			var daemonSet appsv1.DaemonSet
+			err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &daemonSet)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(daemonSet.Spec.Template.Spec.Affinity.NodeAffinity).To(Equal(&nodeAffinity))
+			Expect(daemonSet.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"network.nvidia.com/rdma": "true"}))
+		})).To(BeTrue())
+	})
+
+	It("should render Daemonset with the tolerations of the policy and of the component", func() {
+		cr := getMinimalNicClusterPolicyWithMultus()
+
+		policyToleration := corev1.Toleration{Key: "policykey", Operator: corev1.TolerationOpExists}
+		componentToleration := corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpEqual,
+			Value: "rdma", Effect: corev1.TaintEffectNoSchedule}
+		cr.Spec.Tolerations = []corev1.Toleration{policyToleration}
+		cr.Spec.SecondaryNetwork.Multus.Tolerations = []corev1.Toleration{componentToleration}
+
+		objs, err := state.GetManifestObjects(context.TODO(), cr, catalog, testLogger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(runFuncForObjectInSlice(objs, "DaemonSet", func(obj *unstructured.Unstructured) {
+			var daemonSet appsv1.DaemonSet
+			err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &daemonSet)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(daemonSet.Spec.Template.Spec.Tolerations).To(ContainElements(policyToleration, componentToleration))
+		})).To(BeTrue())
+		Expect(cr.Spec.Tolerations).To(HaveLen(1))
+	})
+
 	It("should render Daemonset with ImagePullSecrets when specified in CR", func() {
 		cr := getMinimalNicClusterPolicyWithMultus()
 
@@ -183,6 +291,47 @@ var _ = Describe("Multus CNI state", func() {
 		})).To(BeTrue())
 	})
 
+	It("should render Daemonset with the ImagePullSecrets of the policy and of the component", func() {
+		cr := getMinimalNicClusterPolicyWithMultus()
+
+		cr.Spec.ImagePullSecrets = []string{"registry", "shared"}
+		cr.Spec.SecondaryNetwork.Multus.ImagePullSecrets = []string{"shared", "myimagepullsecret"}
+
+		objs, err := state.GetManifestObjects(context.TODO(), cr, catalog, testLogger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(runFuncForObjectInSlice(objs, "DaemonSet", func(obj *unstructured.Unstructured) {
+			var daemonSet appsv1.DaemonSet
+			err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &daemonSet)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(daemonSet.Spec.Template.Spec.ImagePullSecrets).To(Equal([]corev1.LocalObjectReference{
+				{Name: "registry"}, {Name: "shared"}, {Name: "myimagepullsecret"}}))
+		})).To(BeTrue())
+	})
+
+	It("should render Daemonset with Env when specified in CR", func() {
+		cr := getMinimalNicClusterPolicyWithMultus()
+
+		env := []corev1.EnvVar{
+			{Name: "MULTUS_LOG_LEVEL", Value: "debug"},
+			{Name: "NODE_NAME", ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"}}},
+		}
+		cr.Spec.SecondaryNetwork.Multus.Env = env
+
+		objs, err := state.GetManifestObjects(context.TODO(), cr, catalog, testLogger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(runFuncForObjectInSlice(objs, "DaemonSet", func(obj *unstructured.Unstructured) {
+			var daemonSet appsv1.DaemonSet
+			err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &daemonSet)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(daemonSet.Spec.Template.Spec.Containers[0].Env).To(Equal(env))
+		})).To(BeTrue())
+	})
+
 	It("should render Daemonset with Tolerations when specified in CR", func() {
 		cr := getMinimalNicClusterPolicyWithMultus()
 
@@ -256,6 +405,41 @@ var _ = Describe("Multus CNI state", func() {
 		})).To(BeTrue())
 	})
 
+	It("should render Daemonset with ephemeral-storage and hugepages Resources when specified in CR", func() {
+		cr := getMinimalNicClusterPolicyWithMultus()
+
+		storage := resource.MustParse("2Gi")
+		hugepages := resource.MustParse("512Mi")
+		cr.Spec.SecondaryNetwork.Multus.ContainerResources = []mellanoxv1alpha1.ResourceRequirements{
+			{
+				Name: "kube-multus",
+				Requests: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: storage,
+					"hugepages-2Mi":                 hugepages,
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: storage,
+					"hugepages-2Mi":                 hugepages,
+				},
+			},
+		}
+
+		objs, err := state.GetManifestObjects(context.TODO(), cr, catalog, testLogger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(runFuncForObjectInSlice(objs, "DaemonSet", func(obj *unstructured.Unstructured) {
+			var daemonSet appsv1.DaemonSet
+			err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &daemonSet)
+			Expect(err).NotTo(HaveOccurred())
+
+			resources := daemonSet.Spec.Template.Spec.Containers[0].Resources
+			Expect(resources.Requests).To(HaveKeyWithValue(corev1.ResourceEphemeralStorage, storage))
+			Expect(resources.Requests).To(HaveKeyWithValue(corev1.ResourceName("hugepages-2Mi"), hugepages))
+			Expect(resources.Limits).To(HaveKeyWithValue(corev1.ResourceEphemeralStorage, storage))
+			Expect(resources.Limits).To(HaveKeyWithValue(corev1.ResourceName("hugepages-2Mi"), hugepages))
+		})).To(BeTrue())
+	})
+
 	It("should render resources correctly when config is specified in CR", func() {
 		cr := getMinimalNicClusterPolicyWithMultus()
 
@@ -0,0 +1,186 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state //nolint:dupl
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/config"
+	"github.com/Mellanox/network-operator/pkg/consts"
+	"github.com/Mellanox/network-operator/pkg/nicconfig"
+	"github.com/Mellanox/network-operator/pkg/render"
+)
+
+// NewStateNICConfigurationDaemon creates a new state for the NIC configuration daemon
+func NewStateNICConfigurationDaemon(
+	k8sAPIClient client.Client, manifestDir string) (State, ManifestRenderer, error) {
+	renderer, err := newManifestRenderer(k8sAPIClient, manifestDir)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get files from manifest dir")
+	}
+
+	state := &stateNICConfigurationDaemon{
+		stateSkel: stateSkel{
+			name:        "state-nic-configuration-daemon",
+			description: "nic-configuration-daemon deployed in the cluster",
+			client:      k8sAPIClient,
+			renderer:    renderer,
+		}}
+	return state, state, nil
+}
+
+type stateNICConfigurationDaemon struct {
+	stateSkel
+}
+
+// nicConfigurationDaemonManifestRenderData is NIC configuration daemon manifest rendering data
+type nicConfigurationDaemonManifestRenderData struct {
+	CrSpec           *mellanoxv1alpha1.NICConfigurationDaemonSpec
+	NodeAffinity     *v1.NodeAffinity
+	NodeSelector     map[string]string
+	Tolerations      []v1.Toleration
+	ImagePullSecrets []string
+	RuntimeSpec      *nicConfigurationDaemonRuntimeSpec
+}
+
+type nicConfigurationDaemonRuntimeSpec struct {
+	runtimeSpec
+	// is true if cluster type is Openshift
+	IsOpenshift        bool
+	ContainerResources ContainerResourcesMap
+	// ConfigurationAnnotation is the node annotation with the desired configuration of the NICs
+	ConfigurationAnnotation string
+	// StatusAnnotation is the node annotation the daemon reports the configuration status in
+	StatusAnnotation string
+}
+
+// Sync attempt to get the system to match the desired state which State represent.
+// a sync operation must be relatively short and must not block the execution thread.
+//
+//nolint:dupl
+func (s *stateNICConfigurationDaemon) Sync(
+	ctx context.Context, customResource interface{}, infoCatalog InfoCatalog) (SyncState, error) {
+	reqLogger := log.FromContext(ctx)
+	cr := customResource.(*mellanoxv1alpha1.NicClusterPolicy)
+	reqLogger.V(consts.LogLevelInfo).Info(
+		"Sync Custom resource", "State:", s.name, "Name:", cr.Name, "Namespace:", cr.Namespace)
+
+	if cr.Spec.NicConfigurationDaemon == nil {
+		// Either this state was not required to run or an update occurred and we need to remove
+		// the resources that where created.
+		return s.handleStateObjectsDeletion(ctx)
+	}
+
+	clusterInfo := infoCatalog.GetClusterTypeProvider()
+	if clusterInfo == nil {
+		return SyncStateError, errors.New("unexpected state, catalog does not provide cluster type info")
+	}
+
+	// Fill ManifestRenderData and render objects
+	objs, err := s.GetManifestObjects(ctx, cr, infoCatalog, reqLogger)
+	if err != nil {
+		return SyncStateNotReady, &RenderError{Err: errors.Wrap(err, "failed to create k8s objects from manifest")}
+	}
+	if len(objs) == 0 {
+		return SyncStateNotReady, nil
+	}
+
+	// Create objects if they dont exist, Update objects if they do exist
+	err = s.createOrUpdateObjs(ctx, func(obj *unstructured.Unstructured) error {
+		if err := controllerutil.SetControllerReference(cr, obj, s.client.Scheme()); err != nil {
+			return errors.Wrap(err, "failed to set controller reference for object")
+		}
+		return nil
+	}, objs, cr.Spec.RawPatches)
+	if err != nil {
+		return SyncStateNotReady, errors.Wrap(err, "failed to create/update objects")
+	}
+	waitForStaleObjectsRemoval, err := s.handleStaleStateObjects(ctx, objs)
+	if err != nil {
+		return SyncStateNotReady, errors.Wrap(err, "failed to handle state stale objects")
+	}
+	if waitForStaleObjectsRemoval {
+		return SyncStateNotReady, nil
+	}
+	// Check objects status
+	syncState, err := s.getSyncState(ctx, objs)
+	if err != nil {
+		return SyncStateNotReady, errors.Wrap(err, "failed to get sync state")
+	}
+	return syncState, nil
+}
+
+// GetWatchSources returns a map of source kinds that should be watched for the state keyed by the source kind name
+func (s *stateNICConfigurationDaemon) GetWatchSources() map[string]WatchSource {
+	wr := make(map[string]WatchSource)
+	wr["DaemonSet"] = daemonSetsWatchSource(s.name)
+	return wr
+}
+
+func (s *stateNICConfigurationDaemon) GetManifestObjects(
+	ctx context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
+	catalog InfoCatalog, reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
+	if cr == nil || cr.Spec.NicConfigurationDaemon == nil {
+		return nil, errors.New("failed to render objects: state spec is nil")
+	}
+
+	clusterInfo := catalog.GetClusterTypeProvider()
+	if clusterInfo == nil {
+		return nil, errors.New("clusterType provider required")
+	}
+	renderData := &nicConfigurationDaemonManifestRenderData{
+		CrSpec:       cr.Spec.NicConfigurationDaemon,
+		NodeAffinity: getNodeAffinity(cr.Spec.NodeAffinity, cr.Spec.NicConfigurationDaemon.NodeAffinity),
+		NodeSelector: cr.Spec.NicConfigurationDaemon.NodeSelector,
+		Tolerations:  getTolerations(cr.Spec.Tolerations, cr.Spec.NicConfigurationDaemon.Tolerations),
+		RuntimeSpec: &nicConfigurationDaemonRuntimeSpec{
+			runtimeSpec:             runtimeSpec{config.FromEnv().State.NetworkOperatorResourceNamespace},
+			IsOpenshift:             clusterInfo.IsOpenshift(),
+			ContainerResources:      createContainerResourcesMap(cr.Spec.NicConfigurationDaemon.ContainerResources),
+			ConfigurationAnnotation: nicconfig.DesiredConfigurationAnnotationKey,
+			StatusAnnotation:        nicconfig.ConfigurationStatusAnnotationKey,
+		},
+		ImagePullSecrets: mellanoxv1alpha1.MergeImagePullSecrets(
+			cr.Spec.ImagePullSecrets, cr.Spec.NicConfigurationDaemon.ImagePullSecrets),
+	}
+
+	// render objects
+	reqLogger.V(consts.LogLevelDebug).Info("Rendering objects", "data:", renderData)
+	objs, err := s.renderer.RenderObjects(ctx, &render.TemplatingData{Data: renderData})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render objects")
+	}
+	if err := setPriorityClassName(objs, &cr.Spec, cr.Spec.NicConfigurationDaemon.PriorityClassName); err != nil {
+		return nil, err
+	}
+	if err := setSecurityContext(objs, cr.Spec.NicConfigurationDaemon.SecurityContext); err != nil {
+		return nil, err
+	}
+
+	reqLogger.V(consts.LogLevelDebug).Info("Rendered", "objects:", objs)
+	return objs, nil
+}
@@ -0,0 +1,44 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/state"
+)
+
+var _ = Describe("NicClusterPolicyReconciler Controller", func() {
+	ctx := context.Background()
+
+	imageSpec := addContainerResources(getTestImageSpec(), "nic-configuration-daemon", "5", "3")
+	cr := getTestClusterPolicyWithBaseFields()
+	cr.Spec.NicConfigurationDaemon = &mellanoxv1alpha1.NICConfigurationDaemonSpec{ImageSpec: *imageSpec}
+	_, s, err := state.NewStateNICConfigurationDaemon(fake.NewClientBuilder().Build(),
+		"../../manifests/state-nic-configuration-daemon")
+	Expect(err).ToNot(HaveOccurred())
+
+	It("should test fields are set correctly", func() {
+		GetManifestObjectsTest(ctx, cr, getTestCatalog(), imageSpec, s)
+	})
+})
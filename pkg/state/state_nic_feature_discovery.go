@@ -21,7 +21,6 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
-	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -32,18 +31,16 @@ import (
 	"github.com/Mellanox/network-operator/pkg/config"
 	"github.com/Mellanox/network-operator/pkg/consts"
 	"github.com/Mellanox/network-operator/pkg/render"
-	"github.com/Mellanox/network-operator/pkg/utils"
 )
 
 // NewStateNICFeatureDiscovery creates a new state for NICFeatureDiscovery
 func NewStateNICFeatureDiscovery(
 	k8sAPIClient client.Client, manifestDir string) (State, ManifestRenderer, error) {
-	files, err := utils.GetFilesWithSuffix(manifestDir, render.ManifestFileSuffix...)
+	renderer, err := newManifestRenderer(k8sAPIClient, manifestDir)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to get files from manifest dir")
 	}
 
-	renderer := render.NewRenderer(files)
 	state := &stateNICFeatureDiscovery{
 		stateSkel: stateSkel{
 			name:        "state-nic-feature-discovery",
@@ -60,10 +57,12 @@ type stateNICFeatureDiscovery struct {
 
 // nfdManifestRenderData is NIC Feature Discovery manifest rendering data
 type nfdManifestRenderData struct {
-	CrSpec       *mellanoxv1alpha1.NICFeatureDiscoverySpec
-	NodeAffinity *v1.NodeAffinity
-	Tolerations  []v1.Toleration
-	RuntimeSpec  *nfdRuntimeSpec
+	CrSpec           *mellanoxv1alpha1.NICFeatureDiscoverySpec
+	NodeAffinity     *v1.NodeAffinity
+	NodeSelector     map[string]string
+	Tolerations      []v1.Toleration
+	ImagePullSecrets []string
+	RuntimeSpec      *nfdRuntimeSpec
 }
 
 type nfdRuntimeSpec struct {
@@ -98,7 +97,7 @@ func (s *stateNICFeatureDiscovery) Sync(
 	// Fill ManifestRenderData and render objects
 	objs, err := s.GetManifestObjects(ctx, cr, infoCatalog, reqLogger)
 	if err != nil {
-		return SyncStateNotReady, errors.Wrap(err, "failed to create k8s objects from manifest")
+		return SyncStateNotReady, &RenderError{Err: errors.Wrap(err, "failed to create k8s objects from manifest")}
 	}
 	if len(objs) == 0 {
 		return SyncStateNotReady, nil
@@ -110,7 +109,7 @@ func (s *stateNICFeatureDiscovery) Sync(
 			return errors.Wrap(err, "failed to set controller reference for object")
 		}
 		return nil
-	}, objs)
+	}, objs, cr.Spec.RawPatches)
 	if err != nil {
 		return SyncStateNotReady, errors.Wrap(err, "failed to create/update objects")
 	}
@@ -130,14 +129,14 @@ func (s *stateNICFeatureDiscovery) Sync(
 }
 
 // GetWatchSources returns a map of source kinds that should be watched for the state keyed by the source kind name
-func (s *stateNICFeatureDiscovery) GetWatchSources() map[string]client.Object {
-	wr := make(map[string]client.Object)
-	wr["DaemonSet"] = &appsv1.DaemonSet{}
+func (s *stateNICFeatureDiscovery) GetWatchSources() map[string]WatchSource {
+	wr := make(map[string]WatchSource)
+	wr["DaemonSet"] = daemonSetsWatchSource(s.name)
 	return wr
 }
 
 func (s *stateNICFeatureDiscovery) GetManifestObjects(
-	_ context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
+	ctx context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
 	catalog InfoCatalog, reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
 	if cr == nil || cr.Spec.NicFeatureDiscovery == nil {
 		return nil, errors.New("failed to render objects: state spec is nil")
@@ -149,22 +148,31 @@ func (s *stateNICFeatureDiscovery) GetManifestObjects(
 	}
 	renderData := &nfdManifestRenderData{
 		CrSpec:       cr.Spec.NicFeatureDiscovery,
-		NodeAffinity: cr.Spec.NodeAffinity,
-		Tolerations:  cr.Spec.Tolerations,
+		NodeAffinity: getNodeAffinity(cr.Spec.NodeAffinity, cr.Spec.NicFeatureDiscovery.NodeAffinity),
+		NodeSelector: cr.Spec.NicFeatureDiscovery.NodeSelector,
+		Tolerations:  getTolerations(cr.Spec.Tolerations, cr.Spec.NicFeatureDiscovery.Tolerations),
 		RuntimeSpec: &nfdRuntimeSpec{
 			runtimeSpec:        runtimeSpec{config.FromEnv().State.NetworkOperatorResourceNamespace},
 			IsOpenshift:        clusterInfo.IsOpenshift(),
 			ContainerResources: createContainerResourcesMap(cr.Spec.NicFeatureDiscovery.ContainerResources),
 		},
+		ImagePullSecrets: mellanoxv1alpha1.MergeImagePullSecrets(
+			cr.Spec.ImagePullSecrets, cr.Spec.NicFeatureDiscovery.ImagePullSecrets),
 	}
 
 	// render objects
 	reqLogger.V(consts.LogLevelDebug).Info("Rendering objects", "data:", renderData)
-	objs, err := s.renderer.RenderObjects(&render.TemplatingData{Data: renderData})
+	objs, err := s.renderer.RenderObjects(ctx, &render.TemplatingData{Data: renderData})
 
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to render objects")
 	}
+	if err := setPriorityClassName(objs, &cr.Spec, cr.Spec.NicFeatureDiscovery.PriorityClassName); err != nil {
+		return nil, err
+	}
+	if err := setSecurityContext(objs, cr.Spec.NicFeatureDiscovery.SecurityContext); err != nil {
+		return nil, err
+	}
 
 	reqLogger.V(consts.LogLevelDebug).Info("Rendered", "objects:", objs)
 	return objs, nil
@@ -22,7 +22,10 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
 	"github.com/Mellanox/network-operator/pkg/state"
@@ -41,4 +44,21 @@ var _ = Describe("NicClusterPolicyReconciler Controller", func() {
 	It("should test fields are set correctly", func() {
 		GetManifestObjectsTest(ctx, cr, getTestCatalog(), imageSpec, s)
 	})
+
+	It("should pass the extended labels to nic-feature-discovery", func() {
+		labelsCr := cr.DeepCopy()
+		labelsCr.Spec.NicFeatureDiscovery.Labels = []mellanoxv1alpha1.NICFeatureDiscoveryLabel{
+			mellanoxv1alpha1.NICFeatureDiscoveryLabelFirmwareVersion, mellanoxv1alpha1.NICFeatureDiscoveryLabelDPUMode}
+		objs, err := s.GetManifestObjects(ctx, labelsCr, getTestCatalog(), log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		ds := &appsv1.DaemonSet{}
+		for _, obj := range objs {
+			if obj.GetKind() == "DaemonSet" {
+				Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, ds)).To(Succeed())
+			}
+		}
+		Expect(ds.Spec.Template.Spec.Containers).To(HaveLen(1))
+		Expect(ds.Spec.Template.Spec.Containers[0].Args).To(Equal([]string{
+			"--v=0", "--logging-format=json", "--label=firmware-version", "--label=dpu-mode"}))
+	})
 })
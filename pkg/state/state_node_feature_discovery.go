@@ -0,0 +1,206 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/config"
+	"github.com/Mellanox/network-operator/pkg/consts"
+	"github.com/Mellanox/network-operator/pkg/render"
+)
+
+const stateNodeFeatureDiscoveryName = "state-node-feature-discovery"
+
+var (
+	// defaultNFDExtraLabelNs are the namespaces of the labels of the NVIDIA components, e.g. nvidia.com/gpu.present
+	defaultNFDExtraLabelNs = []string{"nvidia.com"}
+	// defaultNFDPCIClasses are the PCI classes of the Ethernet and InfiniBand controllers and of the GPUs
+	defaultNFDPCIClasses = []string{"0200", "0207", "0300", "0302"}
+)
+
+// NewStateNodeFeatureDiscovery creates a new state for Node Feature Discovery deployed by the operator
+func NewStateNodeFeatureDiscovery(
+	k8sAPIClient client.Client, manifestDir string) (State, ManifestRenderer, error) {
+	renderer, err := newManifestRenderer(k8sAPIClient, manifestDir)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get files from manifest dir")
+	}
+
+	state := &stateNodeFeatureDiscovery{
+		stateSkel: stateSkel{
+			name:        stateNodeFeatureDiscoveryName,
+			description: "node-feature-discovery deployed in the cluster",
+			client:      k8sAPIClient,
+			renderer:    renderer,
+		}}
+	return state, state, nil
+}
+
+type stateNodeFeatureDiscovery struct {
+	stateSkel
+}
+
+// nodeFeatureDiscoveryManifestRenderData is Node Feature Discovery manifest rendering data
+type nodeFeatureDiscoveryManifestRenderData struct {
+	CrSpec           *mellanoxv1alpha1.NodeFeatureDiscoverySpec
+	ExtraLabelNs     []string
+	PCIClasses       []string
+	NodeAffinity     *v1.NodeAffinity
+	NodeSelector     map[string]string
+	Tolerations      []v1.Toleration
+	ImagePullSecrets []string
+	RuntimeSpec      *nodeFeatureDiscoveryRuntimeSpec
+}
+
+type nodeFeatureDiscoveryRuntimeSpec struct {
+	runtimeSpec
+	// is true if cluster type is Openshift
+	IsOpenshift        bool
+	ContainerResources ContainerResourcesMap
+}
+
+// Sync attempt to get the system to match the desired state which State represent.
+// a sync operation must be relatively short and must not block the execution thread.
+//
+//nolint:dupl
+func (s *stateNodeFeatureDiscovery) Sync(
+	ctx context.Context, customResource interface{}, infoCatalog InfoCatalog) (SyncState, error) {
+	reqLogger := log.FromContext(ctx)
+	cr := customResource.(*mellanoxv1alpha1.NicClusterPolicy)
+	reqLogger.V(consts.LogLevelInfo).Info(
+		"Sync Custom resource", "State:", s.name, "Name:", cr.Name, "Namespace:", cr.Namespace)
+
+	if cr.Spec.NodeFeatureDiscovery == nil {
+		// Either this state was not required to run or an update occurred and we need to remove
+		// the resources that where created, the NFD CRDs included.
+		return s.handleStateObjectsDeletion(ctx)
+	}
+
+	clusterInfo := infoCatalog.GetClusterTypeProvider()
+	if clusterInfo == nil {
+		return SyncStateError, errors.New("unexpected state, catalog does not provide cluster type info")
+	}
+
+	// Fill ManifestRenderData and render objects
+	objs, err := s.GetManifestObjects(ctx, cr, infoCatalog, reqLogger)
+	if err != nil {
+		return SyncStateNotReady, &RenderError{Err: errors.Wrap(err, "failed to create k8s objects from manifest")}
+	}
+	if len(objs) == 0 {
+		return SyncStateNotReady, nil
+	}
+
+	// Create objects if they dont exist, Update objects if they do exist
+	err = s.createOrUpdateObjs(ctx, func(obj *unstructured.Unstructured) error {
+		if err := controllerutil.SetControllerReference(cr, obj, s.client.Scheme()); err != nil {
+			return errors.Wrap(err, "failed to set controller reference for object")
+		}
+		return nil
+	}, objs, cr.Spec.RawPatches)
+	if err != nil {
+		return SyncStateNotReady, errors.Wrap(err, "failed to create/update objects")
+	}
+	waitForStaleObjectsRemoval, err := s.handleStaleStateObjects(ctx, objs)
+	if err != nil {
+		return SyncStateNotReady, errors.Wrap(err, "failed to handle state stale objects")
+	}
+	if waitForStaleObjectsRemoval {
+		return SyncStateNotReady, nil
+	}
+	// Check objects status
+	syncState, err := s.getSyncState(ctx, objs)
+	if err != nil {
+		return SyncStateNotReady, errors.Wrap(err, "failed to get sync state")
+	}
+	return syncState, nil
+}
+
+// GetWatchSources returns a map of source kinds that should be watched for the state keyed by the source kind name
+func (s *stateNodeFeatureDiscovery) GetWatchSources() map[string]WatchSource {
+	wr := make(map[string]WatchSource)
+	wr["DaemonSet"] = daemonSetsWatchSource(s.name)
+	wr["Deployment"] = deploymentsWatchSource(s.name)
+	return wr
+}
+
+// GetManifestObjects renders the objects of nfd-master and nfd-worker, the default label namespaces and
+// PCI classes are used if they are not set in the spec. No objects are rendered if Node Feature Discovery
+// isn't deployed by the policy, e.g. when it is deployed by the Helm chart.
+func (s *stateNodeFeatureDiscovery) GetManifestObjects(
+	ctx context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
+	catalog InfoCatalog, reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
+	if cr == nil {
+		return nil, errors.New("failed to render objects: state spec is nil")
+	}
+	if cr.Spec.NodeFeatureDiscovery == nil {
+		return []*unstructured.Unstructured{}, nil
+	}
+	spec := cr.Spec.NodeFeatureDiscovery
+
+	clusterInfo := catalog.GetClusterTypeProvider()
+	if clusterInfo == nil {
+		return nil, errors.New("clusterType provider required")
+	}
+	extraLabelNs := spec.ExtraLabelNs
+	if len(extraLabelNs) == 0 {
+		extraLabelNs = defaultNFDExtraLabelNs
+	}
+	pciClasses := spec.PCIClasses
+	if len(pciClasses) == 0 {
+		pciClasses = defaultNFDPCIClasses
+	}
+	renderData := &nodeFeatureDiscoveryManifestRenderData{
+		CrSpec:       spec,
+		ExtraLabelNs: extraLabelNs,
+		PCIClasses:   pciClasses,
+		NodeAffinity: getNodeAffinity(cr.Spec.NodeAffinity, spec.NodeAffinity),
+		NodeSelector: spec.NodeSelector,
+		Tolerations:  getTolerations(cr.Spec.Tolerations, spec.Tolerations),
+		RuntimeSpec: &nodeFeatureDiscoveryRuntimeSpec{
+			runtimeSpec:        runtimeSpec{config.FromEnv().State.NetworkOperatorResourceNamespace},
+			IsOpenshift:        clusterInfo.IsOpenshift(),
+			ContainerResources: createContainerResourcesMap(spec.ContainerResources),
+		},
+		ImagePullSecrets: mellanoxv1alpha1.MergeImagePullSecrets(cr.Spec.ImagePullSecrets, spec.ImagePullSecrets),
+	}
+
+	// render objects
+	reqLogger.V(consts.LogLevelDebug).Info("Rendering objects", "data:", renderData)
+	objs, err := s.renderer.RenderObjects(ctx, &render.TemplatingData{Data: renderData})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render objects")
+	}
+	if err := setPriorityClassName(objs, &cr.Spec, spec.PriorityClassName); err != nil {
+		return nil, err
+	}
+	if err := setSecurityContext(objs, spec.SecurityContext); err != nil {
+		return nil, err
+	}
+
+	reqLogger.V(consts.LogLevelDebug).Info("Rendered", "objects:", objs)
+	return objs, nil
+}
@@ -0,0 +1,135 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	clustertype_mocks "github.com/Mellanox/network-operator/pkg/clustertype/mocks"
+	"github.com/Mellanox/network-operator/pkg/state"
+)
+
+var _ = Describe("Node Feature Discovery state", func() {
+	ctx := context.Background()
+
+	imageSpec := addContainerResources(getTestImageSpec(), "nfd-master", "5", "3")
+	imageSpec = addContainerResources(imageSpec, "nfd-worker", "5", "3")
+	cr := getTestClusterPolicyWithBaseFields()
+	cr.Spec.NodeFeatureDiscovery = &mellanoxv1alpha1.NodeFeatureDiscoverySpec{ImageSpec: *imageSpec}
+	_, s, err := state.NewStateNodeFeatureDiscovery(fake.NewClientBuilder().Build(),
+		"../../manifests/state-node-feature-discovery")
+	Expect(err).ToNot(HaveOccurred())
+
+	findObj := func(objs []*unstructured.Unstructured, kind, name string) *unstructured.Unstructured {
+		for _, obj := range objs {
+			if obj.GetKind() == kind && obj.GetName() == name {
+				return obj
+			}
+		}
+		Fail("object not rendered: " + kind + " " + name)
+		return nil
+	}
+
+	It("should test fields are set correctly", func() {
+		GetManifestObjectsTest(ctx, cr, getTestCatalog(), imageSpec, s)
+	})
+
+	It("should not render objects if NFD isn't deployed by the policy", func() {
+		objs, err := s.GetManifestObjects(ctx, getTestClusterPolicyWithBaseFields(), getTestCatalog(), log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(BeEmpty())
+	})
+
+	It("should render the NFD CRDs with the master and the workers", func() {
+		objs, err := s.GetManifestObjects(ctx, cr, getTestCatalog(), log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		findObj(objs, "CustomResourceDefinition", "nodefeatures.nfd.k8s-sigs.io")
+		findObj(objs, "CustomResourceDefinition", "nodefeaturerules.nfd.k8s-sigs.io")
+		findObj(objs, "Service", "nfd-master")
+
+		deployment := &appsv1.Deployment{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(
+			findObj(objs, "Deployment", "nfd-master").Object, deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Spec.Containers[0].Args).To(Equal(
+			[]string{"-port=8080", "-enable-nodefeature-api"}))
+
+		ds := &appsv1.DaemonSet{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(
+			findObj(objs, "DaemonSet", "nfd-worker").Object, ds)).To(Succeed())
+		Expect(ds.Spec.Template.Spec.Containers[0].Image).To(Equal(deployment.Spec.Template.Spec.Containers[0].Image))
+		Expect(ds.Spec.Template.Spec.Containers[0].Args).To(Equal(
+			[]string{"-server=nfd-master:8080", "-enable-nodefeature-api"}))
+	})
+
+	It("should configure NFD with the default label namespaces and PCI classes", func() {
+		objs, err := s.GetManifestObjects(ctx, cr, getTestCatalog(), log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		cm := &corev1.ConfigMap{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(
+			findObj(objs, "ConfigMap", "nfd-master-conf").Object, cm)).To(Succeed())
+		Expect(cm.Data["nfd-master.conf"]).To(Equal("extraLabelNs:\n  - \"nvidia.com\"\n"))
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(
+			findObj(objs, "ConfigMap", "nfd-worker-conf").Object, cm)).To(Succeed())
+		Expect(cm.Data["nfd-worker.conf"]).To(ContainSubstring(
+			"deviceClassWhitelist:\n      - \"0200\"\n      - \"0207\"\n      - \"0300\"\n      - \"0302\"\n"))
+	})
+
+	It("should configure NFD with the label namespaces and PCI classes of the spec", func() {
+		nfdCr := cr.DeepCopy()
+		nfdCr.Spec.NodeFeatureDiscovery.ExtraLabelNs = []string{"example.com"}
+		nfdCr.Spec.NodeFeatureDiscovery.PCIClasses = []string{"0207"}
+		objs, err := s.GetManifestObjects(ctx, nfdCr, getTestCatalog(), log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		cm := &corev1.ConfigMap{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(
+			findObj(objs, "ConfigMap", "nfd-master-conf").Object, cm)).To(Succeed())
+		Expect(cm.Data["nfd-master.conf"]).To(Equal("extraLabelNs:\n  - \"example.com\"\n"))
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(
+			findObj(objs, "ConfigMap", "nfd-worker-conf").Object, cm)).To(Succeed())
+		Expect(cm.Data["nfd-worker.conf"]).To(ContainSubstring("deviceClassWhitelist:\n      - \"0207\"\n"))
+	})
+
+	It("should allow the workers to use the privileged SCC on OpenShift", func() {
+		catalog := state.NewInfoCatalog()
+		clusterTypeProvider := clustertype_mocks.Provider{}
+		clusterTypeProvider.On("IsOpenshift").Return(true)
+		catalog.Add(state.InfoTypeClusterType, &clusterTypeProvider)
+		objs, err := s.GetManifestObjects(ctx, cr, catalog, log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		role := &rbacv1.Role{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(
+			findObj(objs, "Role", "nfd-worker").Object, role)).To(Succeed())
+		Expect(role.Rules).To(ContainElement(rbacv1.PolicyRule{
+			APIGroups:     []string{"security.openshift.io"},
+			Resources:     []string{"securitycontextconstraints"},
+			Verbs:         []string{"use"},
+			ResourceNames: []string{"privileged"},
+		}))
+	})
+})
@@ -0,0 +1,165 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/config"
+	"github.com/Mellanox/network-operator/pkg/consts"
+	"github.com/Mellanox/network-operator/pkg/render"
+)
+
+// defaultNICPCIClasses are the PCI classes of the Ethernet and InfiniBand controllers
+var defaultNICPCIClasses = []string{"0200", "0207"}
+
+// NewStateNodeFeatureRules creates a new state for the NodeFeatureRules of Node Feature Discovery
+func NewStateNodeFeatureRules(
+	k8sAPIClient client.Client, manifestDir string) (State, ManifestRenderer, error) {
+	renderer, err := newManifestRenderer(k8sAPIClient, manifestDir)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get files from manifest dir")
+	}
+
+	state := &stateNodeFeatureRules{
+		stateSkel: stateSkel{
+			name:        "state-node-feature-rules",
+			description: "NodeFeatureRules of Node Feature Discovery deployed in the cluster",
+			// the NodeFeatureRule CRD is deployed with Node Feature Discovery if the operator deploys it
+			dependencies: []string{stateNodeFeatureDiscoveryName},
+			client:       k8sAPIClient,
+			renderer:     renderer,
+		}}
+	return state, state, nil
+}
+
+type stateNodeFeatureRules struct {
+	stateSkel
+}
+
+type nodeFeatureRulesManifestRenderData struct {
+	CrSpec      *mellanoxv1alpha1.NodeFeatureRulesSpec
+	PCIClasses  []string
+	RuntimeSpec *runtimeSpec
+}
+
+// Sync attempt to get the system to match the desired state which State represent.
+// a sync operation must be relatively short and must not block the execution thread.
+func (s *stateNodeFeatureRules) Sync(
+	ctx context.Context, customResource interface{}, infoCatalog InfoCatalog) (SyncState, error) {
+	reqLogger := log.FromContext(ctx)
+	cr := customResource.(*mellanoxv1alpha1.NicClusterPolicy)
+	reqLogger.V(consts.LogLevelInfo).Info(
+		"Sync Custom resource", "State:", s.name, "Name:", cr.Name, "Namespace:", cr.Namespace)
+
+	if cr.Spec.NodeFeatureRules == nil {
+		// Either this state was not required to run or an update occurred and we need to remove
+		// the resources that where created.
+		return s.handleStateObjectsDeletion(ctx)
+	}
+
+	objs, err := s.GetManifestObjects(ctx, cr, infoCatalog, reqLogger)
+	if err != nil {
+		return SyncStateNotReady, &RenderError{Err: errors.Wrap(err, "failed to create k8s objects from manifest")}
+	}
+	if len(objs) == 0 {
+		return SyncStateNotReady, nil
+	}
+
+	// Create objects if they dont exist, Update objects if they do exist
+	err = s.createOrUpdateObjs(ctx, func(obj *unstructured.Unstructured) error {
+		if err := controllerutil.SetControllerReference(cr, obj, s.client.Scheme()); err != nil {
+			return errors.Wrap(err, "failed to set controller reference for object")
+		}
+		return nil
+	}, objs, cr.Spec.RawPatches)
+	if err != nil {
+		return SyncStateNotReady, errors.Wrap(err, "failed to create/update objects")
+	}
+	// the rules of a previous content have another name and are removed as stale objects
+	waitForStaleObjectsRemoval, err := s.handleStaleStateObjects(ctx, objs)
+	if err != nil {
+		return SyncStateNotReady, errors.Wrap(err, "failed to handle state stale objects")
+	}
+	if waitForStaleObjectsRemoval {
+		return SyncStateNotReady, nil
+	}
+	return SyncStateReady, nil
+}
+
+// GetWatchSources returns a map of source kinds that should be watched for the state keyed by the source kind name,
+// the NodeFeatureRules are not watched as their kind exists only if Node Feature Discovery is deployed
+func (s *stateNodeFeatureRules) GetWatchSources() map[string]WatchSource {
+	return map[string]WatchSource{}
+}
+
+// GetManifestObjects renders the NodeFeatureRules, their names are suffixed with the hash of their spec.
+// No objects are rendered if the NodeFeatureRules aren't enabled in the policy.
+func (s *stateNodeFeatureRules) GetManifestObjects(
+	ctx context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
+	_ InfoCatalog, reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
+	if cr == nil {
+		return nil, errors.New("failed to render objects: state spec is nil")
+	}
+	if cr.Spec.NodeFeatureRules == nil {
+		return []*unstructured.Unstructured{}, nil
+	}
+
+	pciClasses := cr.Spec.NodeFeatureRules.PCIClasses
+	if len(pciClasses) == 0 {
+		pciClasses = defaultNICPCIClasses
+	}
+	renderData := &nodeFeatureRulesManifestRenderData{
+		CrSpec:      cr.Spec.NodeFeatureRules,
+		PCIClasses:  pciClasses,
+		RuntimeSpec: &runtimeSpec{config.FromEnv().State.NetworkOperatorResourceNamespace},
+	}
+
+	// render objects
+	reqLogger.V(consts.LogLevelDebug).Info("Rendering objects", "data:", renderData)
+	objs, err := s.renderer.RenderObjects(ctx, &render.TemplatingData{Data: renderData})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render objects")
+	}
+	for _, obj := range objs {
+		if err := addSpecHashToName(obj); err != nil {
+			return nil, errors.Wrapf(err, "failed to name %s %s", obj.GetKind(), obj.GetName())
+		}
+	}
+	reqLogger.V(consts.LogLevelDebug).Info("Rendered", "objects:", objs)
+	return objs, nil
+}
+
+// addSpecHashToName suffixes the name of the object with the hash of its spec, so that an object whose content
+// changes is created anew, the object of the previous content is then removed as a stale object of the state
+func addSpecHashToName(obj *unstructured.Unstructured) error {
+	spec, err := json.Marshal(obj.Object["spec"])
+	if err != nil {
+		return err
+	}
+	obj.SetName(obj.GetName() + "-" + getStringHash(string(spec)))
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/state"
+)
+
+var _ = Describe("NodeFeatureRules state", func() {
+	var (
+		cr       *mellanoxv1alpha1.NicClusterPolicy
+		renderer state.ManifestRenderer
+	)
+	ctx := context.Background()
+
+	getClasses := func(obj *unstructured.Unstructured) []interface{} {
+		rules, _, err := unstructured.NestedSlice(obj.Object, "spec", "rules")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rules).To(HaveLen(1))
+		features, _, err := unstructured.NestedSlice(rules[0].(map[string]interface{}), "matchFeatures")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(features).To(HaveLen(1))
+		classes, _, err := unstructured.NestedSlice(features[0].(map[string]interface{}),
+			"matchExpressions", "class", "value")
+		Expect(err).NotTo(HaveOccurred())
+		return classes
+	}
+
+	BeforeEach(func() {
+		cr = getTestClusterPolicyWithBaseFields()
+		var err error
+		_, renderer, err = state.NewStateNodeFeatureRules(
+			fake.NewClientBuilder().Build(), "../../manifests/state-node-feature-rules")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should not render objects without NodeFeatureRules spec", func() {
+		objs, err := renderer.GetManifestObjects(ctx, cr, state.NewInfoCatalog(), log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(BeEmpty())
+	})
+
+	It("should render the rule with the default PCI classes", func() {
+		cr.Spec.NodeFeatureRules = &mellanoxv1alpha1.NodeFeatureRulesSpec{}
+		objs, err := renderer.GetManifestObjects(ctx, cr, state.NewInfoCatalog(), log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(1))
+		Expect(objs[0].GetKind()).To(Equal("NodeFeatureRule"))
+		Expect(objs[0].GetName()).To(HavePrefix("nvidia-nics-rules-"))
+		Expect(getClasses(objs[0])).To(ConsistOf("0200", "0207"))
+	})
+
+	It("should rename the rule when its content changes", func() {
+		cr.Spec.NodeFeatureRules = &mellanoxv1alpha1.NodeFeatureRulesSpec{}
+		objs, err := renderer.GetManifestObjects(ctx, cr, state.NewInfoCatalog(), log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		defaultName := objs[0].GetName()
+
+		objs, err = renderer.GetManifestObjects(ctx, cr, state.NewInfoCatalog(), log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs[0].GetName()).To(Equal(defaultName))
+
+		cr.Spec.NodeFeatureRules.PCIClasses = []string{"0207"}
+		objs, err = renderer.GetManifestObjects(ctx, cr, state.NewInfoCatalog(), log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs[0].GetName()).To(HavePrefix("nvidia-nics-rules-"))
+		Expect(objs[0].GetName()).NotTo(Equal(defaultName))
+		Expect(getClasses(objs[0])).To(ConsistOf("0207"))
+	})
+})
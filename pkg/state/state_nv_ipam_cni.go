@@ -21,7 +21,6 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
-	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -38,12 +37,11 @@ import (
 // NewStateNVIPAMCNI creates a new state for Multus
 func NewStateNVIPAMCNI(
 	k8sAPIClient client.Client, manifestDir string) (State, ManifestRenderer, error) {
-	files, err := utils.GetFilesWithSuffix(manifestDir, render.ManifestFileSuffix...)
+	renderer, err := newManifestRenderer(k8sAPIClient, manifestDir)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to get files from manifest dir")
 	}
 
-	renderer := render.NewRenderer(files)
 	state := &stateNVIPAMCNI{
 		stateSkel: stateSkel{
 			name:        "state-nv-ipam-cni",
@@ -60,10 +58,12 @@ type stateNVIPAMCNI struct {
 
 // NVIPAMManifestRenderData contains information used to render Kubernetes objects related to NVIPAM.
 type NVIPAMManifestRenderData struct {
-	CrSpec       *mellanoxv1alpha1.NVIPAMSpec
-	NodeAffinity *v1.NodeAffinity
-	Tolerations  []v1.Toleration
-	RuntimeSpec  *cniRuntimeSpec
+	CrSpec           *mellanoxv1alpha1.NVIPAMSpec
+	NodeAffinity     *v1.NodeAffinity
+	NodeSelector     map[string]string
+	Tolerations      []v1.Toleration
+	ImagePullSecrets []string
+	RuntimeSpec      *cniRuntimeSpec
 }
 
 // Sync attempt to get the system to match the desired state which State represent.
@@ -96,7 +96,7 @@ func (s *stateNVIPAMCNI) Sync(
 	// Fill ManifestRenderData and render objects
 	objs, err := s.GetManifestObjects(ctx, cr, infoCatalog, reqLogger)
 	if err != nil {
-		return SyncStateNotReady, errors.Wrap(err, "failed to create k8s objects from manifest")
+		return SyncStateNotReady, &RenderError{Err: errors.Wrap(err, "failed to create k8s objects from manifest")}
 	}
 	if len(objs) == 0 {
 		return SyncStateNotReady, nil
@@ -108,7 +108,7 @@ func (s *stateNVIPAMCNI) Sync(
 			return errors.Wrap(err, "failed to set controller reference for object")
 		}
 		return nil
-	}, objs)
+	}, objs, cr.Spec.RawPatches)
 	if err != nil {
 		return SyncStateNotReady, errors.Wrap(err, "failed to create/update objects")
 	}
@@ -129,16 +129,16 @@ func (s *stateNVIPAMCNI) Sync(
 }
 
 // GetWatchSources returns a map of source kinds that should be watched for the state keyed by the source kind name
-func (s *stateNVIPAMCNI) GetWatchSources() map[string]client.Object {
-	wr := make(map[string]client.Object)
-	wr["DaemonSet"] = &appsv1.DaemonSet{}
-	wr["Deployment"] = &appsv1.Deployment{}
-	wr["ConfigMap"] = &v1.ConfigMap{}
+func (s *stateNVIPAMCNI) GetWatchSources() map[string]WatchSource {
+	wr := make(map[string]WatchSource)
+	wr["DaemonSet"] = daemonSetsWatchSource(s.name)
+	wr["Deployment"] = deploymentsWatchSource(s.name)
+	wr["ConfigMap"] = stateObjectsWatchSource(&v1.ConfigMap{}, s.name)
 	return wr
 }
 
 func (s *stateNVIPAMCNI) GetManifestObjects(
-	_ context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
+	ctx context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
 	catalog InfoCatalog, reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
 	if cr == nil || cr.Spec.NvIpam == nil {
 		return nil, errors.New("failed to render objects: state spec is nil")
@@ -154,23 +154,33 @@ func (s *stateNVIPAMCNI) GetManifestObjects(
 	}
 	renderData := &NVIPAMManifestRenderData{
 		CrSpec:       cr.Spec.NvIpam,
-		NodeAffinity: cr.Spec.NodeAffinity,
-		Tolerations:  cr.Spec.Tolerations,
+		NodeAffinity: getNodeAffinity(cr.Spec.NodeAffinity, cr.Spec.NvIpam.NodeAffinity),
+		NodeSelector: cr.Spec.NvIpam.NodeSelector,
+		Tolerations:  getTolerations(cr.Spec.Tolerations, cr.Spec.NvIpam.Tolerations),
 		RuntimeSpec: &cniRuntimeSpec{
 			runtimeSpec:        runtimeSpec{Namespace: config.FromEnv().State.NetworkOperatorResourceNamespace},
 			IsOpenshift:        clusterInfo.IsOpenshift(),
-			CniBinDirectory:    utils.GetCniBinDirectory(staticConfig, clusterInfo),
+			CniBinDirectory:    utils.GetCniBinDirectory(staticConfig, clusterInfo, cr.Spec.CNIDirectories),
+			CniConfDirectory:   utils.GetCniConfDirectory(staticConfig, cr.Spec.CNIDirectories),
 			ContainerResources: createContainerResourcesMap(cr.Spec.NvIpam.ContainerResources),
 		},
+		ImagePullSecrets: mellanoxv1alpha1.MergeImagePullSecrets(
+			cr.Spec.ImagePullSecrets, cr.Spec.NvIpam.ImagePullSecrets),
 	}
 
 	// render objects
 	reqLogger.V(consts.LogLevelDebug).Info("Rendering objects", "data:", renderData)
-	objs, err := s.renderer.RenderObjects(&render.TemplatingData{Data: renderData})
+	objs, err := s.renderer.RenderObjects(ctx, &render.TemplatingData{Data: renderData})
 
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to render objects")
 	}
+	if err := setPriorityClassName(objs, &cr.Spec, cr.Spec.NvIpam.PriorityClassName); err != nil {
+		return nil, err
+	}
+	if err := setSecurityContext(objs, cr.Spec.NvIpam.SecurityContext); err != nil {
+		return nil, err
+	}
 
 	reqLogger.V(consts.LogLevelDebug).Info("Rendered", "objects:", objs)
 	return objs, nil
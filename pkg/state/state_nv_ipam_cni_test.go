@@ -21,7 +21,9 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/Mellanox/network-operator/pkg/state"
 
@@ -50,4 +52,48 @@ var _ = Describe("NVIPAM Controller", func() {
 	It("should test that manifests are rendered and fields are set correctly", func() {
 		GetManifestObjectsTest(ctx, cr, catalog, imageSpec, s)
 	})
+	It("should render the IP pools", func() {
+		poolsCR := cr.DeepCopy()
+		poolsCR.Spec.NvIpam.Pools = []mellanoxv1alpha1.NVIPAMPoolSpec{
+			{Name: "pool1", Subnet: "192.168.0.0/24", PerNodeBlockSize: 16, Gateway: "192.168.0.1"},
+			{Name: "pool2", Subnet: "192.168.1.0/24", PerNodeBlockSize: 32, Gateway: "192.168.1.1",
+				NodeSelector: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: "node-label", Operator: corev1.NodeSelectorOpExists}}}}}},
+		}
+		objs, err := s.GetManifestObjects(ctx, poolsCR, catalog, log.FromContext(ctx))
+		Expect(err).NotTo(HaveOccurred())
+		pools := map[string]map[string]interface{}{}
+		for _, obj := range objs {
+			if obj.GetKind() == "IPPool" {
+				Expect(obj.GetNamespace()).To(Equal("nvidia-network-operator"))
+				pools[obj.GetName()] = obj.Object["spec"].(map[string]interface{})
+			}
+		}
+		Expect(pools).To(HaveLen(2))
+		Expect(pools["pool1"]).To(Equal(map[string]interface{}{
+			"subnet": "192.168.0.0/24", "perNodeBlockSize": int64(16), "gateway": "192.168.0.1"}))
+		Expect(pools["pool2"]).To(HaveKey("nodeSelector"))
+	})
+	It("should render the CIDR pools", func() {
+		gatewayIndex := int32(0)
+		poolsCR := cr.DeepCopy()
+		poolsCR.Spec.NvIpam.CIDRPools = []mellanoxv1alpha1.NVIPAMCIDRPoolSpec{{
+			Name: "pool1", CIDR: "10.0.0.0/16", PerNodeNetworkPrefix: 24, GatewayIndex: &gatewayIndex,
+			Exclusions: []mellanoxv1alpha1.NVIPAMExcludeRangeSpec{{StartIP: "10.0.0.10", EndIP: "10.0.0.20"}},
+		}}
+		objs, err := s.GetManifestObjects(ctx, poolsCR, catalog, log.FromContext(ctx))
+		Expect(err).NotTo(HaveOccurred())
+		var spec map[string]interface{}
+		for _, obj := range objs {
+			if obj.GetKind() == "CIDRPool" {
+				Expect(obj.GetName()).To(Equal("pool1"))
+				spec = obj.Object["spec"].(map[string]interface{})
+			}
+		}
+		Expect(spec).To(Equal(map[string]interface{}{
+			"cidr": "10.0.0.0/16", "perNodeNetworkPrefix": int64(24), "gatewayIndex": int64(0),
+			"exclusions": []interface{}{map[string]interface{}{"startIP": "10.0.0.10", "endIP": "10.0.0.20"}},
+		}))
+	})
 })
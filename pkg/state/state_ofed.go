@@ -22,7 +22,6 @@ import (
 	"hash/fnv"
 	"path/filepath"
 	"sort"
-	"strings"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
@@ -31,7 +30,6 @@ import (
 	osconfigv1 "github.com/openshift/api/config/v1"
 	apiimagev1 "github.com/openshift/api/image/v1"
 	"github.com/pkg/errors"
-	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -51,7 +49,6 @@ import (
 	"github.com/Mellanox/network-operator/pkg/docadriverimages"
 	"github.com/Mellanox/network-operator/pkg/nodeinfo"
 	"github.com/Mellanox/network-operator/pkg/render"
-	"github.com/Mellanox/network-operator/pkg/utils"
 )
 
 const (
@@ -129,12 +126,11 @@ var ConfigMapKeysOverride = map[string]map[string]string{
 // NewStateOFED creates a new OFED driver state
 func NewStateOFED(
 	k8sAPIClient client.Client, manifestDir string) (State, ManifestRenderer, error) {
-	files, err := utils.GetFilesWithSuffix(manifestDir, render.ManifestFileSuffix...)
+	renderer, err := newManifestRenderer(k8sAPIClient, manifestDir)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to get files from manifest dir")
 	}
 
-	renderer := render.NewRenderer(files)
 	state := &stateOFED{
 		stateSkel: stateSkel{
 			name:        stateOFEDName,
@@ -181,7 +177,9 @@ type ofedRuntimeSpec struct {
 type ofedManifestRenderData struct {
 	CrSpec                 *mellanoxv1alpha1.OFEDDriverSpec
 	Tolerations            []v1.Toleration
+	ImagePullSecrets       []string
 	NodeAffinity           *v1.NodeAffinity
+	NodeSelector           map[string]string
 	RuntimeSpec            *ofedRuntimeSpec
 	AdditionalVolumeMounts additionalVolumeMounts
 }
@@ -298,7 +296,7 @@ func (s *stateOFED) Sync(ctx context.Context, customResource interface{}, infoCa
 	objs, err := s.GetManifestObjects(ctx, cr, infoCatalog, log.FromContext(ctx))
 
 	if err != nil {
-		return SyncStateNotReady, errors.Wrap(err, "failed to create k8s objects from manifest")
+		return SyncStateNotReady, &RenderError{Err: errors.Wrap(err, "failed to create k8s objects from manifest")}
 	}
 	if len(objs) == 0 {
 		// GetManifestObjects returned no objects, this means that no objects need to be applied to the cluster
@@ -313,7 +311,7 @@ func (s *stateOFED) Sync(ctx context.Context, customResource interface{}, infoCa
 			return errors.Wrap(err, "failed to set controller reference for object")
 		}
 		return nil
-	}, objs)
+	}, objs, cr.Spec.RawPatches)
 	if err != nil {
 		return SyncStateNotReady, errors.Wrap(err, "failed to create/update objects")
 	}
@@ -333,9 +331,9 @@ func (s *stateOFED) Sync(ctx context.Context, customResource interface{}, infoCa
 }
 
 // GetWatchSources returns map of source kinds that should be watched for the state keyed by the source kind name
-func (s *stateOFED) GetWatchSources() map[string]client.Object {
-	wr := make(map[string]client.Object)
-	wr["DaemonSet"] = &appsv1.DaemonSet{}
+func (s *stateOFED) GetWatchSources() map[string]WatchSource {
+	wr := make(map[string]WatchSource)
+	wr["DaemonSet"] = daemonSetsWatchSource(s.name)
 	return wr
 }
 
@@ -354,7 +352,9 @@ func (s *stateOFED) handleOpenshiftClusterWideProxyConfig(
 		return nil
 	}
 
-	s.setEnvFromClusterWideProxy(cr, clusterWideProxyConfig)
+	if cr.Spec.Proxy == nil {
+		s.setEnvFromClusterWideProxy(cr, clusterWideProxyConfig)
+	}
 
 	if cr.Spec.OFEDDriver.CertConfig != nil && cr.Spec.OFEDDriver.CertConfig.Name != "" {
 		// CA certificate configMap explicitly set in NicClusterPolicy, ignore CA settings
@@ -419,9 +419,14 @@ func (s *stateOFED) GetManifestObjects(
 		return []*unstructured.Unstructured{}, nil
 	}
 
+	if err := resolveOFEDVersion(cr, docaProvider, reqLogger); err != nil {
+		return nil, err
+	}
+	applyOFEDVersionRollback(cr, reqLogger)
+
 	setProbesDefaults(cr)
-	// Update MOFED Env variables with defaults for the cluster
-	cr.Spec.OFEDDriver.Env = s.mergeWithDefaultEnvs(cr.Spec.OFEDDriver.Env)
+	// Update MOFED Env variables with the proxy settings and defaults for the cluster
+	cr.Spec.OFEDDriver.Env = s.mergeWithDefaultEnvs(addProxyEnvs(cr.Spec.OFEDDriver.Env, cr.Spec.Proxy))
 
 	objs := make([]*unstructured.Unstructured, 0)
 	renderedObjsMap := stateObjects{}
@@ -450,10 +455,13 @@ func (s *stateOFED) GetManifestObjects(
 func renderObjects(ctx context.Context, nodePool *nodeinfo.NodePool, useDtk bool, s *stateOFED,
 	cr *mellanoxv1alpha1.NicClusterPolicy, reqLogger logr.Logger,
 	clusterInfo clustertype.Provider, docaProvider docadriverimages.Provider) ([]*unstructured.Unstructured, error) {
-	precompiledTag := fmt.Sprintf(precompiledTagFormat, cr.Spec.OFEDDriver.Version, nodePool.Kernel,
-		nodePool.OsName, nodePool.OsVersion, nodePool.Arch)
-	precompiledExists := docaProvider.TagExists(precompiledTag)
-	reqLogger.V(consts.LogLevelDebug).Info("Precompiled tag", "tag:", precompiledTag, "found:", precompiledExists)
+	precompiledExists := false
+	if !cr.Spec.OFEDDriver.DisablePrecompiled {
+		precompiledTag := fmt.Sprintf(precompiledTagFormat, cr.Spec.OFEDDriver.ForArch(nodePool.Arch).Version,
+			nodePool.Kernel, nodePool.OsName, nodePool.OsVersion, nodePool.Arch)
+		precompiledExists = docaProvider.TagExists(precompiledTag)
+		reqLogger.V(consts.LogLevelDebug).Info("Precompiled tag", "tag:", precompiledTag, "found:", precompiledExists)
+	}
 	if !precompiledExists && cr.Spec.OFEDDriver.ForcePrecompiled {
 		return nil, fmt.Errorf("ForcePrecompiled is enabled and precompiled image was not found")
 	}
@@ -508,14 +516,75 @@ func renderObjects(ctx context.Context, nodePool *nodeinfo.NodePool, useDtk bool
 			DtkImageName:       dtkImageName,
 			RhcosVersion:       rhcosVersion,
 		},
-		Tolerations:            cr.Spec.Tolerations,
-		NodeAffinity:           cr.Spec.NodeAffinity,
+		Tolerations:            getTolerations(cr.Spec.Tolerations, cr.Spec.OFEDDriver.Tolerations),
+		NodeAffinity:           getNodeAffinity(cr.Spec.NodeAffinity, cr.Spec.OFEDDriver.NodeAffinity),
+		NodeSelector:           cr.Spec.OFEDDriver.NodeSelector,
 		AdditionalVolumeMounts: additionalVolMounts,
+		ImagePullSecrets: mellanoxv1alpha1.MergeImagePullSecrets(
+			cr.Spec.ImagePullSecrets, cr.Spec.OFEDDriver.ImagePullSecrets),
 	}
 
 	reqLogger.V(consts.LogLevelDebug).Info("Rendering objects", "data:", renderData)
-	renderedObjs, err := s.renderer.RenderObjects(&render.TemplatingData{Data: renderData})
-	return renderedObjs, err
+	renderedObjs, err := s.renderer.RenderObjects(ctx, &render.TemplatingData{Data: renderData})
+	if err != nil {
+		return nil, err
+	}
+	if err := setPriorityClassName(renderedObjs, &cr.Spec, cr.Spec.OFEDDriver.PriorityClassName); err != nil {
+		return nil, err
+	}
+	if err := setSecurityContext(renderedObjs, cr.Spec.OFEDDriver.SecurityContext); err != nil {
+		return nil, err
+	}
+	if len(cr.Spec.OFEDDriver.ArchImages.Archs()) == 0 {
+		return renderedObjs, nil
+	}
+	// the images differ per architecture, the DaemonSet of the pool must not run on the nodes of other architectures
+	for _, obj := range renderedObjs {
+		if obj.GetKind() != "DaemonSet" {
+			continue
+		}
+		if err := restrictToArchs(obj, v1.NodeSelectorOpIn, []string{nodePool.Arch}); err != nil {
+			return nil, err
+		}
+	}
+	return renderedObjs, nil
+}
+
+// resolveOFEDVersion pins the OFED driver version to the newest version available in the container registry
+// if the version set in the CR is a version channel, the resolved version is recorded in the CR status
+func resolveOFEDVersion(cr *mellanoxv1alpha1.NicClusterPolicy, docaProvider docadriverimages.Provider,
+	reqLogger logr.Logger) error {
+	channel := cr.Spec.OFEDDriver.Version
+	if !docadriverimages.IsVersionChannel(channel) {
+		cr.Status.OFEDDriverVersion = ""
+		return nil
+	}
+	version, found := docaProvider.ResolveVersion(channel)
+	if !found {
+		return fmt.Errorf("failed to resolve OFED version channel %s, no matching driver image found", channel)
+	}
+	reqLogger.V(consts.LogLevelInfo).Info("Resolved OFED version channel", "channel", channel, "version", version)
+	cr.Spec.OFEDDriver.Version = version
+	cr.Status.OFEDDriverVersion = version
+	return nil
+}
+
+// applyOFEDVersionRollback deploys the OFED driver version recorded in the rollback status of the CR
+// as long as the failed version is set in the CR, the rollback is dropped once the version in the CR is changed
+func applyOFEDVersionRollback(cr *mellanoxv1alpha1.NicClusterPolicy, reqLogger logr.Logger) {
+	rollback := cr.Status.OFEDDriverRollback
+	if rollback == nil {
+		return
+	}
+	if rollback.FailedVersion != cr.Spec.OFEDDriver.Version {
+		reqLogger.V(consts.LogLevelInfo).Info("OFED version changed, dropping the rollback",
+			"failedVersion", rollback.FailedVersion, "version", cr.Spec.OFEDDriver.Version)
+		cr.Status.OFEDDriverRollback = nil
+		return
+	}
+	reqLogger.V(consts.LogLevelInfo).Info("OFED version is rolled back",
+		"failedVersion", rollback.FailedVersion, "version", rollback.Version)
+	cr.Spec.OFEDDriver.Version = rollback.Version
 }
 
 func getProviders(catalog InfoCatalog) (nodeinfo.Provider, clustertype.Provider, docadriverimages.Provider, error) {
@@ -567,15 +636,16 @@ func (s *stateOFED) getMofedDriverImageName(cr *mellanoxv1alpha1.NicClusterPolic
 	}
 	reqLogger.V(consts.LogLevelDebug).Info("Generating ofed driver image name for version: %v", "version", curDriverVer)
 
+	image := cr.Spec.OFEDDriver.ForArch(pool.Arch)
 	if precompiledExists {
 		return fmt.Sprintf(precompiledImageFormat,
-			cr.Spec.OFEDDriver.Repository, cr.Spec.OFEDDriver.Image,
-			cr.Spec.OFEDDriver.Version, pool.Kernel,
+			image.Repository, image.Image,
+			image.Version, pool.Kernel,
 			pool.OsName, pool.OsVersion, pool.Arch)
 	}
 	return fmt.Sprintf(mofedImageFormat,
-		cr.Spec.OFEDDriver.Repository, cr.Spec.OFEDDriver.Image,
-		cr.Spec.OFEDDriver.Version,
+		image.Repository, image.Image,
+		image.Version,
 		pool.OsName,
 		pool.OsVersion,
 		pool.Arch)
@@ -679,33 +749,11 @@ func (s *stateOFED) getOrCreateTrustedCAConfigMap(
 // setEnvFromClusterWideProxy set proxy env variables from cluster wide proxy in OCP
 // values which already configured in NicClusterPolicy take precedence
 func (s *stateOFED) setEnvFromClusterWideProxy(cr *mellanoxv1alpha1.NicClusterPolicy, proxyConfig *osconfigv1.Proxy) {
-	// use [][]string to preserve order of env variables
-	proxiesParams := [][]string{
-		{envVarNameHTTPSProxy, proxyConfig.Spec.HTTPSProxy},
-		{envVarNameHTTPProxy, proxyConfig.Spec.HTTPProxy},
-		{envVarNameNoProxy, proxyConfig.Spec.NoProxy},
-	}
-	envsFromStaticCfg := map[string]v1.EnvVar{}
-	for _, e := range cr.Spec.OFEDDriver.Env {
-		envsFromStaticCfg[e.Name] = e
-	}
-	for _, param := range proxiesParams {
-		envKey, envValue := param[0], param[1]
-		if envValue == "" {
-			continue
-		}
-		_, upperCaseExist := envsFromStaticCfg[strings.ToUpper(envKey)]
-		_, lowerCaseExist := envsFromStaticCfg[strings.ToLower(envKey)]
-		if upperCaseExist || lowerCaseExist {
-			// environment variable statically configured in NicClusterPolicy
-			continue
-		}
-		// add proxy settings in both cases for compatibility
-		cr.Spec.OFEDDriver.Env = append(cr.Spec.OFEDDriver.Env,
-			v1.EnvVar{Name: strings.ToUpper(envKey), Value: envValue},
-			v1.EnvVar{Name: strings.ToLower(envKey), Value: envValue},
-		)
-	}
+	cr.Spec.OFEDDriver.Env = addProxyEnvs(cr.Spec.OFEDDriver.Env, &mellanoxv1alpha1.ProxySpec{
+		HTTPProxy:  proxyConfig.Spec.HTTPProxy,
+		HTTPSProxy: proxyConfig.Spec.HTTPSProxy,
+		NoProxy:    proxyConfig.Spec.NoProxy,
+	})
 }
 
 // mergeWithDefaultEnvs returns env variables provided in currentEnvs merged with default
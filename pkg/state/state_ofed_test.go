@@ -33,6 +33,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/Mellanox/network-operator/api/v1alpha1"
@@ -73,13 +74,18 @@ func (d *openShiftClusterProvider) IsOpenshift() bool {
 }
 
 type dummyOfedImageProvider struct {
-	tagExists bool
+	tagExists       bool
+	resolvedVersion string
 }
 
 func (d *dummyOfedImageProvider) TagExists(_ string) bool {
 	return d.tagExists
 }
 
+func (d *dummyOfedImageProvider) ResolveVersion(_ string) (string, bool) {
+	return d.resolvedVersion, d.resolvedVersion != ""
+}
+
 func (d *dummyOfedImageProvider) SetImageSpec(*v1alpha1.ImageSpec) {}
 
 var _ = Describe("MOFED state test", func() {
@@ -201,10 +207,10 @@ var _ = Describe("MOFED state test", func() {
 		It("NicClusterPolicy proxy settings should have precedence", func() {
 			cr := &v1alpha1.NicClusterPolicy{
 				Spec: v1alpha1.NicClusterPolicySpec{OFEDDriver: &v1alpha1.OFEDDriverSpec{
-					Env: []v1.EnvVar{
+					ImageSpec: v1alpha1.ImageSpec{Env: []v1.EnvVar{
 						{Name: envVarNameNoProxy, Value: testNicPolicyNoProxy},
 						{Name: strings.ToLower(envVarNameHTTPProxy), Value: testNicPolicyHTTPProxy},
-					},
+					}},
 				}}}
 			clusterProxy := &osconfigv1.Proxy{
 				Spec: osconfigv1.ProxySpec{
@@ -223,6 +229,16 @@ var _ = Describe("MOFED state test", func() {
 				v1.EnvVar{Name: strings.ToLower(envVarNameHTTPSProxy), Value: testClusterWideHTTPSProxy},
 			))
 		})
+		It("Set Proxy from NicClusterPolicy proxy settings", func() {
+			envs := addProxyEnvs([]v1.EnvVar{{Name: envVarNameNoProxy, Value: testNicPolicyNoProxy}},
+				&v1alpha1.ProxySpec{HTTPProxy: testNicPolicyHTTPProxy, NoProxy: testClusterWideNoProxy})
+			Expect(envs).To(Equal([]v1.EnvVar{
+				{Name: envVarNameNoProxy, Value: testNicPolicyNoProxy},
+				{Name: envVarNameHTTPProxy, Value: testNicPolicyHTTPProxy},
+				{Name: strings.ToLower(envVarNameHTTPProxy), Value: testNicPolicyHTTPProxy},
+			}))
+			Expect(addProxyEnvs(envs, nil)).To(Equal(envs))
+		})
 	})
 
 	DescribeTable("mergeWithDefaultEnvs",
@@ -326,6 +342,49 @@ var _ = Describe("MOFED state test", func() {
 				verifyPodAntiInfinity(ds.Spec.Template.Spec.Affinity)
 			}
 		})
+		It("Should Render DaemonSet per OS version", func() {
+			ofedState := getOfedState()
+			cr := &v1alpha1.NicClusterPolicy{}
+			cr.Name = "nic-cluster-policy"
+			cr.Spec.OFEDDriver = &v1alpha1.OFEDDriverSpec{
+				ImageSpec: v1alpha1.ImageSpec{
+					Image:      "mofed",
+					Repository: "nvcr.io/mellanox",
+					Version:    "23.10-0.5.5.0",
+				},
+			}
+
+			By("Creating NodeProvider with 2 Nodes with the same kernel and different OS versions")
+			node := getNode("node2", kernelFull1)
+			node.Labels[nodeinfo.NodeLabelOSVer] = "20.04"
+			infoProvider := nodeinfo.NewProvider([]*v1.Node{getNode("node1", kernelFull1), node})
+			catalog := NewInfoCatalog()
+			catalog.Add(InfoTypeClusterType, &dummyProvider{})
+			catalog.Add(InfoTypeNodeInfo, infoProvider)
+			catalog.Add(InfoTypeDocaDriverImage, &dummyOfedImageProvider{tagExists: false})
+			objs, err := ofedState.GetManifestObjects(ctx, cr, catalog, testLogger)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Verify DaemonSets image and NodeSelector")
+			images := map[string]string{}
+			for _, obj := range objs {
+				if obj.GetKind() != "DaemonSet" {
+					continue
+				}
+				ds := appsv1.DaemonSet{}
+				err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &ds)
+				Expect(err).NotTo(HaveOccurred())
+				ver := ds.Spec.Template.Spec.NodeSelector["feature.node.kubernetes.io/system-os_release.VERSION_ID"]
+				Expect(ds.Name).To(Equal(fmt.Sprintf("mofed-%s%s-%s-ds", osName, ver, "54669c9886")))
+				images[ver] = ds.Spec.Template.Spec.Containers[0].Image
+			}
+			Expect(images).To(Equal(map[string]string{
+				"22.04": fmt.Sprintf(mofedImageFormat, "nvcr.io/mellanox", "mofed", "23.10-0.5.5.0",
+					osName, "22.04", archAmd),
+				"20.04": fmt.Sprintf(mofedImageFormat, "nvcr.io/mellanox", "mofed", "23.10-0.5.5.0",
+					osName, "20.04", archAmd),
+			}))
+		})
 	})
 	Context("Render Manifests DTK", func() {
 		It("Should Render DaemonSet with DTK and additional mounts", func() {
@@ -390,6 +449,12 @@ var _ = Describe("MOFED state test", func() {
 					Image:      "mofed",
 					Repository: "nvcr.io/mellanox",
 					Version:    "23.10-0.5.5.0",
+					Env: []v1.EnvVar{
+						{
+							Name:  "ENTRYPOINT_DEBUG",
+							Value: "true",
+						},
+					},
 				},
 				RepoConfig: &v1alpha1.ConfigMapNameReference{
 					Name: "repo-cm",
@@ -397,12 +462,6 @@ var _ = Describe("MOFED state test", func() {
 				CertConfig: &v1alpha1.ConfigMapNameReference{
 					Name: "cert-cm",
 				},
-				Env: []v1.EnvVar{
-					{
-						Name:  "ENTRYPOINT_DEBUG",
-						Value: "true",
-					},
-				},
 			}
 			By("Creating NodeProvider with 1 Node with RHCOS OS TREE label")
 			node := getNode("node1", kernelFull1)
@@ -539,6 +598,144 @@ var _ = Describe("MOFED state test", func() {
 				Expect(ds.Spec.Template.Spec.Containers[0].Image).To(Equal(precompiledImage))
 			}
 		})
+		It("Should use image with sources format, disablePrecompiled true and tag exists", func() {
+			ofedState := getOfedState()
+			cr := &v1alpha1.NicClusterPolicy{}
+			cr.Name = "nic-cluster-policy"
+			cr.Spec.OFEDDriver = &v1alpha1.OFEDDriverSpec{
+				ImageSpec: v1alpha1.ImageSpec{
+					Image:      "mofed",
+					Repository: "nvcr.io/mellanox",
+					Version:    "23.10-0.5.5.0",
+				},
+				DisablePrecompiled: true,
+			}
+			By("Creating NodeProvider with 1 Node, that form 1 Node pool")
+			infoProvider := nodeinfo.NewProvider([]*v1.Node{
+				getNode("node1", kernelFull1),
+			})
+			catalog := NewInfoCatalog()
+			catalog.Add(InfoTypeClusterType, &dummyProvider{})
+			catalog.Add(InfoTypeNodeInfo, infoProvider)
+			catalog.Add(InfoTypeDocaDriverImage, &dummyOfedImageProvider{tagExists: true})
+			objs, err := ofedState.GetManifestObjects(ctx, cr, catalog, testLogger)
+			Expect(err).NotTo(HaveOccurred())
+			By("Verify image is not precompiled format")
+			// Expect 4 objects: DS , Service Account, Role, RoleBinding
+			Expect(len(objs)).To(Equal(4))
+			for _, obj := range objs {
+				if obj.GetKind() != "DaemonSet" {
+					continue
+				}
+				ds := appsv1.DaemonSet{}
+				err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &ds)
+				Expect(err).NotTo(HaveOccurred())
+				withSourceImage := fmt.Sprintf(mofedImageFormat,
+					cr.Spec.OFEDDriver.Repository, cr.Spec.OFEDDriver.Image, cr.Spec.OFEDDriver.Version,
+					osName, osVer, archAmd)
+				Expect(ds.Spec.Template.Spec.Containers[0].Image).To(Equal(withSourceImage))
+			}
+		})
+	})
+	Context("Version channel", func() {
+		It("Should use resolved version and record it in status", func() {
+			ofedState := getOfedState()
+			cr := &v1alpha1.NicClusterPolicy{}
+			cr.Name = "nic-cluster-policy"
+			cr.Spec.OFEDDriver = &v1alpha1.OFEDDriverSpec{
+				ImageSpec: v1alpha1.ImageSpec{
+					Image:      "mofed",
+					Repository: "nvcr.io/mellanox",
+					Version:    "latest-24.xx",
+				},
+			}
+			infoProvider := nodeinfo.NewProvider([]*v1.Node{
+				getNode("node1", kernelFull1),
+			})
+			catalog := NewInfoCatalog()
+			catalog.Add(InfoTypeClusterType, &dummyProvider{})
+			catalog.Add(InfoTypeNodeInfo, infoProvider)
+			catalog.Add(InfoTypeDocaDriverImage, &dummyOfedImageProvider{resolvedVersion: "24.04-0.6.6.0-0"})
+			objs, err := ofedState.GetManifestObjects(ctx, cr, catalog, testLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cr.Status.OFEDDriverVersion).To(Equal("24.04-0.6.6.0-0"))
+			Expect(runFuncForObjectInSlice(objs, "DaemonSet", func(obj *unstructured.Unstructured) {
+				ds := appsv1.DaemonSet{}
+				err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &ds)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ds.Spec.Template.Spec.Containers[0].Image).To(Equal(fmt.Sprintf(mofedImageFormat,
+					"nvcr.io/mellanox", "mofed", "24.04-0.6.6.0-0", osName, osVer, archAmd)))
+			})).To(BeTrue())
+		})
+		It("Should fail getManifestObjects, version channel can't be resolved", func() {
+			ofedState := getOfedState()
+			cr := &v1alpha1.NicClusterPolicy{}
+			cr.Name = "nic-cluster-policy"
+			cr.Spec.OFEDDriver = &v1alpha1.OFEDDriverSpec{
+				ImageSpec: v1alpha1.ImageSpec{
+					Image:      "mofed",
+					Repository: "nvcr.io/mellanox",
+					Version:    "latest",
+				},
+			}
+			infoProvider := nodeinfo.NewProvider([]*v1.Node{
+				getNode("node1", kernelFull1),
+			})
+			catalog := NewInfoCatalog()
+			catalog.Add(InfoTypeClusterType, &dummyProvider{})
+			catalog.Add(InfoTypeNodeInfo, infoProvider)
+			catalog.Add(InfoTypeDocaDriverImage, &dummyOfedImageProvider{})
+			_, err := ofedState.GetManifestObjects(ctx, cr, catalog, testLogger)
+			Expect(err).To(MatchError(ContainSubstring("failed to resolve OFED version channel latest")))
+		})
+	})
+	Context("Version rollback", func() {
+		var cr *v1alpha1.NicClusterPolicy
+		var catalog InfoCatalog
+
+		BeforeEach(func() {
+			cr = &v1alpha1.NicClusterPolicy{}
+			cr.Name = "nic-cluster-policy"
+			cr.Spec.OFEDDriver = &v1alpha1.OFEDDriverSpec{
+				ImageSpec: v1alpha1.ImageSpec{
+					Image:      "mofed",
+					Repository: "nvcr.io/mellanox",
+					Version:    "24.04-0.6.6.0-0",
+				},
+			}
+			cr.Status.OFEDDriverRollback = &v1alpha1.OFEDDriverRollbackStatus{
+				FailedVersion: "24.04-0.6.6.0-0", Version: "24.01-0.3.3.1-0", Reason: "upgrade failed"}
+			catalog = NewInfoCatalog()
+			catalog.Add(InfoTypeClusterType, &dummyProvider{})
+			catalog.Add(InfoTypeNodeInfo, nodeinfo.NewProvider([]*v1.Node{getNode("node1", kernelFull1)}))
+			catalog.Add(InfoTypeDocaDriverImage, &dummyOfedImageProvider{})
+		})
+
+		getDaemonSetImage := func(objs []*unstructured.Unstructured) string {
+			image := ""
+			Expect(runFuncForObjectInSlice(objs, "DaemonSet", func(obj *unstructured.Unstructured) {
+				ds := appsv1.DaemonSet{}
+				Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &ds)).To(Succeed())
+				image = ds.Spec.Template.Spec.Containers[0].Image
+			})).To(BeTrue())
+			return image
+		}
+
+		It("Should deploy the rolled back version while the failed version is set", func() {
+			objs, err := getOfedState().GetManifestObjects(ctx, cr, catalog, testLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getDaemonSetImage(objs)).To(Equal(fmt.Sprintf(mofedImageFormat,
+				"nvcr.io/mellanox", "mofed", "24.01-0.3.3.1-0", osName, osVer, archAmd)))
+			Expect(cr.Status.OFEDDriverRollback).NotTo(BeNil())
+		})
+		It("Should drop the rollback once the version is changed", func() {
+			cr.Spec.OFEDDriver.Version = "24.07-0.6.1.0-0"
+			objs, err := getOfedState().GetManifestObjects(ctx, cr, catalog, testLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getDaemonSetImage(objs)).To(Equal(fmt.Sprintf(mofedImageFormat,
+				"nvcr.io/mellanox", "mofed", "24.07-0.6.1.0-0", osName, osVer, archAmd)))
+			Expect(cr.Status.OFEDDriverRollback).To(BeNil())
+		})
 	})
 })
 
@@ -0,0 +1,155 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/clustertype"
+	"github.com/Mellanox/network-operator/pkg/config"
+	"github.com/Mellanox/network-operator/pkg/consts"
+	"github.com/Mellanox/network-operator/pkg/render"
+)
+
+// NewStateOpenshift creates a new state for the OpenShift specific objects of the components,
+// the SecurityContextConstraints of their service accounts and the MachineConfig which loads kernel modules
+func NewStateOpenshift(
+	k8sAPIClient client.Client, manifestDir string) (State, ManifestRenderer, error) {
+	renderer, err := newManifestRenderer(k8sAPIClient, manifestDir)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get files from manifest dir")
+	}
+
+	state := &stateOpenshift{
+		stateSkel: stateSkel{
+			name:        "state-openshift",
+			description: "OpenShift SecurityContextConstraints and MachineConfig deployed in the cluster",
+			client:      k8sAPIClient,
+			renderer:    renderer,
+		}}
+	return state, state, nil
+}
+
+type stateOpenshift struct {
+	stateSkel
+}
+
+type openshiftManifestRenderData struct {
+	CrSpec      *mellanoxv1alpha1.MachineConfigSpec
+	RuntimeSpec *openshiftRuntimeSpec
+}
+
+type openshiftRuntimeSpec struct {
+	runtimeSpec
+	// KernelModulesConfig is the base64 encoded modules-load.d file of the kernel modules of the MachineConfig
+	KernelModulesConfig string
+}
+
+// Sync attempt to get the system to match the desired state which State represent.
+// a sync operation must be relatively short and must not block the execution thread.
+func (s *stateOpenshift) Sync(
+	ctx context.Context, customResource interface{}, infoCatalog InfoCatalog) (SyncState, error) {
+	reqLogger := log.FromContext(ctx)
+	cr := customResource.(*mellanoxv1alpha1.NicClusterPolicy)
+	reqLogger.V(consts.LogLevelInfo).Info(
+		"Sync Custom resource", "State:", s.name, "Name:", cr.Name, "Namespace:", cr.Namespace)
+
+	staticConfig := infoCatalog.GetStaticConfigProvider()
+	if staticConfig == nil {
+		return SyncStateError, errors.New("unexpected state, catalog does not provide static info")
+	}
+	if staticConfig.GetStaticConfig().ClusterType != clustertype.Openshift {
+		// the objects are OpenShift specific, remove them if the state was synced before
+		return s.handleStateObjectsDeletion(ctx)
+	}
+
+	objs, err := s.GetManifestObjects(ctx, cr, infoCatalog, reqLogger)
+	if err != nil {
+		return SyncStateNotReady, &RenderError{Err: errors.Wrap(err, "failed to create k8s objects from manifest")}
+	}
+
+	// Create objects if they dont exist, Update objects if they do exist
+	err = s.createOrUpdateObjs(ctx, func(obj *unstructured.Unstructured) error {
+		if err := controllerutil.SetControllerReference(cr, obj, s.client.Scheme()); err != nil {
+			return errors.Wrap(err, "failed to set controller reference for object")
+		}
+		return nil
+	}, objs, cr.Spec.RawPatches)
+	if err != nil {
+		return SyncStateNotReady, errors.Wrap(err, "failed to create/update objects")
+	}
+	waitForStaleObjectsRemoval, err := s.handleStaleStateObjects(ctx, objs)
+	if err != nil {
+		return SyncStateNotReady, errors.Wrap(err, "failed to handle state stale objects")
+	}
+	if waitForStaleObjectsRemoval {
+		return SyncStateNotReady, nil
+	}
+	return SyncStateReady, nil
+}
+
+// GetWatchSources returns a map of source kinds that should be watched for the state keyed by the source kind name,
+// the SecurityContextConstraints and MachineConfig are not watched as their kinds exist only on OpenShift
+func (s *stateOpenshift) GetWatchSources() map[string]WatchSource {
+	return map[string]WatchSource{}
+}
+
+// GetManifestObjects renders the OpenShift specific objects, no objects are rendered on other clusters
+func (s *stateOpenshift) GetManifestObjects(
+	ctx context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
+	catalog InfoCatalog, reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
+	if cr == nil {
+		return nil, errors.New("failed to render objects: state spec is nil")
+	}
+
+	staticConfig := catalog.GetStaticConfigProvider()
+	if staticConfig == nil {
+		return nil, errors.New("staticConfig provider required")
+	}
+	if staticConfig.GetStaticConfig().ClusterType != clustertype.Openshift {
+		return []*unstructured.Unstructured{}, nil
+	}
+	renderData := &openshiftManifestRenderData{
+		CrSpec: cr.Spec.MachineConfig,
+		RuntimeSpec: &openshiftRuntimeSpec{
+			runtimeSpec: runtimeSpec{config.FromEnv().State.NetworkOperatorResourceNamespace},
+		},
+	}
+	if cr.Spec.MachineConfig != nil {
+		modules := strings.Join(cr.Spec.MachineConfig.KernelModules, "\n") + "\n"
+		renderData.RuntimeSpec.KernelModulesConfig = base64.StdEncoding.EncodeToString([]byte(modules))
+	}
+
+	// render objects
+	reqLogger.V(consts.LogLevelDebug).Info("Rendering objects", "data:", renderData)
+	objs, err := s.renderer.RenderObjects(ctx, &render.TemplatingData{Data: renderData})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render objects")
+	}
+	reqLogger.V(consts.LogLevelDebug).Info("Rendered", "objects:", objs)
+	return objs, nil
+}
@@ -0,0 +1,92 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state_test
+
+import (
+	"context"
+	"encoding/base64"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/clustertype"
+	"github.com/Mellanox/network-operator/pkg/state"
+	"github.com/Mellanox/network-operator/pkg/staticconfig"
+)
+
+var _ = Describe("OpenShift state", func() {
+	var (
+		cr       *mellanoxv1alpha1.NicClusterPolicy
+		renderer state.ManifestRenderer
+	)
+	ctx := context.Background()
+
+	getCatalog := func(clusterType clustertype.Type) state.InfoCatalog {
+		catalog := state.NewInfoCatalog()
+		catalog.Add(state.InfoTypeStaticConfig,
+			staticconfig.NewProvider(staticconfig.StaticConfig{ClusterType: clusterType}))
+		return catalog
+	}
+
+	BeforeEach(func() {
+		cr = getTestClusterPolicyWithBaseFields()
+		var err error
+		_, renderer, err = state.NewStateOpenshift(fake.NewClientBuilder().Build(), "../../manifests/state-openshift")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should not render objects on Kubernetes", func() {
+		cr.Spec.MachineConfig = &mellanoxv1alpha1.MachineConfigSpec{KernelModules: []string{"ib_umad"}}
+		objs, err := renderer.GetManifestObjects(ctx, cr, getCatalog(clustertype.Kubernetes), log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(BeEmpty())
+	})
+
+	It("should render only the SecurityContextConstraints without MachineConfig", func() {
+		objs, err := renderer.GetManifestObjects(ctx, cr, getCatalog(clustertype.Openshift), log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(1))
+		Expect(objs[0].GetKind()).To(Equal("SecurityContextConstraints"))
+		groups, _, err := unstructured.NestedStringSlice(objs[0].Object, "groups")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(groups).To(ConsistOf("system:serviceaccounts:nvidia-network-operator"))
+	})
+
+	It("should render the MachineConfig which loads the kernel modules", func() {
+		cr.Spec.MachineConfig = &mellanoxv1alpha1.MachineConfigSpec{
+			Role: "infra", KernelModules: []string{"ib_umad", "rdma_ucm"}}
+		objs, err := renderer.GetManifestObjects(ctx, cr, getCatalog(clustertype.Openshift), log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(2))
+		mc := objs[1]
+		Expect(mc.GetKind()).To(Equal("MachineConfig"))
+		Expect(mc.GetName()).To(Equal("99-infra-nvidia-network-operator-kernel-modules"))
+		Expect(mc.GetLabels()).To(HaveKeyWithValue("machineconfiguration.openshift.io/role", "infra"))
+		files, _, err := unstructured.NestedSlice(mc.Object, "spec", "config", "storage", "files")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+		source, _, err := unstructured.NestedString(files[0].(map[string]interface{}), "contents", "source")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(source).To(Equal("data:text/plain;charset=utf-8;base64," +
+			base64.StdEncoding.EncodeToString([]byte("ib_umad\nrdma_ucm\n"))))
+	})
+})
@@ -0,0 +1,96 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/render"
+	"github.com/Mellanox/network-operator/pkg/testing/mocks"
+	"github.com/Mellanox/network-operator/pkg/utils"
+)
+
+var _ = Describe("RDMA CNI State tests", func() {
+	var rdmaCniState stateRDMACNI
+	var catalog InfoCatalog
+
+	BeforeEach(func() {
+		client := mocks.ControllerRuntimeClient{}
+		files, err := utils.GetFilesWithSuffix("../../manifests/state-rdma-cni", render.ManifestFileSuffix...)
+		Expect(err).NotTo(HaveOccurred())
+		rdmaCniState = stateRDMACNI{
+			stateSkel: stateSkel{
+				name:        "state-rdma-cni",
+				description: "RDMA CNI deployed in the cluster",
+				client:      &client,
+				renderer:    render.NewRenderer(files),
+			},
+		}
+		catalog = NewInfoCatalog()
+		catalog.Add(InfoTypeStaticConfig, &dummyProvider{})
+		catalog.Add(InfoTypeClusterType, &dummyProvider{})
+	})
+
+	getDaemonSet := func(objs []*unstructured.Unstructured) *appsv1.DaemonSet {
+		ds := &appsv1.DaemonSet{}
+		found := runFuncForObjectInSlice(objs, "DaemonSet", func(obj *unstructured.Unstructured) {
+			Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, ds)).To(Succeed())
+		})
+		Expect(found).To(BeTrue())
+		return ds
+	}
+
+	It("Should render the RDMA CNI DaemonSet", func() {
+		cr := &mellanoxv1alpha1.NicClusterPolicy{}
+		cr.Spec.SecondaryNetwork = &mellanoxv1alpha1.SecondaryNetworkSpec{
+			RdmaCni: &mellanoxv1alpha1.CNIPluginSpec{ImageSpec: mellanoxv1alpha1.ImageSpec{
+				Image:            "rdma-cni",
+				Repository:       "nvcr.io/nvidia/mellanox",
+				Version:          "v1.2.0",
+				ImagePullSecrets: []string{"secret"},
+			}},
+		}
+		objs, err := rdmaCniState.GetManifestObjects(context.TODO(), cr, catalog, testLogger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(objs)).To(Equal(1))
+
+		ds := getDaemonSet(objs)
+		Expect(ds.Name).To(Equal("kube-rdma-cni-ds"))
+		podSpec := ds.Spec.Template.Spec
+		Expect(podSpec.ServiceAccountName).To(BeEmpty())
+		Expect(podSpec.ImagePullSecrets).To(HaveLen(1))
+		Expect(podSpec.ImagePullSecrets[0].Name).To(Equal("secret"))
+		Expect(podSpec.Containers).To(HaveLen(1))
+		Expect(podSpec.Containers[0].Name).To(Equal("rdma-cni"))
+		Expect(podSpec.Containers[0].Image).To(Equal("nvcr.io/nvidia/mellanox/rdma-cni:v1.2.0"))
+		Expect(podSpec.Volumes[0].HostPath.Path).To(Equal("/opt/cni/bin"))
+	})
+
+	It("Should fail to render without RDMA CNI spec", func() {
+		cr := &mellanoxv1alpha1.NicClusterPolicy{}
+		cr.Spec.SecondaryNetwork = &mellanoxv1alpha1.SecondaryNetworkSpec{}
+		_, err := rdmaCniState.GetManifestObjects(context.TODO(), cr, catalog, testLogger)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -21,7 +21,6 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
-	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -32,24 +31,23 @@ import (
 	"github.com/Mellanox/network-operator/pkg/config"
 	"github.com/Mellanox/network-operator/pkg/consts"
 	"github.com/Mellanox/network-operator/pkg/render"
-	"github.com/Mellanox/network-operator/pkg/utils"
 )
 
 // NewStateSharedDp creates a new shared device plugin state
 func NewStateSharedDp(
 	k8sAPIClient client.Client, manifestDir string) (State, ManifestRenderer, error) {
-	files, err := utils.GetFilesWithSuffix(manifestDir, render.ManifestFileSuffix...)
+	renderer, err := newManifestRenderer(k8sAPIClient, manifestDir)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to get files from manifest dir")
 	}
 
-	renderer := render.NewRenderer(files)
 	state := &stateSharedDp{
 		stateSkel: stateSkel{
-			name:        "state-RDMA-device-plugin",
-			description: "RDMA shared device plugin deployed in the cluster",
-			client:      k8sAPIClient,
-			renderer:    renderer,
+			name:         "state-RDMA-device-plugin",
+			description:  "RDMA shared device plugin deployed in the cluster",
+			dependencies: []string{stateOFEDName},
+			client:       k8sAPIClient,
+			renderer:     renderer,
 		}}
 	return state, state, nil
 }
@@ -67,7 +65,9 @@ type sharedDpRuntimeSpec struct {
 type sharedDpManifestRenderData struct {
 	CrSpec              *mellanoxv1alpha1.DevicePluginSpec
 	Tolerations         []v1.Toleration
+	ImagePullSecrets    []string
 	NodeAffinity        *v1.NodeAffinity
+	NodeSelector        map[string]string
 	DeployInitContainer bool
 	RuntimeSpec         *sharedDpRuntimeSpec
 }
@@ -96,7 +96,7 @@ func (s *stateSharedDp) Sync(
 
 	objs, err := s.GetManifestObjects(ctx, cr, infoCatalog, reqLogger)
 	if err != nil {
-		return SyncStateNotReady, errors.Wrap(err, "failed to create k8s objects from manifest")
+		return SyncStateNotReady, &RenderError{Err: errors.Wrap(err, "failed to create k8s objects from manifest")}
 	}
 	if len(objs) == 0 {
 		return SyncStateNotReady, nil
@@ -108,7 +108,7 @@ func (s *stateSharedDp) Sync(
 			return errors.Wrap(err, "failed to set controller reference for object")
 		}
 		return nil
-	}, objs)
+	}, objs, cr.Spec.RawPatches)
 	if err != nil {
 		return SyncStateNotReady, errors.Wrap(err, "failed to create/update objects")
 	}
@@ -128,16 +128,16 @@ func (s *stateSharedDp) Sync(
 }
 
 // Get a map of source kinds that should be watched for the state keyed by the source kind name
-func (s *stateSharedDp) GetWatchSources() map[string]client.Object {
-	wr := make(map[string]client.Object)
-	wr["DaemonSet"] = &appsv1.DaemonSet{}
-	wr["ConfigMap"] = &v1.ConfigMap{}
+func (s *stateSharedDp) GetWatchSources() map[string]WatchSource {
+	wr := make(map[string]WatchSource)
+	wr["DaemonSet"] = daemonSetsWatchSource(s.name)
+	wr["ConfigMap"] = stateObjectsWatchSource(&v1.ConfigMap{}, s.name)
 	return wr
 }
 
 //nolint:dupl
 func (s *stateSharedDp) GetManifestObjects(
-	_ context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
+	ctx context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
 	catalog InfoCatalog, reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
 	if cr == nil || cr.Spec.RdmaSharedDevicePlugin == nil {
 		return nil, errors.New("failed to render objects: state spec is nil")
@@ -149,21 +149,34 @@ func (s *stateSharedDp) GetManifestObjects(
 	}
 	renderData := &sharedDpManifestRenderData{
 		CrSpec:              cr.Spec.RdmaSharedDevicePlugin,
-		Tolerations:         cr.Spec.Tolerations,
-		NodeAffinity:        cr.Spec.NodeAffinity,
+		Tolerations:         getTolerations(cr.Spec.Tolerations, cr.Spec.RdmaSharedDevicePlugin.Tolerations),
+		NodeAffinity:        getNodeAffinity(cr.Spec.NodeAffinity, cr.Spec.RdmaSharedDevicePlugin.NodeAffinity),
+		NodeSelector:        cr.Spec.RdmaSharedDevicePlugin.NodeSelector,
 		DeployInitContainer: cr.Spec.OFEDDriver != nil,
 		RuntimeSpec: &sharedDpRuntimeSpec{
 			runtimeSpec:        runtimeSpec{config.FromEnv().State.NetworkOperatorResourceNamespace},
 			IsOpenshift:        clusterInfo.IsOpenshift(),
 			ContainerResources: createContainerResourcesMap(cr.Spec.RdmaSharedDevicePlugin.ContainerResources),
 		},
+		ImagePullSecrets: mellanoxv1alpha1.MergeImagePullSecrets(
+			cr.Spec.ImagePullSecrets, cr.Spec.RdmaSharedDevicePlugin.ImagePullSecrets),
 	}
 	// render objects
 	reqLogger.V(consts.LogLevelDebug).Info("Rendering objects", "data:", renderData)
-	objs, err := s.renderer.RenderObjects(&render.TemplatingData{Data: renderData})
+	objs, err := s.renderer.RenderObjects(ctx, &render.TemplatingData{Data: renderData})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to render objects")
 	}
+	if err := setPriorityClassName(objs, &cr.Spec, cr.Spec.RdmaSharedDevicePlugin.PriorityClassName); err != nil {
+		return nil, err
+	}
+	if err := setSecurityContext(objs, cr.Spec.RdmaSharedDevicePlugin.SecurityContext); err != nil {
+		return nil, err
+	}
+	objs, err = splitDaemonSetsByArch(objs, &cr.Spec.RdmaSharedDevicePlugin.ImageSpec)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render DaemonSets per CPU architecture")
+	}
 	reqLogger.V(consts.LogLevelDebug).Info("Rendered", "objects:", objs)
 	return objs, nil
 }
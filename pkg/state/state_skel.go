@@ -19,18 +19,28 @@ package state
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
 	"github.com/Mellanox/network-operator/pkg/consts"
 	"github.com/Mellanox/network-operator/pkg/render"
 	"github.com/Mellanox/network-operator/pkg/revision"
@@ -43,6 +53,7 @@ type runtimeSpec struct {
 type cniRuntimeSpec struct {
 	runtimeSpec
 	CniBinDirectory    string
+	CniConfDirectory   string
 	IsOpenshift        bool
 	ContainerResources ContainerResourcesMap
 }
@@ -52,11 +63,20 @@ type cniRuntimeSpec struct {
 type stateSkel struct {
 	name        string
 	description string
+	// names of the states which should be ready before this state is synced
+	dependencies []string
 
 	client   client.Client
 	renderer render.Renderer
 }
 
+// renderedGVKs holds the kinds rendered by each state since the operator started, in addition to
+// getSupportedGVKs they are checked for stale objects of the state, e.g. kinds added by manifest overlays
+var renderedGVKs = struct {
+	sync.Mutex
+	byState map[string]map[schema.GroupVersionKind]struct{}
+}{byState: map[string]map[schema.GroupVersionKind]struct{}{}}
+
 // Name provides the State name
 func (s *stateSkel) Name() string {
 	return s.name
@@ -67,6 +87,13 @@ func (s *stateSkel) Description() string {
 	return s.description
 }
 
+// Dependencies provides the names of the States which should be synced before this State
+func (s *stateSkel) Dependencies() []string {
+	return s.dependencies
+}
+
+// getSupportedGVKs returns the kinds which are checked for stale objects of the states,
+// the kinds of all the objects in the manifests of the states should be listed here
 func getSupportedGVKs() []schema.GroupVersionKind {
 	return []schema.GroupVersionKind{
 		{
@@ -134,6 +161,16 @@ func getSupportedGVKs() []schema.GroupVersionKind {
 			Kind:    "CronJob",
 			Version: "v1",
 		},
+		{
+			Group:   "nv-ipam.nvidia.com",
+			Kind:    "IPPool",
+			Version: "v1alpha1",
+		},
+		{
+			Group:   "nv-ipam.nvidia.com",
+			Kind:    "CIDRPool",
+			Version: "v1alpha1",
+		},
 		{
 			Group:   "cert-manager.io",
 			Kind:    "Issuer",
@@ -144,6 +181,31 @@ func getSupportedGVKs() []schema.GroupVersionKind {
 			Kind:    "Certificate",
 			Version: "v1",
 		},
+		{
+			Group:   "networking.k8s.io",
+			Kind:    "NetworkPolicy",
+			Version: "v1",
+		},
+		{
+			Group:   "monitoring.coreos.com",
+			Kind:    "PodMonitor",
+			Version: "v1",
+		},
+		{
+			Group:   "security.openshift.io",
+			Kind:    "SecurityContextConstraints",
+			Version: "v1",
+		},
+		{
+			Group:   "machineconfiguration.openshift.io",
+			Kind:    "MachineConfig",
+			Version: "v1",
+		},
+		{
+			Group:   "nfd.k8s-sigs.io",
+			Kind:    "NodeFeatureRule",
+			Version: "v1alpha1",
+		},
 	}
 }
 
@@ -160,13 +222,14 @@ func (s *stateSkel) getObj(ctx context.Context, obj *unstructured.Unstructured)
 	return err
 }
 
-func (s *stateSkel) createObj(ctx context.Context, obj *unstructured.Unstructured) error {
+func (s *stateSkel) createObj(ctx context.Context, obj *unstructured.Unstructured, dryRun bool) error {
 	reqLogger := log.FromContext(ctx)
 
 	s.checkDeleteSupported(ctx, obj)
-	reqLogger.V(consts.LogLevelInfo).Info("Creating Object", "Namespace:", obj.GetNamespace(), "Name:", obj.GetName())
+	reqLogger.V(consts.LogLevelInfo).Info("Creating Object", "Namespace:", obj.GetNamespace(), "Name:", obj.GetName(),
+		"DryRun", dryRun)
 	toCreate := obj.DeepCopy()
-	if err := s.client.Create(ctx, toCreate); err != nil {
+	if err := s.writeClient(dryRun).Create(ctx, toCreate); err != nil {
 		if k8serrors.IsAlreadyExists(err) {
 			reqLogger.V(consts.LogLevelInfo).Info("Object Already Exists")
 		}
@@ -189,89 +252,254 @@ func (s *stateSkel) checkDeleteSupported(ctx context.Context, obj *unstructured.
 		"Namespace:", obj.GetNamespace(), "Name:", obj.GetName(), "GVK", obj.GroupVersionKind())
 }
 
-func (s *stateSkel) updateObj(ctx context.Context, obj *unstructured.Unstructured) error {
+func (s *stateSkel) updateObj(ctx context.Context, obj *unstructured.Unstructured, dryRun bool) error {
 	reqLogger := log.FromContext(ctx)
-	reqLogger.V(consts.LogLevelInfo).Info("Updating Object", "Namespace:", obj.GetNamespace(), "Name:", obj.GetName())
+	reqLogger.V(consts.LogLevelInfo).Info("Updating Object", "Namespace:", obj.GetNamespace(), "Name:", obj.GetName(),
+		"DryRun", dryRun)
 
 	// Note: Some objects may require update of the resource version
 	// TODO: using Patch preserves runtime attributes. In the future consider using patch if relevant
 	desired := obj.DeepCopy()
-	if err := s.client.Update(ctx, desired); err != nil {
+	if err := s.writeClient(dryRun).Update(ctx, desired); err != nil {
 		return errors.Wrap(err, "failed to update resource")
 	}
 	reqLogger.V(consts.LogLevelInfo).Info("Object updated successfully")
 	return nil
 }
 
+// applyObj server-side applies the object. On conflict with other field managers the conflicting fields are logged
+// and the object is applied again taking over their ownership, since the operator is the source of truth for them.
+func (s *stateSkel) applyObj(ctx context.Context, obj *unstructured.Unstructured, dryRun bool) error {
+	reqLogger := log.FromContext(ctx)
+
+	s.checkDeleteSupported(ctx, obj)
+	reqLogger.V(consts.LogLevelInfo).Info("Applying Object", "Namespace:", obj.GetNamespace(), "Name:", obj.GetName(),
+		"DryRun", dryRun)
+	c := s.writeClient(dryRun)
+	toApply := obj.DeepCopy()
+	toApply.SetManagedFields(nil)
+	toApply.SetResourceVersion("")
+	err := c.Patch(ctx, toApply, client.Apply, client.FieldOwner(consts.FieldManager))
+	if k8serrors.IsConflict(err) {
+		reqLogger.V(consts.LogLevelWarning).Info("Object fields are managed by another field manager, forcing ownership",
+			"Namespace:", obj.GetNamespace(), "Name:", obj.GetName(), "conflict", err.Error())
+		toApply = obj.DeepCopy()
+		toApply.SetManagedFields(nil)
+		toApply.SetResourceVersion("")
+		err = c.Patch(ctx, toApply, client.Apply, client.FieldOwner(consts.FieldManager), client.ForceOwnership)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to apply resource")
+	}
+	reqLogger.V(consts.LogLevelInfo).Info("Object applied successfully")
+	return nil
+}
+
+// writeClient returns the client used to create and update the objects,
+// its requests are server-side dry-runs if dryRun is set
+func (s *stateSkel) writeClient(dryRun bool) client.Client {
+	if dryRun {
+		return client.NewDryRunClient(s.client)
+	}
+	return s.client
+}
+
+// createOrUpdateObjs applies the raw patches to the objects, scopes them to the policy of the context, if any,
+// and creates or updates them, the objects are patched in place.
+// If enabled, the objects are validated by a server-side dry-run before any of them is created or updated,
+// so that an object rejected by the API server doesn't leave the state partially applied.
 func (s *stateSkel) createOrUpdateObjs(
 	ctx context.Context,
 	setControllerReference func(obj *unstructured.Unstructured) error,
-	objs []*unstructured.Unstructured) error {
-	reqLogger := log.FromContext(ctx)
+	objs []*unstructured.Unstructured,
+	patches []mellanoxv1alpha1.RawPatch) error {
+	for _, desiredObj := range objs {
+		if err := applyRawPatches(s.client.Scheme(), desiredObj, patches); err != nil {
+			err = errors.Wrapf(err, "failed to patch %s %s", desiredObj.GetKind(), getObjectName(desiredObj))
+			return &RenderError{Err: err}
+		}
+	}
+	// the raw patches target the rendered names, the objects are renamed for the policy once they are patched
+	if scope, ok := getPolicyScope(ctx); ok {
+		if err := scope.apply(objs); err != nil {
+			return &RenderError{Err: errors.Wrapf(err, "failed to scope objects to policy %s", scope.Name)}
+		}
+	}
+	// the checksum is computed once the objects are patched since the patches may rename them
+	checksum := getStateObjectsChecksum(objs)
+	s.addRenderedGVKs(objs)
+	for _, desiredObj := range objs {
+		s.addStateSpecificLabels(desiredObj, checksum)
+	}
+	if envConfig().State.DryRun {
+		for _, desiredObj := range objs {
+			if err := s.createOrUpdateObj(ctx, setControllerReference, desiredObj.DeepCopy(), true); err != nil {
+				return &DryRunError{Object: desiredObj.GetKind() + " " + getObjectName(desiredObj), Err: err}
+			}
+		}
+	}
 	for _, desiredObj := range objs {
-		reqLogger.V(consts.LogLevelInfo).Info("Handling manifest object", "Kind:", desiredObj.GetKind(),
-			"Name", desiredObj.GetName())
-		// Set controller reference for object to allow cleanup on CR deletion
-		if err := setControllerReference(desiredObj); err != nil {
-			return errors.Wrap(err, "failed to set controller reference for object")
+		if err := s.createOrUpdateObj(ctx, setControllerReference, desiredObj, false); err != nil {
+			return &ApplyError{GVK: desiredObj.GroupVersionKind(), Name: getObjectName(desiredObj), Err: err}
 		}
+	}
+	return nil
+}
 
-		s.addStateSpecificLabels(desiredObj)
+// getObjectName returns the name of the object prefixed with its namespace for namespaced objects
+func getObjectName(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() != "" {
+		return obj.GetNamespace() + "/" + obj.GetName()
+	}
+	return obj.GetName()
+}
 
-		desiredRev, err := revision.CalculateRevision(desiredObj)
-		if err != nil {
-			return err
-		}
-		revision.SetRevision(desiredObj, desiredRev)
+// getStateObjectsChecksum returns a checksum of the kinds and names of the objects rendered by a state
+func getStateObjectsChecksum(objs []*unstructured.Unstructured) string {
+	ids := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		ids = append(ids, obj.GroupVersionKind().GroupKind().String()+"/"+getObjectName(obj))
+	}
+	sort.Strings(ids)
+	h := fnv.New32a()
+	for _, id := range ids {
+		_, _ = h.Write([]byte(id + "\n"))
+	}
+	return fmt.Sprintf("%08x", h.Sum32())
+}
 
-		alreadyExist := true
-		currentObj := desiredObj.NewEmptyInstance().(*unstructured.Unstructured)
-		currentObj.SetName(desiredObj.GetName())
-		currentObj.SetNamespace(desiredObj.GetNamespace())
-		if err := s.getObj(ctx, currentObj); err != nil {
-			if k8serrors.IsNotFound(err) {
-				alreadyExist = false
-			} else {
-				return err
-			}
+// addRenderedGVKs records the kinds of the objects rendered by the state
+func (s *stateSkel) addRenderedGVKs(objs []*unstructured.Unstructured) {
+	renderedGVKs.Lock()
+	defer renderedGVKs.Unlock()
+	gvks := renderedGVKs.byState[s.name]
+	if gvks == nil {
+		gvks = make(map[schema.GroupVersionKind]struct{})
+		renderedGVKs.byState[s.name] = gvks
+	}
+	for _, obj := range objs {
+		gvks[obj.GroupVersionKind()] = struct{}{}
+	}
+}
+
+// getStateGVKs returns the kinds which may have objects of the state
+func (s *stateSkel) getStateGVKs() []schema.GroupVersionKind {
+	gvks := getSupportedGVKs()
+	known := make(map[schema.GroupVersionKind]struct{}, len(gvks))
+	for _, gvk := range gvks {
+		known[gvk] = struct{}{}
+	}
+	renderedGVKs.Lock()
+	defer renderedGVKs.Unlock()
+	extra := make([]schema.GroupVersionKind, 0)
+	for gvk := range renderedGVKs.byState[s.name] {
+		if _, ok := known[gvk]; !ok {
+			extra = append(extra, gvk)
 		}
-		if !alreadyExist {
-			err := s.createObj(ctx, desiredObj)
-			if err != nil {
-				return err
-			}
-			continue
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i].String() < extra[j].String() })
+	return append(gvks, extra...)
+}
+
+func (s *stateSkel) createOrUpdateObj(
+	ctx context.Context,
+	setControllerReference func(obj *unstructured.Unstructured) error,
+	desiredObj *unstructured.Unstructured, dryRun bool) error {
+	reqLogger := log.FromContext(ctx)
+	reqLogger.V(consts.LogLevelInfo).Info("Handling manifest object", "Kind:", desiredObj.GetKind(),
+		"Name", desiredObj.GetName())
+	// Set controller reference for object to allow cleanup on CR deletion
+	if err := setControllerReference(desiredObj); err != nil {
+		return errors.Wrap(err, "failed to set controller reference for object")
+	}
+
+	desiredRev, err := revision.CalculateRevision(desiredObj)
+	if err != nil {
+		return err
+	}
+	revision.SetRevision(desiredObj, desiredRev)
+
+	alreadyExist := true
+	currentObj := desiredObj.NewEmptyInstance().(*unstructured.Unstructured)
+	currentObj.SetName(desiredObj.GetName())
+	currentObj.SetNamespace(desiredObj.GetNamespace())
+	if err := s.getObj(ctx, currentObj); err != nil {
+		if k8serrors.IsNotFound(err) {
+			alreadyExist = false
+		} else {
+			return err
 		}
+	}
+	if alreadyExist {
 		currRev := revision.GetRevision(currentObj)
 		if currRev != 0 && currRev == desiredRev {
-			reqLogger.V(consts.LogLevelInfo).Info("Object is already in sync")
-			continue
+			if !isObjectDrifted(desiredObj, currentObj) {
+				reqLogger.V(consts.LogLevelInfo).Info("Object is already in sync")
+				return nil
+			}
+			if !dryRun {
+				// the object was changed after it was applied, e.g. edited by a user
+				reqLogger.V(consts.LogLevelWarning).Info("Object drifted from the desired state, reapplying",
+					"Namespace:", desiredObj.GetNamespace(), "Name:", desiredObj.GetName())
+				recordDrift(ctx, desiredObj)
+			}
 		}
+	}
+	if alreadyExist && !dryRun {
+		s.logObjectDiff(ctx, desiredObj, currentObj)
+	}
+	if envConfig().State.ServerSideApply {
+		// fields which are not set in the desired object, e.g. added by users or other controllers, are preserved
+		if err := s.applyObj(ctx, desiredObj, dryRun); err != nil {
+			return err
+		}
+	} else if !alreadyExist {
+		if err := s.createObj(ctx, desiredObj, dryRun); err != nil {
+			return err
+		}
+	} else {
 		// update required
 		if err := s.mergeObjects(desiredObj, currentObj); err != nil {
 			return err
 		}
-		if err := s.updateObj(ctx, desiredObj); err != nil {
+		if err := s.updateObj(ctx, desiredObj, dryRun); err != nil {
 			return err
 		}
 	}
+	if !dryRun {
+		stateObjectsApplied.WithLabelValues(s.name).Inc()
+	}
 	return nil
 }
 
-func (s *stateSkel) addStateSpecificLabels(obj *unstructured.Unstructured) {
-	labels := obj.GetLabels()
-	if labels == nil {
-		labels = make(map[string]string)
+// logObjectDiff logs the fields of the object which are updated, and records them for an event if enabled
+func (s *stateSkel) logObjectDiff(ctx context.Context, desiredObj, currentObj *unstructured.Unstructured) {
+	diff := getObjectDiff(desiredObj, currentObj)
+	if len(diff) == 0 {
+		return
+	}
+	log.FromContext(ctx).V(consts.LogLevelDebug).Info("Updating object", "Kind", desiredObj.GetKind(),
+		"Name", getObjectName(desiredObj), "Diff", diff)
+	if envConfig().State.DiffEvents {
+		recordUpdate(ctx, desiredObj, diff)
 	}
-	labels[consts.StateLabel] = s.name
-	obj.SetLabels(labels)
+}
+
+func (s *stateSkel) addStateSpecificLabels(obj *unstructured.Unstructured, checksum string) {
+	objLabels := obj.GetLabels()
+	if objLabels == nil {
+		objLabels = make(map[string]string)
+	}
+	objLabels[consts.StateLabel] = s.name
+	objLabels[consts.StateChecksumLabel] = checksum
+	obj.SetLabels(objLabels)
 }
 
 func (s *stateSkel) handleStateObjectsDeletion(ctx context.Context) (SyncState, error) {
 	reqLogger := log.FromContext(ctx)
 	reqLogger.V(consts.LogLevelInfo).Info(
 		"State spec in CR is nil, deleting existing objects if needed", "State:", s.name)
-	found, err := s.deleteStateRelatedObjects(ctx, stateObjects{})
+	found, err := s.deleteStateRelatedObjects(ctx, stateObjects{}, "")
 	if err != nil {
 		return SyncStateError, errors.Wrap(err, "failed to delete k8s objects")
 	}
@@ -282,6 +510,100 @@ func (s *stateSkel) handleStateObjectsDeletion(ctx context.Context) (SyncState,
 	return SyncStateIgnore, nil
 }
 
+// workloadGVKs are the kinds of the objects which run Pods, on teardown they are removed before the other objects
+var workloadGVKs = []schema.GroupVersionKind{
+	{Group: "apps", Kind: "DaemonSet", Version: "v1"},
+	{Group: "apps", Kind: "Deployment", Version: "v1"},
+}
+
+// Teardown removes the objects of the state, the workloads are deleted in foreground and the other objects
+// are deleted only once the workloads and their Pods are gone, so that e.g. the driver Pods can unload
+// the driver while they can still access their ConfigMaps and RBAC
+func (s *stateSkel) Teardown(ctx context.Context) (bool, error) {
+	reqLogger := log.FromContext(ctx)
+	pending, err := s.deleteStateWorkloads(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to delete workloads")
+	}
+	if pending {
+		reqLogger.V(consts.LogLevelInfo).Info("Waiting for the workloads of the state to be removed", "State:", s.name)
+		return false, nil
+	}
+	// the remaining objects are not used once the Pods are gone, there is no need to wait for their removal
+	if _, err := s.deleteStateRelatedObjects(ctx, stateObjects{}, ""); err != nil {
+		return false, errors.Wrap(err, "failed to delete k8s objects")
+	}
+	return true, nil
+}
+
+// deleteStateWorkloads deletes the workloads of the state with foreground propagation,
+// returns true while there are workloads which are not deleted yet or which still have Pods
+func (s *stateSkel) deleteStateWorkloads(ctx context.Context) (bool, error) {
+	selector, err := addScopeRequirement(ctx, labels.SelectorFromSet(labels.Set{consts.StateLabel: s.name}))
+	if err != nil {
+		return false, err
+	}
+	pending := false
+	for _, gvk := range workloadGVKs {
+		l := &unstructured.UnstructuredList{}
+		l.SetGroupVersionKind(gvk)
+		err := s.client.List(ctx, l, client.MatchingLabelsSelector{Selector: selector})
+		if meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		for _, obj := range l.Items {
+			obj := obj
+			if obj.GetDeletionTimestamp() == nil {
+				pending = true
+				err := s.client.Delete(ctx, &obj, client.PropagationPolicy(metav1.DeletePropagationForeground))
+				if err != nil && !k8serrors.IsNotFound(err) {
+					return false, err
+				}
+				continue
+			}
+			// the workload is removed by the garbage collector once its Pods are gone,
+			// Pods which are still running may hold resources of the state, e.g. loaded kernel modules
+			hasPods, err := s.workloadHasPods(ctx, &obj)
+			if err != nil {
+				return false, err
+			}
+			pending = pending || hasPods
+		}
+	}
+	return pending, nil
+}
+
+// workloadHasPods returns true if there are Pods matching the selector of the workload
+func (s *stateSkel) workloadHasPods(ctx context.Context, obj *unstructured.Unstructured) (bool, error) {
+	selectorMap, found, err := unstructured.NestedMap(obj.Object, "spec", "selector")
+	if err != nil || !found {
+		return false, err
+	}
+	labelSelector := &metav1.LabelSelector{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(selectorMap, labelSelector); err != nil {
+		return false, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return false, err
+	}
+	// the workloads of several policies may select the same Pods, only the Pods of the policy are considered
+	selector, err = addScopeRequirement(ctx, selector)
+	if err != nil {
+		return false, err
+	}
+	pods := &corev1.PodList{}
+	err = s.client.List(ctx, pods, client.InNamespace(obj.GetNamespace()),
+		client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return false, err
+	}
+	return len(pods.Items) > 0, nil
+}
+
 // is a mapping where GVK is a key and a map(set) with NamespacedNames is a value
 type stateObjects map[schema.GroupVersionKind]map[types.NamespacedName]struct{}
 
@@ -312,7 +634,7 @@ func (s *stateSkel) handleStaleStateObjects(ctx context.Context,
 	for _, o := range desiredObjs {
 		objsToKeep.Add(o.GroupVersionKind(), types.NamespacedName{Name: o.GetName(), Namespace: o.GetNamespace()})
 	}
-	found, err := s.deleteStateRelatedObjects(ctx, objsToKeep)
+	found, err := s.deleteStateRelatedObjects(ctx, objsToKeep, getStateObjectsChecksum(desiredObjs))
 	if err != nil {
 		return false, errors.Wrap(err, "failed to delete k8s objects")
 	}
@@ -325,15 +647,30 @@ func (s *stateSkel) handleStaleStateObjects(ctx context.Context,
 	return false, nil
 }
 
-func (s *stateSkel) deleteStateRelatedObjects(ctx context.Context, stateObjectsToKeep stateObjects) (bool, error) {
-	stateLabel := map[string]string{
-		consts.StateLabel: s.name,
+// deleteStateRelatedObjects deletes the objects labeled with the state name which are not in stateObjectsToKeep,
+// if checksum is set only the objects with a different state checksum label are considered.
+// If the context has a policy scope only the objects of the policy are considered.
+// The deletion of the other objects is attempted if an object can't be deleted, the returned error aggregates
+// a StaleObjectError per object which couldn't be deleted.
+func (s *stateSkel) deleteStateRelatedObjects(
+	ctx context.Context, stateObjectsToKeep stateObjects, checksum string) (bool, error) {
+	selector, err := addScopeRequirement(ctx, labels.SelectorFromSet(labels.Set{consts.StateLabel: s.name}))
+	if err != nil {
+		return false, err
+	}
+	if checksum != "" {
+		req, err := labels.NewRequirement(consts.StateChecksumLabel, selection.NotEquals, []string{checksum})
+		if err != nil {
+			return false, err
+		}
+		selector = selector.Add(*req)
 	}
 	found := false
-	for _, gvk := range getSupportedGVKs() {
+	var deleteErrs []error
+	for _, gvk := range s.getStateGVKs() {
 		l := &unstructured.UnstructuredList{}
 		l.SetGroupVersionKind(gvk)
-		err := s.client.List(ctx, l, client.MatchingLabels(stateLabel))
+		err := s.client.List(ctx, l, client.MatchingLabelsSelector{Selector: selector})
 		if meta.IsNoMatchError(err) {
 			continue
 		}
@@ -350,14 +687,13 @@ func (s *stateSkel) deleteStateRelatedObjects(ctx context.Context, stateObjectsT
 			}
 			found = true
 			if obj.GetDeletionTimestamp() == nil {
-				err := s.client.Delete(ctx, &obj)
-				if err != nil {
-					return true, err
+				if err := s.client.Delete(ctx, &obj); err != nil {
+					deleteErrs = append(deleteErrs, &StaleObjectError{GVK: gvk, Name: getObjectName(&obj), Err: err})
 				}
 			}
 		}
 	}
-	return found, nil
+	return found, joinErrors(deleteErrs)
 }
 
 func (s *stateSkel) mergeObjects(updated, current *unstructured.Unstructured) error {
@@ -398,11 +734,13 @@ func (s *stateSkel) mergeServiceAccount(updated, current *unstructured.Unstructu
 	return nil
 }
 
-// Iterate over objects and check for their readiness
+// Iterate over objects and check for their readiness. All the objects are checked, so that the rollout progress
+// of every DaemonSet is recorded in the readinessRecorder of the context, if any
 func (s *stateSkel) getSyncState(ctx context.Context, objs []*unstructured.Unstructured) (SyncState, error) {
 	reqLogger := log.FromContext(ctx)
 	reqLogger.V(consts.LogLevelInfo).Info("Checking related object states")
 
+	syncState := SyncState(SyncStateReady)
 	for _, obj := range objs {
 		reqLogger.V(consts.LogLevelInfo).Info("Checking object", "Kind:", obj.GetKind(), "Name", obj.GetName())
 		// Check if object exists
@@ -412,7 +750,8 @@ func (s *stateSkel) getSyncState(ctx context.Context, objs []*unstructured.Unstr
 			if k8serrors.IsNotFound(err) {
 				// does not exist (yet)
 				reqLogger.V(consts.LogLevelInfo).Info("Object is not ready", "Kind:", obj.GetKind(), "Name", obj.GetName())
-				return SyncStateNotReady, nil
+				syncState = SyncStateNotReady
+				continue
 			}
 			// other error
 			return SyncStateNotReady, errors.Wrapf(err, "failed to get object")
@@ -420,18 +759,24 @@ func (s *stateSkel) getSyncState(ctx context.Context, objs []*unstructured.Unstr
 
 		// Object exists, check for Kind specific readiness
 		if found.GetKind() == "DaemonSet" {
-			if ready, err := s.isDaemonSetReady(found, reqLogger); err != nil || !ready {
-				reqLogger.V(consts.LogLevelInfo).Info("Object is not ready", "Kind:", obj.GetKind(), "Name", obj.GetName())
+			ready, err := s.isDaemonSetReady(ctx, found, reqLogger)
+			if err != nil {
 				return SyncStateNotReady, err
 			}
+			if !ready {
+				reqLogger.V(consts.LogLevelInfo).Info("Object is not ready", "Kind:", obj.GetKind(), "Name", obj.GetName())
+				syncState = SyncStateNotReady
+				continue
+			}
 		}
 		reqLogger.V(consts.LogLevelInfo).Info("Object is ready", "Kind:", obj.GetKind(), "Name", obj.GetName())
 	}
-	return SyncStateReady, nil
+	return syncState, nil
 }
 
-// isDaemonSetReady checks if daemonset is ready
-func (s *stateSkel) isDaemonSetReady(uds *unstructured.Unstructured, reqLogger logr.Logger) (bool, error) {
+// isDaemonSetReady checks if daemonset is ready and records its rollout progress
+func (s *stateSkel) isDaemonSetReady(
+	ctx context.Context, uds *unstructured.Unstructured, reqLogger logr.Logger) (bool, error) {
 	buf, err := uds.MarshalJSON()
 	if err != nil {
 		return false, errors.Wrap(err, "failed to marshall unstructured daemonset object")
@@ -441,6 +786,7 @@ func (s *stateSkel) isDaemonSetReady(uds *unstructured.Unstructured, reqLogger l
 	if err = json.Unmarshal(buf, ds); err != nil {
 		return false, errors.Wrap(err, "failed to unmarshall to daemonset object")
 	}
+	recordReadiness(ctx, daemonSetReadiness(ds))
 
 	reqLogger.V(consts.LogLevelDebug).Info(
 		"Check daemonset state",
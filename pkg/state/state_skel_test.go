@@ -18,17 +18,31 @@ package state
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/Mellanox/network-operator/pkg/config"
 	"github.com/Mellanox/network-operator/pkg/consts"
+	"github.com/Mellanox/network-operator/pkg/render"
+	"github.com/Mellanox/network-operator/pkg/revision"
+	"github.com/Mellanox/network-operator/pkg/utils"
 )
 
 const (
@@ -78,5 +92,333 @@ var _ = Describe("stateSkel", func() {
 			Expect(err).To(BeNil())
 			Expect(wait).To(BeTrue())
 		})
+		It("Stale cluster-scoped object", func() {
+			clusterRole := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{
+				Name: "test", Labels: map[string]string{consts.StateLabel: testState}}}
+			s.client = fake.NewClientBuilder().WithObjects(testSa, clusterRole).Build()
+			unstrSa, err := runtime.DefaultUnstructuredConverter.ToUnstructured(testSa)
+			Expect(err).NotTo(HaveOccurred())
+			wait, err := s.handleStaleStateObjects(ctx, []*unstructured.Unstructured{{Object: unstrSa}})
+			Expect(err).To(BeNil())
+			Expect(wait).To(BeTrue())
+			err = s.client.Get(ctx, client.ObjectKeyFromObject(clusterRole), &rbacv1.ClusterRole{})
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			Expect(s.client.Get(ctx, client.ObjectKeyFromObject(testSa), &corev1.ServiceAccount{})).To(Succeed())
+		})
+		It("Stale object of a kind rendered by the state", func() {
+			secret := &corev1.Secret{TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test"}}
+			unstrSecret, err := runtime.DefaultUnstructuredConverter.ToUnstructured(secret)
+			Expect(err).NotTo(HaveOccurred())
+			s.client = fake.NewClientBuilder().Build()
+			Expect(s.createOrUpdateObjs(ctx, func(obj *unstructured.Unstructured) error { return nil },
+				[]*unstructured.Unstructured{{Object: unstrSecret}}, nil)).To(Succeed())
+			wait, err := s.handleStaleStateObjects(ctx, []*unstructured.Unstructured{})
+			Expect(err).To(BeNil())
+			Expect(wait).To(BeTrue())
+			err = s.client.Get(ctx, client.ObjectKeyFromObject(secret), &corev1.Secret{})
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+		It("Should not remove the objects of another policy", func() {
+			policySa := testSa.DeepCopy()
+			policySa.Name = "test-ib"
+			policySa.Labels[consts.PolicyLabel] = "ib"
+			s.client = fake.NewClientBuilder().WithObjects(testSa, policySa).Build()
+			wait, err := s.handleStaleStateObjects(
+				WithPolicyScope(ctx, PolicyScope{Name: consts.NicClusterPolicyResourceName}),
+				[]*unstructured.Unstructured{})
+			Expect(err).To(BeNil())
+			Expect(wait).To(BeTrue())
+			err = s.client.Get(ctx, client.ObjectKeyFromObject(testSa), &corev1.ServiceAccount{})
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			Expect(s.client.Get(ctx, client.ObjectKeyFromObject(policySa), &corev1.ServiceAccount{})).To(Succeed())
+		})
+		It("Should delete the other stale objects if an object can't be deleted", func() {
+			otherSa := testSa.DeepCopy()
+			otherSa.Name = "other"
+			s.client = fake.NewClientBuilder().WithObjects(testSa, otherSa).WithInterceptorFuncs(interceptor.Funcs{
+				Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+					if obj.GetName() == testSa.Name {
+						return k8serrors.NewForbidden(schema.GroupResource{Resource: "serviceaccounts"}, obj.GetName(),
+							errors.New("forbidden"))
+					}
+					return c.Delete(ctx, obj, opts...)
+				},
+			}).Build()
+			_, err := s.handleStaleStateObjects(ctx, []*unstructured.Unstructured{})
+			var staleErr *StaleObjectError
+			Expect(errors.As(err, &staleErr)).To(BeTrue())
+			Expect(staleErr.GVK.Kind).To(Equal("ServiceAccount"))
+			Expect(staleErr.Name).To(Equal("test/test"))
+			Expect(ClassifyError(err)).To(Equal(ErrorClassStaleObject))
+			err = s.client.Get(ctx, client.ObjectKeyFromObject(otherSa), &corev1.ServiceAccount{})
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+	Context("Teardown", func() {
+		It("Should remove the workloads before the other objects", func() {
+			ds := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{
+				Name: "test", Namespace: "test", Labels: map[string]string{consts.StateLabel: testState}}}
+			s.client = fake.NewClientBuilder().WithObjects(testSa.DeepCopy(), ds).Build()
+
+			done, err := s.Teardown(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(done).To(BeFalse())
+			err = s.client.Get(ctx, client.ObjectKeyFromObject(ds), &appsv1.DaemonSet{})
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			Expect(s.client.Get(ctx, client.ObjectKeyFromObject(testSa), &corev1.ServiceAccount{})).To(Succeed())
+
+			done, err = s.Teardown(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(done).To(BeTrue())
+			err = s.client.Get(ctx, client.ObjectKeyFromObject(testSa), &corev1.ServiceAccount{})
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+		It("Should wait for the Pods of the deleted workloads", func() {
+			now := metav1.Now()
+			ds := &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test", DeletionTimestamp: &now,
+					Finalizers: []string{metav1.FinalizerDeleteDependents},
+					Labels:     map[string]string{consts.StateLabel: testState}},
+				Spec: appsv1.DaemonSetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}}},
+			}
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name: "test-pod", Namespace: "test", Labels: map[string]string{"app": "test"}}}
+			s.client = fake.NewClientBuilder().WithObjects(testSa.DeepCopy(), ds, pod).Build()
+
+			done, err := s.Teardown(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(done).To(BeFalse())
+			Expect(s.client.Get(ctx, client.ObjectKeyFromObject(testSa), &corev1.ServiceAccount{})).To(Succeed())
+
+			Expect(s.client.Delete(ctx, pod)).To(Succeed())
+			done, err = s.Teardown(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(done).To(BeTrue())
+			err = s.client.Get(ctx, client.ObjectKeyFromObject(testSa), &corev1.ServiceAccount{})
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+	Context("getSyncState", func() {
+		It("Should record the rollout progress of all the DaemonSets", func() {
+			ds := &appsv1.DaemonSet{
+				TypeMeta:   metav1.TypeMeta{Kind: "DaemonSet", APIVersion: "apps/v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test", Generation: 2},
+				Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 50, NumberAvailable: 48,
+					UpdatedNumberScheduled: 45, ObservedGeneration: 2},
+			}
+			s.client = fake.NewClientBuilder().WithObjects(ds).Build()
+			unstrDs, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ds)
+			Expect(err).NotTo(HaveOccurred())
+			unstrSa, err := runtime.DefaultUnstructuredConverter.ToUnstructured(testSa)
+			Expect(err).NotTo(HaveOccurred())
+			recorderCtx, recorder := withReadinessRecorder(ctx)
+
+			syncState, err := s.getSyncState(recorderCtx,
+				[]*unstructured.Unstructured{{Object: unstrSa}, {Object: unstrDs}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(syncState).To(BeEquivalentTo(SyncStateNotReady))
+			Expect(recorder.recorded()).To(Equal([]ObjectReadiness{{Kind: "DaemonSet", Name: "test/test",
+				Desired: 50, Ready: 45, Generation: 2, ObservedGeneration: 2}}))
+			result := Result{Readiness: recorder.recorded()}
+			Expect(result.ReadinessSummary()).To(Equal("45/50 nodes ready"))
+		})
+		It("Should summarize the rollout progress of the DaemonSets of a state", func() {
+			result := Result{}
+			Expect(result.ReadinessSummary()).To(BeEmpty())
+			result.Readiness = []ObjectReadiness{
+				{Kind: "DaemonSet", Name: "a", Desired: 10, Ready: 10, Generation: 1, ObservedGeneration: 1},
+				{Kind: "DaemonSet", Name: "b", Desired: 5, Ready: 2, Generation: 3, ObservedGeneration: 2},
+			}
+			Expect(result.ReadinessSummary()).To(Equal("12/15 nodes ready, rollout pending"))
+		})
+	})
+	Context("createOrUpdateObjs", func() {
+		It("Should label the objects with the state name and checksum", func() {
+			s.client = fake.NewClientBuilder().Build()
+			sa := testSa.DeepCopy()
+			sa.ResourceVersion = ""
+			unstrSa, err := runtime.DefaultUnstructuredConverter.ToUnstructured(sa)
+			Expect(err).NotTo(HaveOccurred())
+			objs := []*unstructured.Unstructured{{Object: unstrSa}}
+			Expect(s.createOrUpdateObjs(ctx, func(obj *unstructured.Unstructured) error { return nil },
+				objs, nil)).To(Succeed())
+			sa = &corev1.ServiceAccount{}
+			Expect(s.client.Get(ctx, client.ObjectKeyFromObject(testSa), sa)).To(Succeed())
+			Expect(sa.Labels).To(HaveKeyWithValue(consts.StateLabel, testState))
+			Expect(sa.Labels).To(HaveKeyWithValue(consts.StateChecksumLabel, getStateObjectsChecksum(objs)))
+		})
+		It("Should not apply any object if the dry-run of an object fails", func() {
+			origConfig := envConfig
+			defer func() { envConfig = origConfig }()
+			envConfig = func() *config.OperatorConfig {
+				return &config.OperatorConfig{State: config.StateConfig{DryRun: true}}
+			}
+			var created []string
+			s.client = fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					co := &client.CreateOptions{}
+					co.ApplyOptions(opts)
+					if len(co.DryRun) == 0 {
+						created = append(created, obj.GetName())
+					} else if obj.GetName() == "invalid" {
+						return k8serrors.NewInvalid(schema.GroupKind{Kind: "ServiceAccount"}, obj.GetName(), nil)
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			}).Build()
+			var objs []*unstructured.Unstructured
+			for _, name := range []string{"valid", "invalid"} {
+				sa := &unstructured.Unstructured{}
+				sa.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "ServiceAccount"})
+				sa.SetName(name)
+				sa.SetNamespace("test")
+				objs = append(objs, sa)
+			}
+			err := s.createOrUpdateObjs(ctx, func(obj *unstructured.Unstructured) error { return nil }, objs, nil)
+			var dryRunErr *DryRunError
+			Expect(errors.As(err, &dryRunErr)).To(BeTrue())
+			Expect(dryRunErr.Object).To(Equal("ServiceAccount test/invalid"))
+			Expect(created).To(BeEmpty())
+
+			objs = objs[:1]
+			Expect(s.createOrUpdateObjs(ctx, func(obj *unstructured.Unstructured) error { return nil },
+				objs, nil)).To(Succeed())
+			Expect(created).To(Equal([]string{"valid"}))
+		})
+		It("Should return an ApplyError if an object can't be applied", func() {
+			origConfig := envConfig
+			defer func() { envConfig = origConfig }()
+			envConfig = func() *config.OperatorConfig {
+				return &config.OperatorConfig{State: config.StateConfig{DryRun: false}}
+			}
+			s.client = fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					return k8serrors.NewInvalid(schema.GroupKind{Kind: "ServiceAccount"}, obj.GetName(), nil)
+				},
+			}).Build()
+			sa := &unstructured.Unstructured{}
+			sa.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "ServiceAccount"})
+			sa.SetName("test")
+			sa.SetNamespace("test")
+			err := s.createOrUpdateObjs(ctx, func(obj *unstructured.Unstructured) error { return nil },
+				[]*unstructured.Unstructured{sa}, nil)
+			var applyErr *ApplyError
+			Expect(errors.As(err, &applyErr)).To(BeTrue())
+			Expect(applyErr.GVK).To(Equal(sa.GroupVersionKind()))
+			Expect(applyErr.Name).To(Equal("test/test"))
+			Expect(k8serrors.IsInvalid(err)).To(BeTrue())
+		})
+		It("Should compute the checksum independently of the order of the objects", func() {
+			sa := &unstructured.Unstructured{}
+			sa.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "ServiceAccount"})
+			sa.SetName("test")
+			cm := &unstructured.Unstructured{}
+			cm.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			cm.SetName("test")
+			Expect(getStateObjectsChecksum([]*unstructured.Unstructured{sa, cm})).To(
+				Equal(getStateObjectsChecksum([]*unstructured.Unstructured{cm, sa})))
+			Expect(getStateObjectsChecksum([]*unstructured.Unstructured{sa})).NotTo(
+				Equal(getStateObjectsChecksum([]*unstructured.Unstructured{cm})))
+		})
+	})
+	Context("getSupportedGVKs", func() {
+		It("Should contain the kinds of all the manifests", func() {
+			supported := map[schema.GroupVersionKind]bool{}
+			for _, gvk := range getSupportedGVKs() {
+				supported[gvk] = true
+			}
+			files, err := utils.GetFilesWithSuffix(filepath.Join("..", "..", "manifests"), render.ManifestFileSuffix...)
+			Expect(err).NotTo(HaveOccurred())
+			for _, file := range files {
+				content, err := os.ReadFile(file)
+				Expect(err).NotTo(HaveOccurred())
+				for _, doc := range strings.Split(string(content), "\n---") {
+					var apiVersion, kind string
+					for _, line := range strings.Split(doc, "\n") {
+						if v, ok := strings.CutPrefix(line, "apiVersion:"); ok {
+							apiVersion = strings.Trim(strings.TrimSpace(v), `"`)
+						}
+						if v, ok := strings.CutPrefix(line, "kind:"); ok {
+							kind = strings.TrimSpace(v)
+						}
+					}
+					if kind == "" {
+						continue
+					}
+					gv, err := schema.ParseGroupVersion(apiVersion)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(supported).To(HaveKey(gv.WithKind(kind)), "kind of manifest %s", file)
+				}
+			}
+		})
+	})
+	Context("createOrUpdateObjs with Server-Side Apply", func() {
+		var (
+			origConfig func() *config.OperatorConfig
+			patchOpts  []*client.PatchOptions
+			conflicts  int
+		)
+		BeforeEach(func() {
+			origConfig = envConfig
+			envConfig = func() *config.OperatorConfig {
+				return &config.OperatorConfig{State: config.StateConfig{ServerSideApply: true}}
+			}
+			patchOpts = nil
+			conflicts = 0
+			// fake client doesn't support apply patches, record them instead
+			s.client = fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+				Patch: func(ctx context.Context, c client.WithWatch, obj client.Object,
+					patch client.Patch, opts ...client.PatchOption) error {
+					Expect(patch).To(Equal(client.Apply))
+					po := &client.PatchOptions{}
+					po.ApplyOptions(opts)
+					patchOpts = append(patchOpts, po)
+					if conflicts > 0 && (po.Force == nil || !*po.Force) {
+						conflicts--
+						return k8serrors.NewConflict(schema.GroupResource{Resource: "serviceaccounts"}, obj.GetName(),
+							nil)
+					}
+					return nil
+				},
+			}).Build()
+		})
+		AfterEach(func() {
+			envConfig = origConfig
+		})
+		applySa := func() error {
+			unstrSa, err := runtime.DefaultUnstructuredConverter.ToUnstructured(testSa)
+			Expect(err).NotTo(HaveOccurred())
+			return s.createOrUpdateObjs(ctx, func(obj *unstructured.Unstructured) error { return nil },
+				[]*unstructured.Unstructured{{Object: unstrSa}}, nil)
+		}
+		It("Should apply object with the operator field manager", func() {
+			Expect(applySa()).To(Succeed())
+			Expect(patchOpts).To(HaveLen(1))
+			Expect(patchOpts[0].FieldManager).To(Equal(consts.FieldManager))
+			Expect(patchOpts[0].Force).To(BeNil())
+		})
+		It("Should force ownership on conflict", func() {
+			conflicts = 1
+			Expect(applySa()).To(Succeed())
+			Expect(patchOpts).To(HaveLen(2))
+			Expect(*patchOpts[1].Force).To(BeTrue())
+			Expect(patchOpts[1].FieldManager).To(Equal(consts.FieldManager))
+		})
+		It("Should not apply object in sync", func() {
+			unstrSa, err := runtime.DefaultUnstructuredConverter.ToUnstructured(testSa)
+			Expect(err).NotTo(HaveOccurred())
+			desired := &unstructured.Unstructured{Object: unstrSa}
+			s.addStateSpecificLabels(desired, getStateObjectsChecksum([]*unstructured.Unstructured{desired}))
+			rev, err := revision.CalculateRevision(desired)
+			Expect(err).NotTo(HaveOccurred())
+			current := testSa.DeepCopy()
+			current.ResourceVersion = ""
+			current.Labels = desired.GetLabels()
+			revision.SetRevision(current, rev)
+			Expect(s.client.Create(ctx, current)).To(Succeed())
+			Expect(applySa()).To(Succeed())
+			Expect(patchOpts).To(BeEmpty())
+		})
 	})
 })
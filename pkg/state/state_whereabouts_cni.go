@@ -21,7 +21,6 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
-	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -38,12 +37,11 @@ import (
 // NewStateWhereaboutsCNI creates a new state for Whereabouts
 func NewStateWhereaboutsCNI(
 	k8sAPIClient client.Client, manifestDir string) (State, ManifestRenderer, error) {
-	files, err := utils.GetFilesWithSuffix(manifestDir, render.ManifestFileSuffix...)
+	renderer, err := newManifestRenderer(k8sAPIClient, manifestDir)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to get files from manifest dir")
 	}
 
-	renderer := render.NewRenderer(files)
 	state := &stateWhereaboutsCNI{
 		stateSkel: stateSkel{
 			name:        "state-whereabouts-cni",
@@ -54,16 +52,22 @@ func NewStateWhereaboutsCNI(
 	return state, state, nil
 }
 
+// whereaboutsDefaultReconcilerCronExpression is the IP reconciler schedule used if not set in the CR
+const whereaboutsDefaultReconcilerCronExpression = "30 4 * * *"
+
 type stateWhereaboutsCNI struct {
 	stateSkel
 }
 
 // WhereaboutsManifestRenderData contains information used to render Kubernetes objects related to Whereabouts.
 type WhereaboutsManifestRenderData struct {
-	CrSpec       *mellanoxv1alpha1.ImageSpec
-	Tolerations  []v1.Toleration
-	NodeAffinity *v1.NodeAffinity
-	RuntimeSpec  *cniRuntimeSpec
+	CrSpec                   *mellanoxv1alpha1.WhereaboutsSpec
+	ReconcilerCronExpression string
+	Tolerations              []v1.Toleration
+	ImagePullSecrets         []string
+	NodeAffinity             *v1.NodeAffinity
+	NodeSelector             map[string]string
+	RuntimeSpec              *cniRuntimeSpec
 }
 
 // Sync attempt to get the system to match the desired state which State represent.
@@ -90,7 +94,7 @@ func (s *stateWhereaboutsCNI) Sync(
 
 	objs, err := s.GetManifestObjects(ctx, cr, infoCatalog, reqLogger)
 	if err != nil {
-		return SyncStateNotReady, errors.Wrap(err, "failed to create k8s objects from manifest")
+		return SyncStateNotReady, &RenderError{Err: errors.Wrap(err, "failed to create k8s objects from manifest")}
 	}
 	if len(objs) == 0 {
 		return SyncStateNotReady, nil
@@ -102,7 +106,7 @@ func (s *stateWhereaboutsCNI) Sync(
 			return errors.Wrap(err, "failed to set controller reference for object")
 		}
 		return nil
-	}, objs)
+	}, objs, cr.Spec.RawPatches)
 	if err != nil {
 		return SyncStateNotReady, errors.Wrap(err, "failed to create/update objects")
 	}
@@ -122,15 +126,16 @@ func (s *stateWhereaboutsCNI) Sync(
 }
 
 // Get a map of source kinds that should be watched for the state keyed by the source kind name
-func (s *stateWhereaboutsCNI) GetWatchSources() map[string]client.Object {
-	wr := make(map[string]client.Object)
-	wr["DaemonSet"] = &appsv1.DaemonSet{}
+func (s *stateWhereaboutsCNI) GetWatchSources() map[string]WatchSource {
+	wr := make(map[string]WatchSource)
+	wr["DaemonSet"] = daemonSetsWatchSource(s.name)
+	wr["Deployment"] = deploymentsWatchSource(s.name)
 	return wr
 }
 
 //nolint:dupl
 func (s *stateWhereaboutsCNI) GetManifestObjects(
-	_ context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
+	ctx context.Context, cr *mellanoxv1alpha1.NicClusterPolicy,
 	catalog InfoCatalog, reqLogger logr.Logger) ([]*unstructured.Unstructured, error) {
 	if cr == nil || cr.Spec.SecondaryNetwork == nil || cr.Spec.SecondaryNetwork.IpamPlugin == nil {
 		return nil, errors.New("failed to render objects: state spec is nil")
@@ -144,22 +149,37 @@ func (s *stateWhereaboutsCNI) GetManifestObjects(
 	if clusterInfo == nil {
 		return nil, errors.New("clusterInfo provider required")
 	}
+	cronExpression := cr.Spec.SecondaryNetwork.IpamPlugin.ReconcilerCronExpression
+	if cronExpression == "" {
+		cronExpression = whereaboutsDefaultReconcilerCronExpression
+	}
 	renderData := &WhereaboutsManifestRenderData{
-		CrSpec:       cr.Spec.SecondaryNetwork.IpamPlugin,
-		Tolerations:  cr.Spec.Tolerations,
-		NodeAffinity: cr.Spec.NodeAffinity,
+		CrSpec:                   cr.Spec.SecondaryNetwork.IpamPlugin,
+		ReconcilerCronExpression: cronExpression,
+		Tolerations:              getTolerations(cr.Spec.Tolerations, cr.Spec.SecondaryNetwork.IpamPlugin.Tolerations),
+		NodeAffinity:             getNodeAffinity(cr.Spec.NodeAffinity, cr.Spec.SecondaryNetwork.IpamPlugin.NodeAffinity),
+		NodeSelector:             cr.Spec.SecondaryNetwork.IpamPlugin.NodeSelector,
 		RuntimeSpec: &cniRuntimeSpec{
 			runtimeSpec:        runtimeSpec{config.FromEnv().State.NetworkOperatorResourceNamespace},
-			CniBinDirectory:    utils.GetCniBinDirectory(staticConfig, clusterInfo),
+			CniBinDirectory:    utils.GetCniBinDirectory(staticConfig, clusterInfo, cr.Spec.CNIDirectories),
+			CniConfDirectory:   utils.GetCniConfDirectory(staticConfig, cr.Spec.CNIDirectories),
 			ContainerResources: createContainerResourcesMap(cr.Spec.SecondaryNetwork.IpamPlugin.ContainerResources),
 		},
+		ImagePullSecrets: mellanoxv1alpha1.MergeImagePullSecrets(
+			cr.Spec.ImagePullSecrets, cr.Spec.SecondaryNetwork.IpamPlugin.ImagePullSecrets),
 	}
 	// render objects
 	reqLogger.V(consts.LogLevelDebug).Info("Rendering objects", "data:", renderData)
-	objs, err := s.renderer.RenderObjects(&render.TemplatingData{Data: renderData})
+	objs, err := s.renderer.RenderObjects(ctx, &render.TemplatingData{Data: renderData})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to render objects")
 	}
+	if err := setPriorityClassName(objs, &cr.Spec, cr.Spec.SecondaryNetwork.IpamPlugin.PriorityClassName); err != nil {
+		return nil, err
+	}
+	if err := setSecurityContext(objs, cr.Spec.SecondaryNetwork.IpamPlugin.SecurityContext); err != nil {
+		return nil, err
+	}
 	reqLogger.V(consts.LogLevelDebug).Info("Rendered", "objects:", objs)
 	return objs, nil
 }
@@ -0,0 +1,92 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/state"
+	"github.com/Mellanox/network-operator/pkg/staticconfig"
+)
+
+var _ = Describe("Whereabouts CNI state", func() {
+	ctx := context.Background()
+
+	imageSpec := getTestImageSpec()
+	imageSpec = addContainerResources(imageSpec, "whereabouts", "5", "3")
+	imageSpec = addContainerResources(imageSpec, "whereabouts-controller", "5", "3")
+	catalog := getTestCatalog()
+	catalog.Add(state.InfoTypeStaticConfig,
+		staticconfig.NewProvider(staticconfig.StaticConfig{CniBinDirectory: "custom-cni-bin-directory"}))
+
+	_, s, err := state.NewStateWhereaboutsCNI(fake.NewClientBuilder().Build(), "../../manifests/state-whereabouts-cni")
+	Expect(err).NotTo(HaveOccurred())
+
+	getCronExpression := func(cr *mellanoxv1alpha1.NicClusterPolicy) (string, []string) {
+		objs, err := s.GetManifestObjects(ctx, cr, catalog, log.FromContext(ctx))
+		Expect(err).NotTo(HaveOccurred())
+		cronExpression := ""
+		kinds := []string{}
+		for _, obj := range objs {
+			kinds = append(kinds, obj.GetKind())
+			if obj.GetKind() == "ConfigMap" && obj.GetName() == "whereabouts-config" {
+				data := obj.Object["data"].(map[string]interface{})
+				cronExpression = data["cron-expression"].(string)
+			}
+		}
+		return cronExpression, kinds
+	}
+
+	It("should test that manifests are rendered and fields are set correctly", func() {
+		cr := getTestClusterPolicyWithBaseFields()
+		cr.Spec.SecondaryNetwork = &mellanoxv1alpha1.SecondaryNetworkSpec{
+			IpamPlugin: &mellanoxv1alpha1.WhereaboutsSpec{ImageSpec: *imageSpec, EnableNodeSlicing: true},
+		}
+		GetManifestObjectsTest(ctx, cr, catalog, imageSpec, s)
+	})
+
+	It("should render the default reconciler schedule without the node slice controller", func() {
+		cr := getTestClusterPolicyWithBaseFields()
+		cr.Spec.SecondaryNetwork = &mellanoxv1alpha1.SecondaryNetworkSpec{
+			IpamPlugin: &mellanoxv1alpha1.WhereaboutsSpec{ImageSpec: *imageSpec},
+		}
+		cronExpression, kinds := getCronExpression(cr)
+		Expect(cronExpression).To(Equal("30 4 * * *"))
+		Expect(kinds).To(ContainElement("DaemonSet"))
+		Expect(kinds).NotTo(ContainElement("Deployment"))
+	})
+
+	It("should render the reconciler schedule and the node slice controller", func() {
+		cr := getTestClusterPolicyWithBaseFields()
+		cr.Spec.SecondaryNetwork = &mellanoxv1alpha1.SecondaryNetworkSpec{
+			IpamPlugin: &mellanoxv1alpha1.WhereaboutsSpec{
+				ImageSpec:                *imageSpec,
+				ReconcilerCronExpression: "*/5 * * * *",
+				EnableNodeSlicing:        true,
+			},
+		}
+		cronExpression, kinds := getCronExpression(cr)
+		Expect(cronExpression).To(Equal("*/5 * * * *"))
+		Expect(kinds).To(ContainElement("Deployment"))
+	})
+})
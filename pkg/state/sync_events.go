@@ -0,0 +1,112 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EventReasonStateSyncError is the reason of the event emitted when a state transitions to SyncStateError
+	EventReasonStateSyncError = "StateSyncError"
+	// EventReasonStateNotReady is the reason of the event emitted when a state stays in SyncStateNotReady
+	// for longer than the configured threshold
+	EventReasonStateNotReady = "StateNotReady"
+	// EventReasonStateDriftDetected is the reason of the event emitted when objects of a state drifted from
+	// their desired state and were reapplied
+	EventReasonStateDriftDetected = "StateDriftDetected"
+	// EventReasonStateObjectUpdated is the reason of the event emitted when objects of a state were updated,
+	// emitted only if STATE_DIFF_EVENTS is enabled
+	EventReasonStateObjectUpdated = "StateObjectUpdated"
+)
+
+// syncEventEmitter emits events on the custom resource when its states fail to sync
+type syncEventEmitter struct {
+	recorder          record.EventRecorder
+	notReadyThreshold time.Duration
+	now               func() time.Time
+
+	mu sync.Mutex
+	// tracked holds the status of the states which are not ready, per custom resource UID and state name
+	tracked map[string]*trackedState
+}
+
+type trackedState struct {
+	status           SyncState
+	since            time.Time
+	notReadyReported bool
+}
+
+func newSyncEventEmitter(recorder record.EventRecorder, notReadyThreshold time.Duration) *syncEventEmitter {
+	return &syncEventEmitter{
+		recorder:          recorder,
+		notReadyThreshold: notReadyThreshold,
+		now:               time.Now,
+		tracked:           make(map[string]*trackedState),
+	}
+}
+
+// emit emits events on the custom resource according to the results of its states sync
+func (e *syncEventEmitter) emit(customResource interface{}, results []Result) {
+	if e == nil || e.recorder == nil {
+		return
+	}
+	obj, ok := customResource.(client.Object)
+	if !ok {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := e.now()
+	for _, result := range results {
+		if len(result.DriftedObjects) > 0 {
+			e.recorder.Eventf(obj, v1.EventTypeWarning, EventReasonStateDriftDetected,
+				"State %s objects drifted from the desired state and were reapplied: %s", result.StateName,
+				strings.Join(result.DriftedObjects, ", "))
+		}
+		for _, update := range result.UpdatedObjects {
+			e.recorder.Eventf(obj, v1.EventTypeNormal, EventReasonStateObjectUpdated,
+				"State %s updated %s", result.StateName, update)
+		}
+		key := string(obj.GetUID()) + "/" + result.StateName
+		if result.Status != SyncStateError && result.Status != SyncStateNotReady {
+			delete(e.tracked, key)
+			continue
+		}
+		tracked, ok := e.tracked[key]
+		if !ok || tracked.status != result.Status {
+			tracked = &trackedState{status: result.Status, since: now}
+			e.tracked[key] = tracked
+			if result.Status == SyncStateError {
+				e.recorder.Eventf(obj, v1.EventTypeWarning, EventReasonStateSyncError,
+					"State %s failed to sync: %v", result.StateName, result.ErrInfo)
+			}
+		}
+		if result.Status == SyncStateNotReady && !tracked.notReadyReported &&
+			now.Sub(tracked.since) >= e.notReadyThreshold {
+			tracked.notReadyReported = true
+			e.recorder.Eventf(obj, v1.EventTypeWarning, EventReasonStateNotReady,
+				"State %s is not ready for %s", result.StateName, now.Sub(tracked.since).Round(time.Second))
+		}
+	}
+}
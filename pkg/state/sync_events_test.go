@@ -0,0 +1,134 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/testing/mocks"
+)
+
+var _ = Describe("Sync events tests", func() {
+	var (
+		recorder *record.FakeRecorder
+		emitter  *syncEventEmitter
+		cr       *mellanoxv1alpha1.NicClusterPolicy
+		now      time.Time
+	)
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		emitter = newSyncEventEmitter(recorder, 5*time.Minute)
+		now = time.Now()
+		emitter.now = func() time.Time { return now }
+		cr = &mellanoxv1alpha1.NicClusterPolicy{ObjectMeta: metav1.ObjectMeta{Name: "test", UID: "uid"}}
+	})
+
+	It("Should emit event once when state transitions to error", func() {
+		results := []Result{{StateName: "state-OFED", Status: SyncStateError,
+			ErrInfo: errors.New("failed to apply DaemonSet nvidia-network-operator/mofed-ubuntu22.04-ds")}}
+		emitter.emit(cr, results)
+		Expect(recorder.Events).To(Receive(And(
+			ContainSubstring("Warning "+EventReasonStateSyncError),
+			ContainSubstring("State state-OFED failed to sync"),
+			ContainSubstring("DaemonSet nvidia-network-operator/mofed-ubuntu22.04-ds"))))
+		emitter.emit(cr, results)
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("Should emit event again after state recovered from error", func() {
+		errResults := []Result{{StateName: "state-OFED", Status: SyncStateError, ErrInfo: errors.New("error")}}
+		emitter.emit(cr, errResults)
+		Expect(recorder.Events).To(Receive())
+		emitter.emit(cr, []Result{{StateName: "state-OFED", Status: SyncStateReady}})
+		emitter.emit(cr, errResults)
+		Expect(recorder.Events).To(Receive(ContainSubstring(EventReasonStateSyncError)))
+	})
+
+	It("Should emit event when objects of a state drifted", func() {
+		emitter.emit(cr, []Result{{StateName: "state-OFED", Status: SyncStateReady,
+			DriftedObjects: []string{"DaemonSet nvidia-network-operator/mofed-ubuntu22.04-ds"}}})
+		Expect(recorder.Events).To(Receive(And(
+			ContainSubstring("Warning "+EventReasonStateDriftDetected),
+			ContainSubstring("DaemonSet nvidia-network-operator/mofed-ubuntu22.04-ds"))))
+		emitter.emit(cr, []Result{{StateName: "state-OFED", Status: SyncStateReady}})
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("Should emit event for each updated object", func() {
+		emitter.emit(cr, []Result{{StateName: "state-OFED", Status: SyncStateReady,
+			UpdatedObjects: []string{"DaemonSet test/ds: spec.template.spec.hostNetwork: <unset> -> true"}}})
+		Expect(recorder.Events).To(Receive(And(
+			ContainSubstring("Normal "+EventReasonStateObjectUpdated),
+			ContainSubstring("State state-OFED updated DaemonSet test/ds: spec.template.spec.hostNetwork"))))
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("Should emit event once when state is not ready longer than threshold", func() {
+		results := []Result{{StateName: "state-OFED", Status: SyncStateNotReady}}
+		emitter.emit(cr, results)
+		Expect(recorder.Events).NotTo(Receive())
+		now = now.Add(4 * time.Minute)
+		emitter.emit(cr, results)
+		Expect(recorder.Events).NotTo(Receive())
+		now = now.Add(time.Minute)
+		emitter.emit(cr, results)
+		Expect(recorder.Events).To(Receive(And(
+			ContainSubstring("Warning "+EventReasonStateNotReady),
+			ContainSubstring("State state-OFED is not ready for 5m0s"))))
+		now = now.Add(time.Minute)
+		emitter.emit(cr, results)
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("Should not emit events for ready and ignored states", func() {
+		now = now.Add(-time.Hour)
+		emitter.emit(cr, []Result{
+			{StateName: "state-OFED", Status: SyncStateReady},
+			{StateName: "state-SRIOV-device-plugin", Status: SyncStateIgnore}})
+		now = now.Add(time.Hour)
+		emitter.emit(cr, []Result{
+			{StateName: "state-OFED", Status: SyncStateReady},
+			{StateName: "state-SRIOV-device-plugin", Status: SyncStateIgnore}})
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("Should emit events from state manager sync", func() {
+		testState := &fakeState{
+			name:        "test",
+			description: "test description",
+			syncState:   SyncStateError,
+			syncErr:     errors.New("sync failed"),
+		}
+		client := mocks.ControllerRuntimeClient{}
+		manager := &stateManager{
+			states: []State{testState},
+			client: &client,
+			events: emitter,
+		}
+		manager.SyncState(context.TODO(), cr, nil)
+		Expect(recorder.Events).To(Receive(ContainSubstring("State test failed to sync: sync failed")))
+	})
+})
@@ -0,0 +1,138 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"reflect"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+// WatchSource is a kind of objects watched for a state, an event of a watched object triggers the reconciliation
+// of the custom resource controlling it only if the event passes all the predicates
+type WatchSource struct {
+	// Object is an object of the watched kind
+	Object client.Object
+	// Predicates filter the events of the watched objects
+	Predicates []predicate.Predicate
+}
+
+// stateObjectsWatchSource returns a WatchSource of the objects of the kind of obj created by the state,
+// i.e. labeled with the name of the state
+func stateObjectsWatchSource(obj client.Object, stateName string, predicates ...predicate.Predicate) WatchSource {
+	return WatchSource{
+		Object:     obj,
+		Predicates: append([]predicate.Predicate{stateLabelPredicate(stateName)}, predicates...),
+	}
+}
+
+// daemonSetsWatchSource returns a WatchSource of the DaemonSets of the state, updates trigger a reconciliation
+// only if the spec or the rollout progress of a DaemonSet changed
+func daemonSetsWatchSource(stateName string) WatchSource {
+	return stateObjectsWatchSource(&appsv1.DaemonSet{}, stateName,
+		predicate.Or(predicate.GenerationChangedPredicate{}, statusChangedPredicate()))
+}
+
+// deploymentsWatchSource returns a WatchSource of the Deployments of the state, updates trigger a reconciliation
+// only if the spec or the rollout progress of a Deployment changed
+func deploymentsWatchSource(stateName string) WatchSource {
+	return stateObjectsWatchSource(&appsv1.Deployment{}, stateName,
+		predicate.Or(predicate.GenerationChangedPredicate{}, statusChangedPredicate()))
+}
+
+// specChangesWatchSource returns a WatchSource of the objects of the kind of obj, updates trigger a reconciliation
+// only if the spec of an object changed
+func specChangesWatchSource(obj client.Object) WatchSource {
+	return WatchSource{Object: obj, Predicates: []predicate.Predicate{predicate.GenerationChangedPredicate{}}}
+}
+
+// stateLabelPredicate passes the events of the objects labeled with the name of the state, an update passes if
+// either the old or the new object is labeled so that removing the label is detected
+func stateLabelPredicate(stateName string) predicate.Predicate {
+	hasLabel := func(obj client.Object) bool {
+		return obj != nil && obj.GetLabels()[consts.StateLabel] == stateName
+	}
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return hasLabel(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return hasLabel(e.ObjectOld) || hasLabel(e.ObjectNew) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return hasLabel(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return hasLabel(e.Object) },
+	}
+}
+
+// controlledByPredicate passes the events of the objects whose controller is a custom resource of the given kind
+// of the mellanox.com API group, the UID of the controller must be set
+func controlledByPredicate(kind string) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		owner := metav1.GetControllerOf(obj)
+		if owner == nil || owner.Kind != kind || owner.UID == "" {
+			return false
+		}
+		gv, err := schema.ParseGroupVersion(owner.APIVersion)
+		return err == nil && gv.Group == mellanoxv1alpha1.GroupVersion.Group
+	})
+}
+
+// statusChangedPredicate passes updates which change the status of a workload, i.e. its rollout progress
+func statusChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return false
+			}
+			switch oldObj := e.ObjectOld.(type) {
+			case *appsv1.DaemonSet:
+				newObj, ok := e.ObjectNew.(*appsv1.DaemonSet)
+				return !ok || !reflect.DeepEqual(oldObj.Status, newObj.Status)
+			case *appsv1.Deployment:
+				newObj, ok := e.ObjectNew.(*appsv1.Deployment)
+				return !ok || !reflect.DeepEqual(oldObj.Status, newObj.Status)
+			default:
+				return true
+			}
+		},
+	}
+}
+
+// mergeWatchSources merges the watch sources of the states keyed by kind name, an event of a kind watched by several
+// states passes if it passes the predicates of one of them
+func mergeWatchSources(sources []map[string]WatchSource) map[string]WatchSource {
+	merged := make(map[string]WatchSource)
+	perKind := make(map[string][]predicate.Predicate)
+	for _, stateSources := range sources {
+		for kindName, source := range stateSources {
+			if _, ok := merged[kindName]; !ok {
+				merged[kindName] = WatchSource{Object: source.Object}
+			}
+			perKind[kindName] = append(perKind[kindName], predicate.And(source.Predicates...))
+		}
+	}
+	for kindName, predicates := range perKind {
+		source := merged[kindName]
+		source.Predicates = []predicate.Predicate{predicate.Or(predicates...)}
+		merged[kindName] = source
+	}
+	return merged
+}
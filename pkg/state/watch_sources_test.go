@@ -0,0 +1,144 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+func updatePassesAll(predicates []predicate.Predicate, e event.UpdateEvent) bool {
+	for _, p := range predicates {
+		if !p.Update(e) {
+			return false
+		}
+	}
+	return true
+}
+
+func createPassesAll(predicates []predicate.Predicate, obj client.Object) bool {
+	for _, p := range predicates {
+		if !p.Create(event.CreateEvent{Object: obj}) {
+			return false
+		}
+	}
+	return true
+}
+
+func ownedDaemonSet(stateName, ownerKind string, generation int64) *appsv1.DaemonSet {
+	controller := true
+	return &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{
+		Name:       "test",
+		Namespace:  "test",
+		Generation: generation,
+		Labels:     map[string]string{consts.StateLabel: stateName},
+		OwnerReferences: []metav1.OwnerReference{{
+			APIVersion: mellanoxv1alpha1.GroupVersion.String(),
+			Kind:       ownerKind,
+			Name:       "nic-cluster-policy",
+			UID:        "1234",
+			Controller: &controller,
+		}},
+	}}
+}
+
+var _ = Describe("Watch sources tests", func() {
+	Context("DaemonSets watch source", func() {
+		source := daemonSetsWatchSource(testState)
+
+		It("Should pass the events of the DaemonSets of the state", func() {
+			Expect(createPassesAll(source.Predicates, ownedDaemonSet(testState, "NicClusterPolicy", 1))).To(BeTrue())
+			Expect(createPassesAll(source.Predicates, ownedDaemonSet("other", "NicClusterPolicy", 1))).To(BeFalse())
+			Expect(createPassesAll(source.Predicates, &appsv1.DaemonSet{})).To(BeFalse())
+		})
+
+		It("Should pass the updates of the spec or the status of the DaemonSets", func() {
+			oldDs := ownedDaemonSet(testState, "NicClusterPolicy", 1)
+			newDs := oldDs.DeepCopy()
+			newDs.Annotations = map[string]string{"test": "test"}
+			Expect(updatePassesAll(source.Predicates, event.UpdateEvent{ObjectOld: oldDs, ObjectNew: newDs})).To(BeFalse())
+
+			newDs.Generation = 2
+			Expect(updatePassesAll(source.Predicates, event.UpdateEvent{ObjectOld: oldDs, ObjectNew: newDs})).To(BeTrue())
+
+			newDs = oldDs.DeepCopy()
+			newDs.Status.NumberAvailable = 1
+			Expect(updatePassesAll(source.Predicates, event.UpdateEvent{ObjectOld: oldDs, ObjectNew: newDs})).To(BeTrue())
+		})
+
+		It("Should pass the update removing the state label", func() {
+			oldDs := ownedDaemonSet(testState, "NicClusterPolicy", 1)
+			newDs := oldDs.DeepCopy()
+			newDs.Labels = nil
+			newDs.Generation = 2
+			Expect(updatePassesAll(source.Predicates, event.UpdateEvent{ObjectOld: oldDs, ObjectNew: newDs})).To(BeTrue())
+		})
+	})
+
+	Context("Manager watch sources", func() {
+		manager := &stateManager{
+			crdKind: "NicClusterPolicy",
+			states: []State{
+				&fakeState{name: "a", watchResources: map[string]WatchSource{
+					"DaemonSet": daemonSetsWatchSource("a"),
+					"ConfigMap": stateObjectsWatchSource(&v1.ConfigMap{}, "a"),
+				}},
+				&fakeState{name: "b", watchResources: map[string]WatchSource{
+					"DaemonSet": daemonSetsWatchSource("b"),
+				}},
+			},
+		}
+		sources := manager.GetWatchSources()
+
+		It("Should merge the watch sources of the states by kind", func() {
+			Expect(sources).To(HaveLen(2))
+			Expect(sources["DaemonSet"].Object).To(BeAssignableToTypeOf(&appsv1.DaemonSet{}))
+			Expect(sources["ConfigMap"].Object).To(BeAssignableToTypeOf(&v1.ConfigMap{}))
+		})
+
+		It("Should pass the events of the objects of one of the states", func() {
+			predicates := sources["DaemonSet"].Predicates
+			Expect(createPassesAll(predicates, ownedDaemonSet("a", "NicClusterPolicy", 1))).To(BeTrue())
+			Expect(createPassesAll(predicates, ownedDaemonSet("b", "NicClusterPolicy", 1))).To(BeTrue())
+			Expect(createPassesAll(predicates, ownedDaemonSet("c", "NicClusterPolicy", 1))).To(BeFalse())
+			Expect(createPassesAll(sources["ConfigMap"].Predicates, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{consts.StateLabel: "b"}}})).To(BeFalse())
+		})
+
+		It("Should pass only the events of the objects controlled by the custom resource kind", func() {
+			predicates := sources["DaemonSet"].Predicates
+			Expect(createPassesAll(predicates, ownedDaemonSet("a", "HostDeviceNetwork", 1))).To(BeFalse())
+
+			ds := ownedDaemonSet("a", "NicClusterPolicy", 1)
+			ds.OwnerReferences[0].APIVersion = "apps/v1"
+			Expect(createPassesAll(predicates, ds)).To(BeFalse())
+
+			ds = ownedDaemonSet("a", "NicClusterPolicy", 1)
+			ds.OwnerReferences[0].Controller = nil
+			Expect(createPassesAll(predicates, ds)).To(BeFalse())
+		})
+	})
+})
@@ -21,9 +21,17 @@
 // Package staticconfig provides static cluster information, required by network-operator
 package staticconfig
 
+import "github.com/Mellanox/network-operator/pkg/clustertype"
+
 // StaticConfig holds static config for the operator.
 type StaticConfig struct {
 	CniBinDirectory string
+	// CniConfDirectory is the location of the CNI configurations on the nodes set for the operator,
+	// the default location is used if empty
+	CniConfDirectory string
+	// ClusterType is the type of the cluster detected at startup, the OpenShift specific objects,
+	// e.g. SecurityContextConstraints, are rendered only on OpenShift
+	ClusterType clustertype.Type
 }
 
 // Provider provides static cluster attributes
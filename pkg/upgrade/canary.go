@@ -0,0 +1,172 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+	"time"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+const (
+	// UpgradeStateCanaryWaitRequired is set on the nodes which are not canary nodes and require the upgrade
+	// while the canary phase is not over, once it is over the state is changed to upgrade-required
+	UpgradeStateCanaryWaitRequired = "canary-wait-required"
+	// UpgradeStateCanarySoakRequired is set on the upgraded canary nodes until the driver is ready on them
+	// for the soak period, once it is over the state is changed to upgrade-done
+	UpgradeStateCanarySoakRequired = "canary-soak-required"
+)
+
+// CanaryManager upgrades the canary nodes first and holds the upgrade of the other nodes
+// until the driver is ready on all canary nodes for the soak period
+type CanaryManager struct {
+	nodeUpgradeStateProvider upgradeLib.NodeUpgradeStateProvider
+	log                      logr.Logger
+	// now returns the current time, it can be overridden in tests
+	now func() time.Time
+}
+
+// NewCanaryManager creates a CanaryManager which reports the node states with the nodeUpgradeStateProvider
+func NewCanaryManager(nodeUpgradeStateProvider upgradeLib.NodeUpgradeStateProvider, log logr.Logger) *CanaryManager {
+	return &CanaryManager{
+		nodeUpgradeStateProvider: nodeUpgradeStateProvider,
+		log:                      log,
+		now:                      time.Now,
+	}
+}
+
+// processCanary moves the upgraded canary nodes to the canary-soak-required state while the driver is not ready
+// on them for the soak period and the other nodes requiring the upgrade to the canary-wait-required state while
+// the canary phase is not over. The nodes are moved back to upgrade-done and upgrade-required states once the
+// phase is over or if the canary is not set. It returns a copy of the cluster upgrade state with the new states
+func (m *CanaryManager) processCanary(ctx context.Context, state *upgradeLib.ClusterUpgradeState,
+	canary *mellanoxv1alpha1.CanarySpec) (*upgradeLib.ClusterUpgradeState, error) {
+	updated := upgradeLib.NewClusterUpgradeState()
+	for upgradeState, nodeStates := range state.NodeStates {
+		updated.NodeStates[upgradeState] = append(updated.NodeStates[upgradeState], nodeStates...)
+	}
+	if canary == nil {
+		if err := m.moveNodes(ctx, &updated, UpgradeStateCanarySoakRequired, upgradeLib.UpgradeStateDone,
+			func(*upgradeLib.NodeUpgradeState) bool { return true }); err != nil {
+			return nil, err
+		}
+		return &updated, m.moveNodes(ctx, &updated, UpgradeStateCanaryWaitRequired,
+			upgradeLib.UpgradeStateUpgradeRequired, func(*upgradeLib.NodeUpgradeState) bool { return true })
+	}
+
+	selector := labels.SelectorFromSet(canary.NodeSelector)
+	isCanary := func(nodeState *upgradeLib.NodeUpgradeState) bool {
+		return selector.Matches(labels.Set(nodeState.Node.Labels))
+	}
+	soak := time.Duration(canary.SoakSeconds) * time.Second
+	isSoaked := func(nodeState *upgradeLib.NodeUpgradeState) bool {
+		return m.isDriverReadyFor(nodeState.DriverPod, soak)
+	}
+	upgradePending := false
+	for _, upgradeState := range []string{upgradeLib.UpgradeStateUpgradeRequired, UpgradeStateCanaryWaitRequired} {
+		for _, nodeState := range updated.NodeStates[upgradeState] {
+			upgradePending = upgradePending || !isCanary(nodeState)
+		}
+	}
+
+	// the soak of the canary nodes is only relevant while the other nodes wait for their upgrade
+	if err := m.moveNodes(ctx, &updated, UpgradeStateCanarySoakRequired, upgradeLib.UpgradeStateDone,
+		func(nodeState *upgradeLib.NodeUpgradeState) bool {
+			return !upgradePending || !isCanary(nodeState) || isSoaked(nodeState)
+		}); err != nil {
+		return nil, err
+	}
+	if err := m.moveNodes(ctx, &updated, upgradeLib.UpgradeStateDone, UpgradeStateCanarySoakRequired,
+		func(nodeState *upgradeLib.NodeUpgradeState) bool {
+			return upgradePending && isCanary(nodeState) && !isSoaked(nodeState)
+		}); err != nil {
+		return nil, err
+	}
+
+	if m.isCanaryPhaseOver(&updated, isCanary, isSoaked) {
+		return &updated, m.moveNodes(ctx, &updated, UpgradeStateCanaryWaitRequired,
+			upgradeLib.UpgradeStateUpgradeRequired, func(*upgradeLib.NodeUpgradeState) bool { return true })
+	}
+	return &updated, m.moveNodes(ctx, &updated, upgradeLib.UpgradeStateUpgradeRequired,
+		UpgradeStateCanaryWaitRequired, func(nodeState *upgradeLib.NodeUpgradeState) bool {
+			return !isCanary(nodeState)
+		})
+}
+
+// isCanaryPhaseOver returns true if all canary nodes are upgraded and the driver is ready on them
+// for the soak period, the phase is over if there is no canary node
+func (m *CanaryManager) isCanaryPhaseOver(state *upgradeLib.ClusterUpgradeState,
+	isCanary, isSoaked func(*upgradeLib.NodeUpgradeState) bool) bool {
+	for upgradeState, nodeStates := range state.NodeStates {
+		for _, nodeState := range nodeStates {
+			if !isCanary(nodeState) {
+				continue
+			}
+			if upgradeState != upgradeLib.UpgradeStateDone || !isSoaked(nodeState) {
+				m.log.V(consts.LogLevelDebug).Info("Canary phase is not over",
+					"node", nodeState.Node.Name, "upgradeState", upgradeState)
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// moveNodes changes the state of the nodes in the from state which match to the to state,
+// both on the nodes and in the cluster upgrade state
+func (m *CanaryManager) moveNodes(ctx context.Context, state *upgradeLib.ClusterUpgradeState, from, to string,
+	match func(*upgradeLib.NodeUpgradeState) bool) error {
+	remaining := make([]*upgradeLib.NodeUpgradeState, 0, len(state.NodeStates[from]))
+	for _, nodeState := range state.NodeStates[from] {
+		if !match(nodeState) {
+			remaining = append(remaining, nodeState)
+			continue
+		}
+		m.log.V(consts.LogLevelInfo).Info("Changing canary upgrade state of the node",
+			"node", nodeState.Node.Name, "from", from, "to", to)
+		if err := m.nodeUpgradeStateProvider.ChangeNodeUpgradeState(ctx, nodeState.Node, to); err != nil {
+			return err
+		}
+		state.NodeStates[to] = append(state.NodeStates[to], nodeState)
+	}
+	if len(remaining) == 0 {
+		delete(state.NodeStates, from)
+		return nil
+	}
+	state.NodeStates[from] = remaining
+	return nil
+}
+
+// isDriverReadyFor returns true if the driver pod is ready for at least the given duration
+func (m *CanaryManager) isDriverReadyFor(pod *corev1.Pod, duration time.Duration) bool {
+	if pod == nil {
+		return false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue && m.now().Sub(cond.LastTransitionTime.Time) >= duration
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+	"time"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+var _ = Describe("Canary upgrade tests", func() {
+	var (
+		stateProvider *fakeNodeUpgradeStateProvider
+		manager       *CanaryManager
+		canary        *mellanoxv1alpha1.CanarySpec
+		now           time.Time
+	)
+
+	// newCanaryNodeState returns the state of a node, a canary node if canary is set, with a driver pod
+	// which is ready since readySince ago, not ready if readySince is negative
+	newCanaryNodeState := func(name string, isCanary bool, readySince time.Duration) *upgradeLib.NodeUpgradeState {
+		node := newTestNode(name)
+		if isCanary {
+			node.Labels = map[string]string{"example.com/canary": "true"}
+		}
+		readyStatus := corev1.ConditionTrue
+		if readySince < 0 {
+			readyStatus = corev1.ConditionFalse
+		}
+		pod := newTestPod("driver-"+name, name)
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: readyStatus,
+			LastTransitionTime: metav1.NewTime(now.Add(-readySince))}}
+		return &upgradeLib.NodeUpgradeState{Node: node, DriverPod: pod}
+	}
+
+	BeforeEach(func() {
+		now = time.Now()
+		stateProvider = newFakeNodeUpgradeStateProvider()
+		manager = NewCanaryManager(stateProvider, log.Log)
+		manager.now = func() time.Time { return now }
+		canary = &mellanoxv1alpha1.CanarySpec{
+			NodeSelector: map[string]string{"example.com/canary": "true"}, SoakSeconds: 600}
+	})
+
+	It("should hold the upgrade of the other nodes while the canary nodes are upgraded", func() {
+		clusterState := upgradeLib.NewClusterUpgradeState()
+		clusterState.NodeStates[upgradeLib.UpgradeStateUpgradeRequired] = []*upgradeLib.NodeUpgradeState{
+			newCanaryNodeState("canary1", true, time.Hour), newCanaryNodeState("node1", false, time.Hour)}
+		clusterState.NodeStates[upgradeLib.UpgradeStateDrainRequired] = []*upgradeLib.NodeUpgradeState{
+			newCanaryNodeState("canary2", true, time.Hour)}
+
+		updated, err := manager.processCanary(context.TODO(), &clusterState, canary)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodeNames(updated.NodeStates[upgradeLib.UpgradeStateUpgradeRequired])).To(Equal([]string{"canary1"}))
+		Expect(nodeNames(updated.NodeStates[UpgradeStateCanaryWaitRequired])).To(Equal([]string{"node1"}))
+		Expect(stateProvider.getState("node1")).To(Equal(UpgradeStateCanaryWaitRequired))
+		Expect(stateProvider.getState("canary1")).To(BeEmpty())
+		Expect(clusterState.NodeStates[upgradeLib.UpgradeStateUpgradeRequired]).To(HaveLen(2))
+	})
+
+	It("should soak the upgraded canary nodes before the other nodes are upgraded", func() {
+		clusterState := upgradeLib.NewClusterUpgradeState()
+		clusterState.NodeStates[upgradeLib.UpgradeStateDone] = []*upgradeLib.NodeUpgradeState{
+			newCanaryNodeState("canary1", true, time.Minute), newCanaryNodeState("canary2", true, time.Hour)}
+		clusterState.NodeStates[UpgradeStateCanaryWaitRequired] = []*upgradeLib.NodeUpgradeState{
+			newCanaryNodeState("node1", false, time.Hour)}
+
+		updated, err := manager.processCanary(context.TODO(), &clusterState, canary)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodeNames(updated.NodeStates[UpgradeStateCanarySoakRequired])).To(Equal([]string{"canary1"}))
+		Expect(nodeNames(updated.NodeStates[upgradeLib.UpgradeStateDone])).To(Equal([]string{"canary2"}))
+		Expect(nodeNames(updated.NodeStates[UpgradeStateCanaryWaitRequired])).To(Equal([]string{"node1"}))
+		Expect(stateProvider.getState("canary1")).To(Equal(UpgradeStateCanarySoakRequired))
+	})
+
+	It("should keep the canary nodes soaking while the driver is not ready", func() {
+		clusterState := upgradeLib.NewClusterUpgradeState()
+		clusterState.NodeStates[UpgradeStateCanarySoakRequired] = []*upgradeLib.NodeUpgradeState{
+			newCanaryNodeState("canary1", true, -1)}
+		clusterState.NodeStates[UpgradeStateCanaryWaitRequired] = []*upgradeLib.NodeUpgradeState{
+			newCanaryNodeState("node1", false, time.Hour)}
+
+		updated, err := manager.processCanary(context.TODO(), &clusterState, canary)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodeNames(updated.NodeStates[UpgradeStateCanarySoakRequired])).To(Equal([]string{"canary1"}))
+		Expect(nodeNames(updated.NodeStates[UpgradeStateCanaryWaitRequired])).To(Equal([]string{"node1"}))
+		Expect(stateProvider.getState("node1")).To(BeEmpty())
+	})
+
+	It("should upgrade the other nodes once the canary nodes are soaked", func() {
+		clusterState := upgradeLib.NewClusterUpgradeState()
+		clusterState.NodeStates[UpgradeStateCanarySoakRequired] = []*upgradeLib.NodeUpgradeState{
+			newCanaryNodeState("canary1", true, 11*time.Minute)}
+		clusterState.NodeStates[UpgradeStateCanaryWaitRequired] = []*upgradeLib.NodeUpgradeState{
+			newCanaryNodeState("node1", false, time.Hour)}
+
+		updated, err := manager.processCanary(context.TODO(), &clusterState, canary)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodeNames(updated.NodeStates[upgradeLib.UpgradeStateDone])).To(Equal([]string{"canary1"}))
+		Expect(nodeNames(updated.NodeStates[upgradeLib.UpgradeStateUpgradeRequired])).To(Equal([]string{"node1"}))
+		Expect(updated.NodeStates).NotTo(HaveKey(UpgradeStateCanaryWaitRequired))
+		Expect(stateProvider.getState("canary1")).To(Equal(upgradeLib.UpgradeStateDone))
+		Expect(stateProvider.getState("node1")).To(Equal(upgradeLib.UpgradeStateUpgradeRequired))
+	})
+
+	It("should not hold the upgrade if there is no canary node", func() {
+		clusterState := upgradeLib.NewClusterUpgradeState()
+		clusterState.NodeStates[upgradeLib.UpgradeStateUpgradeRequired] = []*upgradeLib.NodeUpgradeState{
+			newCanaryNodeState("node1", false, time.Hour)}
+
+		updated, err := manager.processCanary(context.TODO(), &clusterState, canary)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodeNames(updated.NodeStates[upgradeLib.UpgradeStateUpgradeRequired])).To(Equal([]string{"node1"}))
+		Expect(stateProvider.getState("node1")).To(BeEmpty())
+	})
+
+	It("should release the nodes if the canary is not set", func() {
+		clusterState := upgradeLib.NewClusterUpgradeState()
+		clusterState.NodeStates[UpgradeStateCanarySoakRequired] = []*upgradeLib.NodeUpgradeState{
+			newCanaryNodeState("canary1", true, time.Minute)}
+		clusterState.NodeStates[UpgradeStateCanaryWaitRequired] = []*upgradeLib.NodeUpgradeState{
+			newCanaryNodeState("node1", false, time.Hour)}
+
+		updated, err := manager.processCanary(context.TODO(), &clusterState, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodeNames(updated.NodeStates[upgradeLib.UpgradeStateDone])).To(Equal([]string{"canary1"}))
+		Expect(nodeNames(updated.NodeStates[upgradeLib.UpgradeStateUpgradeRequired])).To(Equal([]string{"node1"}))
+		Expect(stateProvider.getState("canary1")).To(Equal(upgradeLib.UpgradeStateDone))
+		Expect(stateProvider.getState("node1")).To(Equal(upgradeLib.UpgradeStateUpgradeRequired))
+	})
+})
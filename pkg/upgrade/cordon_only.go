@@ -0,0 +1,54 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	"github.com/go-logr/logr"
+
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+// skipNodesDrain moves the cordoned nodes which wait for the deletion of their pods or for their drain
+// to the pod-restart-required state, so that the driver pod is restarted without removing the workloads
+// from the node and the DrainManager is bypassed. It returns a copy of the cluster upgrade state
+// in which these nodes are in the pod-restart-required state
+func skipNodesDrain(ctx context.Context, nodeUpgradeStateProvider upgradeLib.NodeUpgradeStateProvider,
+	log logr.Logger, state *upgradeLib.ClusterUpgradeState) (*upgradeLib.ClusterUpgradeState, error) {
+	updated := upgradeLib.NewClusterUpgradeState()
+	for upgradeState, nodeStates := range state.NodeStates {
+		if upgradeState != upgradeLib.UpgradeStatePodDeletionRequired &&
+			upgradeState != upgradeLib.UpgradeStateDrainRequired {
+			updated.NodeStates[upgradeState] = append(updated.NodeStates[upgradeState], nodeStates...)
+			continue
+		}
+		for _, nodeState := range nodeStates {
+			log.V(consts.LogLevelInfo).Info("Cordon-only upgrade, skipping the drain of the node",
+				"node", nodeState.Node.Name, "upgradeState", upgradeState)
+			err := nodeUpgradeStateProvider.ChangeNodeUpgradeState(
+				ctx, nodeState.Node, upgradeLib.UpgradeStatePodRestartRequired)
+			if err != nil {
+				return nil, err
+			}
+			updated.NodeStates[upgradeLib.UpgradeStatePodRestartRequired] = append(
+				updated.NodeStates[upgradeLib.UpgradeStatePodRestartRequired], nodeState)
+		}
+	}
+	return &updated, nil
+}
@@ -0,0 +1,52 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Cordon-only upgrade tests", func() {
+	It("should restart the driver pod of the nodes waiting for the pod deletion or the drain", func() {
+		stateProvider := newFakeNodeUpgradeStateProvider()
+		clusterState := newClusterUpgradeState(map[string][]string{
+			upgradeLib.UpgradeStateCordonRequired:      {"node1"},
+			upgradeLib.UpgradeStateWaitForJobsRequired: {"node2"},
+			upgradeLib.UpgradeStatePodDeletionRequired: {"node3"},
+			upgradeLib.UpgradeStateDrainRequired:       {"node4"},
+			upgradeLib.UpgradeStatePodRestartRequired:  {"node5"},
+		})
+
+		updated, err := skipNodesDrain(context.TODO(), stateProvider, log.Log, clusterState)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.NodeStates).NotTo(HaveKey(upgradeLib.UpgradeStatePodDeletionRequired))
+		Expect(updated.NodeStates).NotTo(HaveKey(upgradeLib.UpgradeStateDrainRequired))
+		Expect(nodeNames(updated.NodeStates[upgradeLib.UpgradeStatePodRestartRequired])).To(
+			ConsistOf("node3", "node4", "node5"))
+		Expect(nodeNames(updated.NodeStates[upgradeLib.UpgradeStateCordonRequired])).To(Equal([]string{"node1"}))
+		Expect(nodeNames(updated.NodeStates[upgradeLib.UpgradeStateWaitForJobsRequired])).To(Equal([]string{"node2"}))
+		Expect(stateProvider.getState("node3")).To(Equal(upgradeLib.UpgradeStatePodRestartRequired))
+		Expect(stateProvider.getState("node4")).To(Equal(upgradeLib.UpgradeStatePodRestartRequired))
+		Expect(stateProvider.getState("node5")).To(BeEmpty())
+		Expect(clusterState.NodeStates[upgradeLib.UpgradeStateDrainRequired]).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,372 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package upgrade contains the network-operator specific extensions of the driver upgrade flow
+// implemented in github.com/NVIDIA/k8s-operator-libs/pkg/upgrade
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubectl/pkg/drain"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+// DrainManager implements upgradeLib.DrainManager interface, in addition to the DrainManagerImpl
+// from the upgrade library it reports the drain progress of each node in a node annotation
+// and retries failed drains according to the retry policy.
+// The drain can be delegated to the node maintenance operator by creating NodeMaintenance objects
+type DrainManager struct {
+	k8sInterface             kubernetes.Interface
+	dynamicClient            dynamic.Interface
+	drainingNodes            *upgradeLib.StringSet
+	nodeUpgradeStateProvider upgradeLib.NodeUpgradeStateProvider
+	log                      logr.Logger
+	eventRecorder            record.EventRecorder
+
+	mu              sync.Mutex
+	retryPolicy     *mellanoxv1alpha1.DrainRetryPolicySpec
+	podDeletion     *mellanoxv1alpha1.PodDeletionOnDrainTimeoutSpec
+	nodeMaintenance bool
+	options         drainOptions
+}
+
+// drainOptions are the options of the drain.Helper which are not part of the drain spec of the upgrade library
+type drainOptions struct {
+	skipWaitForDeleteTimeoutSeconds int
+	disableEviction                 bool
+	namespacePodSelectors           []mellanoxv1alpha1.NamespacePodSelector
+}
+
+// NewDrainManager creates a DrainManager, dynamicClient is used to manage NodeMaintenance objects
+func NewDrainManager(
+	k8sInterface kubernetes.Interface,
+	dynamicClient dynamic.Interface,
+	nodeUpgradeStateProvider upgradeLib.NodeUpgradeStateProvider,
+	log logr.Logger,
+	eventRecorder record.EventRecorder) *DrainManager {
+	return &DrainManager{
+		k8sInterface:             k8sInterface,
+		dynamicClient:            dynamicClient,
+		drainingNodes:            upgradeLib.NewStringSet(),
+		nodeUpgradeStateProvider: nodeUpgradeStateProvider,
+		log:                      log,
+		eventRecorder:            eventRecorder,
+	}
+}
+
+// SetRetryPolicy sets the retry policy for drains scheduled from now on, nil disables retries
+func (m *DrainManager) SetRetryPolicy(retryPolicy *mellanoxv1alpha1.DrainRetryPolicySpec) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retryPolicy = retryPolicy.DeepCopy()
+}
+
+// SetPodDeletionPolicy sets the deletion policy of the pods remaining on the node after the timeout
+// of the drains scheduled from now on, nil disables the deletion
+func (m *DrainManager) SetPodDeletionPolicy(podDeletion *mellanoxv1alpha1.PodDeletionOnDrainTimeoutSpec) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.podDeletion = podDeletion.DeepCopy()
+}
+
+// SetNodeMaintenance sets if the drains scheduled from now on are delegated to the node maintenance operator
+func (m *DrainManager) SetNodeMaintenance(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nodeMaintenance = enabled
+}
+
+// SetDrainOptions sets the eviction, delete timeout and namespace pod selector options of the drain spec
+// for the drains scheduled from now on, nil resets them to the defaults
+func (m *DrainManager) SetDrainOptions(drainSpec *mellanoxv1alpha1.DrainSpec) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.options = drainOptions{}
+	if drainSpec == nil {
+		return
+	}
+	m.options.skipWaitForDeleteTimeoutSeconds = drainSpec.SkipWaitForDeleteTimeoutSeconds
+	m.options.disableEviction = drainSpec.DisableEviction
+	m.options.namespacePodSelectors = slices.Clone(drainSpec.NamespacePodSelectors)
+}
+
+func (m *DrainManager) isNodeMaintenanceEnabled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.nodeMaintenance
+}
+
+// ScheduleNodesDrain receives DrainConfiguration and schedules drain for each node in the list.
+// When the node gets scheduled, it's marked as being drained and therefore will not be scheduled for drain twice
+// if the initial drain didn't complete yet.
+// During the drain the node is cordoned first, and then pods on the node are evicted,
+// the progress of the drain is reported in the DrainProgressAnnotation of the node.
+// If the pod deletion policy is enabled, the pods which were not evicted till the drain timeout are deleted.
+// A failed drain is retried according to the retry policy.
+// If node maintenance is enabled, a NodeMaintenance object is created for the node instead
+// and the drain is completed once the node maintenance operator reports the maintenance succeeded.
+// If the drain is successful, the node moves to UpgradeStatePodRestartRequired state,
+// otherwise it moves to UpgradeStateFailed state.
+func (m *DrainManager) ScheduleNodesDrain(ctx context.Context, drainConfig *upgradeLib.DrainConfiguration) error {
+	m.log.V(consts.LogLevelInfo).Info("Drain Manager, starting Node Drain")
+
+	if len(drainConfig.Nodes) == 0 {
+		m.log.V(consts.LogLevelInfo).Info("Drain Manager, no nodes scheduled to drain")
+		return nil
+	}
+
+	if drainConfig.Spec == nil {
+		return fmt.Errorf("drain spec should not be empty")
+	}
+	if !drainConfig.Spec.Enable {
+		m.log.V(consts.LogLevelInfo).Info("Drain Manager, drain is disabled")
+		return nil
+	}
+
+	m.mu.Lock()
+	retryPolicy := m.retryPolicy
+	podDeletion := m.podDeletion
+	nodeMaintenance := m.nodeMaintenance
+	options := m.options
+	m.mu.Unlock()
+
+	for _, node := range drainConfig.Nodes {
+		node := node
+		if m.drainingNodes.Has(node.Name) {
+			m.log.V(consts.LogLevelInfo).Info("Node is already being drained, skipping", "node", node.Name)
+			continue
+		}
+		m.log.V(consts.LogLevelInfo).Info("Schedule drain for node", "node", node.Name)
+		m.logEvent(node, corev1.EventTypeNormal, "Scheduling drain of the node")
+
+		m.drainingNodes.Add(node.Name)
+		go func() {
+			defer m.drainingNodes.Remove(node.Name)
+			if nodeMaintenance {
+				m.maintainNode(ctx, time.Duration(drainConfig.Spec.TimeoutSecond)*time.Second, node)
+				return
+			}
+			m.drainNode(ctx, drainConfig, options, retryPolicy, podDeletion, node)
+		}()
+	}
+	return nil
+}
+
+// drainNode cordons and drains the node and moves it to the next upgrade state according to the result
+func (m *DrainManager) drainNode(ctx context.Context, drainConfig *upgradeLib.DrainConfiguration,
+	options drainOptions, retryPolicy *mellanoxv1alpha1.DrainRetryPolicySpec,
+	podDeletion *mellanoxv1alpha1.PodDeletionOnDrainTimeoutSpec,
+	node *corev1.Node) {
+	tracker := newDrainProgressTracker(ctx, m.k8sInterface, node.Name, m.log)
+	drainHelper := m.newDrainHelper(ctx, drainConfig, options, tracker)
+
+	if err := drain.RunCordonOrUncordon(drainHelper, node, true); err != nil {
+		m.log.V(consts.LogLevelError).Error(err, "Failed to cordon node", "node", node.Name)
+		cordonFailures.Inc()
+		tracker.failed(err)
+		_ = m.nodeUpgradeStateProvider.ChangeNodeUpgradeState(ctx, node, upgradeLib.UpgradeStateFailed)
+		m.logEvent(node, corev1.EventTypeWarning, fmt.Sprintf("Failed to cordon the node, %s", err.Error()))
+		return
+	}
+	m.log.V(consts.LogLevelInfo).Info("Cordoned the node", "node", node.Name)
+
+	backoff := newDrainBackoff(retryPolicy)
+	start := time.Now()
+	for {
+		tracker.newAttempt()
+		podList, errs := drainHelper.GetPodsForDeletion(node.Name)
+		if len(errs) == 0 {
+			tracker.start(len(podList.Pods()))
+		}
+
+		err := drain.RunNodeDrain(drainHelper, node.Name)
+		if err != nil && podDeletion != nil && podDeletion.Enable {
+			m.log.V(consts.LogLevelWarning).Info("Drain failed, deleting remaining pods", "node", node.Name, "error", err)
+			err = deleteRemainingPods(drainHelper, node.Name, podDeletion)
+		}
+		if err == nil {
+			break
+		}
+		m.log.V(consts.LogLevelError).Error(err, "Failed to drain node", "node", node.Name)
+		tracker.failed(err)
+
+		delay, retry := backoff.next()
+		if !retry {
+			drainDuration.WithLabelValues(drainResultFailure).Observe(time.Since(start).Seconds())
+			_ = m.nodeUpgradeStateProvider.ChangeNodeUpgradeState(ctx, node, upgradeLib.UpgradeStateFailed)
+			m.logEvent(node, corev1.EventTypeWarning, fmt.Sprintf("Failed to drain the node, %s", err.Error()))
+			return
+		}
+		m.log.V(consts.LogLevelInfo).Info("Retry drain of the node", "node", node.Name, "delay", delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+	drainDuration.WithLabelValues(drainResultSuccess).Observe(time.Since(start).Seconds())
+	m.log.V(consts.LogLevelInfo).Info("Drained the node", "node", node.Name)
+	m.logEvent(node, corev1.EventTypeNormal, "Successfully drained the node")
+	tracker.done()
+
+	_ = m.nodeUpgradeStateProvider.ChangeNodeUpgradeState(ctx, node, upgradeLib.UpgradeStatePodRestartRequired)
+}
+
+// deleteRemainingPods deletes the pods remaining on the node, bypassing the eviction and their PodDisruptionBudgets,
+// it fails without deleting any pod if some of the remaining pods are not allowed to be deleted by the policy
+func deleteRemainingPods(drainHelper *drain.Helper, nodeName string,
+	podDeletion *mellanoxv1alpha1.PodDeletionOnDrainTimeoutSpec) error {
+	podList, errs := drainHelper.GetPodsForDeletion(nodeName)
+	if len(errs) > 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+	pods := podList.Pods()
+	for i := range pods {
+		if !isPodDeletionAllowed(podDeletion, pods[i].Namespace) {
+			return fmt.Errorf("pod %s/%s can't be evicted and is not allowed to be deleted",
+				pods[i].Namespace, pods[i].Name)
+		}
+	}
+	deleteHelper := *drainHelper
+	deleteHelper.DisableEviction = true
+	return deleteHelper.DeleteOrEvictPods(pods)
+}
+
+// isPodDeletionAllowed returns true if the pods in the namespace can be deleted according to the policy
+func isPodDeletionAllowed(podDeletion *mellanoxv1alpha1.PodDeletionOnDrainTimeoutSpec, namespace string) bool {
+	if slices.Contains(podDeletion.ExcludeNamespaces, namespace) {
+		return false
+	}
+	return len(podDeletion.Namespaces) == 0 || slices.Contains(podDeletion.Namespaces, namespace)
+}
+
+// drainBackoff computes the delays between drain attempts according to the retry policy
+type drainBackoff struct {
+	attempts    int
+	maxAttempts int
+	delay       time.Duration
+	maxDelay    time.Duration
+	deadline    time.Time
+}
+
+func newDrainBackoff(retryPolicy *mellanoxv1alpha1.DrainRetryPolicySpec) *drainBackoff {
+	b := &drainBackoff{attempts: 1, maxAttempts: 1}
+	if retryPolicy == nil {
+		return b
+	}
+	b.maxAttempts = retryPolicy.MaxAttempts
+	b.delay = time.Duration(retryPolicy.BackoffSeconds) * time.Second
+	b.maxDelay = time.Duration(retryPolicy.MaxBackoffSeconds) * time.Second
+	if retryPolicy.DeadlineSeconds > 0 {
+		b.deadline = time.Now().Add(time.Duration(retryPolicy.DeadlineSeconds) * time.Second)
+	}
+	return b
+}
+
+// next returns the delay before the next attempt, or false if the drain should not be retried anymore
+func (b *drainBackoff) next() (time.Duration, bool) {
+	if b.attempts >= b.maxAttempts {
+		return 0, false
+	}
+	delay := b.delay
+	if b.maxDelay > 0 && delay > b.maxDelay {
+		delay = b.maxDelay
+	}
+	if !b.deadline.IsZero() && time.Now().Add(delay).After(b.deadline) {
+		return 0, false
+	}
+	b.attempts++
+	b.delay *= 2
+	return delay, true
+}
+
+// newDrainHelper creates drain.Helper according to the drain spec and options which reports evicted pods
+// to the tracker
+func (m *DrainManager) newDrainHelper(ctx context.Context, drainConfig *upgradeLib.DrainConfiguration,
+	options drainOptions, tracker *drainProgressTracker) *drain.Helper {
+	drainSpec := drainConfig.Spec
+	return &drain.Helper{
+		Ctx:    ctx,
+		Client: m.k8sInterface,
+		Force:  drainSpec.Force,
+		// OFED Drivers Pods are part of a DaemonSet, so, this option needs to be set to true
+		IgnoreAllDaemonSets: true,
+		DeleteEmptyDirData:  drainSpec.DeleteEmptyDir,
+		GracePeriodSeconds:  -1,
+		Timeout:             time.Duration(drainSpec.TimeoutSecond) * time.Second,
+		PodSelector:         drainSpec.PodSelector,
+		DisableEviction:     options.disableEviction,
+		AdditionalFilters:   []drain.PodFilter{m.newNamespacePodFilter(options.namespacePodSelectors)},
+
+		SkipWaitForDeleteTimeoutSeconds: options.skipWaitForDeleteTimeoutSeconds,
+		OnPodDeletedOrEvicted: func(pod *corev1.Pod, usingEviction bool) {
+			verbStr := "Deleted"
+			if usingEviction {
+				verbStr = "Evicted"
+			}
+			m.log.V(consts.LogLevelInfo).Info(fmt.Sprintf("%s pod from Node %s/%s", verbStr, pod.Namespace, pod.Name))
+			tracker.podEvicted()
+		},
+		Out:    os.Stdout,
+		ErrOut: os.Stdout,
+	}
+}
+
+// newNamespacePodFilter returns a drain.PodFilter which skips the pods of the namespaces with a pod selector
+// which don't match it, invalid selectors are ignored
+func (m *DrainManager) newNamespacePodFilter(
+	namespacePodSelectors []mellanoxv1alpha1.NamespacePodSelector) drain.PodFilter {
+	selectors := make(map[string]labels.Selector, len(namespacePodSelectors))
+	for _, namespacePodSelector := range namespacePodSelectors {
+		selector, err := labels.Parse(namespacePodSelector.PodSelector)
+		if err != nil {
+			m.log.V(consts.LogLevelWarning).Info("Ignoring invalid namespace pod selector",
+				"namespace", namespacePodSelector.Namespace, "podSelector", namespacePodSelector.PodSelector,
+				"error", err)
+			continue
+		}
+		selectors[namespacePodSelector.Namespace] = selector
+	}
+	return func(pod corev1.Pod) drain.PodDeleteStatus {
+		selector, ok := selectors[pod.Namespace]
+		if ok && !selector.Matches(labels.Set(pod.Labels)) {
+			return drain.MakePodDeleteStatusSkip()
+		}
+		return drain.MakePodDeleteStatusOkay()
+	}
+}
+
+func (m *DrainManager) logEvent(node *corev1.Node, eventType, message string) {
+	if m.eventRecorder != nil {
+		m.eventRecorder.Event(node, eventType, upgradeLib.GetEventReason(), message)
+	}
+}
@@ -0,0 +1,358 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	upgradeApi "github.com/NVIDIA/k8s-operator-libs/api/upgrade/v1alpha1"
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+func newTestNode(name string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func newTestPod(name, nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs", UID: "uid", Controller: ptrTo(true)}},
+		},
+		Spec: corev1.PodSpec{NodeName: nodeName},
+	}
+}
+
+// newFakeClientset creates a fake clientset whose discovery reports no eviction support, so pods are deleted
+func newFakeClientset(objects ...runtime.Object) *fake.Clientset {
+	k8sInterface := fake.NewSimpleClientset(objects...)
+	k8sInterface.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod"}},
+	}}
+	return k8sInterface
+}
+
+func ptrTo[T any](v T) *T {
+	return &v
+}
+
+func getNode(k8sInterface *fake.Clientset, name string) *corev1.Node {
+	node, err := k8sInterface.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
+	Expect(err).NotTo(HaveOccurred())
+	return node
+}
+
+var _ = Describe("DrainManager tests", func() {
+	var (
+		k8sInterface  *fake.Clientset
+		stateProvider *fakeNodeUpgradeStateProvider
+		drainManager  *DrainManager
+		drainSpec     *upgradeApi.DrainSpec
+	)
+
+	BeforeEach(func() {
+		stateProvider = newFakeNodeUpgradeStateProvider()
+		drainSpec = &upgradeApi.DrainSpec{Enable: true, Force: true, TimeoutSecond: 5}
+	})
+
+	JustBeforeEach(func() {
+		drainManager = NewDrainManager(k8sInterface, nil, stateProvider, log.Log, nil)
+	})
+
+	Context("successful drain", func() {
+		BeforeEach(func() {
+			k8sInterface = newFakeClientset(newTestNode("node1"),
+				newTestPod("pod1", "node1"), newTestPod("pod2", "node1"))
+		})
+
+		It("should cordon and drain the node and remove the progress annotation", func() {
+			node := getNode(k8sInterface, "node1")
+			err := drainManager.ScheduleNodesDrain(context.TODO(),
+				&upgradeLib.DrainConfiguration{Spec: drainSpec, Nodes: []*corev1.Node{node}})
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() string { return stateProvider.getState("node1") }).
+				WithTimeout(5 * time.Second).Should(Equal(upgradeLib.UpgradeStatePodRestartRequired))
+
+			node = getNode(k8sInterface, "node1")
+			Expect(node.Spec.Unschedulable).To(BeTrue())
+			Expect(node.Annotations).NotTo(HaveKey(GetDrainProgressAnnotationKey()))
+			pods, err := k8sInterface.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pods.Items).To(BeEmpty())
+		})
+
+		It("should report progress in the node annotation", func() {
+			tracker := newDrainProgressTracker(context.TODO(), k8sInterface, "node1", log.Log)
+			tracker.start(2)
+			tracker.podEvicted()
+			progress, err := GetDrainProgress(getNode(k8sInterface, "node1"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(progress).NotTo(BeNil())
+			Expect(progress.PodsEvicted).To(Equal(1))
+			Expect(progress.PodsRemaining).To(Equal(1))
+			Expect(progress.LastError).To(BeEmpty())
+		})
+	})
+
+	Context("failed drain", func() {
+		BeforeEach(func() {
+			k8sInterface = newFakeClientset(newTestNode("node1"), newTestPod("pod1", "node1"))
+			k8sInterface.PrependReactor("delete", "pods",
+				func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, errors.New("failed to delete pod")
+				})
+		})
+
+		It("should move the node to failed state and keep the progress annotation", func() {
+			node := getNode(k8sInterface, "node1")
+			err := drainManager.ScheduleNodesDrain(context.TODO(),
+				&upgradeLib.DrainConfiguration{Spec: drainSpec, Nodes: []*corev1.Node{node}})
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() string { return stateProvider.getState("node1") }).
+				WithTimeout(5 * time.Second).Should(Equal(upgradeLib.UpgradeStateFailed))
+
+			progress, err := GetDrainProgress(getNode(k8sInterface, "node1"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(progress).NotTo(BeNil())
+			Expect(progress.PodsRemaining).To(Equal(1))
+			Expect(progress.LastError).To(ContainSubstring("failed to delete pod"))
+		})
+	})
+
+	Context("drain retries", func() {
+		var deleteFailures int32
+		BeforeEach(func() {
+			deleteFailures = 0
+			k8sInterface = newFakeClientset(newTestNode("node1"), newTestPod("pod1", "node1"))
+			k8sInterface.PrependReactor("delete", "pods",
+				func(action k8stesting.Action) (bool, runtime.Object, error) {
+					if atomic.AddInt32(&deleteFailures, 1) <= 2 {
+						return true, nil, errors.New("failed to delete pod")
+					}
+					return false, nil, nil
+				})
+		})
+
+		It("should succeed after retries", func() {
+			drainManager.SetRetryPolicy(&mellanoxv1alpha1.DrainRetryPolicySpec{MaxAttempts: 3})
+			node := getNode(k8sInterface, "node1")
+			err := drainManager.ScheduleNodesDrain(context.TODO(),
+				&upgradeLib.DrainConfiguration{Spec: drainSpec, Nodes: []*corev1.Node{node}})
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() string { return stateProvider.getState("node1") }).
+				WithTimeout(5 * time.Second).Should(Equal(upgradeLib.UpgradeStatePodRestartRequired))
+		})
+
+		It("should fail when attempts are exhausted", func() {
+			drainManager.SetRetryPolicy(&mellanoxv1alpha1.DrainRetryPolicySpec{MaxAttempts: 2})
+			node := getNode(k8sInterface, "node1")
+			err := drainManager.ScheduleNodesDrain(context.TODO(),
+				&upgradeLib.DrainConfiguration{Spec: drainSpec, Nodes: []*corev1.Node{node}})
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() string { return stateProvider.getState("node1") }).
+				WithTimeout(5 * time.Second).Should(Equal(upgradeLib.UpgradeStateFailed))
+			progress, err := GetDrainProgress(getNode(k8sInterface, "node1"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(progress.Attempt).To(Equal(2))
+		})
+	})
+
+	Context("pod deletion on drain timeout", func() {
+		BeforeEach(func() {
+			drainSpec.TimeoutSecond = 1
+			k8sInterface = newFakeClientset(newTestNode("node1"), newTestPod("pod1", "node1"))
+			// pods are evicted and the eviction is always blocked, as if by a PodDisruptionBudget
+			k8sInterface.Resources = []*metav1.APIResourceList{{
+				GroupVersion: "v1",
+				APIResources: []metav1.APIResource{
+					{Name: "pods", Kind: "Pod"},
+					{Name: "pods/eviction", Kind: "Eviction", Group: "policy", Version: "v1"}},
+			}}
+			k8sInterface.PrependReactor("create", "pods",
+				func(action k8stesting.Action) (bool, runtime.Object, error) {
+					if action.GetSubresource() != "eviction" {
+						return false, nil, nil
+					}
+					return true, nil, apierrors.NewTooManyRequests("Cannot evict pod", 10)
+				})
+		})
+
+		// the blocked eviction is retried after 5 seconds, so the drain timeout expires only after the retry
+		It("should delete the pods which can't be evicted", func() {
+			drainManager.SetPodDeletionPolicy(&mellanoxv1alpha1.PodDeletionOnDrainTimeoutSpec{Enable: true})
+			node := getNode(k8sInterface, "node1")
+			err := drainManager.ScheduleNodesDrain(context.TODO(),
+				&upgradeLib.DrainConfiguration{Spec: drainSpec, Nodes: []*corev1.Node{node}})
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() string { return stateProvider.getState("node1") }).
+				WithTimeout(10 * time.Second).Should(Equal(upgradeLib.UpgradeStatePodRestartRequired))
+			pods, err := k8sInterface.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pods.Items).To(BeEmpty())
+		})
+
+		It("should not delete the pods in excluded namespaces", func() {
+			drainManager.SetPodDeletionPolicy(&mellanoxv1alpha1.PodDeletionOnDrainTimeoutSpec{
+				Enable: true, ExcludeNamespaces: []string{"default"}})
+			node := getNode(k8sInterface, "node1")
+			err := drainManager.ScheduleNodesDrain(context.TODO(),
+				&upgradeLib.DrainConfiguration{Spec: drainSpec, Nodes: []*corev1.Node{node}})
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() string { return stateProvider.getState("node1") }).
+				WithTimeout(10 * time.Second).Should(Equal(upgradeLib.UpgradeStateFailed))
+			pods, err := k8sInterface.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pods.Items).To(HaveLen(1))
+		})
+	})
+
+	Context("drain options", func() {
+		BeforeEach(func() {
+			labeledPod := newTestPod("pod1", "node1")
+			labeledPod.Labels = map[string]string{"app": "rdma"}
+			k8sInterface = newFakeClientset(newTestNode("node1"), labeledPod, newTestPod("pod2", "node1"))
+			// the eviction is always blocked, as if by a PodDisruptionBudget
+			k8sInterface.Resources = []*metav1.APIResourceList{{
+				GroupVersion: "v1",
+				APIResources: []metav1.APIResource{
+					{Name: "pods", Kind: "Pod"},
+					{Name: "pods/eviction", Kind: "Eviction", Group: "policy", Version: "v1"}},
+			}}
+			k8sInterface.PrependReactor("create", "pods",
+				func(action k8stesting.Action) (bool, runtime.Object, error) {
+					if action.GetSubresource() != "eviction" {
+						return false, nil, nil
+					}
+					return true, nil, apierrors.NewTooManyRequests("Cannot evict pod", 10)
+				})
+		})
+
+		It("should delete the pods if the eviction is disabled", func() {
+			drainManager.SetDrainOptions(&mellanoxv1alpha1.DrainSpec{DisableEviction: true})
+			node := getNode(k8sInterface, "node1")
+			err := drainManager.ScheduleNodesDrain(context.TODO(),
+				&upgradeLib.DrainConfiguration{Spec: drainSpec, Nodes: []*corev1.Node{node}})
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() string { return stateProvider.getState("node1") }).
+				WithTimeout(5 * time.Second).Should(Equal(upgradeLib.UpgradeStatePodRestartRequired))
+			pods, err := k8sInterface.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pods.Items).To(BeEmpty())
+		})
+
+		It("should only drain the pods matching the selector of their namespace", func() {
+			drainManager.SetDrainOptions(&mellanoxv1alpha1.DrainSpec{DisableEviction: true,
+				NamespacePodSelectors: []mellanoxv1alpha1.NamespacePodSelector{
+					{Namespace: "default", PodSelector: "app=rdma"}, {Namespace: "other", PodSelector: "app=other"}}})
+			node := getNode(k8sInterface, "node1")
+			err := drainManager.ScheduleNodesDrain(context.TODO(),
+				&upgradeLib.DrainConfiguration{Spec: drainSpec, Nodes: []*corev1.Node{node}})
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() string { return stateProvider.getState("node1") }).
+				WithTimeout(5 * time.Second).Should(Equal(upgradeLib.UpgradeStatePodRestartRequired))
+			pods, err := k8sInterface.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pods.Items).To(HaveLen(1))
+			Expect(pods.Items[0].Name).To(Equal("pod2"))
+		})
+
+		It("should set the options of the drain helper", func() {
+			drainManager.SetDrainOptions(&mellanoxv1alpha1.DrainSpec{
+				SkipWaitForDeleteTimeoutSeconds: 30, DisableEviction: true})
+			drainManager.mu.Lock()
+			options := drainManager.options
+			drainManager.mu.Unlock()
+			drainHelper := drainManager.newDrainHelper(context.TODO(), &upgradeLib.DrainConfiguration{Spec: drainSpec},
+				options, newDrainProgressTracker(context.TODO(), k8sInterface, "node1", log.Log))
+			Expect(drainHelper.SkipWaitForDeleteTimeoutSeconds).To(Equal(30))
+			Expect(drainHelper.DisableEviction).To(BeTrue())
+
+			drainManager.SetDrainOptions(nil)
+			Expect(drainManager.options).To(Equal(drainOptions{}))
+		})
+	})
+
+	Context("pod deletion policy", func() {
+		DescribeTable("should filter namespaces",
+			func(namespaces, excludeNamespaces []string, namespace string, allowed bool) {
+				policy := &mellanoxv1alpha1.PodDeletionOnDrainTimeoutSpec{
+					Enable: true, Namespaces: namespaces, ExcludeNamespaces: excludeNamespaces}
+				Expect(isPodDeletionAllowed(policy, namespace)).To(Equal(allowed))
+			},
+			Entry("all namespaces", nil, nil, "ns1", true),
+			Entry("allowed namespace", []string{"ns1"}, nil, "ns1", true),
+			Entry("not allowed namespace", []string{"ns1"}, nil, "ns2", false),
+			Entry("excluded namespace", nil, []string{"ns1"}, "ns1", false),
+			Entry("excluded takes precedence", []string{"ns1"}, []string{"ns1"}, "ns1", false),
+		)
+	})
+
+	Context("drain backoff", func() {
+		It("should not retry without retry policy", func() {
+			_, retry := newDrainBackoff(nil).next()
+			Expect(retry).To(BeFalse())
+		})
+
+		It("should double the delay up to the max delay", func() {
+			b := newDrainBackoff(&mellanoxv1alpha1.DrainRetryPolicySpec{
+				MaxAttempts: 4, BackoffSeconds: 10, MaxBackoffSeconds: 30})
+			delays := make([]time.Duration, 0)
+			for {
+				delay, retry := b.next()
+				if !retry {
+					break
+				}
+				delays = append(delays, delay)
+			}
+			Expect(delays).To(Equal([]time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second}))
+		})
+
+		It("should not retry after the deadline", func() {
+			b := newDrainBackoff(&mellanoxv1alpha1.DrainRetryPolicySpec{
+				MaxAttempts: 10, BackoffSeconds: 20, DeadlineSeconds: 30})
+			_, retry := b.next()
+			Expect(retry).To(BeTrue())
+			_, retry = b.next()
+			Expect(retry).To(BeFalse())
+		})
+	})
+
+	It("should return error if drain spec is empty", func() {
+		k8sInterface = newFakeClientset()
+		drainManager = NewDrainManager(k8sInterface, nil, stateProvider, log.Log, nil)
+		err := drainManager.ScheduleNodesDrain(context.TODO(),
+			&upgradeLib.DrainConfiguration{Nodes: []*corev1.Node{newTestNode("node1")}})
+		Expect(err).To(HaveOccurred())
+	})
+})
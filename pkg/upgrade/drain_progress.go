@@ -0,0 +1,154 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+// DrainProgressAnnotationKeyFmt is the format of the node annotation key which holds the drain progress of the node
+const DrainProgressAnnotationKeyFmt = "nvidia.com/%s-driver-upgrade.drain-progress"
+
+// GetDrainProgressAnnotationKey returns the key of the node annotation which holds the drain progress of the node
+func GetDrainProgressAnnotationKey() string {
+	return fmt.Sprintf(DrainProgressAnnotationKeyFmt, upgradeLib.DriverName)
+}
+
+// DrainProgress describes the progress of a node drain, it is stored as JSON in the node annotation
+type DrainProgress struct {
+	// StartTime is the time the drain of the node started
+	StartTime time.Time `json:"startTime"`
+	// ElapsedSeconds is the time passed since StartTime till the last progress update
+	ElapsedSeconds int64 `json:"elapsedSeconds"`
+	// PodsEvicted is the number of pods evicted or deleted from the node
+	PodsEvicted int `json:"podsEvicted"`
+	// PodsRemaining is the number of pods which are yet to be evicted from the node
+	PodsRemaining int `json:"podsRemaining"`
+	// Attempt is the number of the current drain attempt, starting from 1
+	Attempt int `json:"attempt"`
+	// LastError is the last error which occurred during the drain
+	LastError string `json:"lastError,omitempty"`
+}
+
+// GetDrainProgress returns the drain progress reported in the node annotation, nil if not reported
+func GetDrainProgress(node *corev1.Node) (*DrainProgress, error) {
+	value, ok := node.Annotations[GetDrainProgressAnnotationKey()]
+	if !ok {
+		return nil, nil
+	}
+	progress := &DrainProgress{}
+	if err := json.Unmarshal([]byte(value), progress); err != nil {
+		return nil, fmt.Errorf("failed to parse drain progress of node %s: %v", node.Name, err)
+	}
+	return progress, nil
+}
+
+// drainProgressTracker tracks the drain progress of a single node and reports it in the node annotation
+type drainProgressTracker struct {
+	ctx          context.Context
+	k8sInterface kubernetes.Interface
+	nodeName     string
+	log          logr.Logger
+
+	mu       sync.Mutex
+	progress DrainProgress
+}
+
+func newDrainProgressTracker(ctx context.Context, k8sInterface kubernetes.Interface, nodeName string,
+	log logr.Logger) *drainProgressTracker {
+	return &drainProgressTracker{
+		ctx:          ctx,
+		k8sInterface: k8sInterface,
+		nodeName:     nodeName,
+		log:          log,
+		progress:     DrainProgress{StartTime: time.Now().UTC().Truncate(time.Second)},
+	}
+}
+
+// newAttempt reports the start of a new drain attempt
+func (t *drainProgressTracker) newAttempt() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.progress.Attempt++
+}
+
+// start reports the number of pods which should be evicted from the node
+func (t *drainProgressTracker) start(podsToEvict int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.progress.PodsRemaining = podsToEvict
+	t.report()
+}
+
+// podEvicted reports a single pod eviction
+func (t *drainProgressTracker) podEvicted() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.progress.PodsEvicted++
+	if t.progress.PodsRemaining > 0 {
+		t.progress.PodsRemaining--
+	}
+	t.report()
+}
+
+// failed reports a drain failure, the annotation is kept on the node for troubleshooting
+func (t *drainProgressTracker) failed(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.progress.LastError = err.Error()
+	t.report()
+}
+
+// done removes the drain progress annotation from the node once the drain has completed
+func (t *drainProgressTracker) done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.patch(fmt.Sprintf(`{"metadata":{"annotations":{%q: null}}}`, GetDrainProgressAnnotationKey()))
+}
+
+// report writes the current progress to the node annotation, must be called with the lock held
+func (t *drainProgressTracker) report() {
+	t.progress.ElapsedSeconds = int64(time.Since(t.progress.StartTime).Seconds())
+	value, err := json.Marshal(t.progress)
+	if err != nil {
+		t.log.V(consts.LogLevelError).Error(err, "Failed to marshal drain progress", "node", t.nodeName)
+		return
+	}
+	t.patch(fmt.Sprintf(`{"metadata":{"annotations":{%q: %q}}}`, GetDrainProgressAnnotationKey(), string(value)))
+}
+
+func (t *drainProgressTracker) patch(patch string) {
+	_, err := t.k8sInterface.CoreV1().Nodes().Patch(t.ctx, t.nodeName, types.MergePatchType, []byte(patch),
+		metav1.PatchOptions{})
+	if err != nil {
+		// progress reporting is best effort, it should not fail the drain
+		t.log.V(consts.LogLevelWarning).Error(err, "Failed to update drain progress annotation", "node", t.nodeName)
+	}
+}
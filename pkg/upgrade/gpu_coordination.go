@@ -0,0 +1,133 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+	"fmt"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+const (
+	// gpuDriverName is the driver name used by the NVIDIA GPU Operator in the upgrade labels of the nodes
+	gpuDriverName = "gpu"
+	// GPUUpgradePausedAnnotationKeyFmt is the format of the node annotation key which marks that the GPU driver
+	// upgrade of the node was paused by the network-operator
+	GPUUpgradePausedAnnotationKeyFmt = "nvidia.com/%s-driver-upgrade.gpu-upgrade-paused"
+)
+
+// GetGPUUpgradePausedAnnotationKey returns the key of the node annotation which marks that the GPU driver
+// upgrade of the node was paused by the network-operator
+func GetGPUUpgradePausedAnnotationKey() string {
+	return fmt.Sprintf(GPUUpgradePausedAnnotationKeyFmt, upgradeLib.DriverName)
+}
+
+// GetGPUUpgradeSkipLabelKey returns the key of the node label which makes the GPU Operator skip the node
+// during the GPU driver upgrade
+func GetGPUUpgradeSkipLabelKey() string {
+	return fmt.Sprintf(upgradeLib.UpgradeSkipNodeLabelKeyFmt, gpuDriverName)
+}
+
+func getGPUUpgradeStateLabelKey() string {
+	return fmt.Sprintf(upgradeLib.UpgradeStateLabelKeyFmt, gpuDriverName)
+}
+
+// IsUpgradeInProgress returns true if the upgrade state label with the given key reports an upgrade
+// which already started on the node, i.e. the node may be cordoned, drained or its driver restarted
+func IsUpgradeInProgress(node *corev1.Node, upgradeStateLabelKey string) bool {
+	switch node.Labels[upgradeStateLabelKey] {
+	case upgradeLib.UpgradeStateUnknown, upgradeLib.UpgradeStateUpgradeRequired,
+		upgradeLib.UpgradeStateDone, upgradeLib.UpgradeStateFailed,
+		UpgradeStateCanaryWaitRequired, UpgradeStateCanarySoakRequired:
+		return false
+	}
+	return true
+}
+
+// gpuUpgradeCoordinator makes sure that the driver and the GPU driver are never upgraded on the same node
+// at the same time, it relies on the upgrade state and skip labels of the GPU Operator
+type gpuUpgradeCoordinator struct {
+	k8sInterface kubernetes.Interface
+	log          logr.Logger
+}
+
+// excludeGPUUpgradingNodes returns a copy of the cluster upgrade state without the nodes waiting for the driver
+// upgrade on which the GPU driver upgrade is in progress, these nodes are kept in upgrade-required state
+func (c *gpuUpgradeCoordinator) excludeGPUUpgradingNodes(
+	state *upgradeLib.ClusterUpgradeState) *upgradeLib.ClusterUpgradeState {
+	filtered := upgradeLib.NewClusterUpgradeState()
+	for upgradeState, nodeStates := range state.NodeStates {
+		if upgradeState != upgradeLib.UpgradeStateUpgradeRequired {
+			filtered.NodeStates[upgradeState] = nodeStates
+			continue
+		}
+		for _, nodeState := range nodeStates {
+			if IsUpgradeInProgress(nodeState.Node, getGPUUpgradeStateLabelKey()) {
+				c.log.V(consts.LogLevelInfo).Info("GPU driver upgrade is in progress, postponing driver upgrade",
+					"node", nodeState.Node.Name)
+				continue
+			}
+			filtered.NodeStates[upgradeState] = append(filtered.NodeStates[upgradeState], nodeState)
+		}
+	}
+	return &filtered
+}
+
+// syncGPUUpgradePause pauses the GPU driver upgrade on the nodes on which the driver upgrade is in progress
+// by setting the GPU Operator skip label and resumes it once the driver upgrade is over or the coordination
+// is disabled. The skip label is removed only from the nodes on which it was set by the network-operator
+func (c *gpuUpgradeCoordinator) syncGPUUpgradePause(ctx context.Context,
+	state *upgradeLib.ClusterUpgradeState, enabled bool) error {
+	for _, nodeStates := range state.NodeStates {
+		for _, nodeState := range nodeStates {
+			node := nodeState.Node
+			_, paused := node.Annotations[GetGPUUpgradePausedAnnotationKey()]
+			pause := enabled && IsUpgradeInProgress(node, upgradeLib.GetUpgradeStateLabelKey())
+			var patch string
+			switch {
+			case pause && !paused:
+				if node.Labels[GetGPUUpgradeSkipLabelKey()] == "true" {
+					// the GPU driver upgrade of the node is already skipped by the user
+					continue
+				}
+				c.log.V(consts.LogLevelInfo).Info("Pausing GPU driver upgrade", "node", node.Name)
+				patch = fmt.Sprintf(`{"metadata":{"labels":{%q: "true"},"annotations":{%q: "true"}}}`,
+					GetGPUUpgradeSkipLabelKey(), GetGPUUpgradePausedAnnotationKey())
+			case !pause && paused:
+				c.log.V(consts.LogLevelInfo).Info("Resuming GPU driver upgrade", "node", node.Name)
+				patch = fmt.Sprintf(`{"metadata":{"labels":{%q: null},"annotations":{%q: null}}}`,
+					GetGPUUpgradeSkipLabelKey(), GetGPUUpgradePausedAnnotationKey())
+			default:
+				continue
+			}
+			_, err := c.k8sInterface.CoreV1().Nodes().Patch(
+				ctx, node.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to update GPU driver upgrade pause of node %s: %v", node.Name, err)
+			}
+		}
+	}
+	return nil
+}
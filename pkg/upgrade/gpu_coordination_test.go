@@ -0,0 +1,127 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func newLabeledTestNode(name string, labels, annotations map[string]string) *corev1.Node {
+	node := newTestNode(name)
+	node.Labels = labels
+	node.Annotations = annotations
+	return node
+}
+
+var _ = Describe("GPU upgrade coordination tests", func() {
+	var (
+		k8sInterface *fake.Clientset
+		coordinator  *gpuUpgradeCoordinator
+	)
+
+	BeforeEach(func() {
+		k8sInterface = newFakeClientset()
+		coordinator = &gpuUpgradeCoordinator{k8sInterface: k8sInterface, log: log.Log}
+	})
+
+	It("should exclude nodes with GPU driver upgrade in progress from upgrade", func() {
+		gpuState := getGPUUpgradeStateLabelKey()
+		state := upgradeLib.NewClusterUpgradeState()
+		state.NodeStates[upgradeLib.UpgradeStateUpgradeRequired] = []*upgradeLib.NodeUpgradeState{
+			{Node: newLabeledTestNode("idle", nil, nil)},
+			{Node: newLabeledTestNode("gpu-required", map[string]string{
+				gpuState: upgradeLib.UpgradeStateUpgradeRequired}, nil)},
+			{Node: newLabeledTestNode("gpu-draining", map[string]string{
+				gpuState: upgradeLib.UpgradeStateDrainRequired}, nil)},
+			{Node: newLabeledTestNode("gpu-done", map[string]string{gpuState: upgradeLib.UpgradeStateDone}, nil)},
+		}
+		state.NodeStates[upgradeLib.UpgradeStateDrainRequired] = []*upgradeLib.NodeUpgradeState{
+			{Node: newLabeledTestNode("draining", map[string]string{
+				gpuState: upgradeLib.UpgradeStateCordonRequired}, nil)},
+		}
+
+		filtered := coordinator.excludeGPUUpgradingNodes(&state)
+
+		var names []string
+		for _, nodeState := range filtered.NodeStates[upgradeLib.UpgradeStateUpgradeRequired] {
+			names = append(names, nodeState.Node.Name)
+		}
+		Expect(names).To(ConsistOf("idle", "gpu-required", "gpu-done"))
+		Expect(filtered.NodeStates[upgradeLib.UpgradeStateDrainRequired]).To(HaveLen(1))
+		Expect(state.NodeStates[upgradeLib.UpgradeStateUpgradeRequired]).To(HaveLen(4))
+	})
+
+	It("should pause GPU driver upgrade on nodes being upgraded and resume it when done", func() {
+		node := newLabeledTestNode("node", map[string]string{
+			upgradeLib.GetUpgradeStateLabelKey(): upgradeLib.UpgradeStateDrainRequired}, nil)
+		k8sInterface = newFakeClientset(node.DeepCopy())
+		coordinator.k8sInterface = k8sInterface
+		state := upgradeLib.NewClusterUpgradeState()
+		state.NodeStates[upgradeLib.UpgradeStateDrainRequired] = []*upgradeLib.NodeUpgradeState{{Node: node}}
+
+		Expect(coordinator.syncGPUUpgradePause(context.TODO(), &state, true)).To(Succeed())
+		updated := getNode(k8sInterface, "node")
+		Expect(updated.Labels).To(HaveKeyWithValue(GetGPUUpgradeSkipLabelKey(), "true"))
+		Expect(updated.Annotations).To(HaveKey(GetGPUUpgradePausedAnnotationKey()))
+
+		updated.Labels[upgradeLib.GetUpgradeStateLabelKey()] = upgradeLib.UpgradeStateDone
+		state.NodeStates[upgradeLib.UpgradeStateDrainRequired] = []*upgradeLib.NodeUpgradeState{{Node: updated}}
+		Expect(coordinator.syncGPUUpgradePause(context.TODO(), &state, true)).To(Succeed())
+		updated = getNode(k8sInterface, "node")
+		Expect(updated.Labels).NotTo(HaveKey(GetGPUUpgradeSkipLabelKey()))
+		Expect(updated.Annotations).NotTo(HaveKey(GetGPUUpgradePausedAnnotationKey()))
+	})
+
+	It("should resume paused GPU driver upgrade when coordination is disabled", func() {
+		node := newLabeledTestNode("node",
+			map[string]string{
+				upgradeLib.GetUpgradeStateLabelKey(): upgradeLib.UpgradeStateDrainRequired,
+				GetGPUUpgradeSkipLabelKey():          "true"},
+			map[string]string{GetGPUUpgradePausedAnnotationKey(): "true"})
+		k8sInterface = newFakeClientset(node.DeepCopy())
+		coordinator.k8sInterface = k8sInterface
+		state := upgradeLib.NewClusterUpgradeState()
+		state.NodeStates[upgradeLib.UpgradeStateDrainRequired] = []*upgradeLib.NodeUpgradeState{{Node: node}}
+
+		Expect(coordinator.syncGPUUpgradePause(context.TODO(), &state, false)).To(Succeed())
+		updated := getNode(k8sInterface, "node")
+		Expect(updated.Labels).NotTo(HaveKey(GetGPUUpgradeSkipLabelKey()))
+		Expect(updated.Annotations).NotTo(HaveKey(GetGPUUpgradePausedAnnotationKey()))
+	})
+
+	It("should not take over GPU driver upgrade skip label set by the user", func() {
+		node := newLabeledTestNode("node", map[string]string{
+			upgradeLib.GetUpgradeStateLabelKey(): upgradeLib.UpgradeStateDrainRequired,
+			GetGPUUpgradeSkipLabelKey():          "true"}, nil)
+		k8sInterface = newFakeClientset(node.DeepCopy())
+		coordinator.k8sInterface = k8sInterface
+		state := upgradeLib.NewClusterUpgradeState()
+		state.NodeStates[upgradeLib.UpgradeStateDrainRequired] = []*upgradeLib.NodeUpgradeState{{Node: node}}
+
+		Expect(coordinator.syncGPUUpgradePause(context.TODO(), &state, true)).To(Succeed())
+		updated := getNode(k8sInterface, "node")
+		Expect(updated.Labels).To(HaveKeyWithValue(GetGPUUpgradeSkipLabelKey(), "true"))
+		Expect(updated.Annotations).NotTo(HaveKey(GetGPUUpgradePausedAnnotationKey()))
+	})
+})
@@ -0,0 +1,441 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/tools/remotecommand"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/config"
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+const (
+	// UpgradeStatePreDrainHookRequired is set while the pre-drain hook is run on the node,
+	// once it is done the state is changed to cordon-required
+	UpgradeStatePreDrainHookRequired = "pre-drain-hook-required"
+	// UpgradeStatePostDrainHookRequired is set while the post-drain hook is run on the node,
+	// once it is done the state is changed to uncordon-required
+	UpgradeStatePostDrainHookRequired = "post-drain-hook-required"
+	// HookDoneAnnotationKeyFmt is the format of the node annotation key which marks that a hook is done on the node
+	HookDoneAnnotationKeyFmt = "nvidia.com/%s-driver-upgrade.%s-hook-done"
+	// HookCommandAnnotationKeyFmt is the format of the pod annotation key which holds the command executed
+	// in the pod by a hook
+	HookCommandAnnotationKeyFmt = "nvidia.com/%s-driver-upgrade.%s-hook"
+	// HookContainerAnnotationKeyFmt is the format of the pod annotation key which holds the name of the container
+	// in which the commands of the hooks are executed
+	HookContainerAnnotationKeyFmt = "nvidia.com/%s-driver-upgrade.hook-container"
+	// HookJobLabel is the label of the hook Jobs, its value is the name of the hook
+	HookJobLabel = "nvidia.com/upgrade-hook"
+)
+
+// upgradeHook is a hook run on the node during the upgrade
+type upgradeHook struct {
+	// name is the name of the hook, it is used in the names of the hook Jobs and annotations
+	name string
+	// state is the upgrade state of the node while the hook is run
+	state string
+	// resumeState is the upgrade state before which the hook is run, the node is moved to it once the hook is done
+	resumeState string
+}
+
+var (
+	preDrainHook = upgradeHook{
+		name: "pre-drain", state: UpgradeStatePreDrainHookRequired, resumeState: upgradeLib.UpgradeStateCordonRequired}
+	postDrainHook = upgradeHook{
+		name: "post-drain", state: UpgradeStatePostDrainHookRequired, resumeState: upgradeLib.UpgradeStateUncordonRequired}
+	upgradeHooks = []upgradeHook{preDrainHook, postDrainHook}
+)
+
+// spec returns the settings of the hook in the hooks spec, nil if the hook is not set
+func (h upgradeHook) spec(hooks *mellanoxv1alpha1.UpgradeHooksSpec) *mellanoxv1alpha1.UpgradeHookSpec {
+	if hooks == nil {
+		return nil
+	}
+	if h == preDrainHook {
+		return hooks.PreDrain
+	}
+	return hooks.PostDrain
+}
+
+// GetHookDoneAnnotationKey returns the key of the node annotation which marks that the hook is done on the node
+func GetHookDoneAnnotationKey(hookName string) string {
+	return fmt.Sprintf(HookDoneAnnotationKeyFmt, upgradeLib.DriverName, hookName)
+}
+
+// GetHookDoneAnnotationKeys returns the keys of the node annotations which mark that the hooks are done on the node
+func GetHookDoneAnnotationKeys() []string {
+	keys := make([]string, 0, len(upgradeHooks))
+	for _, hook := range upgradeHooks {
+		keys = append(keys, GetHookDoneAnnotationKey(hook.name))
+	}
+	return keys
+}
+
+// GetHookCommandAnnotationKey returns the key of the pod annotation which holds the command executed in the pod
+// by the hook
+func GetHookCommandAnnotationKey(hookName string) string {
+	return fmt.Sprintf(HookCommandAnnotationKeyFmt, upgradeLib.DriverName, hookName)
+}
+
+// GetHookContainerAnnotationKey returns the key of the pod annotation which holds the name of the container
+// in which the commands of the hooks are executed
+func GetHookContainerAnnotationKey() string {
+	return fmt.Sprintf(HookContainerAnnotationKeyFmt, upgradeLib.DriverName)
+}
+
+// podExecutor executes a command in a container of a pod
+type podExecutor interface {
+	exec(ctx context.Context, pod *corev1.Pod, container string, command []string) error
+}
+
+// remotePodExecutor executes commands in the pods through the API server
+type remotePodExecutor struct {
+	k8sConfig    *rest.Config
+	k8sInterface kubernetes.Interface
+}
+
+func (e *remotePodExecutor) exec(ctx context.Context, pod *corev1.Pod, container string, command []string) error {
+	req := e.k8sInterface.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(pod.Namespace).Name(pod.Name).SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(e.k8sConfig, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	var stdout, stderr bytes.Buffer
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr})
+	if err != nil {
+		return fmt.Errorf("%v, stderr: %s", err, stderr.String())
+	}
+	return nil
+}
+
+// HookManager runs the pre-drain hook on the nodes before they are cordoned and the post-drain hook
+// after their driver pod is restarted, the nodes are kept in the hook states until the hooks are done
+type HookManager struct {
+	k8sInterface             kubernetes.Interface
+	nodeUpgradeStateProvider upgradeLib.NodeUpgradeStateProvider
+	executor                 podExecutor
+	log                      logr.Logger
+	eventRecorder            record.EventRecorder
+	// now returns the current time, it can be overridden in tests
+	now func() time.Time
+}
+
+// NewHookManager creates a HookManager which reports the node states with the nodeUpgradeStateProvider
+// and executes the commands of the annotated pods through the API server
+func NewHookManager(
+	k8sConfig *rest.Config,
+	k8sInterface kubernetes.Interface,
+	nodeUpgradeStateProvider upgradeLib.NodeUpgradeStateProvider,
+	log logr.Logger,
+	eventRecorder record.EventRecorder) *HookManager {
+	return &HookManager{
+		k8sInterface:             k8sInterface,
+		nodeUpgradeStateProvider: nodeUpgradeStateProvider,
+		executor:                 &remotePodExecutor{k8sConfig: k8sConfig, k8sInterface: k8sInterface},
+		log:                      log,
+		eventRecorder:            eventRecorder,
+		now:                      time.Now,
+	}
+}
+
+// getHookJobName returns the name of the Job which runs the hook on the node
+func getHookJobName(hook upgradeHook, nodeName string) string {
+	return fmt.Sprintf("%s-driver-%s-hook-%s", upgradeLib.DriverName, hook.name, nodeName)
+}
+
+// cleanupHooks removes the hook done annotations from the nodes on which the upgrade is not in progress,
+// so that the hooks are run again on the next upgrade of the node
+func (m *HookManager) cleanupHooks(ctx context.Context, state *upgradeLib.ClusterUpgradeState) error {
+	for _, nodeStates := range state.NodeStates {
+		for _, nodeState := range nodeStates {
+			node := nodeState.Node
+			if IsUpgradeInProgress(node, upgradeLib.GetUpgradeStateLabelKey()) {
+				continue
+			}
+			for _, key := range GetHookDoneAnnotationKeys() {
+				if _, ok := node.Annotations[key]; !ok {
+					continue
+				}
+				if err := m.patchNodeAnnotations(ctx, node.Name, fmt.Sprintf(`{%q: null}`, key)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// requestHooks moves the nodes which are about to be cordoned or uncordoned to the state of the hook run before,
+// unless the hook is not set or already done on the node. It returns a copy of the cluster upgrade state in which
+// these nodes are in the hook states
+func (m *HookManager) requestHooks(ctx context.Context, state *upgradeLib.ClusterUpgradeState,
+	hooks *mellanoxv1alpha1.UpgradeHooksSpec) (*upgradeLib.ClusterUpgradeState, error) {
+	updated := upgradeLib.NewClusterUpgradeState()
+	for upgradeState, nodeStates := range state.NodeStates {
+		var hook *upgradeHook
+		for i := range upgradeHooks {
+			if upgradeHooks[i].resumeState == upgradeState && upgradeHooks[i].spec(hooks) != nil {
+				hook = &upgradeHooks[i]
+			}
+		}
+		if hook == nil {
+			updated.NodeStates[upgradeState] = append(updated.NodeStates[upgradeState], nodeStates...)
+			continue
+		}
+		for _, nodeState := range nodeStates {
+			node := nodeState.Node
+			if _, done := node.Annotations[GetHookDoneAnnotationKey(hook.name)]; done {
+				updated.NodeStates[upgradeState] = append(updated.NodeStates[upgradeState], nodeState)
+				continue
+			}
+			m.log.V(consts.LogLevelInfo).Info("Running upgrade hook", "hook", hook.name, "node", node.Name)
+			if err := m.nodeUpgradeStateProvider.ChangeNodeUpgradeState(ctx, node, hook.state); err != nil {
+				return nil, err
+			}
+			updated.NodeStates[hook.state] = append(updated.NodeStates[hook.state], nodeState)
+		}
+	}
+	return &updated, nil
+}
+
+// processHookRequiredNodes runs the hooks on the nodes in the hook states
+func (m *HookManager) processHookRequiredNodes(ctx context.Context, state *upgradeLib.ClusterUpgradeState,
+	hooks *mellanoxv1alpha1.UpgradeHooksSpec) error {
+	for _, hook := range upgradeHooks {
+		for _, nodeState := range state.NodeStates[hook.state] {
+			if err := m.processHookRequiredNode(ctx, nodeState.Node, hook, hook.spec(hooks)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// processHookRequiredNode runs the hook on the node: the commands of the annotated pods are executed first,
+// then the Job is created and waited for. Once the hook is done the node is moved back to the state
+// before which the hook is run, if the hook fails it is handled according to its failure policy
+func (m *HookManager) processHookRequiredNode(ctx context.Context, node *corev1.Node, hook upgradeHook,
+	hookSpec *mellanoxv1alpha1.UpgradeHookSpec) error {
+	if hookSpec == nil {
+		// the hook was removed from the upgrade policy while it was run
+		return m.completeHook(ctx, node, hook)
+	}
+
+	var job *batchv1.Job
+	if hookSpec.Job != nil {
+		var err error
+		job, err = m.k8sInterface.BatchV1().Jobs(hookJobNamespace()).Get(
+			ctx, getHookJobName(hook, node.Name), metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		if apierrors.IsNotFound(err) {
+			job = nil
+		}
+	}
+
+	if job == nil {
+		// the hook is started, the Job doesn't exist yet
+		if hookSpec.ExecAnnotatedPods {
+			if err := m.execAnnotatedPods(ctx, node, hook, hookSpec); err != nil {
+				return m.failHook(ctx, node, hook, hookSpec, err.Error())
+			}
+		}
+		if hookSpec.Job == nil {
+			return m.completeHook(ctx, node, hook)
+		}
+		_, err := m.k8sInterface.BatchV1().Jobs(hookJobNamespace()).Create(
+			ctx, newHookJob(getHookJobName(hook, node.Name), node.Name, hook, hookSpec.Job), metav1.CreateOptions{})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create %s hook job for node %s: %v", hook.name, node.Name, err)
+		}
+		m.logEvent(node, corev1.EventTypeNormal, fmt.Sprintf("Started %s hook job", hook.name))
+		return nil
+	}
+
+	reason := ""
+	switch {
+	case isJobConditionTrue(job, batchv1.JobComplete):
+		if err := m.deleteHookJob(ctx, hook, node.Name); err != nil {
+			return err
+		}
+		return m.completeHook(ctx, node, hook)
+	case isJobConditionTrue(job, batchv1.JobFailed):
+		reason = "hook job failed"
+	case hookSpec.TimeoutSeconds > 0 &&
+		m.now().Sub(job.CreationTimestamp.Time) > time.Duration(hookSpec.TimeoutSeconds)*time.Second:
+		reason = "timed out waiting for the hook job to complete"
+	default:
+		m.log.V(consts.LogLevelDebug).Info("Waiting for the hook job to complete", "hook", hook.name, "node", node.Name)
+		return nil
+	}
+	if err := m.deleteHookJob(ctx, hook, node.Name); err != nil {
+		return err
+	}
+	return m.failHook(ctx, node, hook, hookSpec, reason)
+}
+
+// execAnnotatedPods executes the command of the hook in the running pods on the node which have the hook annotation
+func (m *HookManager) execAnnotatedPods(ctx context.Context, node *corev1.Node, hook upgradeHook,
+	hookSpec *mellanoxv1alpha1.UpgradeHookSpec) error {
+	pods, err := m.k8sInterface.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("spec.nodeName=%s", node.Name),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list pods of node %s: %v", node.Name, err)
+	}
+	if hookSpec.TimeoutSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(hookSpec.TimeoutSeconds)*time.Second)
+		defer cancel()
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		command, ok := pod.Annotations[GetHookCommandAnnotationKey(hook.name)]
+		if !ok || pod.Spec.NodeName != node.Name || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		container := pod.Annotations[GetHookContainerAnnotationKey()]
+		if container == "" && len(pod.Spec.Containers) > 0 {
+			container = pod.Spec.Containers[0].Name
+		}
+		m.log.V(consts.LogLevelInfo).Info("Executing hook command", "hook", hook.name,
+			"pod", types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, "container", container)
+		if err := m.executor.exec(ctx, pod, container, []string{"sh", "-c", command}); err != nil {
+			return fmt.Errorf("hook command failed in pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+	}
+	return nil
+}
+
+// completeHook marks the hook as done on the node and moves the node to the state before which the hook is run
+func (m *HookManager) completeHook(ctx context.Context, node *corev1.Node, hook upgradeHook) error {
+	err := m.patchNodeAnnotations(ctx, node.Name, fmt.Sprintf(`{%q: "true"}`, GetHookDoneAnnotationKey(hook.name)))
+	if err != nil {
+		return err
+	}
+	m.log.V(consts.LogLevelInfo).Info("Upgrade hook is done", "hook", hook.name, "node", node.Name)
+	m.logEvent(node, corev1.EventTypeNormal, fmt.Sprintf("Successfully ran %s hook", hook.name))
+	return m.nodeUpgradeStateProvider.ChangeNodeUpgradeState(ctx, node, hook.resumeState)
+}
+
+// failHook moves the node to the upgrade-failed state, or continues its upgrade if the failure policy
+// of the hook is Ignore
+func (m *HookManager) failHook(ctx context.Context, node *corev1.Node, hook upgradeHook,
+	hookSpec *mellanoxv1alpha1.UpgradeHookSpec, reason string) error {
+	m.log.V(consts.LogLevelWarning).Info("Upgrade hook failed", "hook", hook.name, "node", node.Name,
+		"reason", reason)
+	if hookSpec.FailurePolicy == mellanoxv1alpha1.UpgradeHookFailurePolicyIgnore {
+		m.logEvent(node, corev1.EventTypeWarning, fmt.Sprintf("Ignoring failure of %s hook, %s", hook.name, reason))
+		return m.completeHook(ctx, node, hook)
+	}
+	m.logEvent(node, corev1.EventTypeWarning, fmt.Sprintf("Failed to run %s hook, %s", hook.name, reason))
+	return m.nodeUpgradeStateProvider.ChangeNodeUpgradeState(ctx, node, upgradeLib.UpgradeStateFailed)
+}
+
+func (m *HookManager) patchNodeAnnotations(ctx context.Context, nodeName, annotations string) error {
+	_, err := m.k8sInterface.CoreV1().Nodes().Patch(ctx, nodeName, types.MergePatchType,
+		[]byte(fmt.Sprintf(`{"metadata":{"annotations":%s}}`, annotations)), metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update hook annotations of node %s: %v", nodeName, err)
+	}
+	return nil
+}
+
+func (m *HookManager) deleteHookJob(ctx context.Context, hook upgradeHook, nodeName string) error {
+	propagation := metav1.DeletePropagationBackground
+	err := m.k8sInterface.BatchV1().Jobs(hookJobNamespace()).Delete(
+		ctx, getHookJobName(hook, nodeName), metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete %s hook job of node %s: %v", hook.name, nodeName, err)
+	}
+	return nil
+}
+
+func (m *HookManager) logEvent(node *corev1.Node, eventType, message string) {
+	if m.eventRecorder != nil {
+		m.eventRecorder.Event(node, eventType, upgradeLib.GetEventReason(), message)
+	}
+}
+
+func hookJobNamespace() string {
+	return config.FromEnv().State.NetworkOperatorResourceNamespace
+}
+
+func isJobConditionTrue(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// newHookJob creates the Job which runs the hook on the node
+func newHookJob(name, nodeName string, hook upgradeHook, jobSpec *mellanoxv1alpha1.UpgradeHookJobSpec) *batchv1.Job {
+	var backoffLimit int32
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: hookJobNamespace(),
+			Labels:    map[string]string{HookJobLabel: hook.name},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{HookJobLabel: hook.name}},
+				Spec: corev1.PodSpec{
+					NodeName:           nodeName,
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: jobSpec.ServiceAccountName,
+					Tolerations:        []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+					Containers: []corev1.Container{{
+						Name:    "hook",
+						Image:   jobSpec.Image,
+						Command: jobSpec.Command,
+						Args:    jobSpec.Args,
+						Env:     []corev1.EnvVar{{Name: "NODE_NAME", Value: nodeName}},
+					}},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,255 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	upgradeApi "github.com/NVIDIA/k8s-operator-libs/api/upgrade/v1alpha1"
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+// fakePodExecutor records the executed commands and fails them if err is set
+type fakePodExecutor struct {
+	executed []string
+	err      error
+}
+
+func (e *fakePodExecutor) exec(_ context.Context, pod *corev1.Pod, container string, command []string) error {
+	e.executed = append(e.executed, pod.Name+"/"+container+": "+command[len(command)-1])
+	return e.err
+}
+
+func newHookJobWithCondition(hook upgradeHook, nodeName string, conditionType batchv1.JobConditionType,
+	created time.Time) *batchv1.Job {
+	job := newHookJob(getHookJobName(hook, nodeName), nodeName, hook,
+		&mellanoxv1alpha1.UpgradeHookJobSpec{Image: "hook"})
+	job.CreationTimestamp = metav1.NewTime(created)
+	if conditionType != "" {
+		job.Status.Conditions = []batchv1.JobCondition{{Type: conditionType, Status: corev1.ConditionTrue}}
+	}
+	return job
+}
+
+func createHookJob(k8sInterface *fake.Clientset, job *batchv1.Job) {
+	_, err := k8sInterface.BatchV1().Jobs(job.Namespace).Create(context.TODO(), job, metav1.CreateOptions{})
+	Expect(err).NotTo(HaveOccurred())
+}
+
+func getHookJob(k8sInterface *fake.Clientset, hook upgradeHook, nodeName string) (*batchv1.Job, error) {
+	return k8sInterface.BatchV1().Jobs(hookJobNamespace()).Get(
+		context.TODO(), getHookJobName(hook, nodeName), metav1.GetOptions{})
+}
+
+var _ = Describe("Upgrade hooks tests", func() {
+	var (
+		k8sInterface  *fake.Clientset
+		stateProvider *fakeNodeUpgradeStateProvider
+		executor      *fakePodExecutor
+		manager       *HookManager
+		hookSpec      *mellanoxv1alpha1.UpgradeHookSpec
+		node          *corev1.Node
+		now           time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Now()
+		stateProvider = newFakeNodeUpgradeStateProvider()
+		executor = &fakePodExecutor{}
+		hookSpec = &mellanoxv1alpha1.UpgradeHookSpec{
+			Job:            &mellanoxv1alpha1.UpgradeHookJobSpec{Image: "hook", Command: []string{"checkpoint"}},
+			TimeoutSeconds: 600,
+			FailurePolicy:  mellanoxv1alpha1.UpgradeHookFailurePolicyFail,
+		}
+		node = newTestNode("node1")
+		k8sInterface = newFakeClientset(node.DeepCopy())
+	})
+
+	JustBeforeEach(func() {
+		manager = NewHookManager(nil, k8sInterface, stateProvider, log.Log, nil)
+		manager.executor = executor
+		manager.now = func() time.Time { return now }
+	})
+
+	Context("hook request", func() {
+		It("should move the nodes to the hook states before they are cordoned or uncordoned", func() {
+			state := newClusterUpgradeState(map[string][]string{
+				upgradeLib.UpgradeStateCordonRequired:   {"node1"},
+				upgradeLib.UpgradeStateUncordonRequired: {"node2"},
+				upgradeLib.UpgradeStateDrainRequired:    {"node3"},
+			})
+			hooks := &mellanoxv1alpha1.UpgradeHooksSpec{PreDrain: hookSpec, PostDrain: hookSpec}
+
+			updated, err := manager.requestHooks(context.TODO(), state, hooks)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stateProvider.getState("node1")).To(Equal(UpgradeStatePreDrainHookRequired))
+			Expect(stateProvider.getState("node2")).To(Equal(UpgradeStatePostDrainHookRequired))
+			Expect(stateProvider.getState("node3")).To(BeEmpty())
+			Expect(updated.NodeStates[UpgradeStatePreDrainHookRequired]).To(HaveLen(1))
+			Expect(updated.NodeStates[UpgradeStatePostDrainHookRequired]).To(HaveLen(1))
+			Expect(updated.NodeStates[upgradeLib.UpgradeStateDrainRequired]).To(HaveLen(1))
+		})
+
+		It("should not run the hooks which are not set or already done", func() {
+			state := newClusterUpgradeState(map[string][]string{
+				upgradeLib.UpgradeStateCordonRequired:   {"node1"},
+				upgradeLib.UpgradeStateUncordonRequired: {"node2"},
+			})
+			state.NodeStates[upgradeLib.UpgradeStateCordonRequired][0].Node.Annotations = map[string]string{
+				GetHookDoneAnnotationKey(preDrainHook.name): "true"}
+			hooks := &mellanoxv1alpha1.UpgradeHooksSpec{PreDrain: hookSpec}
+
+			updated, err := manager.requestHooks(context.TODO(), state, hooks)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stateProvider.getState("node1")).To(BeEmpty())
+			Expect(stateProvider.getState("node2")).To(BeEmpty())
+			Expect(updated.NodeStates[upgradeLib.UpgradeStateCordonRequired]).To(HaveLen(1))
+			Expect(updated.NodeStates[upgradeLib.UpgradeStateUncordonRequired]).To(HaveLen(1))
+		})
+
+		It("should remove the hook done annotations once the upgrade is over", func() {
+			node.Annotations = map[string]string{GetHookDoneAnnotationKey(postDrainHook.name): "true"}
+			node.Labels = map[string]string{upgradeLib.GetUpgradeStateLabelKey(): upgradeLib.UpgradeStateDone}
+			_, err := k8sInterface.CoreV1().Nodes().Update(context.TODO(), node, metav1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			state := upgradeLib.NewClusterUpgradeState()
+			state.NodeStates[upgradeLib.UpgradeStateDone] = []*upgradeLib.NodeUpgradeState{{Node: node}}
+
+			Expect(manager.cleanupHooks(context.TODO(), &state)).To(Succeed())
+			Expect(getNode(k8sInterface, "node1").Annotations).NotTo(
+				HaveKey(GetHookDoneAnnotationKey(postDrainHook.name)))
+		})
+	})
+
+	Context("hook job", func() {
+		It("should create the hook job", func() {
+			Expect(manager.processHookRequiredNode(context.TODO(), node, preDrainHook, hookSpec)).To(Succeed())
+			job, err := getHookJob(k8sInterface, preDrainHook, "node1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.Labels).To(HaveKeyWithValue(HookJobLabel, "pre-drain"))
+			Expect(job.Spec.Template.Spec.NodeName).To(Equal("node1"))
+			Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal("hook"))
+			Expect(job.Spec.Template.Spec.Containers[0].Command).To(Equal([]string{"checkpoint"}))
+			Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElement(
+				corev1.EnvVar{Name: "NODE_NAME", Value: "node1"}))
+			Expect(stateProvider.getState("node1")).To(BeEmpty())
+		})
+
+		It("should wait for the hook job to complete", func() {
+			createHookJob(k8sInterface,
+				newHookJobWithCondition(preDrainHook, "node1", "", now.Add(-time.Minute)))
+			Expect(manager.processHookRequiredNode(context.TODO(), node, preDrainHook, hookSpec)).To(Succeed())
+			Expect(stateProvider.getState("node1")).To(BeEmpty())
+		})
+
+		It("should move the node to cordon-required state once the hook job is complete", func() {
+			createHookJob(k8sInterface,
+				newHookJobWithCondition(preDrainHook, "node1", batchv1.JobComplete, now.Add(-time.Minute)))
+			Expect(manager.processHookRequiredNode(context.TODO(), node, preDrainHook, hookSpec)).To(Succeed())
+			Expect(stateProvider.getState("node1")).To(Equal(upgradeLib.UpgradeStateCordonRequired))
+			Expect(getNode(k8sInterface, "node1").Annotations).To(
+				HaveKeyWithValue(GetHookDoneAnnotationKey(preDrainHook.name), "true"))
+			_, err := getHookJob(k8sInterface, preDrainHook, "node1")
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should move the node to upgrade-failed state if the hook job failed", func() {
+			createHookJob(k8sInterface,
+				newHookJobWithCondition(postDrainHook, "node1", batchv1.JobFailed, now.Add(-time.Minute)))
+			Expect(manager.processHookRequiredNode(context.TODO(), node, postDrainHook, hookSpec)).To(Succeed())
+			Expect(stateProvider.getState("node1")).To(Equal(upgradeLib.UpgradeStateFailed))
+			Expect(getNode(k8sInterface, "node1").Annotations).NotTo(
+				HaveKey(GetHookDoneAnnotationKey(postDrainHook.name)))
+			_, err := getHookJob(k8sInterface, postDrainHook, "node1")
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should continue the upgrade on timeout if the failure is ignored", func() {
+			hookSpec.FailurePolicy = mellanoxv1alpha1.UpgradeHookFailurePolicyIgnore
+			createHookJob(k8sInterface,
+				newHookJobWithCondition(postDrainHook, "node1", "", now.Add(-time.Hour)))
+			Expect(manager.processHookRequiredNode(context.TODO(), node, postDrainHook, hookSpec)).To(Succeed())
+			Expect(stateProvider.getState("node1")).To(Equal(upgradeLib.UpgradeStateUncordonRequired))
+		})
+	})
+
+	Context("annotated pods", func() {
+		BeforeEach(func() {
+			hookSpec.Job = nil
+			hookSpec.ExecAnnotatedPods = true
+			annotated := newTestPod("app1", "node1")
+			annotated.Annotations = map[string]string{GetHookCommandAnnotationKey(preDrainHook.name): "flush"}
+			annotated.Spec.Containers = []corev1.Container{{Name: "main"}, {Name: "sidecar"}}
+			annotated.Status.Phase = corev1.PodRunning
+			withContainer := annotated.DeepCopy()
+			withContainer.Name = "app2"
+			withContainer.Annotations[GetHookContainerAnnotationKey()] = "sidecar"
+			other := newTestPod("app3", "node1")
+			other.Status.Phase = corev1.PodRunning
+			otherNode := annotated.DeepCopy()
+			otherNode.Name = "app4"
+			otherNode.Spec.NodeName = "node2"
+			k8sInterface = newFakeClientset(node.DeepCopy(), annotated, withContainer, other, otherNode)
+		})
+
+		It("should execute the hook command in the annotated pods of the node", func() {
+			Expect(manager.processHookRequiredNode(context.TODO(), node, preDrainHook, hookSpec)).To(Succeed())
+			Expect(executor.executed).To(ConsistOf("app1/main: flush", "app2/sidecar: flush"))
+			Expect(stateProvider.getState("node1")).To(Equal(upgradeLib.UpgradeStateCordonRequired))
+		})
+
+		It("should move the node to upgrade-failed state if the command failed", func() {
+			executor.err = errors.New("command terminated with exit code 1")
+			Expect(manager.processHookRequiredNode(context.TODO(), node, preDrainHook, hookSpec)).To(Succeed())
+			Expect(stateProvider.getState("node1")).To(Equal(upgradeLib.UpgradeStateFailed))
+		})
+
+		It("should create the hook job after the commands are executed", func() {
+			hookSpec.Job = &mellanoxv1alpha1.UpgradeHookJobSpec{Image: "hook"}
+			Expect(manager.processHookRequiredNode(context.TODO(), node, preDrainHook, hookSpec)).To(Succeed())
+			Expect(executor.executed).To(HaveLen(2))
+			_, err := getHookJob(k8sInterface, preDrainHook, "node1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stateProvider.getState("node1")).To(BeEmpty())
+		})
+	})
+
+	Context("parallel upgrades", func() {
+		It("should account the nodes running hooks in parallel upgrades", func() {
+			state := newClusterUpgradeState(map[string][]string{
+				UpgradeStatePreDrainHookRequired:       {"node1"},
+				UpgradeStatePostDrainHookRequired:      {"node2"},
+				upgradeLib.UpgradeStateUpgradeRequired: {"node3"},
+			})
+			policy := &upgradeApi.DriverUpgradePolicySpec{AutoUpgrade: true, MaxParallelUpgrades: 3}
+			_, limitedPolicy := limitParallelUpgrades(state, policy)
+			Expect(limitedPolicy.MaxParallelUpgrades).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,164 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsNamespace = "network_operator"
+	metricsSubsystem = "upgrade"
+
+	drainResultSuccess = "success"
+	drainResultFailure = "failure"
+)
+
+var (
+	upgradeNodes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "nodes",
+		Help:      "Number of nodes in each driver upgrade state",
+	}, []string{"state"})
+	drainDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "drain_duration_seconds",
+		Help:      "Duration of node drains in seconds, including retries",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 14),
+	}, []string{"result"})
+	cordonFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "cordon_failures_total",
+		Help:      "Number of failed attempts to cordon a node",
+	})
+	failedStateDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "failed_state_duration_seconds",
+		Help:      "Time in seconds since the node was observed in the upgrade-failed state",
+	}, []string{"node"})
+)
+
+// upgradeStates are the driver upgrade states reported by the upgradeNodes metric
+var upgradeStates = []string{
+	upgradeLib.UpgradeStateUnknown,
+	upgradeLib.UpgradeStateUpgradeRequired,
+	UpgradeStateCanaryWaitRequired,
+	UpgradeStatePreDrainHookRequired,
+	upgradeLib.UpgradeStateCordonRequired,
+	upgradeLib.UpgradeStateWaitForJobsRequired,
+	upgradeLib.UpgradeStatePodDeletionRequired,
+	upgradeLib.UpgradeStateDrainRequired,
+	upgradeLib.UpgradeStatePodRestartRequired,
+	upgradeLib.UpgradeStateValidationRequired,
+	UpgradeStateRebootRequired,
+	UpgradeStatePostDrainHookRequired,
+	upgradeLib.UpgradeStateUncordonRequired,
+	UpgradeStateCanarySoakRequired,
+	upgradeLib.UpgradeStateDone,
+	upgradeLib.UpgradeStateFailed,
+}
+
+func init() {
+	metrics.Registry.MustRegister(upgradeNodes, drainDuration, cordonFailures, failedStateDuration)
+}
+
+// stateMetricsRecorder records the metrics of the cluster upgrade state
+type stateMetricsRecorder struct {
+	now func() time.Time
+
+	mu sync.Mutex
+	// failedSince holds the time each node was first observed in the upgrade-failed state
+	failedSince map[string]time.Time
+}
+
+func newStateMetricsRecorder() *stateMetricsRecorder {
+	return &stateMetricsRecorder{
+		now:         time.Now,
+		failedSince: make(map[string]time.Time),
+	}
+}
+
+// record records the number of nodes per upgrade state and the time the nodes spend in the upgrade-failed state
+func (r *stateMetricsRecorder) record(state *upgradeLib.ClusterUpgradeState) {
+	if state == nil {
+		return
+	}
+	for _, upgradeState := range upgradeStates {
+		upgradeNodes.WithLabelValues(upgradeState).Set(float64(len(state.NodeStates[upgradeState])))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	failedNodes := make(map[string]bool)
+	for _, nodeState := range state.NodeStates[upgradeLib.UpgradeStateFailed] {
+		if nodeState == nil || nodeState.Node == nil {
+			continue
+		}
+		name := nodeState.Node.Name
+		failedNodes[name] = true
+		since, ok := r.failedSince[name]
+		if !ok {
+			since = now
+			r.failedSince[name] = now
+		}
+		failedStateDuration.WithLabelValues(name).Set(now.Sub(since).Seconds())
+	}
+	for name := range r.failedSince {
+		if !failedNodes[name] {
+			delete(r.failedSince, name)
+			failedStateDuration.DeleteLabelValues(name)
+		}
+	}
+}
+
+// cordonManager is an upgradeLib.CordonManager which counts the failures to cordon a node
+// and ends the node maintenance of the node before uncordoning it
+type cordonManager struct {
+	upgradeLib.CordonManager
+	drainManager *DrainManager
+}
+
+// Cordon marks a node as unschedulable
+func (m *cordonManager) Cordon(ctx context.Context, node *corev1.Node) error {
+	err := m.CordonManager.Cordon(ctx, node)
+	if err != nil {
+		cordonFailures.Inc()
+	}
+	return err
+}
+
+// Uncordon deletes the NodeMaintenance object of the node, if any, and marks the node as schedulable
+func (m *cordonManager) Uncordon(ctx context.Context, node *corev1.Node) error {
+	if m.drainManager != nil {
+		if err := m.drainManager.endNodeMaintenance(ctx, node.Name); err != nil {
+			return err
+		}
+	}
+	return m.CordonManager.Uncordon(ctx, node)
+}
@@ -0,0 +1,126 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	upgradeApi "github.com/NVIDIA/k8s-operator-libs/api/upgrade/v1alpha1"
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type fakeCordonManager struct {
+	err error
+}
+
+func (m *fakeCordonManager) Cordon(_ context.Context, _ *corev1.Node) error {
+	return m.err
+}
+
+func (m *fakeCordonManager) Uncordon(_ context.Context, _ *corev1.Node) error {
+	return m.err
+}
+
+func newClusterUpgradeState(nodesPerState map[string][]string) *upgradeLib.ClusterUpgradeState {
+	state := upgradeLib.NewClusterUpgradeState()
+	for upgradeState, nodes := range nodesPerState {
+		for _, node := range nodes {
+			state.NodeStates[upgradeState] = append(state.NodeStates[upgradeState],
+				&upgradeLib.NodeUpgradeState{Node: newTestNode(node)})
+		}
+	}
+	return &state
+}
+
+func getDrainCount(result string) uint64 {
+	metric := &dto.Metric{}
+	Expect(drainDuration.WithLabelValues(result).(prometheus.Metric).Write(metric)).To(Succeed())
+	return metric.GetHistogram().GetSampleCount()
+}
+
+var _ = Describe("Upgrade metrics tests", func() {
+	BeforeEach(func() {
+		upgradeNodes.Reset()
+		failedStateDuration.Reset()
+	})
+
+	It("should report the number of nodes per upgrade state", func() {
+		recorder := newStateMetricsRecorder()
+		recorder.record(newClusterUpgradeState(map[string][]string{
+			upgradeLib.UpgradeStateDone:               {"node1", "node2"},
+			upgradeLib.UpgradeStateDrainRequired:      {"node3"},
+			upgradeLib.UpgradeStateUpgradeRequired:    {"node4"},
+			upgradeLib.UpgradeStateUncordonRequired:   {},
+			upgradeLib.UpgradeStatePodRestartRequired: nil,
+		}))
+		Expect(testutil.ToFloat64(upgradeNodes.WithLabelValues(upgradeLib.UpgradeStateDone))).To(Equal(2.0))
+		Expect(testutil.ToFloat64(upgradeNodes.WithLabelValues(upgradeLib.UpgradeStateDrainRequired))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(upgradeNodes.WithLabelValues(upgradeLib.UpgradeStateFailed))).To(Equal(0.0))
+	})
+
+	It("should report the time nodes spend in upgrade-failed state", func() {
+		recorder := newStateMetricsRecorder()
+		now := time.Now()
+		recorder.now = func() time.Time { return now }
+		recorder.record(newClusterUpgradeState(map[string][]string{
+			upgradeLib.UpgradeStateFailed: {"node1", "node2"},
+		}))
+		Expect(testutil.ToFloat64(failedStateDuration.WithLabelValues("node1"))).To(Equal(0.0))
+
+		now = now.Add(time.Minute)
+		recorder.record(newClusterUpgradeState(map[string][]string{
+			upgradeLib.UpgradeStateFailed: {"node1"},
+			upgradeLib.UpgradeStateDone:   {"node2"},
+		}))
+		Expect(testutil.ToFloat64(failedStateDuration.WithLabelValues("node1"))).To(Equal(60.0))
+		Expect(testutil.CollectAndCount(failedStateDuration)).To(Equal(1))
+	})
+
+	It("should count cordon failures", func() {
+		before := testutil.ToFloat64(cordonFailures)
+		manager := &cordonManager{CordonManager: &fakeCordonManager{}}
+		Expect(manager.Cordon(context.TODO(), newTestNode("node1"))).To(Succeed())
+		Expect(testutil.ToFloat64(cordonFailures)).To(Equal(before))
+
+		manager = &cordonManager{CordonManager: &fakeCordonManager{err: errors.New("failed to cordon")}}
+		Expect(manager.Cordon(context.TODO(), newTestNode("node1"))).NotTo(Succeed())
+		Expect(testutil.ToFloat64(cordonFailures)).To(Equal(before + 1))
+	})
+
+	It("should report the drain duration", func() {
+		before := getDrainCount(drainResultSuccess)
+		k8sInterface := newFakeClientset(newTestNode("node1"), newTestPod("pod1", "node1"))
+		stateProvider := newFakeNodeUpgradeStateProvider()
+		drainManager := NewDrainManager(k8sInterface, nil, stateProvider, log.Log, nil)
+		err := drainManager.ScheduleNodesDrain(context.TODO(), &upgradeLib.DrainConfiguration{
+			Spec:  &upgradeApi.DrainSpec{Enable: true, Force: true, TimeoutSecond: 5},
+			Nodes: []*corev1.Node{getNode(k8sInterface, "node1")}})
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() string { return stateProvider.getState("node1") }).
+			WithTimeout(5 * time.Second).Should(Equal(upgradeLib.UpgradeStatePodRestartRequired))
+		Expect(getDrainCount(drainResultSuccess)).To(Equal(before + 1))
+	})
+})
@@ -0,0 +1,136 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+const (
+	// NodeMaintenancePhaseSucceeded is the phase of a NodeMaintenance whose node was drained
+	NodeMaintenancePhaseSucceeded = "Succeeded"
+	// NodeMaintenancePhaseFailed is the phase of a NodeMaintenance which failed
+	NodeMaintenancePhaseFailed = "Failed"
+)
+
+// NodeMaintenanceGVR is the resource of the NodeMaintenance objects of the node maintenance operator
+var NodeMaintenanceGVR = schema.GroupVersionResource{
+	Group: "nodemaintenance.medik8s.io", Version: "v1beta1", Resource: "nodemaintenances"}
+
+// nodeMaintenancePollInterval is the interval between the checks of the NodeMaintenance phase
+var nodeMaintenancePollInterval = 10 * time.Second
+
+// GetNodeMaintenanceName returns the name of the NodeMaintenance object created for the driver upgrade of the node
+func GetNodeMaintenanceName(nodeName string) string {
+	return fmt.Sprintf("%s-driver-upgrade-%s", upgradeLib.DriverName, nodeName)
+}
+
+func newNodeMaintenance(nodeName string) *unstructured.Unstructured {
+	nm := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"nodeName": nodeName,
+			"reason":   fmt.Sprintf("%s driver upgrade", upgradeLib.DriverName),
+		},
+	}}
+	nm.SetAPIVersion(NodeMaintenanceGVR.GroupVersion().String())
+	nm.SetKind("NodeMaintenance")
+	nm.SetName(GetNodeMaintenanceName(nodeName))
+	return nm
+}
+
+// maintainNode creates a NodeMaintenance object for the node and waits for the node maintenance operator
+// to drain the node, the node is moved to the next upgrade state according to the maintenance result
+func (m *DrainManager) maintainNode(ctx context.Context, timeout time.Duration, node *corev1.Node) {
+	err := m.runNodeMaintenance(ctx, timeout, node.Name)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		m.log.V(consts.LogLevelError).Error(err, "Node maintenance failed", "node", node.Name)
+		_ = m.nodeUpgradeStateProvider.ChangeNodeUpgradeState(ctx, node, upgradeLib.UpgradeStateFailed)
+		m.logEvent(node, corev1.EventTypeWarning, fmt.Sprintf("Failed to drain the node, %s", err.Error()))
+		return
+	}
+	m.log.V(consts.LogLevelInfo).Info("Drained the node by node maintenance", "node", node.Name)
+	m.logEvent(node, corev1.EventTypeNormal, "Successfully drained the node")
+	_ = m.nodeUpgradeStateProvider.ChangeNodeUpgradeState(ctx, node, upgradeLib.UpgradeStatePodRestartRequired)
+}
+
+// runNodeMaintenance creates the NodeMaintenance object of the node if it doesn't exist yet
+// and polls its phase until it succeeds, fails or the timeout expires, zero timeout means infinite
+func (m *DrainManager) runNodeMaintenance(ctx context.Context, timeout time.Duration, nodeName string) error {
+	if m.dynamicClient == nil {
+		return fmt.Errorf("node maintenance is not supported")
+	}
+	client := m.dynamicClient.Resource(NodeMaintenanceGVR)
+	_, err := client.Create(ctx, newNodeMaintenance(nodeName), metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create NodeMaintenance: %v", err)
+	}
+
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	for {
+		nm, err := client.Get(ctx, GetNodeMaintenanceName(nodeName), metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get NodeMaintenance: %v", err)
+		}
+		phase, _, _ := unstructured.NestedString(nm.Object, "status", "phase")
+		switch phase {
+		case NodeMaintenancePhaseSucceeded:
+			return nil
+		case NodeMaintenancePhaseFailed:
+			lastError, _, _ := unstructured.NestedString(nm.Object, "status", "lastError")
+			return fmt.Errorf("NodeMaintenance %s failed: %s", nm.GetName(), lastError)
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return fmt.Errorf("NodeMaintenance %s did not complete in %s", nm.GetName(), timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(nodeMaintenancePollInterval):
+		}
+	}
+}
+
+// endNodeMaintenance deletes the NodeMaintenance object of the node, which makes the node maintenance operator
+// uncordon the node, it does nothing if node maintenance is disabled
+func (m *DrainManager) endNodeMaintenance(ctx context.Context, nodeName string) error {
+	if !m.isNodeMaintenanceEnabled() || m.dynamicClient == nil {
+		return nil
+	}
+	err := m.dynamicClient.Resource(NodeMaintenanceGVR).Delete(
+		ctx, GetNodeMaintenanceName(nodeName), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete NodeMaintenance of node %s: %v", nodeName, err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+	"time"
+
+	upgradeApi "github.com/NVIDIA/k8s-operator-libs/api/upgrade/v1alpha1"
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func setNodeMaintenancePhase(dynamicClient *dynamicfake.FakeDynamicClient, nodeName, phase string) {
+	client := dynamicClient.Resource(NodeMaintenanceGVR)
+	nm, err := client.Get(context.TODO(), GetNodeMaintenanceName(nodeName), metav1.GetOptions{})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(unstructured.SetNestedField(nm.Object, phase, "status", "phase")).To(Succeed())
+	_, err = client.Update(context.TODO(), nm, metav1.UpdateOptions{})
+	Expect(err).NotTo(HaveOccurred())
+}
+
+var _ = Describe("Node maintenance tests", func() {
+	var (
+		dynamicClient *dynamicfake.FakeDynamicClient
+		stateProvider *fakeNodeUpgradeStateProvider
+		drainManager  *DrainManager
+		drainConfig   *upgradeLib.DrainConfiguration
+	)
+
+	BeforeEach(func() {
+		pollInterval := nodeMaintenancePollInterval
+		nodeMaintenancePollInterval = 10 * time.Millisecond
+		DeferCleanup(func() { nodeMaintenancePollInterval = pollInterval })
+
+		dynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{NodeMaintenanceGVR: "NodeMaintenanceList"})
+		stateProvider = newFakeNodeUpgradeStateProvider()
+		drainManager = NewDrainManager(newFakeClientset(), dynamicClient, stateProvider, log.Log, nil)
+		drainManager.SetNodeMaintenance(true)
+		drainConfig = &upgradeLib.DrainConfiguration{
+			Spec:  &upgradeApi.DrainSpec{Enable: true, TimeoutSecond: 5},
+			Nodes: []*corev1.Node{newTestNode("node1")},
+		}
+	})
+
+	It("should create NodeMaintenance and complete the drain when it succeeds", func() {
+		Expect(drainManager.ScheduleNodesDrain(context.TODO(), drainConfig)).To(Succeed())
+		Eventually(func() error {
+			_, err := dynamicClient.Resource(NodeMaintenanceGVR).Get(
+				context.TODO(), GetNodeMaintenanceName("node1"), metav1.GetOptions{})
+			return err
+		}).Should(Succeed())
+		Consistently(func() string { return stateProvider.getState("node1") }, "50ms").Should(BeEmpty())
+
+		setNodeMaintenancePhase(dynamicClient, "node1", NodeMaintenancePhaseSucceeded)
+		Eventually(func() string { return stateProvider.getState("node1") }).
+			Should(Equal(upgradeLib.UpgradeStatePodRestartRequired))
+	})
+
+	It("should move the node to upgrade-failed state when NodeMaintenance fails", func() {
+		_, err := dynamicClient.Resource(NodeMaintenanceGVR).Create(
+			context.TODO(), newNodeMaintenance("node1"), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		setNodeMaintenancePhase(dynamicClient, "node1", NodeMaintenancePhaseFailed)
+
+		Expect(drainManager.ScheduleNodesDrain(context.TODO(), drainConfig)).To(Succeed())
+		Eventually(func() string { return stateProvider.getState("node1") }).
+			Should(Equal(upgradeLib.UpgradeStateFailed))
+	})
+
+	It("should move the node to upgrade-failed state when NodeMaintenance times out", func() {
+		drainConfig.Spec.TimeoutSecond = 1
+		Expect(drainManager.ScheduleNodesDrain(context.TODO(), drainConfig)).To(Succeed())
+		Eventually(func() string { return stateProvider.getState("node1") }, "3s").
+			Should(Equal(upgradeLib.UpgradeStateFailed))
+	})
+
+	It("should delete NodeMaintenance when the node is uncordoned", func() {
+		_, err := dynamicClient.Resource(NodeMaintenanceGVR).Create(
+			context.TODO(), newNodeMaintenance("node1"), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		manager := &cordonManager{CordonManager: &fakeCordonManager{}, drainManager: drainManager}
+		Expect(manager.Uncordon(context.TODO(), newTestNode("node1"))).To(Succeed())
+		_, err = dynamicClient.Resource(NodeMaintenanceGVR).Get(
+			context.TODO(), GetNodeMaintenanceName("node1"), metav1.GetOptions{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		// deleting a missing NodeMaintenance is not an error
+		Expect(manager.Uncordon(context.TODO(), newTestNode("node1"))).To(Succeed())
+	})
+})
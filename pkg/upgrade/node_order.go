@@ -0,0 +1,162 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+// NodeSorter orders the nodes waiting for the upgrade, the upgrade library starts the upgrade of the nodes
+// in the returned order
+type NodeSorter interface {
+	Sort(ctx context.Context, nodeStates []*upgradeLib.NodeUpgradeState) ([]*upgradeLib.NodeUpgradeState, error)
+}
+
+// NewNodeSorter returns the NodeSorter of the node order policy, nil if the order is not set
+func NewNodeSorter(k8sInterface kubernetes.Interface, order *mellanoxv1alpha1.NodeOrderSpec) NodeSorter {
+	if order == nil {
+		return nil
+	}
+	switch order.Policy {
+	case mellanoxv1alpha1.NodeOrderPolicyZone:
+		return &labelValueSorter{key: corev1.LabelTopologyZone, values: order.Values}
+	case mellanoxv1alpha1.NodeOrderPolicyLabel:
+		return &labelValueSorter{key: order.LabelKey, values: order.Values}
+	case mellanoxv1alpha1.NodeOrderPolicyNodeList:
+		return &nodeListSorter{nodes: order.Nodes}
+	case mellanoxv1alpha1.NodeOrderPolicyEmptiestFirst:
+		return &podCountSorter{k8sInterface: k8sInterface}
+	}
+	return nil
+}
+
+// sortNodeStates returns a copy of the node states sorted by compare, the nodes which are equal are ordered by name
+func sortNodeStates(nodeStates []*upgradeLib.NodeUpgradeState,
+	compare func(a, b *corev1.Node) int) []*upgradeLib.NodeUpgradeState {
+	sorted := slices.Clone(nodeStates)
+	slices.SortStableFunc(sorted, func(a, b *upgradeLib.NodeUpgradeState) int {
+		if c := compare(a.Node, b.Node); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Node.Name, b.Node.Name)
+	})
+	return sorted
+}
+
+// labelValueSorter orders the nodes by the value of a label, the values in the list come first in the order
+// of the list, followed by the other values ordered by value, the nodes without the label come last
+type labelValueSorter struct {
+	key    string
+	values []string
+}
+
+// Sort implements NodeSorter
+func (s *labelValueSorter) Sort(_ context.Context,
+	nodeStates []*upgradeLib.NodeUpgradeState) ([]*upgradeLib.NodeUpgradeState, error) {
+	rank := func(node *corev1.Node) (int, string) {
+		value, ok := node.Labels[s.key]
+		if !ok {
+			return len(s.values) + 1, ""
+		}
+		if i := slices.Index(s.values, value); i >= 0 {
+			return i, ""
+		}
+		return len(s.values), value
+	}
+	return sortNodeStates(nodeStates, func(a, b *corev1.Node) int {
+		rankA, valueA := rank(a)
+		rankB, valueB := rank(b)
+		if c := cmp.Compare(rankA, rankB); c != 0 {
+			return c
+		}
+		return cmp.Compare(valueA, valueB)
+	}), nil
+}
+
+// nodeListSorter orders the nodes in the order of the list, the nodes which are not in the list come last
+type nodeListSorter struct {
+	nodes []string
+}
+
+// Sort implements NodeSorter
+func (s *nodeListSorter) Sort(_ context.Context,
+	nodeStates []*upgradeLib.NodeUpgradeState) ([]*upgradeLib.NodeUpgradeState, error) {
+	rank := func(node *corev1.Node) int {
+		if i := slices.Index(s.nodes, node.Name); i >= 0 {
+			return i
+		}
+		return len(s.nodes)
+	}
+	return sortNodeStates(nodeStates, func(a, b *corev1.Node) int {
+		return cmp.Compare(rank(a), rank(b))
+	}), nil
+}
+
+// podCountSorter orders the nodes by the number of the pods running on them, the nodes with the least pods first,
+// so that the least workloads are disrupted while the upgrade is in progress
+type podCountSorter struct {
+	k8sInterface kubernetes.Interface
+}
+
+// Sort implements NodeSorter
+func (s *podCountSorter) Sort(ctx context.Context,
+	nodeStates []*upgradeLib.NodeUpgradeState) ([]*upgradeLib.NodeUpgradeState, error) {
+	pods, err := s.k8sInterface.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods to order the nodes: %v", err)
+	}
+	podCount := make(map[string]int)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		podCount[pod.Spec.NodeName]++
+	}
+	return sortNodeStates(nodeStates, func(a, b *corev1.Node) int {
+		return cmp.Compare(podCount[a.Name], podCount[b.Name])
+	}), nil
+}
+
+// orderUpgradeRequiredNodes returns a copy of the cluster upgrade state in which the nodes waiting for the upgrade
+// are ordered by the sorter, the state is returned as is if the sorter is nil
+func orderUpgradeRequiredNodes(ctx context.Context, state *upgradeLib.ClusterUpgradeState,
+	sorter NodeSorter) (*upgradeLib.ClusterUpgradeState, error) {
+	if sorter == nil || len(state.NodeStates[upgradeLib.UpgradeStateUpgradeRequired]) < 2 {
+		return state, nil
+	}
+	sorted, err := sorter.Sort(ctx, state.NodeStates[upgradeLib.UpgradeStateUpgradeRequired])
+	if err != nil {
+		return nil, err
+	}
+	ordered := upgradeLib.NewClusterUpgradeState()
+	for upgradeState, nodeStates := range state.NodeStates {
+		ordered.NodeStates[upgradeState] = nodeStates
+	}
+	ordered.NodeStates[upgradeLib.UpgradeStateUpgradeRequired] = sorted
+	return &ordered, nil
+}
@@ -0,0 +1,126 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+func newNodeStatesWithLabel(key string, valuePerNode map[string]string,
+	nodes ...string) []*upgradeLib.NodeUpgradeState {
+	nodeStates := make([]*upgradeLib.NodeUpgradeState, 0, len(nodes))
+	for _, name := range nodes {
+		node := newTestNode(name)
+		if value, ok := valuePerNode[name]; ok {
+			node.Labels = map[string]string{key: value}
+		}
+		nodeStates = append(nodeStates, &upgradeLib.NodeUpgradeState{Node: node})
+	}
+	return nodeStates
+}
+
+func nodeNames(nodeStates []*upgradeLib.NodeUpgradeState) []string {
+	names := make([]string, 0, len(nodeStates))
+	for _, nodeState := range nodeStates {
+		names = append(names, nodeState.Node.Name)
+	}
+	return names
+}
+
+func sortNodes(order *mellanoxv1alpha1.NodeOrderSpec, nodeStates []*upgradeLib.NodeUpgradeState) []string {
+	sorted, err := NewNodeSorter(newFakeClientset(), order).Sort(context.TODO(), nodeStates)
+	Expect(err).NotTo(HaveOccurred())
+	return nodeNames(sorted)
+}
+
+var _ = Describe("Node order tests", func() {
+	zones := map[string]string{"node1": "zone-b", "node2": "zone-a", "node3": "zone-b", "node4": "zone-c"}
+
+	It("should not order the nodes without a node order", func() {
+		Expect(NewNodeSorter(newFakeClientset(), nil)).To(BeNil())
+	})
+
+	It("should order the nodes by zone", func() {
+		nodeStates := newNodeStatesWithLabel(corev1.LabelTopologyZone, zones, "node5", "node4", "node3", "node2", "node1")
+		Expect(sortNodes(&mellanoxv1alpha1.NodeOrderSpec{Policy: mellanoxv1alpha1.NodeOrderPolicyZone}, nodeStates)).
+			To(Equal([]string{"node2", "node1", "node3", "node4", "node5"}))
+	})
+
+	It("should order the zones in the order of the values", func() {
+		nodeStates := newNodeStatesWithLabel(corev1.LabelTopologyZone, zones, "node5", "node4", "node3", "node2", "node1")
+		order := &mellanoxv1alpha1.NodeOrderSpec{
+			Policy: mellanoxv1alpha1.NodeOrderPolicyZone, Values: []string{"zone-c", "zone-b"}}
+		Expect(sortNodes(order, nodeStates)).To(Equal([]string{"node4", "node1", "node3", "node2", "node5"}))
+	})
+
+	It("should order the nodes by the value of a label", func() {
+		nodeStates := newNodeStatesWithLabel("rack", map[string]string{"node1": "2", "node2": "1"},
+			"node3", "node2", "node1")
+		order := &mellanoxv1alpha1.NodeOrderSpec{Policy: mellanoxv1alpha1.NodeOrderPolicyLabel, LabelKey: "rack"}
+		Expect(sortNodes(order, nodeStates)).To(Equal([]string{"node2", "node1", "node3"}))
+	})
+
+	It("should order the nodes in the order of the node list", func() {
+		nodeStates := newNodeStatesWithLabel("", nil, "node1", "node2", "node3", "node4")
+		order := &mellanoxv1alpha1.NodeOrderSpec{
+			Policy: mellanoxv1alpha1.NodeOrderPolicyNodeList, Nodes: []string{"node3", "node1"}}
+		Expect(sortNodes(order, nodeStates)).To(Equal([]string{"node3", "node1", "node2", "node4"}))
+	})
+
+	It("should upgrade the nodes with the least pods first", func() {
+		running := func(name, nodeName string) *corev1.Pod {
+			pod := newTestPod(name, nodeName)
+			pod.Status.Phase = corev1.PodRunning
+			return pod
+		}
+		completed := newTestPod("completed", "node3")
+		completed.Status.Phase = corev1.PodSucceeded
+		k8sInterface := newFakeClientset(running("pod1", "node1"), running("pod2", "node1"),
+			running("pod3", "node2"), completed)
+		order := &mellanoxv1alpha1.NodeOrderSpec{Policy: mellanoxv1alpha1.NodeOrderPolicyEmptiestFirst}
+		nodeStates := newNodeStatesWithLabel("", nil, "node1", "node2", "node3")
+
+		sorted, err := NewNodeSorter(k8sInterface, order).Sort(context.TODO(), nodeStates)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodeNames(sorted)).To(Equal([]string{"node3", "node2", "node1"}))
+	})
+
+	It("should order only the nodes waiting for the upgrade", func() {
+		state := newClusterUpgradeState(map[string][]string{
+			upgradeLib.UpgradeStateUpgradeRequired: {"node2", "node1"},
+			upgradeLib.UpgradeStateDrainRequired:   {"node4", "node3"},
+		})
+		sorter := NewNodeSorter(newFakeClientset(), &mellanoxv1alpha1.NodeOrderSpec{
+			Policy: mellanoxv1alpha1.NodeOrderPolicyNodeList, Nodes: []string{"node1"}})
+
+		ordered, err := orderUpgradeRequiredNodes(context.TODO(), state, sorter)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodeNames(ordered.NodeStates[upgradeLib.UpgradeStateUpgradeRequired])).To(
+			Equal([]string{"node1", "node2"}))
+		Expect(nodeNames(ordered.NodeStates[upgradeLib.UpgradeStateDrainRequired])).To(
+			Equal([]string{"node4", "node3"}))
+		Expect(nodeNames(state.NodeStates[upgradeLib.UpgradeStateUpgradeRequired])).To(
+			Equal([]string{"node2", "node1"}))
+	})
+})
@@ -0,0 +1,77 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"fmt"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+const (
+	// UpgradePausedAnnotationKeyFmt is the format of the node annotation key which pauses the upgrade of the node
+	// when set to "true", the node is kept in its current upgrade state until the annotation is removed
+	UpgradePausedAnnotationKeyFmt = "nvidia.com/%s-driver-upgrade.paused"
+
+	// pausedNodesState groups the paused nodes in the cluster upgrade state, it is never set on the nodes.
+	// The upgrade library ignores the nodes in this state, but counts the cordoned ones as unavailable
+	pausedNodesState = "paused"
+)
+
+// GetUpgradePausedAnnotationKey returns the key of the node annotation which pauses the upgrade of the node
+func GetUpgradePausedAnnotationKey() string {
+	return fmt.Sprintf(UpgradePausedAnnotationKeyFmt, upgradeLib.DriverName)
+}
+
+// isNodeUpgradePaused returns true if the upgrade of the node is paused by the annotation
+func isNodeUpgradePaused(node *corev1.Node) bool {
+	return node.Annotations[GetUpgradePausedAnnotationKey()] == "true"
+}
+
+// excludePausedNodes returns a copy of the cluster upgrade state in which the paused nodes are moved
+// to the paused state, so that they are not processed
+func excludePausedNodes(log logr.Logger,
+	state *upgradeLib.ClusterUpgradeState) *upgradeLib.ClusterUpgradeState {
+	filtered := upgradeLib.NewClusterUpgradeState()
+	for upgradeState, nodeStates := range state.NodeStates {
+		for _, nodeState := range nodeStates {
+			if !isNodeUpgradePaused(nodeState.Node) {
+				filtered.NodeStates[upgradeState] = append(filtered.NodeStates[upgradeState], nodeState)
+				continue
+			}
+			log.V(consts.LogLevelDebug).Info("Upgrade of the node is paused",
+				"node", nodeState.Node.Name, "upgradeState", upgradeState)
+			filtered.NodeStates[pausedNodesState] = append(filtered.NodeStates[pausedNodesState], nodeState)
+		}
+	}
+	return &filtered
+}
+
+// countPausedUpgrades returns the number of the paused nodes on which the upgrade is in progress
+func countPausedUpgrades(state *upgradeLib.ClusterUpgradeState) int {
+	count := 0
+	for _, nodeState := range state.NodeStates[pausedNodesState] {
+		if IsUpgradeInProgress(nodeState.Node, upgradeLib.GetUpgradeStateLabelKey()) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,67 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	upgradeApi "github.com/NVIDIA/k8s-operator-libs/api/upgrade/v1alpha1"
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Upgrade pause tests", func() {
+	var state *upgradeLib.ClusterUpgradeState
+
+	BeforeEach(func() {
+		paused := map[string]string{GetUpgradePausedAnnotationKey(): "true"}
+		stateLabel := upgradeLib.GetUpgradeStateLabelKey()
+		clusterState := upgradeLib.NewClusterUpgradeState()
+		state = &clusterState
+		state.NodeStates[upgradeLib.UpgradeStateUpgradeRequired] = []*upgradeLib.NodeUpgradeState{
+			{Node: newLabeledTestNode("required", nil, nil)},
+			{Node: newLabeledTestNode("required-paused", map[string]string{
+				stateLabel: upgradeLib.UpgradeStateUpgradeRequired}, paused)},
+		}
+		state.NodeStates[upgradeLib.UpgradeStateDrainRequired] = []*upgradeLib.NodeUpgradeState{
+			{Node: newLabeledTestNode("draining-paused", map[string]string{
+				stateLabel: upgradeLib.UpgradeStateDrainRequired}, paused)},
+			{Node: newLabeledTestNode("draining-not-paused", map[string]string{
+				stateLabel: upgradeLib.UpgradeStateDrainRequired}, map[string]string{
+				GetUpgradePausedAnnotationKey(): "false"})},
+		}
+	})
+
+	It("should not process the paused nodes", func() {
+		filtered := excludePausedNodes(log.Log, state)
+		Expect(nodeNames(filtered.NodeStates[upgradeLib.UpgradeStateUpgradeRequired])).To(
+			Equal([]string{"required"}))
+		Expect(nodeNames(filtered.NodeStates[upgradeLib.UpgradeStateDrainRequired])).To(
+			Equal([]string{"draining-not-paused"}))
+		Expect(nodeNames(filtered.NodeStates[pausedNodesState])).To(
+			ConsistOf("required-paused", "draining-paused"))
+		Expect(state.NodeStates[upgradeLib.UpgradeStateUpgradeRequired]).To(HaveLen(2))
+	})
+
+	It("should account the paused nodes with upgrade in progress in parallel upgrades", func() {
+		filtered := excludePausedNodes(log.Log, state)
+		Expect(countPausedUpgrades(filtered)).To(Equal(1))
+		policy := &upgradeApi.DriverUpgradePolicySpec{AutoUpgrade: true, MaxParallelUpgrades: 3}
+		_, limitedPolicy := limitParallelUpgrades(filtered, policy)
+		Expect(limitedPolicy.MaxParallelUpgrades).To(Equal(2))
+	})
+})
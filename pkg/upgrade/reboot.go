@@ -0,0 +1,309 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	upgradeApi "github.com/NVIDIA/k8s-operator-libs/api/upgrade/v1alpha1"
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/config"
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+const (
+	// UpgradeStateRebootRequired is set when the driver upgrade requires a reboot of the node,
+	// after the reboot the state is changed to pod-restart-required
+	UpgradeStateRebootRequired = "reboot-required"
+	// RebootRequiredAnnotationKeyFmt is the format of the node annotation key which reports that the driver upgrade
+	// requires a reboot of the node, it is set to "true" by the driver container or by the administrator
+	RebootRequiredAnnotationKeyFmt = "nvidia.com/%s-driver-upgrade.reboot-required"
+	// RebootBootIDAnnotationKeyFmt is the format of the node annotation key which holds the boot ID of the node
+	// at the time its reboot was requested
+	RebootBootIDAnnotationKeyFmt = "nvidia.com/%s-driver-upgrade.reboot-boot-id"
+
+	rebootRequiredFile = "/var/run/reboot-required"
+)
+
+// GetRebootRequiredAnnotationKey returns the key of the node annotation which reports that the driver upgrade
+// requires a reboot of the node
+func GetRebootRequiredAnnotationKey() string {
+	return fmt.Sprintf(RebootRequiredAnnotationKeyFmt, upgradeLib.DriverName)
+}
+
+// GetRebootBootIDAnnotationKey returns the key of the node annotation which holds the boot ID of the node
+// at the time its reboot was requested
+func GetRebootBootIDAnnotationKey() string {
+	return fmt.Sprintf(RebootBootIDAnnotationKeyFmt, upgradeLib.DriverName)
+}
+
+// RebootManager moves the nodes whose driver upgrade requires a reboot to the reboot-required state
+// and reboots them
+type RebootManager struct {
+	k8sInterface             kubernetes.Interface
+	nodeUpgradeStateProvider upgradeLib.NodeUpgradeStateProvider
+	log                      logr.Logger
+	eventRecorder            record.EventRecorder
+	// bootIDAnnotationKey is the key of the node annotation which holds the boot ID of the node
+	// at the time its reboot was requested
+	bootIDAnnotationKey string
+	// podName is the name of the helper pod which reboots the node, it is suffixed with the node name
+	podName string
+	// now returns the current time, it can be overridden in tests
+	now func() time.Time
+}
+
+// NewRebootManager creates a RebootManager which reports the node states with the nodeUpgradeStateProvider,
+// bootIDAnnotationKey and podName allow to reboot nodes by different upgrade flows
+func NewRebootManager(
+	k8sInterface kubernetes.Interface,
+	nodeUpgradeStateProvider upgradeLib.NodeUpgradeStateProvider,
+	log logr.Logger,
+	eventRecorder record.EventRecorder,
+	bootIDAnnotationKey, podName string) *RebootManager {
+	return &RebootManager{
+		k8sInterface:             k8sInterface,
+		nodeUpgradeStateProvider: nodeUpgradeStateProvider,
+		log:                      log,
+		eventRecorder:            eventRecorder,
+		bootIDAnnotationKey:      bootIDAnnotationKey,
+		podName:                  podName,
+		now:                      time.Now,
+	}
+}
+
+// getRebootPodName returns the name of the helper pod which reboots the node
+func (m *RebootManager) getRebootPodName(nodeName string) string {
+	return fmt.Sprintf("%s-%s", m.podName, nodeName)
+}
+
+// requestReboots moves the nodes which report that their driver upgrade requires a reboot to the reboot-required
+// state, it returns a copy of the cluster upgrade state in which these nodes are in the reboot-required state
+func (m *RebootManager) requestReboots(ctx context.Context,
+	state *upgradeLib.ClusterUpgradeState) (*upgradeLib.ClusterUpgradeState, error) {
+	updated := upgradeLib.NewClusterUpgradeState()
+	for upgradeState, nodeStates := range state.NodeStates {
+		if upgradeState != upgradeLib.UpgradeStatePodRestartRequired &&
+			upgradeState != upgradeLib.UpgradeStateValidationRequired {
+			updated.NodeStates[upgradeState] = append(updated.NodeStates[upgradeState], nodeStates...)
+			continue
+		}
+		for _, nodeState := range nodeStates {
+			node := nodeState.Node
+			if node.Annotations[GetRebootRequiredAnnotationKey()] != "true" {
+				updated.NodeStates[upgradeState] = append(updated.NodeStates[upgradeState], nodeState)
+				continue
+			}
+			m.log.V(consts.LogLevelInfo).Info("Driver upgrade requires reboot of the node", "node", node.Name)
+			err := m.nodeUpgradeStateProvider.ChangeNodeUpgradeState(ctx, node, UpgradeStateRebootRequired)
+			if err != nil {
+				return nil, err
+			}
+			m.logEvent(node, corev1.EventTypeNormal, "Driver upgrade requires reboot of the node")
+			updated.NodeStates[UpgradeStateRebootRequired] = append(
+				updated.NodeStates[UpgradeStateRebootRequired], nodeState)
+		}
+	}
+	return &updated, nil
+}
+
+// limitParallelUpgrades accounts the nodes in the reboot-required and the hook states and the paused nodes
+// on which the upgrade is in progress, which are ignored by the upgrade library, in the upgrades in progress.
+// It returns the state and the upgrade policy to apply by the upgrade library
+func limitParallelUpgrades(state *upgradeLib.ClusterUpgradeState,
+	upgradePolicy *upgradeApi.DriverUpgradePolicySpec) (
+	*upgradeLib.ClusterUpgradeState, *upgradeApi.DriverUpgradePolicySpec) {
+	inProgress := len(state.NodeStates[UpgradeStateRebootRequired]) +
+		len(state.NodeStates[UpgradeStatePreDrainHookRequired]) +
+		len(state.NodeStates[UpgradeStatePostDrainHookRequired]) +
+		countPausedUpgrades(state)
+	if inProgress == 0 || upgradePolicy == nil || upgradePolicy.MaxParallelUpgrades == 0 {
+		return state, upgradePolicy
+	}
+	if upgradePolicy.MaxParallelUpgrades > inProgress {
+		policy := upgradePolicy.DeepCopy()
+		policy.MaxParallelUpgrades -= inProgress
+		return state, policy
+	}
+	// no upgrade slots are available, no nodes should start the upgrade
+	limited := upgradeLib.NewClusterUpgradeState()
+	for upgradeState, nodeStates := range state.NodeStates {
+		if upgradeState != upgradeLib.UpgradeStateUpgradeRequired {
+			limited.NodeStates[upgradeState] = nodeStates
+		}
+	}
+	return &limited, upgradePolicy
+}
+
+// processRebootRequiredNodes reboots the nodes in the reboot-required state and moves them to the
+// pod-restart-required state once they are rebooted, or to the upgrade-failed state if the reboot
+// is disabled, failed or timed out
+func (m *RebootManager) processRebootRequiredNodes(ctx context.Context, state *upgradeLib.ClusterUpgradeState,
+	rebootSpec *mellanoxv1alpha1.RebootSpec) error {
+	for _, nodeState := range state.NodeStates[UpgradeStateRebootRequired] {
+		if err := m.ProcessRebootRequiredNode(ctx, nodeState.Node, rebootSpec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProcessRebootRequiredNode reboots the node in the reboot-required state and moves it to the pod-restart-required
+// state once it is rebooted, or to the upgrade-failed state if the reboot is disabled, failed or timed out
+func (m *RebootManager) ProcessRebootRequiredNode(ctx context.Context, node *corev1.Node,
+	rebootSpec *mellanoxv1alpha1.RebootSpec) error {
+	if rebootSpec == nil || !rebootSpec.Enable {
+		m.logEvent(node, corev1.EventTypeWarning, "Driver upgrade requires reboot of the node, but reboot is disabled")
+		return m.nodeUpgradeStateProvider.ChangeNodeUpgradeState(ctx, node, upgradeLib.UpgradeStateFailed)
+	}
+
+	bootID, requested := node.Annotations[m.bootIDAnnotationKey]
+	if !requested {
+		m.log.V(consts.LogLevelInfo).Info("Requesting reboot of the node", "node", node.Name)
+		err := m.patchNodeAnnotations(ctx, node.Name,
+			fmt.Sprintf(`{%q: %q}`, m.bootIDAnnotationKey, node.Status.NodeInfo.BootID))
+		if err != nil {
+			return err
+		}
+		_, err = m.k8sInterface.CoreV1().Pods(rebootPodNamespace()).Create(
+			ctx, newRebootPod(m.getRebootPodName(node.Name), node.Name, rebootSpec), metav1.CreateOptions{})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create reboot pod for node %s: %v", node.Name, err)
+		}
+		m.logEvent(node, corev1.EventTypeNormal, "Requested reboot of the node")
+		return nil
+	}
+
+	if node.Status.NodeInfo.BootID != bootID {
+		m.log.V(consts.LogLevelInfo).Info("Node is rebooted", "node", node.Name)
+		if err := m.deleteRebootPod(ctx, node.Name); err != nil {
+			return err
+		}
+		err := m.patchNodeAnnotations(ctx, node.Name, fmt.Sprintf(`{%q: null, %q: null}`,
+			GetRebootRequiredAnnotationKey(), m.bootIDAnnotationKey))
+		if err != nil {
+			return err
+		}
+		m.logEvent(node, corev1.EventTypeNormal, "Successfully rebooted the node")
+		return m.nodeUpgradeStateProvider.ChangeNodeUpgradeState(ctx, node, upgradeLib.UpgradeStatePodRestartRequired)
+	}
+
+	pod, err := m.k8sInterface.CoreV1().Pods(rebootPodNamespace()).Get(
+		ctx, m.getRebootPodName(node.Name), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// the reboot pod was deleted, request the reboot again
+			return m.patchNodeAnnotations(ctx, node.Name, fmt.Sprintf(`{%q: null}`, m.bootIDAnnotationKey))
+		}
+		return err
+	}
+	reason := ""
+	switch {
+	case pod.Status.Phase == corev1.PodFailed:
+		reason = "reboot pod failed"
+	case rebootSpec.TimeoutSecond > 0 &&
+		m.now().Sub(pod.CreationTimestamp.Time) > time.Duration(rebootSpec.TimeoutSecond)*time.Second:
+		reason = "timed out waiting for the node to reboot"
+	default:
+		m.log.V(consts.LogLevelDebug).Info("Waiting for the node to reboot", "node", node.Name)
+		return nil
+	}
+	m.log.V(consts.LogLevelWarning).Info("Failed to reboot the node", "node", node.Name, "reason", reason)
+	m.logEvent(node, corev1.EventTypeWarning, fmt.Sprintf("Failed to reboot the node, %s", reason))
+	// the reboot-required annotation is kept, so the reboot is requested again on the next upgrade attempt
+	if err := m.deleteRebootPod(ctx, node.Name); err != nil {
+		return err
+	}
+	if err := m.patchNodeAnnotations(ctx, node.Name,
+		fmt.Sprintf(`{%q: null}`, m.bootIDAnnotationKey)); err != nil {
+		return err
+	}
+	return m.nodeUpgradeStateProvider.ChangeNodeUpgradeState(ctx, node, upgradeLib.UpgradeStateFailed)
+}
+
+func (m *RebootManager) patchNodeAnnotations(ctx context.Context, nodeName, annotations string) error {
+	_, err := m.k8sInterface.CoreV1().Nodes().Patch(ctx, nodeName, types.MergePatchType,
+		[]byte(fmt.Sprintf(`{"metadata":{"annotations":%s}}`, annotations)), metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update reboot annotations of node %s: %v", nodeName, err)
+	}
+	return nil
+}
+
+func (m *RebootManager) deleteRebootPod(ctx context.Context, nodeName string) error {
+	err := m.k8sInterface.CoreV1().Pods(rebootPodNamespace()).Delete(
+		ctx, m.getRebootPodName(nodeName), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete reboot pod of node %s: %v", nodeName, err)
+	}
+	return nil
+}
+
+func (m *RebootManager) logEvent(node *corev1.Node, eventType, message string) {
+	if m.eventRecorder != nil {
+		m.eventRecorder.Event(node, eventType, upgradeLib.GetEventReason(), message)
+	}
+}
+
+func rebootPodNamespace() string {
+	return config.FromEnv().State.NetworkOperatorResourceNamespace
+}
+
+// newRebootPod creates the privileged helper pod which reboots the node or requests its reboot
+// from the reboot daemon according to the reboot method
+func newRebootPod(name, nodeName string, rebootSpec *mellanoxv1alpha1.RebootSpec) *corev1.Pod {
+	command := []string{"chroot", "/host", "systemctl", "reboot"}
+	if rebootSpec.Method == mellanoxv1alpha1.RebootMethodRebootDaemon {
+		command = []string{"chroot", "/host", "touch", rebootRequiredFile}
+	}
+	privileged := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: rebootPodNamespace(),
+			Labels:    map[string]string{"app": "network-operator-reboot"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:      nodeName,
+			HostPID:       true,
+			RestartPolicy: corev1.RestartPolicyNever,
+			Tolerations:   []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			Containers: []corev1.Container{{
+				Name:            "reboot",
+				Image:           rebootSpec.Image,
+				Command:         command,
+				SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+				VolumeMounts:    []corev1.VolumeMount{{Name: "host", MountPath: "/host"}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name:         "host",
+				VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/"}},
+			}},
+		},
+	}
+}
@@ -0,0 +1,197 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+	"time"
+
+	upgradeApi "github.com/NVIDIA/k8s-operator-libs/api/upgrade/v1alpha1"
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+)
+
+func newRebootTestNode(name, bootID string, annotations map[string]string) *corev1.Node {
+	node := newTestNode(name)
+	node.Annotations = annotations
+	node.Status.NodeInfo.BootID = bootID
+	return node
+}
+
+var _ = Describe("Reboot tests", func() {
+	var (
+		k8sInterface  *fake.Clientset
+		stateProvider *fakeNodeUpgradeStateProvider
+		manager       *RebootManager
+		rebootSpec    *mellanoxv1alpha1.RebootSpec
+		now           time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Now()
+		stateProvider = newFakeNodeUpgradeStateProvider()
+		rebootSpec = &mellanoxv1alpha1.RebootSpec{
+			Enable: true, Method: mellanoxv1alpha1.RebootMethodHelperPod, Image: "busybox", TimeoutSecond: 600}
+	})
+
+	JustBeforeEach(func() {
+		manager = NewRebootManager(k8sInterface, stateProvider, log.Log, nil,
+			GetRebootBootIDAnnotationKey(), "ofed-driver-reboot")
+		manager.now = func() time.Time { return now }
+	})
+
+	Context("reboot request", func() {
+		BeforeEach(func() {
+			k8sInterface = newFakeClientset()
+		})
+
+		It("should move the nodes which require reboot to reboot-required state", func() {
+			state := upgradeLib.NewClusterUpgradeState()
+			state.NodeStates[upgradeLib.UpgradeStatePodRestartRequired] = []*upgradeLib.NodeUpgradeState{
+				{Node: newRebootTestNode("node1", "boot1", map[string]string{GetRebootRequiredAnnotationKey(): "true"})},
+				{Node: newRebootTestNode("node2", "boot1", nil)},
+			}
+			state.NodeStates[upgradeLib.UpgradeStateDone] = []*upgradeLib.NodeUpgradeState{
+				{Node: newRebootTestNode("node3", "boot1", map[string]string{GetRebootRequiredAnnotationKey(): "true"})},
+			}
+
+			updated, err := manager.requestReboots(context.TODO(), &state)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stateProvider.getState("node1")).To(Equal(UpgradeStateRebootRequired))
+			Expect(stateProvider.getState("node2")).To(BeEmpty())
+			Expect(stateProvider.getState("node3")).To(BeEmpty())
+			Expect(updated.NodeStates[UpgradeStateRebootRequired]).To(HaveLen(1))
+			Expect(updated.NodeStates[upgradeLib.UpgradeStatePodRestartRequired]).To(HaveLen(1))
+			Expect(updated.NodeStates[upgradeLib.UpgradeStateDone]).To(HaveLen(1))
+		})
+	})
+
+	Context("reboot of the node", func() {
+		var node *corev1.Node
+
+		BeforeEach(func() {
+			node = newRebootTestNode("node1", "boot1", map[string]string{GetRebootRequiredAnnotationKey(): "true"})
+			k8sInterface = newFakeClientset(node.DeepCopy())
+		})
+
+		It("should move the node to upgrade-failed state if reboot is disabled", func() {
+			rebootSpec.Enable = false
+			Expect(manager.ProcessRebootRequiredNode(context.TODO(), node, rebootSpec)).To(Succeed())
+			Expect(stateProvider.getState("node1")).To(Equal(upgradeLib.UpgradeStateFailed))
+		})
+
+		It("should create the reboot pod and record the boot ID", func() {
+			Expect(manager.ProcessRebootRequiredNode(context.TODO(), node, rebootSpec)).To(Succeed())
+			Expect(getNode(k8sInterface, "node1").Annotations).To(HaveKeyWithValue(GetRebootBootIDAnnotationKey(), "boot1"))
+			pod, err := k8sInterface.CoreV1().Pods(rebootPodNamespace()).Get(
+				context.TODO(), manager.getRebootPodName("node1"), metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pod.Spec.NodeName).To(Equal("node1"))
+			Expect(pod.Spec.Containers[0].Image).To(Equal("busybox"))
+			Expect(pod.Spec.Containers[0].Command).To(Equal([]string{"chroot", "/host", "systemctl", "reboot"}))
+			Expect(stateProvider.getState("node1")).To(BeEmpty())
+		})
+
+		It("should request the reboot from the reboot daemon", func() {
+			rebootSpec.Method = mellanoxv1alpha1.RebootMethodRebootDaemon
+			Expect(manager.ProcessRebootRequiredNode(context.TODO(), node, rebootSpec)).To(Succeed())
+			pod, err := k8sInterface.CoreV1().Pods(rebootPodNamespace()).Get(
+				context.TODO(), manager.getRebootPodName("node1"), metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pod.Spec.Containers[0].Command).To(Equal(
+				[]string{"chroot", "/host", "touch", "/var/run/reboot-required"}))
+		})
+
+		Context("reboot requested", func() {
+			BeforeEach(func() {
+				node.Annotations[GetRebootBootIDAnnotationKey()] = "boot1"
+				pod := newRebootPod("ofed-driver-reboot-node1", "node1", rebootSpec)
+				pod.CreationTimestamp = metav1.NewTime(now.Add(-time.Minute))
+				k8sInterface = newFakeClientset(node.DeepCopy(), pod)
+			})
+
+			It("should wait for the node to reboot", func() {
+				Expect(manager.ProcessRebootRequiredNode(context.TODO(), node, rebootSpec)).To(Succeed())
+				Expect(stateProvider.getState("node1")).To(BeEmpty())
+			})
+
+			It("should move the node to pod-restart-required state once rebooted", func() {
+				node.Status.NodeInfo.BootID = "boot2"
+				Expect(manager.ProcessRebootRequiredNode(context.TODO(), node, rebootSpec)).To(Succeed())
+				Expect(stateProvider.getState("node1")).To(Equal(upgradeLib.UpgradeStatePodRestartRequired))
+				annotations := getNode(k8sInterface, "node1").Annotations
+				Expect(annotations).NotTo(HaveKey(GetRebootRequiredAnnotationKey()))
+				Expect(annotations).NotTo(HaveKey(GetRebootBootIDAnnotationKey()))
+				_, err := k8sInterface.CoreV1().Pods(rebootPodNamespace()).Get(
+					context.TODO(), manager.getRebootPodName("node1"), metav1.GetOptions{})
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			})
+
+			It("should move the node to upgrade-failed state on timeout", func() {
+				now = now.Add(time.Hour)
+				Expect(manager.ProcessRebootRequiredNode(context.TODO(), node, rebootSpec)).To(Succeed())
+				Expect(stateProvider.getState("node1")).To(Equal(upgradeLib.UpgradeStateFailed))
+				annotations := getNode(k8sInterface, "node1").Annotations
+				Expect(annotations).To(HaveKey(GetRebootRequiredAnnotationKey()))
+				Expect(annotations).NotTo(HaveKey(GetRebootBootIDAnnotationKey()))
+				_, err := k8sInterface.CoreV1().Pods(rebootPodNamespace()).Get(
+					context.TODO(), manager.getRebootPodName("node1"), metav1.GetOptions{})
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+	})
+
+	Context("parallel upgrades", func() {
+		var state *upgradeLib.ClusterUpgradeState
+
+		BeforeEach(func() {
+			state = newClusterUpgradeState(map[string][]string{
+				UpgradeStateRebootRequired:             {"node1"},
+				upgradeLib.UpgradeStateUpgradeRequired: {"node2", "node3"},
+			})
+		})
+
+		It("should not change unlimited parallel upgrades", func() {
+			policy := &upgradeApi.DriverUpgradePolicySpec{AutoUpgrade: true}
+			limitedState, limitedPolicy := limitParallelUpgrades(state, policy)
+			Expect(limitedState).To(Equal(state))
+			Expect(limitedPolicy).To(Equal(policy))
+		})
+
+		It("should account the rebooting nodes in parallel upgrades", func() {
+			policy := &upgradeApi.DriverUpgradePolicySpec{AutoUpgrade: true, MaxParallelUpgrades: 3}
+			_, limitedPolicy := limitParallelUpgrades(state, policy)
+			Expect(limitedPolicy.MaxParallelUpgrades).To(Equal(2))
+			Expect(policy.MaxParallelUpgrades).To(Equal(3))
+		})
+
+		It("should not start upgrades if no upgrade slots are available", func() {
+			policy := &upgradeApi.DriverUpgradePolicySpec{AutoUpgrade: true, MaxParallelUpgrades: 1}
+			limitedState, _ := limitParallelUpgrades(state, policy)
+			Expect(limitedState.NodeStates).NotTo(HaveKey(upgradeLib.UpgradeStateUpgradeRequired))
+			Expect(limitedState.NodeStates[UpgradeStateRebootRequired]).To(HaveLen(1))
+		})
+	})
+})
@@ -0,0 +1,215 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+const (
+	// SafeLoadStageAnnotationKeyFmt is the format of the node annotation key which reports the stage
+	// of the safe driver load on the node
+	SafeLoadStageAnnotationKeyFmt = "nvidia.com/%s-driver-upgrade.safe-load-stage"
+	// SafeLoadCancelAnnotationKeyFmt is the format of the node annotation key which cancels the safe driver load
+	// on the node when set to "true", the driver is loaded without waiting for the workloads to be stopped
+	SafeLoadCancelAnnotationKeyFmt = "nvidia.com/%s-driver-upgrade.safe-load-cancel"
+
+	// SafeLoadStageWorkloadsStopping is reported while the driver waits for the node to be cordoned and drained
+	SafeLoadStageWorkloadsStopping = "workloads-stopping"
+	// SafeLoadStageDriverUnloading is reported once the driver load is unblocked until the driver pod is running
+	SafeLoadStageDriverUnloading = "driver-unloading"
+	// SafeLoadStageDriverLoading is reported while the driver pod is running but not ready yet
+	SafeLoadStageDriverLoading = "driver-loading"
+	// SafeLoadStageDone is reported once the driver pod is ready, until the next safe driver load on the node
+	SafeLoadStageDone = "done"
+)
+
+// safeLoadCancelableStates are the upgrade states from which a node is moved to the pod-restart-required state
+// when its safe driver load is canceled, the node is not drained yet in these states
+var safeLoadCancelableStates = []string{
+	upgradeLib.UpgradeStateUpgradeRequired,
+	UpgradeStateCanaryWaitRequired,
+	UpgradeStatePreDrainHookRequired,
+	upgradeLib.UpgradeStateCordonRequired,
+	upgradeLib.UpgradeStateWaitForJobsRequired,
+	upgradeLib.UpgradeStatePodDeletionRequired,
+	upgradeLib.UpgradeStateDrainRequired,
+	upgradeLib.UpgradeStateFailed,
+}
+
+// GetSafeLoadStageAnnotationKey returns the key of the node annotation which reports the stage
+// of the safe driver load on the node
+func GetSafeLoadStageAnnotationKey() string {
+	return fmt.Sprintf(SafeLoadStageAnnotationKeyFmt, upgradeLib.DriverName)
+}
+
+// GetSafeLoadCancelAnnotationKey returns the key of the node annotation which cancels the safe driver load
+// on the node
+func GetSafeLoadCancelAnnotationKey() string {
+	return fmt.Sprintf(SafeLoadCancelAnnotationKeyFmt, upgradeLib.DriverName)
+}
+
+// SafeLoadManager reports the stage of the safe driver load of each node in a node annotation
+// and cancels the safe driver load of the nodes annotated for it
+type SafeLoadManager struct {
+	k8sInterface             kubernetes.Interface
+	nodeUpgradeStateProvider upgradeLib.NodeUpgradeStateProvider
+	log                      logr.Logger
+	eventRecorder            record.EventRecorder
+}
+
+// NewSafeLoadManager creates a SafeLoadManager which reports the node states with the nodeUpgradeStateProvider
+func NewSafeLoadManager(
+	k8sInterface kubernetes.Interface,
+	nodeUpgradeStateProvider upgradeLib.NodeUpgradeStateProvider,
+	log logr.Logger,
+	eventRecorder record.EventRecorder) *SafeLoadManager {
+	return &SafeLoadManager{
+		k8sInterface:             k8sInterface,
+		nodeUpgradeStateProvider: nodeUpgradeStateProvider,
+		log:                      log,
+		eventRecorder:            eventRecorder,
+	}
+}
+
+// processSafeLoad cancels the safe driver load of the nodes annotated for it and updates the stage
+// of the safe driver load reported on the nodes. The driver load of a canceled node is unblocked and the node
+// is moved to the pod-restart-required state if it is not drained yet. It returns a copy of the cluster
+// upgrade state in which the canceled nodes are in the pod-restart-required state
+func (m *SafeLoadManager) processSafeLoad(ctx context.Context,
+	state *upgradeLib.ClusterUpgradeState) (*upgradeLib.ClusterUpgradeState, error) {
+	updated := upgradeLib.NewClusterUpgradeState()
+	for upgradeState, nodeStates := range state.NodeStates {
+		for _, nodeState := range nodeStates {
+			node := nodeState.Node
+			nodeUpgradeState := upgradeState
+			waiting := node.Annotations[upgradeLib.GetUpgradeDriverWaitForSafeLoadAnnotationKey()] != ""
+			canceled := false
+			if _, ok := node.Annotations[GetSafeLoadCancelAnnotationKey()]; ok {
+				var err error
+				canceled, err = m.cancelSafeLoad(ctx, nodeState, upgradeState, waiting)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if canceled && slices.Contains(safeLoadCancelableStates, upgradeState) {
+				nodeUpgradeState = upgradeLib.UpgradeStatePodRestartRequired
+			}
+			updated.NodeStates[nodeUpgradeState] = append(updated.NodeStates[nodeUpgradeState], nodeState)
+			if err := m.updateSafeLoadStage(ctx, nodeState, waiting && !canceled, canceled); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &updated, nil
+}
+
+// cancelSafeLoad removes the cancel annotation from the node and, if the driver is waiting for the safe load
+// and the annotation is set to "true", unblocks the driver load and moves the node to the pod-restart-required
+// state if it is not drained yet. It returns true if the safe driver load is canceled
+func (m *SafeLoadManager) cancelSafeLoad(ctx context.Context, nodeState *upgradeLib.NodeUpgradeState,
+	upgradeState string, waiting bool) (bool, error) {
+	node := nodeState.Node
+	if !waiting || node.Annotations[GetSafeLoadCancelAnnotationKey()] != "true" {
+		m.log.V(consts.LogLevelInfo).Info("No safe driver load to cancel on the node", "node", node.Name)
+		return false, m.patchNodeAnnotations(ctx, node.Name, fmt.Sprintf(`{%q: null}`,
+			GetSafeLoadCancelAnnotationKey()))
+	}
+	m.log.V(consts.LogLevelInfo).Info("Canceling safe driver load on the node",
+		"node", node.Name, "upgradeState", upgradeState)
+	if err := m.patchNodeAnnotations(ctx, node.Name, fmt.Sprintf(`{%q: null, %q: null}`,
+		upgradeLib.GetUpgradeDriverWaitForSafeLoadAnnotationKey(), GetSafeLoadCancelAnnotationKey())); err != nil {
+		return false, err
+	}
+	m.logEvent(node, corev1.EventTypeNormal, "Safe driver load canceled, loading the driver")
+	if !slices.Contains(safeLoadCancelableStates, upgradeState) {
+		return true, nil
+	}
+	return true, m.nodeUpgradeStateProvider.ChangeNodeUpgradeState(
+		ctx, node, upgradeLib.UpgradeStatePodRestartRequired)
+}
+
+// updateSafeLoadStage reports the stage of the safe driver load in the node annotation if it changed,
+// the stage is only reported for the nodes on which the driver waits or waited for the safe load
+func (m *SafeLoadManager) updateSafeLoadStage(ctx context.Context, nodeState *upgradeLib.NodeUpgradeState,
+	waiting, canceled bool) error {
+	node := nodeState.Node
+	current := node.Annotations[GetSafeLoadStageAnnotationKey()]
+	previous := current
+	if canceled {
+		// the node annotations are not updated in the cluster upgrade state
+		previous = SafeLoadStageWorkloadsStopping
+	}
+	stage := getSafeLoadStage(nodeState.DriverPod, previous, waiting)
+	if stage == current {
+		return nil
+	}
+	m.log.V(consts.LogLevelInfo).Info("Safe driver load stage of the node changed",
+		"node", node.Name, "from", current, "to", stage)
+	if err := m.patchNodeAnnotations(ctx, node.Name, fmt.Sprintf(`{%q: %q}`,
+		GetSafeLoadStageAnnotationKey(), stage)); err != nil {
+		return err
+	}
+	m.logEvent(node, corev1.EventTypeNormal, fmt.Sprintf("Safe driver load stage is %s", stage))
+	return nil
+}
+
+// getSafeLoadStage returns the stage of the safe driver load on the node from the current stage reported
+// on the node, the driver pod and whether the driver waits for the safe load
+func getSafeLoadStage(driverPod *corev1.Pod, current string, waiting bool) string {
+	switch {
+	case waiting:
+		return SafeLoadStageWorkloadsStopping
+	case current == "" || current == SafeLoadStageDone:
+		return current
+	case driverPod == nil || driverPod.Status.Phase != corev1.PodRunning:
+		return SafeLoadStageDriverUnloading
+	}
+	for _, cond := range driverPod.Status.Conditions {
+		if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+			return SafeLoadStageDone
+		}
+	}
+	return SafeLoadStageDriverLoading
+}
+
+func (m *SafeLoadManager) patchNodeAnnotations(ctx context.Context, nodeName, annotations string) error {
+	_, err := m.k8sInterface.CoreV1().Nodes().Patch(ctx, nodeName, types.MergePatchType,
+		[]byte(fmt.Sprintf(`{"metadata":{"annotations":%s}}`, annotations)), metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update safe load annotations of node %s: %v", nodeName, err)
+	}
+	return nil
+}
+
+func (m *SafeLoadManager) logEvent(node *corev1.Node, eventType, message string) {
+	if m.eventRecorder != nil {
+		m.eventRecorder.Event(node, eventType, upgradeLib.GetEventReason(), message)
+	}
+}
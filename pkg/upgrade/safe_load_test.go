@@ -0,0 +1,99 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Safe load tests", func() {
+	var (
+		k8sInterface  *fake.Clientset
+		stateProvider *fakeNodeUpgradeStateProvider
+		manager       *SafeLoadManager
+	)
+
+	newSafeLoadNode := func(name string, annotations map[string]string) *corev1.Node {
+		node := newTestNode(name)
+		node.Annotations = annotations
+		return node
+	}
+
+	BeforeEach(func() {
+		k8sInterface = newFakeClientset(
+			newSafeLoadNode("node1", map[string]string{
+				upgradeLib.GetUpgradeDriverWaitForSafeLoadAnnotationKey(): "true",
+				GetSafeLoadCancelAnnotationKey():                          "true"}),
+			newSafeLoadNode("node2", map[string]string{
+				upgradeLib.GetUpgradeDriverWaitForSafeLoadAnnotationKey(): "true"}),
+			newSafeLoadNode("node3", map[string]string{GetSafeLoadCancelAnnotationKey(): "true"}))
+		stateProvider = newFakeNodeUpgradeStateProvider()
+		manager = NewSafeLoadManager(k8sInterface, stateProvider, log.Log, nil)
+	})
+
+	It("should cancel the safe load and report the safe load stage of the nodes", func() {
+		clusterState := upgradeLib.NewClusterUpgradeState()
+		clusterState.NodeStates[upgradeLib.UpgradeStateDrainRequired] = []*upgradeLib.NodeUpgradeState{
+			{Node: getNode(k8sInterface, "node1")}, {Node: getNode(k8sInterface, "node2")}}
+		clusterState.NodeStates[upgradeLib.UpgradeStateDone] = []*upgradeLib.NodeUpgradeState{
+			{Node: getNode(k8sInterface, "node3")}}
+
+		updated, err := manager.processSafeLoad(context.TODO(), &clusterState)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodeNames(updated.NodeStates[upgradeLib.UpgradeStatePodRestartRequired])).To(Equal([]string{"node1"}))
+		Expect(nodeNames(updated.NodeStates[upgradeLib.UpgradeStateDrainRequired])).To(Equal([]string{"node2"}))
+		Expect(nodeNames(updated.NodeStates[upgradeLib.UpgradeStateDone])).To(Equal([]string{"node3"}))
+		Expect(stateProvider.getState("node1")).To(Equal(upgradeLib.UpgradeStatePodRestartRequired))
+		Expect(stateProvider.getState("node2")).To(BeEmpty())
+
+		node := getNode(k8sInterface, "node1")
+		Expect(node.Annotations).NotTo(HaveKey(upgradeLib.GetUpgradeDriverWaitForSafeLoadAnnotationKey()))
+		Expect(node.Annotations).NotTo(HaveKey(GetSafeLoadCancelAnnotationKey()))
+		Expect(node.Annotations).To(HaveKeyWithValue(GetSafeLoadStageAnnotationKey(), SafeLoadStageDriverUnloading))
+		node = getNode(k8sInterface, "node2")
+		Expect(node.Annotations).To(HaveKeyWithValue(GetSafeLoadStageAnnotationKey(), SafeLoadStageWorkloadsStopping))
+		node = getNode(k8sInterface, "node3")
+		Expect(node.Annotations).NotTo(HaveKey(GetSafeLoadCancelAnnotationKey()))
+		Expect(node.Annotations).NotTo(HaveKey(GetSafeLoadStageAnnotationKey()))
+	})
+
+	DescribeTable("should get the safe load stage",
+		func(phase corev1.PodPhase, ready bool, current string, waiting bool, expected string) {
+			pod := newTestPod("driver", "node1")
+			pod.Status.Phase = phase
+			if ready {
+				pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+			}
+			Expect(getSafeLoadStage(pod, current, waiting)).To(Equal(expected))
+		},
+		Entry("waiting for the safe load", corev1.PodPending, false, "", true, SafeLoadStageWorkloadsStopping),
+		Entry("no safe load", corev1.PodRunning, true, "", false, ""),
+		Entry("driver pod not running", corev1.PodPending, false,
+			SafeLoadStageWorkloadsStopping, false, SafeLoadStageDriverUnloading),
+		Entry("driver pod not ready", corev1.PodRunning, false,
+			SafeLoadStageDriverUnloading, false, SafeLoadStageDriverLoading),
+		Entry("driver pod ready", corev1.PodRunning, true, SafeLoadStageDriverLoading, false, SafeLoadStageDone),
+		Entry("done", corev1.PodPending, false, SafeLoadStageDone, false, SafeLoadStageDone),
+	)
+})
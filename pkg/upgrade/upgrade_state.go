@@ -0,0 +1,209 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+	"fmt"
+
+	upgradeApi "github.com/NVIDIA/k8s-operator-libs/api/upgrade/v1alpha1"
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+// ClusterUpgradeStateManager is an upgradeLib.ClusterUpgradeStateManager which additionally handles
+// the network-operator specific settings of the upgrade policy
+type ClusterUpgradeStateManager interface {
+	upgradeLib.ClusterUpgradeStateManager
+	// SetUpgradePolicy applies the network-operator specific settings of the upgrade policy,
+	// it should be called before ApplyState
+	SetUpgradePolicy(policy *mellanoxv1alpha1.DriverUpgradePolicySpec)
+}
+
+// clusterUpgradeStateManager implements ClusterUpgradeStateManager interface
+type clusterUpgradeStateManager struct {
+	upgradeLib.ClusterUpgradeStateManager
+	drainManager *DrainManager
+	metrics      *stateMetricsRecorder
+	coordinator  *gpuUpgradeCoordinator
+	reboot       *RebootManager
+	hooks        *HookManager
+	safeLoad     *SafeLoadManager
+	canary       *CanaryManager
+	k8sInterface kubernetes.Interface
+	log          logr.Logger
+
+	nodeUpgradeStateProvider upgradeLib.NodeUpgradeStateProvider
+	// paused is set when the upgrade is paused by the upgrade policy
+	paused bool
+	// cordonOnly is set when the driver pod should be restarted without draining the node
+	cordonOnly bool
+	// gpuOperatorCoordination is set when the upgrades should be coordinated with the GPU Operator
+	gpuOperatorCoordination bool
+	rebootSpec              *mellanoxv1alpha1.RebootSpec
+	hooksSpec               *mellanoxv1alpha1.UpgradeHooksSpec
+	canarySpec              *mellanoxv1alpha1.CanarySpec
+	// nodeSorter orders the nodes waiting for the upgrade, the order is arbitrary if nil
+	nodeSorter NodeSorter
+}
+
+// NewClusterUpgradeStateManager creates a ClusterUpgradeStateManager from the upgrade library
+// with the network-operator specific managers
+func NewClusterUpgradeStateManager(
+	log logr.Logger,
+	k8sConfig *rest.Config,
+	eventRecorder record.EventRecorder) (ClusterUpgradeStateManager, error) {
+	manager, err := upgradeLib.NewClusterUpgradeStateManager(log, k8sConfig, eventRecorder)
+	if err != nil {
+		return nil, err
+	}
+	managerImpl, ok := manager.(*upgradeLib.ClusterUpgradeStateManagerImpl)
+	if !ok {
+		return nil, fmt.Errorf("unexpected ClusterUpgradeStateManager implementation %T", manager)
+	}
+	dynamicClient, err := dynamic.NewForConfig(k8sConfig)
+	if err != nil {
+		return nil, err
+	}
+	drainManager := NewDrainManager(
+		managerImpl.K8sInterface, dynamicClient, managerImpl.NodeUpgradeStateProvider, log, eventRecorder)
+	managerImpl.DrainManager = drainManager
+	managerImpl.CordonManager = &cordonManager{CordonManager: managerImpl.CordonManager, drainManager: drainManager}
+	return &clusterUpgradeStateManager{
+		ClusterUpgradeStateManager: managerImpl,
+		drainManager:               drainManager,
+		metrics:                    newStateMetricsRecorder(),
+		coordinator:                &gpuUpgradeCoordinator{k8sInterface: managerImpl.K8sInterface, log: log},
+		reboot: NewRebootManager(managerImpl.K8sInterface, managerImpl.NodeUpgradeStateProvider, log, eventRecorder,
+			GetRebootBootIDAnnotationKey(), fmt.Sprintf("%s-driver-reboot", upgradeLib.DriverName)),
+		hooks: NewHookManager(
+			k8sConfig, managerImpl.K8sInterface, managerImpl.NodeUpgradeStateProvider, log, eventRecorder),
+		safeLoad: NewSafeLoadManager(
+			managerImpl.K8sInterface, managerImpl.NodeUpgradeStateProvider, log, eventRecorder),
+		canary:       NewCanaryManager(managerImpl.NodeUpgradeStateProvider, log),
+		k8sInterface: managerImpl.K8sInterface,
+		log:          log,
+
+		nodeUpgradeStateProvider: managerImpl.NodeUpgradeStateProvider,
+	}, nil
+}
+
+// SetUpgradePolicy applies the network-operator specific settings of the upgrade policy
+func (m *clusterUpgradeStateManager) SetUpgradePolicy(policy *mellanoxv1alpha1.DriverUpgradePolicySpec) {
+	var retryPolicy *mellanoxv1alpha1.DrainRetryPolicySpec
+	var podDeletion *mellanoxv1alpha1.PodDeletionOnDrainTimeoutSpec
+	var drainSpec *mellanoxv1alpha1.DrainSpec
+	nodeMaintenance := false
+	if policy != nil {
+		podDeletion = policy.PodDeletionOnDrainTimeout
+		drainSpec = policy.DrainSpec
+	}
+	if policy != nil && policy.DrainSpec != nil {
+		retryPolicy = policy.DrainSpec.RetryPolicy
+		nodeMaintenance = policy.DrainSpec.NodeMaintenance
+	}
+	m.drainManager.SetRetryPolicy(retryPolicy)
+	m.drainManager.SetPodDeletionPolicy(podDeletion)
+	m.drainManager.SetNodeMaintenance(nodeMaintenance)
+	m.drainManager.SetDrainOptions(drainSpec)
+	m.paused = policy != nil && policy.Paused
+	m.gpuOperatorCoordination = policy != nil && policy.GPUOperatorCoordination
+	m.cordonOnly = policy != nil && policy.CordonOnly
+	m.rebootSpec = nil
+	m.hooksSpec = nil
+	m.canarySpec = nil
+	m.nodeSorter = nil
+	if policy != nil {
+		m.rebootSpec = policy.Reboot.DeepCopy()
+		m.hooksSpec = policy.Hooks.DeepCopy()
+		m.canarySpec = policy.Canary.DeepCopy()
+		m.nodeSorter = NewNodeSorter(m.k8sInterface, policy.NodeOrder.DeepCopy())
+	}
+}
+
+// ApplyState records the metrics of the cluster upgrade state and processes each node's state,
+// unless the upgrade is paused. The nodes whose upgrade is paused by the node annotation are not processed,
+// when the coordination with the GPU Operator is enabled, the nodes on which the GPU driver upgrade
+// is in progress are not upgraded and the GPU driver upgrade is paused on the nodes being upgraded.
+// The stage of the safe driver load is reported on the nodes, the nodes on which it is canceled
+// are moved to the pod-restart-required state.
+// With a canary phase, the other nodes wait for the upgrade in the canary-wait-required state until
+// the driver is ready on the upgraded canary nodes, which are in the canary-soak-required state meanwhile.
+// The nodes whose driver upgrade requires a reboot are handled in the reboot-required state
+// and the upgrade hooks are run in the pre-drain-hook-required and post-drain-hook-required states,
+// which are not known to the upgrade library. In the cordon-only mode the pod deletion and the drain of the nodes
+// are skipped, the driver pod is restarted once the node is cordoned. The nodes waiting for the upgrade
+// are ordered by the node order policy, the upgrade library starts their upgrade in this order
+func (m *clusterUpgradeStateManager) ApplyState(ctx context.Context,
+	currentState *upgradeLib.ClusterUpgradeState, upgradePolicy *upgradeApi.DriverUpgradePolicySpec) error {
+	m.metrics.record(currentState)
+	if m.paused {
+		m.log.V(consts.LogLevelInfo).Info("Driver upgrade is paused, keeping the nodes in their upgrade state")
+		return nil
+	}
+	state := excludePausedNodes(m.log, currentState)
+	if m.gpuOperatorCoordination {
+		state = m.coordinator.excludeGPUUpgradingNodes(state)
+	}
+	state, err := m.safeLoad.processSafeLoad(ctx, state)
+	if err != nil {
+		return err
+	}
+	state, err = m.canary.processCanary(ctx, state, m.canarySpec)
+	if err != nil {
+		return err
+	}
+	state, err = m.reboot.requestReboots(ctx, state)
+	if err != nil {
+		return err
+	}
+	if err := m.reboot.processRebootRequiredNodes(ctx, state, m.rebootSpec); err != nil {
+		return err
+	}
+	if err := m.hooks.cleanupHooks(ctx, state); err != nil {
+		return err
+	}
+	state, err = m.hooks.requestHooks(ctx, state, m.hooksSpec)
+	if err != nil {
+		return err
+	}
+	if err := m.hooks.processHookRequiredNodes(ctx, state, m.hooksSpec); err != nil {
+		return err
+	}
+	if m.cordonOnly {
+		state, err = skipNodesDrain(ctx, m.nodeUpgradeStateProvider, m.log, state)
+		if err != nil {
+			return err
+		}
+	}
+	state, err = orderUpgradeRequiredNodes(ctx, state, m.nodeSorter)
+	if err != nil {
+		return err
+	}
+	state, upgradePolicy = limitParallelUpgrades(state, upgradePolicy)
+	if err := m.ClusterUpgradeStateManager.ApplyState(ctx, state, upgradePolicy); err != nil {
+		return err
+	}
+	return m.coordinator.syncGPUUpgradePause(ctx, currentState, m.gpuOperatorCoordination)
+}
@@ -0,0 +1,71 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	upgradeLib "github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpgrade(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Upgrade Suite")
+}
+
+var _ = BeforeSuite(func() {
+	upgradeLib.SetDriverName("ofed")
+})
+
+// fakeNodeUpgradeStateProvider records the upgrade states set for the nodes
+type fakeNodeUpgradeStateProvider struct {
+	mu     sync.Mutex
+	states map[string]string
+}
+
+func newFakeNodeUpgradeStateProvider() *fakeNodeUpgradeStateProvider {
+	return &fakeNodeUpgradeStateProvider{states: map[string]string{}}
+}
+
+func (p *fakeNodeUpgradeStateProvider) GetNode(_ context.Context, nodeName string) (*corev1.Node, error) {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}, nil
+}
+
+func (p *fakeNodeUpgradeStateProvider) ChangeNodeUpgradeState(
+	_ context.Context, node *corev1.Node, newNodeState string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.states[node.Name] = newNodeState
+	return nil
+}
+
+func (p *fakeNodeUpgradeStateProvider) ChangeNodeUpgradeAnnotation(
+	_ context.Context, _ *corev1.Node, _ string, _ string) error {
+	return nil
+}
+
+func (p *fakeNodeUpgradeStateProvider) getState(nodeName string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.states[nodeName]
+}
@@ -26,6 +26,7 @@ import (
 	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	"github.com/pkg/errors"
 
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
 	"github.com/Mellanox/network-operator/pkg/clustertype"
 	"github.com/Mellanox/network-operator/pkg/consts"
 	"github.com/Mellanox/network-operator/pkg/staticconfig"
@@ -68,10 +69,33 @@ func GetNetworkAttachmentDefLink(netAttDef *netattdefv1.NetworkAttachmentDefinit
 	return
 }
 
+// cniFlavorDirectories are the locations of the CNI binaries and configurations of the Kubernetes distributions
+var cniFlavorDirectories = map[mellanoxv1alpha1.CNIFlavor]struct {
+	bin  string
+	conf string
+}{
+	mellanoxv1alpha1.CNIFlavorKubernetes: {consts.DefaultCniBinDirectory, consts.DefaultCniConfDirectory},
+	mellanoxv1alpha1.CNIFlavorOpenshift:  {consts.OcpCniBinDirectory, consts.DefaultCniConfDirectory},
+	mellanoxv1alpha1.CNIFlavorK3s: {
+		"/var/lib/rancher/k3s/data/current/bin", "/var/lib/rancher/k3s/agent/etc/cni/net.d"},
+	mellanoxv1alpha1.CNIFlavorRKE2: {consts.DefaultCniBinDirectory, consts.DefaultCniConfDirectory},
+	mellanoxv1alpha1.CNIFlavorMicroK8s: {
+		"/var/snap/microk8s/current/opt/cni/bin", "/var/snap/microk8s/current/args/cni-network"},
+}
+
 // GetCniBinDirectory returns the location where the CNI binaries are stored on the node.
 func GetCniBinDirectory(staticInfo staticconfig.Provider,
-	clusterInfo clustertype.Provider) string {
-	// First we try to set the user-set value, then fallback to defaults for Openshift / K8s
+	clusterInfo clustertype.Provider, cniDirectories *mellanoxv1alpha1.CNIDirectoriesSpec) string {
+	// First we try the directory set in the NicClusterPolicy, either directly or by its flavor,
+	// then the user-set value of the operator, then fallback to defaults for Openshift / K8s
+	if cniDirectories != nil {
+		if cniDirectories.BinDirectory != "" {
+			return cniDirectories.BinDirectory
+		}
+		if flavor, ok := cniFlavorDirectories[cniDirectories.Flavor]; ok {
+			return flavor.bin
+		}
+	}
 	userSetDirectory := staticInfo.GetStaticConfig().CniBinDirectory
 	if userSetDirectory != "" {
 		return userSetDirectory
@@ -81,3 +105,20 @@ func GetCniBinDirectory(staticInfo staticconfig.Provider,
 	}
 	return consts.DefaultCniBinDirectory
 }
+
+// GetCniConfDirectory returns the location where the CNI configurations are stored on the node.
+func GetCniConfDirectory(staticInfo staticconfig.Provider, cniDirectories *mellanoxv1alpha1.CNIDirectoriesSpec) string {
+	// The same precedence as for the CNI binaries applies, the default location is the same for Openshift / K8s
+	if cniDirectories != nil {
+		if cniDirectories.ConfDirectory != "" {
+			return cniDirectories.ConfDirectory
+		}
+		if flavor, ok := cniFlavorDirectories[cniDirectories.Flavor]; ok {
+			return flavor.conf
+		}
+	}
+	if userSetDirectory := staticInfo.GetStaticConfig().CniConfDirectory; userSetDirectory != "" {
+		return userSetDirectory
+	}
+	return consts.DefaultCniConfDirectory
+}
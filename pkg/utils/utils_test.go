@@ -20,6 +20,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	mellanoxv1alpha1 "github.com/Mellanox/network-operator/api/v1alpha1"
 	"github.com/Mellanox/network-operator/pkg/clustertype/mocks"
 	"github.com/Mellanox/network-operator/pkg/consts"
 	"github.com/Mellanox/network-operator/pkg/staticconfig"
@@ -33,7 +34,7 @@ var _ = Describe("Utils tests", func() {
 			staticConfigProvider := staticconfig.NewProvider(staticconfig.StaticConfig{CniBinDirectory: userSetDir})
 			clusterTypeProvider := mocks.Provider{}
 			clusterTypeProvider.On("IsOpenshift").Return(false)
-			result := GetCniBinDirectory(staticConfigProvider, &clusterTypeProvider)
+			result := GetCniBinDirectory(staticConfigProvider, &clusterTypeProvider, nil)
 			Expect(result).To(Equal(userSetDir))
 		})
 
@@ -42,7 +43,7 @@ var _ = Describe("Utils tests", func() {
 			staticConfigProvider := staticconfig.NewProvider(staticconfig.StaticConfig{CniBinDirectory: userSetDir})
 			clusterTypeProvider := mocks.Provider{}
 			clusterTypeProvider.On("IsOpenshift").Return(true)
-			result := GetCniBinDirectory(staticConfigProvider, &clusterTypeProvider)
+			result := GetCniBinDirectory(staticConfigProvider, &clusterTypeProvider, nil)
 			Expect(result).To(Equal(userSetDir))
 		})
 
@@ -50,7 +51,7 @@ var _ = Describe("Utils tests", func() {
 			staticConfigProvider := staticconfig.NewProvider(staticconfig.StaticConfig{CniBinDirectory: ""})
 			clusterTypeProvider := mocks.Provider{}
 			clusterTypeProvider.On("IsOpenshift").Return(true)
-			result := GetCniBinDirectory(staticConfigProvider, &clusterTypeProvider)
+			result := GetCniBinDirectory(staticConfigProvider, &clusterTypeProvider, nil)
 			Expect(result).To(Equal(consts.OcpCniBinDirectory))
 		})
 
@@ -58,14 +59,62 @@ var _ = Describe("Utils tests", func() {
 			staticConfigProvider := staticconfig.NewProvider(staticconfig.StaticConfig{CniBinDirectory: ""})
 			clusterTypeProvider := mocks.Provider{}
 			clusterTypeProvider.On("IsOpenshift").Return(false)
-			result := GetCniBinDirectory(staticConfigProvider, &clusterTypeProvider)
+			result := GetCniBinDirectory(staticConfigProvider, &clusterTypeProvider, nil)
 			Expect(result).To(Equal(consts.DefaultCniBinDirectory))
 		})
 
 		It("Should return default K8s directory if cluster info is nil", func() {
 			staticConfigProvider := staticconfig.NewProvider(staticconfig.StaticConfig{CniBinDirectory: ""})
-			result := GetCniBinDirectory(staticConfigProvider, nil)
+			result := GetCniBinDirectory(staticConfigProvider, nil, nil)
 			Expect(result).To(Equal(consts.DefaultCniBinDirectory))
 		})
+
+		It("Should return the directory set in the NicClusterPolicy", func() {
+			staticConfigProvider := staticconfig.NewProvider(staticconfig.StaticConfig{CniBinDirectory: "/user/set/directory"})
+			clusterTypeProvider := mocks.Provider{}
+			clusterTypeProvider.On("IsOpenshift").Return(false)
+			cniDirectories := &mellanoxv1alpha1.CNIDirectoriesSpec{
+				Flavor: mellanoxv1alpha1.CNIFlavorK3s, BinDirectory: "/policy/set/directory"}
+			result := GetCniBinDirectory(staticConfigProvider, &clusterTypeProvider, cniDirectories)
+			Expect(result).To(Equal("/policy/set/directory"))
+		})
+
+		It("Should return the directory of the flavor set in the NicClusterPolicy", func() {
+			staticConfigProvider := staticconfig.NewProvider(staticconfig.StaticConfig{CniBinDirectory: "/user/set/directory"})
+			clusterTypeProvider := mocks.Provider{}
+			clusterTypeProvider.On("IsOpenshift").Return(false)
+			cniDirectories := &mellanoxv1alpha1.CNIDirectoriesSpec{Flavor: mellanoxv1alpha1.CNIFlavorMicroK8s}
+			result := GetCniBinDirectory(staticConfigProvider, &clusterTypeProvider, cniDirectories)
+			Expect(result).To(Equal("/var/snap/microk8s/current/opt/cni/bin"))
+		})
+	})
+
+	Context("Testing CniConfDirectory retrieval", func() {
+		It("Should return default directory", func() {
+			staticConfigProvider := staticconfig.NewProvider(staticconfig.StaticConfig{})
+			result := GetCniConfDirectory(staticConfigProvider, nil)
+			Expect(result).To(Equal(consts.DefaultCniConfDirectory))
+		})
+
+		It("Should return user set directory", func() {
+			staticConfigProvider := staticconfig.NewProvider(staticconfig.StaticConfig{CniConfDirectory: "/user/set/directory"})
+			result := GetCniConfDirectory(staticConfigProvider, &mellanoxv1alpha1.CNIDirectoriesSpec{})
+			Expect(result).To(Equal("/user/set/directory"))
+		})
+
+		It("Should return the directory of the flavor set in the NicClusterPolicy", func() {
+			staticConfigProvider := staticconfig.NewProvider(staticconfig.StaticConfig{CniConfDirectory: "/user/set/directory"})
+			cniDirectories := &mellanoxv1alpha1.CNIDirectoriesSpec{Flavor: mellanoxv1alpha1.CNIFlavorK3s}
+			result := GetCniConfDirectory(staticConfigProvider, cniDirectories)
+			Expect(result).To(Equal("/var/lib/rancher/k3s/agent/etc/cni/net.d"))
+		})
+
+		It("Should return the directory set in the NicClusterPolicy", func() {
+			staticConfigProvider := staticconfig.NewProvider(staticconfig.StaticConfig{})
+			cniDirectories := &mellanoxv1alpha1.CNIDirectoriesSpec{
+				Flavor: mellanoxv1alpha1.CNIFlavorK3s, ConfDirectory: "/policy/set/directory"}
+			result := GetCniConfDirectory(staticConfigProvider, cniDirectories)
+			Expect(result).To(Equal("/policy/set/directory"))
+		})
 	})
 })
@@ -0,0 +1,171 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookcert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const caCommonName = "network-operator-webhook-ca"
+
+// keyPair is a certificate along with its private key
+type keyPair struct {
+	Cert    *x509.Certificate
+	CertPEM []byte
+	KeyPEM  []byte
+	key     *ecdsa.PrivateKey
+}
+
+// newCA generates a self-signed CA valid for the given duration
+func newCA(validity time.Duration) (*keyPair, error) {
+	tmpl := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: caCommonName},
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return newKeyPair(tmpl, nil, validity)
+}
+
+// newServingCert generates a serving certificate for the given DNS names signed by the CA
+func newServingCert(ca *keyPair, dnsNames []string, validity time.Duration) (*keyPair, error) {
+	tmpl := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:    dnsNames,
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	return newKeyPair(tmpl, ca, validity)
+}
+
+// newKeyPair generates a key and a certificate from the template, the certificate is self-signed if parent is nil
+func newKeyPair(tmpl *x509.Certificate, parent *keyPair, validity time.Duration) (*keyPair, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate private key")
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate serial number")
+	}
+	now := time.Now()
+	tmpl.SerialNumber = serial
+	// tolerate clock skew between the operator and the API server
+	tmpl.NotBefore = now.Add(-time.Hour)
+	tmpl.NotAfter = now.Add(validity)
+
+	parentCert, signer := tmpl, key
+	if parent != nil {
+		parentCert, signer = parent.Cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parentCert, &key.PublicKey, signer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create certificate")
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse created certificate")
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal private key")
+	}
+	return &keyPair{
+		Cert:    cert,
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		KeyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		key:     key,
+	}, nil
+}
+
+// parseKeyPair parses a PEM encoded certificate and private key,
+// only the first certificate of certPEM is considered
+func parseKeyPair(certPEM, keyPEM []byte) (*keyPair, error) {
+	certs, err := parseCerts(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("failed to decode private key")
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse private key")
+	}
+	return &keyPair{
+		Cert:    certs[0],
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certs[0].Raw}),
+		KeyPEM:  keyPEM,
+		key:     key,
+	}, nil
+}
+
+// parseCerts parses all the PEM encoded certificates
+func parseCerts(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse certificate")
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate found")
+	}
+	return certs, nil
+}
+
+// isServingCertValid returns true if the serving certificate is signed by the CA, is issued for all the DNS names
+// and is valid for longer than the threshold
+func isServingCertValid(cert *x509.Certificate, ca *x509.Certificate, dnsNames []string, threshold time.Duration) bool {
+	if !isCertValid(cert, threshold) {
+		return false
+	}
+	if err := cert.CheckSignatureFrom(ca); err != nil {
+		return false
+	}
+	for _, name := range dnsNames {
+		if err := cert.VerifyHostname(name); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// isCertValid returns true if the certificate is valid for longer than the threshold
+func isCertValid(cert *x509.Certificate, threshold time.Duration) bool {
+	return time.Now().Add(threshold).Before(cert.NotAfter)
+}
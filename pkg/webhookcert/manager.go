@@ -0,0 +1,306 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhookcert generates and rotates the serving certificate of the webhook server of the Operator.
+package webhookcert
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/Mellanox/network-operator/pkg/config"
+	"github.com/Mellanox/network-operator/pkg/consts"
+)
+
+const (
+	// CACertKey is the key of the CA bundle in the certificate Secret
+	CACertKey = "ca.crt"
+	// CAKeyKey is the key of the CA private key in the certificate Secret
+	CAKeyKey = "ca.key"
+
+	// the CA is valid caValidityFactor times longer than the serving certificate
+	caValidityFactor = 10
+)
+
+// Manager generates the serving certificate of the webhook server and its CA, stores them in a Secret shared
+// by all the replicas, writes the serving certificate to the directory of the webhook server
+// and injects the CA into the ValidatingWebhookConfiguration and into the conversion webhook of the CRD.
+// The certificates are rotated once their remaining validity is below the rotation threshold,
+// the previous CA is kept in the CA bundle until it expires so that certificates it signed remain trusted.
+type Manager struct {
+	K8sClient client.Client
+	Namespace string
+	CertDir   string
+	Config    config.WebhookConfig
+	Logger    logr.Logger
+}
+
+// NeedLeaderElection implements manager.NeedLeaderElection,
+// webhooks are served by all the replicas which need the certificate
+func (m *Manager) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable, checks the certificate periodically until the context is cancelled
+func (m *Manager) Start(ctx context.Context) error {
+	ticker := time.NewTicker(m.Config.CertCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := m.Ensure(ctx); err != nil {
+				// retried on the next tick, the current certificate is valid for longer than the check interval
+				m.Logger.V(consts.LogLevelError).Error(err, "failed to ensure webhook certificate")
+			}
+		}
+	}
+}
+
+// Ensure makes sure that a valid certificate exists, is used by the webhook server and trusted by the API server
+func (m *Manager) Ensure(ctx context.Context) error {
+	secret, err := m.ensureSecret(ctx)
+	if err != nil {
+		return err
+	}
+	if err := m.writeCertFiles(secret); err != nil {
+		return err
+	}
+	if err := m.injectCABundle(ctx, secret.Data[CACertKey]); err != nil {
+		return err
+	}
+	return m.configureConversionWebhook(ctx, secret.Data[CACertKey])
+}
+
+// dnsNames returns the DNS names of the webhook Service
+func (m *Manager) dnsNames() []string {
+	svc := fmt.Sprintf("%s.%s.svc", m.Config.ServiceName, m.Namespace)
+	return []string{svc, svc + ".cluster.local"}
+}
+
+// ensureSecret returns the certificate Secret, creating it or rotating the certificates it holds if needed
+func (m *Manager) ensureSecret(ctx context.Context) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: m.Namespace, Name: m.Config.CertSecretName}
+	err := m.K8sClient.Get(ctx, key, secret)
+	if err != nil && !apiErrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "failed to get webhook certificate secret")
+	}
+	if apiErrors.IsNotFound(err) {
+		data, err := m.generate(nil)
+		if err != nil {
+			return nil, err
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: m.Namespace, Name: m.Config.CertSecretName},
+			Type:       corev1.SecretTypeTLS,
+			Data:       data,
+		}
+		m.Logger.V(consts.LogLevelInfo).Info("creating webhook certificate secret", "secret", key)
+		err = m.K8sClient.Create(ctx, secret)
+		if apiErrors.IsAlreadyExists(err) {
+			// another replica created the secret, use it
+			err = m.K8sClient.Get(ctx, key, secret)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create webhook certificate secret")
+		}
+		return secret, nil
+	}
+
+	data, err := m.generate(secret.Data)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return secret, nil
+	}
+	m.Logger.V(consts.LogLevelInfo).Info("rotating webhook certificate", "secret", key)
+	secret.Data = data
+	if err := m.K8sClient.Update(ctx, secret); err != nil {
+		// on conflict another replica rotated the certificate, the update is retried on the next check
+		return nil, errors.Wrap(err, "failed to update webhook certificate secret")
+	}
+	return secret, nil
+}
+
+// generate returns the content of the certificate Secret with the certificates rotated if needed,
+// nil is returned if the current content is valid
+func (m *Manager) generate(current map[string][]byte) (map[string][]byte, error) {
+	threshold := m.Config.CertRotationThreshold
+
+	var ca *keyPair
+	if len(current) != 0 {
+		var err error
+		ca, err = parseKeyPair(current[CACertKey], current[CAKeyKey])
+		if err != nil {
+			m.Logger.V(consts.LogLevelWarning).Info("invalid webhook CA, regenerating it", "reason", err.Error())
+		}
+	}
+
+	if ca != nil && isCertValid(ca.Cert, threshold) {
+		serving, err := parseKeyPair(current[corev1.TLSCertKey], current[corev1.TLSPrivateKeyKey])
+		if err == nil && isServingCertValid(serving.Cert, ca.Cert, m.dnsNames(), threshold) {
+			return nil, nil
+		}
+	} else {
+		var err error
+		ca, err = newCA(m.Config.CertValidity * caValidityFactor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	serving, err := newServingCert(ca, m.dnsNames(), m.Config.CertValidity)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		corev1.TLSCertKey:       serving.CertPEM,
+		corev1.TLSPrivateKeyKey: serving.KeyPEM,
+		CACertKey:               caBundle(ca, current[CACertKey]),
+		CAKeyKey:                ca.KeyPEM,
+	}, nil
+}
+
+// caBundle returns the PEM encoded CA followed by the not yet expired CAs of the previous bundle,
+// certificates signed by a previous CA are trusted until all the replicas serve the new certificate
+func caBundle(ca *keyPair, previous []byte) []byte {
+	bundle := append([]byte{}, ca.CertPEM...)
+	certs, err := parseCerts(previous)
+	if err != nil {
+		return bundle
+	}
+	for _, cert := range certs {
+		if cert.Equal(ca.Cert) || !isCertValid(cert, 0) {
+			continue
+		}
+		bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	return bundle
+}
+
+// writeCertFiles writes the serving certificate to the directory of the webhook server,
+// the files are only written if their content changed, the webhook server reloads them on change
+func (m *Manager) writeCertFiles(secret *corev1.Secret) error {
+	if err := os.MkdirAll(m.CertDir, 0o755); err != nil {
+		return errors.Wrap(err, "failed to create webhook certificate directory")
+	}
+	for _, name := range []string{corev1.TLSPrivateKeyKey, corev1.TLSCertKey} {
+		path := filepath.Join(m.CertDir, name)
+		if content, err := os.ReadFile(path); err == nil && bytes.Equal(content, secret.Data[name]) {
+			continue
+		}
+		if err := os.WriteFile(path, secret.Data[name], 0o600); err != nil {
+			return errors.Wrapf(err, "failed to write webhook certificate file %s", path)
+		}
+	}
+	return nil
+}
+
+// injectCABundle sets the CA bundle of all the webhooks of the ValidatingWebhookConfiguration
+func (m *Manager) injectCABundle(ctx context.Context, bundle []byte) error {
+	cfg := &admissionv1.ValidatingWebhookConfiguration{}
+	if err := m.K8sClient.Get(ctx, types.NamespacedName{Name: m.Config.ConfigurationName}, cfg); err != nil {
+		return errors.Wrap(err, "failed to get ValidatingWebhookConfiguration")
+	}
+	patch := client.MergeFrom(cfg.DeepCopy())
+	changed := false
+	for i := range cfg.Webhooks {
+		if !bytes.Equal(cfg.Webhooks[i].ClientConfig.CABundle, bundle) {
+			cfg.Webhooks[i].ClientConfig.CABundle = bundle
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	m.Logger.V(consts.LogLevelInfo).Info("injecting CA bundle", "ValidatingWebhookConfiguration", cfg.Name)
+	if err := m.K8sClient.Patch(ctx, cfg, patch); err != nil {
+		return errors.Wrap(err, "failed to patch ValidatingWebhookConfiguration")
+	}
+	return nil
+}
+
+// configureConversionWebhook sets the conversion of the CRD with several API versions to the conversion webhook
+// of the webhook server, with the CA bundle. The CRDs deployed by the Helm chart can't reference the Service of
+// the release, the conversion is configured once the Operator runs. The CRD is skipped if it doesn't exist.
+func (m *Manager) configureConversionWebhook(ctx context.Context, bundle []byte) error {
+	if m.Config.ConversionCRDName == "" {
+		return nil
+	}
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(schema.GroupVersionKind{
+		Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"})
+	if err := m.K8sClient.Get(ctx, types.NamespacedName{Name: m.Config.ConversionCRDName}, crd); err != nil {
+		if apiErrors.IsNotFound(err) {
+			m.Logger.V(consts.LogLevelInfo).Info("CRD not found, skipping conversion webhook configuration",
+				"CustomResourceDefinition", m.Config.ConversionCRDName)
+			return nil
+		}
+		return errors.Wrap(err, "failed to get CustomResourceDefinition")
+	}
+	conversion := map[string]interface{}{
+		"strategy": "Webhook",
+		"webhook": map[string]interface{}{
+			"clientConfig": map[string]interface{}{
+				"service": map[string]interface{}{
+					"namespace": m.Namespace,
+					"name":      m.Config.ServiceName,
+					"path":      "/convert",
+					"port":      int64(443),
+				},
+				"caBundle": base64.StdEncoding.EncodeToString(bundle),
+			},
+			"conversionReviewVersions": []interface{}{"v1"},
+		},
+	}
+	current, _, err := unstructured.NestedMap(crd.Object, "spec", "conversion")
+	if err != nil {
+		return errors.Wrap(err, "failed to get conversion of CustomResourceDefinition")
+	}
+	if equality.Semantic.DeepEqual(current, conversion) {
+		return nil
+	}
+	patch := client.MergeFrom(crd.DeepCopy())
+	if err := unstructured.SetNestedMap(crd.Object, conversion, "spec", "conversion"); err != nil {
+		return errors.Wrap(err, "failed to set conversion of CustomResourceDefinition")
+	}
+	m.Logger.V(consts.LogLevelInfo).Info("configuring conversion webhook", "CustomResourceDefinition", crd.GetName())
+	if err := m.K8sClient.Patch(ctx, crd, patch); err != nil {
+		return errors.Wrap(err, "failed to patch CustomResourceDefinition")
+	}
+	return nil
+}
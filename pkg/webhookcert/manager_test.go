@@ -0,0 +1,210 @@
+/*
+2024 NVIDIA CORPORATION & AFFILIATES
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookcert
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/Mellanox/network-operator/pkg/config"
+)
+
+const (
+	testNamespace     = "nvidia-network-operator"
+	testSecretName    = "webhook-server-cert"
+	testWebhookConfig = "network-operator-validating-webhook"
+)
+
+var _ = Describe("Webhook certificate Manager", func() {
+	var (
+		ctx       context.Context
+		k8sClient client.Client
+		m         *Manager
+	)
+
+	getSecret := func() *corev1.Secret {
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: testSecretName},
+			secret)).To(Succeed())
+		return secret
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		k8sClient = fake.NewClientBuilder().WithObjects(&admissionv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: testWebhookConfig},
+			Webhooks: []admissionv1.ValidatingWebhook{
+				{Name: "vnicclusterpolicy.kb.io"}, {Name: "vhostdevicenetwork.kb.io"}},
+		}).Build()
+		m = &Manager{
+			K8sClient: k8sClient,
+			Namespace: testNamespace,
+			CertDir:   GinkgoT().TempDir(),
+			Config: config.WebhookConfig{
+				CertSecretName:        testSecretName,
+				ServiceName:           "network-operator-webhook-service",
+				ConfigurationName:     testWebhookConfig,
+				CertValidity:          365 * 24 * time.Hour,
+				CertRotationThreshold: 30 * 24 * time.Hour,
+			},
+			Logger: logr.Discard(),
+		}
+	})
+
+	It("should generate the certificate, write it and inject the CA bundle", func() {
+		Expect(m.Ensure(ctx)).To(Succeed())
+		secret := getSecret()
+
+		ca, err := parseCerts(secret.Data[CACertKey])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ca).To(HaveLen(1))
+		serving, err := parseKeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(serving.Cert.DNSNames).To(ConsistOf(
+			"network-operator-webhook-service.nvidia-network-operator.svc",
+			"network-operator-webhook-service.nvidia-network-operator.svc.cluster.local"))
+		Expect(serving.Cert.CheckSignatureFrom(ca[0])).To(Succeed())
+
+		for _, name := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+			content, err := os.ReadFile(filepath.Join(m.CertDir, name))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(content).To(Equal(secret.Data[name]))
+		}
+
+		cfg := &admissionv1.ValidatingWebhookConfiguration{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: testWebhookConfig}, cfg)).To(Succeed())
+		for _, webhook := range cfg.Webhooks {
+			Expect(webhook.ClientConfig.CABundle).To(Equal(secret.Data[CACertKey]))
+		}
+	})
+	It("should keep a valid certificate", func() {
+		Expect(m.Ensure(ctx)).To(Succeed())
+		before := getSecret()
+		Expect(m.Ensure(ctx)).To(Succeed())
+		Expect(getSecret().Data).To(Equal(before.Data))
+	})
+	It("should rotate the serving certificate once it expires within the threshold", func() {
+		Expect(m.Ensure(ctx)).To(Succeed())
+		before := getSecret()
+
+		m.Config.CertRotationThreshold = m.Config.CertValidity
+		Expect(m.Ensure(ctx)).To(Succeed())
+		after := getSecret()
+		Expect(after.Data[corev1.TLSCertKey]).NotTo(Equal(before.Data[corev1.TLSCertKey]))
+		Expect(after.Data[CAKeyKey]).To(Equal(before.Data[CAKeyKey]))
+		Expect(after.Data[CACertKey]).To(Equal(before.Data[CACertKey]))
+	})
+	It("should rotate the CA and keep the previous one in the bundle", func() {
+		Expect(m.Ensure(ctx)).To(Succeed())
+		before := getSecret()
+
+		m.Config.CertRotationThreshold = m.Config.CertValidity * caValidityFactor
+		Expect(m.Ensure(ctx)).To(Succeed())
+		after := getSecret()
+		Expect(after.Data[CAKeyKey]).NotTo(Equal(before.Data[CAKeyKey]))
+		bundle, err := parseCerts(after.Data[CACertKey])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bundle).To(HaveLen(2))
+		previous, err := parseCerts(before.Data[CACertKey])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bundle[1].Equal(previous[0])).To(BeTrue())
+
+		serving, err := parseKeyPair(after.Data[corev1.TLSCertKey], after.Data[corev1.TLSPrivateKeyKey])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(serving.Cert.CheckSignatureFrom(bundle[0])).To(Succeed())
+	})
+	It("should reissue the serving certificate if the Service changed", func() {
+		Expect(m.Ensure(ctx)).To(Succeed())
+
+		m.Config.ServiceName = "other-webhook-service"
+		Expect(m.Ensure(ctx)).To(Succeed())
+		secret := getSecret()
+		serving, err := parseKeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(serving.Cert.VerifyHostname("other-webhook-service.nvidia-network-operator.svc")).To(Succeed())
+	})
+	It("should replace a Secret without CA", func() {
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testSecretName},
+			Data:       map[string][]byte{corev1.TLSCertKey: []byte("invalid")},
+		})).To(Succeed())
+		Expect(m.Ensure(ctx)).To(Succeed())
+		secret := getSecret()
+		pool := x509.NewCertPool()
+		Expect(pool.AppendCertsFromPEM(secret.Data[CACertKey])).To(BeTrue())
+		serving, err := parseKeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		Expect(err).NotTo(HaveOccurred())
+		_, err = serving.Cert.Verify(x509.VerifyOptions{
+			DNSName: "network-operator-webhook-service.nvidia-network-operator.svc", Roots: pool})
+		Expect(err).NotTo(HaveOccurred())
+	})
+	It("should fail if the ValidatingWebhookConfiguration doesn't exist", func() {
+		m.Config.ConfigurationName = "missing"
+		Expect(m.Ensure(ctx)).NotTo(Succeed())
+	})
+	It("should configure the conversion webhook of the CRD", func() {
+		crdGVK := schema.GroupVersionKind{
+			Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+		crd := &unstructured.Unstructured{}
+		crd.SetGroupVersionKind(crdGVK)
+		crd.SetName("nicclusterpolicies.mellanox.com")
+		Expect(unstructured.SetNestedField(crd.Object, "None", "spec", "conversion", "strategy")).To(Succeed())
+		Expect(k8sClient.Create(ctx, crd)).To(Succeed())
+
+		m.Config.ConversionCRDName = crd.GetName()
+		Expect(m.Ensure(ctx)).To(Succeed())
+
+		crd = &unstructured.Unstructured{}
+		crd.SetGroupVersionKind(crdGVK)
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "nicclusterpolicies.mellanox.com"}, crd)).To(Succeed())
+		strategy, _, err := unstructured.NestedString(crd.Object, "spec", "conversion", "strategy")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strategy).To(Equal("Webhook"))
+		name, _, err := unstructured.NestedString(crd.Object,
+			"spec", "conversion", "webhook", "clientConfig", "service", "name")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("network-operator-webhook-service"))
+		path, _, err := unstructured.NestedString(crd.Object,
+			"spec", "conversion", "webhook", "clientConfig", "service", "path")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(path).To(Equal("/convert"))
+		caBundle, _, err := unstructured.NestedString(crd.Object,
+			"spec", "conversion", "webhook", "clientConfig", "caBundle")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(caBundle).To(Equal(base64.StdEncoding.EncodeToString(getSecret().Data[CACertKey])))
+	})
+	It("should skip the conversion webhook of a missing CRD", func() {
+		m.Config.ConversionCRDName = "missing.mellanox.com"
+		Expect(m.Ensure(ctx)).To(Succeed())
+	})
+})